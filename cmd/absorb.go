@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var absorbNoSquash bool
+
+var absorbCmd = &cobra.Command{
+	Use:   "absorb",
+	Short: i18n.T("absorb.short"),
+	Long:  i18n.T("absorb.long"),
+	Example: `  # Address review feedback for several layers from the top of the stack
+  git add -p
+  stack absorb
+
+  # Only commit the fixups, squash them in later
+  stack absorb --no-squash`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runAbsorb(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	absorbCmd.Flags().BoolVar(&absorbNoSquash, "no-squash", false, "Only commit the fixups, without squashing them in or restacking")
+}
+
+func runAbsorb(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	if gitClient.GetConfig(configRebasePlanBackup) != "" {
+		return errors.New(i18n.T("rebase.planInProgress", ui.Command("stack rebase --continue"), ui.Command("stack rebase --abort")))
+	}
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return fmt.Errorf("%s is not in a stack", currentBranch)
+	}
+
+	staged, err := gitClient.HasStagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check staged changes: %w", err)
+	}
+	if !staged {
+		return fmt.Errorf("no staged changes to absorb\n\nStage them with '%s' first", ui.Command("git add"))
+	}
+
+	// The stack's commits, bottom up, and the branch each belongs to. chain
+	// starts with the base branch, which isn't part of the stack.
+	fork, err := gitClient.GetMergeBase(chain[0], chain[1])
+	if err != nil {
+		return fmt.Errorf("failed to find where %s starts: %w", chain[1], err)
+	}
+	var commits []string
+	owners := make(map[string]string)
+	upstream := fork
+	for _, branch := range chain[1:] {
+		branchCommits, err := gitClient.GetUniqueCommits(upstream, branch)
+		if err != nil {
+			return fmt.Errorf("failed to list commits of %s: %w", branch, err)
+		}
+		for _, commit := range branchCommits {
+			commits = append(commits, commit)
+			owners[commit] = branch
+		}
+		upstream = branch
+	}
+
+	hunks, err := gitClient.GetStagedHunks()
+	if err != nil {
+		return fmt.Errorf("failed to read staged changes: %w", err)
+	}
+	targets := make(map[string][]git.Hunk)
+	var left []git.Hunk
+	belowCurrent := false
+	for _, hunk := range hunks {
+		target := absorbTarget(gitClient, hunk, owners)
+		if target == "" {
+			left = append(left, hunk)
+			continue
+		}
+		targets[target] = append(targets[target], hunk)
+		belowCurrent = belowCurrent || owners[target] != currentBranch
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("none of the staged changes modify lines of the stack's commits\n\nCommit them with '%s' or '%s'", ui.Command("git commit"), ui.Command("stack fixup <branch>"))
+	}
+	if !absorbNoSquash && belowCurrent && !gitClient.SupportsUpdateRefs() {
+		return fmt.Errorf("squashing fixups into the branches below %s needs git 2.38 or newer\n\nCommit the fixups only with '%s'", currentBranch, ui.Command("stack absorb --no-squash"))
+	}
+
+	head, err := gitClient.GetCommitHash("HEAD")
+	if err != nil {
+		return fmt.Errorf("failed to get commit of %s: %w", currentBranch, err)
+	}
+
+	// Each fixup goes on top of the previous one, so the hunks' line numbers
+	// move with the hunks committed before them
+	parent := head
+	var committed []git.Hunk
+	for _, commit := range commits {
+		group := targets[commit]
+		if len(group) == 0 {
+			continue
+		}
+		message, err := gitClient.GetCommitMessage(commit)
+		if err != nil {
+			return fmt.Errorf("failed to read commit %s: %w", ui.ShortSHA(commit), err)
+		}
+		subject, _, _ := strings.Cut(message, "\n")
+		fixup, err := gitClient.CommitHunks(parent, shiftHunks(group, committed), "fixup! "+subject)
+		if err != nil {
+			return fmt.Errorf("failed to commit fixup of %s: %w", ui.ShortSHA(commit), err)
+		}
+		parent = fixup
+		committed = append(committed, group...)
+		fmt.Printf("  %s %s %s (%s): %d hunk(s)\n", ui.SuccessIcon(), ui.ShortSHA(commit), subject, ui.Branch(owners[commit]), len(group))
+	}
+	if err := gitClient.UpdateRef("HEAD", parent); err != nil {
+		return fmt.Errorf("failed to update %s: %w", currentBranch, err)
+	}
+
+	if len(left) > 0 {
+		fmt.Printf("\n%d hunk(s) don't belong to a single stack commit and are left uncommitted:\n", len(left))
+		for _, hunk := range left {
+			fmt.Printf("  %s:%d\n", hunk.Path, hunk.NewStart)
+		}
+	}
+	fmt.Println()
+
+	if absorbNoSquash {
+		fmt.Printf("Squash them in later with '%s'\n", ui.Command("git rebase -i --autosquash --update-refs "+ui.ShortSHA(fork)))
+		return nil
+	}
+
+	fmt.Println("Squashing fixups...")
+	if err := gitClient.RebaseAutosquash(fork); err != nil {
+		fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("conflict.rebaseStopped"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.continue"))
+		fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("absorb.conflict.abort"))
+		fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("absorb.conflict.restack"))
+		return fmt.Errorf("failed to squash fixups: %w", err)
+	}
+	if !dryRun {
+		fmt.Printf("%s Squashed %d fixup(s)\n", ui.SuccessIcon(), len(targets))
+	}
+
+	restacked, err := restackAbove(gitClient, githubClient, currentBranch, head)
+	if err == nil && len(restacked) == 0 {
+		fmt.Printf("Push the branches with '%s'\n", ui.Command("stack sync"))
+	}
+	return err
+}
+
+// absorbTarget returns the stack commit that last changed every line hunk
+// changes or removes, or "" when there isn't a single one
+func absorbTarget(gitClient git.GitClient, hunk git.Hunk, owners map[string]string) string {
+	// A pure addition doesn't touch any existing line
+	if hunk.OldLines == 0 {
+		return ""
+	}
+	blamed, err := gitClient.BlameLines("HEAD", hunk.Path, hunk.OldStart, hunk.OldLines)
+	if err != nil || len(blamed) != hunk.OldLines {
+		return ""
+	}
+	for _, commit := range blamed {
+		if commit != blamed[0] {
+			return ""
+		}
+	}
+	if owners[blamed[0]] == "" {
+		return ""
+	}
+	return blamed[0]
+}
+
+// shiftHunks moves hunks by the lines that hunks committed before them added or
+// removed above them in the same file
+func shiftHunks(hunks, committed []git.Hunk) []git.Hunk {
+	shifted := make([]git.Hunk, len(hunks))
+	for i, hunk := range hunks {
+		for _, c := range committed {
+			if c.Path == hunk.Path && c.OldStart < hunk.OldStart {
+				hunk.OldStart += c.NewLines - c.OldLines
+			}
+		}
+		shifted[i] = hunk
+	}
+	return shifted
+}
+
+// restackAbove rebases the branches stacked on branch, which was at oldTip, onto
+// its new commits with a rebase plan. It returns the branches it rebased, parents
+// first.
+func restackAbove(gitClient git.GitClient, githubClient github.GitHubClient, branch, oldTip string) ([]string, error) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	children := make(map[string][]string)
+	for child, parent := range parents {
+		children[parent] = append(children[parent], child)
+	}
+
+	var steps []planStep
+	var branches []string
+	tips := map[string]string{branch: oldTip}
+	queue := []string{branch}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+		sort.Strings(children[parent])
+		for _, child := range children[parent] {
+			tip, err := gitClient.GetCommitHash(child)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get commit of %s: %w", child, err)
+			}
+			tips[child] = tip
+			steps = append(steps, planStep{"rebase", child, tips[parent], parent})
+			branches = append(branches, child)
+			queue = append(queue, child)
+		}
+	}
+
+	if len(steps) == 0 {
+		return nil, nil
+	}
+	fmt.Println()
+	return branches, applyRebasePlan(gitClient, githubClient, steps, branches, branch)
+}
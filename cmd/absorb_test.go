@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestShiftHunks(t *testing.T) {
+	committed := []git.Hunk{
+		{Path: "a.go", OldStart: 2, OldLines: 1, NewLines: 3},
+		{Path: "a.go", OldStart: 30, OldLines: 2, NewLines: 0},
+		{Path: "b.go", OldStart: 1, OldLines: 1, NewLines: 5},
+	}
+	hunks := []git.Hunk{
+		{Path: "a.go", OldStart: 10, OldLines: 1, NewLines: 1},
+		{Path: "a.go", OldStart: 40, OldLines: 1, NewLines: 1},
+		{Path: "c.go", OldStart: 10, OldLines: 1, NewLines: 1},
+	}
+
+	shifted := shiftHunks(hunks, committed)
+
+	assert.Equal(t, 12, shifted[0].OldStart)
+	assert.Equal(t, 40, shifted[1].OldStart)
+	assert.Equal(t, 10, shifted[2].OldStart)
+	assert.Equal(t, 10, hunks[0].OldStart, "hunks are copied")
+}
+
+func TestRunAbsorb(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	hunkA := git.Hunk{Path: "a.go", OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 1, Lines: []string{"-old", "+new"}}
+	hunkB := git.Hunk{Path: "a.go", OldStart: 10, OldLines: 2, NewStart: 10, NewLines: 3, Lines: []string{"-x", "-y", "+x", "+y", "+z"}}
+	added := git.Hunk{Path: "a.go", OldStart: 20, OldLines: 0, NewStart: 21, NewLines: 1, Lines: []string{"+added"}}
+	mixed := git.Hunk{Path: "b.go", OldStart: 1, OldLines: 2, NewStart: 1, NewLines: 2, Lines: []string{"-p", "-q", "+P", "+Q"}}
+
+	tests := []struct {
+		name          string
+		noSquash      bool
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name: "commits a fixup per commit and squashes them in",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+				mockGit.On("GetStagedHunks").Return([]git.Hunk{hunkA, hunkB, added, mixed}, nil)
+				// No BlameLines for the added hunk: it has no old lines to blame
+				mockGit.On("BlameLines", "HEAD", "a.go", 3, 1).Return([]string{"b2"}, nil)
+				mockGit.On("BlameLines", "HEAD", "a.go", 10, 2).Return([]string{"a1", "a1"}, nil)
+				mockGit.On("BlameLines", "HEAD", "b.go", 1, 2).Return([]string{"a1", "b1"}, nil)
+				mockGit.On("SupportsUpdateRefs").Return(true)
+				mockGit.On("GetCommitHash", "HEAD").Return("head", nil)
+				mockGit.On("GetCommitMessage", "a1").Return("Add a\n\nBody", nil)
+				mockGit.On("GetCommitMessage", "b2").Return("Tweak a", nil)
+				// a1's fixup comes first, so b2's hunk is moved by nothing above it
+				mockGit.On("CommitHunks", "head", []git.Hunk{hunkB}, "fixup! Add a").Return("fix1", nil)
+				mockGit.On("CommitHunks", "fix1", []git.Hunk{hunkA}, "fixup! Tweak a").Return("fix2", nil)
+				mockGit.On("UpdateRef", "HEAD", "fix2").Return(nil)
+				mockGit.On("RebaseAutosquash", "fork").Return(nil)
+				mockGit.On("GetCommitHash", "feature-c").Return("sha-c", nil)
+				mockGit.On("SetConfig", configRebasePlanBackup, "feature-c sha-c").Return(nil)
+				mockGit.On("SetConfig", configRebasePlanOriginalBranch, "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-b", "head", "feature-c").Return(nil)
+				mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+				mockGit.On("UnsetConfig", mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:     "only commits the fixups with --no-squash",
+			noSquash: true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No RebaseAutosquash: the fixups are left for the user to squash
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+				mockGit.On("GetStagedHunks").Return([]git.Hunk{hunkA}, nil)
+				mockGit.On("BlameLines", "HEAD", "a.go", 3, 1).Return([]string{"b2"}, nil)
+				mockGit.On("GetCommitHash", "HEAD").Return("head", nil)
+				mockGit.On("GetCommitMessage", "b2").Return("Tweak a", nil)
+				mockGit.On("CommitHunks", "head", []git.Hunk{hunkA}, "fixup! Tweak a").Return("fix1", nil)
+				mockGit.On("UpdateRef", "HEAD", "fix1").Return(nil)
+			},
+		},
+		{
+			name: "fails when no hunk belongs to a stack commit",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No UpdateRef: nothing is committed
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+				mockGit.On("GetStagedHunks").Return([]git.Hunk{added, mixed}, nil)
+				mockGit.On("BlameLines", "HEAD", "b.go", 1, 2).Return([]string{"a1", "b1"}, nil)
+			},
+			expectError:   true,
+			errorContains: "none of the staged changes",
+		},
+		{
+			name: "needs --update-refs to squash into lower branches",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CommitHunks: the check comes before anything is committed
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+				mockGit.On("GetStagedHunks").Return([]git.Hunk{hunkB}, nil)
+				mockGit.On("BlameLines", "HEAD", "a.go", 10, 2).Return([]string{"a1", "a1"}, nil)
+				mockGit.On("SupportsUpdateRefs").Return(false)
+			},
+			expectError:   true,
+			errorContains: "git 2.38",
+		},
+		{
+			name: "refuses without staged changes",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("HasStagedChanges").Return(false, nil)
+			},
+			expectError:   true,
+			errorContains: "no staged changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			absorbNoSquash = tt.noSquash
+			defer func() { absorbNoSquash = false }()
+
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			mockGit.On("GetConfig", configRebasePlanBackup).Return("")
+			mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}, nil)
+			tt.setupMocks(mockGit)
+
+			err := runAbsorb(mockGit, mockGH)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
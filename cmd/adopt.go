@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// adoptFromPRs infers the stacks from the bases of open PRs
+var adoptFromPRs bool
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: i18n.T("adopt.short"),
+	Long:  i18n.T("adopt.long"),
+	Example: `  # Track the stacks you opened with gh or the web UI
+  stack adopt --from-prs
+
+  # Without asking for confirmation
+  stack adopt --from-prs --yes`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if !adoptFromPRs {
+			exitWithError(errors.New("nothing to adopt from, pass --from-prs"))
+		}
+		if err := runAdoptFromPRs(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	adoptCmd.Flags().BoolVar(&adoptFromPRs, "from-prs", false, "Infer the stacks from the base branches of your open PRs")
+}
+
+// runAdoptFromPRs tracks the stacks formed by the current user's open PRs, after
+// showing them for confirmation
+func runAdoptFromPRs(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	var prs []*github.PRInfo
+	if err := spinner.WrapWithAutoDelay("Fetching PRs...", 300*time.Millisecond, func() error {
+		var err error
+		prs, err = githubClient.ListAuthoredPRs("@me")
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(prs) == 0 {
+		fmt.Println("No open PRs found.")
+		return nil
+	}
+
+	stacks := groupPRsByStack(prs)
+	var changes []*github.PRInfo
+	for i, s := range stacks {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf(" %s\n", ui.Branch(s.base))
+		for _, row := range s.rows {
+			pr := row.pr
+			note := ""
+			switch current := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", pr.Head)); current {
+			case pr.Base:
+				note = " " + ui.Dim("(already tracked)")
+			case "":
+				changes = append(changes, pr)
+			default:
+				note = fmt.Sprintf(" %s parent was %s", ui.WarningIcon(), ui.Branch(current))
+				changes = append(changes, pr)
+			}
+			fmt.Printf("   %s%s %s%s\n", strings.Repeat("  ", row.depth), ui.Branch(pr.Head), ui.Dim(fmt.Sprintf("#%d", pr.Number)), note)
+		}
+	}
+	fmt.Println()
+
+	if len(changes) == 0 {
+		fmt.Println(ui.Success("All of your open PRs are already tracked"))
+		return nil
+	}
+	if !assumeYes && !confirmAdopt(len(changes)) {
+		fmt.Println("Nothing adopted.")
+		return nil
+	}
+	fmt.Println()
+
+	// Stacks list parents before children, so each base exists before its children use it
+	adopted := 0
+	for _, pr := range changes {
+		if importBranch(gitClient, pr, "") {
+			adopted++
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Success(fmt.Sprintf("Adopted %d of %d branch(es)", adopted, len(changes))))
+	fmt.Printf("\n%s\n", i18n.T("adopt.syncHint", ui.Command("stack sync")))
+	return nil
+}
+
+// confirmAdopt asks whether to write the stack parents shown
+func confirmAdopt(count int) bool {
+	fmt.Printf("Track %d branch(es) with these parents? [Y/n] ", count)
+	input, err := bufio.NewReader(stdinReader).ReadString('\n')
+	if err != nil && input == "" {
+		fmt.Println()
+		return false
+	}
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "" || input == "y" || input == "yes"
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunAdoptFromPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { stdinReader = os.Stdin }()
+
+	prs := []*github.PRInfo{
+		newAuthoredPR(2, "feature-b", "feature-a"),
+		newAuthoredPR(1, "feature-a", "main"),
+		newAuthoredPR(3, "feature-c", "feature-b"),
+	}
+
+	t.Run("tracks untracked branches once confirmed", func(t *testing.T) {
+		stdinReader = strings.NewReader("\n")
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		testutil.AllowPRPins(mockGit)
+		mockGH.On("ListAuthoredPRs", "@me").Return(prs, nil)
+
+		// feature-a is already tracked
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		// feature-b exists locally without a parent
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("")
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("BranchExists", "feature-b").Return(true)
+		mockGit.On("GetCommitHash", "feature-b").Return("b1", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("b1", nil)
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+		// feature-c was tracked on the wrong parent
+		mockGit.On("GetConfig", "branch.feature-c.stackparent").Return("main")
+		mockGit.On("FetchBranch", "feature-c").Return(nil)
+		mockGit.On("BranchExists", "feature-c").Return(true)
+		mockGit.On("GetCommitHash", "feature-c").Return("c1", nil)
+		mockGit.On("GetCommitHash", "origin/feature-c").Return("c1", nil)
+		mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-b").Return(nil)
+
+		err := runAdoptFromPRs(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "FetchBranch", "feature-a")
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("writes nothing when declined", func(t *testing.T) {
+		stdinReader = strings.NewReader("n\n")
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "@me").Return(prs, nil)
+		mockGit.On("GetConfig", mock.Anything).Return("")
+
+		err := runAdoptFromPRs(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+		mockGit.AssertNotCalled(t, "FetchBranch", mock.Anything)
+	})
+
+	t.Run("nothing to do when everything is tracked", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "@me").Return(prs[:2], nil)
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+
+		err := runAdoptFromPRs(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+}
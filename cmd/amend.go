@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	amendMessage string
+	amendAll     bool
+	amendPush    bool
+)
+
+var amendCmd = &cobra.Command{
+	Use:   "amend",
+	Short: i18n.T("amend.short"),
+	Long:  i18n.T("amend.long"),
+	Example: `  # Fold a fix into the current branch and restack the branches above
+  git add -p
+  stack amend
+
+  # Amend every tracked change, reword the commit and push the result
+  stack amend --all -m "Add login form" --push`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if amendPush {
+			strategy, err := parsePushStrategy(gitClient.GetConfig(configPushStrategy))
+			if err != nil {
+				exitWithError(err)
+			}
+			syncPushStrategy = strategy
+			if syncForcePatterns, err = parseBranchPatterns(gitClient.GetConfig(configPushForceBranches)); err != nil {
+				exitWithError(err)
+			}
+
+			// Pushes upload the branch's LFS objects too
+			configureLFS(gitClient, false)
+
+			// Explain what the push does before the first force-push in this repo
+			if !dryRun {
+				if err := ensureOnboarded(gitClient); err != nil {
+					exitWithError(err)
+				}
+			}
+		}
+
+		if err := runAmend(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	amendCmd.Flags().StringVarP(&amendMessage, "message", "m", "", "Use the given commit message")
+	amendCmd.Flags().BoolVarP(&amendAll, "all", "a", false, "Stage every change to tracked files first")
+	amendCmd.Flags().BoolVar(&amendPush, "push", false, "Push the branch and the branches above it afterwards")
+}
+
+func runAmend(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	if gitClient.GetConfig(configRebasePlanBackup) != "" {
+		return errors.New(i18n.T("rebase.planInProgress", ui.Command("stack rebase --continue"), ui.Command("stack rebase --abort")))
+	}
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", currentBranch)) == "" {
+		return fmt.Errorf("%s is not in a stack", currentBranch)
+	}
+
+	changed, err := gitClient.HasStagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check staged changes: %w", err)
+	}
+	if !changed && amendAll {
+		clean, err := gitClient.IsWorkingTreeClean()
+		if err != nil {
+			return fmt.Errorf("failed to check working tree status: %w", err)
+		}
+		changed = !clean
+	}
+	if !changed && amendMessage == "" {
+		return fmt.Errorf("no changes to amend\n\nStage them with '%s', use --all, or reword the commit with -m", ui.Command("git add"))
+	}
+
+	// A branch without commits of its own would amend its parent's tip
+	commits, err := commitsToPick(gitClient, currentBranch)
+	if err != nil {
+		return err
+	}
+	amend := len(commits) > 0
+
+	oldTip, err := gitClient.GetCommitHash(currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get commit of %s: %w", currentBranch, err)
+	}
+	if err := gitClient.Commit(amendMessage, amend, amendAll); err != nil {
+		return fmt.Errorf("failed to commit: %w", err)
+	}
+	if !dryRun {
+		if amend {
+			fmt.Printf("%s Amended the last commit of %s\n", ui.SuccessIcon(), ui.Branch(currentBranch))
+		} else {
+			fmt.Printf("%s Committed to %s\n", ui.SuccessIcon(), ui.Branch(currentBranch))
+		}
+	}
+
+	restacked, err := restackAbove(gitClient, githubClient, currentBranch, oldTip)
+	if err != nil {
+		return err
+	}
+
+	if !amendPush {
+		if len(restacked) == 0 {
+			fmt.Printf("Push it with '%s'\n", ui.Command("stack sync"))
+		}
+		return nil
+	}
+	for _, branch := range append([]string{currentBranch}, restacked...) {
+		fmt.Println()
+		fmt.Printf("Pushing %s\n", ui.Branch(branch))
+		if err := pushRestacked(gitClient, branch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
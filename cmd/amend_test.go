@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunAmend(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		message       string
+		all           bool
+		push          bool
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name: "amends and restacks the branches above",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No Push or ForcePush: only --push pushes
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("Commit", "", true, false).Return(nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}, nil)
+				mockGit.On("GetCommitHash", "feature-b").Return("old-b", nil)
+				mockGit.On("GetCommitHash", "feature-c").Return("old-c", nil)
+				mockGit.On("SetConfig", configRebasePlanBackup, "feature-b old-b\nfeature-c old-c").Return(nil)
+				mockGit.On("SetConfig", configRebasePlanOriginalBranch, "feature-a").Return(nil)
+				mockGit.On("RebaseOnto", "feature-a", "old-a", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-b", "old-b", "feature-c").Return(nil)
+				mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+				mockGit.On("UnsetConfig", mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:    "commits when the branch has no commits of its own",
+			message: "Add a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("HasStagedChanges").Return(true, nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{}, nil)
+				mockGit.On("Commit", "Add a", false, false).Return(nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+			},
+		},
+		{
+			name: "pushes with --push",
+			all:  true,
+			push: true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("HasStagedChanges").Return(false, nil)
+				mockGit.On("IsWorkingTreeClean").Return(false, nil)
+				mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("Commit", "", true, true).Return(nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+				mockGit.On("RemoteBranchExists", "feature-a").Return(true)
+				mockGit.On("GetCommitHash", "feature-a").Return("new-a", nil)
+				mockGit.On("GetCommitHash", "origin/feature-a").Return("old-a", nil)
+				mockGit.On("Push", "feature-a", true).Return(nil)
+			},
+		},
+		{
+			name: "refuses without changes or a message",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No Commit: there is nothing to amend with
+				mockGit.On("HasStagedChanges").Return(false, nil)
+			},
+			expectError:   true,
+			errorContains: "no changes to amend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amendMessage, amendAll, amendPush = tt.message, tt.all, tt.push
+			syncPushStrategy = syncer.PushLease
+			defer func() {
+				amendMessage, amendAll, amendPush = "", false, false
+				syncPushStrategy = syncer.PushLeaseSHA
+			}()
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", configRebasePlanBackup).Return("")
+			mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+			mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+			// The refusals come before the branch's commits are looked up
+			if !tt.expectError {
+				mockGit.On("GetConfig", "stack.baseBranch").Return("")
+				mockGit.On("GetDefaultBranch").Return("main")
+				mockGit.On("BranchExists", "feature-a").Return(true)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("old-a", nil).Once()
+			}
+			tt.setupMocks(mockGit)
+
+			err := runAmend(mockGit, new(testutil.MockGitHubClient))
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// configBaseBranch overrides the auto-detected base branch for a repo
+const configBaseBranch = "stack.baseBranch"
+
+var baseDetect bool
+
+var baseCmd = &cobra.Command{
+	Use:   "base",
+	Short: i18n.T("base.short"),
+	Long:  i18n.T("base.long"),
+	Example: `  # Show the effective base branch
+  stack base
+
+  # Build stacks on develop instead
+  stack base set develop
+
+  # Go back to the branch detected from origin/HEAD
+  stack base --detect`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		var err error
+		if baseDetect {
+			err = runBaseDetect(gitClient)
+		} else {
+			err = runBase(gitClient)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var baseSetCmd = &cobra.Command{
+	Use:   "set <branch>",
+	Short: i18n.T("baseSet.short"),
+	Example: `  # Build stacks on develop
+  stack base set develop
+
+  # Preview which stacks would move
+  stack base set develop --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runBaseSet(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	baseCmd.Flags().BoolVar(&baseDetect, "detect", false, "Use the base branch detected from origin/HEAD instead of stack.baseBranch")
+	baseCmd.AddCommand(baseSetCmd)
+}
+
+func runBase(gitClient git.GitClient) error {
+	configured := gitClient.GetConfig(configBaseBranch)
+	detected := gitClient.GetDefaultBranch()
+
+	if configured != "" {
+		fmt.Printf("%s %s\n", ui.Branch(configured), ui.Dim("(from "+configBaseBranch+")"))
+		if configured != detected {
+			fmt.Println(ui.Dim(fmt.Sprintf("Detected default branch is %s", detected)))
+		}
+	} else {
+		fmt.Printf("%s %s\n", ui.Branch(detected), ui.Dim("(detected)"))
+	}
+
+	base := stack.GetBaseBranch(gitClient)
+	if !gitClient.RemoteBranchExists(base) {
+		fmt.Printf("%s\n", ui.Warning(fmt.Sprintf("%s does not exist on origin", base)))
+	}
+	return nil
+}
+
+func runBaseSet(gitClient git.GitClient, newBase string) error {
+	if err := validateRemoteBase(gitClient, newBase); err != nil {
+		return err
+	}
+
+	oldBase := stack.GetBaseBranch(gitClient)
+	if err := migrateStackRoots(gitClient, oldBase, newBase); err != nil {
+		return err
+	}
+
+	if err := gitClient.SetConfig(configBaseBranch, newBase); err != nil {
+		return fmt.Errorf("failed to set %s: %w", configBaseBranch, err)
+	}
+
+	if !dryRun {
+		fmt.Println(ui.Success(fmt.Sprintf("Base branch set to %s", ui.Branch(newBase))))
+	}
+	return nil
+}
+
+func runBaseDetect(gitClient git.GitClient) error {
+	configured := gitClient.GetConfig(configBaseBranch)
+	detected := gitClient.GetDefaultBranch()
+
+	if configured == "" {
+		fmt.Printf("Already using the detected base branch %s\n", ui.Branch(detected))
+		return nil
+	}
+
+	if configured != detected {
+		if err := migrateStackRoots(gitClient, configured, detected); err != nil {
+			return err
+		}
+	}
+
+	if err := gitClient.UnsetConfig(configBaseBranch); err != nil {
+		return fmt.Errorf("failed to unset %s: %w", configBaseBranch, err)
+	}
+
+	if !dryRun {
+		fmt.Println(ui.Success(fmt.Sprintf("Base branch set to detected %s", ui.Branch(detected))))
+	}
+	return nil
+}
+
+// validateRemoteBase checks that branch exists on origin, since sync rebases onto origin/<base>
+func validateRemoteBase(gitClient git.GitClient, branch string) error {
+	if gitClient.RemoteBranchExists(branch) {
+		return nil
+	}
+	// The tracking ref may just be missing locally
+	if err := gitClient.FetchBranch(branch); err == nil && (dryRun || gitClient.RemoteBranchExists(branch)) {
+		return nil
+	}
+	return fmt.Errorf("branch %s does not exist on origin", branch)
+}
+
+// migrateStackRoots moves stacks rooted on oldBase onto newBase
+func migrateStackRoots(gitClient git.GitClient, oldBase, newBase string) error {
+	if oldBase == newBase {
+		return nil
+	}
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack parents: %w", err)
+	}
+
+	moved := 0
+	for branch, parent := range parents {
+		if parent != oldBase {
+			continue
+		}
+		configKey := fmt.Sprintf("branch.%s.stackparent", branch)
+		if err := gitClient.SetConfig(configKey, newBase); err != nil {
+			return fmt.Errorf("failed to move %s onto %s: %w", branch, newBase, err)
+		}
+		fmt.Printf("  Moved %s from %s to %s\n", ui.Branch(branch), ui.Branch(oldBase), ui.Branch(newBase))
+		moved++
+	}
+
+	if moved > 0 {
+		fmt.Println(i18n.T("base.syncHint", ui.Command("stack sync"), ui.Branch(newBase)))
+	}
+	return nil
+}
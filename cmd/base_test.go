@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunBaseSet(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("moves stacks rooted on the old base", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("RemoteBranchExists", "develop").Return(true)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+		mockGit.On("GetAllStackParents").Return(map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+		}, nil)
+		mockGit.On("SetConfig", "branch.feature-a.stackparent", "develop").Return(nil)
+		mockGit.On("SetConfig", "stack.baseBranch", "develop").Return(nil)
+
+		err := runBaseSet(mockGit, "develop")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "SetConfig", "branch.feature-b.stackparent", mock.Anything)
+	})
+
+	t.Run("fetches base missing locally", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("RemoteBranchExists", "develop").Return(false).Once()
+		mockGit.On("FetchBranch", "develop").Return(nil)
+		mockGit.On("RemoteBranchExists", "develop").Return(true)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("develop")
+		mockGit.On("SetConfig", "stack.baseBranch", "develop").Return(nil)
+
+		err := runBaseSet(mockGit, "develop")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("rejects base missing on origin", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("RemoteBranchExists", "nope").Return(false)
+		mockGit.On("FetchBranch", "nope").Return(errors.New("couldn't find remote ref"))
+
+		err := runBaseSet(mockGit, "nope")
+
+		assert.EqualError(t, err, "branch nope does not exist on origin")
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+}
+
+func TestRunBaseDetect(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("unsets config and moves stacks back", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("develop")
+		mockGit.On("GetDefaultBranch").Return("main")
+		mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "develop"}, nil)
+		mockGit.On("SetConfig", "branch.feature-a.stackparent", "main").Return(nil)
+		mockGit.On("UnsetConfig", "stack.baseBranch").Return(nil)
+
+		err := runBaseDetect(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("nothing configured", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+
+		err := runBaseDetect(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "UnsetConfig", mock.Anything)
+	})
+}
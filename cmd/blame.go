@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	blameDepth int
+	blameTop   int
+)
+
+var blameCmd = &cobra.Command{
+	Use:   "blame",
+	Short: i18n.T("blame.short"),
+	Long:  i18n.T("blame.long"),
+	Example: `  # Summarize each branch in the current stack
+  stack blame
+
+  # Group by top-level directory only
+  stack blame --depth 1
+
+  # Example output:
+  #  main
+  #   |
+  #  feature-auth  +120 -30 in 5 file(s)
+  #      internal/auth   +90 -10  (3)
+  #      cmd             +30 -20  (2)`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runBlame(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	blameCmd.Flags().IntVar(&blameDepth, "depth", 0, "Group files by their first N directory levels (0 for the full directory)")
+	blameCmd.Flags().IntVar(&blameTop, "top", 5, "Directories listed per branch (0 for all)")
+}
+
+// dirStat is the change size of a branch in one directory
+type dirStat struct {
+	Dir     string
+	Files   int
+	Added   int
+	Deleted int
+}
+
+func runBlame(gitClient git.GitClient) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	tree, err := stack.BuildStackTreeForBranch(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to build stack tree: %w", err)
+	}
+	if tree == nil {
+		fmt.Printf("Branch '%s' is not in a stack.\n", ui.Branch(currentBranch))
+		return nil
+	}
+
+	fmt.Println()
+	printBlameTree(gitClient, tree, "", stack.GetBaseBranch(gitClient), currentBranch, false)
+	return nil
+}
+
+// printBlameTree prints the stack like 'stack show', with each branch's diff
+// against its parent summarized underneath
+func printBlameTree(gitClient git.GitClient, node *stack.TreeNode, parent, baseBranch, currentBranch string, isPipe bool) {
+	marker := ""
+	if node.Name == currentBranch {
+		marker = ui.CurrentBranchMarker()
+	}
+
+	if isPipe {
+		fmt.Printf("  %s\n", ui.Pipe())
+	}
+
+	if parent == "" {
+		fmt.Printf(" %s%s\n", ui.Branch(node.Name), marker)
+	} else {
+		// The base branch is compared as it is on origin, like sync rebases onto it
+		from := parent
+		if parent == baseBranch {
+			from = git.RemoteRef(baseBranch)
+		}
+		stats, err := gitClient.GetDiffStat(from, node.Name)
+		if err != nil {
+			fmt.Printf(" %s%s %s\n", ui.Branch(node.Name), marker, ui.Dim("(could not diff against "+from+")"))
+		} else {
+			dirs, added, deleted := groupByDir(stats, blameDepth)
+			fmt.Printf(" %s  %s%s\n", ui.Branch(node.Name), ui.Dim(fmt.Sprintf("+%d -%d in %d file(s)", added, deleted, len(stats))), marker)
+			printDirStats(dirs, blameTop)
+		}
+	}
+
+	for _, child := range node.Children {
+		printBlameTree(gitClient, child, node.Name, baseBranch, currentBranch, true)
+	}
+}
+
+// groupByDir sums file stats per directory, truncated to depth levels (0 keeps the
+// full directory), largest change first. Also returns the totals.
+func groupByDir(stats []git.FileStat, depth int) ([]dirStat, int, int) {
+	byDir := make(map[string]*dirStat)
+	added, deleted := 0, 0
+	for _, stat := range stats {
+		dir := path.Dir(stat.Path)
+		if depth > 0 && dir != "." {
+			if parts := strings.Split(dir, "/"); len(parts) > depth {
+				dir = strings.Join(parts[:depth], "/")
+			}
+		}
+		d, ok := byDir[dir]
+		if !ok {
+			d = &dirStat{Dir: dir}
+			byDir[dir] = d
+		}
+		d.Files++
+		d.Added += stat.Added
+		d.Deleted += stat.Deleted
+		added += stat.Added
+		deleted += stat.Deleted
+	}
+
+	dirs := make([]dirStat, 0, len(byDir))
+	for _, d := range byDir {
+		dirs = append(dirs, *d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		li, lj := dirs[i].Added+dirs[i].Deleted, dirs[j].Added+dirs[j].Deleted
+		if li != lj {
+			return li > lj
+		}
+		return dirs[i].Dir < dirs[j].Dir
+	})
+	return dirs, added, deleted
+}
+
+// printDirStats prints up to top directories (all if top is 0) under a branch
+func printDirStats(dirs []dirStat, top int) {
+	width := 0
+	for _, d := range dirs {
+		if len(d.Dir) > width {
+			width = len(d.Dir)
+		}
+	}
+
+	for i, d := range dirs {
+		if top > 0 && i == top {
+			fmt.Printf("     %s\n", ui.Dim(fmt.Sprintf("... and %d more", len(dirs)-top)))
+			break
+		}
+		fmt.Printf("     %-*s  %s\n", width, d.Dir, ui.Dim(fmt.Sprintf("+%d -%d  (%d)", d.Added, d.Deleted, d.Files)))
+	}
+}
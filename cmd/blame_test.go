@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupByDir(t *testing.T) {
+	stats := []git.FileStat{
+		{Path: "cmd/sync.go", Added: 10, Deleted: 2},
+		{Path: "internal/git/git.go", Added: 30},
+		{Path: "internal/git/interface.go", Added: 1},
+		{Path: "internal/stack/stack.go", Deleted: 4},
+		{Path: "go.mod", Added: 1},
+	}
+
+	t.Run("full directory", func(t *testing.T) {
+		dirs, added, deleted := groupByDir(stats, 0)
+
+		assert.Equal(t, 42, added)
+		assert.Equal(t, 6, deleted)
+		assert.Equal(t, []dirStat{
+			{Dir: "internal/git", Files: 2, Added: 31},
+			{Dir: "cmd", Files: 1, Added: 10, Deleted: 2},
+			{Dir: "internal/stack", Files: 1, Deleted: 4},
+			{Dir: ".", Files: 1, Added: 1},
+		}, dirs)
+	})
+
+	t.Run("truncated to depth", func(t *testing.T) {
+		dirs, _, _ := groupByDir(stats, 1)
+
+		assert.Equal(t, "internal", dirs[0].Dir)
+		assert.Equal(t, 3, dirs[0].Files)
+		assert.Len(t, dirs, 3)
+	})
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+var (
+	// Characters git never allows in a ref name, plus whitespace
+	invalidRefChars = regexp.MustCompile(`[\s~^:?*\[\\\x00-\x1f\x7f]+`)
+	repeatedDashes  = regexp.MustCompile(`-{2,}`)
+)
+
+// validateBranchName checks a new branch name before anything is created or
+// any config is written, so a bad name can't leave a half-updated stack behind
+func validateBranchName(gitClient git.GitClient, name string) error {
+	if name == "" {
+		return fmt.Errorf("branch name cannot be empty")
+	}
+
+	// Checked before calling git so the name isn't parsed as a flag
+	if strings.HasPrefix(name, "-") {
+		return invalidBranchName(name, "cannot start with '-'")
+	}
+
+	// check-ref-format --branch expands @{-N} to a previous branch instead of rejecting it
+	if name == "HEAD" || name == "@" || strings.Contains(name, "@{") {
+		return fmt.Errorf("invalid branch name %q: is reserved by git", name)
+	}
+
+	if local := git.TrimRemote(name); local != name {
+		return fmt.Errorf("invalid branch name %q: looks like a remote branch (try %q)", name, local)
+	}
+
+	if err := gitClient.CheckBranchName(name); err != nil {
+		return invalidBranchName(name, "")
+	}
+
+	return nil
+}
+
+// invalidBranchName builds the validation error, with a suggested fix when one can be derived
+func invalidBranchName(name, reason string) error {
+	msg := fmt.Sprintf("invalid branch name %q", name)
+	if reason != "" {
+		msg += ": " + reason
+	}
+	if suggestion := suggestBranchName(name); suggestion != "" && suggestion != name {
+		msg += fmt.Sprintf(" (try %q)", suggestion)
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// suggestBranchName rewrites name into something git accepts, or returns "" if nothing is left
+func suggestBranchName(name string) string {
+	s := invalidRefChars.ReplaceAllString(name, "-")
+
+	for strings.Contains(s, "..") {
+		s = strings.ReplaceAll(s, "..", ".")
+	}
+	for strings.Contains(s, "//") {
+		s = strings.ReplaceAll(s, "//", "/")
+	}
+
+	// Path components can't start with '.' or end with '.lock'
+	parts := strings.Split(s, "/")
+	kept := parts[:0]
+	for _, part := range parts {
+		part = strings.TrimLeft(part, ".-")
+		part = strings.TrimSuffix(part, ".lock")
+		part = strings.Trim(part, "-")
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	s = strings.Join(kept, "/")
+
+	s = repeatedDashes.ReplaceAllString(s, "-")
+	s = strings.TrimRight(s, ".")
+
+	if s == "HEAD" || s == "@" {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name        string
+		branchName  string
+		gitValid    bool
+		expectError string
+	}{
+		{
+			name:       "valid name",
+			branchName: "feature/login-form",
+			gitValid:   true,
+		},
+		{
+			name:        "rejected by git with suggestion",
+			branchName:  "fix login bug",
+			expectError: `invalid branch name "fix login bug" (try "fix-login-bug")`,
+		},
+		{
+			name:        "leading dash",
+			branchName:  "-feature",
+			expectError: `invalid branch name "-feature": cannot start with '-' (try "feature")`,
+		},
+		{
+			name:        "previous branch syntax",
+			branchName:  "@{-1}",
+			expectError: `invalid branch name "@{-1}": is reserved by git`,
+		},
+		{
+			name:        "remote prefix",
+			branchName:  "origin/feature",
+			expectError: `invalid branch name "origin/feature": looks like a remote branch (try "feature")`,
+		},
+		{
+			name:        "empty",
+			branchName:  "",
+			expectError: "branch name cannot be empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			if tt.gitValid {
+				mockGit.On("CheckBranchName", tt.branchName).Return(nil)
+			} else {
+				mockGit.On("CheckBranchName", mock.Anything).Return(errors.New("fatal: not a valid branch name")).Maybe()
+			}
+
+			err := validateBranchName(mockGit, tt.branchName)
+
+			if tt.expectError != "" {
+				assert.EqualError(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSuggestBranchName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"my feature", "my-feature"},
+		{"feature..x", "feature.x"},
+		{"feature/.hidden", "feature/hidden"},
+		{"feature.lock", "feature"},
+		{"feat: add ~thing^", "feat-add-thing"},
+		{"feature/", "feature"},
+		{"wip.", "wip"},
+		{"HEAD", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, suggestBranchName(tt.input))
+		})
+	}
+}
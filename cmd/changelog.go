@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+// changelogNoPR leaves PR titles and links out of the changelog
+var changelogNoPR bool
+
+var changelogCmd = &cobra.Command{
+	Use:   "changelog [branch]",
+	Short: i18n.T("changelog.short"),
+	Long:  i18n.T("changelog.long"),
+	Example: `  # Changelog of the stack up to the current branch
+  stack changelog
+
+  # Changelog up to the top of the stack, into the clipboard
+  stack changelog feature-auth-ui | pbcopy
+
+  # Without looking up PRs
+  stack changelog --no-pr`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		var githubClient github.GitHubClient
+		if !changelogNoPR {
+			githubClient = forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+		}
+
+		branch := ""
+		if len(args) == 1 {
+			branch = args[0]
+		}
+		if err := runChangelog(gitClient, githubClient, branch); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	changelogCmd.Flags().BoolVar(&changelogNoPR, "no-pr", false, "Leave out PR titles and links (faster)")
+}
+
+// changelogEntry is a branch of the stack and what it changes
+type changelogEntry struct {
+	Branch   string
+	PR       *github.PRInfo // nil when the branch has no PR or PRs weren't looked up
+	Subjects []string       // Commit subjects, oldest first
+}
+
+// runChangelog prints the changelog of the stack up to branch (the current branch
+// when empty). githubClient may be nil to leave PRs out.
+func runChangelog(gitClient git.GitClient, githubClient github.GitHubClient, branch string) error {
+	if branch == "" {
+		current, err := gitClient.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	}
+	chain, err := stack.GetStackChain(gitClient, branch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return &stack.NotInStackError{Branch: branch}
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	prs := make(map[string]*github.PRInfo)
+	if githubClient != nil {
+		found, err := syncer.LoadPRs(githubClient, chain[1:], prScope)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to look up PRs, leaving them out: %v\n", err)
+		} else {
+			prs = found
+		}
+	}
+
+	var entries []changelogEntry
+	for i, name := range chain[1:] {
+		if umbrellas[name] {
+			continue
+		}
+		// Compare the first branch with the base on origin, like sync rebases onto it
+		parent := chain[i]
+		if i == 0 {
+			parent = git.RemoteRef(parent)
+		}
+		subjects, err := commitSubjects(gitClient, parent, name)
+		if err != nil {
+			return fmt.Errorf("failed to list the commits of %s: %w", name, err)
+		}
+		entries = append(entries, changelogEntry{Branch: name, PR: prs[name], Subjects: subjects})
+	}
+
+	fmt.Print(formatChangelog(entries))
+	return nil
+}
+
+// commitSubjects returns the subjects of the commits in branch that aren't in
+// parent, oldest first, leaving out fixup! and squash! commits. Commits are
+// compared by patch, so a branch that still sits on an older version of its
+// parent doesn't list the parent's commits again.
+func commitSubjects(gitClient git.GitClient, parent, branch string) ([]string, error) {
+	commits, err := gitClient.GetUniqueCommitsByPatch(parent, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjects []string
+	for _, commit := range commits {
+		message, err := gitClient.GetCommitMessage(commit)
+		if err != nil {
+			return nil, err
+		}
+		subject, _, _ := strings.Cut(message, "\n")
+		if strings.HasPrefix(subject, "fixup! ") || strings.HasPrefix(subject, "squash! ") {
+			continue
+		}
+		subjects = append(subjects, subject)
+	}
+	return subjects, nil
+}
+
+// formatChangelog renders the entries as markdown, one section per branch
+func formatChangelog(entries []changelogEntry) string {
+	var b strings.Builder
+	for i, entry := range entries {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		switch {
+		case entry.PR != nil && entry.PR.URL != "":
+			fmt.Fprintf(&b, "### %s ([#%d](%s))\n\n", entry.PR.Title, entry.PR.Number, entry.PR.URL)
+		case entry.PR != nil:
+			fmt.Fprintf(&b, "### %s (#%d)\n\n", entry.PR.Title, entry.PR.Number)
+		default:
+			fmt.Fprintf(&b, "### `%s`\n\n", entry.Branch)
+		}
+		if len(entry.Subjects) == 0 {
+			b.WriteString("_No commits of its own._\n")
+			continue
+		}
+		for _, subject := range entry.Subjects {
+			fmt.Fprintf(&b, "- %s\n", subject)
+		}
+	}
+	return b.String()
+}
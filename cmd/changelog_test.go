@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitSubjects(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{"c1", "c2", "c3"}, nil)
+	mockGit.On("GetCommitMessage", "c1").Return("Add logout\n\nClears the session.", nil)
+	mockGit.On("GetCommitMessage", "c2").Return("fixup! Add logout", nil)
+	mockGit.On("GetCommitMessage", "c3").Return("Add logout button", nil)
+
+	subjects, err := commitSubjects(mockGit, "feature-a", "feature-b")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Add logout", "Add logout button"}, subjects)
+}
+
+func TestFormatChangelog(t *testing.T) {
+	entries := []changelogEntry{
+		{
+			Branch:   "feature-a",
+			PR:       &github.PRInfo{Number: 12, Title: "Add login", URL: "https://github.com/o/r/pull/12"},
+			Subjects: []string{"Add login form", "Validate email"},
+		},
+		{Branch: "feature-b", Subjects: []string{"Add logout"}},
+		{Branch: "feature-c", PR: &github.PRInfo{Number: 14, Title: "Tidy up"}},
+	}
+
+	expected := "### Add login ([#12](https://github.com/o/r/pull/12))\n\n" +
+		"- Add login form\n" +
+		"- Validate email\n" +
+		"\n" +
+		"### `feature-b`\n\n" +
+		"- Add logout\n" +
+		"\n" +
+		"### Tidy up (#14)\n\n" +
+		"_No commits of its own._\n"
+	assert.Equal(t, expected, formatChangelog(entries))
+}
+
+func TestRunChangelog(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name             string
+		branch           string
+		withGitHub       bool
+		setupMocks       func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectNotInStack bool
+	}{
+		{
+			name:       "looks up the PRs of the chain",
+			withGitHub: true,
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGit.On("GetUniqueCommitsByPatch", "origin/main", "feature-a").Return([]string{"c1"}, nil)
+				mockGit.On("GetCommitMessage", "c1").Return("Add login", nil)
+				mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{"c2"}, nil)
+				mockGit.On("GetCommitMessage", "c2").Return("Add logout", nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{
+					"feature-a": {Number: 12, Title: "Add login", URL: "https://github.com/o/r/pull/12"},
+				}, nil)
+			},
+		},
+		{
+			name:       "leaves PRs out when they can't be looked up",
+			withGitHub: true,
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGit.On("GetUniqueCommitsByPatch", "origin/main", "feature-a").Return([]string{"c1"}, nil)
+				mockGit.On("GetCommitMessage", "c1").Return("Add login", nil)
+				mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{"c2"}, nil)
+				mockGit.On("GetCommitMessage", "c2").Return("Add logout", nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{}, errors.New("gh failed"))
+			},
+		},
+		{
+			name: "without PRs",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGit.On("GetUniqueCommitsByPatch", "origin/main", "feature-a").Return([]string{"c1"}, nil)
+				mockGit.On("GetCommitMessage", "c1").Return("Add login", nil)
+				mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{"c2"}, nil)
+				mockGit.On("GetCommitMessage", "c2").Return("Add logout", nil)
+			},
+		},
+		{
+			name:   "branch not in a stack",
+			branch: "hotfix",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+			},
+			expectNotInStack: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			tt.setupMocks(mockGit, mockGH)
+
+			var githubClient github.GitHubClient
+			if tt.withGitHub {
+				githubClient = mockGH
+			}
+			err := runChangelog(mockGit, githubClient, tt.branch)
+
+			if tt.expectNotInStack {
+				var notInStack *stack.NotInStackError
+				assert.ErrorAs(t, err, &notInStack)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	checkPrePush   bool
+	checkPreCommit bool
+	checkJSON      bool
+)
+
+// zeroSHA is what git passes in the pre-push hook for a ref that doesn't exist
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: i18n.T("check.short"),
+	Long:  i18n.T("check.long"),
+	Example: `  # Check the stack
+  stack check
+
+  # Machine-readable findings for CI
+  stack check --json
+
+  # What the pre-push hook runs
+  stack check --pre-push origin <url> < refs`,
+	Args: cobra.ArbitraryArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		// Keep color codes out of the JSON messages
+		if checkJSON {
+			ui.SetNoColor(true)
+		}
+
+		if err := runCheck(gitClient, args, stdinReader); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	checkCmd.Flags().BoolVar(&checkPrePush, "pre-push", false, "Check the refs being pushed, read from stdin as passed to the pre-push hook")
+	checkCmd.Flags().BoolVar(&checkPreCommit, "pre-commit", false, "Warn when committing directly on the base branch while stacks exist")
+	checkCmd.Flags().BoolVar(&checkJSON, "json", false, "Print the findings as JSON")
+}
+
+// checkFinding is a problem found by 'stack check'. Errors fail the check,
+// warnings are only reported.
+type checkFinding struct {
+	Level   string `json:"level"`
+	Check   string `json:"check"`
+	Branch  string `json:"branch,omitempty"`
+	Message string `json:"message"`
+}
+
+// pushRef is one line of the pre-push hook input
+type pushRef struct {
+	LocalRef  string
+	LocalSHA  string
+	RemoteRef string
+	RemoteSHA string
+}
+
+func runCheck(gitClient git.GitClient, args []string, input io.Reader) error {
+	var findings []checkFinding
+	var err error
+	switch {
+	case checkPrePush:
+		remote := git.Remote
+		if len(args) > 0 {
+			remote = args[0]
+		}
+		refs, parseErr := parsePushRefs(input)
+		if parseErr != nil {
+			return fmt.Errorf("failed to read refs to push: %w", parseErr)
+		}
+		findings, err = checkPush(gitClient, remote, refs)
+	case checkPreCommit:
+		findings, err = checkCommitOnBase(gitClient)
+	default:
+		findings, err = checkStack(gitClient)
+	}
+	if err != nil {
+		return err
+	}
+
+	failed := false
+	for _, f := range findings {
+		if f.Level == "error" {
+			failed = true
+		}
+	}
+
+	if checkJSON {
+		if err := printCheckJSON(findings, !failed); err != nil {
+			return err
+		}
+		if failed {
+			return ui.Reported(errors.New("stack check failed"))
+		}
+		return nil
+	}
+
+	for _, f := range findings {
+		icon := ui.WarningIcon()
+		if f.Level == "error" {
+			icon = ui.ErrorIcon()
+		}
+		if f.Branch != "" {
+			fmt.Fprintf(os.Stderr, "%s %s: %s\n", icon, ui.Branch(f.Branch), f.Message)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s %s\n", icon, f.Message)
+		}
+	}
+
+	if failed {
+		if checkPrePush {
+			fmt.Fprintf(os.Stderr, "\nPush refused by 'stack check'. Skip the check with '%s'\n", ui.Command("git push --no-verify"))
+		}
+		return ui.Reported(errors.New("stack check failed"))
+	}
+	if !checkPrePush && !checkPreCommit && len(findings) == 0 {
+		fmt.Printf("%s Stack is consistent\n", ui.SuccessIcon())
+	}
+	return nil
+}
+
+// printCheckJSON prints the findings as {"ok": ..., "findings": [...]}
+func printCheckJSON(findings []checkFinding, ok bool) error {
+	if findings == nil {
+		findings = []checkFinding{}
+	}
+	out, err := json.MarshalIndent(struct {
+		OK       bool           `json:"ok"`
+		Findings []checkFinding `json:"findings"`
+	}{ok, findings}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode findings: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// parsePushRefs reads the "<local ref> <local sha> <remote ref> <remote sha>"
+// lines git passes to the pre-push hook
+func parsePushRefs(input io.Reader) ([]pushRef, error) {
+	var refs []pushRef
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 4 {
+			continue
+		}
+		refs = append(refs, pushRef{LocalRef: fields[0], LocalSHA: fields[1], RemoteRef: fields[2], RemoteSHA: fields[3]})
+	}
+	return refs, scanner.Err()
+}
+
+// checkPush checks the branches about to be pushed to remote
+func checkPush(gitClient git.GitClient, remote string, refs []pushRef) ([]checkFinding, error) {
+	baseBranch := stack.GetBaseBranch(gitClient)
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	var findings []checkFinding
+	var pushed []string
+	for _, ref := range refs {
+		// Deletions and tags aren't stack branches
+		if ref.LocalSHA == zeroSHA || !strings.HasPrefix(ref.RemoteRef, "refs/heads/") {
+			continue
+		}
+		branch := strings.TrimPrefix(ref.LocalRef, "refs/heads/")
+		remoteBranch := strings.TrimPrefix(ref.RemoteRef, "refs/heads/")
+
+		if remoteBranch == baseBranch && branch != baseBranch {
+			findings = append(findings, checkFinding{
+				Level:   "error",
+				Check:   "base-push",
+				Branch:  branch,
+				Message: fmt.Sprintf("pushes onto the base branch %s; stack branches are merged through their PRs", ui.Branch(baseBranch)),
+			})
+			continue
+		}
+
+		if ref.RemoteSHA != zeroSHA {
+			if f := checkStalePush(gitClient, remote, remoteBranch, ref); f != nil {
+				findings = append(findings, *f)
+			}
+		}
+		if _, inStack := parents[branch]; inStack {
+			pushed = append(pushed, branch)
+		}
+	}
+
+	return append(findings, checkPRBases(gitClient, parents, pushed)...), nil
+}
+
+// checkStalePush reports a push that would overwrite remote commits the local
+// branch doesn't have and that are newer than it, or that weren't fetched yet
+func checkStalePush(gitClient git.GitClient, remote, remoteBranch string, ref pushRef) *checkFinding {
+	remoteName := remote + "/" + remoteBranch
+
+	remoteTime, err := gitClient.GetCommitTime(ref.RemoteSHA)
+	if err != nil {
+		return &checkFinding{
+			Level:   "error",
+			Check:   "stale-push",
+			Branch:  remoteBranch,
+			Message: i18n.T("check.notFetched", ui.Branch(remoteName), ui.Command("stack sync")),
+		}
+	}
+
+	// A fast-forward keeps everything on the remote
+	if mergeBase, err := gitClient.GetMergeBase(ref.LocalSHA, ref.RemoteSHA); err == nil && mergeBase == ref.RemoteSHA {
+		return nil
+	}
+
+	// A rebased branch is rewritten after its remote, so only an older local
+	// branch overwriting a newer remote one is stale
+	localTime, err := gitClient.GetCommitTime(ref.LocalSHA)
+	if err != nil || !remoteTime.After(localTime) {
+		return nil
+	}
+	return &checkFinding{
+		Level:   "error",
+		Check:   "stale-push",
+		Branch:  remoteBranch,
+		Message: i18n.T("check.remoteNewer", ui.Branch(remoteName), ui.Command("stack sync")),
+	}
+}
+
+// checkStack checks every branch of the stack
+func checkStack(gitClient git.GitClient) ([]checkFinding, error) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	findings := checkSyncState(gitClient)
+	graphFindings, err := checkGraph(gitClient, parents)
+	if err != nil {
+		return nil, err
+	}
+	findings = append(findings, graphFindings...)
+	findings = append(findings, checkBaseCommits(gitClient, len(parents))...)
+
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	return append(findings, checkPRBases(gitClient, parents, branches)...), nil
+}
+
+// checkCommitOnBase warns when the current branch is the base branch while
+// stacks exist, as the commit most likely belongs in a stack branch
+func checkCommitOnBase(gitClient git.GitClient) ([]checkFinding, error) {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	baseBranch := stack.GetBaseBranch(gitClient)
+	if currentBranch != baseBranch {
+		return nil, nil
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	if len(parents) == 0 {
+		return nil, nil
+	}
+
+	return []checkFinding{{
+		Level:   "warning",
+		Check:   "base-commit",
+		Branch:  baseBranch,
+		Message: fmt.Sprintf("committing directly on the base branch while %d stack branch(es) exist; start a stack branch with '%s' instead, or move the commit there afterwards with '%s'", len(parents), ui.Command("stack new <name>"), ui.Command("stack uplift <name>")),
+	}}, nil
+}
+
+// checkBaseCommits warns about local commits on the base branch that aren't on
+// origin while stacks exist, e.g. a commit meant for a stack branch
+func checkBaseCommits(gitClient git.GitClient, stackBranches int) []checkFinding {
+	if stackBranches == 0 {
+		return nil
+	}
+	baseBranch := stack.GetBaseBranch(gitClient)
+	commits, err := gitClient.GetUniqueCommits(git.RemoteRef(baseBranch), baseBranch)
+	if err != nil || len(commits) == 0 {
+		return nil
+	}
+	return []checkFinding{{
+		Level:   "warning",
+		Check:   "base-commits",
+		Branch:  baseBranch,
+		Message: fmt.Sprintf("has %d local commit(s) that aren't on %s; move them into a stack branch with '%s'", len(commits), ui.Branch(git.RemoteRef(baseBranch)), ui.Command("stack uplift <name>")),
+	}}
+}
+
+// checkSyncState reports a sync, rebase plan or git operation that was left half-way
+func checkSyncState(gitClient git.GitClient) []checkFinding {
+	var findings []checkFinding
+	if branch := gitClient.GetConfig(syncer.ConfigOriginalBranch); branch != "" {
+		findings = append(findings, checkFinding{
+			Level:   "error",
+			Check:   "sync-state",
+			Message: fmt.Sprintf("a sync started from %s was interrupted; finish it with '%s' or '%s'", ui.Branch(branch), ui.Command("stack sync --resume"), ui.Command("stack sync --abort")),
+		})
+	}
+	if gitClient.GetConfig(configRebasePlanTodo) != "" {
+		findings = append(findings, checkFinding{
+			Level:   "error",
+			Check:   "sync-state",
+			Message: fmt.Sprintf("a rebase plan is in progress; finish it with '%s' or '%s'", ui.Command("stack rebase --continue"), ui.Command("stack rebase --abort")),
+		})
+	}
+	// Sync and rebase plans stop in a git rebase, so that's already covered
+	if operation := gitClient.GetOperationInProgress(); operation != nil && len(findings) == 0 {
+		findings = append(findings, checkFinding{
+			Level:   "error",
+			Check:   "sync-state",
+			Branch:  operation.Branch,
+			Message: fmt.Sprintf("a %s is in progress; resolve or abort it first", operation.Kind),
+		})
+	}
+	return findings
+}
+
+// checkGraph reports cycles in the stack parents, parents that don't exist, and
+// stack config left behind by deleted branches
+func checkGraph(gitClient git.GitClient, parents map[string]string) ([]checkFinding, error) {
+	baseBranch := stack.GetBaseBranch(gitClient)
+	localBranches, err := gitClient.ListBranches()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	exists := make(map[string]bool, len(localBranches))
+	for _, branch := range localBranches {
+		exists[branch] = true
+	}
+
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var findings []checkFinding
+	reported := make(map[string]bool)
+	for _, branch := range branches {
+		if cycle := findCycle(parents, branch); cycle != nil && !reported[cycle[0]] {
+			reported[cycle[0]] = true
+			findings = append(findings, checkFinding{
+				Level:   "error",
+				Check:   "cycle",
+				Branch:  cycle[0],
+				Message: fmt.Sprintf("stack parents form a cycle: %s; fix it with '%s'", strings.Join(cycle, " → "), ui.Command("stack reparent")),
+			})
+		}
+
+		parent := parents[branch]
+		if parent != baseBranch && !exists[parent] {
+			findings = append(findings, checkFinding{
+				Level:   "error",
+				Check:   "missing-parent",
+				Branch:  branch,
+				Message: fmt.Sprintf("parent %s doesn't exist; move the branch with '%s'", ui.Branch(parent), ui.Command("stack reparent")),
+			})
+		}
+	}
+
+	orphaned, _ := gitClient.GetOrphanedStackParents()
+	var stale []string
+	for branch := range orphaned {
+		stale = append(stale, branch)
+	}
+	sort.Strings(stale)
+	for _, branch := range stale {
+		findings = append(findings, checkFinding{
+			Level:   "warning",
+			Check:   "stale-config",
+			Branch:  branch,
+			Message: fmt.Sprintf("branch no longer exists but still has stack config; '%s' removes it", ui.Command("stack clean-config")),
+		})
+	}
+	return findings, nil
+}
+
+// findCycle returns the cycle reached by following parents from branch, starting
+// and ending with its alphabetically first branch, or nil if the walk ends
+func findCycle(parents map[string]string, branch string) []string {
+	seen := make(map[string]int)
+	var path []string
+	for current := branch; ; {
+		if i, ok := seen[current]; ok {
+			cycle := path[i:]
+			start := 0
+			for j, name := range cycle {
+				if name < cycle[start] {
+					start = j
+				}
+			}
+			ordered := append(append([]string{}, cycle[start:]...), cycle[:start]...)
+			return append(ordered, ordered[0])
+		}
+		parent, ok := parents[current]
+		if !ok {
+			return nil
+		}
+		seen[current] = len(path)
+		path = append(path, current)
+		current = parent
+	}
+}
+
+// checkPRBases reports branches whose PR, as last seen by sync, targets
+// something other than the branch's parent
+func checkPRBases(gitClient git.GitClient, parents map[string]string, branches []string) []checkFinding {
+	if len(branches) == 0 {
+		return nil
+	}
+	pins, _ := gitClient.GetAllStackPRs()
+	prBases, _ := gitClient.GetAllStackPRBases()
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	sorted := append([]string(nil), branches...)
+	sort.Strings(sorted)
+
+	var findings []checkFinding
+	for _, branch := range sorted {
+		number, pinned := pins[branch]
+		prBase, known := prBases[branch]
+		if !pinned || !known || flatPRs {
+			continue
+		}
+		if want := syncer.PRBaseFor(parents[branch], parents, umbrellas); prBase != want {
+			findings = append(findings, checkFinding{
+				Level:   "warning",
+				Check:   "pr-base",
+				Branch:  branch,
+				Message: fmt.Sprintf("PR #%d targets %s but the branch's parent is %s; '%s' retargets it", number, ui.Branch(prBase), ui.Branch(want), ui.Command("stack sync")),
+			})
+		}
+	}
+	return findings
+}
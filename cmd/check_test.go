@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	localSHA  = "1111111111111111111111111111111111111111"
+	remoteSHA = "2222222222222222222222222222222222222222"
+)
+
+func TestParsePushRefs(t *testing.T) {
+	input := "refs/heads/feature-a " + localSHA + " refs/heads/feature-a " + remoteSHA + "\n" +
+		"\n" +
+		"refs/tags/v1 " + localSHA + " refs/tags/v1 " + zeroSHA + "\n"
+
+	refs, err := parsePushRefs(strings.NewReader(input))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []pushRef{
+		{LocalRef: "refs/heads/feature-a", LocalSHA: localSHA, RemoteRef: "refs/heads/feature-a", RemoteSHA: remoteSHA},
+		{LocalRef: "refs/tags/v1", LocalSHA: localSHA, RemoteRef: "refs/tags/v1", RemoteSHA: zeroSHA},
+	}, refs)
+}
+
+func TestCheckPush(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	now := time.Now()
+
+	tests := []struct {
+		name            string
+		local           string
+		remote          string
+		remoteSHA       string
+		parents         map[string]string
+		setupMocks      func(*testutil.MockGitClient)
+		expected        []string
+		messageContains string
+	}{
+		{
+			name:      "new branch passes",
+			local:     "feature-a",
+			remote:    "feature-a",
+			remoteSHA: zeroSHA,
+		},
+		{
+			name:      "refuses pushing a stack branch onto the base branch",
+			local:     "feature-a",
+			remote:    "main",
+			remoteSHA: remoteSHA,
+			expected:  []string{"base-push:error"},
+		},
+		{
+			name:      "fast-forward passes",
+			local:     "feature-a",
+			remote:    "feature-a",
+			remoteSHA: remoteSHA,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitTime", remoteSHA).Return(now, nil)
+				mockGit.On("GetMergeBase", localSHA, remoteSHA).Return(remoteSHA, nil)
+			},
+		},
+		{
+			name:      "rebased branch passes",
+			local:     "feature-a",
+			remote:    "feature-a",
+			remoteSHA: remoteSHA,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitTime", remoteSHA).Return(now.Add(-time.Hour), nil)
+				mockGit.On("GetCommitTime", localSHA).Return(now, nil)
+				mockGit.On("GetMergeBase", localSHA, remoteSHA).Return("fork", nil)
+			},
+		},
+		{
+			name:      "refuses overwriting a newer remote branch",
+			local:     "feature-a",
+			remote:    "feature-a",
+			remoteSHA: remoteSHA,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitTime", remoteSHA).Return(now, nil)
+				mockGit.On("GetCommitTime", localSHA).Return(now.Add(-time.Hour), nil)
+				mockGit.On("GetMergeBase", localSHA, remoteSHA).Return("fork", nil)
+			},
+			expected: []string{"stale-push:error"},
+		},
+		{
+			name:      "refuses overwriting unfetched remote commits",
+			local:     "feature-a",
+			remote:    "feature-a",
+			remoteSHA: remoteSHA,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitTime", remoteSHA).Return(time.Time{}, errors.New("bad object"))
+			},
+			expected:        []string{"stale-push:error"},
+			messageContains: "haven't been fetched",
+		},
+		{
+			name:      "warns when PR base doesn't match parent",
+			local:     "feature-b",
+			remote:    "feature-b",
+			remoteSHA: zeroSHA,
+			parents:   map[string]string{"feature-a": "main", "feature-b": "main"},
+			expected:  []string{"pr-base:warning"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parents := tt.parents
+			if parents == nil {
+				parents = map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+			}
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(parents, nil)
+			mockGit.On("GetAllStackPRs").Return(map[string]int{"feature-b": 13}, nil).Maybe()
+			mockGit.On("GetAllStackPRBases").Return(map[string]string{"feature-b": "feature-a"}, nil).Maybe()
+			mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil).Maybe()
+			if tt.setupMocks != nil {
+				tt.setupMocks(mockGit)
+			}
+
+			refs := []pushRef{{LocalRef: "refs/heads/" + tt.local, LocalSHA: localSHA, RemoteRef: "refs/heads/" + tt.remote, RemoteSHA: tt.remoteSHA}}
+			findings, err := checkPush(mockGit, "origin", refs)
+
+			assert.NoError(t, err)
+			var checks []string
+			for _, f := range findings {
+				checks = append(checks, f.Check+":"+f.Level)
+			}
+			assert.Equal(t, tt.expected, checks)
+			if tt.messageContains != "" {
+				assert.Contains(t, findings[0].Message, tt.messageContains)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunCheckPrePushFailsOnErrors(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	checkPrePush = true
+	defer func() { checkPrePush = false }()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+
+	input := "refs/heads/feature-a " + localSHA + " refs/heads/main " + remoteSHA + "\n"
+	err := runCheck(mockGit, []string{"origin", "url"}, strings.NewReader(input))
+
+	assert.True(t, isAlreadyReported(err))
+}
+
+func TestFindCycle(t *testing.T) {
+	parents := map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-d",
+		"feature-c": "feature-b",
+		"feature-d": "feature-c",
+		"feature-e": "feature-c",
+	}
+
+	assert.Nil(t, findCycle(parents, "feature-a"))
+	assert.Equal(t, []string{"feature-b", "feature-d", "feature-c", "feature-b"}, findCycle(parents, "feature-c"))
+	assert.Equal(t, []string{"feature-b", "feature-d", "feature-c", "feature-b"}, findCycle(parents, "feature-e"))
+}
+
+func TestCheckStack(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	cleanState := func(mockGit *testutil.MockGitClient) {
+		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+		mockGit.On("GetConfig", "stack.rebasePlan.todo").Return("")
+		mockGit.On("GetOperationInProgress").Return(nil)
+	}
+
+	tests := []struct {
+		name       string
+		parents    map[string]string
+		setupMocks func(*testutil.MockGitClient)
+		expected   []string
+	}{
+		{
+			name:       "consistent stack has no findings",
+			parents:    map[string]string{"feature-a": "main", "feature-b": "feature-a"},
+			setupMocks: cleanState,
+		},
+		{
+			name:       "reports cycles and missing parents",
+			parents:    map[string]string{"feature-a": "feature-b", "feature-b": "feature-a", "feature-c": "gone"},
+			setupMocks: cleanState,
+			expected:   []string{"cycle:feature-a:error", "missing-parent:feature-c:error"},
+		},
+		{
+			name:    "reports an interrupted sync",
+			parents: map[string]string{"feature-a": "main"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", "stack.sync.originalBranch").Return("feature-a")
+				mockGit.On("GetConfig", "stack.rebasePlan.todo").Return("")
+				mockGit.On("GetOperationInProgress").Return(&git.Operation{Kind: "rebase", Branch: "feature-a"})
+			},
+			expected: []string{"sync-state::error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(tt.parents, nil)
+			mockGit.On("ListBranches").Return([]string{"main", "feature-a", "feature-b"}, nil)
+			mockGit.On("GetOrphanedStackParents").Return(map[string]string{}, nil)
+			mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+			mockGit.On("GetAllStackPRBases").Return(map[string]string{}, nil)
+			mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+			mockGit.On("GetUniqueCommits", "origin/main", "main").Return([]string{}, nil)
+			tt.setupMocks(mockGit)
+
+			findings, err := checkStack(mockGit)
+
+			assert.NoError(t, err)
+			var checks []string
+			for _, f := range findings {
+				checks = append(checks, f.Check+":"+f.Branch+":"+f.Level)
+			}
+			assert.Equal(t, tt.expected, checks)
+		})
+	}
+}
+
+func TestCheckCommitOnBase(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		currentBranch string
+		expected      []string
+	}{
+		{
+			name:          "warns on the base branch while stacks exist",
+			currentBranch: "main",
+			expected:      []string{"base-commit:warning"},
+		},
+		{
+			name:          "stays quiet on a stack branch",
+			currentBranch: "feature-a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return(tt.currentBranch, nil)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil).Maybe()
+
+			findings, err := checkCommitOnBase(mockGit)
+
+			assert.NoError(t, err)
+			var checks []string
+			for _, f := range findings {
+				checks = append(checks, f.Check+":"+f.Level)
+			}
+			assert.Equal(t, tt.expected, checks)
+		})
+	}
+}
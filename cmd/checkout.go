@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// checkoutAll is the --all flag of checkout
+var checkoutAll bool
+
+var checkoutCmd = &cobra.Command{
+	Use:     "checkout <branch>",
+	Aliases: []string{"co"},
+	Short:   i18n.T("checkout.short"),
+	Long:    i18n.T("checkout.long"),
+	Example: `  # Check out feature-payments-api from its stack
+  stack checkout api
+
+  # Look in every stack
+  stack co --all login`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runCheckout(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	checkoutCmd.Flags().BoolVar(&checkoutAll, "all", false, "Match the branches of every stack, not only the current one")
+}
+
+func runCheckout(gitClient git.GitClient, query string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	var candidates []string
+	if _, inStack := parents[currentBranch]; inStack && !checkoutAll {
+		candidates = stack.Members(parents, stack.RootOf(parents, currentBranch))
+	} else {
+		for branch := range parents {
+			candidates = append(candidates, branch)
+		}
+		sort.Strings(candidates)
+	}
+
+	matches := bestBranchMatches(query, candidates)
+	if len(matches) == 0 {
+		if !checkoutAll && len(candidates) < len(parents) {
+			return fmt.Errorf("no branch of this stack matches %q (use --all to look in every stack)", query)
+		}
+		return fmt.Errorf("no stack branch matches %q", query)
+	}
+
+	target := matches[0]
+	if len(matches) > 1 {
+		fmt.Printf("Several branches match %q:\n", query)
+		for i, branch := range matches {
+			fmt.Printf("  %d) %s\n", i+1, ui.Branch(branch))
+		}
+		fmt.Printf("\nSelect branch (1-%d): ", len(matches))
+
+		input, err := bufio.NewReader(stdinReader).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+		selection, err := strconv.Atoi(input)
+		if err != nil || selection < 1 || selection > len(matches) {
+			return fmt.Errorf("invalid selection: %s", input)
+		}
+		target = matches[selection-1]
+	}
+
+	if target == currentBranch {
+		fmt.Printf("Already on %s\n", ui.Branch(target))
+		return nil
+	}
+	if err := gitClient.CheckoutBranch(target); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", target, err)
+	}
+	if !dryRun {
+		fmt.Printf("Switched to %s\n", ui.Branch(target))
+	}
+	return nil
+}
+
+// Kinds of match between a query and a branch name, best first
+const (
+	matchExact = iota
+	matchPrefix
+	matchSubstring
+	matchLetters
+	matchNone
+)
+
+// branchMatch returns how query matches name, ignoring case
+func branchMatch(query, name string) int {
+	query, name = strings.ToLower(query), strings.ToLower(name)
+	switch {
+	case name == query:
+		return matchExact
+	case strings.HasPrefix(name, query):
+		return matchPrefix
+	case strings.Contains(name, query):
+		return matchSubstring
+	}
+
+	// The query's letters in order, with anything in between
+	rest := name
+	for _, r := range query {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return matchNone
+		}
+		rest = rest[i+len(string(r)):]
+	}
+	return matchLetters
+}
+
+// bestBranchMatches returns the candidates that match query best, in their
+// order: one branch when the match is clear, several when it's ambiguous, none
+// when nothing matches
+func bestBranchMatches(query string, candidates []string) []string {
+	best := matchNone
+	var matches []string
+	for _, name := range candidates {
+		kind := branchMatch(query, name)
+		if kind < best {
+			best = kind
+			matches = nil
+		}
+		if kind == best && kind != matchNone {
+			matches = append(matches, name)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBestBranchMatches(t *testing.T) {
+	candidates := []string{"auth-login", "auth-logout", "payments-api", "payments"}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"payments", []string{"payments"}},
+		{"PAYMENTS-API", []string{"payments-api"}},
+		{"auth", []string{"auth-login", "auth-logout"}},
+		{"logout", []string{"auth-logout"}},
+		{"pay-api", []string{"payments-api"}},
+		{"xyz", nil},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, bestBranchMatches(tt.query, candidates), tt.query)
+	}
+}
+
+func TestRunCheckout(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		currentBranch string
+		query         string
+		all           bool
+		input         string
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "checks out the best match in the current stack",
+			currentBranch: "payments",
+			query:         "api",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "payments-api").Return(nil)
+			},
+		},
+		{
+			name:          "ignores other stacks without --all",
+			currentBranch: "payments",
+			query:         "logout",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CheckoutBranch: auth-logout is in another stack
+			},
+			expectError:   true,
+			errorContains: "--all",
+		},
+		{
+			name:          "looks in every stack with --all",
+			currentBranch: "payments",
+			query:         "logout",
+			all:           true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "auth-logout").Return(nil)
+			},
+		},
+		{
+			name:          "looks in every stack outside a stack",
+			currentBranch: "main",
+			query:         "payments",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "payments").Return(nil)
+			},
+		},
+		{
+			name:          "prompts when ambiguous",
+			currentBranch: "auth-login",
+			query:         "auth",
+			input:         "2\n",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "auth-logout").Return(nil)
+			},
+		},
+		{
+			name:          "already on the branch",
+			currentBranch: "payments-api",
+			query:         "api",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CheckoutBranch: payments-api is already checked out
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkoutAll = tt.all
+			defer func() { checkoutAll = false }()
+			if tt.input != "" {
+				stdinReader = strings.NewReader(tt.input)
+				defer func() { stdinReader = os.Stdin }()
+			}
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return(tt.currentBranch, nil)
+			mockGit.On("GetAllStackParents").Return(map[string]string{
+				"auth-login":   "main",
+				"auth-logout":  "auth-login",
+				"payments":     "main",
+				"payments-api": "payments",
+			}, nil)
+			tt.setupMocks(mockGit)
+
+			err := runCheckout(mockGit, tt.query)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/javoire/stackinator/internal/syncer"
+
+// configWaitForCI enables --wait-for-ci by default for a repo
+const configWaitForCI = "stack.sync.waitForCI"
+
+// configCITimeout sets the default for --ci-timeout (a Go duration, e.g. "45m")
+const configCITimeout = "stack.sync.ciTimeout"
+
+var (
+	// syncWaitForCI waits for a branch's checks to pass before pushing its children
+	syncWaitForCI bool
+	// syncCITimeout bounds each wait
+	syncCITimeout = syncer.DefaultCITimeout
+)
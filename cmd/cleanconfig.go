@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var cleanConfigCmd = &cobra.Command{
+	Use:   "clean-config",
+	Short: i18n.T("cleanConfig.short"),
+	Long:  i18n.T("cleanConfig.long"),
+	Example: `  # Remove orphaned stack config
+  stack clean-config
+
+  # Show what would be removed
+  stack clean-config --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runCleanConfig(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runCleanConfig(gitClient git.GitClient) error {
+	keys, err := gitClient.GetStackConfigKeys()
+	if err != nil {
+		return fmt.Errorf("failed to read stack config: %w", err)
+	}
+
+	// Group keys by branch so each branch is only checked once
+	byBranch := make(map[string][]string)
+	for _, key := range keys {
+		if branch := stackConfigBranch(key); branch != "" {
+			byBranch[branch] = append(byBranch[branch], key)
+		}
+	}
+
+	var orphaned []string
+	for branch := range byBranch {
+		if !gitClient.BranchExists(branch) {
+			orphaned = append(orphaned, branch)
+		}
+	}
+	sort.Strings(orphaned)
+
+	if len(orphaned) == 0 {
+		fmt.Println("No orphaned stack config found.")
+		return nil
+	}
+
+	fmt.Printf("Found stack config for %d branch(es) that no longer exist:\n", len(orphaned))
+	removed := 0
+	for _, branch := range orphaned {
+		fmt.Printf("  %s\n", ui.Branch(branch))
+		branchKeys := byBranch[branch]
+		sort.Strings(branchKeys)
+		for _, key := range branchKeys {
+			if err := gitClient.UnsetConfig(key); err != nil {
+				fmt.Fprintf(os.Stderr, "    Warning: failed to remove %s: %v\n", key, err)
+				continue
+			}
+			if !dryRun {
+				fmt.Printf("    %s\n", ui.Dim("removed "+key))
+			}
+			removed++
+		}
+	}
+
+	if !dryRun {
+		fmt.Println(ui.Success(fmt.Sprintf("Removed %d stack config key(s)", removed)))
+	}
+	return nil
+}
+
+// stackConfigBranch extracts the branch name from a branch.<name>.<key> config key.
+// Branch names may contain dots, so the key is split at the last one.
+func stackConfigBranch(key string) string {
+	if !strings.HasPrefix(key, "branch.") {
+		return ""
+	}
+	rest := strings.TrimPrefix(key, "branch.")
+	i := strings.LastIndex(rest, ".")
+	if i <= 0 {
+		return ""
+	}
+	return rest[:i]
+}
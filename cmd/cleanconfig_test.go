@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCleanConfig(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("removes config of deleted branches", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStackConfigKeys").Return([]string{
+			"branch.feature-a.stackparent",
+			"branch.release.1.stackparent",
+			"branch.release.1.stackpr",
+			"branch.gone.stackparent",
+		}, nil)
+		mockGit.On("BranchExists", "feature-a").Return(true)
+		mockGit.On("BranchExists", "release.1").Return(false)
+		mockGit.On("BranchExists", "gone").Return(false)
+		mockGit.On("UnsetConfig", "branch.release.1.stackparent").Return(nil)
+		mockGit.On("UnsetConfig", "branch.release.1.stackpr").Return(nil)
+		mockGit.On("UnsetConfig", "branch.gone.stackparent").Return(nil)
+
+		err := runCleanConfig(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "UnsetConfig", "branch.feature-a.stackparent")
+	})
+
+	t.Run("nothing to clean", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStackConfigKeys").Return([]string{"branch.feature-a.stackparent"}, nil)
+		mockGit.On("BranchExists", "feature-a").Return(true)
+
+		err := runCleanConfig(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+}
+
+func TestStackConfigBranch(t *testing.T) {
+	assert.Equal(t, "feature-a", stackConfigBranch("branch.feature-a.stackparent"))
+	assert.Equal(t, "release.1", stackConfigBranch("branch.release.1.stackpr"))
+	assert.Equal(t, "", stackConfigBranch("stack.baseBranch"))
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+// configCleanIgnore lists paths, separated by spaces, whose changes don't count
+// when checking for uncommitted changes, so sync doesn't stash around files that
+// are always dirty. Pathspecs like 'generated/*' work too.
+const configCleanIgnore = "stack.cleanIgnore"
+
+// configureCleanIgnore reads the paths ignored by the uncommitted changes check
+func configureCleanIgnore(gitClient git.GitClient) {
+	git.CleanIgnorePaths = strings.Fields(gitClient.GetConfig(configCleanIgnore))
+}
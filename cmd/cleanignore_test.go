@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureCleanIgnore(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { git.CleanIgnorePaths = nil }()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", configCleanIgnore).Return(" .env  generated/* ").Once()
+	mockGit.On("GetConfig", configCleanIgnore).Return("").Once()
+
+	configureCleanIgnore(mockGit)
+	assert.Equal(t, []string{".env", "generated/*"}, git.CleanIgnorePaths)
+
+	configureCleanIgnore(mockGit)
+	assert.Empty(t, git.CleanIgnorePaths)
+	mockGit.AssertExpectations(t)
+}
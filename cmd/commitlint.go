@@ -0,0 +1,32 @@
+package cmd
+
+import "fmt"
+
+// configCommitLint is a shell command sync runs on the message of each commit
+// before pushing, with the message on stdin; a non-zero exit fails the commit
+const configCommitLint = "stack.commitLint.command"
+
+// configCommitLintMode is "fail" (the default) to stop the sync when a commit fails
+// the linter, or "warn" to only report it
+const configCommitLintMode = "stack.commitLint.mode"
+
+var (
+	// syncCommitLint is the linter command, empty when linting is off
+	syncCommitLint string
+	// syncCommitLintWarn reports lint failures without stopping the sync
+	syncCommitLintWarn bool
+	// syncNoLint skips the commit message linter for one sync
+	syncNoLint bool
+)
+
+// parseCommitLintMode reads stack.commitLint.mode
+func parseCommitLintMode(value string) (warn bool, err error) {
+	switch value {
+	case "", "fail":
+		return false, nil
+	case "warn":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid %s %q (use fail or warn)", configCommitLintMode, value)
+	}
+}
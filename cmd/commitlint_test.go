@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommitLintMode(t *testing.T) {
+	warn, err := parseCommitLintMode("")
+	assert.NoError(t, err)
+	assert.False(t, warn)
+
+	warn, err = parseCommitLintMode("warn")
+	assert.NoError(t, err)
+	assert.True(t, warn)
+
+	_, err = parseCommitLintMode("loud")
+	assert.Error(t, err)
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var conflictsCmd = &cobra.Command{
+	Use:   "conflicts",
+	Short: i18n.T("conflicts.short"),
+	Long:  i18n.T("conflicts.long"),
+	Example: `  # Check the stack before the bottom PR lands
+  stack conflicts`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runConflicts(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// layerConflicts are the files a branch conflicts on with the base branch, split
+// into those it brings in and those inherited from the branch below
+type layerConflicts struct {
+	introduced []string
+	inherited  []string
+}
+
+// findLayerConflicts simulates merging each branch (bottom to top) into baseRef
+func findLayerConflicts(gitClient git.GitClient, baseRef string, branches []string) (map[string]layerConflicts, error) {
+	result := make(map[string]layerConflicts)
+	below := make(map[string]bool)
+	for _, branch := range branches {
+		files, err := gitClient.GetMergeConflicts(baseRef, branch)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge %s into %s: %w", branch, baseRef, err)
+		}
+		var layer layerConflicts
+		current := make(map[string]bool)
+		for _, file := range files {
+			current[file] = true
+			if below[file] {
+				layer.inherited = append(layer.inherited, file)
+			} else {
+				layer.introduced = append(layer.introduced, file)
+			}
+		}
+		result[branch] = layer
+		below = current
+	}
+	return result, nil
+}
+
+func runConflicts(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return &stack.NotInStackError{Branch: currentBranch}
+	}
+	baseBranch, branches := chain[0], chain[1:]
+
+	if err := spinner.WrapWithAutoDelay(fmt.Sprintf("Fetching from %s...", git.Remote), 300*time.Millisecond, gitClient.Fetch); err != nil {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	baseRef := baseBranch
+	if gitClient.RemoteBranchExists(baseBranch) {
+		baseRef = git.RemoteRef(baseBranch)
+	}
+
+	var conflicts map[string]layerConflicts
+	var prs map[string]*github.PRInfo
+	if err := spinner.WrapWithAutoDelay(fmt.Sprintf("Merging %d branch(es) into %s...", len(branches), baseRef), 300*time.Millisecond, func() error {
+		var err error
+		if conflicts, err = findLayerConflicts(gitClient, baseRef, branches); err != nil {
+			return err
+		}
+		// GitHub's view is a bonus, the simulation above is what counts
+		if prs, err = syncer.LoadPRs(githubClient, branches, prScope); err != nil {
+			if verbose {
+				fmt.Printf("Note: could not load PRs: %v\n", err)
+			}
+			prs = make(map[string]*github.PRInfo)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	conflicting := 0
+	origin := ""
+	for _, branch := range branches {
+		layer := conflicts[branch]
+		pr := prs[branch]
+		dirty := pr != nil && pr.State == "OPEN" && pr.MergeStateStatus == "DIRTY"
+
+		switch {
+		case len(layer.introduced) > 0:
+			fmt.Printf("%s %s conflicts with %s in %d file(s), starting here:\n", ui.ErrorIcon(), ui.Branch(branch), ui.Branch(baseBranch), len(layer.introduced))
+			for _, file := range layer.introduced {
+				fmt.Printf("    %s\n", file)
+			}
+			if len(layer.inherited) > 0 {
+				fmt.Printf("  and %d file(s) inherited from below\n", len(layer.inherited))
+			}
+			if origin == "" {
+				origin = branch
+			}
+		case len(layer.inherited) > 0:
+			fmt.Printf("%s %s conflicts with %s in %d file(s) inherited from below\n", ui.WarningIcon(), ui.Branch(branch), ui.Branch(baseBranch), len(layer.inherited))
+		default:
+			fmt.Printf("%s %s merges cleanly into %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.Branch(baseBranch))
+		}
+		if dirty {
+			fmt.Printf("  PR #%d conflicts with %s on GitHub\n", pr.Number, ui.Branch(pr.Base))
+		}
+		if len(layer.introduced) > 0 || len(layer.inherited) > 0 || dirty {
+			conflicting++
+		}
+	}
+
+	fmt.Println()
+	if conflicting == 0 {
+		fmt.Println(ui.Success(fmt.Sprintf("The stack merges cleanly into %s", baseRef)))
+		return nil
+	}
+	if origin != "" {
+		fmt.Printf("Resolve them on %s first: '%s' brings it up to date with %s and stops on the conflicts.\n",
+			ui.Branch(origin), ui.Command("stack sync"), baseRef)
+	}
+	return ui.Reported(fmt.Errorf("%d branch(es) conflict", conflicting))
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFindLayerConflicts(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetMergeConflicts", "origin/main", "feature-a").Return([]string{}, nil)
+	mockGit.On("GetMergeConflicts", "origin/main", "feature-b").Return([]string{"a.go"}, nil)
+	mockGit.On("GetMergeConflicts", "origin/main", "feature-c").Return([]string{"a.go", "b.go"}, nil)
+
+	conflicts, err := findLayerConflicts(mockGit, "origin/main", []string{"feature-a", "feature-b", "feature-c"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts["feature-a"].introduced)
+	assert.Equal(t, []string{"a.go"}, conflicts["feature-b"].introduced)
+	assert.Empty(t, conflicts["feature-b"].inherited)
+	assert.Equal(t, []string{"b.go"}, conflicts["feature-c"].introduced)
+	assert.Equal(t, []string{"a.go"}, conflicts["feature-c"].inherited)
+}
+
+func TestRunConflicts(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	dirty := testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url2")
+	dirty.MergeStateStatus = "DIRTY"
+
+	tests := []struct {
+		name        string
+		setupMocks  func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectError bool
+	}{
+		{
+			name: "clean stack",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetMergeConflicts", "origin/main", mock.Anything).Return([]string{}, nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{}, nil)
+			},
+		},
+		{
+			name: "conflicts fail the command",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetMergeConflicts", "origin/main", "feature-a").Return([]string{}, nil)
+				mockGit.On("GetMergeConflicts", "origin/main", "feature-b").Return([]string{"a.go"}, nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{}, nil)
+			},
+			expectError: true,
+		},
+		{
+			name: "a PR GitHub reports as conflicting",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetMergeConflicts", "origin/main", mock.Anything).Return([]string{}, nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{"feature-b": dirty}, nil)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+			mockGit.On("Fetch").Return(nil)
+			mockGit.On("RemoteBranchExists", "main").Return(true)
+			tt.setupMocks(mockGit, mockGH)
+
+			err := runConflicts(mockGit, mockGH)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				// The conflicts are printed as a report, not as the error
+				assert.True(t, isAlreadyReported(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/syncer"
+)
+
+// configDCO is "check" to require a Signed-off-by trailer from the author on each
+// commit sync pushes (Developer Certificate of Origin), or "fix" to add missing ones
+const configDCO = "stack.dco"
+
+// syncDCO is the sign-off policy for the sync, empty when it's off
+var syncDCO string
+
+// parseDCOMode reads stack.dco
+func parseDCOMode(value string) (string, error) {
+	switch value {
+	case "", "off":
+		return "", nil
+	case syncer.DCOCheck, syncer.DCOFix:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q (use check, fix or off)", configDCO, value)
+	}
+}
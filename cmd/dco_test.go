@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDCOMode(t *testing.T) {
+	mode, err := parseDCOMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", mode)
+
+	mode, err = parseDCOMode("fix")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.DCOFix, mode)
+
+	_, err = parseDCOMode("strict")
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	describeMessage string
+	describeShow    bool
+)
+
+var describeCmd = &cobra.Command{
+	Use:   "describe [branch]",
+	Short: i18n.T("describe.short"),
+	Long:  i18n.T("describe.long"),
+	Example: `  # Write the description of the current branch
+  stack describe
+
+  # Set it without an editor
+  stack describe -m "Adds the login form. Part 2 of the auth stack."
+
+  # Show the description of another branch
+  stack describe feature-auth --show`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		branch := ""
+		if len(args) == 1 {
+			branch = args[0]
+		}
+		if err := runDescribe(gitClient, branch, cmd.Flags().Changed("message")); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	describeCmd.Flags().StringVarP(&describeMessage, "message", "m", "", "Set the description instead of opening the editor")
+	describeCmd.Flags().BoolVar(&describeShow, "show", false, "Print the description instead of editing it")
+}
+
+func runDescribe(gitClient git.GitClient, branch string, setMessage bool) error {
+	if branch == "" {
+		current, err := gitClient.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+		branch = current
+	} else if !gitClient.BranchExists(branch) {
+		return fmt.Errorf("branch %s does not exist", branch)
+	}
+
+	if describeShow {
+		if description := syncer.BranchDescription(gitClient, branch); description != "" {
+			fmt.Println(description)
+		} else {
+			fmt.Printf("%s has no description (write one with '%s')\n", ui.Branch(branch), ui.Command("stack describe"))
+		}
+		return nil
+	}
+
+	if setMessage {
+		if describeMessage == "" {
+			if syncer.BranchDescription(gitClient, branch) == "" {
+				return nil
+			}
+			if err := gitClient.UnsetConfig(syncer.BranchDescriptionKey(branch)); err != nil {
+				return fmt.Errorf("failed to remove description: %w", err)
+			}
+		} else if err := gitClient.SetConfig(syncer.BranchDescriptionKey(branch), describeMessage+"\n"); err != nil {
+			return fmt.Errorf("failed to set description: %w", err)
+		}
+	} else if err := gitClient.EditBranchDescription(branch); err != nil {
+		return err
+	}
+
+	if !dryRun {
+		if syncer.BranchDescription(gitClient, branch) == "" {
+			fmt.Printf("%s Removed the description of %s\n", ui.SuccessIcon(), ui.Branch(branch))
+		} else {
+			fmt.Printf("%s Updated the description of %s\n", ui.SuccessIcon(), ui.Branch(branch))
+		}
+	}
+	return nil
+}
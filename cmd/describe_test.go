@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunDescribe(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("opens the editor for the current branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+		mockGit.On("EditBranchDescription", "feature-a").Return(nil)
+		mockGit.On("GetConfig", "branch.feature-a.description").Return("Adds auth\n")
+
+		assert.NoError(t, runDescribe(mockGit, "", false))
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("sets the description with --message", func(t *testing.T) {
+		describeMessage = "Adds auth"
+		defer func() { describeMessage = "" }()
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("BranchExists", "feature-b").Return(true)
+		mockGit.On("SetConfig", "branch.feature-b.description", "Adds auth\n").Return(nil)
+		mockGit.On("GetConfig", "branch.feature-b.description").Return("Adds auth\n")
+
+		assert.NoError(t, runDescribe(mockGit, "feature-b", true))
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "EditBranchDescription", "feature-b")
+	})
+
+	t.Run("removes the description with an empty --message", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+		mockGit.On("GetConfig", "branch.feature-a.description").Return("Adds auth\n").Once()
+		mockGit.On("UnsetConfig", "branch.feature-a.description").Return(nil)
+		mockGit.On("GetConfig", "branch.feature-a.description").Return("")
+
+		assert.NoError(t, runDescribe(mockGit, "", true))
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("fails for an unknown branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("BranchExists", "nope").Return(false)
+
+		assert.Error(t, runDescribe(mockGit, "nope", false))
+	})
+}
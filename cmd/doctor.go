@@ -0,0 +1,405 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// doctorFix repairs the problems that have a safe fix
+	doctorFix bool
+	// doctorNoPR compares PR bases with what sync last saw instead of looking them up
+	doctorNoPR bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: i18n.T("doctor.short"),
+	Long:  i18n.T("doctor.long"),
+	Example: `  # Check the stack metadata
+  stack doctor
+
+  # Repair what can be repaired
+  stack doctor --fix
+
+  # Show what --fix would do
+  stack doctor --fix --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		var githubClient github.GitHubClient
+		if !doctorNoPR {
+			githubClient = forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+		}
+		if err := runDoctor(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Repair the problems that have a safe fix")
+	doctorCmd.Flags().BoolVar(&doctorNoPR, "no-pr", false, "Don't look up PRs; compare PR bases with what 'stack sync' last saw")
+}
+
+// doctorIssue is a problem found by 'stack doctor', with its repair when there's
+// a safe one
+type doctorIssue struct {
+	checkFinding
+	Fix   func() error // nil when the problem needs a decision
+	Fixed string       // What Fix does, e.g. "moved onto main"
+}
+
+// runDoctor checks the stack metadata, repairing it with --fix. githubClient may
+// be nil to compare PR bases with what sync last recorded.
+func runDoctor(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	localBranches, err := gitClient.ListBranches()
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	exists := make(map[string]bool, len(localBranches))
+	for _, branch := range localBranches {
+		exists[branch] = true
+	}
+	baseBranch := stack.GetBaseBranch(gitClient)
+
+	var issues []doctorIssue
+	for _, f := range checkSyncState(gitClient) {
+		issues = append(issues, doctorIssue{checkFinding: f})
+	}
+	graphIssues, broken := diagnoseGraph(gitClient, parents, exists, baseBranch)
+	issues = append(issues, graphIssues...)
+	issues = append(issues, diagnoseStaleConfig(gitClient)...)
+
+	var prs map[string]*github.PRInfo
+	if githubClient != nil {
+		if prs, err = lookUpDoctorPRs(githubClient, parents); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to look up PRs, comparing with what 'stack sync' last saw: %v\n", err)
+		}
+	}
+	issues = append(issues, diagnoseRemote(gitClient, parents, prs)...)
+	issues = append(issues, diagnosePRBases(gitClient, githubClient, parents, prs, broken)...)
+	issues = append(issues, diagnoseWorktrees(gitClient)...)
+
+	if len(issues) == 0 {
+		fmt.Printf("%s Stack metadata is healthy\n", ui.SuccessIcon())
+		return nil
+	}
+
+	remaining, fixable := 0, 0
+	for _, issue := range issues {
+		icon := ui.WarningIcon()
+		if issue.Level == "error" {
+			icon = ui.ErrorIcon()
+		}
+		if issue.Branch != "" {
+			fmt.Printf("%s %s: %s\n", icon, ui.Branch(issue.Branch), issue.Message)
+		} else {
+			fmt.Printf("%s %s\n", icon, issue.Message)
+		}
+
+		fixed := false
+		switch {
+		case issue.Fix == nil:
+		case !doctorFix:
+			fixable++
+		default:
+			if err := issue.Fix(); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: failed to repair: %v\n", err)
+				break
+			}
+			if !dryRun {
+				fmt.Printf("  %s %s\n", ui.SuccessIcon(), issue.Fixed)
+			}
+			fixed = true
+		}
+		if issue.Level == "error" && !fixed {
+			remaining++
+		}
+	}
+
+	if fixable > 0 {
+		fmt.Printf("\n%d problem(s) can be repaired with '%s'\n", fixable, ui.Command("stack doctor --fix"))
+	}
+	if remaining > 0 {
+		return ui.Reported(errors.New("stack metadata has errors"))
+	}
+	return nil
+}
+
+// diagnoseGraph reports cycles and parents that don't exist. A missing parent is
+// fixed by moving the branch onto its closest ancestor that still exists, found
+// through the config the deleted branches left behind. Also returns the branches
+// whose parent is broken, so their PR base isn't judged.
+func diagnoseGraph(gitClient git.GitClient, parents map[string]string, exists map[string]bool, baseBranch string) ([]doctorIssue, map[string]bool) {
+	orphaned, _ := gitClient.GetOrphanedStackParents()
+
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var issues []doctorIssue
+	broken := make(map[string]bool)
+	reported := make(map[string]bool)
+	for _, branch := range branches {
+		if cycle := findCycle(parents, branch); cycle != nil {
+			broken[branch] = true
+			if !reported[cycle[0]] {
+				reported[cycle[0]] = true
+				issues = append(issues, doctorIssue{checkFinding: checkFinding{
+					Level:   "error",
+					Check:   "cycle",
+					Branch:  cycle[0],
+					Message: fmt.Sprintf("stack parents form a cycle: %s; break it with '%s'", strings.Join(cycle, " → "), ui.Command("stack reparent")),
+				}})
+			}
+			continue
+		}
+
+		parent := parents[branch]
+		if parent == baseBranch || exists[parent] {
+			continue
+		}
+		broken[branch] = true
+		target := closestExistingAncestor(parent, orphaned, exists, baseBranch)
+		configKey := fmt.Sprintf("branch.%s.stackparent", branch)
+		issues = append(issues, doctorIssue{
+			checkFinding: checkFinding{
+				Level:   "error",
+				Check:   "missing-parent",
+				Branch:  branch,
+				Message: fmt.Sprintf("parent %s doesn't exist", ui.Branch(parent)),
+			},
+			Fix: func() error {
+				return gitClient.SetConfig(configKey, target)
+			},
+			Fixed: fmt.Sprintf("Moved onto %s; '%s' rebases it", ui.Branch(target), ui.Command("stack sync")),
+		})
+	}
+	return issues, broken
+}
+
+// closestExistingAncestor follows the parents deleted branches left in the config
+// from branch to the first one that still exists, or the base branch
+func closestExistingAncestor(branch string, orphaned map[string]string, exists map[string]bool, baseBranch string) string {
+	seen := make(map[string]bool)
+	for !seen[branch] {
+		seen[branch] = true
+		if branch == baseBranch || exists[branch] {
+			return branch
+		}
+		parent, ok := orphaned[branch]
+		if !ok {
+			break
+		}
+		branch = parent
+	}
+	return baseBranch
+}
+
+// diagnoseStaleConfig reports stack config left behind by branches that no longer
+// exist, fixed by removing it like 'stack clean-config'
+func diagnoseStaleConfig(gitClient git.GitClient) []doctorIssue {
+	keys, err := gitClient.GetStackConfigKeys()
+	if err != nil {
+		return nil
+	}
+	byBranch := make(map[string][]string)
+	for _, key := range keys {
+		if branch := stackConfigBranch(key); branch != "" && !gitClient.BranchExists(branch) {
+			byBranch[branch] = append(byBranch[branch], key)
+		}
+	}
+
+	branches := make([]string, 0, len(byBranch))
+	for branch := range byBranch {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var issues []doctorIssue
+	for _, branch := range branches {
+		branchKeys := byBranch[branch]
+		sort.Strings(branchKeys)
+		issues = append(issues, doctorIssue{
+			checkFinding: checkFinding{
+				Level:   "warning",
+				Check:   "stale-config",
+				Branch:  branch,
+				Message: "branch no longer exists but still has stack config",
+			},
+			Fix: func() error {
+				for _, key := range branchKeys {
+					if err := gitClient.UnsetConfig(key); err != nil {
+						return fmt.Errorf("failed to remove %s: %w", key, err)
+					}
+				}
+				return nil
+			},
+			Fixed: fmt.Sprintf("Removed %d stack config key(s)", len(branchKeys)),
+		})
+	}
+	return issues
+}
+
+// lookUpDoctorPRs fetches the PRs of the stack branches, resolving pinned ones
+func lookUpDoctorPRs(githubClient github.GitHubClient, parents map[string]string) (map[string]*github.PRInfo, error) {
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+	return syncer.LoadPRs(githubClient, branches, prScope)
+}
+
+// diagnoseRemote reports branches that were pushed before, as they have a PR, but
+// are no longer on origin. Pushing is left to the user.
+func diagnoseRemote(gitClient git.GitClient, parents map[string]string, prs map[string]*github.PRInfo) []doctorIssue {
+	remote := gitClient.GetRemoteBranchesSet()
+	pins, _ := gitClient.GetAllStackPRs()
+
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var issues []doctorIssue
+	for _, branch := range branches {
+		if remote[branch] {
+			continue
+		}
+		pr := prs[branch]
+		number, pinned := pins[branch]
+		if pr == nil && !pinned {
+			continue
+		}
+		if pr != nil {
+			number = pr.Number
+		}
+
+		message := fmt.Sprintf("isn't on origin anymore, but had PR #%d; push it again with '%s'", number, ui.Command("stack submit"))
+		if pr != nil && pr.State == "MERGED" {
+			message = fmt.Sprintf("isn't on origin anymore, as PR #%d was merged; '%s' removes it", number, ui.Command("stack prune"))
+		}
+		issues = append(issues, doctorIssue{checkFinding: checkFinding{
+			Level:   "warning",
+			Check:   "missing-remote",
+			Branch:  branch,
+			Message: message,
+		}})
+	}
+	return issues
+}
+
+// diagnosePRBases reports open PRs that don't target the branch's parent, fixed
+// by retargeting them like sync does. Without PRs looked up, it falls back to the
+// bases sync last recorded, which can't be fixed from here.
+func diagnosePRBases(gitClient git.GitClient, githubClient github.GitHubClient, parents map[string]string, prs map[string]*github.PRInfo, broken map[string]bool) []doctorIssue {
+	if prs == nil {
+		var branches []string
+		for branch := range parents {
+			if !broken[branch] {
+				branches = append(branches, branch)
+			}
+		}
+		var issues []doctorIssue
+		for _, f := range checkPRBases(gitClient, parents, branches) {
+			issues = append(issues, doctorIssue{checkFinding: f})
+		}
+		return issues
+	}
+
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	branches := make([]string, 0, len(prs))
+	for branch := range prs {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	var issues []doctorIssue
+	for _, branch := range branches {
+		pr := prs[branch]
+		if pr.State != "OPEN" || broken[branch] || flatPRs {
+			continue
+		}
+		want := syncer.PRBaseFor(parents[branch], parents, umbrellas)
+		if pr.Base == want {
+			continue
+		}
+		issue := doctorIssue{checkFinding: checkFinding{
+			Level:   "warning",
+			Check:   "pr-base",
+			Branch:  branch,
+			Message: fmt.Sprintf("PR #%d targets %s but the branch's parent is %s", pr.Number, ui.Branch(pr.Base), ui.Branch(want)),
+		}}
+		if !github.Offline {
+			number, branch := pr.Number, branch
+			issue.Fix = func() error {
+				if err := syncer.RetargetPR(githubClient, number, want); err != nil {
+					return err
+				}
+				if dryRun {
+					return nil
+				}
+				return gitClient.SetConfig(syncer.StackPRBaseKey(branch), want)
+			}
+			issue.Fixed = fmt.Sprintf("Retargeted PR #%d to %s", pr.Number, ui.Branch(want))
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// diagnoseWorktrees reports worktrees whose directory was deleted without 'git
+// worktree remove', which keep their branch from being checked out elsewhere
+func diagnoseWorktrees(gitClient git.GitClient) []doctorIssue {
+	paths, err := gitClient.ListWorktrees()
+	if err != nil {
+		return nil
+	}
+
+	pruned := false
+	var issues []doctorIssue
+	for _, path := range paths {
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		issues = append(issues, doctorIssue{
+			checkFinding: checkFinding{
+				Level:   "warning",
+				Check:   "orphaned-worktree",
+				Message: fmt.Sprintf("worktree %s no longer exists", path),
+			},
+			// One prune forgets all of them
+			Fix: func() error {
+				if pruned {
+					return nil
+				}
+				pruned = true
+				return gitClient.PruneWorktrees()
+			},
+			Fixed: "Forgot the worktree",
+		})
+	}
+	return issues
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClosestExistingAncestor(t *testing.T) {
+	exists := map[string]bool{"main": true, "feature-a": true}
+	orphaned := map[string]string{"feature-b": "feature-a", "feature-c": "feature-b", "feature-x": "gone"}
+
+	assert.Equal(t, "feature-a", closestExistingAncestor("feature-c", orphaned, exists, "main"))
+	assert.Equal(t, "main", closestExistingAncestor("feature-x", orphaned, exists, "main"))
+	assert.Equal(t, "main", closestExistingAncestor("unknown", orphaned, exists, "main"))
+}
+
+func TestRunDoctor(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name           string
+		fix            bool
+		parents        map[string]string
+		withGitHub     bool
+		setupMocks     func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectReported bool
+	}{
+		{
+			name:       "healthy stack",
+			parents:    map[string]string{"feature-a": "main"},
+			withGitHub: true,
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{}, nil)
+				mockGit.On("GetStackConfigKeys").Return([]string{"branch.feature-a.stackparent"}, nil)
+				mockGit.On("BranchExists", "feature-a").Return(true)
+				mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true, "feature-c": true})
+				mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a"}).Return(map[string]*github.PRInfo{
+					"feature-a": {Number: 1, State: "OPEN", Base: "main"},
+				}, nil)
+			},
+		},
+		{
+			name:    "reports a missing parent without --fix",
+			parents: map[string]string{"feature-a": "main", "feature-c": "feature-b"},
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No SetConfig or UnsetConfig: nothing is repaired without --fix
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{"feature-b": "feature-a"}, nil)
+				mockGit.On("GetStackConfigKeys").Return([]string{"branch.feature-b.stackparent"}, nil)
+				mockGit.On("BranchExists", "feature-b").Return(false)
+				mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true, "feature-c": true})
+				mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+			},
+			expectReported: true,
+		},
+		{
+			name:       "moves the branch onto the closest ancestor and removes stale config",
+			fix:        true,
+			parents:    map[string]string{"feature-a": "main", "feature-c": "feature-b"},
+			withGitHub: true,
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{"feature-b": "feature-a"}, nil)
+				mockGit.On("GetStackConfigKeys").Return([]string{"branch.feature-b.stackparent", "branch.feature-b.stackpr"}, nil)
+				mockGit.On("BranchExists", "feature-b").Return(false)
+				mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true, "feature-c": true})
+				mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-a").Return(nil)
+				mockGit.On("UnsetConfig", "branch.feature-b.stackparent").Return(nil)
+				mockGit.On("UnsetConfig", "branch.feature-b.stackpr").Return(nil)
+				// No UpdatePRBase: feature-c's PR isn't judged while its parent is broken
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-c"}).Return(map[string]*github.PRInfo{
+					"feature-c": {Number: 3, State: "OPEN", Base: "feature-b"},
+				}, nil)
+			},
+		},
+		{
+			name:       "retargets a PR with the wrong base",
+			fix:        true,
+			parents:    map[string]string{"feature-a": "main", "feature-c": "feature-a"},
+			withGitHub: true,
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{}, nil)
+				mockGit.On("GetStackConfigKeys").Return([]string{"branch.feature-a.stackparent"}, nil)
+				mockGit.On("BranchExists", "feature-a").Return(true)
+				mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true, "feature-c": true})
+				mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackprbase", "feature-a").Return(nil)
+				mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-c"}).Return(map[string]*github.PRInfo{
+					"feature-a": {Number: 1, State: "OPEN", Base: "main"},
+					"feature-c": {Number: 3, State: "OPEN", Base: "main"},
+				}, nil)
+				mockGH.On("GetPRByNumber", 3).Return(&github.PRInfo{Number: 3, State: "OPEN", Base: "main"}, nil)
+				mockGH.On("UpdatePRBase", 3, "feature-a").Return(nil)
+			},
+		},
+		{
+			name:    "reports a cycle and a branch gone from origin",
+			fix:     true,
+			parents: map[string]string{"feature-a": "feature-c", "feature-c": "feature-a"},
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No SetConfig: a cycle has no ancestor to repair onto. No
+				// GetStackUmbrellas: both branches are broken, so no PR base is checked
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{}, nil)
+				mockGit.On("GetStackConfigKeys").Return([]string{}, nil)
+				mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-c": true})
+				mockGit.On("GetAllStackPRs").Return(map[string]int{"feature-a": 1}, nil)
+			},
+			expectReported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doctorFix = tt.fix
+			defer func() { doctorFix = false }()
+
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+			mockGit.On("GetConfig", "stack.rebasePlan.todo").Return("")
+			mockGit.On("GetOperationInProgress").Return(nil)
+			mockGit.On("GetAllStackParents").Return(tt.parents, nil)
+			mockGit.On("ListBranches").Return([]string{"main", "feature-a", "feature-c"}, nil)
+			mockGit.On("GetAllStackPRBases").Return(map[string]string{}, nil).Maybe()
+			mockGit.On("ListWorktrees").Return([]string{t.TempDir()}, nil)
+			tt.setupMocks(mockGit, mockGH)
+
+			var githubClient github.GitHubClient
+			if tt.withGitHub {
+				githubClient = mockGH
+			}
+			err := runDoctor(mockGit, githubClient)
+
+			if tt.expectReported {
+				assert.True(t, isAlreadyReported(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDiagnoseWorktrees(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	present := t.TempDir()
+	gone := filepath.Join(t.TempDir(), "deleted")
+	alsoGone := filepath.Join(t.TempDir(), "also-deleted")
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("ListWorktrees").Return([]string{present, gone, alsoGone}, nil)
+	mockGit.On("PruneWorktrees").Return(nil).Once()
+
+	issues := diagnoseWorktrees(mockGit)
+
+	assert.Len(t, issues, 2)
+	for _, issue := range issues {
+		assert.NoError(t, issue.Fix())
+	}
+	mockGit.AssertExpectations(t)
+}
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/stack"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
@@ -15,21 +17,15 @@ import (
 
 var downCmd = &cobra.Command{
 	Use:   "down",
-	Short: "Move to a child branch in the stack",
-	Long: `Checkout a child branch of the current branch in the stack.
-
-If the current branch has no children (is at the tip of the stack),
-an error message will be displayed.
-
-If there are multiple children, you will be prompted to select one.`,
+	Short: i18n.T("down.short"),
+	Long:  i18n.T("down.long"),
 	Example: `  # Move to child branch
   stack down`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
 
 		if err := runDown(gitClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -48,7 +44,7 @@ func runDown(gitClient git.GitClient) error {
 	}
 
 	if len(children) == 0 {
-		return fmt.Errorf("no children (tip of stack)")
+		return errors.New(i18n.T("down.noChildren"))
 	}
 
 	var targetBranch string
@@ -58,11 +54,11 @@ func runDown(gitClient git.GitClient) error {
 		targetBranch = children[0].Name
 	} else {
 		// Multiple children, prompt for selection
-		fmt.Printf("Multiple children found for %s:\n", ui.Branch(currentBranch))
+		fmt.Println(i18n.T("down.multiple", ui.Branch(currentBranch)))
 		for i, child := range children {
 			fmt.Printf("  %d) %s\n", i+1, ui.Branch(child.Name))
 		}
-		fmt.Print("\nSelect branch (1-" + strconv.Itoa(len(children)) + "): ")
+		fmt.Print("\n" + i18n.T("down.select", len(children)))
 
 		reader := bufio.NewReader(os.Stdin)
 		input, err := reader.ReadString('\n')
@@ -84,6 +80,6 @@ func runDown(gitClient git.GitClient) error {
 		return fmt.Errorf("failed to checkout child branch %s: %w", targetBranch, err)
 	}
 
-	fmt.Printf("Switched to child branch: %s\n", ui.Branch(targetBranch))
+	fmt.Println(i18n.T("down.switched", ui.Branch(targetBranch)))
 	return nil
 }
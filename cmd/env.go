@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/gitlab"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// configGHHost is the GitHub host to use for the repo, for when origin's host is an
+// SSH alias rather than the real host (GH_HOST works too)
+const configGHHost = "stack.ghHost"
+
+// configGHUser is which of the accounts gh is logged in to on the host to use
+const configGHUser = "stack.ghUser"
+
+var envCmd = &cobra.Command{
+	Use:     "env",
+	Short:   i18n.T("env.short"),
+	Long:    i18n.T("env.long"),
+	Example: `  stack env`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL(git.Remote))
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		runEnv(gitClient, githubClient, repo)
+	},
+}
+
+// configureGitHubAccount selects the GitHub host and account gh is run with
+func configureGitHubAccount(gitClient git.GitClient) {
+	github.Host = gitClient.GetConfig(configGHHost)
+	if github.Host == "" {
+		github.Host = os.Getenv("GH_HOST")
+	}
+	github.User = gitClient.GetConfig(configGHUser)
+}
+
+// ghHostSource says where the GitHub host came from
+func ghHostSource(gitClient git.GitClient) string {
+	switch {
+	case gitClient.GetConfig(configGHHost) != "":
+		return configGHHost
+	case os.Getenv("GH_HOST") != "":
+		return "GH_HOST"
+	default:
+		return git.Remote
+	}
+}
+
+func runEnv(gitClient git.GitClient, githubClient github.GitHubClient, repo string) {
+	root, err := gitClient.GetRepoRoot()
+	if err != nil {
+		root = ui.Dim("(unknown)")
+	}
+	fmt.Printf("Repository:   %s\n", root)
+	fmt.Printf("Remote:       %s %s\n", git.Remote, ui.Dim(gitClient.GetRemoteURL(git.Remote)))
+	if git.PushRemote != "" {
+		fmt.Printf("Push remote:  %s %s\n", git.PushRemote, ui.Dim(gitClient.GetRemoteURL(git.PushRemote)))
+	}
+	fmt.Printf("Base branch:  %s\n", ui.Branch(stack.GetBaseBranch(gitClient)))
+
+	if forge.Detect(gitClient.GetRemoteURL(git.Remote)) == forge.GitLab {
+		host, project := gitlab.ParseProjectFromURL(gitClient.GetRemoteURL(git.Remote))
+		if project == "" {
+			fmt.Printf("GitLab repo:  %s\n", ui.Dim(fmt.Sprintf("(%s is not a GitLab remote)", git.Remote)))
+			return
+		}
+		fmt.Printf("GitLab repo:  %s/%s\n", host, project)
+		if login, err := githubClient.GetCurrentUser(); err != nil {
+			fmt.Printf("GitLab user:  %s %s\n", ui.ErrorIcon(), ui.Dim("(glab's account)"))
+			fmt.Fprintf(os.Stderr, "\n%v\n", err)
+		} else {
+			fmt.Printf("GitLab user:  %s %s\n", login, ui.Dim("(glab's account)"))
+		}
+		return
+	}
+
+	if repo == "" {
+		fmt.Printf("GitHub repo:  %s\n", ui.Dim(fmt.Sprintf("(%s is not a GitHub remote)", git.Remote)))
+		return
+	}
+	repo = github.WithHost(repo)
+	fmt.Printf("GitHub repo:  %s\n", repo)
+	fmt.Printf("GitHub host:  %s %s\n", github.HostOf(repo), ui.Dim("(from "+ghHostSource(gitClient)+")"))
+
+	source := "gh's active account"
+	if github.User != "" {
+		source = configGHUser
+	}
+	login, err := githubClient.GetCurrentUser()
+	if err != nil {
+		fmt.Printf("GitHub user:  %s %s\n", ui.ErrorIcon(), ui.Dim("("+source+")"))
+		fmt.Fprintf(os.Stderr, "\n%v\n", err)
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+		return
+	}
+	fmt.Printf("GitHub user:  %s %s\n", login, ui.Dim("("+source+")"))
+}
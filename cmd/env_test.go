@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureGitHubAccount(t *testing.T) {
+	defer func() {
+		github.Host = ""
+		github.User = ""
+	}()
+
+	t.Run("reads the host and account from config", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configGHHost).Return("github.com")
+		mockGit.On("GetConfig", configGHUser).Return("work-login")
+
+		configureGitHubAccount(mockGit)
+
+		assert.Equal(t, "github.com", github.Host)
+		assert.Equal(t, "work-login", github.User)
+	})
+
+	t.Run("falls back to GH_HOST", func(t *testing.T) {
+		t.Setenv("GH_HOST", "ghe.example.com")
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configGHHost).Return("")
+		mockGit.On("GetConfig", configGHUser).Return("")
+
+		configureGitHubAccount(mockGit)
+
+		assert.Equal(t, "ghe.example.com", github.Host)
+		assert.Equal(t, "", github.User)
+		assert.Equal(t, "GH_HOST", ghHostSource(mockGit))
+	})
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// Exit codes, so scripts can tell why a command failed
+const (
+	exitError      = 1
+	exitConflict   = 2
+	exitDiverged   = 3
+	exitAuth       = 4
+	exitNotInStack = 5
+)
+
+// isAlreadyReported reports whether err, or an error it wraps, was already shown
+func isAlreadyReported(err error) bool {
+	var reported *ui.ReportedError
+	return errors.As(err, &reported)
+}
+
+// exitCode picks the exit code for err
+func exitCode(err error) int {
+	var conflict *git.ConflictError
+	var diverged *git.DivergedError
+	var auth *github.AuthError
+	var notInStack *stack.NotInStackError
+	switch {
+	case errors.As(err, &conflict):
+		return exitConflict
+	case errors.As(err, &diverged):
+		return exitDiverged
+	case errors.As(err, &auth):
+		return exitAuth
+	case errors.As(err, &notInStack):
+		return exitNotInStack
+	default:
+		return exitError
+	}
+}
+
+// errorHint is what to do about err, or "" when there's nothing specific to say
+func errorHint(err error) string {
+	var conflict *git.ConflictError
+	var diverged *git.DivergedError
+	var auth *github.AuthError
+	var notInStack *stack.NotInStackError
+	switch {
+	case errors.As(err, &conflict):
+		return i18n.T("hint.conflict",
+			ui.Command("git "+conflict.Op+" --continue"), ui.Command("git "+conflict.Op+" --abort"))
+	case errors.As(err, &diverged):
+		return i18n.T("sync.pushHint")
+	case errors.As(err, &auth):
+		return i18n.T("hint.auth", ui.Command("gh auth login"), ui.Command("gh auth status"))
+	case errors.As(err, &notInStack):
+		return syncer.NotInStackHint()
+	case errors.Is(err, git.ErrOffline), errors.Is(err, github.ErrOffline):
+		return i18n.T("hint.offline")
+	default:
+		return ""
+	}
+}
+
+// exitWithError explains err, unless that was already done, and exits with the
+// exit code for it
+func exitWithError(err error) {
+	if !isAlreadyReported(err) {
+		fmt.Fprintln(os.Stderr, i18n.T("error", err))
+		if hint := errorHint(err); hint != "" {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+	}
+	os.Exit(exitCode(err))
+}
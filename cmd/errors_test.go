@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	cause := errors.New("git failed")
+
+	assert.Equal(t, exitError, exitCode(cause))
+	assert.Equal(t, exitConflict, exitCode(fmt.Errorf("failed to rebase feature-a: %w", &git.ConflictError{Op: "rebase", Err: cause})))
+	assert.Equal(t, exitDiverged, exitCode(fmt.Errorf("push failed for feature-a: %w", &git.DivergedError{Branch: "feature-a", Err: cause})))
+	assert.Equal(t, exitAuth, exitCode(&github.AuthError{Err: cause}))
+	assert.Equal(t, exitNotInStack, exitCode(&stack.NotInStackError{Branch: "main"}))
+
+	// Reporting an error doesn't change what kind of error it is
+	assert.Equal(t, exitConflict, exitCode(ui.Reported(&git.ConflictError{Op: "rebase", Err: cause})))
+}
+
+func TestAlreadyReported(t *testing.T) {
+	err := ui.Reported(errors.New("stack check failed"))
+
+	assert.True(t, isAlreadyReported(err))
+	assert.True(t, isAlreadyReported(fmt.Errorf("sync: %w", err)))
+	assert.False(t, isAlreadyReported(errors.New("stack check failed")))
+	assert.Equal(t, "stack check failed", err.Error())
+}
+
+func TestErrorHint(t *testing.T) {
+	assert.Contains(t, errorHint(&git.ConflictError{Op: "am", Err: errors.New("patch failed")}), "git am --continue")
+	assert.Contains(t, errorHint(&github.AuthError{Err: errors.New("401")}), "gh auth login")
+	assert.Equal(t, "", errorHint(errors.New("something else")))
+}
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fixupCommit  string
+	fixupRestack bool
+)
+
+var fixupCmd = &cobra.Command{
+	Use:   "fixup <branch>",
+	Short: i18n.T("fixup.short"),
+	Long:  i18n.T("fixup.long"),
+	Example: `  # Address review feedback for a lower layer from the top of the stack
+  git add -p
+  stack fixup feature-auth
+
+  # Squash it in right away
+  stack fixup feature-auth --restack
+
+  # Target a specific commit of the branch
+  stack fixup feature-auth --commit a1b2c3d`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runFixup(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	fixupCmd.Flags().StringVar(&fixupCommit, "commit", "", "Commit of the branch to fix up (defaults to its tip)")
+	fixupCmd.Flags().BoolVar(&fixupRestack, "restack", false, "Squash the fixup into its target right away with an autosquash rebase")
+}
+
+func runFixup(gitClient git.GitClient, target string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	// chain starts with the base branch, which isn't part of the stack
+	index := -1
+	for i := 1; i < len(chain); i++ {
+		if chain[i] == target {
+			index = i
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("'%s' is not %s or a branch below it in the stack", target, currentBranch)
+	}
+
+	staged, err := gitClient.HasStagedChanges()
+	if err != nil {
+		return fmt.Errorf("failed to check staged changes: %w", err)
+	}
+	if !staged {
+		return fmt.Errorf("no staged changes to commit\n\nStage the fix with '%s' first", ui.Command("git add"))
+	}
+
+	// The target's own commits start after its parent, or its fork point with the base
+	upstream := chain[index-1]
+	if index == 1 {
+		if upstream, err = gitClient.GetMergeBase(chain[0], target); err != nil {
+			return fmt.Errorf("failed to find where %s starts: %w", target, err)
+		}
+	}
+
+	commit, err := fixupTarget(gitClient, target, upstream)
+	if err != nil {
+		return err
+	}
+
+	if err := gitClient.CommitFixup(commit); err != nil {
+		return fmt.Errorf("failed to commit fixup: %w", err)
+	}
+	if !dryRun {
+		fmt.Printf("%s Committed fixup of %s on %s\n", ui.SuccessIcon(), ui.Branch(target), ui.Branch(currentBranch))
+	}
+
+	if !fixupRestack {
+		fmt.Printf("Squash it into %s later with '%s'\n", ui.Branch(target), ui.Command("git rebase -i --autosquash --update-refs "+upstream))
+		return nil
+	}
+
+	fmt.Printf("Squashing fixup into %s...\n", ui.Branch(target))
+	if err := gitClient.RebaseAutosquash(upstream); err != nil {
+		fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("conflict.rebaseStopped"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+		fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.continue"))
+		fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("fixup.conflict.abort"))
+		return fmt.Errorf("failed to squash fixup: %w", err)
+	}
+	if !dryRun {
+		fmt.Printf("%s Squashed fixup into %s\n", ui.SuccessIcon(), ui.Branch(target))
+	}
+	fmt.Printf("Restack the branches above and push with '%s'\n", ui.Command("stack sync"))
+	return nil
+}
+
+// fixupTarget resolves the commit to fix up: --commit if given, which must be one
+// of target's own commits, otherwise target's tip
+func fixupTarget(gitClient git.GitClient, target, upstream string) (string, error) {
+	if fixupCommit == "" {
+		commit, err := gitClient.GetCommitHash(target)
+		if err != nil {
+			return "", fmt.Errorf("failed to get commit of %s: %w", target, err)
+		}
+		return commit, nil
+	}
+
+	commit, err := gitClient.GetCommitHash(fixupCommit)
+	if err != nil {
+		return "", fmt.Errorf("unknown commit '%s': %w", fixupCommit, err)
+	}
+	commits, err := gitClient.GetUniqueCommits(upstream, target)
+	if err != nil {
+		return "", fmt.Errorf("failed to list commits of %s: %w", target, err)
+	}
+	for _, c := range commits {
+		if c == commit {
+			return commit, nil
+		}
+	}
+	return "", fmt.Errorf("commit %s is not one of %s's own commits", fixupCommit, target)
+}
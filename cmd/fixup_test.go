@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunFixup(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}
+
+	t.Run("fixes up the tip of a lower branch and restacks", func(t *testing.T) {
+		fixupRestack = true
+		defer func() { fixupRestack = false }()
+
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("HasStagedChanges").Return(true, nil)
+		mockGit.On("GetCommitHash", "feature-b").Return("sha-b", nil)
+		mockGit.On("CommitFixup", "sha-b").Return(nil)
+		mockGit.On("RebaseAutosquash", "feature-a").Return(nil)
+
+		err := runFixup(mockGit, "feature-b")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("bottom branch starts at its fork point", func(t *testing.T) {
+		fixupCommit = "abc"
+		defer func() { fixupCommit = "" }()
+
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("HasStagedChanges").Return(true, nil)
+		mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+		mockGit.On("GetCommitHash", "abc").Return("sha-abc", nil)
+		mockGit.On("GetUniqueCommits", "fork", "feature-a").Return([]string{"sha-abc", "sha-a"}, nil)
+		mockGit.On("CommitFixup", "sha-abc").Return(nil)
+
+		err := runFixup(mockGit, "feature-a")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("commit outside the branch", func(t *testing.T) {
+		fixupCommit = "abc"
+		defer func() { fixupCommit = "" }()
+
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("HasStagedChanges").Return(true, nil)
+		mockGit.On("GetCommitHash", "abc").Return("sha-abc", nil)
+		mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"sha-b"}, nil)
+
+		err := runFixup(mockGit, "feature-b")
+
+		assert.ErrorContains(t, err, "not one of feature-b's own commits")
+	})
+
+	t.Run("branch above the current one", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+
+		err := runFixup(mockGit, "feature-c")
+
+		assert.Error(t, err)
+	})
+
+	t.Run("nothing staged", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("HasStagedChanges").Return(false, nil)
+
+		err := runFixup(mockGit, "feature-b")
+
+		assert.ErrorContains(t, err, "no staged changes")
+	})
+}
@@ -0,0 +1,16 @@
+package cmd
+
+import "github.com/javoire/stackinator/internal/git"
+
+// configFlatPRs keeps every PR targeting the base branch instead of the branch's
+// parent, for teams that only look at the incremental diffs locally
+const configFlatPRs = "stack.flatPRs"
+
+// flatPRs is set from stack.flatPRs when a command starts. New PRs then target
+// the base branch, and the bases of existing PRs are never checked or changed.
+var flatPRs bool
+
+// configureFlatPRs reads whether PRs target the base branch rather than parents
+func configureFlatPRs(gitClient git.GitClient) {
+	flatPRs = gitClient.GetConfig(configFlatPRs) == "true"
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFlatPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	flatPRs = true
+	defer func() { flatPRs = false }()
+
+	t.Run("submit opens PRs against the base branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"),
+		}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "feature-a": true})
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa", nil)
+		mockGit.On("Push", "feature-b", false).Return(nil)
+		mockGit.On("GetConfig", mock.Anything).Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+		mockGH.On("CreatePR", "feature-b", "main", false, "").Return(testutil.NewPRInfo(7, "OPEN", "main", "B", "url7"), nil)
+
+		assert.NoError(t, runSubmit(mockGit, mockGH))
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("doesn't retarget PRs after a rebase plan", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		testutil.AllowStackUmbrellas(mockGit)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRsForBranches", []string{"feature-b"}).Return(map[string]*github.PRInfo{
+			"feature-b": testutil.NewPRInfo(13, "OPEN", "main", "B", "url"),
+		}, nil)
+
+		retargetPlanPRs(mockGit, mockGH, map[string]string{"feature-b": "feature-a"}, nil, nil)
+
+		mockGH.AssertNotCalled(t, "UpdatePRBase")
+	})
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var foldCmd = &cobra.Command{
+	Use:   "fold",
+	Short: i18n.T("fold.short"),
+	Long:  i18n.T("fold.long"),
+	Example: `  # Stack: main -> feature-a -> feature-b (current) -> feature-c
+  stack fold
+  # Stack: main -> feature-a (with feature-b's commits) -> feature-c`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runFold(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runFold(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	branch, branches, err := rebasePlanStack(gitClient, "")
+	if err != nil || len(branches) == 0 {
+		return err
+	}
+
+	position := slices.Index(branches, branch)
+	if position == 0 {
+		return fmt.Errorf("%s is at the bottom of the stack, there is no parent to fold it into", branch)
+	}
+	parent := branches[position-1]
+	if umbrellas, _ := gitClient.GetStackUmbrellas(); umbrellas[parent] {
+		return fmt.Errorf("%s is an umbrella branch, it can't take commits", parent)
+	}
+
+	entries := make([]planEntry, len(branches))
+	for i, b := range branches {
+		entries[i] = planEntry{Action: "pick", Branch: b}
+	}
+	entries[position].Action = "fold"
+
+	steps, err := compilePlan(gitClient, entries, branches, stack.GetBaseBranch(gitClient))
+	if err != nil {
+		return err
+	}
+
+	steps = append(steps, planStep{"delete", branch, parent})
+
+	return applyRebasePlan(gitClient, githubClient, steps, branches, parent)
+}
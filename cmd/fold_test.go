@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunFold(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		currentBranch string
+		setupMocks    func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "folds the current branch into its parent",
+			currentBranch: "feature-b",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				testutil.AllowStackUmbrellas(mockGit)
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "feature-b").Return("sha-b", nil)
+				mockGit.On("GetCommitHash", "feature-c").Return("sha-c", nil)
+				mockGit.On("SetConfig", configRebasePlanBackup, mock.Anything).Return(nil)
+				mockGit.On("SetConfig", configRebasePlanOriginalBranch, "feature-a").Return(nil)
+				mockGit.On("RebaseOnto", "fork", "fork", "feature-a").Return(nil).Once()
+				mockGit.On("RebaseOnto", "feature-a", "sha-a", "feature-b").Return(nil).Once()
+				mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+				mockGit.On("ResetHard", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-a", "sha-b", "feature-c").Return(nil).Once()
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "main").Return(nil)
+				mockGit.On("UnsetConfig", "branch.feature-b.stackparent").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-a").Return(nil)
+				mockGit.On("DeleteBranchForce", "feature-b").Return(nil)
+				mockGit.On("UnsetConfig", mock.Anything).Return(nil)
+				mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{
+					"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "feature-a", ""),
+					"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "feature-b", ""),
+					"feature-c": testutil.NewPRInfo(3, "OPEN", "feature-b", "feature-c", ""),
+				}, nil)
+				mockGH.On("GetPRByNumber", 3).Return(testutil.NewPRInfo(3, "OPEN", "feature-b", "feature-c", ""), nil)
+				mockGH.On("UpdatePRBase", 3, "feature-a").Return(nil)
+				mockGH.On("ClosePR", 2, "Closed by stackinator: feature-b was folded into feature-a (#1).").Return(nil)
+
+			},
+		},
+		{
+			name:          "refuses the bottom branch",
+			currentBranch: "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No RebaseOnto: there is no parent to fold into
+			},
+			expectError:   true,
+			errorContains: "bottom of the stack",
+		},
+		{
+			name:          "refuses to fold into an umbrella branch",
+			currentBranch: "feature-b",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No RebaseOnto: an umbrella branch has no commits to fold into
+				mockGit.On("GetStackUmbrellas").Return(map[string]bool{"feature-a": true}, nil)
+			},
+			expectError:   true,
+			errorContains: "umbrella",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			mockGit.On("GetConfig", configRebasePlanBackup).Return("")
+			mockGit.On("IsWorkingTreeClean").Return(true, nil)
+			mockGit.On("GetCurrentBranch").Return(tt.currentBranch, nil)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}, nil)
+			tt.setupMocks(mockGit, mockGH)
+
+			err := runFold(mockGit, mockGH)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+)
+
+// configForge picks GitHub or GitLab when the origin host doesn't say which it is,
+// e.g. a self-hosted GitLab at git.example.com
+const configForge = "stack.forge"
+
+// configureForge applies stack.forge. An unknown value is reported and the forge
+// is detected from the origin URL instead.
+func configureForge(gitClient git.GitClient) {
+	value := gitClient.GetConfig(configForge)
+	switch value {
+	case "", forge.GitHub, forge.GitLab:
+		forge.Override = value
+	default:
+		forge.Override = ""
+		fmt.Fprintln(os.Stderr, i18n.T("error", fmt.Errorf("%s: unknown forge %q (expected github or gitlab)", configForge, value)))
+	}
+}
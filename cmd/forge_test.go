@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureForge(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { forge.Override = "" }()
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"detected from origin when unset", "", ""},
+		{"gitlab", "gitlab", forge.GitLab},
+		{"github", "github", forge.GitHub},
+		{"unknown value is ignored", "bitbucket", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forge.Override = "stale"
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", configForge).Return(tt.value)
+
+			configureForge(mockGit)
+
+			assert.Equal(t, tt.expected, forge.Override)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// seriesFile lists the branches of an exported stack, bottom first
+const seriesFile = "stack-series"
+
+var (
+	formatPatchOutput string
+	amOnto            string
+)
+
+var formatPatchCmd = &cobra.Command{
+	Use:   "format-patch",
+	Short: i18n.T("formatPatch.short"),
+	Long:  i18n.T("formatPatch.long"),
+	Example: `  # Stack: main -> feature-a -> feature-b (current)
+  stack format-patch
+  # patches/stack-series
+  # patches/01-feature-a/0001-Add-login.patch
+  # patches/02-feature-b/0001-Add-logout.patch
+
+  # Write the series somewhere else
+  stack format-patch -o /tmp/auth-stack`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runFormatPatch(gitClient, formatPatchOutput); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var amCmd = &cobra.Command{
+	Use:   "am <directory>",
+	Short: i18n.T("am.short"),
+	Long:  i18n.T("am.long"),
+	Example: `  # Rebuild the stack exported to ./patches
+  stack am patches
+
+  # Start the stack from a different branch
+  stack am /tmp/auth-stack --onto release-2.0`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runAm(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	formatPatchCmd.Flags().StringVarP(&formatPatchOutput, "output-directory", "o", "patches", "Directory to write the series to")
+	amCmd.Flags().StringVar(&amOnto, "onto", "", "Branch to start the bottom of the stack from")
+}
+
+// seriesEntry is one branch of an exported stack
+type seriesEntry struct {
+	Dir    string // Directory holding the branch's patches, relative to the series
+	Branch string
+	Parent string
+}
+
+// formatSeries renders the stack-series file
+func formatSeries(entries []seriesEntry) string {
+	var b strings.Builder
+	b.WriteString("# Stack exported by 'stack format-patch', bottom first: <directory> <branch> <parent>\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s %s %s\n", e.Dir, e.Branch, e.Parent)
+	}
+	return b.String()
+}
+
+// parseSeries reads a stack-series file, ignoring blank lines and comments
+func parseSeries(content string) ([]seriesEntry, error) {
+	var entries []seriesEntry
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s line %d: expected '<directory> <branch> <parent>', got %q", seriesFile, n, line)
+		}
+		if filepath.IsAbs(fields[0]) || strings.Contains(fields[0], "..") {
+			return nil, fmt.Errorf("%s line %d: directory %q must be inside the series", seriesFile, n, fields[0])
+		}
+		entries = append(entries, seriesEntry{Dir: fields[0], Branch: fields[1], Parent: fields[2]})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%s lists no branches", seriesFile)
+	}
+	return entries, nil
+}
+
+// seriesDir is the directory name for the branch at position i (from 0)
+func seriesDir(i int, branch string) string {
+	return fmt.Sprintf("%02d-%s", i+1, strings.ReplaceAll(branch, "/", "-"))
+}
+
+// workDirPath resolves a path given on the command line against --chdir
+func workDirPath(path string) string {
+	if filepath.IsAbs(path) || git.WorkDir == "" {
+		return path
+	}
+	return filepath.Join(git.WorkDir, path)
+}
+
+func runFormatPatch(gitClient git.GitClient, outputDir string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return &stack.NotInStackError{Branch: currentBranch}
+	}
+
+	outputDir = workDirPath(outputDir)
+	manifest := filepath.Join(outputDir, seriesFile)
+	if _, err := os.Stat(manifest); err == nil {
+		return fmt.Errorf("%s already exists, remove it or pick another directory with -o", manifest)
+	}
+
+	// The bottom branch is exported against the remote base, so base commits that
+	// haven't been pulled yet aren't in its patches
+	baseBranch := chain[0]
+	exportBase := baseBranch
+	if gitClient.RemoteBranchExists(baseBranch) {
+		exportBase = git.RemoteRef(baseBranch)
+	}
+
+	var entries []seriesEntry
+	for i, branch := range chain[1:] {
+		parent := chain[i]
+		from := parent
+		if i == 0 {
+			from = exportBase
+		}
+
+		entry := seriesEntry{Dir: seriesDir(i, branch), Branch: branch, Parent: parent}
+		files, err := gitClient.FormatPatch(from, branch, filepath.Join(outputDir, entry.Dir))
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", branch, err)
+		}
+		entries = append(entries, entry)
+		if !git.DryRun {
+			fmt.Printf("%s %s %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.Dim(fmt.Sprintf("(%d patch(es) in %s)", len(files), entry.Dir)))
+		}
+	}
+
+	if git.DryRun {
+		dryrun.Record(dryrun.Other)
+		fmt.Printf("  [DRY RUN] write %s\n", manifest)
+		return nil
+	}
+	if err := os.WriteFile(manifest, []byte(formatSeries(entries)), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", manifest, err)
+	}
+
+	fmt.Printf("\nExported %d branch(es) to %s\n", len(entries), outputDir)
+	fmt.Printf("Rebuild the stack elsewhere with '%s'\n", ui.Command("stack am "+outputDir))
+	return nil
+}
+
+func runAm(gitClient git.GitClient, dir string) error {
+	dir = workDirPath(dir)
+	content, err := os.ReadFile(filepath.Join(dir, seriesFile))
+	if err != nil {
+		return fmt.Errorf("failed to read the series: %w", err)
+	}
+	entries, err := parseSeries(string(content))
+	if err != nil {
+		return err
+	}
+
+	if op := gitClient.GetOperationInProgress(); op != nil {
+		return fmt.Errorf("a %s is in progress; finish it with 'git %s --continue' or 'git %s --abort' first", op.Kind, op.Kind, op.Kind)
+	}
+	clean, err := gitClient.IsWorkingTreeClean()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if !clean {
+		return fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+	}
+
+	// Validate everything before creating the first branch
+	inSeries := make(map[string]bool)
+	for _, e := range entries {
+		if err := validateBranchName(gitClient, e.Branch); err != nil {
+			return err
+		}
+		inSeries[e.Branch] = true
+	}
+	onto := amOnto
+	if onto != "" && !gitClient.BranchExists(onto) {
+		return fmt.Errorf("branch %s does not exist", onto)
+	}
+
+	for _, e := range entries {
+		parent := e.Parent
+		if !inSeries[parent] {
+			switch {
+			case onto != "":
+				parent = onto
+			case !gitClient.BranchExists(parent):
+				parent = stack.GetBaseBranch(gitClient)
+			}
+		}
+
+		if gitClient.BranchExists(e.Branch) {
+			fmt.Printf("%s %s %s\n", ui.SuccessIcon(), ui.Branch(e.Branch), ui.Dim("(already exists, skipped)"))
+			continue
+		}
+
+		patches, err := filepath.Glob(filepath.Join(dir, e.Dir, "*.patch"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(patches)
+
+		if err := gitClient.CreateBranchAndCheckout(e.Branch, parent); err != nil {
+			return fmt.Errorf("failed to create %s: %w", e.Branch, err)
+		}
+		if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", e.Branch), parent); err != nil {
+			return fmt.Errorf("failed to set parent of %s: %w", e.Branch, err)
+		}
+		if len(patches) > 0 {
+			if err := gitClient.ApplyPatches(patches); err != nil {
+				fmt.Fprintf(os.Stderr, "%s %s: %v\n\n", ui.ErrorIcon(), ui.Branch(e.Branch), err)
+				fmt.Fprintln(os.Stderr, i18n.T("am.conflict.stopped"))
+				fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("am.conflict.resolve"))
+				fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("am.conflict.continue", ui.Command("git am --continue")))
+				fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("am.conflict.rerun", ui.Command("stack am "+dir)))
+				fmt.Fprintln(os.Stderr, i18n.T("am.conflict.abort", ui.Command("git am --abort"), ui.Command("git branch -D "+e.Branch)))
+				return ui.Reported(fmt.Errorf("failed to apply the patches of %s: %w", e.Branch, err))
+			}
+		}
+		fmt.Printf("%s %s %s\n", ui.SuccessIcon(), ui.Branch(e.Branch), ui.Dim(fmt.Sprintf("(%d patch(es) on %s)", len(patches), parent)))
+	}
+
+	fmt.Printf("\nRebuilt %d branch(es). Push them and create PRs with '%s'\n", len(entries), ui.Command("stack sync"))
+	return nil
+}
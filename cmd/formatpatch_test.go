@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseSeries(t *testing.T) {
+	entries := []seriesEntry{
+		{Dir: "01-feature-a", Branch: "feature-a", Parent: "main"},
+		{Dir: "02-user-feature-b", Branch: "user/feature-b", Parent: "feature-a"},
+	}
+
+	parsed, err := parseSeries(formatSeries(entries))
+	assert.NoError(t, err)
+	assert.Equal(t, entries, parsed)
+
+	_, err = parseSeries("01-feature-a feature-a\n")
+	assert.Error(t, err)
+
+	_, err = parseSeries("../outside feature-a main\n")
+	assert.Error(t, err)
+
+	_, err = parseSeries("# nothing here\n")
+	assert.Error(t, err)
+}
+
+func TestRunFormatPatch(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	dir := t.TempDir()
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCurrentBranch").Return("user/feature-b", nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a":      "main",
+		"user/feature-b": "feature-a",
+	}, nil)
+	mockGit.On("RemoteBranchExists", "main").Return(true)
+	mockGit.On("FormatPatch", "origin/main", "feature-a", filepath.Join(dir, "01-feature-a")).Return([]string{"0001-a.patch"}, nil)
+	mockGit.On("FormatPatch", "feature-a", "user/feature-b", filepath.Join(dir, "02-user-feature-b")).Return([]string{"0001-b.patch"}, nil)
+
+	assert.NoError(t, runFormatPatch(mockGit, dir))
+	mockGit.AssertExpectations(t)
+
+	content, err := os.ReadFile(filepath.Join(dir, seriesFile))
+	assert.NoError(t, err)
+	entries, err := parseSeries(string(content))
+	assert.NoError(t, err)
+	assert.Equal(t, []seriesEntry{
+		{Dir: "01-feature-a", Branch: "feature-a", Parent: "main"},
+		{Dir: "02-user-feature-b", Branch: "user/feature-b", Parent: "feature-a"},
+	}, entries)
+
+	t.Run("refuses to overwrite a series", func(t *testing.T) {
+		assert.Error(t, runFormatPatch(mockGit, dir))
+	})
+}
+
+// writeTestSeries lays out a series with one patch per branch
+func writeTestSeries(t *testing.T) string {
+	dir := t.TempDir()
+	entries := []seriesEntry{
+		{Dir: "01-feature-a", Branch: "feature-a", Parent: "develop"},
+		{Dir: "02-feature-b", Branch: "feature-b", Parent: "feature-a"},
+	}
+	for _, e := range entries {
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, e.Dir), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, e.Dir, "0001-change.patch"), []byte("patch"), 0o644))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, seriesFile), []byte(formatSeries(entries)), 0o644))
+	return dir
+}
+
+func TestRunAm(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name        string
+		onto        string
+		setupMocks  func(mockGit *testutil.MockGitClient, dir string)
+		expectError bool
+	}{
+		{
+			name: "creates each branch on its parent and applies its patches",
+			setupMocks: func(mockGit *testutil.MockGitClient, dir string) {
+				// The exported parent doesn't exist here, so the stack starts from the base
+				mockGit.On("BranchExists", "develop").Return(false)
+				mockGit.On("GetConfig", "stack.baseBranch").Return("main")
+				mockGit.On("BranchExists", "feature-a").Return(false)
+				mockGit.On("BranchExists", "feature-b").Return(false)
+				mockGit.On("CreateBranchAndCheckout", "feature-a", "main").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "main").Return(nil)
+				mockGit.On("ApplyPatches", []string{filepath.Join(dir, "01-feature-a", "0001-change.patch")}).Return(nil)
+				mockGit.On("CreateBranchAndCheckout", "feature-b", "feature-a").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+				mockGit.On("ApplyPatches", []string{filepath.Join(dir, "02-feature-b", "0001-change.patch")}).Return(nil)
+			},
+		},
+		{
+			name: "skips branches that already exist",
+			onto: "release",
+			setupMocks: func(mockGit *testutil.MockGitClient, dir string) {
+				// feature-a isn't created again, only feature-b gets patches
+				mockGit.On("BranchExists", "release").Return(true)
+				mockGit.On("BranchExists", "feature-a").Return(true)
+				mockGit.On("BranchExists", "feature-b").Return(false)
+				mockGit.On("CreateBranchAndCheckout", "feature-b", "feature-a").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+				mockGit.On("ApplyPatches", mock.Anything).Return(nil).Once()
+			},
+		},
+		{
+			name: "stops when a patch doesn't apply",
+			setupMocks: func(mockGit *testutil.MockGitClient, dir string) {
+				// feature-b isn't created once feature-a fails
+				mockGit.On("BranchExists", "develop").Return(true)
+				mockGit.On("BranchExists", "feature-a").Return(false)
+				mockGit.On("CreateBranchAndCheckout", "feature-a", "develop").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "develop").Return(nil)
+				mockGit.On("ApplyPatches", mock.Anything).Return(assert.AnError)
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTestSeries(t)
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetOperationInProgress").Return(nil)
+			mockGit.On("IsWorkingTreeClean").Return(true, nil)
+			mockGit.On("CheckBranchName", mock.Anything).Return(nil)
+			tt.setupMocks(mockGit, dir)
+
+			amOnto = tt.onto
+			defer func() { amOnto = "" }()
+
+			err := runAm(mockGit, dir)
+
+			if tt.expectError {
+				assert.True(t, isAlreadyReported(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
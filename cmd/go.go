@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var goCmd = &cobra.Command{
+	Use:   "go [n]",
+	Short: i18n.T("go.short"),
+	Long:  i18n.T("go.long"),
+	Example: `  # List the stack and pick a branch
+  stack go
+
+  # Jump to the third branch from the bottom
+  stack go 3`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		target := ""
+		if len(args) == 1 {
+			target = args[0]
+		}
+		if err := runGo(gitClient, target); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runGo(gitClient git.GitClient, target string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	if parents[currentBranch] == "" {
+		return errors.New(i18n.T("go.notInStack", currentBranch))
+	}
+
+	chain := branchLine(parents, currentBranch)
+	if target == "" {
+		for i, branch := range chain {
+			marker := ""
+			if branch == currentBranch {
+				marker = " *"
+			}
+			fmt.Printf("  %d) %s%s\n", i+1, ui.Branch(branch), marker)
+		}
+		fmt.Print("\n" + i18n.T("down.select", len(chain)))
+
+		input, err := bufio.NewReader(stdinReader).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		target = strings.TrimSpace(input)
+	}
+
+	n, err := strconv.Atoi(target)
+	if err != nil || n < 1 || n > len(chain) {
+		return errors.New(i18n.T("go.outOfRange", target, len(chain)))
+	}
+
+	branch := chain[n-1]
+	if branch == currentBranch {
+		fmt.Printf("Already on %s\n", ui.Branch(branch))
+		return nil
+	}
+	if err := gitClient.CheckoutBranch(branch); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", branch, err)
+	}
+	fmt.Println(i18n.T("go.switched", ui.Branch(branch), n, len(chain)))
+	return nil
+}
+
+// branchLine returns the branches from the bottom of branch's stack up through
+// branch, then on to its tip for as long as each branch has a single child
+func branchLine(parents map[string]string, branch string) []string {
+	seen := map[string]bool{}
+	var line []string
+	for b := branch; parents[b] != "" && !seen[b]; b = parents[b] {
+		seen[b] = true
+		line = append([]string{b}, line...)
+	}
+
+	children := make(map[string][]string)
+	for child, parent := range parents {
+		children[parent] = append(children[parent], child)
+	}
+	for {
+		next := children[line[len(line)-1]]
+		if len(next) != 1 || seen[next[0]] {
+			break
+		}
+		seen[next[0]] = true
+		line = append(line, next[0])
+	}
+	return line
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBranchLine(t *testing.T) {
+	parents := map[string]string{
+		"feature-a":   "main",
+		"feature-b":   "feature-a",
+		"feature-c":   "feature-b",
+		"feature-c-1": "feature-c",
+		"feature-c-2": "feature-c",
+		"other":       "main",
+	}
+
+	assert.Equal(t, []string{"feature-a", "feature-b", "feature-c"}, branchLine(parents, "feature-a"))
+	assert.Equal(t, []string{"feature-a", "feature-b", "feature-c", "feature-c-2"}, branchLine(parents, "feature-c-2"))
+	assert.Equal(t, []string{"other"}, branchLine(parents, "other"))
+}
+
+func TestRunGo(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		currentBranch string
+		target        string
+		input         string
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:   "jumps to the given branch",
+			target: "3",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "feature-c").Return(nil)
+			},
+		},
+		{
+			name:  "prompts without a number",
+			input: "1\n",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+			},
+		},
+		{
+			name:   "stays on the current branch",
+			target: "2",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CheckoutBranch: feature-b is already checked out
+			},
+		},
+		{
+			name:          "rejects zero",
+			target:        "0",
+			setupMocks:    func(mockGit *testutil.MockGitClient) {},
+			expectError:   true,
+			errorContains: "invalid branch number",
+		},
+		{
+			name:          "rejects numbers past the top",
+			target:        "4",
+			setupMocks:    func(mockGit *testutil.MockGitClient) {},
+			expectError:   true,
+			errorContains: "invalid branch number",
+		},
+		{
+			name:          "rejects words",
+			target:        "top",
+			setupMocks:    func(mockGit *testutil.MockGitClient) {},
+			expectError:   true,
+			errorContains: "invalid branch number",
+		},
+		{
+			name:          "refuses outside a stack",
+			currentBranch: "main",
+			target:        "1",
+			setupMocks:    func(mockGit *testutil.MockGitClient) {},
+			expectError:   true,
+			errorContains: "not in a stack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.input != "" {
+				stdinReader = strings.NewReader(tt.input)
+				defer func() { stdinReader = os.Stdin }()
+			}
+			currentBranch := tt.currentBranch
+			if currentBranch == "" {
+				currentBranch = "feature-b"
+			}
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return(currentBranch, nil)
+			mockGit.On("GetAllStackParents").Return(map[string]string{
+				"feature-a": "main",
+				"feature-b": "feature-a",
+				"feature-c": "feature-b",
+			}, nil)
+			tt.setupMocks(mockGit)
+
+			err := runGo(mockGit, tt.target)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hookForce     bool
+	hookPreCommit bool
+)
+
+// hookMarker identifies hooks written by 'stack hook install', so they can be
+// replaced or removed without touching a hook someone else wrote
+const hookMarker = "# Installed by 'stack hook install'"
+
+// prePushHook runs 'stack check' on every push. It's skipped when stack isn't on
+// PATH, so a clone shared with tools that don't have it still pushes.
+const prePushHook = `#!/bin/sh
+` + hookMarker + `: checks the stack before pushing.
+# Skip it once with 'git push --no-verify', remove it with 'stack hook uninstall'.
+command -v stack >/dev/null 2>&1 || exit 0
+STACKINATOR_READONLY=1 exec stack check --pre-push "$@"
+`
+
+// preCommitHook warns about commits made directly on the base branch while
+// stacks exist. It never blocks the commit.
+const preCommitHook = `#!/bin/sh
+` + hookMarker + `: warns about commits on the base branch.
+# Remove it with 'stack hook uninstall'.
+command -v stack >/dev/null 2>&1 || exit 0
+STACKINATOR_READONLY=1 exec stack check --pre-commit
+`
+
+// stackHooks are the hooks stack can install, by name
+var stackHooks = map[string]string{
+	"pre-push":   prePushHook,
+	"pre-commit": preCommitHook,
+}
+
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: i18n.T("hook.short"),
+	Long:  i18n.T("hook.long"),
+	Example: `  # Check the stack on every push
+  stack hook install
+
+  # Also warn about commits on the base branch
+  stack hook install --pre-commit
+
+  # Remove the hook again
+  stack hook uninstall`,
+}
+
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: i18n.T("hookInstall.short"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runHookInstall(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: i18n.T("hookUninstall.short"),
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runHookUninstall(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	hookInstallCmd.Flags().BoolVar(&hookForce, "force", false, "Replace existing hooks that weren't installed by stack")
+	hookInstallCmd.Flags().BoolVar(&hookPreCommit, "pre-commit", false, "Also install a pre-commit hook that warns about commits on the base branch")
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+}
+
+func runHookInstall(gitClient git.GitClient) error {
+	names := []string{"pre-push"}
+	if hookPreCommit {
+		names = append(names, "pre-commit")
+	}
+	for _, name := range names {
+		if err := installHook(gitClient, name); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Skip the pre-push check for a single push with '%s'\n", ui.Command("git push --no-verify"))
+	return nil
+}
+
+// installHook writes one of stackHooks, refusing to replace a hook someone else
+// wrote unless --force is given
+func installHook(gitClient git.GitClient, name string) error {
+	path, err := hookPath(gitClient, name)
+	if err != nil {
+		return err
+	}
+	script := stackHooks[name]
+
+	if existing, err := os.ReadFile(path); err == nil {
+		if string(existing) == script {
+			fmt.Printf("%s %s hook is already installed\n", ui.SuccessIcon(), name)
+			return nil
+		}
+		if !strings.Contains(string(existing), hookMarker) && !hookForce {
+			return fmt.Errorf("%s already exists and wasn't installed by stack\n\nAdd '%s' to it yourself, or replace it with --force", path, ui.Command(hookCommand(script)))
+		}
+	}
+
+	if dryRun {
+		dryrun.Record(dryrun.Other)
+		fmt.Printf("  [DRY RUN] Writing %s\n", path)
+		return nil
+	}
+	if git.ReadOnly {
+		return fmt.Errorf("cannot write %s: %w", path, git.ErrReadOnly)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create hooks directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return fmt.Errorf("failed to write hook: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0o755); err != nil {
+		return fmt.Errorf("failed to make hook executable: %w", err)
+	}
+
+	fmt.Printf("%s Installed %s hook: %s\n", ui.SuccessIcon(), name, path)
+	return nil
+}
+
+func runHookUninstall(gitClient git.GitClient) error {
+	removed := 0
+	for _, name := range []string{"pre-push", "pre-commit"} {
+		path, err := hookPath(gitClient, name)
+		if err != nil {
+			return err
+		}
+
+		existing, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read hook: %w", err)
+		}
+		if !strings.Contains(string(existing), hookMarker) {
+			fmt.Printf("%s wasn't installed by stack; leaving it alone\n", path)
+			continue
+		}
+
+		removed++
+		if dryRun {
+			dryrun.Record(dryrun.Delete)
+			fmt.Printf("  [DRY RUN] Removing %s\n", path)
+			continue
+		}
+		if git.ReadOnly {
+			return fmt.Errorf("cannot remove %s: %w", path, git.ErrReadOnly)
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove hook: %w", err)
+		}
+		fmt.Printf("%s Removed %s hook\n", ui.SuccessIcon(), name)
+	}
+
+	if removed == 0 {
+		fmt.Println("No hooks installed by stack")
+	}
+	return nil
+}
+
+// hookPath returns where git looks for the named hook
+func hookPath(gitClient git.GitClient, name string) (string, error) {
+	dir, err := gitClient.GetHooksDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find hooks directory: %w", err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// hookCommand returns the line of a hook script that runs stack
+func hookCommand(script string) string {
+	lines := strings.Split(strings.TrimSpace(script), "\n")
+	return strings.TrimPrefix(lines[len(lines)-1], "STACKINATOR_READONLY=1 exec ")
+}
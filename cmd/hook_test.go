@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookInstall(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	foreign := "#!/bin/sh\nmake lint\n"
+
+	tests := []struct {
+		name        string
+		preCommit   bool
+		force       bool
+		existing    string
+		hook        string
+		expectError bool
+		want        string
+	}{
+		{
+			name: "installs the pre-push hook",
+			hook: "pre-push",
+			want: prePushHook,
+		},
+		{
+			name:      "installs the pre-commit hook with --pre-commit",
+			preCommit: true,
+			hook:      "pre-commit",
+			want:      preCommitHook,
+		},
+		{
+			name:        "leaves a foreign hook alone",
+			existing:    foreign,
+			hook:        "pre-push",
+			expectError: true,
+			want:        foreign,
+		},
+		{
+			name:     "replaces a foreign hook with --force",
+			force:    true,
+			existing: foreign,
+			hook:     "pre-push",
+			want:     prePushHook,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hookPreCommit, hookForce = tt.preCommit, tt.force
+			defer func() { hookPreCommit, hookForce = false, false }()
+
+			dir := filepath.Join(t.TempDir(), "hooks")
+			path := filepath.Join(dir, tt.hook)
+			if tt.existing != "" {
+				assert.NoError(t, os.MkdirAll(dir, 0o755))
+				assert.NoError(t, os.WriteFile(path, []byte(tt.existing), 0o644))
+			}
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetHooksDir").Return(dir, nil)
+
+			err := runHookInstall(mockGit)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				info, _ := os.Stat(path)
+				assert.NotZero(t, info.Mode()&0o100, "hook should be executable")
+			}
+			content, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+
+			// Uninstalling removes only the hooks stack installed
+			assert.NoError(t, runHookUninstall(mockGit))
+			_, err = os.Stat(path)
+			if tt.want == foreign {
+				assert.NoError(t, err)
+			} else {
+				assert.True(t, os.IsNotExist(err))
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	importAuthor    string
+	importWorktrees bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: i18n.T("import.short"),
+	Long:  i18n.T("import.long"),
+	Example: `  # Import your own stacks (e.g. on a new machine)
+  stack import
+
+  # Import a teammate's stacks into worktrees for review
+  stack import --author alice --worktrees
+
+  # Preview without executing
+  stack import --author alice --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runImport(gitClient, githubClient, importAuthor); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importAuthor, "author", "@me", "Import the stacks of this user's open PRs")
+	importCmd.Flags().BoolVar(&importWorktrees, "worktrees", false, "Check out each imported branch in a worktree under .worktrees/")
+}
+
+func runImport(gitClient git.GitClient, githubClient github.GitHubClient, author string) error {
+	var prs []*github.PRInfo
+	if err := spinner.WrapWithAutoDelay("Fetching PRs...", 300*time.Millisecond, func() error {
+		var err error
+		prs, err = githubClient.ListAuthoredPRs(author)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(prs) == 0 {
+		fmt.Println("No open PRs found.")
+		return nil
+	}
+
+	var repoRoot string
+	if importWorktrees {
+		var err error
+		repoRoot, err = gitClient.GetRepoRoot()
+		if err != nil {
+			return fmt.Errorf("failed to get repo root: %w", err)
+		}
+		if err := ensureWorktreesIgnored(repoRoot); err != nil {
+			return fmt.Errorf("failed to update .gitignore: %w", err)
+		}
+	}
+
+	// Import parents before children so each base exists before its children use it
+	stacks := groupPRsByStack(prs)
+	imported := 0
+	for _, s := range stacks {
+		for _, row := range s.rows {
+			if importBranch(gitClient, row.pr, repoRoot) {
+				imported++
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Success(fmt.Sprintf("Imported %d of %d branch(es)", imported, len(prs))))
+	fmt.Printf("\n%s\n", i18n.T("import.statusHint", ui.Command("stack status")))
+	return nil
+}
+
+// importBranch fetches a PR's head branch, creates it locally (or in a worktree when
+// repoRoot is set) and records its base as the stack parent. An existing local
+// branch is brought up to the PR head. Failures are reported as warnings so the
+// rest of the stacks can still be imported.
+func importBranch(gitClient git.GitClient, pr *github.PRInfo, repoRoot string) bool {
+	branch := pr.Head
+	fmt.Printf("Importing %s (PR #%d, parent %s)...\n", ui.Branch(branch), pr.Number, ui.Branch(pr.Base))
+
+	// Heads from forks aren't on origin, so there is nothing to fetch
+	if err := gitClient.FetchBranch(branch); err != nil {
+		fmt.Fprintf(os.Stderr, "  %s Skipping: could not fetch %s from origin (PR from a fork?): %v\n", ui.WarningIcon(), branch, err)
+		return false
+	}
+
+	exists := gitClient.BranchExists(branch)
+	if exists {
+		updateImportedBranch(gitClient, branch)
+	}
+
+	if repoRoot != "" {
+		worktreePath := filepath.Join(repoRoot, ".worktrees", branch)
+		if _, err := os.Stat(worktreePath); err == nil {
+			fmt.Printf("  Worktree already exists at %s\n", worktreePath)
+		} else {
+			var err error
+			if exists {
+				err = gitClient.AddWorktree(worktreePath, branch)
+			} else {
+				err = gitClient.AddWorktreeFromRemote(worktreePath, branch)
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "  %s Skipping: failed to create worktree: %v\n", ui.WarningIcon(), err)
+				return false
+			}
+			fmt.Printf("  Created worktree at %s\n", worktreePath)
+		}
+	} else if !exists {
+		if err := gitClient.CreateBranch(branch, git.RemoteRef(branch)); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s Skipping: failed to create branch: %v\n", ui.WarningIcon(), err)
+			return false
+		}
+	}
+
+	configKey := fmt.Sprintf("branch.%s.stackparent", branch)
+	if gitClient.GetConfig(configKey) != pr.Base {
+		if err := gitClient.SetConfig(configKey, pr.Base); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s Failed to set stack parent: %v\n", ui.WarningIcon(), err)
+			return false
+		}
+	}
+
+	// Pin the PR so it is still found if the head branch is renamed later
+	if err := gitClient.SetConfig(syncer.StackPRKey(branch), strconv.Itoa(pr.Number)); err != nil && verbose {
+		fmt.Printf("  Note: could not pin PR #%d to %s: %v\n", pr.Number, branch, err)
+	}
+
+	fmt.Printf("  %s Tracked with parent %s\n", ui.SuccessIcon(), ui.Branch(pr.Base))
+	return true
+}
+
+// updateImportedBranch fast-forwards an existing local branch to the PR head just
+// fetched. A branch with commits of its own, or one checked out in a worktree, is
+// left alone and reported instead, so no local work is lost.
+func updateImportedBranch(gitClient git.GitClient, branch string) {
+	remoteRef := git.RemoteRef(branch)
+	local, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  %s Could not compare %s with the PR head: %v\n", ui.WarningIcon(), branch, err)
+		return
+	}
+	head, err := gitClient.GetCommitHash(remoteRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  %s Could not compare %s with the PR head: %v\n", ui.WarningIcon(), branch, err)
+		return
+	}
+	if local == head {
+		return
+	}
+	base, err := gitClient.GetMergeBase(branch, remoteRef)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  %s Could not compare %s with the PR head: %v\n", ui.WarningIcon(), branch, err)
+		return
+	}
+
+	switch base {
+	case local:
+		worktrees, err := gitClient.GetWorktreeBranches()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %s Could not list worktrees: %v\n", ui.WarningIcon(), err)
+			return
+		}
+		if path, ok := worktrees[branch]; ok {
+			fmt.Fprintf(os.Stderr, "  %s %s is behind the PR head but checked out at %s, update it there with '%s'\n",
+				ui.WarningIcon(), ui.Branch(branch), path, ui.Command("git merge --ff-only "+remoteRef))
+			return
+		}
+		if err := gitClient.ForceBranch(branch, remoteRef); err != nil {
+			fmt.Fprintf(os.Stderr, "  %s Failed to fast-forward %s to the PR head: %v\n", ui.WarningIcon(), branch, err)
+			return
+		}
+		fmt.Printf("  Fast-forwarded %s to the PR head\n", ui.Branch(branch))
+	case head:
+		fmt.Printf("  %s has local commits that aren't in the PR yet, kept as is\n", ui.Branch(branch))
+	default:
+		fmt.Fprintf(os.Stderr, "  %s %s differs from the PR head (%s), kept as is: compare them before syncing\n",
+			ui.WarningIcon(), ui.Branch(branch), remoteRef)
+	}
+}
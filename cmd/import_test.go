@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunImport(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	prs := []*github.PRInfo{
+		newAuthoredPR(2, "alice/feature-b", "alice/feature-a"),
+		newAuthoredPR(1, "alice/feature-a", "main"),
+		newAuthoredPR(3, "fork-branch", "main"),
+	}
+
+	t.Run("creates branches and records parents", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		testutil.AllowPRPins(mockGit)
+
+		mockGH.On("ListAuthoredPRs", "alice").Return(prs, nil)
+
+		// feature-a is new locally
+		mockGit.On("FetchBranch", "alice/feature-a").Return(nil)
+		mockGit.On("BranchExists", "alice/feature-a").Return(false)
+		mockGit.On("CreateBranch", "alice/feature-a", "origin/alice/feature-a").Return(nil)
+		mockGit.On("GetConfig", "branch.alice/feature-a.stackparent").Return("")
+		mockGit.On("SetConfig", "branch.alice/feature-a.stackparent", "main").Return(nil)
+
+		// feature-b already exists and is tracked
+		mockGit.On("FetchBranch", "alice/feature-b").Return(nil)
+		mockGit.On("BranchExists", "alice/feature-b").Return(true)
+		mockGit.On("GetConfig", "branch.alice/feature-b.stackparent").Return("alice/feature-a")
+		mockGit.On("GetCommitHash", "alice/feature-b").Return("b1", nil)
+		mockGit.On("GetCommitHash", "origin/alice/feature-b").Return("b1", nil)
+
+		// Branch from a fork can't be fetched from origin
+		mockGit.On("FetchBranch", "fork-branch").Return(errors.New("couldn't find remote ref"))
+
+		err := runImport(mockGit, mockGH, "alice")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "CreateBranch", "alice/feature-b", "origin/alice/feature-b")
+	})
+
+	t.Run("fast-forwards an existing branch to the PR head", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		testutil.AllowPRPins(mockGit)
+
+		mockGH.On("ListAuthoredPRs", "alice").Return(prs[1:2], nil)
+		mockGit.On("FetchBranch", "alice/feature-a").Return(nil)
+		mockGit.On("BranchExists", "alice/feature-a").Return(true)
+		mockGit.On("GetCommitHash", "alice/feature-a").Return("old", nil)
+		mockGit.On("GetCommitHash", "origin/alice/feature-a").Return("new", nil)
+		mockGit.On("GetMergeBase", "alice/feature-a", "origin/alice/feature-a").Return("old", nil)
+		mockGit.On("GetWorktreeBranches").Return(map[string]string{"main": "/repo"}, nil)
+		mockGit.On("ForceBranch", "alice/feature-a", "origin/alice/feature-a").Return(nil)
+		mockGit.On("GetConfig", "branch.alice/feature-a.stackparent").Return("main")
+
+		err := runImport(mockGit, mockGH, "alice")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("keeps an existing branch with local commits", func(t *testing.T) {
+		for name, base := range map[string]string{"ahead": "new", "diverged": "fork"} {
+			t.Run(name, func(t *testing.T) {
+				mockGit := new(testutil.MockGitClient)
+				mockGH := new(testutil.MockGitHubClient)
+				testutil.AllowPRPins(mockGit)
+
+				mockGH.On("ListAuthoredPRs", "alice").Return(prs[1:2], nil)
+				mockGit.On("FetchBranch", "alice/feature-a").Return(nil)
+				mockGit.On("BranchExists", "alice/feature-a").Return(true)
+				mockGit.On("GetCommitHash", "alice/feature-a").Return("local", nil)
+				mockGit.On("GetCommitHash", "origin/alice/feature-a").Return("new", nil)
+				mockGit.On("GetMergeBase", "alice/feature-a", "origin/alice/feature-a").Return(base, nil)
+				mockGit.On("GetConfig", "branch.alice/feature-a.stackparent").Return("main")
+
+				err := runImport(mockGit, mockGH, "alice")
+
+				assert.NoError(t, err)
+				mockGit.AssertExpectations(t)
+				mockGit.AssertNotCalled(t, "ForceBranch", mock.Anything, mock.Anything)
+			})
+		}
+	})
+
+	t.Run("leaves a checked out branch behind the PR head alone", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		testutil.AllowPRPins(mockGit)
+
+		mockGH.On("ListAuthoredPRs", "alice").Return(prs[1:2], nil)
+		mockGit.On("FetchBranch", "alice/feature-a").Return(nil)
+		mockGit.On("BranchExists", "alice/feature-a").Return(true)
+		mockGit.On("GetCommitHash", "alice/feature-a").Return("old", nil)
+		mockGit.On("GetCommitHash", "origin/alice/feature-a").Return("new", nil)
+		mockGit.On("GetMergeBase", "alice/feature-a", "origin/alice/feature-a").Return("old", nil)
+		mockGit.On("GetWorktreeBranches").Return(map[string]string{"alice/feature-a": "/repo"}, nil)
+		mockGit.On("GetConfig", "branch.alice/feature-a.stackparent").Return("main")
+
+		err := runImport(mockGit, mockGH, "alice")
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "ForceBranch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("into worktrees", func(t *testing.T) {
+		importWorktrees = true
+		defer func() { importWorktrees = false }()
+
+		repoRoot := t.TempDir()
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		testutil.AllowPRPins(mockGit)
+
+		mockGH.On("ListAuthoredPRs", "alice").Return(prs[1:2], nil)
+		mockGit.On("GetRepoRoot").Return(repoRoot, nil)
+		mockGit.On("FetchBranch", "alice/feature-a").Return(nil)
+		mockGit.On("BranchExists", "alice/feature-a").Return(false)
+		mockGit.On("AddWorktreeFromRemote", filepath.Join(repoRoot, ".worktrees", "alice/feature-a"), "alice/feature-a").Return(nil)
+		mockGit.On("GetConfig", "branch.alice/feature-a.stackparent").Return("")
+		mockGit.On("SetConfig", "branch.alice/feature-a.stackparent", "main").Return(nil)
+
+		err := runImport(mockGit, mockGH, "alice")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// interruptedSync is the state a sync leaves behind when it stops part way, e.g.
+// on a conflict, until it's resumed or aborted
+type interruptedSync struct {
+	Branch    string     `json:"branch"`
+	StartedAt *time.Time `json:"startedAt,omitempty"`
+}
+
+// readInterruptedSync returns the state of an interrupted sync, or nil when
+// there is none. Syncs started by older versions didn't record when.
+func readInterruptedSync(gitClient git.GitClient) *interruptedSync {
+	branch := gitClient.GetConfig(syncer.ConfigOriginalBranch)
+	if branch == "" {
+		return nil
+	}
+	state := &interruptedSync{Branch: branch}
+	if seconds, err := strconv.ParseInt(gitClient.GetConfig(syncer.ConfigStartedAt), 10, 64); err == nil {
+		startedAt := time.Unix(seconds, 0)
+		state.StartedAt = &startedAt
+	}
+	return state
+}
+
+// printInterruptedSync points out an interrupted sync, so its state (and maybe a
+// stash) doesn't linger unnoticed
+func printInterruptedSync(state *interruptedSync, now time.Time) {
+	if state.StartedAt == nil {
+		fmt.Println(ui.Warning(i18n.T("status.interruptedSyncNoTime", ui.Branch(state.Branch))))
+	} else {
+		fmt.Println(ui.Warning(i18n.T("status.interruptedSync", ui.Age(now.Sub(*state.StartedAt)), ui.Branch(state.Branch))))
+	}
+	fmt.Println(i18n.T("status.inProgressHint", ui.Command("stack sync --resume"), ui.Command("stack sync --abort")))
+}
+
+// completeSyncRecovery suggests --resume and --abort for 'stack sync <TAB>'
+// while an interrupted sync is waiting for one of them
+func completeSyncRecovery(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	state := readInterruptedSync(git.NewGitClient())
+	if state == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	started := ""
+	if state.StartedAt != nil {
+		started = fmt.Sprintf(" %s ago", ui.Age(time.Since(*state.StartedAt)))
+	}
+	return []string{
+		fmt.Sprintf("--resume\tResume the sync started%s on %s", started, state.Branch),
+		fmt.Sprintf("--abort\tAbort the sync started%s on %s", started, state.Branch),
+	}, cobra.ShellCompDirectiveNoFileComp
+}
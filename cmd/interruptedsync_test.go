@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadInterruptedSync(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("no sync state", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", syncer.ConfigOriginalBranch).Return("")
+
+		assert.Nil(t, readInterruptedSync(mockGit))
+	})
+
+	t.Run("with the start time", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", syncer.ConfigOriginalBranch).Return("feature-x")
+		mockGit.On("GetConfig", syncer.ConfigStartedAt).Return("1700000000")
+
+		state := readInterruptedSync(mockGit)
+
+		require.NotNil(t, state)
+		assert.Equal(t, "feature-x", state.Branch)
+		require.NotNil(t, state.StartedAt)
+		assert.True(t, state.StartedAt.Equal(time.Unix(1700000000, 0)))
+	})
+
+	t.Run("started by a version that didn't record when", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", syncer.ConfigOriginalBranch).Return("feature-x")
+		mockGit.On("GetConfig", syncer.ConfigStartedAt).Return("")
+
+		state := readInterruptedSync(mockGit)
+
+		require.NotNil(t, state)
+		assert.Nil(t, state.StartedAt)
+	})
+}
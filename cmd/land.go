@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// configLandMethod is how 'stack land' merges PRs: squash (the default), merge
+// or rebase
+const configLandMethod = "stack.land.method"
+
+const defaultLandTimeout = 30 * time.Minute
+
+var (
+	// landMethod is the merge method, falling back to stack.land.method
+	landMethod string
+	// landTimeout bounds the wait for the PR to be merged
+	landTimeout time.Duration
+
+	// landPollInterval is how often the PR is polled while waiting for the merge,
+	// and landSleep how we wait in between (replaced in tests)
+	landPollInterval = 10 * time.Second
+	landSleep        = time.Sleep
+)
+
+var landCmd = &cobra.Command{
+	Use:   "land",
+	Short: i18n.T("land.short"),
+	Long:  i18n.T("land.long"),
+	Example: `  # Merge the bottom PR and restack
+  stack land
+
+  # Use a merge commit instead of squashing
+  stack land --method merge
+
+  # Preview the merge
+  stack land --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if !cmd.Flags().Changed("method") {
+			landMethod = gitClient.GetConfig(configLandMethod)
+		}
+
+		landed, err := runLand(gitClient, githubClient)
+		if err != nil {
+			exitWithError(err)
+		}
+		if !landed {
+			return
+		}
+
+		// Restack with the same settings as 'stack sync'
+		fmt.Println()
+		syncCmd.Run(syncCmd, nil)
+	},
+}
+
+func init() {
+	landCmd.Flags().StringVar(&landMethod, "method", "", "How to merge the PR: squash, merge or rebase (defaults to stack.land.method, or squash)")
+	landCmd.Flags().DurationVar(&landTimeout, "timeout", defaultLandTimeout, "How long to wait for the PR to be merged, e.g. when it's in a merge queue")
+}
+
+// parseLandMethod reads a merge method, squash when empty
+func parseLandMethod(value string) (string, error) {
+	switch value {
+	case "":
+		return "squash", nil
+	case "squash", "merge", "rebase":
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid merge method %q (use squash, merge or rebase)", value)
+	}
+}
+
+// runLand merges the PR of the bottom branch of the current stack and waits for
+// the merge. It reports whether the stack should now be synced, which it
+// shouldn't in dry-run mode.
+func runLand(gitClient git.GitClient, githubClient github.GitHubClient) (bool, error) {
+	method, err := parseLandMethod(landMethod)
+	if err != nil {
+		return false, err
+	}
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return false, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return false, fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return false, &stack.NotInStackError{Branch: currentBranch}
+	}
+	baseBranch, bottom := chain[0], chain[1]
+
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	if umbrellas[bottom] {
+		return false, fmt.Errorf("%s is an umbrella branch and has no PR to land", bottom)
+	}
+
+	pr, err := githubClient.GetPRForBranch(bottom)
+	if err != nil {
+		return false, fmt.Errorf("failed to get PR for %s: %w", bottom, err)
+	}
+	if pr == nil {
+		return false, fmt.Errorf("%s has no PR, open one with '%s'", bottom, ui.Command("stack submit"))
+	}
+
+	switch pr.State {
+	case "MERGED":
+		fmt.Printf("PR #%d for %s is already %s\n", pr.Number, ui.Branch(bottom), ui.PRState(pr.State))
+	case "OPEN":
+		if pr.Base != baseBranch {
+			return false, errors.New(i18n.T("land.wrongBase", pr.Number, pr.Base, baseBranch, ui.Command("stack sync")))
+		}
+		if err := checkLandedBranchPushed(gitClient, bottom); err != nil {
+			return false, err
+		}
+
+		if err := spinner.WrapWithSuccess(
+			fmt.Sprintf("Merging PR #%d (%s) into %s...", pr.Number, bottom, baseBranch),
+			fmt.Sprintf("Requested merge of PR #%d", pr.Number),
+			func() error { return githubClient.MergePR(pr.Number, method) },
+		); err != nil {
+			return false, fmt.Errorf("failed to merge PR #%d: %w", pr.Number, err)
+		}
+		if dryRun {
+			fmt.Println("Dry run, not waiting for the merge or syncing the stack")
+			return false, nil
+		}
+
+		if err := spinner.WrapWithSuccess(
+			fmt.Sprintf("Waiting for PR #%d to merge...", pr.Number),
+			fmt.Sprintf("PR #%d merged", pr.Number),
+			func() error { return waitForMerge(githubClient, pr.Number, landTimeout) },
+		); err != nil {
+			return false, err
+		}
+	default:
+		return false, fmt.Errorf("PR #%d for %s is %s", pr.Number, bottom, pr.State)
+	}
+
+	// The sync only covers the stack of the current branch, which for the landed
+	// branch is just itself
+	if currentBranch == bottom {
+		children, err := stack.GetChildrenOf(gitClient, bottom)
+		if err != nil {
+			return false, fmt.Errorf("failed to get children of %s: %w", bottom, err)
+		}
+		switch len(children) {
+		case 0:
+		case 1:
+			if err := gitClient.CheckoutBranch(children[0].Name); err != nil {
+				return false, fmt.Errorf("failed to checkout %s: %w", children[0].Name, err)
+			}
+		default:
+			fmt.Println(i18n.T("land.severalChildren", len(children), ui.Branch(bottom), ui.Command("stack sync")))
+		}
+	}
+	return true, nil
+}
+
+// checkLandedBranchPushed makes sure origin has what's about to be merged, so no
+// local commit is left behind
+func checkLandedBranchPushed(gitClient git.GitClient, branch string) error {
+	local, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", branch, err)
+	}
+	remote, err := gitClient.GetCommitHash(git.RemoteRef(branch))
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", git.RemoteRef(branch), err)
+	}
+	if local != remote {
+		return errors.New(i18n.T("land.differs", branch, git.RemoteRef(branch), ui.Command("stack sync")))
+	}
+	return nil
+}
+
+// waitForMerge polls a PR until it's merged, failing when it gets closed instead
+// or timeout passes
+func waitForMerge(githubClient github.GitHubClient, prNumber int, timeout time.Duration) error {
+	start := time.Now()
+	for {
+		pr, err := githubClient.GetPRByNumber(prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to get PR #%d: %w", prNumber, err)
+		}
+		if pr != nil {
+			switch pr.State {
+			case "MERGED":
+				return nil
+			case "CLOSED":
+				return fmt.Errorf("PR #%d was closed without being merged", prNumber)
+			}
+		}
+
+		if time.Since(start) >= timeout {
+			return errors.New(i18n.T("land.notMerged", prNumber, timeout, ui.Command("stack sync")))
+		}
+		landSleep(landPollInterval)
+	}
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunLand(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { landSleep = time.Sleep }()
+	landSleep = func(time.Duration) {}
+	landTimeout = defaultLandTimeout
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+
+	t.Run("merges the bottom PR and waits for the merge queue", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"), nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa", nil)
+		mockGH.On("MergePR", 1, "squash").Return(nil)
+		mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"), nil).Once()
+		mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "MERGED", "main", "A", "url1"), nil).Once()
+
+		landed, err := runLand(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		assert.True(t, landed)
+		mockGit.AssertNotCalled(t, "CheckoutBranch", mock.Anything)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("checks out the branch above when landing the current branch", func(t *testing.T) {
+		landMethod = "merge"
+		defer func() { landMethod = "" }()
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"), nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa", nil)
+		mockGH.On("MergePR", 1, "merge").Return(nil)
+		mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "MERGED", "main", "A", "url1"), nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+
+		landed, err := runLand(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		assert.True(t, landed)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("an already merged PR is only synced", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "MERGED", "main", "A", "url1"), nil)
+
+		landed, err := runLand(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		assert.True(t, landed)
+		mockGH.AssertNotCalled(t, "MergePR", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses a branch that differs from origin", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"), nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa2", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa1", nil)
+
+		landed, err := runLand(mockGit, mockGH)
+
+		assert.ErrorContains(t, err, "feature-a differs from origin/feature-a")
+		assert.False(t, landed)
+		mockGH.AssertNotCalled(t, "MergePR", mock.Anything, mock.Anything)
+	})
+
+	t.Run("refuses a PR that doesn't target the base branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "develop", "A", "url1"), nil)
+
+		_, err := runLand(mockGit, mockGH)
+
+		assert.ErrorContains(t, err, "targets develop instead of main")
+	})
+
+	t.Run("fails when the PR is closed while waiting", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"), nil)
+		mockGit.On("GetCommitHash", mock.Anything).Return("aaa", nil)
+		mockGH.On("MergePR", 1, "squash").Return(nil)
+		mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "CLOSED", "main", "A", "url1"), nil)
+
+		landed, err := runLand(mockGit, mockGH)
+
+		assert.ErrorContains(t, err, "closed without being merged")
+		assert.False(t, landed)
+	})
+
+	t.Run("not in a stack", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("main", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+
+		_, err := runLand(mockGit, mockGH)
+
+		var notInStack *stack.NotInStackError
+		assert.ErrorAs(t, err, &notInStack)
+	})
+
+	t.Run("invalid merge method", func(t *testing.T) {
+		landMethod = "fast-forward"
+		defer func() { landMethod = "" }()
+
+		_, err := runLand(new(testutil.MockGitClient), new(testutil.MockGitHubClient))
+
+		assert.ErrorContains(t, err, "invalid merge method")
+	})
+}
+
+func TestWaitForMergeTimesOut(t *testing.T) {
+	defer func() { landSleep = time.Sleep }()
+	landSleep = func(time.Duration) {}
+
+	mockGH := new(testutil.MockGitHubClient)
+	mockGH.On("GetPRByNumber", 7).Return(&github.PRInfo{Number: 7, State: "OPEN"}, nil)
+
+	err := waitForMerge(mockGH, 7, 0)
+
+	assert.ErrorContains(t, err, "isn't merged after")
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// syncNoLFSSmudge leaves LFS files as pointers while sync checks out and rebases
+// branches, and downloads them once for the branch sync ends on
+var syncNoLFSSmudge bool
+
+// configureLFS makes pushes upload LFS objects in repos that track files in LFS,
+// and skips downloading them on checkouts when skipSmudge is set. Without git-lfs
+// installed, LFS files can't be pushed, so that's pointed out instead.
+func configureLFS(gitClient git.GitClient, skipSmudge bool) {
+	if !gitClient.UsesLFS() {
+		return
+	}
+	if !gitClient.LFSInstalled() {
+		fmt.Fprintf(os.Stderr, "%s This repo stores files in Git LFS, but git-lfs isn't installed: pushes won't upload them\n", ui.WarningIcon())
+		return
+	}
+	git.LFS = true
+	git.SkipLFSSmudge = skipSmudge
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureLFS(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	reset := func() {
+		git.LFS = false
+		git.SkipLFSSmudge = false
+	}
+	defer reset()
+
+	t.Run("leaves repos without LFS files alone", func(t *testing.T) {
+		reset()
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("UsesLFS").Return(false)
+
+		configureLFS(mockGit, true)
+
+		assert.False(t, git.LFS)
+		assert.False(t, git.SkipLFSSmudge)
+		mockGit.AssertNotCalled(t, "LFSInstalled")
+	})
+
+	t.Run("verifies LFS pushes and skips smudge when asked", func(t *testing.T) {
+		reset()
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("UsesLFS").Return(true)
+		mockGit.On("LFSInstalled").Return(true)
+
+		configureLFS(mockGit, true)
+
+		assert.True(t, git.LFS)
+		assert.True(t, git.SkipLFSSmudge)
+	})
+
+	t.Run("does nothing without git-lfs installed", func(t *testing.T) {
+		reset()
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("UsesLFS").Return(true)
+		mockGit.On("LFSInstalled").Return(false)
+
+		configureLFS(mockGit, true)
+
+		assert.False(t, git.LFS)
+		assert.False(t, git.SkipLFSSmudge)
+	})
+}
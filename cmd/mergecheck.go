@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configMergeCheckInterval is how often commands look up whether stack branches
+// were merged since the last sync, e.g. through the web UI ("off" disables)
+const configMergeCheckInterval = "stack.mergeCheck.interval"
+
+// configMergeCheckMerged caches the stack branches found merged by the last check
+const configMergeCheckMerged = "stack.mergeCheck.merged"
+
+// configMergeCheckedAt is when the merge check last asked GitHub (unix seconds)
+const configMergeCheckedAt = "stack.mergeCheck.checkedAt"
+
+const defaultMergeCheckInterval = 10 * time.Minute
+
+// mergeCheckSkipped are the commands that don't run the merge check: sync and prune
+// handle merged branches themselves, and the rest must stay fast and offline, like
+// navigating the stack and showing it from local config
+var mergeCheckSkipped = map[string]bool{
+	"stack":            true,
+	"sync":             true,
+	"prune":            true,
+	"check":            true,
+	"show":             true,
+	"parent":           true,
+	"up":               true,
+	"down":             true,
+	"go":               true,
+	"checkout":         true,
+	"switch":           true,
+	"list":             true,
+	"hook":             true,
+	"install":          true,
+	"uninstall":        true,
+	"version":          true,
+	"help":             true,
+	"completion":       true,
+	"__complete":       true,
+	"__completeNoDesc": true,
+}
+
+// notifyMergedParents warns about stack branches whose parent was merged since the
+// last sync, so it shows up right away instead of only during the next sync.
+// GitHub is asked at most once per stack.mergeCheck.interval; in between, the
+// cached result is used.
+func notifyMergedParents(gitClient git.GitClient, newGitHubClient func() github.GitHubClient, now time.Time) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil || len(parents) == 0 {
+		return
+	}
+
+	merged, ok := mergedStackBranches(gitClient, newGitHubClient, parents, now)
+	if !ok {
+		return
+	}
+
+	children := make([]string, 0, len(parents))
+	for branch := range parents {
+		children = append(children, branch)
+	}
+	sort.Strings(children)
+
+	for _, branch := range children {
+		parent := parents[branch]
+		if !merged[parent] || merged[branch] {
+			continue
+		}
+		grandparent, ok := parents[parent]
+		if !ok {
+			grandparent = stack.GetBaseBranch(gitClient)
+		}
+		fmt.Fprintf(os.Stderr, "%s %s\n", ui.WarningIcon(),
+			i18n.T("mergeCheck.parentMerged", ui.Branch(parent), ui.Branch(branch), ui.Command("stack sync"), ui.Branch(grandparent)))
+	}
+}
+
+// mergedStackBranches returns the stack branches whose PR is merged, from the cache
+// while it's fresh and from GitHub otherwise. ok is false when the check is off or
+// GitHub couldn't be asked.
+func mergedStackBranches(gitClient git.GitClient, newGitHubClient func() github.GitHubClient, parents map[string]string, now time.Time) (merged map[string]bool, ok bool) {
+	interval := defaultMergeCheckInterval
+	if value := gitClient.GetConfig(configMergeCheckInterval); value == "off" {
+		return nil, false
+	} else if value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			interval = parsed
+		} else if verbose {
+			fmt.Printf("Note: invalid %s %q, using %s\n", configMergeCheckInterval, value, interval)
+		}
+	}
+
+	if checkedAt, err := strconv.ParseInt(gitClient.GetConfig(configMergeCheckedAt), 10, 64); err == nil && now.Sub(time.Unix(checkedAt, 0)) < interval {
+		merged = make(map[string]bool)
+		for _, branch := range strings.Split(gitClient.GetConfig(configMergeCheckMerged), ",") {
+			if branch != "" {
+				merged[branch] = true
+			}
+		}
+		return merged, true
+	}
+
+	branches := make([]string, 0, len(parents))
+	for branch := range parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	prs, err := newGitHubClient().GetPRsForBranches(branches)
+	if err != nil {
+		if verbose {
+			fmt.Printf("Note: could not check for merged PRs: %v\n", err)
+		}
+		return nil, false
+	}
+
+	merged = make(map[string]bool)
+	var names []string
+	for _, branch := range branches {
+		if pr := prs[branch]; pr != nil && pr.State == "MERGED" {
+			merged[branch] = true
+			names = append(names, branch)
+		}
+	}
+
+	// Caching is best effort, e.g. in read-only mode it just runs again next time
+	if dryRun {
+		return merged, true
+	}
+	_ = gitClient.SetConfig(configMergeCheckMerged, strings.Join(names, ","))
+	_ = gitClient.SetConfig(configMergeCheckedAt, strconv.FormatInt(now.Unix(), 10))
+	return merged, true
+}
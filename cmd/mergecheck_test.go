@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergedStackBranches(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+	now := time.Unix(1700000000, 0)
+
+	t.Run("asks GitHub when the cache is stale and caches the result", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetConfig", configMergeCheckInterval).Return("")
+		mockGit.On("GetConfig", configMergeCheckedAt).Return(strconv.FormatInt(now.Add(-time.Hour).Unix(), 10))
+		mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(12, "MERGED", "main", "A", "url"),
+			"feature-b": testutil.NewPRInfo(13, "OPEN", "feature-a", "B", "url"),
+		}, nil)
+		mockGit.On("SetConfig", configMergeCheckMerged, "feature-a").Return(nil)
+		mockGit.On("SetConfig", configMergeCheckedAt, strconv.FormatInt(now.Unix(), 10)).Return(nil)
+
+		merged, ok := mergedStackBranches(mockGit, func() github.GitHubClient { return mockGH }, parents, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, map[string]bool{"feature-a": true}, merged)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("uses the cache while it's fresh", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configMergeCheckInterval).Return("30m")
+		mockGit.On("GetConfig", configMergeCheckedAt).Return(strconv.FormatInt(now.Add(-20*time.Minute).Unix(), 10))
+		mockGit.On("GetConfig", configMergeCheckMerged).Return("feature-a")
+
+		merged, ok := mergedStackBranches(mockGit, func() github.GitHubClient {
+			t.Fatal("GitHub shouldn't be asked")
+			return nil
+		}, parents, now)
+
+		assert.True(t, ok)
+		assert.Equal(t, map[string]bool{"feature-a": true}, merged)
+	})
+
+	t.Run("can be turned off", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configMergeCheckInterval).Return("off")
+
+		_, ok := mergedStackBranches(mockGit, nil, parents, now)
+
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// configDetectMergedByCommit enables --detect-merged-by-commit by default for a repo
+const configDetectMergedByCommit = "stack.detectMergedByCommit"
+
+// detectMergedByCommit is the --detect-merged-by-commit flag of sync and prune
+var detectMergedByCommit bool
+
+// readDetectMergedByCommit falls back to stack.detectMergedByCommit when the
+// --detect-merged-by-commit flag wasn't given
+func readDetectMergedByCommit(cmd *cobra.Command, gitClient git.GitClient) {
+	if !cmd.Flags().Changed("detect-merged-by-commit") {
+		detectMergedByCommit = gitClient.GetConfig(configDetectMergedByCommit) == "true"
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunSyncSkipsQueuedPR(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+
+	queued := testutil.NewPRInfo(12, "OPEN", "feature-x", "A", "url")
+	queued.InMergeQueue = true
+
+	mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+	mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+	mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+	mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+	mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+	mockGit.On("IsWorkingTreeClean").Return(true, nil)
+	mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+	mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+	mockGit.On("GetDefaultBranch").Return("main").Maybe()
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+	}, nil).Maybe()
+	testutil.AllowPRPins(mockGit)
+	testutil.AllowStackUmbrellas(mockGit)
+	testutil.AllowSyncState(mockGit)
+	testutil.AllowSyncWorktree(mockGit)
+	testutil.AllowSubmodules(mockGit)
+	testutil.AllowUpdateRefs(mockGit)
+	testutil.AllowOpLog(mockGit)
+	testutil.AllowPickedCommits(mockGit)
+	mockGit.On("Fetch").Return(nil)
+	mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{"feature-a": queued}, nil)
+	mockGH.On("GetPRForBranch", mock.Anything).Return(nil, nil).Maybe()
+	mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+	mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+	mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "feature-a": true, "feature-b": true})
+	// feature-a is queued and left alone; feature-b is restacked on it as usual
+	mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+	mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+	mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+	mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{}, nil)
+	mockGit.On("FetchBranch", "feature-b").Return(nil)
+	mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+	err := runSync(mockGit, mockGH)
+
+	assert.NoError(t, err)
+	mockGit.AssertNotCalled(t, "CheckoutBranch", "feature-a")
+	mockGit.AssertNotCalled(t, "PushWithExpectedRemote", "feature-a", mock.Anything)
+	mockGH.AssertNotCalled(t, "UpdatePRBase", mock.Anything, mock.Anything)
+}
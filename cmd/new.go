@@ -5,26 +5,29 @@ import (
 	"os"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/stack"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// newUmbrella creates the branch as an umbrella that only groups sub-stacks
+var newUmbrella bool
+
 var newCmd = &cobra.Command{
 	Use:   "new <branch-name> [parent]",
-	Short: "Create a new branch in the stack",
-	Long: `Create a new branch in the stack, optionally specifying a parent branch.
-
-The new branch will be created from the specified parent (or current branch if not specified),
-and the parent relationship will be stored in git config (branch.<name>.stackparent).
-
-If no parent is specified and you're not on a stack branch, the base branch (default: main)
-will be used as the parent.`,
+	Short: i18n.T("new.short"),
+	Long:  i18n.T("new.long"),
 	Example: `  # Create a stack: main <- A <- B <- C
   stack new A main                         # A based on main
   stack new B                              # B based on current (A)
   stack new C                              # C based on current (B)
 
+  # Group two sub-stacks under an umbrella branch
+  stack new payments --umbrella main
+  stack new payments-api                   # based on payments
+  stack new payments-ui payments           # based on payments
+
   # Preview without creating
   stack new feature-xyz --dry-run`,
 	Args: cobra.RangeArgs(1, 2),
@@ -38,13 +41,20 @@ will be used as the parent.`,
 		gitClient := git.NewGitClient()
 
 		if err := runNew(gitClient, branchName, parent); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
 
+func init() {
+	newCmd.Flags().BoolVar(&newUmbrella, "umbrella", false, "Create an umbrella branch that only groups sub-stacks (never pushed, no PR)")
+}
+
 func runNew(gitClient git.GitClient, branchName string, explicitParent string) error {
+	if err := validateBranchName(gitClient, branchName); err != nil {
+		return err
+	}
+
 	// Check if branch already exists
 	if gitClient.BranchExists(branchName) {
 		return fmt.Errorf("branch %s already exists", branchName)
@@ -91,6 +101,12 @@ func runNew(gitClient git.GitClient, branchName string, explicitParent string) e
 		return fmt.Errorf("failed to set parent config: %w", err)
 	}
 
+	if newUmbrella {
+		if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackumbrella", branchName), "true"); err != nil {
+			return fmt.Errorf("failed to mark umbrella branch: %w", err)
+		}
+	}
+
 	if !dryRun {
 		fmt.Println(ui.Success(fmt.Sprintf("Created branch %s with parent %s", ui.Branch(branchName), ui.Branch(parent))))
 		fmt.Println()
@@ -118,7 +134,8 @@ func showStack(gitClient git.GitClient) error {
 	}
 
 	// Use the same local tree printer as stack show
-	printLocalStackTree(tree, currentBranch, false)
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	printLocalStackTree(tree, currentBranch, umbrellas, false)
 
 	return nil
 }
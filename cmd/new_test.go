@@ -24,6 +24,7 @@ func TestRunNew(t *testing.T) {
 			branchName:     "feature-b",
 			explicitParent: "feature-a",
 			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckBranchName", "feature-b").Return(nil)
 				// Branch doesn't exist
 				mockGit.On("BranchExists", "feature-b").Return(false)
 				// Parent exists
@@ -40,6 +41,7 @@ func TestRunNew(t *testing.T) {
 			branchName:     "feature-b",
 			explicitParent: "",
 			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckBranchName", "feature-b").Return(nil)
 				// Branch doesn't exist
 				mockGit.On("BranchExists", "feature-b").Return(false)
 				// Get current branch
@@ -58,6 +60,7 @@ func TestRunNew(t *testing.T) {
 			branchName:     "feature-a",
 			explicitParent: "main",
 			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckBranchName", "feature-a").Return(nil)
 				// Branch already exists
 				mockGit.On("BranchExists", "feature-a").Return(true)
 			},
@@ -68,6 +71,7 @@ func TestRunNew(t *testing.T) {
 			branchName:     "feature-b",
 			explicitParent: "non-existent",
 			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("CheckBranchName", "feature-b").Return(nil)
 				// Branch doesn't exist
 				mockGit.On("BranchExists", "feature-b").Return(false)
 				// Parent doesn't exist
@@ -109,6 +113,7 @@ func TestRunNewValidation(t *testing.T) {
 	t.Run("validates branch name", func(t *testing.T) {
 		mockGit := new(testutil.MockGitClient)
 
+		mockGit.On("CheckBranchName", "existing-branch").Return(nil)
 		// Branch already exists
 		mockGit.On("BranchExists", "existing-branch").Return(true)
 
@@ -123,6 +128,7 @@ func TestRunNewValidation(t *testing.T) {
 	t.Run("validates parent exists", func(t *testing.T) {
 		mockGit := new(testutil.MockGitClient)
 
+		mockGit.On("CheckBranchName", "new-branch").Return(nil)
 		// Branch doesn't exist
 		mockGit.On("BranchExists", "new-branch").Return(false)
 		// Parent doesn't exist
@@ -143,6 +149,7 @@ func TestRunNewSetConfig(t *testing.T) {
 
 	mockGit := new(testutil.MockGitClient)
 
+	mockGit.On("CheckBranchName", "new-branch").Return(nil)
 	// Branch doesn't exist
 	mockGit.On("BranchExists", "new-branch").Return(false)
 	// Parent exists
@@ -166,6 +173,7 @@ func TestRunNewFromCurrentBranch(t *testing.T) {
 
 	mockGit := new(testutil.MockGitClient)
 
+	mockGit.On("CheckBranchName", "new-branch").Return(nil)
 	// Branch doesn't exist
 	mockGit.On("BranchExists", "new-branch").Return(false)
 	// Get current branch
@@ -192,6 +200,7 @@ func TestRunNewErrorHandling(t *testing.T) {
 	t.Run("error on CreateBranchAndCheckout failure", func(t *testing.T) {
 		mockGit := new(testutil.MockGitClient)
 
+		mockGit.On("CheckBranchName", "new-branch").Return(nil)
 		mockGit.On("BranchExists", "new-branch").Return(false)
 		mockGit.On("BranchExists", "parent").Return(true)
 		mockGit.On("CreateBranchAndCheckout", "new-branch", "parent").Return(fmt.Errorf("git error"))
@@ -207,6 +216,7 @@ func TestRunNewErrorHandling(t *testing.T) {
 	t.Run("error on SetConfig failure", func(t *testing.T) {
 		mockGit := new(testutil.MockGitClient)
 
+		mockGit.On("CheckBranchName", "new-branch").Return(nil)
 		mockGit.On("BranchExists", "new-branch").Return(false)
 		mockGit.On("BranchExists", "parent").Return(true)
 		mockGit.On("CreateBranchAndCheckout", "new-branch", "parent").Return(nil)
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunShowNotInStack(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCurrentBranch").Return("feature-x", nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+	mockGit.On("ListBranches").Return([]string{"main", "feature-a", "feature-x"}, nil).Maybe()
+	mockGit.On("GetConfig", "stack.baseBranch").Return("main")
+	mockGit.On("GetConfig", "stack.notInStack").Return("error")
+
+	err := runShow(mockGit)
+
+	var notInStack *stack.NotInStackError
+	assert.ErrorAs(t, err, &notInStack)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// offline skips fetching, pushing and GitHub, working from the local refs and
+// the PRs cached by earlier runs
+var offline bool
+
+// envOffline enables offline mode like --offline
+const envOffline = "STACKINATOR_OFFLINE"
+
+// prCacheFile is where PRs looked up online are kept for offline runs, in the git
+// directory shared by all worktrees
+const prCacheFile = "stackinator/prs.json"
+
+var offlineOnce sync.Once
+
+// configureOffline sets up the PR cache, and offline mode when asked for. Commands
+// also go offline by themselves the first time origin or GitHub is unreachable.
+func configureOffline(gitClient git.GitClient) {
+	if dir, err := gitClient.GetCommonDir(); err == nil {
+		github.CacheFile = filepath.Join(dir, prCacheFile)
+	}
+	git.OnUnreachable = goOffline
+	github.OnUnreachable = goOffline
+
+	if offline || isTruthy(os.Getenv(envOffline)) {
+		goOffline(nil)
+	}
+}
+
+// goOffline switches git and gh to offline mode and says so, once. reason is the
+// network failure that caused it, nil when offline mode was asked for.
+func goOffline(reason error) {
+	git.Offline = true
+	github.Offline = true
+	offlineOnce.Do(func() {
+		if reason != nil {
+			fmt.Fprintf(os.Stderr, "%s Network unreachable, continuing offline: %v\n", ui.WarningIcon(), reason)
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", ui.Dim(github.OfflineNote(time.Now())))
+	})
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfflineNote(t *testing.T) {
+	github.CacheFile = filepath.Join(t.TempDir(), "prs.json")
+	defer func() { github.CacheFile = "" }()
+
+	assert.Equal(t, "Offline: using local refs, no PR data cached yet", github.OfflineNote(time.Now()))
+
+	// Calls that need GitHub fail with a hint to retry online
+	client := github.NewGitHubClient("owner/repo")
+	github.Offline = true
+	defer func() { github.Offline = false }()
+	_, err := client.GetCurrentUser()
+	assert.ErrorIs(t, err, github.ErrOffline)
+	assert.Contains(t, errorHint(err), "once online")
+}
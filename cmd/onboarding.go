@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configOnboarded records that the user acknowledged the first-run overview in this repo
+const configOnboarded = "stack.onboarded"
+
+// ensureOnboarded shows a one-time overview of what stackinator does to a repository
+// (config it writes, force-pushes during sync) and asks for acknowledgment before the
+// first force-push. The acknowledgment is stored in git config so it's only shown once.
+// Passing --yes acknowledges without prompting.
+func ensureOnboarded(gitClient git.GitClient) error {
+	if gitClient.GetConfig(configOnboarded) == "true" {
+		return nil
+	}
+
+	if !assumeYes {
+		fmt.Println(ui.Warning("First sync in this repository"))
+		fmt.Println()
+		fmt.Println("Before continuing, here's what stackinator will do:")
+		fmt.Printf("  - Store stack structure in git config (%s)\n", ui.Command("branch.<name>.stackparent"))
+		fmt.Println("  - Rebase each stack branch onto its parent")
+		fmt.Printf("  - Force-push rebased branches to %s (%s by default, see %s)\n", git.Remote, ui.Command("--force-with-lease"), ui.Command("stack.pushStrategy"))
+		fmt.Println("    (with a lease, the push is refused if someone else updated the remote branch)")
+		fmt.Println("  - Update PR base branches to match the stack")
+		fmt.Println()
+		fmt.Println("To put the stack back as it was before a sync, restack or reparent, run:")
+		fmt.Printf("  %s\n", ui.Command("stack undo"))
+		fmt.Println()
+		fmt.Print("Continue? [y/N] ")
+
+		reader := bufio.NewReader(stdinReader)
+		input, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input (use --yes to acknowledge non-interactively): %w", err)
+		}
+
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			return fmt.Errorf("aborted: first-run overview not acknowledged")
+		}
+		fmt.Println()
+	}
+
+	if err := gitClient.SetConfig(configOnboarded, "true"); err != nil {
+		return fmt.Errorf("failed to save acknowledgment: %w", err)
+	}
+
+	return nil
+}
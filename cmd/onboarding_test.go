@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsureOnboarded(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name        string
+		onboarded   string
+		yes         bool
+		input       string
+		expectSave  bool
+		expectError bool
+	}{
+		{
+			name:      "already acknowledged",
+			onboarded: "true",
+		},
+		{
+			name:       "acknowledged interactively",
+			input:      "y\n",
+			expectSave: true,
+		},
+		{
+			name:        "declined",
+			input:       "n\n",
+			expectError: true,
+		},
+		{
+			name:        "no input available",
+			input:       "",
+			expectError: true,
+		},
+		{
+			name:       "acknowledged with --yes",
+			yes:        true,
+			expectSave: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.onboarded").Return(tt.onboarded)
+			if tt.expectSave {
+				mockGit.On("SetConfig", "stack.onboarded", "true").Return(nil)
+			}
+
+			assumeYes = tt.yes
+			stdinReader = strings.NewReader(tt.input)
+			defer func() {
+				assumeYes = false
+				stdinReader = os.Stdin
+			}()
+
+			err := ensureOnboarded(mockGit)
+
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			if !tt.expectSave {
+				mockGit.AssertNotCalled(t, "SetConfig", "stack.onboarded", "true")
+			}
+		})
+	}
+}
@@ -2,28 +2,24 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var parentCmd = &cobra.Command{
 	Use:   "parent",
-	Short: "Show the parent of the current branch",
-	Long: `Display the parent branch of the current branch in the stack.
-
-If the current branch has no parent set, it will show that the branch
-is not part of a stack.`,
+	Short: i18n.T("parent.short"),
+	Long:  i18n.T("parent.long"),
 	Example: `  # Show parent of current branch
   stack parent`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
 
 		if err := runParent(gitClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -39,11 +35,10 @@ func runParent(gitClient git.GitClient) error {
 	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", currentBranch))
 
 	if parent == "" {
-		fmt.Printf("%s %s\n", ui.Branch(currentBranch), ui.Dim("(not in a stack)"))
+		fmt.Printf("%s %s\n", ui.Branch(currentBranch), ui.Dim(i18n.T("parent.notInStack")))
 	} else {
 		fmt.Println(ui.Branch(parent))
 	}
 
 	return nil
 }
-
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var pickCmd = &cobra.Command{
+	Use:   "pick <commit|branch>",
+	Short: i18n.T("pick.short"),
+	Long:  i18n.T("pick.long"),
+	Example: `  # Pick a fix from another stack
+  stack pick a1b2c3d
+
+  # Pick every commit of another branch
+  stack pick fix-flaky-test`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runPick(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runPick(gitClient git.GitClient, source string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if source == currentBranch {
+		return fmt.Errorf("cannot pick %s onto itself", source)
+	}
+
+	commits, err := commitsToPick(gitClient, source)
+	if err != nil {
+		return err
+	}
+	if len(commits) == 0 {
+		fmt.Printf("%s has no commits of its own to pick.\n", ui.Branch(source))
+		return nil
+	}
+
+	for i, commit := range commits {
+		if err := gitClient.CherryPickTracked(commit); err != nil {
+			fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("conflict.cherryPickOn", ui.ShortSHA(commit)))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.cherryPickContinue"))
+			if rest := commits[i+1:]; len(rest) > 0 {
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("pick.conflict.rest", strings.Join(rest, " ")))
+			}
+			fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("pick.conflict.abort"))
+			return fmt.Errorf("cherry-pick conflict: %w", err)
+		}
+	}
+
+	if !dryRun {
+		fmt.Printf("%s Picked %d commit(s) from %s onto %s\n", ui.SuccessIcon(), len(commits), ui.Branch(source), ui.Branch(currentBranch))
+		fmt.Printf("'%s' offers to drop them once the originals land on %s\n", ui.Command("stack sync"), ui.Branch(stack.GetBaseBranch(gitClient)))
+	}
+	return nil
+}
+
+// commitsToPick returns the commits to pick for source, oldest first: a branch's
+// own commits, or source itself as a single commit
+func commitsToPick(gitClient git.GitClient, source string) ([]string, error) {
+	if !gitClient.BranchExists(source) {
+		commit, err := gitClient.GetCommitHash(source)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a branch or commit", source)
+		}
+		return []string{commit}, nil
+	}
+
+	baseBranch := stack.GetBaseBranch(gitClient)
+	from := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", source))
+	if from == "" || from == baseBranch {
+		var err error
+		if from, err = gitClient.GetMergeBase(baseBranch, source); err != nil {
+			return nil, fmt.Errorf("failed to find where %s starts: %w", source, err)
+		}
+	}
+	commits, err := gitClient.GetUniqueCommits(from, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits of %s: %w", source, err)
+	}
+	return commits, nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPick(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("picks a branch's own commits", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("BranchExists", "fix").Return(true)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+		mockGit.On("GetConfig", "branch.fix.stackparent").Return("main")
+		mockGit.On("GetMergeBase", "main", "fix").Return("fork", nil)
+		mockGit.On("GetUniqueCommits", "fork", "fix").Return([]string{"c1", "c2"}, nil)
+		mockGit.On("CherryPickTracked", "c1").Return(nil)
+		mockGit.On("CherryPickTracked", "c2").Return(nil)
+
+		err := runPick(mockGit, "fix")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("picks a single commit", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("BranchExists", "abc").Return(false)
+		mockGit.On("GetCommitHash", "abc").Return("sha-abc", nil)
+		mockGit.On("CherryPickTracked", "sha-abc").Return(nil)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+
+		err := runPick(mockGit, "abc")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+// configPRScope selects which PRs status, sync and prune look up
+const configPRScope = "stack.prScope"
+
+// prScope is set from --pr-scope or stack.prScope in Run
+var prScope = syncer.PRScopeStack
+
+// parsePRScope validates a --pr-scope or stack.prScope value. Empty means the default.
+func parsePRScope(value string) (string, error) {
+	switch value {
+	case "":
+		return syncer.PRScopeStack, nil
+	case syncer.PRScopeStack, syncer.PRScopeAuthor:
+		return value, nil
+	}
+	return "", fmt.Errorf("invalid PR scope %q (use %s or %s)", value, syncer.PRScopeStack, syncer.PRScopeAuthor)
+}
+
+// readPRScope sets prScope from the --pr-scope flag, falling back to stack.prScope
+func readPRScope(cmd *cobra.Command, gitClient git.GitClient) error {
+	value := prScope
+	if !cmd.Flags().Changed("pr-scope") {
+		value = gitClient.GetConfig(configPRScope)
+	}
+	scope, err := parsePRScope(value)
+	if err != nil {
+		return err
+	}
+	prScope = scope
+	return nil
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePRScope(t *testing.T) {
+	scope, err := parsePRScope("")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.PRScopeStack, scope)
+
+	scope, err = parsePRScope("author")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.PRScopeAuthor, scope)
+
+	_, err = parsePRScope("everyone")
+	assert.Error(t, err)
+}
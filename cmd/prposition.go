@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/spf13/cobra"
+)
+
+// configPRPosition marks each PR with its place in the stack, as --pr-position
+// does for sync and submit: "label" (a stack:2/5 label), "title" (a "[2/5]"
+// title prefix), "both" or "off" (the default)
+const configPRPosition = "stack.prPosition"
+
+var (
+	// prPosition is the --pr-position flag of sync and submit
+	prPosition string
+	// prPositionMode is set from --pr-position or stack.prPosition in Run
+	prPositionMode syncer.PositionMode
+)
+
+// parsePRPosition validates a --pr-position or stack.prPosition value. Empty is off.
+func parsePRPosition(value string) (syncer.PositionMode, error) {
+	switch value {
+	case "", "off":
+		return syncer.PositionMode{}, nil
+	case "label":
+		return syncer.PositionMode{Labels: true}, nil
+	case "title":
+		return syncer.PositionMode{Titles: true}, nil
+	case "both":
+		return syncer.PositionMode{Labels: true, Titles: true}, nil
+	}
+	return syncer.PositionMode{}, fmt.Errorf("invalid PR position %q (use label, title, both or off)", value)
+}
+
+// readPRPosition sets prPositionMode from the --pr-position flag, falling back to
+// stack.prPosition
+func readPRPosition(cmd *cobra.Command, gitClient git.GitClient) error {
+	value := prPosition
+	if !cmd.Flags().Changed("pr-position") {
+		value = gitClient.GetConfig(configPRPosition)
+	}
+	mode, err := parsePRPosition(value)
+	if err != nil {
+		return err
+	}
+	prPositionMode = mode
+	return nil
+}
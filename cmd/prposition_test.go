@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePRPosition(t *testing.T) {
+	mode, err := parsePRPosition("")
+	assert.NoError(t, err)
+	assert.False(t, mode.Enabled())
+
+	mode, err = parsePRPosition("both")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.PositionMode{Labels: true, Titles: true}, mode)
+
+	_, err = parsePRPosition("prefix")
+	assert.Error(t, err)
+}
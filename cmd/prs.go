@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var prsCmd = &cobra.Command{
+	Use:   "prs",
+	Short: i18n.T("prs.short"),
+	Long:  i18n.T("prs.long"),
+	Example: `  # Show your open PRs grouped by stack
+  stack prs
+
+  # Example output:
+  #  main
+  #    #123  feature-auth        open   ✓ passing  approved           3d  Add auth
+  #    #124    feature-auth-ui   draft  ⚠ pending  review required    1d  Auth UI`,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runPRs(githubClient, "@me"); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// prRow is a PR in a stack, with its depth below the stack's base branch
+type prRow struct {
+	pr    *github.PRInfo
+	depth int
+}
+
+// prStack is a chain (or tree) of PRs rooted on a branch that has no PR of its own
+type prStack struct {
+	base string
+	rows []prRow
+}
+
+// runPRs lists the open PRs of author ("@me" for the current user) grouped by stack
+func runPRs(githubClient github.GitHubClient, author string) error {
+	var prs []*github.PRInfo
+	if err := spinner.WrapWithAutoDelay("Fetching PRs...", 300*time.Millisecond, func() error {
+		var err error
+		prs, err = githubClient.ListAuthoredPRs(author)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if len(prs) == 0 {
+		fmt.Println("No open PRs found.")
+		return nil
+	}
+
+	printPRStacks(groupPRsByStack(prs), time.Now())
+	return nil
+}
+
+// groupPRsByStack groups PRs into stacks by matching each PR's base to another PR's head.
+// PRs whose base isn't another PR's head start a new stack; stacks sharing a base branch
+// are listed under it together.
+func groupPRsByStack(prs []*github.PRInfo) []prStack {
+	byHead := make(map[string]*github.PRInfo)
+	for _, pr := range prs {
+		byHead[pr.Head] = pr
+	}
+
+	children := make(map[string][]*github.PRInfo)
+	for _, pr := range prs {
+		children[pr.Base] = append(children[pr.Base], pr)
+	}
+	for _, list := range children {
+		sort.Slice(list, func(i, j int) bool { return list[i].Number < list[j].Number })
+	}
+
+	var bases []string
+	for base := range children {
+		if _, stacked := byHead[base]; !stacked {
+			bases = append(bases, base)
+		}
+	}
+	sort.Strings(bases)
+
+	visited := make(map[int]bool)
+	var walk func(pr *github.PRInfo, depth int, rows []prRow) []prRow
+	walk = func(pr *github.PRInfo, depth int, rows []prRow) []prRow {
+		if visited[pr.Number] {
+			return rows
+		}
+		visited[pr.Number] = true
+		rows = append(rows, prRow{pr: pr, depth: depth})
+		for _, child := range children[pr.Head] {
+			rows = walk(child, depth+1, rows)
+		}
+		return rows
+	}
+
+	var stacks []prStack
+	for _, base := range bases {
+		var rows []prRow
+		for _, pr := range children[base] {
+			rows = walk(pr, 0, rows)
+		}
+		stacks = append(stacks, prStack{base: base, rows: rows})
+	}
+
+	// PRs in a base cycle (e.g. A -> B -> A) have no root; list them on their own
+	for _, pr := range prs {
+		if !visited[pr.Number] {
+			stacks = append(stacks, prStack{base: pr.Base, rows: walk(pr, 0, nil)})
+		}
+	}
+
+	return stacks
+}
+
+// printPRStacks prints each stack with aligned state, CI, review and age columns
+func printPRStacks(stacks []prStack, now time.Time) {
+	// Compute column widths from the plain text, before coloring
+	numberWidth, branchWidth := 0, 0
+	for _, s := range stacks {
+		for _, row := range s.rows {
+			numberWidth = max(numberWidth, len(fmt.Sprintf("#%d", row.pr.Number)))
+			branchWidth = max(branchWidth, row.depth*2+len(row.pr.Head))
+		}
+	}
+
+	for i, s := range stacks {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf(" %s\n", ui.Branch(s.base))
+		for _, row := range s.rows {
+			pr := row.pr
+			number := fmt.Sprintf("%-*s", numberWidth, fmt.Sprintf("#%d", pr.Number))
+			branch := strings.Repeat("  ", row.depth) + ui.Branch(pr.Head) +
+				strings.Repeat(" ", branchWidth-row.depth*2-len(pr.Head))
+			fmt.Printf("   %s  %s  %s  %s  %s  %4s  %s\n",
+				ui.Dim(number),
+				branch,
+				formatPRState(pr),
+				formatChecks(pr.Checks),
+				formatReview(pr.ReviewDecision),
+				ui.Age(now.Sub(pr.CreatedAt)),
+				pr.Title,
+			)
+		}
+	}
+}
+
+// formatPRState shows whether a PR is a draft
+func formatPRState(pr *github.PRInfo) string {
+	if pr.IsDraft {
+		return ui.Dim("draft")
+	}
+	return ui.PRState(fmt.Sprintf("%-5s", pr.State))
+}
+
+// formatChecks shows a PR's summarized CI state as an icon and a label, padded
+// to the same width for every state so the column lines up
+func formatChecks(checks string) string {
+	const width = len("passing")
+	label := func(text string) string {
+		return fmt.Sprintf("%-*s", width, text)
+	}
+	switch checks {
+	case "SUCCESS":
+		return ui.Success(label("passing"))
+	case "FAILURE":
+		return ui.Error(label("failing"))
+	case "PENDING":
+		return ui.Warning(label("pending"))
+	default:
+		return ui.Dim("- " + label("none"))
+	}
+}
+
+// formatReview shows a PR's review decision
+func formatReview(decision string) string {
+	const width = len("changes requested")
+	switch decision {
+	case "APPROVED":
+		return ui.Command(fmt.Sprintf("%-*s", width, "approved"))
+	case "CHANGES_REQUESTED":
+		return ui.ErrorText(fmt.Sprintf("%-*s", width, "changes requested"))
+	case "REVIEW_REQUIRED":
+		return fmt.Sprintf("%-*s", width, "review required")
+	default:
+		return ui.Dim(fmt.Sprintf("%-*s", width, "-"))
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newAuthoredPR(number int, head, base string) *github.PRInfo {
+	pr := testutil.NewPRInfo(number, "OPEN", base, "PR "+head, "url")
+	pr.Head = head
+	return pr
+}
+
+func TestGroupPRsByStack(t *testing.T) {
+	prs := []*github.PRInfo{
+		newAuthoredPR(3, "feature-b", "feature-a"),
+		newAuthoredPR(1, "feature-a", "main"),
+		newAuthoredPR(5, "hotfix", "release"),
+		newAuthoredPR(4, "feature-c", "feature-a"),
+		newAuthoredPR(2, "other", "main"),
+	}
+
+	stacks := groupPRsByStack(prs)
+
+	assert.Len(t, stacks, 2)
+
+	assert.Equal(t, "main", stacks[0].base)
+	var rows []string
+	var depths []int
+	for _, row := range stacks[0].rows {
+		rows = append(rows, row.pr.Head)
+		depths = append(depths, row.depth)
+	}
+	assert.Equal(t, []string{"feature-a", "feature-b", "feature-c", "other"}, rows)
+	assert.Equal(t, []int{0, 1, 1, 0}, depths)
+
+	assert.Equal(t, "release", stacks[1].base)
+	assert.Len(t, stacks[1].rows, 1)
+}
+
+func TestGroupPRsByStackCycle(t *testing.T) {
+	prs := []*github.PRInfo{
+		newAuthoredPR(1, "a", "b"),
+		newAuthoredPR(2, "b", "a"),
+	}
+
+	stacks := groupPRsByStack(prs)
+
+	assert.Len(t, stacks, 1)
+	assert.Len(t, stacks[0].rows, 2)
+}
+
+func TestRunPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("lists authored PRs", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "@me").Return([]*github.PRInfo{
+			newAuthoredPR(1, "feature-a", "main"),
+		}, nil)
+
+		err := runPRs(mockGH, "@me")
+
+		assert.NoError(t, err)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("lists a teammate's PRs", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "alice").Return([]*github.PRInfo{
+			newAuthoredPR(1, "alice/feature-a", "main"),
+			newAuthoredPR(2, "alice/feature-b", "alice/feature-a"),
+		}, nil)
+
+		err := runPRs(mockGH, "alice")
+
+		assert.NoError(t, err)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("no open PRs", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "@me").Return([]*github.PRInfo{}, nil)
+
+		err := runPRs(mockGH, "@me")
+
+		assert.NoError(t, err)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("gh error", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("ListAuthoredPRs", "@me").Return(nil, errors.New("failed to list PRs"))
+
+		err := runPRs(mockGH, "@me")
+
+		assert.Error(t, err)
+		mockGH.AssertExpectations(t)
+	})
+}
+
+func TestFormatChecks(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	assert.Equal(t, "✓ passing", formatChecks("SUCCESS"))
+	assert.Equal(t, "✗ failing", formatChecks("FAILURE"))
+	assert.Equal(t, "⚠ pending", formatChecks("PENDING"))
+	assert.Equal(t, "- none   ", formatChecks(""))
+}
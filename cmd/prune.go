@@ -3,12 +3,14 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"sync"
 
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/spinner"
 	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -20,18 +22,8 @@ var (
 
 var pruneCmd = &cobra.Command{
 	Use:   "prune",
-	Short: "Clean up branches with merged PRs",
-	Long: `Remove branches with merged PRs from stack tracking and delete them locally.
-
-By default, this command only checks branches in the stack (those created with 'stack new').
-Use --all to check all local branches.
-
-This command will:
-  1. Find all branches with merged PRs
-  2. Remove them from stack tracking (if applicable)
-  3. Delete the local branches with 'git branch -d'
-
-If a branch has unmerged commits locally, use --force to delete it anyway.`,
+	Short: i18n.T("prune.short"),
+	Long:  i18n.T("prune.long"),
 	Example: `  # Clean up merged stack branches
   stack prune
 
@@ -45,12 +37,15 @@ If a branch has unmerged commits locally, use --force to delete it anyway.`,
   stack prune --dry-run`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
-		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL("origin"))
-		githubClient := github.NewGitHubClient(repo)
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := readPRScope(cmd, gitClient); err != nil {
+			exitWithError(err)
+		}
+		readDetectMergedByCommit(cmd, gitClient)
 
 		if err := runPrune(gitClient, githubClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -58,6 +53,8 @@ If a branch has unmerged commits locally, use --force to delete it anyway.`,
 func init() {
 	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "Force delete branches even if they have unmerged commits")
 	pruneCmd.Flags().BoolVarP(&pruneAll, "all", "a", false, "Check all local branches, not just stack branches")
+	pruneCmd.Flags().BoolVar(&detectMergedByCommit, "detect-merged-by-commit", false, "Also prune branches with a closed PR whose tip is already on the base branch")
+	pruneCmd.Flags().StringVar(&prScope, "pr-scope", syncer.PRScopeStack, "Which PRs to look up: stack (PRs of the branches checked) or author (only your own PRs)")
 }
 
 func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
@@ -70,25 +67,13 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 	// Get base branch to exclude it from pruning
 	baseBranch := stack.GetBaseBranch(gitClient)
 
-	// Start PR fetch in parallel with branch loading (PR fetch is the slowest operation)
-	var wg sync.WaitGroup
-	var prCache map[string]*github.PRInfo
-	var prErr error
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		prCache, prErr = githubClient.GetAllPRs()
-	}()
-
-	// Get branches to check (runs in parallel with PR fetch)
+	// Get branches to check. PRs are looked up for these branches only.
 	var branchNames []string
 	var branchErr error
 	if pruneAll {
 		// Check all local branches
 		branchNames, branchErr = gitClient.ListBranches()
 		if branchErr != nil {
-			wg.Wait() // Wait for PR fetch before returning
 			return fmt.Errorf("failed to get branches: %w", branchErr)
 		}
 
@@ -105,7 +90,6 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 		var stackBranches []stack.StackBranch
 		stackBranches, branchErr = stack.GetStackBranches(gitClient)
 		if branchErr != nil {
-			wg.Wait() // Wait for PR fetch before returning
 			return fmt.Errorf("failed to get stack branches: %w", branchErr)
 		}
 
@@ -115,7 +99,6 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 	}
 
 	if len(branchNames) == 0 {
-		wg.Wait() // Wait for PR fetch before returning
 		if pruneAll {
 			fmt.Println("No branches found to check.")
 		} else {
@@ -124,23 +107,30 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 		return nil
 	}
 
-	// Wait for PR fetch to complete
-	if err := spinner.WrapWithSuccess("Loading branches and fetching PRs...", "Loaded branches and PRs", func() error {
-		wg.Wait()
+	var prCache map[string]*github.PRInfo
+	if err := spinner.WrapWithSuccess("Fetching PRs...", "Fetched PRs", func() error {
+		var prErr error
+		prCache, prErr = syncer.LoadPRs(githubClient, branchNames, prScope)
+		if prErr != nil {
+			return fmt.Errorf("failed to fetch PRs: %w", prErr)
+		}
 		return nil
 	}); err != nil {
 		return err
 	}
 
-	// Check for PR fetch errors
-	if prErr != nil {
-		return fmt.Errorf("failed to fetch PRs: %w", prErr)
+	// Closed PRs whose commits were pushed to the base branch directly count as merged
+	landedByCommit := map[string]bool{}
+	if detectMergedByCommit {
+		if landedByCommit, err = syncer.MergedByCommit(gitClient, git.RemoteRef(baseBranch), prCache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not check for branches merged by commit: %v\n", err)
+		}
 	}
 
 	// Find branches with merged PRs
 	var mergedBranches []string
 	for _, branchName := range branchNames {
-		if pr, exists := prCache[branchName]; exists && pr.State == "MERGED" {
+		if pr, exists := prCache[branchName]; (exists && pr.State == "MERGED") || landedByCommit[branchName] {
 			mergedBranches = append(mergedBranches, branchName)
 		}
 	}
@@ -188,16 +178,16 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 		fmt.Println("  Deleting branch...")
 		var deleteErr error
 		if pruneForce {
-			deleteErr = deleteBranchForce(gitClient, branch)
+			deleteErr = syncer.DeleteBranchForce(gitClient, branch)
 		} else {
-			deleteErr = deleteBranch(gitClient, branch)
+			deleteErr = syncer.DeleteBranch(gitClient, branch)
 		}
 
 		if deleteErr != nil {
 			fmt.Fprintf(os.Stderr, "  Warning: failed to delete branch: %v\n", deleteErr)
 			if !pruneForce {
-				fmt.Fprintf(os.Stderr, "  Use '%s' to force delete, or manually delete with: %s\n",
-					ui.Command("stack prune --force"), ui.Command(fmt.Sprintf("git branch -D %s", branch)))
+				fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("prune.forceHint",
+					ui.Command("stack prune --force"), ui.Command(fmt.Sprintf("git branch -D %s", branch))))
 			}
 		} else {
 			fmt.Printf("  %s Deleted\n", ui.SuccessIcon())
@@ -205,23 +195,7 @@ func runPrune(gitClient git.GitClient, githubClient github.GitHubClient) error {
 		fmt.Println()
 	}
 
-	fmt.Println(ui.Success("Prune complete!"))
+	fmt.Println(ui.Success(i18n.T("prune.complete")))
 
 	return nil
 }
-
-// deleteBranch deletes a branch using 'git branch -d' (safe delete)
-func deleteBranch(gitClient git.GitClient, name string) error {
-	if verbose {
-		fmt.Printf("  [git] branch -d %s\n", name)
-	}
-	return gitClient.DeleteBranch(name)
-}
-
-// deleteBranchForce deletes a branch using 'git branch -D' (force delete)
-func deleteBranchForce(gitClient git.GitClient, name string) error {
-	if verbose {
-		fmt.Printf("  [git] branch -D %s\n", name)
-	}
-	return gitClient.DeleteBranchForce(name)
-}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/syncer"
+)
+
+// configPushStrategy sets how sync force-pushes rebased branches: lease-sha, lease or force
+const configPushStrategy = "stack.pushStrategy"
+
+// configPushForceBranches lists branch patterns (e.g. "sandbox/* wip-*") that are
+// always pushed with plain --force, whatever the default strategy
+const configPushForceBranches = "stack.pushForceBranches"
+
+var (
+	// Read from config in Run; --force overrides both
+	syncPushStrategy  = syncer.PushLeaseSHA
+	syncForcePatterns []string
+)
+
+// parsePushStrategy validates a configured strategy, defaulting to lease-sha when unset
+func parsePushStrategy(value string) (string, error) {
+	switch value {
+	case "":
+		return syncer.PushLeaseSHA, nil
+	case syncer.PushLeaseSHA, syncer.PushLease, syncer.PushForce:
+		return value, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q (use %s, %s or %s)", configPushStrategy, value, syncer.PushLeaseSHA, syncer.PushLease, syncer.PushForce)
+	}
+}
+
+// parseBranchPatterns splits a comma- or space-separated list of branch glob patterns
+func parseBranchPatterns(value string) ([]string, error) {
+	patterns := strings.Fields(strings.ReplaceAll(value, ",", " "))
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid branch pattern %q in %s: %w", pattern, configPushForceBranches, err)
+		}
+	}
+	return patterns, nil
+}
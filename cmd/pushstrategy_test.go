@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushStrategy(t *testing.T) {
+	strategy, err := parsePushStrategy("")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.PushLeaseSHA, strategy)
+
+	strategy, err = parsePushStrategy("lease")
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.PushLease, strategy)
+
+	_, err = parsePushStrategy("yolo")
+	assert.EqualError(t, err, `invalid stack.pushStrategy "yolo" (use lease-sha, lease or force)`)
+}
+
+func TestParseBranchPatterns(t *testing.T) {
+	patterns, err := parseBranchPatterns("sandbox/*, wip-*  scratch")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sandbox/*", "wip-*", "scratch"}, patterns)
+
+	patterns, err = parseBranchPatterns("")
+	assert.NoError(t, err)
+	assert.Empty(t, patterns)
+
+	_, err = parseBranchPatterns("bad[")
+	assert.Error(t, err)
+}
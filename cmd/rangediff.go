@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var rangeDiffComment bool
+
+var rangeDiffCmd = &cobra.Command{
+	Use:   "range-diff [branch]",
+	Short: i18n.T("rangediff.short"),
+	Long:  i18n.T("rangediff.long"),
+	Example: `  # What changed in the current branch since the last push?
+  stack range-diff
+
+  # Compare a specific branch
+  stack range-diff feature-auth
+
+  # Post the summary as a PR comment
+  stack range-diff --comment`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		var branch string
+		if len(args) > 0 {
+			branch = args[0]
+		}
+
+		if err := runRangeDiff(gitClient, githubClient, branch); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rangeDiffCmd.Flags().BoolVar(&rangeDiffComment, "comment", false, "Post the summary and range-diff as a comment on the branch's PR")
+}
+
+func runRangeDiff(gitClient git.GitClient, githubClient github.GitHubClient, branch string) error {
+	if branch == "" {
+		var err error
+		branch, err = gitClient.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+	}
+
+	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", branch))
+	if parent == "" {
+		parent = stack.GetBaseBranch(gitClient)
+	}
+
+	// Refresh origin/<branch> so we compare against what reviewers actually see
+	if err := gitClient.FetchBranch(branch); err != nil && verbose {
+		fmt.Fprintf(os.Stderr, "Note: could not fetch %s: %v\n", branch, err)
+	}
+	if !gitClient.RemoteBranchExists(branch) {
+		fmt.Printf("%s has not been pushed yet, nothing to compare.\n", ui.Branch(branch))
+		return nil
+	}
+
+	output, summary, err := rangeDiffSincePush(gitClient, branch, parent)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		fmt.Printf("No commits in %s or %s.\n", ui.Branch(branch), git.RemoteRef(branch))
+		return nil
+	}
+
+	fmt.Println(output)
+	fmt.Println()
+	printRangeDiffSummary(summary)
+
+	if !rangeDiffComment {
+		return nil
+	}
+
+	pr, err := githubClient.GetPRForBranch(branch)
+	if err != nil || pr == nil {
+		return fmt.Errorf("no PR found for %s", branch)
+	}
+	if err := githubClient.CommentOnPR(pr.Number, syncer.FormatRangeDiffComment(summary, output)); err != nil {
+		return fmt.Errorf("failed to comment on PR #%d: %w", pr.Number, err)
+	}
+	fmt.Printf("\n%s Posted comment on PR #%d\n", ui.SuccessIcon(), pr.Number)
+	return nil
+}
+
+// rangeDiffSincePush range-diffs origin/<branch> against the local branch. Each side's
+// commits are taken relative to the parent as it was on that side: origin/<parent>
+// for the pushed branch, the local parent for the local one. That way commits of a
+// parent that was rebased in the meantime don't show up as changes.
+func rangeDiffSincePush(gitClient git.GitClient, branch, parent string) (string, git.RangeDiffSummary, error) {
+	remoteRef := git.RemoteRef(branch)
+
+	oldParent := parent
+	if gitClient.RemoteBranchExists(parent) {
+		oldParent = git.RemoteRef(parent)
+	}
+	oldBase, err := gitClient.GetMergeBase(oldParent, remoteRef)
+	if err != nil {
+		return "", git.RangeDiffSummary{}, fmt.Errorf("failed to find merge base of %s and %s: %w", oldParent, remoteRef, err)
+	}
+	newBase, err := gitClient.GetMergeBase(parent, branch)
+	if err != nil {
+		return "", git.RangeDiffSummary{}, fmt.Errorf("failed to find merge base of %s and %s: %w", parent, branch, err)
+	}
+
+	output, err := gitClient.RangeDiff(oldBase, remoteRef, newBase, branch)
+	if err != nil {
+		return "", git.RangeDiffSummary{}, fmt.Errorf("failed to compute range-diff: %w", err)
+	}
+	return output, git.ParseRangeDiff(output), nil
+}
+
+// printRangeDiffSummary prints one line per kind of change
+func printRangeDiffSummary(summary git.RangeDiffSummary) {
+	if !summary.HasContentChanges() {
+		fmt.Println(ui.Success(fmt.Sprintf("No content changes (%d commit(s) unchanged)", len(summary.Unchanged))))
+		return
+	}
+	if len(summary.Changed) > 0 {
+		fmt.Printf("%s %d changed: %s\n", ui.WarningIcon(), len(summary.Changed), strings.Join(summary.Changed, ", "))
+	}
+	if len(summary.Added) > 0 {
+		fmt.Printf("%s %d added: %s\n", ui.SuccessIcon(), len(summary.Added), strings.Join(summary.Added, ", "))
+	}
+	if len(summary.Dropped) > 0 {
+		fmt.Printf("%s %d dropped: %s\n", ui.ErrorIcon(), len(summary.Dropped), strings.Join(summary.Dropped, ", "))
+	}
+	if len(summary.Unchanged) > 0 {
+		fmt.Println(ui.Dim(fmt.Sprintf("%d unchanged", len(summary.Unchanged))))
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunRangeDiff(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	setupMocks := func(mockGit *testutil.MockGitClient, output string) {
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("RemoteBranchExists", "feature-b").Return(true)
+		mockGit.On("RemoteBranchExists", "feature-a").Return(true)
+		mockGit.On("GetMergeBase", "origin/feature-a", "origin/feature-b").Return("oldbase", nil)
+		mockGit.On("GetMergeBase", "feature-a", "feature-b").Return("newbase", nil)
+		mockGit.On("RangeDiff", "oldbase", "origin/feature-b", "newbase", "feature-b").Return(output, nil)
+	}
+
+	t.Run("compares against the pushed branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		setupMocks(mockGit, "1:  abc1234 = 1:  def5678 Add feature")
+
+		err := runRangeDiff(mockGit, mockGH, "")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertNotCalled(t, "CommentOnPR", mock.Anything, mock.Anything)
+	})
+
+	t.Run("posts comment on PR", func(t *testing.T) {
+		rangeDiffComment = true
+		defer func() { rangeDiffComment = false }()
+
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		setupMocks(mockGit, "-:  ------- > 2:  def5678 Add tests")
+		mockGH.On("GetPRForBranch", "feature-b").Return(testutil.NewPRInfo(12, "OPEN", "feature-a", "Feature B", "url"), nil)
+		mockGH.On("CommentOnPR", 12, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "- Add tests")
+		})).Return(nil)
+
+		err := runRangeDiff(mockGit, mockGH, "")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("branch not pushed yet", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetConfig", "branch.feature-c.stackparent").Return("feature-b")
+		mockGit.On("FetchBranch", "feature-c").Return(nil)
+		mockGit.On("RemoteBranchExists", "feature-c").Return(false)
+
+		err := runRangeDiff(mockGit, mockGH, "feature-c")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,614 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// configRebasePlanTodo holds the steps of an interrupted rebase plan, one per line
+	configRebasePlanTodo = "stack.rebasePlan.todo"
+	// configRebasePlanBackup holds "<branch> <sha>" lines to restore on --abort
+	configRebasePlanBackup = "stack.rebasePlan.backup"
+	// configRebasePlanOriginalBranch is the branch to return to when the plan is done
+	configRebasePlanOriginalBranch = "stack.rebasePlan.originalBranch"
+)
+
+var (
+	rebaseInteractivePlan bool
+	rebaseContinue        bool
+	rebaseAbort           bool
+)
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase",
+	Short: i18n.T("rebase.short"),
+	Long:  i18n.T("rebase.long"),
+	Example: `  # Reorder, fold or drop branches of the current stack
+  stack rebase --interactive-plan
+
+  # After resolving a conflict
+  git rebase --continue
+  stack rebase --continue
+
+  # Give up and restore every branch
+  stack rebase --abort`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runRebase(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rebaseCmd.Flags().BoolVarP(&rebaseInteractivePlan, "interactive-plan", "i", false, "Edit the order of the stack's branches in your editor and apply it")
+	rebaseCmd.Flags().BoolVar(&rebaseContinue, "continue", false, "Continue the plan after resolving a rebase conflict")
+	rebaseCmd.Flags().BoolVar(&rebaseAbort, "abort", false, "Abort the plan and restore every branch")
+	rebaseCmd.MarkFlagsMutuallyExclusive("interactive-plan", "continue", "abort")
+}
+
+// planEntry is one line of the plan edited by the user
+type planEntry struct {
+	Action string // pick, fold or drop
+	Branch string
+}
+
+// planStep is one step of the plan's execution, saved in git config so it can be
+// continued after a conflict:
+//
+//	rebase <branch> <old-base> <onto>   replay the branch's own commits onto a branch or commit
+//	move <branch> <target>              point branch at target (folds a branch into it)
+//	parent <branch> <parent>            set the branch's stack parent
+//	untrack <branch>                    remove the branch from the stack
+//	delete <branch> <into>              delete a branch folded into another, closing its PR
+type planStep []string
+
+func runRebase(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	switch {
+	case rebaseAbort:
+		return abortRebasePlan(gitClient)
+	case rebaseContinue:
+		return continueRebasePlan(gitClient, githubClient)
+	case rebaseInteractivePlan:
+		return startRebasePlan(gitClient, githubClient)
+	default:
+		return errors.New(i18n.T("rebase.nothingToDo", ui.Command("stack rebase --interactive-plan")))
+	}
+}
+
+func startRebasePlan(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	originalBranch, branches, err := rebasePlanStack(gitClient, "")
+	if err != nil || len(branches) == 0 {
+		return err
+	}
+	baseBranch := stack.GetBaseBranch(gitClient)
+
+	edited, err := editPlan(gitClient, formatPlan(branches, baseBranch))
+	if err != nil {
+		return err
+	}
+	entries, err := parsePlan(edited, branches)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Empty plan, nothing to do.")
+		return nil
+	}
+	if planUnchanged(entries, branches) {
+		fmt.Println("Plan unchanged, nothing to do.")
+		return nil
+	}
+
+	steps, err := compilePlan(gitClient, entries, branches, baseBranch)
+	if err != nil {
+		return err
+	}
+	return applyRebasePlan(gitClient, githubClient, steps, branches, originalBranch)
+}
+
+// rebasePlanStack checks a rebase plan can start and returns the current branch, and
+// the linear stack of branch (the current branch if empty), bottom first.
+// branches is empty when the branch isn't in a stack.
+func rebasePlanStack(gitClient git.GitClient, branch string) (originalBranch string, branches []string, err error) {
+	if gitClient.GetConfig(configRebasePlanBackup) != "" {
+		return "", nil, errors.New(i18n.T("rebase.planAlreadyInProgress", ui.Command("stack rebase --continue"), ui.Command("stack rebase --abort")))
+	}
+
+	clean, err := gitClient.IsWorkingTreeClean()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if !clean {
+		return "", nil, fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+	}
+
+	originalBranch, err = gitClient.GetCurrentBranch()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	if branch == "" {
+		branch = originalBranch
+	}
+	branches, err = linearStack(gitClient, branch)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(branches) == 0 {
+		fmt.Printf("Branch '%s' is not in a stack.\n", ui.Branch(branch))
+	}
+	return originalBranch, branches, nil
+}
+
+// applyRebasePlan saves the commits of branches for --abort and runs the steps
+func applyRebasePlan(gitClient git.GitClient, githubClient github.GitHubClient, steps []planStep, branches []string, originalBranch string) error {
+	if !dryRun {
+		var backup []string
+		for _, branch := range branches {
+			hash, err := gitClient.GetCommitHash(branch)
+			if err != nil {
+				return fmt.Errorf("failed to get commit of %s: %w", branch, err)
+			}
+			backup = append(backup, branch+" "+hash)
+		}
+		if err := gitClient.SetConfig(configRebasePlanBackup, strings.Join(backup, "\n")); err != nil {
+			return fmt.Errorf("failed to save rebase plan state: %w", err)
+		}
+		if err := gitClient.SetConfig(configRebasePlanOriginalBranch, originalBranch); err != nil {
+			return fmt.Errorf("failed to save rebase plan state: %w", err)
+		}
+	}
+
+	return executeRebasePlan(gitClient, githubClient, steps, originalBranch)
+}
+
+func continueRebasePlan(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	todo := gitClient.GetConfig(configRebasePlanTodo)
+	if todo == "" {
+		return fmt.Errorf("no rebase plan in progress")
+	}
+	if gitClient.IsRebaseInProgress() {
+		return errors.New(i18n.T("rebase.stillInProgress", ui.Command("git rebase --continue")))
+	}
+
+	fmt.Println("Continuing rebase plan...")
+	fmt.Println()
+	return executeRebasePlan(gitClient, githubClient, decodePlanSteps(todo), gitClient.GetConfig(configRebasePlanOriginalBranch))
+}
+
+func abortRebasePlan(gitClient git.GitClient) error {
+	backup := gitClient.GetConfig(configRebasePlanBackup)
+	if backup == "" {
+		return fmt.Errorf("no rebase plan in progress")
+	}
+
+	if gitClient.IsRebaseInProgress() {
+		if err := gitClient.AbortRebase(); err != nil {
+			return fmt.Errorf("failed to abort rebase: %w", err)
+		}
+		fmt.Println(ui.Success("Aborted rebase"))
+	}
+
+	// Stack parents are only changed once every branch is rebased, so restoring
+	// the branches is enough
+	for _, line := range strings.Split(backup, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if err := gitClient.CheckoutBranch(fields[0]); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", fields[0], err)
+		}
+		if err := gitClient.ResetHard(fields[1]); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", fields[0], err)
+		}
+		fmt.Printf("%s Restored %s\n", ui.SuccessIcon(), ui.Branch(fields[0]))
+	}
+
+	if originalBranch := gitClient.GetConfig(configRebasePlanOriginalBranch); originalBranch != "" {
+		if err := gitClient.CheckoutBranch(originalBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
+		}
+	}
+
+	clearRebasePlanState(gitClient)
+	fmt.Println()
+	fmt.Println(ui.Success("Rebase plan aborted"))
+	return nil
+}
+
+// linearStack returns the branches of branch's stack, bottom first: the chain from
+// the base up to branch, then the branches stacked above it up to the top. Every
+// branch in the stack may have at most one child, since the plan is a single list.
+func linearStack(gitClient git.GitClient, branch string) ([]string, error) {
+	tree, err := stack.BuildStackTreeForBranch(gitClient, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build stack tree: %w", err)
+	}
+	if tree == nil {
+		return nil, nil
+	}
+
+	var branches []string
+	for node := tree; len(node.Children) > 0; node = node.Children[0] {
+		if len(node.Children) > 1 {
+			return nil, fmt.Errorf("'%s' has %d child branches, only linear stacks can be rebased with a plan", node.Name, len(node.Children))
+		}
+		branches = append(branches, node.Children[0].Name)
+	}
+
+	// The chain stops at branch, the branches above it have to move with the rest
+	for top := branches[len(branches)-1]; ; {
+		children, err := stack.GetChildrenOf(gitClient, top)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get children of %s: %w", top, err)
+		}
+		if len(children) == 0 {
+			return branches, nil
+		}
+		if len(children) > 1 {
+			return nil, fmt.Errorf("'%s' has %d child branches, only linear stacks can be rebased with a plan", top, len(children))
+		}
+		top = children[0].Name
+		branches = append(branches, top)
+	}
+}
+
+// formatPlan renders the plan the user edits, one "pick" per branch
+func formatPlan(branches []string, baseBranch string) string {
+	var b strings.Builder
+	for _, branch := range branches {
+		fmt.Fprintf(&b, "pick %s\n", branch)
+	}
+	fmt.Fprintf(&b, `
+# Rebase plan for the stack on %s (bottom of the stack first)
+#
+# Commands:
+# p, pick <branch> = keep the branch
+# f, fold <branch> = fold the branch's commits into the branch above it in this list
+# d, drop <branch> = take the branch and its commits out of the stack
+#
+# Lines can be reordered. Removing a line drops the branch.
+# An empty plan does nothing.
+`, baseBranch)
+	return b.String()
+}
+
+// editPlan lets the user edit plan in their editor and returns the result
+func editPlan(gitClient git.GitClient, plan string) (string, error) {
+	file, err := os.CreateTemp("", "stack-rebase-plan-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create plan file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(plan); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write plan file: %w", err)
+	}
+	file.Close()
+
+	if err := gitClient.EditFile(file.Name()); err != nil {
+		return "", err
+	}
+
+	edited, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", fmt.Errorf("failed to read plan file: %w", err)
+	}
+	return string(edited), nil
+}
+
+// parsePlan parses the edited plan, checking it only names branches of the stack
+func parsePlan(plan string, branches []string) ([]planEntry, error) {
+	inStack := make(map[string]bool)
+	for _, branch := range branches {
+		inStack[branch] = true
+	}
+
+	var entries []planEntry
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(plan, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid plan line '%s', expected '<action> <branch>'", line)
+		}
+
+		var action string
+		switch fields[0] {
+		case "p", "pick":
+			action = "pick"
+		case "f", "fold":
+			action = "fold"
+		case "d", "drop":
+			action = "drop"
+		default:
+			return nil, fmt.Errorf("unknown action '%s' in plan line '%s'", fields[0], line)
+		}
+
+		branch := fields[1]
+		if !inStack[branch] {
+			return nil, fmt.Errorf("'%s' is not a branch of this stack", branch)
+		}
+		if seen[branch] {
+			return nil, fmt.Errorf("'%s' appears more than once in the plan", branch)
+		}
+		seen[branch] = true
+
+		if action == "fold" && !hasKeptEntry(entries) {
+			return nil, fmt.Errorf("cannot fold '%s': there is no branch above it to fold into", branch)
+		}
+		entries = append(entries, planEntry{Action: action, Branch: branch})
+	}
+
+	// Like 'git rebase -i', a removed line drops the branch
+	if len(entries) > 0 {
+		for _, branch := range branches {
+			if !seen[branch] {
+				entries = append(entries, planEntry{Action: "drop", Branch: branch})
+			}
+		}
+	}
+	return entries, nil
+}
+
+func hasKeptEntry(entries []planEntry) bool {
+	for _, entry := range entries {
+		if entry.Action == "pick" {
+			return true
+		}
+	}
+	return false
+}
+
+// planUnchanged reports whether the plan picks every branch in its current order
+func planUnchanged(entries []planEntry, branches []string) bool {
+	if len(entries) != len(branches) {
+		return false
+	}
+	for i, entry := range entries {
+		if entry.Action != "pick" || entry.Branch != branches[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// compilePlan turns the plan into steps. Every branch's own commits are those
+// since its current parent, so they're replayed with --onto from the parent's
+// current commit; the bottom branch's are those since its fork point with the base.
+func compilePlan(gitClient git.GitClient, entries []planEntry, branches []string, baseBranch string) ([]planStep, error) {
+	oldBase := make(map[string]string)
+	for i, branch := range branches {
+		var hash string
+		var err error
+		if i == 0 {
+			hash, err = gitClient.GetMergeBase(baseBranch, branch)
+		} else {
+			hash, err = gitClient.GetCommitHash(branches[i-1])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find where %s starts: %w", branch, err)
+		}
+		oldBase[branch] = hash
+	}
+	forkPoint := oldBase[branches[0]]
+
+	var rebases, configs []planStep
+	prev := ""
+	for _, entry := range entries {
+		switch entry.Action {
+		case "pick":
+			onto, parent := forkPoint, baseBranch
+			if prev != "" {
+				onto, parent = prev, prev
+			}
+			rebases = append(rebases, planStep{"rebase", entry.Branch, oldBase[entry.Branch], onto})
+			configs = append(configs, planStep{"parent", entry.Branch, parent})
+			prev = entry.Branch
+		case "fold":
+			rebases = append(rebases,
+				planStep{"rebase", entry.Branch, oldBase[entry.Branch], prev},
+				planStep{"move", prev, entry.Branch},
+			)
+			configs = append(configs, planStep{"untrack", entry.Branch})
+		case "drop":
+			configs = append(configs, planStep{"untrack", entry.Branch})
+		}
+	}
+
+	// Parents only change once every branch is in place, so --abort just resets branches
+	return append(rebases, configs...), nil
+}
+
+// executeRebasePlan runs the steps, saving the rest of them if a rebase stops on a conflict
+func executeRebasePlan(gitClient git.GitClient, githubClient github.GitHubClient, steps []planStep, originalBranch string) error {
+	parents := make(map[string]string)
+	var untracked []string
+	deleted := make(map[string]string)
+
+	for i, step := range steps {
+		switch step[0] {
+		case "rebase":
+			branch, oldBase, onto := step[1], step[2], step[3]
+			label := onto
+			if len(label) == 40 {
+				// The fork point with the base branch
+				label = label[:8]
+			}
+			fmt.Printf("Rebasing %s onto %s...\n", ui.Branch(branch), ui.Branch(label))
+			if err := gitClient.RebaseOnto(onto, oldBase, branch); err != nil {
+				if !dryRun {
+					if err := gitClient.SetConfig(configRebasePlanTodo, encodePlanSteps(steps[i+1:])); err != nil {
+						fmt.Fprintf(os.Stderr, "Warning: failed to save rebase plan state: %v\n", err)
+					}
+				}
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("conflict.rebaseOn", branch))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.continue"))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("rebase.conflict.continue"))
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("rebase.conflict.abort"))
+				fmt.Fprintf(os.Stderr, "    stack rebase --abort\n")
+				return ui.Reported(fmt.Errorf("failed to rebase %s: %w", branch, err))
+			}
+		case "move":
+			branch, target := step[1], step[2]
+			fmt.Printf("Folding %s into %s...\n", ui.Branch(target), ui.Branch(branch))
+			if err := gitClient.CheckoutBranch(branch); err != nil {
+				return fmt.Errorf("failed to checkout %s: %w", branch, err)
+			}
+			if err := gitClient.ResetHard(target); err != nil {
+				return fmt.Errorf("failed to fold %s into %s: %w", target, branch, err)
+			}
+		case "parent":
+			branch, parent := step[1], step[2]
+			if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", branch), parent); err != nil {
+				return fmt.Errorf("failed to set parent of %s: %w", branch, err)
+			}
+			parents[branch] = parent
+		case "untrack":
+			branch := step[1]
+			if err := gitClient.UnsetConfig(fmt.Sprintf("branch.%s.stackparent", branch)); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to remove %s from the stack: %v\n", branch, err)
+			}
+			untracked = append(untracked, branch)
+		case "delete":
+			branch, into := step[1], step[2]
+			if err := gitClient.DeleteBranchForce(branch); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to delete %s: %v\n", branch, err)
+				continue
+			}
+			deleted[branch] = into
+		}
+	}
+
+	if originalBranch != "" {
+		if err := gitClient.CheckoutBranch(originalBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
+		}
+	}
+	if !dryRun {
+		clearRebasePlanState(gitClient)
+	}
+
+	fmt.Println()
+	retargetPlanPRs(gitClient, githubClient, parents, untracked, deleted)
+
+	for _, branch := range untracked {
+		if into, ok := deleted[branch]; ok {
+			fmt.Printf("%s Deleted %s, folded into %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.Branch(into))
+			continue
+		}
+		fmt.Printf("%s %s is no longer part of the stack (delete it with %s)\n", ui.SuccessIcon(), ui.Branch(branch), ui.Command(fmt.Sprintf("git branch -D %s", branch)))
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Success("Rebase plan applied"))
+	fmt.Printf("Push the branches with '%s'\n", ui.Command("stack sync"))
+	return nil
+}
+
+// retargetPlanPRs points open PRs at their branch's new parent, closes those of
+// branches deleted after being folded into another, and points out PRs left open
+// for other branches taken out of the stack
+func retargetPlanPRs(gitClient git.GitClient, githubClient github.GitHubClient, parents map[string]string, untracked []string, deleted map[string]string) {
+	names := append([]string{}, untracked...)
+	for branch := range parents {
+		names = append(names, branch)
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	prCache, err := syncer.LoadPRs(githubClient, names, prScope)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("rebase.loadPRsFailed", err))
+		return
+	}
+
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	for branch, parent := range parents {
+		pr := prCache[branch]
+		if pr == nil || pr.State != "OPEN" || flatPRs {
+			continue
+		}
+		base := syncer.PRBaseFor(parent, parents, umbrellas)
+		if pr.Base == base {
+			continue
+		}
+		var notOpen *syncer.PRNotOpenError
+		if err := syncer.RetargetPR(githubClient, pr.Number, base); errors.As(err, &notOpen) {
+			fmt.Printf("%s %s\n", ui.WarningIcon(), i18n.T("rebase.prNotOpen", pr.Number, ui.PRState(notOpen.PR.State)))
+			continue
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update base of PR #%d: %v\n", pr.Number, err)
+			continue
+		}
+		fmt.Printf("%s PR #%d now targets %s\n", ui.SuccessIcon(), pr.Number, ui.Branch(base))
+	}
+
+	for _, branch := range untracked {
+		pr := prCache[branch]
+		if pr == nil || pr.State != "OPEN" {
+			continue
+		}
+		if into, ok := deleted[branch]; ok {
+			comment := fmt.Sprintf("Closed by stackinator: %s was folded into %s.", branch, into)
+			if intoPR := prCache[into]; intoPR != nil && intoPR.State == "OPEN" {
+				comment = fmt.Sprintf("Closed by stackinator: %s was folded into %s (#%d).", branch, into, intoPR.Number)
+			}
+			if err := githubClient.ClosePR(pr.Number, comment); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to close PR #%d: %v\n", pr.Number, err)
+				continue
+			}
+			fmt.Printf("%s Closed PR #%d for %s\n", ui.SuccessIcon(), pr.Number, ui.Branch(branch))
+		} else {
+			fmt.Printf("%s PR #%d for %s is still open, close it if it's no longer needed\n", ui.WarningIcon(), pr.Number, ui.Branch(branch))
+		}
+	}
+}
+
+func encodePlanSteps(steps []planStep) string {
+	lines := make([]string, 0, len(steps))
+	for _, step := range steps {
+		lines = append(lines, strings.Join(step, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func decodePlanSteps(todo string) []planStep {
+	var steps []planStep
+	for _, line := range strings.Split(todo, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			steps = append(steps, planStep(fields))
+		}
+	}
+	return steps
+}
+
+func clearRebasePlanState(gitClient git.GitClient) {
+	_ = gitClient.UnsetConfig(configRebasePlanTodo)
+	_ = gitClient.UnsetConfig(configRebasePlanBackup)
+	_ = gitClient.UnsetConfig(configRebasePlanOriginalBranch)
+}
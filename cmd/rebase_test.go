@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParsePlan(t *testing.T) {
+	branches := []string{"feature-a", "feature-b", "feature-c"}
+
+	t.Run("reorder, fold and removed lines", func(t *testing.T) {
+		entries, err := parsePlan("pick feature-b\n# comment\np feature-a\n", branches)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []planEntry{
+			{Action: "pick", Branch: "feature-b"},
+			{Action: "pick", Branch: "feature-a"},
+			{Action: "drop", Branch: "feature-c"},
+		}, entries)
+	})
+
+	t.Run("empty plan", func(t *testing.T) {
+		entries, err := parsePlan(formatPlan(nil, "main"), branches)
+
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	errorCases := map[string]string{
+		"unknown action":    "squash feature-a",
+		"unknown branch":    "pick feature-x",
+		"duplicate branch":  "pick feature-a\npick feature-a",
+		"fold without pick": "fold feature-a\npick feature-b",
+		"missing branch":    "pick",
+	}
+	for name, plan := range errorCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := parsePlan(plan, branches)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLinearStack(t *testing.T) {
+	tests := []struct {
+		name          string
+		parents       map[string]string
+		branch        string
+		want          []string
+		errorContains string
+	}{
+		{
+			name:    "includes the branches above the branch",
+			parents: map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b", "feature-d": "feature-c"},
+			branch:  "feature-b",
+			want:    []string{"feature-a", "feature-b", "feature-c", "feature-d"},
+		},
+		{
+			name:          "refuses a stack that branches out above the branch",
+			parents:       map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b", "feature-d": "feature-b"},
+			branch:        "feature-a",
+			errorContains: "'feature-b' has 2 child branches",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(tt.parents, nil)
+
+			branches, err := linearStack(mockGit, tt.branch)
+
+			if tt.errorContains != "" {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, branches)
+			}
+		})
+	}
+}
+
+func TestCompilePlan(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+	mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+	mockGit.On("GetCommitHash", "feature-b").Return("sha-b", nil)
+
+	entries := []planEntry{
+		{Action: "pick", Branch: "feature-b"},
+		{Action: "pick", Branch: "feature-a"},
+		{Action: "fold", Branch: "feature-c"},
+	}
+	steps, err := compilePlan(mockGit, entries, []string{"feature-a", "feature-b", "feature-c"}, "main")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []planStep{
+		{"rebase", "feature-b", "sha-a", "fork"},
+		{"rebase", "feature-a", "fork", "feature-b"},
+		{"rebase", "feature-c", "sha-b", "feature-a"},
+		{"move", "feature-a", "feature-c"},
+		{"parent", "feature-b", "main"},
+		{"parent", "feature-a", "feature-b"},
+		{"untrack", "feature-c"},
+	}, steps)
+}
+
+func TestExecuteRebasePlan(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	steps := []planStep{
+		{"rebase", "feature-b", "sha-a", "fork"},
+		{"rebase", "feature-a", "fork", "feature-b"},
+		{"parent", "feature-b", "main"},
+		{"parent", "feature-a", "feature-b"},
+	}
+
+	tests := []struct {
+		name           string
+		setupMocks     func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectReported bool
+	}{
+		{
+			name: "saves the remaining steps on conflict",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("RebaseOnto", "fork", "sha-a", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-b", "fork", "feature-a").Return(errors.New("conflict"))
+				mockGit.On("SetConfig", configRebasePlanTodo, "parent feature-b main\nparent feature-a feature-b").Return(nil)
+			},
+			expectReported: true,
+		},
+		{
+			name: "updates parents and retargets PRs",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("RebaseOnto", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "feature-b").Return(nil)
+				mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+				mockGit.On("UnsetConfig", mock.Anything).Return(nil)
+				testutil.AllowStackUmbrellas(mockGit)
+				mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{
+					"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "A", "url"),
+					"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url"),
+				}, nil)
+				mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url"), nil)
+				mockGH.On("GetPRByNumber", 2).Return(testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url"), nil)
+				mockGH.On("UpdatePRBase", 1, "feature-b").Return(nil)
+				mockGH.On("UpdatePRBase", 2, "main").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			tt.setupMocks(mockGit, mockGH)
+
+			err := executeRebasePlan(mockGit, mockGH, steps, "feature-a")
+
+			if tt.expectReported {
+				assert.True(t, isAlreadyReported(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
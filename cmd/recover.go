@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// journalFile records a rename or reparent while it runs, in the git directory
+// shared by all worktrees, so 'stack recover' can finish or undo it if it stops
+// part way
+const journalFile = "stackinator/journal.json"
+
+// recoverRollback undoes the unfinished operation instead of finishing it
+var recoverRollback bool
+
+var recoverCmd = &cobra.Command{
+	Use:   "recover",
+	Short: i18n.T("recover.short"),
+	Long:  i18n.T("recover.long"),
+	Example: `  # Finish an interrupted rename or reparent
+  stack recover
+
+  # Put the stack back as it was before it
+  stack recover --rollback`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runRecover(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	recoverCmd.Flags().BoolVar(&recoverRollback, "rollback", false, "Undo the steps that happened instead of finishing the operation")
+}
+
+// journal is a rename or reparent in progress
+type journal struct {
+	Operation string    `json:"operation"` // "rename" or "reparent"
+	StartedAt time.Time `json:"startedAt"`
+	Branch    string    `json:"branch"`
+	OldParent string    `json:"oldParent,omitempty"`
+
+	// rename
+	NewName  string   `json:"newName,omitempty"`
+	Children []string `json:"children,omitempty"`
+
+	// reparent
+	NewParent string `json:"newParent,omitempty"`
+}
+
+// describe says what the journaled operation was doing
+func (j *journal) describe() string {
+	if j.Operation == "rename" {
+		return fmt.Sprintf("rename of %s to %s", ui.Branch(j.Branch), ui.Branch(j.NewName))
+	}
+	if j.OldParent == "" {
+		return fmt.Sprintf("reparent of %s onto %s", ui.Branch(j.Branch), ui.Branch(j.NewParent))
+	}
+	return fmt.Sprintf("reparent of %s from %s onto %s", ui.Branch(j.Branch), ui.Branch(j.OldParent), ui.Branch(j.NewParent))
+}
+
+func journalPath(gitClient git.GitClient) (string, error) {
+	dir, err := gitClient.GetCommonDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git directory: %w", err)
+	}
+	return filepath.Join(dir, journalFile), nil
+}
+
+// readJournal returns the unfinished operation, or nil when there is none
+func readJournal(gitClient git.GitClient) (*journal, error) {
+	path, err := journalPath(gitClient)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var j journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &j, nil
+}
+
+// beginJournal records an operation before its first step. It refuses to start
+// while another one is unfinished, since that one's steps would get mixed in.
+// Nothing is recorded in a dry run, as nothing changes.
+func beginJournal(gitClient git.GitClient, j journal) error {
+	if dryRun {
+		return nil
+	}
+	pending, err := readJournal(gitClient)
+	if err != nil {
+		return err
+	}
+	if pending != nil {
+		return errors.New(i18n.T("recover.pending", pending.describe(), ui.Command("stack recover"), ui.Command("stack recover --rollback")))
+	}
+
+	path, err := journalPath(gitClient)
+	if err != nil {
+		return err
+	}
+	j.StartedAt = time.Now()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// endJournal clears the record of a finished operation
+func endJournal(gitClient git.GitClient) {
+	if dryRun {
+		return
+	}
+	path, err := journalPath(gitClient)
+	if err != nil {
+		return
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove %s: %v\n", path, err)
+	}
+}
+
+// recoverHint points a failed rename or reparent at 'stack recover'
+func recoverHint(operation string, err error) error {
+	if dryRun {
+		return err
+	}
+	return fmt.Errorf("%w\n\n%s", err, i18n.T("recover.stopped", operation, ui.Command("stack recover"), ui.Command("stack recover --rollback")))
+}
+
+func runRecover(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	j, err := readJournal(gitClient)
+	if err != nil {
+		return err
+	}
+	if j == nil {
+		fmt.Println("Nothing to recover: no rename or reparent was interrupted")
+		return nil
+	}
+
+	action := "Finishing"
+	if recoverRollback {
+		action = "Rolling back"
+	}
+	fmt.Printf("%s the %s (started %s ago)\n", action, j.describe(), ui.Age(time.Since(j.StartedAt)))
+
+	switch {
+	case j.Operation == "rename" && recoverRollback:
+		err = renameSteps(gitClient, j.NewName, j.Branch, j.OldParent, j.Children)
+	case j.Operation == "rename":
+		err = renameSteps(gitClient, j.Branch, j.NewName, j.OldParent, j.Children)
+	case j.Operation == "reparent" && recoverRollback:
+		err = reparentSteps(gitClient, githubClient, j.Branch, j.OldParent)
+	case j.Operation == "reparent":
+		err = reparentSteps(gitClient, githubClient, j.Branch, j.NewParent)
+	default:
+		return fmt.Errorf("unknown operation %q in the journal", j.Operation)
+	}
+	if err != nil {
+		return err
+	}
+
+	endJournal(gitClient)
+	if !dryRun {
+		fmt.Println(ui.Success("Recovered, the stack is consistent again"))
+	}
+	return nil
+}
+
+// renameSteps brings a rename from one name to the other, skipping the steps
+// already done. Rolling back is the same rename in the other direction.
+func renameSteps(gitClient git.GitClient, from, to, parent string, children []string) error {
+	fromExists, toExists := gitClient.BranchExists(from), gitClient.BranchExists(to)
+	switch {
+	case fromExists && toExists:
+		return errors.New(i18n.T("recover.bothExist", from, to, ui.Command("stack recover")))
+	case fromExists:
+		if err := gitClient.RenameBranch(from, to); err != nil {
+			return fmt.Errorf("failed to rename branch: %w", err)
+		}
+		fmt.Printf("  %s Renamed %s to %s\n", ui.SuccessIcon(), ui.Branch(from), ui.Branch(to))
+	case !toExists:
+		return fmt.Errorf("neither %s nor %s exists", from, to)
+	}
+
+	// git moves the branch's config along with it, but not always all of it
+	toKey := fmt.Sprintf("branch.%s.stackparent", to)
+	if gitClient.GetConfig(toKey) != parent {
+		if err := gitClient.SetConfig(toKey, parent); err != nil {
+			return fmt.Errorf("failed to set the parent of %s: %w", to, err)
+		}
+		fmt.Printf("  %s Set the parent of %s to %s\n", ui.SuccessIcon(), ui.Branch(to), ui.Branch(parent))
+	}
+	fromKey := fmt.Sprintf("branch.%s.stackparent", from)
+	if gitClient.GetConfig(fromKey) != "" {
+		if err := gitClient.UnsetConfig(fromKey); err != nil {
+			return fmt.Errorf("failed to remove the parent of %s: %w", from, err)
+		}
+	}
+
+	for _, child := range children {
+		childKey := fmt.Sprintf("branch.%s.stackparent", child)
+		if gitClient.GetConfig(childKey) != from {
+			continue
+		}
+		if err := gitClient.SetConfig(childKey, to); err != nil {
+			return fmt.Errorf("failed to update child %s: %w", child, err)
+		}
+		fmt.Printf("  %s Updated child %s to point to %s\n", ui.SuccessIcon(), ui.Branch(child), ui.Branch(to))
+	}
+	return nil
+}
+
+// reparentSteps moves branch onto parent to, along with its PR, skipping the
+// steps already done. An empty parent takes the branch out of the stack, as it
+// was before it was first reparented.
+func reparentSteps(gitClient git.GitClient, githubClient github.GitHubClient, branch, to string) error {
+	key := fmt.Sprintf("branch.%s.stackparent", branch)
+	if gitClient.GetConfig(key) != to {
+		var err error
+		if to == "" {
+			err = gitClient.UnsetConfig(key)
+		} else {
+			err = gitClient.SetConfig(key, to)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update parent config: %w", err)
+		}
+		if to == "" {
+			fmt.Printf("  %s Removed %s from the stack\n", ui.SuccessIcon(), ui.Branch(branch))
+		} else {
+			fmt.Printf("  %s Set the parent of %s to %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.Branch(to))
+		}
+	}
+
+	if to == "" || flatPRs {
+		return nil
+	}
+	pr, err := githubClient.GetPRForBranch(branch)
+	if err != nil {
+		return fmt.Errorf("failed to check for PR: %w", err)
+	}
+	if pr == nil || pr.State != "OPEN" || pr.Base == to {
+		return nil
+	}
+	if err := githubClient.UpdatePRBase(pr.Number, to); err != nil {
+		return fmt.Errorf("failed to update PR base: %w", err)
+	}
+	fmt.Printf("  %s Updated PR #%d base to %s\n", ui.SuccessIcon(), pr.Number, ui.Branch(to))
+	return nil
+}
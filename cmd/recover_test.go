@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameFailureLeavesJournal(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+	mockGit.On("CheckBranchName", "feature-new").Return(nil)
+	mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+	mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+	mockGit.On("BranchExists", "feature-new").Return(false)
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+	}, nil)
+	mockGit.On("RenameBranch", "feature-a", "feature-new").Return(nil)
+	mockGit.On("SetConfig", "branch.feature-new.stackparent", "main").Return(nil)
+	mockGit.On("UnsetConfig", "branch.feature-a.stackparent").Return(nil)
+	mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-new").Return(errors.New("could not lock config file"))
+
+	err := runRename(mockGit, "feature-new")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stack recover")
+	j, err := readJournal(mockGit)
+	require.NoError(t, err)
+	require.NotNil(t, j)
+	assert.Equal(t, journal{
+		Operation: "rename",
+		StartedAt: j.StartedAt,
+		Branch:    "feature-a",
+		OldParent: "main",
+		NewName:   "feature-new",
+		Children:  []string{"feature-b"},
+	}, *j)
+
+	// Another rename has to wait for the first to be recovered
+	err = runRename(mockGit, "feature-new")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "didn't finish")
+}
+
+func TestRecoverRename(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("finishes the steps that didn't happen", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+		require.NoError(t, beginJournal(mockGit, journal{Operation: "rename", Branch: "feature-a", OldParent: "main", NewName: "feature-new", Children: []string{"feature-b", "feature-c"}}))
+
+		// The branch was renamed, and git moved its parent along; one child was updated
+		mockGit.On("BranchExists", "feature-a").Return(false)
+		mockGit.On("BranchExists", "feature-new").Return(true)
+		mockGit.On("GetConfig", "branch.feature-new.stackparent").Return("main")
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("")
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-new")
+		mockGit.On("GetConfig", "branch.feature-c.stackparent").Return("feature-a")
+		mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-new").Return(nil)
+
+		require.NoError(t, runRecover(mockGit, mockGH))
+
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "RenameBranch", "feature-a", "feature-new")
+		j, err := readJournal(mockGit)
+		require.NoError(t, err)
+		assert.Nil(t, j)
+	})
+
+	t.Run("rolls back", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+		require.NoError(t, beginJournal(mockGit, journal{Operation: "rename", Branch: "feature-a", OldParent: "main", NewName: "feature-new", Children: []string{"feature-b"}}))
+		recoverRollback = true
+		defer func() { recoverRollback = false }()
+
+		mockGit.On("BranchExists", "feature-new").Return(true)
+		mockGit.On("BranchExists", "feature-a").Return(false)
+		mockGit.On("RenameBranch", "feature-new", "feature-a").Return(nil)
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("GetConfig", "branch.feature-new.stackparent").Return("")
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-new")
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+
+		require.NoError(t, runRecover(mockGit, mockGH))
+
+		mockGit.AssertExpectations(t)
+	})
+}
+
+func TestRecoverReparent(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+	mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+	require.NoError(t, beginJournal(mockGit, journal{Operation: "reparent", Branch: "feature-b", OldParent: "feature-a", NewParent: "main"}))
+
+	// The parent was updated, the PR wasn't
+	mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("main")
+	mockGH.On("GetPRForBranch", "feature-b").Return(testutil.NewPRInfo(7, "OPEN", "feature-a", "B", "url"), nil)
+	mockGH.On("UpdatePRBase", 7, "main").Return(nil)
+
+	require.NoError(t, runRecover(mockGit, mockGH))
+
+	mockGit.AssertExpectations(t)
+	mockGH.AssertExpectations(t)
+}
+
+func TestRecoverNothing(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+
+	assert.NoError(t, runRecover(mockGit, new(testutil.MockGitHubClient)))
+}
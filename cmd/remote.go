@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// configRemote names the remote branches are fetched from, pushed to and
+// compared with, and whose URL picks the GitHub repository. Defaults to origin.
+const configRemote = "stack.remote"
+
+// configureRemote points git.Remote at the remote named by stack.remote, if
+// it's set and the repository has it
+func configureRemote(gitClient git.GitClient) error {
+	remote := gitClient.GetConfig(configRemote)
+	if remote == "" {
+		return nil
+	}
+	if gitClient.GetRemoteURL(remote) == "" {
+		return fmt.Errorf("%s is set to %q, but there is no such remote (see 'git remote -v')", configRemote, remote)
+	}
+	git.Remote = remote
+	return nil
+}
+
+// configPushRemote names the remote stack branches are pushed to when it isn't
+// stack.remote: a fork, with PRs opened from it against stack.remote's repo
+const configPushRemote = "stack.pushRemote"
+
+// configurePushRemote sets up pushing to the fork named by stack.pushRemote. The
+// base branch, and any other branch a stack is based on, stays on git.Remote;
+// PRs are opened with the fork owner's name in front of the branch.
+func configurePushRemote(gitClient git.GitClient) error {
+	remote := gitClient.GetConfig(configPushRemote)
+	if remote == "" || remote == git.Remote {
+		return nil
+	}
+	url := gitClient.GetRemoteURL(remote)
+	if url == "" {
+		return fmt.Errorf("%s is set to %q, but there is no such remote (see 'git remote -v')", configPushRemote, remote)
+	}
+	owner := forkOwner(url)
+	if owner == "" {
+		return fmt.Errorf("%s: can't tell the owner of %s from its URL %s", configPushRemote, remote, url)
+	}
+
+	upstream := []string{stack.GetBaseBranch(gitClient)}
+	if parents, err := gitClient.GetAllStackParents(); err == nil {
+		for _, parent := range parents {
+			if _, tracked := parents[parent]; !tracked {
+				upstream = append(upstream, parent)
+			}
+		}
+	}
+
+	git.PushRemote = remote
+	git.SetUpstreamBranches(upstream)
+	github.HeadOwner = owner
+	return nil
+}
+
+// forkOwner returns the owner in a remote URL, e.g. me in git@github.com:me/repo.git
+func forkOwner(url string) string {
+	parts := strings.Split(github.ParseRepoFromURL(url), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[len(parts)-2]
+}
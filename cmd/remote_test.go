@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureRemote(t *testing.T) {
+	defer func() { git.Remote = "origin" }()
+
+	t.Run("defaults to origin", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.remote").Return("")
+
+		assert.NoError(t, configureRemote(mockGit))
+		assert.Equal(t, "origin/main", git.RemoteRef("main"))
+	})
+
+	t.Run("missing remote", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.remote").Return("upstream")
+		mockGit.On("GetRemoteURL", "upstream").Return("")
+
+		err := configureRemote(mockGit)
+
+		assert.ErrorContains(t, err, "no such remote")
+		assert.Equal(t, "origin", git.Remote)
+	})
+
+	t.Run("another remote", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.remote").Return("upstream")
+		mockGit.On("GetRemoteURL", "upstream").Return("git@github.com:org/repo.git")
+
+		assert.NoError(t, configureRemote(mockGit))
+		assert.Equal(t, "upstream/main", git.RemoteRef("main"))
+	})
+}
+
+func TestConfigurePushRemote(t *testing.T) {
+	defer func() {
+		git.PushRemote = ""
+		git.SetUpstreamBranches(nil)
+		github.HeadOwner = ""
+	}()
+
+	t.Run("not set", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.pushRemote").Return("")
+
+		assert.NoError(t, configurePushRemote(mockGit))
+		assert.Empty(t, git.PushRemote)
+		assert.Empty(t, github.HeadOwner)
+	})
+
+	t.Run("missing remote", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.pushRemote").Return("myfork")
+		mockGit.On("GetRemoteURL", "myfork").Return("")
+
+		err := configurePushRemote(mockGit)
+
+		assert.ErrorContains(t, err, "no such remote")
+		assert.Empty(t, git.PushRemote)
+	})
+
+	t.Run("fork", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.pushRemote").Return("myfork")
+		mockGit.On("GetRemoteURL", "myfork").Return("git@github.com:me/repo.git")
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+		mockGit.On("GetAllStackParents").Return(map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+			"hotfix":    "release",
+		}, nil)
+
+		assert.NoError(t, configurePushRemote(mockGit))
+		assert.Equal(t, "me", github.HeadOwner)
+		assert.Equal(t, "myfork/feature-a", git.RemoteRef("feature-a"))
+		assert.Equal(t, "myfork/feature-b", git.RemoteRef("feature-b"))
+		// Branches the stacks are based on stay upstream
+		assert.Equal(t, "origin/main", git.RemoteRef("main"))
+		assert.Equal(t, "origin/release", git.RemoteRef("release"))
+	})
+}
+
+func TestForkOwner(t *testing.T) {
+	assert.Equal(t, "me", forkOwner("git@github.com:me/repo.git"))
+	assert.Equal(t, "me", forkOwner("https://ghe.example.com/me/repo"))
+	assert.Empty(t, forkOwner("/srv/git/repo.git"))
+}
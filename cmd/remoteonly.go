@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// restoreRemoteOnlyBranches finds stack branches that were deleted locally but are
+// still on origin with an open PR, and offers to recreate them from origin. Without
+// them the stack is cut in two and their children have nothing to rebase onto.
+// --yes recreates them without asking.
+func restoreRemoteOnlyBranches(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	orphaned, err := gitClient.GetOrphanedStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to check for deleted stack branches: %w", err)
+	}
+
+	branches := make([]string, 0, len(orphaned))
+	for branch := range orphaned {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	for _, branch := range branches {
+		if !gitClient.RemoteBranchExists(branch) {
+			continue
+		}
+		pr, err := githubClient.GetPRForBranch(branch)
+		if err != nil || pr == nil || pr.State != "OPEN" {
+			continue
+		}
+
+		fmt.Printf("%s %s was deleted locally, but is still on origin with open PR #%d\n",
+			ui.WarningIcon(), ui.Branch(branch), pr.Number)
+		if !assumeYes && !confirmRestore(branch) {
+			fmt.Printf("  Skipped. Recreate it with '%s', or forget it with '%s'\n\n",
+				ui.Command(fmt.Sprintf("git branch %s %s", branch, git.RemoteRef(branch))), ui.Command("stack clean-config"))
+			continue
+		}
+
+		if err := gitClient.CreateBranch(branch, git.RemoteRef(branch)); err != nil {
+			return fmt.Errorf("failed to recreate %s from origin: %w", branch, err)
+		}
+		if !dryRun {
+			fmt.Println(ui.Success(fmt.Sprintf("Recreated %s from %s", ui.Branch(branch), git.RemoteRef(branch))))
+		}
+		fmt.Println()
+	}
+
+	return nil
+}
+
+// confirmRestore asks whether to recreate branch from origin. Defaults to yes;
+// without a terminal to answer on, nothing is recreated.
+func confirmRestore(branch string) bool {
+	fmt.Printf("  Recreate it from %s? [Y/n] ", git.RemoteRef(branch))
+	input, err := bufio.NewReader(stdinReader).ReadString('\n')
+	if err != nil && input == "" {
+		fmt.Println()
+		return false
+	}
+	input = strings.TrimSpace(strings.ToLower(input))
+	return input == "" || input == "y" || input == "yes"
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreRemoteOnlyBranches(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	orphaned := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "main"}
+
+	tests := []struct {
+		name       string
+		input      string
+		setupMocks func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+	}{
+		{
+			name:  "recreates branch with open PR when confirmed",
+			input: "\n",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No CreateBranch for feature-b: its PR is merged. No
+				// GetPRForBranch for feature-c: it's gone from origin too
+				mockGit.On("GetOrphanedStackParents").Return(orphaned, nil)
+				mockGit.On("RemoteBranchExists", "feature-a").Return(true)
+				mockGit.On("RemoteBranchExists", "feature-b").Return(true)
+				mockGit.On("RemoteBranchExists", "feature-c").Return(false)
+				mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url"), nil)
+				mockGH.On("GetPRForBranch", "feature-b").Return(testutil.NewPRInfo(2, "MERGED", "feature-a", "B", "url"), nil)
+				mockGit.On("CreateBranch", "feature-a", "origin/feature-a").Return(nil)
+			},
+		},
+		{
+			name:  "leaves branch alone when declined",
+			input: "n\n",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No CreateBranch: the prompt was declined
+				mockGit.On("GetOrphanedStackParents").Return(orphaned, nil)
+				mockGit.On("RemoteBranchExists", "feature-a").Return(true)
+				mockGit.On("RemoteBranchExists", "feature-b").Return(true)
+				mockGit.On("RemoteBranchExists", "feature-c").Return(false)
+				mockGH.On("GetPRForBranch", "feature-a").Return(testutil.NewPRInfo(1, "OPEN", "main", "A", "url"), nil)
+				mockGH.On("GetPRForBranch", "feature-b").Return(testutil.NewPRInfo(2, "MERGED", "feature-a", "B", "url"), nil)
+			},
+		},
+		{
+			name: "nothing to do",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOrphanedStackParents").Return(map[string]string{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdinReader = strings.NewReader(tt.input)
+			defer func() { stdinReader = os.Stdin }()
+
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			tt.setupMocks(mockGit, mockGH)
+
+			err := restoreRemoteOnlyBranches(mockGit, mockGH)
+
+			assert.NoError(t, err)
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/stack"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
@@ -12,15 +13,8 @@ import (
 
 var renameCmd = &cobra.Command{
 	Use:   "rename <new-name>",
-	Short: "Rename the current branch while preserving stack relationships",
-	Long: `Rename the current branch to a new name while preserving all stack relationships.
-
-This command will:
-  - Rename the git branch
-  - Update the branch's parent reference in git config
-  - Update all child branches to point to the new name
-
-The command must be run while on the branch you want to rename.`,
+	Short: i18n.T("rename.short"),
+	Long:  i18n.T("rename.long"),
 	Example: `  # Rename current branch
   stack rename feature-improved-name
 
@@ -33,13 +27,16 @@ The command must be run while on the branch you want to rename.`,
 		gitClient := git.NewGitClient()
 
 		if err := runRename(gitClient, newName); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
 
 func runRename(gitClient git.GitClient, newName string) error {
+	if err := validateBranchName(gitClient, newName); err != nil {
+		return err
+	}
+
 	// Get current branch
 	oldName, err := gitClient.GetCurrentBranch()
 	if err != nil {
@@ -63,6 +60,14 @@ func runRename(gitClient git.GitClient, newName string) error {
 		return fmt.Errorf("failed to get children: %w", err)
 	}
 
+	childNames := make([]string, len(children))
+	for i, child := range children {
+		childNames[i] = child.Name
+	}
+	if err := beginJournal(gitClient, journal{Operation: "rename", Branch: oldName, OldParent: oldParent, NewName: newName, Children: childNames}); err != nil {
+		return err
+	}
+
 	fmt.Printf("Renaming branch %s -> %s\n", ui.Branch(oldName), ui.Branch(newName))
 	if len(children) > 0 {
 		fmt.Printf("  Will update %d child branch(es)\n", len(children))
@@ -70,6 +75,7 @@ func runRename(gitClient git.GitClient, newName string) error {
 
 	// Rename the branch
 	if err := gitClient.RenameBranch(oldName, newName); err != nil {
+		endJournal(gitClient)
 		return fmt.Errorf("failed to rename branch: %w", err)
 	}
 
@@ -78,7 +84,7 @@ func runRename(gitClient git.GitClient, newName string) error {
 	newConfigKey := fmt.Sprintf("branch.%s.stackparent", newName)
 
 	if err := gitClient.SetConfig(newConfigKey, oldParent); err != nil {
-		return fmt.Errorf("failed to set new parent config: %w", err)
+		return recoverHint("rename", fmt.Errorf("failed to set new parent config: %w", err))
 	}
 
 	if err := gitClient.UnsetConfig(oldConfigKey); err != nil {
@@ -93,11 +99,13 @@ func runRename(gitClient git.GitClient, newName string) error {
 	for _, child := range children {
 		childConfigKey := fmt.Sprintf("branch.%s.stackparent", child.Name)
 		if err := gitClient.SetConfig(childConfigKey, newName); err != nil {
-			return fmt.Errorf("failed to update child %s: %w", child.Name, err)
+			return recoverHint("rename", fmt.Errorf("failed to update child %s: %w", child.Name, err))
 		}
 		fmt.Printf("  %s Updated child %s to point to %s\n", ui.SuccessIcon(), ui.Branch(child.Name), ui.Branch(newName))
 	}
 
+	endJournal(gitClient)
+
 	if !dryRun {
 		fmt.Println(ui.Success(fmt.Sprintf("Successfully renamed branch %s -> %s", ui.Branch(oldName), ui.Branch(newName))))
 		fmt.Println()
@@ -111,4 +119,3 @@ func runRename(gitClient git.GitClient, newName string) error {
 
 	return nil
 }
-
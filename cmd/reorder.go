@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var reorderCmd = &cobra.Command{
+	Use:   "reorder [branch]",
+	Short: i18n.T("reorder.short"),
+	Long:  i18n.T("reorder.long"),
+	Example: `  # Stack: main -> feature-a -> feature-b (current)
+  stack reorder
+  # Stack: main -> feature-b -> feature-a
+
+  # Move feature-c below its parent, from anywhere in the stack
+  stack reorder feature-c`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		branch := ""
+		if len(args) == 1 {
+			branch = args[0]
+		}
+		if err := runReorder(gitClient, githubClient, branch); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runReorder(gitClient git.GitClient, githubClient github.GitHubClient, branch string) error {
+	if branch != "" && !gitClient.BranchExists(branch) {
+		return fmt.Errorf("branch %s does not exist", branch)
+	}
+	originalBranch, branches, err := rebasePlanStack(gitClient, branch)
+	if err != nil || len(branches) == 0 {
+		return err
+	}
+
+	if branch == "" {
+		branch = originalBranch
+	}
+	position := slices.Index(branches, branch)
+	if position == 0 {
+		return fmt.Errorf("%s is at the bottom of the stack, there is no parent to swap it with", branch)
+	}
+	parent := branches[position-1]
+
+	entries := make([]planEntry, len(branches))
+	for i, b := range branches {
+		entries[i] = planEntry{Action: "pick", Branch: b}
+	}
+	entries[position-1], entries[position] = entries[position], entries[position-1]
+
+	steps, err := compilePlan(gitClient, entries, branches, stack.GetBaseBranch(gitClient))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Moving %s below %s\n\n", ui.Branch(branch), ui.Branch(parent))
+	return applyRebasePlan(gitClient, githubClient, steps, branches, originalBranch)
+}
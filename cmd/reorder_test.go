@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunReorder(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name          string
+		branch        string
+		setupMocks    func(*testutil.MockGitClient, *testutil.MockGitHubClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name: "swaps the current branch with its parent",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetMergeBase", "main", "feature-a").Return("fork", nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "feature-b").Return("sha-b", nil)
+				mockGit.On("GetCommitHash", "feature-c").Return("sha-c", nil)
+				mockGit.On("SetConfig", configRebasePlanBackup, mock.Anything).Return(nil)
+				mockGit.On("SetConfig", configRebasePlanOriginalBranch, "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "fork", "sha-a", "feature-b").Return(nil).Once()
+				mockGit.On("RebaseOnto", "feature-b", "fork", "feature-a").Return(nil).Once()
+				mockGit.On("RebaseOnto", "feature-a", "sha-b", "feature-c").Return(nil).Once()
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "feature-b").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-a").Return(nil)
+				mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+				mockGit.On("UnsetConfig", mock.Anything).Return(nil)
+				testutil.AllowStackUmbrellas(mockGit)
+				mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{}, nil)
+			},
+		},
+		{
+			name:   "refuses the bottom branch",
+			branch: "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				// No RebaseOnto: nothing is below it to swap with
+				mockGit.On("BranchExists", "feature-a").Return(true)
+			},
+			expectError:   true,
+			errorContains: "bottom of the stack",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGH := new(testutil.MockGitHubClient)
+			mockGit.On("GetConfig", configRebasePlanBackup).Return("")
+			mockGit.On("IsWorkingTreeClean").Return(true, nil)
+			mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}, nil)
+			tt.setupMocks(mockGit, mockGH)
+
+			err := runReorder(mockGit, mockGH, tt.branch)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+			mockGH.AssertExpectations(t)
+		})
+	}
+}
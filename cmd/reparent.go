@@ -2,26 +2,20 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/oplog"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var reparentCmd = &cobra.Command{
 	Use:   "reparent <new-parent>",
-	Short: "Change the parent of the current branch",
-	Long: `Change or set the parent branch of the current branch.
-
-This command updates the stack parent relationship in git config and, if a PR
-exists for the current branch, automatically updates the PR base to match the
-new parent.
-
-This is useful for:
-- Adding an existing branch to a stack (when no parent is currently set)
-- Reorganizing your stack when you want to change which branch a feature is based on`,
+	Short: i18n.T("reparent.short"),
+	Long:  i18n.T("reparent.long"),
 	Example: `  # Change current branch to be based on a different parent
   stack reparent feature-auth
 
@@ -35,12 +29,10 @@ This is useful for:
 		newParent := args[0]
 
 		gitClient := git.NewGitClient()
-		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL("origin"))
-		githubClient := github.NewGitHubClient(repo)
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
 
 		if err := runReparent(gitClient, githubClient, newParent); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -83,37 +75,55 @@ func runReparent(gitClient git.GitClient, githubClient github.GitHubClient, newP
 		fmt.Printf("Reparenting %s: %s -> %s\n", ui.Branch(currentBranch), ui.Branch(currentParent), ui.Branch(newParent))
 	}
 
+	if err := beginJournal(gitClient, journal{Operation: "reparent", Branch: currentBranch, OldParent: currentParent, NewParent: newParent}); err != nil {
+		return err
+	}
+
+	oplog.Record(gitClient, "reparent", currentBranch, currentBranch)
+
 	// Update git config
 	configKey := fmt.Sprintf("branch.%s.stackparent", currentBranch)
 	if err := gitClient.SetConfig(configKey, newParent); err != nil {
+		endJournal(gitClient)
 		return fmt.Errorf("failed to update parent config: %w", err)
 	}
+	oplog.Finish(gitClient, "reparent")
 
 	// Check if there's a PR for this branch
 	pr, err := githubClient.GetPRForBranch(currentBranch)
 	if err != nil {
-		// Error fetching PR info, but config was updated successfully
+		// Error fetching PR info, but config was updated successfully; sync retargets the PR later
+		endJournal(gitClient)
 		fmt.Println(ui.Success(fmt.Sprintf("Updated parent to %s", ui.Branch(newParent))))
 		fmt.Printf("Warning: failed to check for PR: %v\n", err)
 		return nil
 	}
 
-	if pr != nil {
+	if pr != nil && flatPRs {
+		// PRs keep targeting the base branch
+		endJournal(gitClient)
+		if !dryRun {
+			fmt.Println(ui.Success(fmt.Sprintf("Updated parent to %s", ui.Branch(newParent))))
+			fmt.Printf("  (PR #%d keeps targeting %s, %s is set)\n", pr.Number, ui.Branch(pr.Base), configFlatPRs)
+		}
+	} else if pr != nil {
 		// PR exists, update its base
 		fmt.Printf("Updating PR #%d base: %s -> %s\n", pr.Number, ui.Branch(pr.Base), ui.Branch(newParent))
 
 		if err := githubClient.UpdatePRBase(pr.Number, newParent); err != nil {
 			// Config was updated but PR base update failed
 			fmt.Println(ui.Success(fmt.Sprintf("Updated parent to %s", ui.Branch(newParent))))
-			return fmt.Errorf("failed to update PR base: %w", err)
+			return recoverHint("reparent", fmt.Errorf("failed to update PR base: %w", err))
 		}
 
+		endJournal(gitClient)
 		if !dryRun {
 			fmt.Println(ui.Success(fmt.Sprintf("Updated parent to %s", ui.Branch(newParent))))
 			fmt.Println(ui.Success(fmt.Sprintf("Updated PR #%d base to %s", pr.Number, ui.Branch(newParent))))
 		}
 	} else {
 		// No PR exists
+		endJournal(gitClient)
 		if !dryRun {
 			fmt.Println(ui.Success(fmt.Sprintf("Updated parent to %s", ui.Branch(newParent))))
 			fmt.Println("  (no PR found for this branch)")
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/oplog"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// restackDescendants also restacks the branch's children
+var restackDescendants bool
+
+var restackCmd = &cobra.Command{
+	Use:   "restack [branch]",
+	Short: i18n.T("restack.short"),
+	Long:  i18n.T("restack.long"),
+	Example: `  # Rebase the current branch onto its parent and push it
+  stack restack
+
+  # Restack feature-b and the branches stacked directly on it
+  stack restack feature-b --descendants`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		strategy, err := parsePushStrategy(gitClient.GetConfig(configPushStrategy))
+		if err != nil {
+			exitWithError(err)
+		}
+		syncPushStrategy = strategy
+		if syncForcePatterns, err = parseBranchPatterns(gitClient.GetConfig(configPushForceBranches)); err != nil {
+			exitWithError(err)
+		}
+
+		// Pushes upload the branch's LFS objects too
+		configureLFS(gitClient, false)
+
+		// Explain what restack does before the first force-push in this repo
+		if !dryRun {
+			if err := ensureOnboarded(gitClient); err != nil {
+				exitWithError(err)
+			}
+		}
+
+		branch := ""
+		if len(args) == 1 {
+			branch = args[0]
+		}
+		if err := runRestack(gitClient, branch); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	restackCmd.Flags().BoolVar(&restackDescendants, "descendants", false, "Also restack the branches stacked directly on the branch")
+}
+
+func runRestack(gitClient git.GitClient, branch string) error {
+	originalBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	if branch == "" {
+		branch = originalBranch
+	} else if !gitClient.BranchExists(branch) {
+		return fmt.Errorf("branch %s does not exist", branch)
+	}
+
+	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", branch))
+	if parent == "" {
+		return fmt.Errorf("%s is not in a stack, there is no parent to restack it onto", branch)
+	}
+
+	clean, err := gitClient.IsWorkingTreeClean()
+	if err != nil {
+		return fmt.Errorf("failed to check working tree status: %w", err)
+	}
+	if !clean {
+		return fmt.Errorf("working tree has uncommitted changes, commit or stash them first")
+	}
+
+	oplog.Record(gitClient, "restack", branch)
+
+	var children []stack.StackBranch
+	if restackDescendants {
+		if children, err = stack.GetChildrenOf(gitClient, branch); err != nil {
+			return fmt.Errorf("failed to get children of %s: %w", branch, err)
+		}
+	}
+
+	// The children's own commits start after the branch's current tip, which the
+	// rebase is about to move
+	oldTip, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get commit of %s: %w", branch, err)
+	}
+
+	if err := restackBranch(gitClient, branch, parent, ""); err != nil {
+		return err
+	}
+	for _, child := range children {
+		fmt.Println()
+		if err := restackBranch(gitClient, child.Name, branch, oldTip); err != nil {
+			return err
+		}
+	}
+
+	if current, err := gitClient.GetCurrentBranch(); err == nil && current != originalBranch {
+		if err := gitClient.CheckoutBranch(originalBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
+		}
+	}
+	oplog.Finish(gitClient, "restack")
+	return nil
+}
+
+// restackBranch replays branch's own commits onto parent and pushes it. The
+// commits start after oldBase when branch contains it (the parent's tip before it
+// was restacked), and after the merge base with parent otherwise.
+func restackBranch(gitClient git.GitClient, branch, parent, oldBase string) error {
+	fmt.Printf("Restacking %s onto %s\n", ui.Branch(branch), ui.Branch(parent))
+
+	parentHash, err := gitClient.GetCommitHash(parent)
+	if err != nil {
+		return fmt.Errorf("failed to get commit of %s: %w", parent, err)
+	}
+	base, err := gitClient.GetMergeBase(branch, parent)
+	if err != nil {
+		return fmt.Errorf("failed to find where %s forked from %s: %w", branch, parent, err)
+	}
+	if oldBase != "" {
+		if mergeBase, err := gitClient.GetMergeBase(branch, oldBase); err == nil && mergeBase == oldBase {
+			base = oldBase
+		}
+	}
+
+	if base == parentHash {
+		fmt.Printf("  %s Already on %s\n", ui.SuccessIcon(), ui.Branch(parent))
+	} else {
+		if err := gitClient.CheckoutBranch(branch); err != nil {
+			return fmt.Errorf("failed to checkout %s: %w", branch, err)
+		}
+		if err := gitClient.RebaseOnto(parent, base, branch); err != nil {
+			var conflict *git.ConflictError
+			if errors.As(err, &conflict) {
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("restack.conflict.stopped", branch))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("restack.conflict.resolve"))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("restack.conflict.continue"))
+				fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("restack.conflict.push", branch))
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("restack.conflict.abort", branch))
+				return ui.Reported(fmt.Errorf("failed to rebase %s: %w", branch, err))
+			}
+			return fmt.Errorf("failed to rebase %s: %w", branch, err)
+		}
+		fmt.Printf("  %s Rebased onto %s\n", ui.SuccessIcon(), ui.Branch(parent))
+	}
+
+	return pushRestacked(gitClient, branch)
+}
+
+// pushRestacked pushes branch after it was rewritten, unless it isn't on origin
+// yet or origin already has it
+func pushRestacked(gitClient git.GitClient, branch string) error {
+	if !gitClient.RemoteBranchExists(branch) {
+		fmt.Printf("  %s\n", ui.Dim("Not on origin yet, not pushed"))
+		return nil
+	}
+	localHash, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		return fmt.Errorf("failed to get commit of %s: %w", branch, err)
+	}
+	if remoteHash, err := gitClient.GetCommitHash(git.RemoteRef(branch)); err == nil && remoteHash == localHash {
+		fmt.Printf("  %s Up to date with origin\n", ui.SuccessIcon())
+		return nil
+	}
+	if err := syncer.PushBranch(gitClient, branch, syncer.PushStrategyFor(branch, syncPushStrategy, syncForcePatterns)); err != nil {
+		return fmt.Errorf("failed to push %s: %w", branch, err)
+	}
+	fmt.Printf("  %s Pushed %s\n", ui.SuccessIcon(), ui.Branch(branch))
+	return nil
+}
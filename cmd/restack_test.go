@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunRestack(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() {
+		restackDescendants = false
+		syncPushStrategy = syncer.PushLeaseSHA
+	}()
+	syncPushStrategy = syncer.PushLease
+
+	tests := []struct {
+		name          string
+		currentBranch string
+		parent        string
+		descendants   bool
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:          "rebases the branch onto its parent and pushes it",
+			currentBranch: "feature-b",
+			parent:        "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsWorkingTreeClean").Return(true, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				testutil.AllowOpLog(mockGit)
+				mockGit.On("GetCommitHash", "feature-b").Return("old-b", nil).Once()
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("fork", nil)
+				mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-a", "fork", "feature-b").Return(nil)
+				mockGit.On("RemoteBranchExists", "feature-b").Return(true)
+				mockGit.On("GetCommitHash", "feature-b").Return("new-b", nil)
+				mockGit.On("GetCommitHash", "origin/feature-b").Return("old-b", nil)
+				mockGit.On("Push", "feature-b", true).Return(nil)
+			},
+		},
+		{
+			name:          "skips the rebase and push when nothing changed",
+			currentBranch: "feature-b",
+			parent:        "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No RebaseOnto or Push: the branch is already on its parent and origin
+				mockGit.On("IsWorkingTreeClean").Return(true, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				testutil.AllowOpLog(mockGit)
+				mockGit.On("GetCommitHash", "feature-b").Return("sha-b", nil)
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("sha-a", nil)
+				mockGit.On("RemoteBranchExists", "feature-b").Return(true)
+				mockGit.On("GetCommitHash", "origin/feature-b").Return("sha-b", nil)
+			},
+		},
+		{
+			name:          "restacks direct children onto the branch with --descendants",
+			currentBranch: "feature-b",
+			parent:        "feature-a",
+			descendants:   true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsWorkingTreeClean").Return(true, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				testutil.AllowOpLog(mockGit)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b", "feature-d": "feature-c"}, nil)
+				mockGit.On("GetCommitHash", "feature-b").Return("old-b", nil).Once()
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("fork", nil)
+				mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-a", "fork", "feature-b").Return(nil)
+				mockGit.On("RemoteBranchExists", "feature-b").Return(false)
+
+				// The child's own commits start after feature-b's old tip; feature-d is left alone
+				mockGit.On("GetCommitHash", "feature-b").Return("new-b", nil)
+				mockGit.On("GetMergeBase", "feature-c", "feature-b").Return("fork", nil)
+				mockGit.On("GetMergeBase", "feature-c", "old-b").Return("old-b", nil)
+				mockGit.On("CheckoutBranch", "feature-c").Return(nil)
+				mockGit.On("RebaseOnto", "feature-b", "old-b", "feature-c").Return(nil)
+				mockGit.On("RemoteBranchExists", "feature-c").Return(false)
+			},
+		},
+		{
+			name:          "stops on a conflict",
+			currentBranch: "feature-b",
+			parent:        "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No Push after the conflict
+				mockGit.On("IsWorkingTreeClean").Return(true, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				testutil.AllowOpLog(mockGit)
+				mockGit.On("GetCommitHash", "feature-b").Return("old-b", nil)
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("fork", nil)
+				mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+				mockGit.On("RebaseOnto", "feature-a", "fork", "feature-b").Return(&git.ConflictError{Op: "rebase", Err: errors.New("conflict")})
+			},
+			expectError:   true,
+			errorContains: "failed to rebase feature-b",
+		},
+		{
+			name:          "fails for a branch outside a stack",
+			currentBranch: "main",
+			setupMocks:    func(mockGit *testutil.MockGitClient) {},
+			expectError:   true,
+			errorContains: "not in a stack",
+		},
+		{
+			name:          "fails with uncommitted changes",
+			currentBranch: "feature-b",
+			parent:        "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsWorkingTreeClean").Return(false, nil)
+			},
+			expectError:   true,
+			errorContains: "uncommitted changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return(tt.currentBranch, nil)
+			mockGit.On("GetConfig", "branch."+tt.currentBranch+".stackparent").Return(tt.parent)
+			tt.setupMocks(mockGit)
+
+			restackDescendants = tt.descendants
+
+			err := runRestack(mockGit, "")
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review <branch|pr-number>",
+	Short: i18n.T("review.short"),
+	Long:  i18n.T("review.long"),
+	Example: `  # Review a PR by branch name
+  stack review feature-auth
+
+  # Review a PR by number
+  stack review 123`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runReview(gitClient, githubClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runReview(gitClient git.GitClient, githubClient github.GitHubClient, target string) error {
+	pr, err := githubClient.GetPRForBranch(target)
+	if err != nil {
+		return fmt.Errorf("failed to look up PR: %w", err)
+	}
+	if pr == nil {
+		return fmt.Errorf("no PR found for %s", target)
+	}
+	head := pr.Head
+	if head == "" {
+		head = target
+	}
+
+	if err := spinner.WrapWithSuccess(
+		fmt.Sprintf("Fetching %s and %s...", head, pr.Base),
+		fmt.Sprintf("Fetched %s and %s", head, pr.Base),
+		func() error {
+			if err := gitClient.FetchBranch(head); err != nil {
+				return fmt.Errorf("could not fetch %s from origin (PR from a fork?): %w", head, err)
+			}
+			return gitClient.FetchBranch(pr.Base)
+		},
+	); err != nil {
+		return err
+	}
+
+	headRef := git.RemoteRef(head)
+	baseRef := git.RemoteRef(pr.Base)
+	newHead, err := gitClient.GetCommitHash(headRef)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", headRef, err)
+	}
+	newBase, err := gitClient.GetMergeBase(baseRef, newHead)
+	if err != nil {
+		return fmt.Errorf("failed to find merge base with %s: %w", baseRef, err)
+	}
+
+	repoRoot, err := gitClient.GetRepoRoot()
+	if err != nil {
+		return fmt.Errorf("failed to get repo root: %w", err)
+	}
+	if err := ensureWorktreesIgnored(repoRoot); err != nil {
+		return fmt.Errorf("failed to update .gitignore: %w", err)
+	}
+	worktreePath := filepath.Join(repoRoot, ".worktrees", "review", head)
+
+	// First review: compare against nothing, so every commit shows up as new.
+	// Re-review: compare the previously reviewed commits with the new ones.
+	oldBase, oldHead := newBase, newBase
+	if _, err := os.Stat(worktreePath); err == nil {
+		reviewedHead, err := gitClient.GetWorktreeHead(worktreePath)
+		if err != nil {
+			return fmt.Errorf("failed to read review worktree at %s: %w", worktreePath, err)
+		}
+		if reviewedHead == newHead {
+			fmt.Printf("No changes to PR #%d since the last review.\n", pr.Number)
+			fmt.Printf("\nTo switch to the review worktree, run:\n  %s\n", ui.Command(fmt.Sprintf("cd %s", worktreePath)))
+			return nil
+		}
+		oldHead = reviewedHead
+		if reviewedBase, err := gitClient.GetMergeBase(baseRef, reviewedHead); err == nil {
+			oldBase = reviewedBase
+		}
+		fmt.Printf("Updating review worktree for PR #%d to %s\n", pr.Number, ui.Branch(headRef))
+		if err := gitClient.CheckoutDetachedInWorktree(worktreePath, newHead); err != nil {
+			return fmt.Errorf("failed to update review worktree: %w", err)
+		}
+	} else {
+		fmt.Printf("Creating review worktree for PR #%d at %s\n", pr.Number, ui.Branch(headRef))
+		if err := gitClient.AddWorktreeDetached(worktreePath, newHead); err != nil {
+			return fmt.Errorf("failed to create review worktree: %w", err)
+		}
+	}
+
+	rangeDiff, err := gitClient.RangeDiff(oldBase, oldHead, newBase, newHead)
+	if err != nil {
+		return fmt.Errorf("failed to compute range-diff: %w", err)
+	}
+
+	fmt.Printf("\n%s %s\n", ui.Dim(fmt.Sprintf("PR #%d:", pr.Number)), pr.Title)
+	fmt.Printf("%s %s %s %s\n\n", ui.Dim("Range-diff against"), ui.Branch(pr.Base), ui.Dim("for"), ui.Branch(head))
+	if rangeDiff == "" {
+		fmt.Println(ui.Dim("(no commits)"))
+	} else {
+		fmt.Println(rangeDiff)
+	}
+
+	if !dryRun {
+		fmt.Printf("\nTo switch to the review worktree, run:\n  %s\n", ui.Command(fmt.Sprintf("cd %s", worktreePath)))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunReview(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	pr := &github.PRInfo{Number: 7, State: "OPEN", Base: "main", Head: "feature-a", Title: "Feature A"}
+
+	setupFetch := func(mockGit *testutil.MockGitClient, repoRoot string) {
+		mockGit.On("FetchBranch", "feature-a").Return(nil)
+		mockGit.On("FetchBranch", "main").Return(nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("new123", nil)
+		mockGit.On("GetMergeBase", "origin/main", "new123").Return("base1", nil)
+		mockGit.On("GetRepoRoot").Return(repoRoot, nil)
+	}
+
+	t.Run("first review creates worktree", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		mockGH.On("GetPRForBranch", "7").Return(pr, nil)
+		setupFetch(mockGit, repoRoot)
+		mockGit.On("AddWorktreeDetached", filepath.Join(repoRoot, ".worktrees", "review", "feature-a"), "new123").Return(nil)
+		mockGit.On("RangeDiff", "base1", "base1", "base1", "new123").Return("-:  ------- > 1:  new123 Add feature", nil)
+
+		err := runReview(mockGit, mockGH, "7")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("re-review compares against previously reviewed head", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		worktreePath := filepath.Join(repoRoot, ".worktrees", "review", "feature-a")
+		assert.NoError(t, os.MkdirAll(worktreePath, 0o755))
+
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		mockGH.On("GetPRForBranch", "feature-a").Return(pr, nil)
+		setupFetch(mockGit, repoRoot)
+		mockGit.On("GetWorktreeHead", worktreePath).Return("old456", nil)
+		mockGit.On("GetMergeBase", "origin/main", "old456").Return("base0", nil)
+		mockGit.On("CheckoutDetachedInWorktree", worktreePath, "new123").Return(nil)
+		mockGit.On("RangeDiff", "base0", "old456", "base1", "new123").Return("1:  old456 ! 1:  new123 Add feature", nil)
+
+		err := runReview(mockGit, mockGH, "feature-a")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("no changes since last review", func(t *testing.T) {
+		repoRoot := t.TempDir()
+		worktreePath := filepath.Join(repoRoot, ".worktrees", "review", "feature-a")
+		assert.NoError(t, os.MkdirAll(worktreePath, 0o755))
+
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		mockGH.On("GetPRForBranch", "feature-a").Return(pr, nil)
+		setupFetch(mockGit, repoRoot)
+		mockGit.On("GetWorktreeHead", worktreePath).Return("new123", nil)
+
+		err := runReview(mockGit, mockGH, "feature-a")
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "CheckoutDetachedInWorktree", worktreePath, "new123")
+	})
+
+	t.Run("no PR", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		mockGH.On("GetPRForBranch", "nope").Return(nil, nil)
+
+		err := runReview(mockGit, mockGH, "nope")
+
+		assert.ErrorContains(t, err, "no PR found for nope")
+	})
+}
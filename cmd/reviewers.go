@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// configRequestReviewers enables --request-reviewers for stack sync by default
+const configRequestReviewers = "stack.sync.requestReviewers"
+
+var (
+	reviewersRequest bool
+	// syncRequestReviewers requests CODEOWNERS reviewers on each PR during sync
+	syncRequestReviewers bool
+)
+
+var reviewersCmd = &cobra.Command{
+	Use:   "reviewers",
+	Short: i18n.T("reviewers.short"),
+	Long:  i18n.T("reviewers.long"),
+	Example: `  # Show suggested reviewers per branch
+  stack reviewers
+
+  # Request them on the PRs
+  stack reviewers --request`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if err := runReviewers(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	reviewersCmd.Flags().BoolVar(&reviewersRequest, "request", false, "Request the suggested reviewers on each branch's open PR")
+}
+
+func runReviewers(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	branches, err := stackBranchesFor(gitClient, currentBranch)
+	if err != nil {
+		return err
+	}
+	if len(branches) == 0 {
+		fmt.Printf("Branch '%s' is not in a stack.\n", ui.Branch(currentBranch))
+		return nil
+	}
+
+	baseBranch := stack.GetBaseBranch(gitClient)
+	owners, err := syncer.LoadCodeOwners(gitClient, git.RemoteRef(baseBranch))
+	if err != nil {
+		return err
+	}
+
+	var prCache map[string]*github.PRInfo
+	if reviewersRequest {
+		names := make([]string, 0, len(branches))
+		for _, b := range branches {
+			names = append(names, b.Name)
+		}
+		if prCache, err = syncer.LoadPRs(githubClient, names, prScope); err != nil {
+			return err
+		}
+	}
+
+	self := syncer.CurrentUser(githubClient)
+	for _, branch := range branches {
+		suggested, files, err := syncer.SuggestReviewers(gitClient, owners, branch, baseBranch, self)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to diff %s: %v\n", branch.Name, err)
+			continue
+		}
+
+		summary := ui.Dim("no owners")
+		if len(suggested) > 0 {
+			summary = strings.Join(suggested, ", ")
+		}
+		fmt.Printf("%s %s %s\n", ui.Branch(branch.Name), ui.Dim(fmt.Sprintf("(%d file(s))", files)), summary)
+
+		if reviewersRequest && len(suggested) > 0 {
+			if pr := prCache[branch.Name]; pr != nil && pr.State == "OPEN" {
+				syncer.RequestReviewers(githubClient, pr.Number, suggested)
+			} else {
+				fmt.Printf("  No open PR to request reviews on\n")
+			}
+		}
+	}
+
+	return nil
+}
+
+// stackBranchesFor returns the stack branches from the base up to branch, bottom first
+func stackBranchesFor(gitClient git.GitClient, branch string) ([]stack.StackBranch, error) {
+	chain, err := stack.GetStackChain(gitClient, branch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	chainSet := make(map[string]bool)
+	for _, b := range chain {
+		chainSet[b] = true
+	}
+
+	all, err := stack.GetStackBranches(gitClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	var branches []stack.StackBranch
+	for _, b := range all {
+		if chainSet[b.Name] {
+			branches = append(branches, b)
+		}
+	}
+	return stack.TopologicalSort(branches)
+}
@@ -1,29 +1,52 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/gitlab"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/script"
 	"github.com/javoire/stackinator/internal/spinner"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	dryRun  bool
-	verbose bool
-	noColor bool
+	dryRun    bool
+	verbose   bool
+	noColor   bool
+	assumeYes bool
+	chdir     string
+	asScript  bool
+	readOnly  bool
+
+	// scriptOut is the real stdout while --script sends everything else to stderr
+	scriptOut io.Writer = os.Stdout
 )
 
+// configLocale selects the language of user-facing messages for a repo
+const configLocale = "stack.locale"
+
+// envReadOnly enables read-only mode like --read-only, e.g. for CI and hooks
+const envReadOnly = "STACKINATOR_READONLY"
+
+// configAccessible replaces animated spinners with timestamped progress lines
+const configAccessible = "stack.accessible"
+
 var rootCmd = &cobra.Command{
 	Use:   "stack",
-	Short: "Manage stacked branches and sync them to GitHub PRs",
-	Long: `A CLI tool for managing stacks of branches and syncing them to GitHub Pull Requests.
-
-Stack branches are tracked using git config, where each branch stores its parent.
-The tool helps you create, navigate, and sync stacked branches with minimal overhead.`,
+	Short: i18n.T("root.short"),
+	Long:  i18n.T("root.long"),
 	Example: `  # Create a new feature branch
   stack new feature-auth
 
@@ -41,32 +64,160 @@ The tool helps you create, navigate, and sync stacked branches with minimal over
 
   # See detailed output
   stack sync --verbose`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// An interrupted sync is easy to forget, so point it out before the help
+		if state := readInterruptedSync(git.NewGitClient()); state != nil {
+			printInterruptedSync(state, time.Now())
+			fmt.Println()
+		}
+		_ = cmd.Help()
+	},
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		// Set global flags
 		git.DryRun = dryRun
 		git.Verbose = verbose
 		github.DryRun = dryRun
 		github.Verbose = verbose
+		gitlab.DryRun = dryRun
+		gitlab.Verbose = verbose
 
-		// Disable spinners in verbose mode to avoid visual conflicts
-		spinner.Enabled = !verbose
+		// Read-only mode turns every mutating git/gh call into an error
+		if readOnly || isTruthy(os.Getenv(envReadOnly)) {
+			git.ReadOnly = true
+			github.ReadOnly = true
+		}
+
+		// Disable spinners in verbose mode to avoid visual conflicts, and when
+		// output isn't a terminal (piped or redirected) to keep it clean
+		spinner.Enabled = !verbose && spinner.IsTerminal()
+		// Dumb terminals can't redraw a line, so fall back to plain progress lines
+		spinner.Accessible = os.Getenv("TERM") == "dumb"
 
 		// Set color output flag
 		ui.SetNoColor(noColor)
 
-		// Validate we're in a git repository
+		// With --script, stdout only gets the generated script so it can be
+		// redirected to a file; all other output moves to stderr
+		if asScript {
+			if !dryRun {
+				fmt.Fprintln(os.Stderr, i18n.T("error.scriptNeedsDryRun"))
+				os.Exit(1)
+			}
+			script.Enabled = true
+			scriptOut = os.Stdout
+			os.Stdout = os.Stderr
+			spinner.SetStdout(os.Stderr)
+			spinner.Enabled = false
+		}
+
+		// Run git and gh in another directory, like git -C
+		if chdir != "" {
+			dir, err := resolveWorkDir(chdir)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, i18n.T("error.chdir", chdir, err))
+				os.Exit(1)
+			}
+			git.WorkDir = dir
+			github.WorkDir = dir
+			gitlab.WorkDir = dir
+		}
+		if script.Enabled {
+			script.WorkDir = git.WorkDir
+			if script.WorkDir == "" {
+				script.WorkDir, _ = os.Getwd()
+			}
+		}
+
+		// Hooks and other tooling may point git elsewhere with GIT_DIR/GIT_WORK_TREE.
+		// Pin them to absolute paths so every git call agrees on the repository.
+		if err := absolutizeGitEnv(git.WorkDir); err != nil {
+			exitWithError(err)
+		}
+		if verbose && os.Getenv("GIT_DIR") != "" {
+			fmt.Fprintf(os.Stderr, "Using GIT_DIR=%s GIT_WORK_TREE=%s\n", os.Getenv("GIT_DIR"), os.Getenv("GIT_WORK_TREE"))
+		}
+
+		// Validate we're in a git repository with a working tree
 		gitClient := git.NewGitClient()
-		if _, err := gitClient.GetRepoRoot(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: not in a git repository\n")
+		if err := checkRepository(gitClient); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+
+		// Accessibility mode can also be enabled explicitly (per repo or globally)
+		if gitClient.GetConfig(configAccessible) == "true" {
+			spinner.Accessible = true
+		}
+
+		// Spinner frames, status glyphs and their colors, for fonts that lack the defaults
+		configureTheme(gitClient)
+
+		// A per-repo locale overrides the one detected from LANG/LC_ALL
+		if locale := gitClient.GetConfig(configLocale); locale != "" {
+			if !i18n.SetLocale(locale) && verbose {
+				fmt.Fprintf(os.Stderr, "Note: unsupported locale %q in %s, using %s\n", locale, configLocale, i18n.Locale())
+			}
+		}
+
+		// Fetch from and push to another remote than origin, e.g. in a fork
+		if err := configureRemote(gitClient); err != nil {
+			exitWithError(err)
+		}
+
+		// Push stack branches to a fork, with PRs opened against the remote above
+		if err := configurePushRemote(gitClient); err != nil {
+			exitWithError(err)
+		}
+
+		// Pick the GitHub host and account, for machines with several accounts
+		configureGitHubAccount(gitClient)
+
+		// GitHub or GitLab, when the origin URL doesn't tell
+		configureForge(gitClient)
+
+		// PRs target the base branch rather than their parent
+		configureFlatPRs(gitClient)
+
+		// Keep submodules on the commits recorded by each branch checked out
+		configureSubmodules(gitClient)
+
+		// Files that are always dirty shouldn't make sync stash
+		configureCleanIgnore(gitClient)
+
+		// Work from local refs and cached PRs without a network, e.g. on a train
+		configureOffline(gitClient)
+
+		// Point out parents merged since the last sync, e.g. through the web UI
+		if !mergeCheckSkipped[cmd.Name()] && !script.Enabled {
+			notifyMergedParents(gitClient, func() github.GitHubClient {
+				return forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+			}, time.Now())
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if dryRun {
+			fmt.Println()
+			fmt.Println(dryRunSummary())
+		}
+		if !script.Enabled {
+			return
+		}
+		title := fmt.Sprintf("Generated by '%s --dry-run --script'", cmd.CommandPath())
+		if err := script.Write(scriptOut, title); err != nil {
+			exitWithError(err)
+		}
 	},
 }
 
 func init() {
-	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Show what would happen without executing")
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Show detailed output")
-	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, i18n.T("flag.dryRun"))
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, i18n.T("flag.verbose"))
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, i18n.T("flag.noColor"))
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, i18n.T("flag.yes"))
+	rootCmd.PersistentFlags().StringVarP(&chdir, "chdir", "C", "", i18n.T("flag.chdir"))
+	rootCmd.PersistentFlags().BoolVar(&asScript, "script", false, i18n.T("flag.script"))
+	rootCmd.PersistentFlags().BoolVar(&readOnly, "read-only", false, i18n.T("flag.readOnly"))
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, i18n.T("flag.offline"))
 
 	// Add subcommands
 	rootCmd.AddCommand(newCmd)
@@ -80,6 +231,133 @@ func init() {
 	rootCmd.AddCommand(worktreeCmd)
 	rootCmd.AddCommand(upCmd)
 	rootCmd.AddCommand(downCmd)
+	rootCmd.AddCommand(goCmd)
+	rootCmd.AddCommand(prsCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(rangeDiffCmd)
+	rootCmd.AddCommand(baseCmd)
+	rootCmd.AddCommand(cleanConfigCmd)
+	rootCmd.AddCommand(reviewersCmd)
+	rootCmd.AddCommand(blameCmd)
+	rootCmd.AddCommand(rebaseCmd)
+	rootCmd.AddCommand(fixupCmd)
+	rootCmd.AddCommand(absorbCmd)
+	rootCmd.AddCommand(amendCmd)
+	rootCmd.AddCommand(pickCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(upliftCmd)
+	rootCmd.AddCommand(nameCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(switchCmd)
+	rootCmd.AddCommand(checkoutCmd)
+	rootCmd.AddCommand(describeCmd)
+	rootCmd.AddCommand(reorderCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(foldCmd)
+	rootCmd.AddCommand(formatPatchCmd)
+	rootCmd.AddCommand(amCmd)
+	rootCmd.AddCommand(envCmd)
+	rootCmd.AddCommand(submitCmd)
+	rootCmd.AddCommand(landCmd)
+	rootCmd.AddCommand(adoptCmd)
+	rootCmd.AddCommand(shareCmd)
+	rootCmd.AddCommand(fetchMetadataCmd)
+	rootCmd.AddCommand(conflictsCmd)
+	rootCmd.AddCommand(uiCmd)
+	rootCmd.AddCommand(recoverCmd)
+	rootCmd.AddCommand(restackCmd)
+	rootCmd.AddCommand(sizeGuardCmd)
+	rootCmd.AddCommand(undoCmd)
+	rootCmd.AddCommand(changelogCmd)
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// dryRunSummary counts the changes a dry run skipped, by kind
+func dryRunSummary() string {
+	kinds := []struct {
+		kind dryrun.Kind
+		key  string
+	}{
+		{dryrun.Push, "dryRun.pushes"},
+		{dryrun.Delete, "dryRun.deletions"},
+		{dryrun.Config, "dryRun.config"},
+		{dryrun.PREdit, "dryRun.prEdits"},
+		{dryrun.Other, "dryRun.other"},
+	}
+	var parts []string
+	for _, k := range kinds {
+		if n := dryrun.Count(k.kind); n > 0 {
+			parts = append(parts, i18n.T(k.key, n))
+		}
+	}
+	if len(parts) == 0 {
+		return i18n.T("dryRun.nothing")
+	}
+	return i18n.T("dryRun.summary", strings.Join(parts, ", "))
+}
+
+// isTruthy reports whether an environment variable value turns a setting on
+func isTruthy(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	}
+	return false
+}
+
+// resolveWorkDir makes path absolute and checks that it is an existing directory
+func resolveWorkDir(path string) (string, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("not a directory")
+	}
+	return dir, nil
+}
+
+// absolutizeGitEnv rewrites relative GIT_DIR and GIT_WORK_TREE values as absolute
+// paths. Git resolves them against its working directory, which would otherwise
+// change their meaning when combined with -C.
+func absolutizeGitEnv(workDir string) error {
+	base := workDir
+	if base == "" {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		base = cwd
+	}
+	for _, name := range []string{"GIT_DIR", "GIT_WORK_TREE"} {
+		value := os.Getenv(name)
+		if value == "" || filepath.IsAbs(value) {
+			continue
+		}
+		if err := os.Setenv(name, filepath.Join(base, value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkRepository verifies we're in a git repository that has a working tree.
+// Bare repositories are rejected up front: stack needs to check out and rebase
+// branches, which would otherwise fail deep inside sync.
+func checkRepository(gitClient git.GitClient) error {
+	if gitClient.IsBareRepo() {
+		return errors.New(i18n.T("error.bareRepo"))
+	}
+	if _, err := gitClient.GetRepoRoot(); err != nil {
+		return errors.New(i18n.T("error.notInRepo"))
+	}
+	return nil
 }
 
 // Execute runs the root command
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRepository(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name        string
+		setupMocks  func(*testutil.MockGitClient)
+		expectError string
+	}{
+		{
+			name: "repository with working tree",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsBareRepo").Return(false)
+				mockGit.On("GetRepoRoot").Return("/repo", nil)
+			},
+		},
+		{
+			name: "bare repository",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsBareRepo").Return(true)
+			},
+			expectError: "bare repository",
+		},
+		{
+			name: "not a repository",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("IsBareRepo").Return(false)
+				mockGit.On("GetRepoRoot").Return("", errors.New("not a git repository"))
+			},
+			expectError: "not in a git repository",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			tt.setupMocks(mockGit)
+
+			err := checkRepository(mockGit)
+
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAbsolutizeGitEnv(t *testing.T) {
+	base := t.TempDir()
+	abs := filepath.Join(base, "elsewhere")
+
+	t.Setenv("GIT_DIR", ".git")
+	t.Setenv("GIT_WORK_TREE", abs)
+
+	assert.NoError(t, absolutizeGitEnv(base))
+	assert.Equal(t, filepath.Join(base, ".git"), os.Getenv("GIT_DIR"))
+	assert.Equal(t, abs, os.Getenv("GIT_WORK_TREE"))
+}
+
+func TestDryRunSummary(t *testing.T) {
+	locale := i18n.Locale()
+	i18n.SetLocale("en")
+	dryrun.Reset()
+	defer func() {
+		i18n.SetLocale(locale)
+		dryrun.Reset()
+	}()
+
+	assert.Equal(t, "Dry run: nothing would change", dryRunSummary())
+
+	dryrun.Record(dryrun.Push)
+	dryrun.Record(dryrun.Push)
+	dryrun.Record(dryrun.PREdit)
+	dryrun.Record(dryrun.Other)
+	assert.Equal(t, "Dry run: skipped 2 push(es), 1 PR edit(s), 1 other change(s)", dryRunSummary())
+}
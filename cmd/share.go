@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// stackMetadataRef is where 'stack share' publishes stack parents on origin.
+	// It points at a commit holding stackMetadataFile, and every share adds a
+	// commit on top of the previous one.
+	stackMetadataRef  = "refs/stack/metadata"
+	stackMetadataFile = "stack.json"
+)
+
+// stackMetadata is the content of stackMetadataFile
+type stackMetadata struct {
+	Parents map[string]string `json:"parents"`
+}
+
+var shareCmd = &cobra.Command{
+	Use:   "share",
+	Short: i18n.T("share.short"),
+	Long:  i18n.T("share.long", stackMetadataRef),
+	Example: `  # Publish your stacks
+  stack share
+
+  # On a teammate's clone
+  git checkout feature-b
+  stack fetch-metadata
+  stack status`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runShare(git.NewGitClient()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var fetchMetadataCmd = &cobra.Command{
+	Use:   "fetch-metadata",
+	Short: i18n.T("fetchMetadata.short"),
+	Long:  i18n.T("fetchMetadata.long"),
+	Example: `  # Pick up the stacks of branches you checked out from a teammate
+  stack fetch-metadata`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := runFetchMetadata(git.NewGitClient()); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+// readSharedMetadata returns the stack metadata last fetched from origin and the
+// commit holding it, or empty metadata and "" when nothing was shared yet
+func readSharedMetadata(gitClient git.GitClient) (stackMetadata, string, error) {
+	metadata := stackMetadata{Parents: make(map[string]string)}
+	head, err := gitClient.GetCommitHash(stackMetadataRef)
+	if err != nil || head == "" {
+		return metadata, "", nil
+	}
+	content, err := gitClient.ShowFile(stackMetadataRef, stackMetadataFile)
+	if err != nil {
+		return metadata, "", fmt.Errorf("failed to read %s: %w", stackMetadataRef, err)
+	}
+	if err := json.Unmarshal([]byte(content), &metadata); err != nil {
+		return metadata, "", fmt.Errorf("failed to parse %s: %w", stackMetadataRef, err)
+	}
+	if metadata.Parents == nil {
+		metadata.Parents = make(map[string]string)
+	}
+	return metadata, head, nil
+}
+
+func runShare(gitClient git.GitClient) error {
+	if err := gitClient.FetchRef(stackMetadataRef); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", stackMetadataRef, err)
+	}
+	shared, head, err := readSharedMetadata(gitClient)
+	if err != nil {
+		return err
+	}
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	remoteBranches := gitClient.GetRemoteBranchesSet()
+
+	// Keep what others shared, as long as the branch is still on origin
+	updated := stackMetadata{Parents: make(map[string]string)}
+	for branch, parent := range shared.Parents {
+		if remoteBranches[branch] {
+			updated.Parents[branch] = parent
+		}
+	}
+	mine := 0
+	for branch, parent := range parents {
+		if remoteBranches[branch] {
+			updated.Parents[branch] = parent
+			mine++
+		}
+	}
+
+	if mine == 0 {
+		fmt.Printf("None of your stack branches are on origin yet, push them with '%s' first.\n", ui.Command("stack submit"))
+		return nil
+	}
+	if head != "" && maps.Equal(updated.Parents, shared.Parents) {
+		fmt.Println(ui.Success("The stacks shared on origin are up to date"))
+		return nil
+	}
+
+	content, err := json.MarshalIndent(updated, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode stack metadata: %w", err)
+	}
+	commit, err := gitClient.CommitFile(head, stackMetadataFile, string(content)+"\n", "Update stack metadata")
+	if err != nil {
+		return fmt.Errorf("failed to write stack metadata: %w", err)
+	}
+	if err := gitClient.UpdateRef(stackMetadataRef, commit); err != nil {
+		return fmt.Errorf("failed to update %s: %w", stackMetadataRef, err)
+	}
+	if err := gitClient.PushRef(stackMetadataRef); err != nil {
+		return fmt.Errorf("%s: %w", i18n.T("share.pushFailed", stackMetadataRef, ui.Command("stack share")), err)
+	}
+
+	if dryRun {
+		fmt.Println("Dry run, nothing was shared")
+		return nil
+	}
+	fmt.Println(ui.Success(fmt.Sprintf("Shared the parents of %d branch(es) on origin", mine)))
+	fmt.Println(i18n.T("share.fetchHint", ui.Command("stack fetch-metadata")))
+	return nil
+}
+
+func runFetchMetadata(gitClient git.GitClient) error {
+	if err := gitClient.FetchRef(stackMetadataRef); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", stackMetadataRef, err)
+	}
+	shared, head, err := readSharedMetadata(gitClient)
+	if err != nil {
+		return err
+	}
+	if head == "" {
+		fmt.Printf("No stacks are shared on origin yet, publish yours with '%s'.\n", ui.Command("stack share"))
+		return nil
+	}
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	branches := make([]string, 0, len(shared.Parents))
+	for branch := range shared.Parents {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	tracked, missing := 0, 0
+	for _, branch := range branches {
+		parent := shared.Parents[branch]
+		if !gitClient.BranchExists(branch) {
+			missing++
+			continue
+		}
+		switch current := parents[branch]; current {
+		case parent:
+		case "":
+			configKey := fmt.Sprintf("branch.%s.stackparent", branch)
+			if err := gitClient.SetConfig(configKey, parent); err != nil {
+				return fmt.Errorf("failed to set parent of %s: %w", branch, err)
+			}
+			fmt.Printf("  %s %s on %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.Branch(parent))
+			tracked++
+		default:
+			fmt.Fprintf(os.Stderr, "  %s %s keeps its parent %s (shared: %s)\n", ui.WarningIcon(), ui.Branch(branch), ui.Branch(current), ui.Branch(parent))
+		}
+	}
+
+	if tracked > 0 {
+		fmt.Println(ui.Success(fmt.Sprintf("Tracked %d branch(es) from the shared stacks", tracked)))
+	} else {
+		fmt.Println("No new branches to track.")
+	}
+	if missing > 0 {
+		fmt.Println(i18n.T("fetchMetadata.missing", missing, ui.Command("stack fetch-metadata")))
+	}
+	return nil
+}
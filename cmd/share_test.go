@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunShare(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("merges with what others shared and pushes on top of it", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchRef", "refs/stack/metadata").Return(nil)
+		mockGit.On("GetCommitHash", "refs/stack/metadata").Return("meta1", nil)
+		mockGit.On("ShowFile", "refs/stack/metadata", "stack.json").Return(`{"parents":{"bob-a":"main","gone":"main","feature-a":"develop"}}`, nil)
+		mockGit.On("GetAllStackParents").Return(map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+			"local":     "main",
+		}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "bob-a": true, "feature-a": true, "feature-b": true})
+		expected := "{\n  \"parents\": {\n    \"bob-a\": \"main\",\n    \"feature-a\": \"main\",\n    \"feature-b\": \"feature-a\"\n  }\n}\n"
+		mockGit.On("CommitFile", "meta1", "stack.json", expected, "Update stack metadata").Return("meta2", nil)
+		mockGit.On("UpdateRef", "refs/stack/metadata", "meta2").Return(nil)
+		mockGit.On("PushRef", "refs/stack/metadata").Return(nil)
+
+		err := runShare(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("first share", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchRef", "refs/stack/metadata").Return(nil)
+		mockGit.On("GetCommitHash", "refs/stack/metadata").Return("", errors.New("unknown revision"))
+		mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true})
+		mockGit.On("CommitFile", "", "stack.json", mock.Anything, mock.Anything).Return("meta1", nil)
+		mockGit.On("UpdateRef", "refs/stack/metadata", "meta1").Return(nil)
+		mockGit.On("PushRef", "refs/stack/metadata").Return(nil)
+
+		err := runShare(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("nothing to push when up to date", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchRef", "refs/stack/metadata").Return(nil)
+		mockGit.On("GetCommitHash", "refs/stack/metadata").Return("meta1", nil)
+		mockGit.On("ShowFile", "refs/stack/metadata", "stack.json").Return(`{"parents":{"feature-a":"main"}}`, nil)
+		mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true})
+
+		err := runShare(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "PushRef", mock.Anything)
+	})
+}
+
+func TestRunFetchMetadata(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("tracks local branches without a parent", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchRef", "refs/stack/metadata").Return(nil)
+		mockGit.On("GetCommitHash", "refs/stack/metadata").Return("meta1", nil)
+		mockGit.On("ShowFile", "refs/stack/metadata", "stack.json").Return(`{"parents":{"feature-a":"main","feature-b":"feature-a","feature-c":"feature-b","other":"main"}}`, nil)
+		mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-c": "main"}, nil)
+		mockGit.On("BranchExists", "feature-a").Return(true)
+		mockGit.On("BranchExists", "feature-b").Return(true)
+		mockGit.On("BranchExists", "feature-c").Return(true)
+		mockGit.On("BranchExists", "other").Return(false)
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+
+		err := runFetchMetadata(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "SetConfig", "branch.feature-c.stackparent", mock.Anything)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("nothing shared yet", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchRef", "refs/stack/metadata").Return(nil)
+		mockGit.On("GetCommitHash", "refs/stack/metadata").Return("", errors.New("unknown revision"))
+
+		err := runFetchMetadata(mockGit)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+}
@@ -2,24 +2,28 @@ package cmd
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// showJSON prints the stack as JSON instead of a tree
+var showJSON bool
+
 var showCmd = &cobra.Command{
 	Use:   "show",
-	Short: "Show the local stack structure (fast)",
-	Long: `Display the local stack structure as a tree without fetching remote PR info.
-
-This is a fast version of 'stack status' that only reads local git config.
-Use 'stack status' to see PR information and sync issues.`,
+	Short: i18n.T("show.short"),
+	Long:  i18n.T("show.long"),
 	Example: `  # Show local stack structure
   stack show
 
+  # Print the stack as JSON for scripts
+  stack show --json
+
   # Example output:
   #  main
   #   |
@@ -28,14 +32,20 @@ Use 'stack status' to see PR information and sync issues.`,
   #  feature-auth-tests *`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
+		if showJSON {
+			ui.SetNoColor(true)
+		}
 
 		if err := runShow(gitClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
 
+func init() {
+	showCmd.Flags().BoolVar(&showJSON, "json", false, "Print the stack as JSON")
+}
+
 func runShow(gitClient git.GitClient) error {
 	currentBranch, err := gitClient.GetCurrentBranch()
 	if err != nil {
@@ -49,9 +59,12 @@ func runShow(gitClient git.GitClient) error {
 	}
 
 	if len(stackBranches) == 0 {
-		fmt.Println("No stack branches found.")
-		fmt.Printf("Current branch: %s\n", ui.Branch(currentBranch))
-		fmt.Printf("\nUse '%s' to create a new stack branch.\n", ui.Command("stack new <branch-name>"))
+		if showJSON {
+			return printJSON(newStackJSON(gitClient, nil, currentBranch, nil, nil, nil))
+		}
+		fmt.Println(i18n.T("stack.noBranches"))
+		fmt.Println(i18n.T("stack.currentBranch", ui.Branch(currentBranch)))
+		fmt.Printf("\n%s\n", i18n.T("stack.createHint", ui.Command("stack new <branch-name>")))
 		return nil
 	}
 
@@ -61,15 +74,32 @@ func runShow(gitClient git.GitClient) error {
 		return fmt.Errorf("failed to build stack tree: %w", err)
 	}
 
+	// The current branch isn't part of any stack
+	if tree == nil {
+		if showJSON {
+			return printJSON(newStackJSON(gitClient, nil, currentBranch, nil, nil, nil))
+		}
+		added, err := syncer.OfferToAddToStack(gitClient, stdinReader, currentBranch, stack.GetBaseBranch(gitClient))
+		if err != nil || !added {
+			return err
+		}
+		return runShow(gitClient)
+	}
+
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	if showJSON {
+		return printJSON(newStackJSON(gitClient, tree, currentBranch, nil, umbrellas, nil))
+	}
+
 	// Print the tree
 	fmt.Println()
-	printLocalStackTree(tree, currentBranch, false)
+	printLocalStackTree(tree, currentBranch, umbrellas, false)
 
 	return nil
 }
 
 // printLocalStackTree prints the stack tree without PR info (local-only, fast)
-func printLocalStackTree(node *stack.TreeNode, currentBranch string, isPipe bool) {
+func printLocalStackTree(node *stack.TreeNode, currentBranch string, umbrellas map[string]bool, isPipe bool) {
 	if node == nil {
 		return
 	}
@@ -85,10 +115,10 @@ func printLocalStackTree(node *stack.TreeNode, currentBranch string, isPipe bool
 	}
 
 	// Print current node (no PR info)
-	fmt.Printf(" %s%s\n", ui.Branch(node.Name), marker)
+	fmt.Printf(" %s%s%s\n", ui.Branch(node.Name), syncer.UmbrellaLabel(umbrellas, node.Name), marker)
 
 	// Print children vertically
 	for _, child := range node.Children {
-		printLocalStackTree(child, currentBranch, true)
+		printLocalStackTree(child, currentBranch, umbrellas, true)
 	}
 }
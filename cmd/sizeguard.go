@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Git config keys for the review size limits of a single branch. A limit of 0 (the
+// default) is no limit.
+const (
+	configSizeGuardMaxFiles = "stack.sizeGuard.maxFiles"
+	configSizeGuardMaxLines = "stack.sizeGuard.maxLines"
+	// configSizeGuardMode is "warn" (the default) to report oversized branches, or
+	// "block" to stop sync and submit before anything is pushed
+	configSizeGuardMode = "stack.sizeGuard.mode"
+)
+
+var (
+	// sizeGuardLimits are the limits sync and submit check, zero when off
+	sizeGuardLimits syncer.SizeLimits
+	// noSizeGuard skips the size check for one sync or submit
+	noSizeGuard bool
+	// sizeGuardMaxFiles and sizeGuardMaxLines override the configured limits
+	sizeGuardMaxFiles int
+	sizeGuardMaxLines int
+)
+
+// readSizeLimits reads the stack.sizeGuard settings
+func readSizeLimits(gitClient git.GitClient) (syncer.SizeLimits, error) {
+	var limits syncer.SizeLimits
+	var err error
+	if limits.MaxFiles, err = readSizeLimit(gitClient, configSizeGuardMaxFiles); err != nil {
+		return syncer.SizeLimits{}, err
+	}
+	if limits.MaxLines, err = readSizeLimit(gitClient, configSizeGuardMaxLines); err != nil {
+		return syncer.SizeLimits{}, err
+	}
+
+	switch mode := gitClient.GetConfig(configSizeGuardMode); mode {
+	case "", "warn":
+	case "block":
+		limits.Block = true
+	default:
+		return syncer.SizeLimits{}, fmt.Errorf("invalid %s %q (use warn or block)", configSizeGuardMode, mode)
+	}
+	return limits, nil
+}
+
+// readSizeLimit reads one limit, 0 when it isn't set
+func readSizeLimit(gitClient git.GitClient, key string) (int, error) {
+	value := gitClient.GetConfig(key)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q (use a number, 0 for no limit)", key, value)
+	}
+	return n, nil
+}
+
+var sizeGuardCmd = &cobra.Command{
+	Use:   "size-guard",
+	Short: i18n.T("sizeGuard.short"),
+	Long:  i18n.T("sizeGuard.long"),
+	Example: `  # Check the stack against the configured limits
+  stack size-guard
+
+  # Limit each branch to 400 changed lines and 20 files
+  git config stack.sizeGuard.maxLines 400
+  git config stack.sizeGuard.maxFiles 20
+
+  # Try other limits without configuring them
+  stack size-guard --max-lines 250`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		limits, err := readSizeLimits(gitClient)
+		if err != nil {
+			exitWithError(err)
+		}
+		if cmd.Flags().Changed("max-files") {
+			limits.MaxFiles = sizeGuardMaxFiles
+		}
+		if cmd.Flags().Changed("max-lines") {
+			limits.MaxLines = sizeGuardMaxLines
+		}
+		if err := runSizeGuard(gitClient, limits); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	sizeGuardCmd.Flags().IntVar(&sizeGuardMaxFiles, "max-files", 0, "Most files a branch may change (0 for no limit)")
+	sizeGuardCmd.Flags().IntVar(&sizeGuardMaxLines, "max-lines", 0, "Most lines a branch may add and delete (0 for no limit)")
+}
+
+func runSizeGuard(gitClient git.GitClient, limits syncer.SizeLimits) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return &stack.NotInStackError{Branch: currentBranch}
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	var branches []stack.StackBranch
+	for _, name := range chain[1:] {
+		branches = append(branches, stack.StackBranch{Name: name, Parent: parents[name]})
+	}
+
+	oversized := 0
+	for _, size := range syncer.MeasureLayers(gitClient, branches, nil, umbrellas, chain[0]) {
+		icon := ui.SuccessIcon()
+		if limits.Enabled() && size.Exceeds(limits) {
+			icon = ui.WarningIcon()
+			oversized++
+		}
+		fmt.Printf("%s %s  %s\n", icon, ui.Branch(size.Branch), ui.Dim(syncer.DescribeLayerSize(size)))
+	}
+	fmt.Println()
+
+	switch {
+	case !limits.Enabled():
+		fmt.Printf("No size limits set; set them with '%s' or '%s'\n",
+			ui.Command("git config "+configSizeGuardMaxLines+" <lines>"), ui.Command("git config "+configSizeGuardMaxFiles+" <files>"))
+		return nil
+	case oversized == 0:
+		fmt.Println(ui.Success(fmt.Sprintf("Every branch is within the limits (%s)", limits)))
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%d branch(es) over the limits (%s); consider splitting them with '%s'\n", oversized, limits, ui.Command("stack split"))
+	return ui.Reported(errors.New("branches over the review size limit"))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadSizeLimits(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", configSizeGuardMaxFiles).Return("20").Once()
+	mockGit.On("GetConfig", configSizeGuardMaxLines).Return("").Once()
+	mockGit.On("GetConfig", configSizeGuardMode).Return("block").Once()
+
+	limits, err := readSizeLimits(mockGit)
+	assert.NoError(t, err)
+	assert.Equal(t, syncer.SizeLimits{MaxFiles: 20, Block: true}, limits)
+	assert.Equal(t, "20 files", limits.String())
+
+	mockGit.On("GetConfig", configSizeGuardMaxFiles).Return("lots").Once()
+	_, err = readSizeLimits(mockGit)
+	assert.ErrorContains(t, err, configSizeGuardMaxFiles)
+}
+
+func TestRunSizeGuard(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name        string
+		limits      syncer.SizeLimits
+		expectError bool
+	}{
+		{
+			name:        "fails when a branch is over the limits",
+			limits:      syncer.SizeLimits{MaxFiles: 2},
+			expectError: true,
+		},
+		{
+			name:   "passes when every branch is within the limits",
+			limits: syncer.SizeLimits{MaxFiles: 3, MaxLines: 50},
+		},
+		{
+			name:   "only reports sizes without limits",
+			limits: syncer.SizeLimits{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+			mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+			mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+			mockGit.On("GetDiffStat", "origin/main", "feature-a").Return([]git.FileStat{
+				{Path: "auth/login.go", Added: 40, Deleted: 2},
+			}, nil)
+			mockGit.On("GetDiffStat", "feature-a", "feature-b").Return([]git.FileStat{
+				{Path: "auth/logout.go", Added: 30},
+				{Path: "auth/session.go", Added: 10, Deleted: 5},
+				{Path: "auth/token.go", Added: 1},
+			}, nil)
+
+			err := runSizeGuard(mockGit, tt.limits)
+
+			if tt.expectError {
+				assert.True(t, isAlreadyReported(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
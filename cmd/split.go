@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// splitAt is the --at flag of split, "<commit>:<branch>" once per new branch
+var splitAt []string
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: i18n.T("split.short"),
+	Long:  i18n.T("split.long"),
+	Example: `  # Pick the split points and names interactively
+  stack split
+
+  # Commits 1-2 become feature-models, 3-4 feature-api, the rest stays
+  stack split --at HEAD~3:feature-models --at HEAD~1:feature-api`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runSplit(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	splitCmd.Flags().StringArrayVar(&splitAt, "at", nil, "Create a branch ending at a commit, as <commit>:<branch> (repeatable)")
+}
+
+// splitPart is a new branch of a split, ending at one of the branch's commits
+type splitPart struct {
+	Branch string
+	End    int // Index of its last commit, oldest first
+}
+
+func runSplit(gitClient git.GitClient) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", currentBranch))
+	if parent == "" {
+		return fmt.Errorf("current branch %s is not part of a stack (no stackparent configured)", currentBranch)
+	}
+
+	commits, err := commitsToPick(gitClient, currentBranch)
+	if err != nil {
+		return err
+	}
+	if len(commits) < 2 {
+		return fmt.Errorf("%s has %d commit(s) of its own, there is nothing to split", currentBranch, len(commits))
+	}
+
+	var parts []splitPart
+	if len(splitAt) > 0 {
+		parts, err = parseSplitFlags(gitClient, splitAt, commits)
+	} else {
+		parts, err = promptSplit(gitClient, currentBranch, commits)
+	}
+	if err != nil {
+		return err
+	}
+	if err := validateSplit(gitClient, parts, commits); err != nil {
+		return err
+	}
+
+	// Each part is stacked on the one before it, the first on the branch's parent
+	below := parent
+	for _, part := range parts {
+		fmt.Printf("Creating %s at %s\n", ui.Branch(part.Branch), ui.ShortSHA(commits[part.End]))
+		if err := gitClient.CreateBranch(part.Branch, commits[part.End]); err != nil {
+			return fmt.Errorf("failed to create branch %s: %w", part.Branch, err)
+		}
+		if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", part.Branch), below); err != nil {
+			return fmt.Errorf("failed to set parent of %s: %w", part.Branch, err)
+		}
+		below = part.Branch
+	}
+	if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", currentBranch), below); err != nil {
+		return fmt.Errorf("failed to set parent of %s: %w", currentBranch, err)
+	}
+
+	if !dryRun {
+		fmt.Println(ui.Success(fmt.Sprintf("Split %s into %d branches", ui.Branch(currentBranch), len(parts)+1)))
+		fmt.Println()
+		if err := showStack(gitClient); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to display stack: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// parseSplitFlags reads the --at values, finding each commit among the branch's
+func parseSplitFlags(gitClient git.GitClient, values []string, commits []string) ([]splitPart, error) {
+	var parts []splitPart
+	for _, value := range values {
+		ref, branch, ok := strings.Cut(value, ":")
+		if !ok || ref == "" || branch == "" {
+			return nil, fmt.Errorf("invalid --at %q, expected <commit>:<branch>", value)
+		}
+		sha, err := gitClient.GetCommitHash(ref)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a commit", ref)
+		}
+		end := -1
+		for i, commit := range commits {
+			if commit == sha {
+				end = i
+			}
+		}
+		if end == -1 {
+			return nil, fmt.Errorf("commit %s is not one of the branch's own commits", ui.ShortSHA(sha))
+		}
+		parts = append(parts, splitPart{Branch: branch, End: end})
+	}
+	return parts, nil
+}
+
+// promptSplit lists the commits and asks where to split and what to call the
+// new branches, suggesting <branch>-1, <branch>-2, ...
+func promptSplit(gitClient git.GitClient, branch string, commits []string) ([]splitPart, error) {
+	fmt.Printf("Commits of %s, oldest first:\n", ui.Branch(branch))
+	for i, commit := range commits {
+		message, _ := gitClient.GetCommitMessage(commit)
+		subject, _, _ := strings.Cut(message, "\n")
+		fmt.Printf("  %d) %s %s\n", i+1, ui.ShortSHA(commit), subject)
+	}
+
+	reader := bufio.NewReader(stdinReader)
+	fmt.Printf("\nSplit after commits (e.g. 1,3): ")
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	var parts []splitPart
+	for _, field := range strings.Split(strings.TrimSpace(input), ",") {
+		field = strings.TrimSpace(field)
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 1 || n > len(commits) {
+			return nil, fmt.Errorf("invalid selection: %s", field)
+		}
+		parts = append(parts, splitPart{End: n - 1})
+	}
+
+	start := 1
+	for i := range parts {
+		suggested := fmt.Sprintf("%s-%d", branch, i+1)
+		fmt.Printf("Name for commits %d-%d [%s]: ", start, parts[i].End+1, suggested)
+		name, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		if parts[i].Branch = strings.TrimSpace(name); parts[i].Branch == "" {
+			parts[i].Branch = suggested
+		}
+		start = parts[i].End + 2
+	}
+	return parts, nil
+}
+
+// validateSplit checks that the parts are in commit order, leave the branch at
+// least one commit and are named after branches that don't exist yet
+func validateSplit(gitClient git.GitClient, parts []splitPart, commits []string) error {
+	names := make(map[string]bool)
+	for i, part := range parts {
+		if i > 0 && part.End <= parts[i-1].End {
+			return fmt.Errorf("split points must be distinct and oldest first")
+		}
+		if part.End == len(commits)-1 {
+			return fmt.Errorf("cannot split after the last commit, the branch would be left empty")
+		}
+		if err := validateBranchName(gitClient, part.Branch); err != nil {
+			return err
+		}
+		if names[part.Branch] || gitClient.BranchExists(part.Branch) {
+			return fmt.Errorf("branch %s already exists", part.Branch)
+		}
+		names[part.Branch] = true
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSplit(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	// Set dryRun to true to skip the display logic at the end
+	dryRun = true
+	defer func() { dryRun = false }()
+
+	commits := []string{"sha-1", "sha-2", "sha-3", "sha-4"}
+
+	tests := []struct {
+		name          string
+		splitAt       []string
+		input         string
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:    "stacks the new branches below the current one",
+			splitAt: []string{"HEAD~3:feature-models", "HEAD~1:feature-api"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitHash", "HEAD~3").Return("sha-1", nil)
+				mockGit.On("GetCommitHash", "HEAD~1").Return("sha-3", nil)
+				for _, name := range []string{"feature-models", "feature-api"} {
+					mockGit.On("CheckBranchName", name).Return(nil)
+					mockGit.On("BranchExists", name).Return(false)
+				}
+				mockGit.On("CreateBranch", "feature-models", "sha-1").Return(nil)
+				mockGit.On("CreateBranch", "feature-api", "sha-3").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-models.stackparent", "feature-base").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-api.stackparent", "feature-models").Return(nil)
+				mockGit.On("SetConfig", "branch.feature.stackparent", "feature-api").Return(nil)
+			},
+		},
+		{
+			name:  "prompts for split points and names",
+			input: "2\n\n",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				for _, commit := range commits {
+					mockGit.On("GetCommitMessage", commit).Return("Commit "+commit+"\n\nBody", nil)
+				}
+				mockGit.On("CheckBranchName", "feature-1").Return(nil)
+				mockGit.On("BranchExists", "feature-1").Return(false)
+				mockGit.On("CreateBranch", "feature-1", "sha-2").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-1.stackparent", "feature-base").Return(nil)
+				mockGit.On("SetConfig", "branch.feature.stackparent", "feature-1").Return(nil)
+			},
+		},
+		{
+			name:    "refuses to leave the branch empty",
+			splitAt: []string{"HEAD:feature-all"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CreateBranch: the last commit can't start a new branch
+				mockGit.On("GetCommitHash", "HEAD").Return("sha-4", nil)
+			},
+			expectError:   true,
+			errorContains: "left empty",
+		},
+		{
+			name:    "refuses commits of other branches",
+			splitAt: []string{"main:feature-main"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitHash", "main").Return("sha-main", nil)
+			},
+			expectError:   true,
+			errorContains: "not one of the branch's own commits",
+		},
+		{
+			name:    "refuses split points out of order",
+			splitAt: []string{"HEAD~1:feature-api", "HEAD~3:feature-models"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CreateBranch: the order is checked before anything is created
+				mockGit.On("GetCommitHash", "HEAD~1").Return("sha-3", nil)
+				mockGit.On("GetCommitHash", "HEAD~3").Return("sha-1", nil)
+				mockGit.On("CheckBranchName", "feature-api").Return(nil)
+				mockGit.On("BranchExists", "feature-api").Return(false)
+			},
+			expectError:   true,
+			errorContains: "oldest first",
+		},
+		{
+			name:    "refuses existing branch names",
+			splitAt: []string{"HEAD~3:feature-base"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCommitHash", "HEAD~3").Return("sha-1", nil)
+				mockGit.On("CheckBranchName", "feature-base").Return(nil)
+				mockGit.On("BranchExists", "feature-base").Return(true)
+			},
+			expectError:   true,
+			errorContains: "already exists",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			splitAt = tt.splitAt
+			defer func() { splitAt = nil }()
+			if tt.input != "" {
+				stdinReader = strings.NewReader(tt.input)
+				defer func() { stdinReader = os.Stdin }()
+			}
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return("feature", nil)
+			mockGit.On("GetConfig", "branch.feature.stackparent").Return("feature-base")
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("BranchExists", "feature").Return(true)
+			mockGit.On("GetUniqueCommits", "feature-base", "feature").Return(commits, nil)
+			tt.setupMocks(mockGit)
+
+			err := runSplit(mockGit)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
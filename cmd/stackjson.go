@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// stackJSON is the stack printed by 'stack show --json'. Tree is null when the
+// current branch isn't in a stack.
+type stackJSON struct {
+	Current   string         `json:"current"`
+	Base      string         `json:"base"`
+	Operation *operationJSON `json:"operation,omitempty"`
+	Tree      *branchJSON    `json:"tree"`
+}
+
+// statusJSON is the stack printed by 'stack status --json'. Issues is null when
+// they weren't checked (--no-pr, or during a rebase or merge).
+type statusJSON struct {
+	stackJSON
+	InterruptedSync *interruptedSync `json:"interruptedSync,omitempty"`
+	Issues          []syncIssue      `json:"issues"`
+}
+
+// operationJSON is a rebase, merge or cherry-pick stopped part way
+type operationJSON struct {
+	Kind   string `json:"kind"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// branchJSON is a branch of the tree. Ahead and Behind count commits relative to
+// the parent, and are left out for the base branch.
+type branchJSON struct {
+	Name     string        `json:"name"`
+	Parent   string        `json:"parent,omitempty"`
+	Current  bool          `json:"current"`
+	Umbrella bool          `json:"umbrella,omitempty"`
+	Ahead    *int          `json:"ahead,omitempty"`
+	Behind   *int          `json:"behind,omitempty"`
+	PR       *prJSON       `json:"pr,omitempty"`
+	Children []*branchJSON `json:"children"`
+}
+
+// prJSON is the PR of a branch
+type prJSON struct {
+	Number int    `json:"number"`
+	State  string `json:"state"`
+	URL    string `json:"url"`
+	Base   string `json:"base"`
+	Title  string `json:"title"`
+}
+
+// newStackJSON describes tree, which may be nil, as seen from currentBranch.
+// prCache may be nil when PRs weren't looked up.
+func newStackJSON(gitClient git.GitClient, tree *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, umbrellas map[string]bool, operation *git.Operation) stackJSON {
+	out := stackJSON{
+		Current: currentBranch,
+		Base:    stack.GetBaseBranch(gitClient),
+	}
+	if operation != nil {
+		out.Operation = &operationJSON{Kind: operation.Kind, Branch: operation.Branch}
+	}
+
+	var convert func(node *stack.TreeNode, parent string) *branchJSON
+	convert = func(node *stack.TreeNode, parent string) *branchJSON {
+		b := &branchJSON{
+			Name:     node.Name,
+			Parent:   parent,
+			Current:  node.Name == currentBranch,
+			Umbrella: umbrellas[node.Name],
+			Children: []*branchJSON{},
+		}
+		if parent != "" {
+			if commits, err := gitClient.GetUniqueCommits(parent, node.Name); err == nil {
+				ahead := len(commits)
+				b.Ahead = &ahead
+			}
+			if commits, err := gitClient.GetUniqueCommits(node.Name, parent); err == nil {
+				behind := len(commits)
+				b.Behind = &behind
+			}
+		}
+		if pr := prCache[node.Name]; pr != nil {
+			b.PR = &prJSON{Number: pr.Number, State: pr.State, URL: pr.URL, Base: pr.Base, Title: pr.Title}
+		}
+		for _, child := range node.Children {
+			b.Children = append(b.Children, convert(child, node.Name))
+		}
+		return b
+	}
+	if tree != nil {
+		out.Tree = convert(tree, "")
+	}
+	return out
+}
+
+// printJSON prints v as indented JSON
+func printJSON(v any) error {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStackJSON(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+	mockGit.On("GetUniqueCommits", "main", "feature-a").Return([]string{"a1", "a2"}, nil)
+	mockGit.On("GetUniqueCommits", "feature-a", "main").Return([]string{"m1"}, nil)
+	mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1"}, nil)
+	mockGit.On("GetUniqueCommits", "feature-b", "feature-a").Return([]string{}, nil)
+
+	tree := &stack.TreeNode{Name: "main", Children: []*stack.TreeNode{
+		{Name: "feature-a", Children: []*stack.TreeNode{{Name: "feature-b"}}},
+	}}
+	prs := map[string]*github.PRInfo{
+		"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "Add A", "https://github.com/o/r/pull/1"),
+	}
+
+	out := newStackJSON(mockGit, tree, "feature-b", prs, nil, &git.Operation{Kind: "rebase", Branch: "feature-b"})
+
+	encoded, err := json.Marshal(statusJSON{stackJSON: out, Issues: []syncIssue{
+		{Branch: "feature-b", Kind: "needs-push", Message: "differs from origin (needs push)", line: "ignored"},
+	}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"current": "feature-b",
+		"base": "main",
+		"operation": {"kind": "rebase", "branch": "feature-b"},
+		"tree": {
+			"name": "main",
+			"current": false,
+			"children": [{
+				"name": "feature-a",
+				"parent": "main",
+				"current": false,
+				"ahead": 2,
+				"behind": 1,
+				"pr": {"number": 1, "state": "OPEN", "url": "https://github.com/o/r/pull/1", "base": "main", "title": "Add A"},
+				"children": [{
+					"name": "feature-b",
+					"parent": "feature-a",
+					"current": true,
+					"ahead": 1,
+					"behind": 0,
+					"children": []
+				}]
+			}]
+		},
+		"issues": [{"branch": "feature-b", "kind": "needs-push", "message": "differs from origin (needs push)"}]
+	}`, string(encoded))
+}
+
+func TestNewStackJSONNotInStack(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+
+	encoded, err := json.Marshal(newStackJSON(mockGit, nil, "scratch", nil, nil, nil))
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"current": "scratch", "base": "main", "tree": null}`, string(encoded))
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var nameUnset bool
+
+// stackNameKey is the git config key holding a stack's name, stored on its root branch
+func stackNameKey(root string) string {
+	return fmt.Sprintf("branch.%s.stackname", root)
+}
+
+var nameCmd = &cobra.Command{
+	Use:   "name [name]",
+	Short: i18n.T("name.short"),
+	Long:  i18n.T("name.long"),
+	Example: `  # Name the current stack
+  stack name payments
+
+  # Show its name
+  stack name
+
+  # Remove the name
+  stack name --unset`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		if err := runName(gitClient, name); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:     "list",
+	Short:   i18n.T("list.short"),
+	Long:    i18n.T("list.long"),
+	Example: `  stack list`,
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runList(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+var switchCmd = &cobra.Command{
+	Use:   "switch <stack>",
+	Short: i18n.T("switch.short"),
+	Long:  i18n.T("switch.long"),
+	Example: `  # Jump to the top of the payments stack
+  stack switch payments`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runSwitch(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	nameCmd.Flags().BoolVar(&nameUnset, "unset", false, "Remove the name of the current stack")
+}
+
+func runName(gitClient git.GitClient, name string) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	if _, inStack := parents[currentBranch]; !inStack {
+		return &stack.NotInStackError{Branch: currentBranch}
+	}
+	root := stack.RootOf(parents, currentBranch)
+
+	if nameUnset {
+		if gitClient.GetConfig(stackNameKey(root)) == "" {
+			fmt.Printf("The stack rooted at %s has no name\n", ui.Branch(root))
+			return nil
+		}
+		if err := gitClient.UnsetConfig(stackNameKey(root)); err != nil {
+			return fmt.Errorf("failed to remove stack name: %w", err)
+		}
+		if !dryRun {
+			fmt.Printf("%s Removed the name of the stack rooted at %s\n", ui.SuccessIcon(), ui.Branch(root))
+		}
+		return nil
+	}
+
+	if name == "" {
+		if current := gitClient.GetConfig(stackNameKey(root)); current != "" {
+			fmt.Println(current)
+		} else {
+			fmt.Printf("The stack rooted at %s has no name (set one with '%s')\n", ui.Branch(root), ui.Command("stack name <name>"))
+		}
+		return nil
+	}
+
+	if strings.ContainsAny(name, " \t\n") {
+		return fmt.Errorf("stack name %q cannot contain whitespace", name)
+	}
+	for _, other := range stackRoots(parents) {
+		if other != root && gitClient.GetConfig(stackNameKey(other)) == name {
+			return fmt.Errorf("the stack rooted at %s is already named %s", other, name)
+		}
+	}
+
+	if err := gitClient.SetConfig(stackNameKey(root), name); err != nil {
+		return fmt.Errorf("failed to set stack name: %w", err)
+	}
+	if !dryRun {
+		fmt.Printf("%s Named the stack rooted at %s %s\n", ui.SuccessIcon(), ui.Branch(root), name)
+	}
+	return nil
+}
+
+func runList(gitClient git.GitClient) error {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	roots := stackRoots(parents)
+	if len(roots) == 0 {
+		fmt.Println(i18n.T("stack.noBranches"))
+		return nil
+	}
+
+	currentRoot := ""
+	if currentBranch, err := gitClient.GetCurrentBranch(); err == nil {
+		if _, inStack := parents[currentBranch]; inStack {
+			currentRoot = stack.RootOf(parents, currentBranch)
+		}
+	}
+
+	for _, root := range roots {
+		name := gitClient.GetConfig(stackNameKey(root))
+		if name == "" {
+			name = ui.Dim("(unnamed)")
+		}
+		marker := ""
+		if root == currentRoot {
+			marker = ui.CurrentBranchMarker()
+		}
+		fmt.Printf("  %s  %s %s%s\n", name, ui.Branch(root), ui.Dim(fmt.Sprintf("(%d branch(es))", len(stack.Members(parents, root)))), marker)
+	}
+	return nil
+}
+
+func runSwitch(gitClient git.GitClient, target string) error {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	root := ""
+	for _, candidate := range stackRoots(parents) {
+		if gitClient.GetConfig(stackNameKey(candidate)) == target {
+			root = candidate
+			break
+		}
+	}
+	if root == "" {
+		if _, inStack := parents[target]; !inStack {
+			return fmt.Errorf("no stack named %s (see '%s')", target, ui.Command("stack list"))
+		}
+		root = stack.RootOf(parents, target)
+	}
+
+	tips := stackTips(parents, root)
+	if len(tips) == 0 {
+		// Every branch has a child when the stack parents form a cycle
+		return fmt.Errorf("the stack of %s has no tip, its parents may form a cycle (see '%s')", target, ui.Command("stack doctor"))
+	}
+	tip := tips[0]
+	if len(tips) > 1 {
+		fmt.Printf("The stack rooted at %s has several tips:\n", ui.Branch(root))
+		for i, branch := range tips {
+			fmt.Printf("  %d) %s\n", i+1, ui.Branch(branch))
+		}
+		fmt.Printf("\nSelect branch (1-%d): ", len(tips))
+
+		input, err := bufio.NewReader(stdinReader).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(input)
+		selection, err := strconv.Atoi(input)
+		if err != nil || selection < 1 || selection > len(tips) {
+			return fmt.Errorf("invalid selection: %s", input)
+		}
+		tip = tips[selection-1]
+	}
+
+	if err := gitClient.CheckoutBranch(tip); err != nil {
+		return fmt.Errorf("failed to checkout %s: %w", tip, err)
+	}
+	if !dryRun {
+		fmt.Printf("Switched to %s\n", ui.Branch(tip))
+	}
+	return nil
+}
+
+// stackRoots returns the stack branches whose parent isn't a stack branch, sorted
+func stackRoots(parents map[string]string) []string {
+	var roots []string
+	for branch, parent := range parents {
+		if _, stacked := parents[parent]; !stacked {
+			roots = append(roots, branch)
+		}
+	}
+	sort.Strings(roots)
+	return roots
+}
+
+// stackTips returns the branches of root's stack that have no children, sorted
+func stackTips(parents map[string]string, root string) []string {
+	hasChildren := make(map[string]bool)
+	for _, parent := range parents {
+		hasChildren[parent] = true
+	}
+	var tips []string
+	for _, branch := range stack.Members(parents, root) {
+		if !hasChildren[branch] {
+			tips = append(tips, branch)
+		}
+	}
+	return tips
+}
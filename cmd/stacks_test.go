@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackTopology(t *testing.T) {
+	parents := map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+		"feature-c": "feature-a",
+		"other":     "main",
+	}
+
+	assert.Equal(t, []string{"feature-a", "other"}, stackRoots(parents))
+	assert.Equal(t, "feature-a", stack.RootOf(parents, "feature-c"))
+	assert.Equal(t, []string{"feature-a", "feature-b", "feature-c"}, stack.Members(parents, "feature-a"))
+	assert.Equal(t, []string{"feature-b", "feature-c"}, stackTips(parents, "feature-a"))
+	assert.Equal(t, []string{"other"}, stackTips(parents, "other"))
+}
+
+func TestRunName(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "other": "main"}
+
+	tests := []struct {
+		name          string
+		stackName     string
+		unset         bool
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:      "names the stack on its root branch",
+			stackName: "payments",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", "branch.other.stackname").Return("")
+				mockGit.On("SetConfig", "branch.feature-a.stackname", "payments").Return(nil)
+			},
+		},
+		{
+			name:      "refuses a name used by another stack",
+			stackName: "payments",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No SetConfig: the name is already taken
+				mockGit.On("GetConfig", "branch.other.stackname").Return("payments")
+			},
+			expectError:   true,
+			errorContains: "already named",
+		},
+		{
+			name:  "removes the name with --unset",
+			unset: true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", "branch.feature-a.stackname").Return("payments")
+				mockGit.On("UnsetConfig", "branch.feature-a.stackname").Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+			mockGit.On("GetAllStackParents").Return(parents, nil)
+			tt.setupMocks(mockGit)
+
+			nameUnset = tt.unset
+			defer func() { nameUnset = false }()
+
+			err := runName(mockGit, tt.stackName)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRunSwitch(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+		"feature-c": "feature-a",
+		"other":     "main",
+	}
+
+	tests := []struct {
+		name          string
+		target        string
+		input         string
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:   "checks out the tip of the named stack",
+			target: "other",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetAllStackParents").Return(parents, nil)
+				mockGit.On("GetConfig", "branch.feature-a.stackname").Return("payments")
+				mockGit.On("GetConfig", "branch.other.stackname").Return("")
+				mockGit.On("CheckoutBranch", "other").Return(nil)
+			},
+		},
+		{
+			name:   "prompts when the stack has several tips",
+			target: "payments",
+			input:  "2\n",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetAllStackParents").Return(parents, nil)
+				mockGit.On("GetConfig", "branch.feature-a.stackname").Return("payments")
+				mockGit.On("CheckoutBranch", "feature-c").Return(nil)
+			},
+		},
+		{
+			name:   "fails for an unknown stack",
+			target: "nope",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CheckoutBranch: there's nothing to switch to
+				mockGit.On("GetAllStackParents").Return(parents, nil)
+				mockGit.On("GetConfig", "branch.feature-a.stackname").Return("payments")
+				mockGit.On("GetConfig", "branch.other.stackname").Return("")
+			},
+			expectError:   true,
+			errorContains: "no stack named",
+		},
+		{
+			name:   "fails when the stack parents form a cycle",
+			target: "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "feature-b", "feature-b": "feature-a"}, nil)
+			},
+			expectError:   true,
+			errorContains: "stack doctor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			tt.setupMocks(mockGit)
+
+			stdinReader = strings.NewReader(tt.input)
+			defer func() { stdinReader = os.Stdin }()
+
+			err := runSwitch(mockGit, tt.target)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configStaleCommits is how many commits the base branch may get ahead of a stack root
+// before status nudges to restack (0 disables)
+const configStaleCommits = "stack.staleCommits"
+
+// configStaleDays is how many days a stack root may go without being restacked
+// onto a moving base branch before status nudges (0 disables)
+const configStaleDays = "stack.staleDays"
+
+const (
+	defaultStaleCommits = 20
+	defaultStaleDays    = 7
+)
+
+// staleRoot is a stack root that the base branch has moved ahead of
+type staleRoot struct {
+	branch    string
+	base      string
+	behind    int
+	restacked time.Time // zero if unknown (no commits of its own)
+}
+
+// readStaleThreshold reads a non-negative integer config value, falling back to def
+func readStaleThreshold(gitClient git.GitClient, key string, def int) int {
+	value := gitClient.GetConfig(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// detectStaleRoots finds the stack roots (branches directly on the base branch) that
+// origin/<base> has moved past by at least commitThreshold commits, or that were last
+// restacked at least dayThreshold days ago while the base kept moving.
+func detectStaleRoots(gitClient git.GitClient, branches []stack.StackBranch, baseBranch string, commitThreshold, dayThreshold int, now time.Time) []staleRoot {
+	if commitThreshold == 0 && dayThreshold == 0 {
+		return nil
+	}
+
+	var stale []staleRoot
+	for _, branch := range branches {
+		if branch.Parent != baseBranch {
+			continue
+		}
+
+		behind, err := gitClient.CountCommitsBehind(branch.Name, baseBranch)
+		if err != nil || behind == 0 {
+			continue
+		}
+
+		// Rebasing rewrites committer dates, so the root's first commit on top of
+		// the base tells when the base was last integrated
+		var restacked time.Time
+		if mergeBase, err := gitClient.GetMergeBase(branch.Name, git.RemoteRef(baseBranch)); err == nil {
+			if commits, err := gitClient.GetUniqueCommits(mergeBase, branch.Name); err == nil && len(commits) > 0 {
+				restacked, _ = gitClient.GetCommitTime(commits[0])
+			}
+		}
+
+		tooFarBehind := commitThreshold > 0 && behind >= commitThreshold
+		tooOld := dayThreshold > 0 && !restacked.IsZero() && now.Sub(restacked) >= time.Duration(dayThreshold)*24*time.Hour
+		if !tooFarBehind && !tooOld {
+			continue
+		}
+
+		stale = append(stale, staleRoot{
+			branch:    branch.Name,
+			base:      baseBranch,
+			behind:    behind,
+			restacked: restacked,
+		})
+	}
+	return stale
+}
+
+// printStaleRoots nudges to restack stacks whose base has moved on
+func printStaleRoots(stale []staleRoot, now time.Time) {
+	if len(stale) == 0 {
+		return
+	}
+
+	fmt.Println()
+	for _, root := range stale {
+		msg := fmt.Sprintf("%s is %d commit(s) ahead of your stack root %s", root.base, root.behind, root.branch)
+		if !root.restacked.IsZero() {
+			msg += fmt.Sprintf(" (last restacked %s)", formatDaysAgo(now.Sub(root.restacked)))
+		}
+		fmt.Println(ui.Warning(msg))
+	}
+	fmt.Println(i18n.T("staleness.syncHint", ui.Command("stack sync")))
+}
+
+// formatDaysAgo renders an age as "today", "1 day ago" or "N days ago"
+func formatDaysAgo(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
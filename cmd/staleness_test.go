@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDetectStaleRoots(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+	}
+
+	setupMocks := func(mockGit *testutil.MockGitClient, behind int, restacked time.Time) {
+		mockGit.On("CountCommitsBehind", "feature-a", "main").Return(behind, nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("base123", nil).Maybe()
+		mockGit.On("GetUniqueCommits", "base123", "feature-a").Return([]string{"first", "second"}, nil).Maybe()
+		mockGit.On("GetCommitTime", "first").Return(restacked, nil).Maybe()
+	}
+
+	t.Run("base far ahead", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		setupMocks(mockGit, 37, now.Add(-5*24*time.Hour))
+
+		stale := detectStaleRoots(mockGit, branches, "main", 20, 7, now)
+
+		assert.Equal(t, []staleRoot{{branch: "feature-a", base: "main", behind: 37, restacked: now.Add(-5 * 24 * time.Hour)}}, stale)
+		mockGit.AssertNotCalled(t, "CountCommitsBehind", "feature-b", mock.Anything)
+	})
+
+	t.Run("restacked long ago", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		setupMocks(mockGit, 3, now.Add(-10*24*time.Hour))
+
+		stale := detectStaleRoots(mockGit, branches, "main", 20, 7, now)
+
+		assert.Len(t, stale, 1)
+	})
+
+	t.Run("recent and close to base", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		setupMocks(mockGit, 3, now.Add(-2*24*time.Hour))
+
+		stale := detectStaleRoots(mockGit, branches, "main", 20, 7, now)
+
+		assert.Empty(t, stale)
+	})
+
+	t.Run("up to date with base", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		setupMocks(mockGit, 0, time.Time{})
+
+		stale := detectStaleRoots(mockGit, branches, "main", 20, 7, now)
+
+		assert.Empty(t, stale)
+		mockGit.AssertNotCalled(t, "GetMergeBase", mock.Anything, mock.Anything)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+
+		stale := detectStaleRoots(mockGit, branches, "main", 0, 0, now)
+
+		assert.Empty(t, stale)
+		mockGit.AssertNotCalled(t, "CountCommitsBehind", mock.Anything, mock.Anything)
+	})
+}
+
+func TestReadStaleThreshold(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected int
+	}{
+		{"", 20},
+		{"50", 50},
+		{"0", 0},
+		{"-1", 20},
+		{"lots", 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.staleCommits").Return(tt.value)
+
+			assert.Equal(t, tt.expected, readStaleThreshold(mockGit, configStaleCommits, 20))
+		})
+	}
+}
+
+func TestFormatDaysAgo(t *testing.T) {
+	assert.Equal(t, "today", formatDaysAgo(3*time.Hour))
+	assert.Equal(t, "1 day ago", formatDaysAgo(30*time.Hour))
+	assert.Equal(t, "5 days ago", formatDaysAgo(5*24*time.Hour+time.Hour))
+}
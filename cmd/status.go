@@ -1,40 +1,57 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"os"
-	"strings"
 	"sync"
 	"time"
 
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/spinner"
 	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	noPR bool
+	noPR         bool
+	statusAuthor string
+	// statusJSONOutput prints the stack as JSON instead of a tree
+	statusJSONOutput bool
+
+	// Thresholds for the restack nudge, read from config in Run
+	staleCommits int
+	staleDays    int
 )
 
 var statusCmd = &cobra.Command{
 	Use:   "status",
-	Short: "Show the current stack structure",
-	Long: `Display the stack structure as a tree, showing:
-  - Branch hierarchy (parent → child relationships)
-  - Current branch (highlighted with *)
-  - PR status for each branch (if available)
-
-This helps you visualize your stack and see which branches have PRs.`,
+	Short: i18n.T("status.short"),
+	Long:  i18n.T("status.long"),
 	Example: `  # Show stack structure
   stack status
 
   # Show without PR info (faster)
   stack status --no-pr
 
+  # Show every stack, two levels deep, or everything unfolded
+  stack status --all --depth 2
+  stack status --all --expand
+
+  # Show a teammate's stacks, discovered from their open PRs
+  stack status --author alice
+
+  # Show where the branches were last Monday, or when a commit was made
+  stack status --at 2024-05-06
+  stack status --at 3f2a9c1
+
+  # Print the stack, PRs and sync issues as JSON for scripts
+  stack status --json
+
   # Example output:
   #  main
   #   |
@@ -43,18 +60,61 @@ This helps you visualize your stack and see which branches have PRs.`,
   #  feature-auth-tests *`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
-		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL("origin"))
-		githubClient := github.NewGitHubClient(repo)
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if statusJSONOutput {
+			if statusAt != "" || statusAuthor != "" {
+				exitWithError(errors.New("--json can't be combined with --at or --author"))
+			}
+			// Keep color codes out of the JSON
+			ui.SetNoColor(true)
+		}
+
+		// Past states come from the reflogs, so GitHub isn't needed
+		if statusAt != "" {
+			if err := runStatusAt(gitClient, statusAt); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+
+		// A teammate's stacks aren't tracked locally, so derive them from their PRs
+		if statusAuthor != "" {
+			if err := runPRs(githubClient, statusAuthor); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+
+		if err := readPRScope(cmd, gitClient); err != nil {
+			exitWithError(err)
+		}
+		// Looking up PRs for deleted branches needs GitHub, so --no-pr skips it.
+		// Recreating them asks first, which scripts reading JSON can't answer.
+		if !noPR && !statusJSONOutput {
+			if err := restoreRemoteOnlyBranches(gitClient, githubClient); err != nil {
+				exitWithError(err)
+			}
+		}
+
+		staleCommits = readStaleThreshold(gitClient, configStaleCommits, defaultStaleCommits)
+		staleDays = readStaleThreshold(gitClient, configStaleDays, defaultStaleDays)
 
 		if err := runStatus(gitClient, githubClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
 
 func init() {
 	statusCmd.Flags().BoolVar(&noPR, "no-pr", false, "Skip fetching PR information (faster)")
+	statusCmd.Flags().StringVar(&statusAuthor, "author", "", "Show the stacks of another user's open PRs instead of the local stack")
+	statusCmd.Flags().StringVar(&statusAt, "at", "", "Show the branch tips at a past date (YYYY-MM-DD [HH:MM]) or commit, from the reflogs")
+	statusCmd.Flags().BoolVar(&statusAll, "all", false, "Show every stack, not only the current branch's")
+	statusCmd.Flags().IntVar(&statusDepth, "depth", 0, "Show this many levels below the base branch, folding the rest (0 for all)")
+	statusCmd.Flags().BoolVar(&statusExpand, "expand", false, "Show every branch, without folding merged or deep ones")
+	statusCmd.Flags().StringVar(&prScope, "pr-scope", syncer.PRScopeStack, "Which PRs to look up: stack (PRs of the stack's branches) or author (only your own PRs)")
+	statusCmd.Flags().BoolVar(&statusJSONOutput, "json", false, "Print the stack, PRs and sync issues as JSON")
 }
 
 func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error {
@@ -63,10 +123,23 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 	var tree *stack.TreeNode
 	var allTreeBranches []string
 
+	// A stopped rebase/merge leaves the tree looking healthy, so call it out first
+	operation := gitClient.GetOperationInProgress()
+	if operation != nil && !statusJSONOutput {
+		printOperationInProgress(gitClient, operation)
+	}
+	// Without a stopped rebase the sync state may have been forgotten, which the
+	// next sync would trip over
+	interrupted := readInterruptedSync(gitClient)
+	if interrupted != nil && operation == nil && !statusJSONOutput {
+		printInterruptedSync(interrupted, time.Now())
+	}
+
 	// Start fetch and PR loading in parallel with stack tree building (if not --no-pr)
 	// These are the slowest operations and can run while we build the tree
 	var wg sync.WaitGroup
 	var prCache map[string]*github.PRInfo
+	var prQueried map[string]bool
 	var prErr error
 	fetchDone := false
 
@@ -74,7 +147,7 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 		wg.Add(2)
 		go func() {
 			defer wg.Done()
-			prCache, prErr = githubClient.GetAllPRs()
+			prCache, prQueried, prErr = syncer.LoadStackPRs(gitClient, githubClient, prScope)
 			if prErr != nil {
 				if verbose {
 					fmt.Printf("  [gh] Error fetching PRs: %v\n", prErr)
@@ -101,6 +174,10 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 		if err != nil {
 			return fmt.Errorf("failed to get current branch: %w", err)
 		}
+		// HEAD is detached during a rebase; show the stack of the branch being rebased
+		if currentBranch == "" && operation != nil {
+			currentBranch = operation.Branch
+		}
 
 		// Check if there are any stack branches
 		stackBranches, err = stack.GetStackBranches(gitClient)
@@ -112,8 +189,12 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 			return nil // Handle this after the spinner
 		}
 
-		// Build stack tree for current branch only
-		tree, err = stack.BuildStackTreeForBranch(gitClient, currentBranch)
+		// Build stack tree for current branch only, unless every stack was asked for
+		if statusAll {
+			tree, err = stack.BuildStackTree(gitClient)
+		} else {
+			tree, err = stack.BuildStackTreeForBranch(gitClient, currentBranch)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to build stack tree: %w", err)
 		}
@@ -130,9 +211,8 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 		if !noPR {
 			wg.Wait()
 
-			// GetAllPRs only fetches open PRs (to avoid 502 timeouts on large repos).
-			// For branches in our stack that aren't in the cache, check individually
-			// to detect merged PRs that need special handling.
+			// Branches that weren't part of the bulk query, or all of them if it failed,
+			// are checked individually to detect merged PRs that need special handling.
 			// OPTIMIZATION: Only check branches in the current tree, not all stack branches.
 			branchSet := make(map[string]bool)
 			for _, name := range allTreeBranches {
@@ -140,13 +220,23 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 			}
 			baseBranch := stack.GetBaseBranch(gitClient)
 
+			// Pinned PR numbers survive head branch renames and head-name clashes across forks
+			var treeBranches []stack.StackBranch
+			for _, branch := range stackBranches {
+				if branchSet[branch.Name] {
+					treeBranches = append(treeBranches, branch)
+				}
+			}
+			prPins, _ := gitClient.GetAllStackPRs()
+			syncer.ResolvePinnedPRs(githubClient, treeBranches, prCache, prPins)
+
 			for _, branch := range stackBranches {
 				// Skip branches not in the current tree
 				if !branchSet[branch.Name] {
 					continue
 				}
-				// Skip if already in cache (has open PR)
-				if _, exists := prCache[branch.Name]; exists {
+				// Skip if already in cache, or known to have no PR
+				if _, exists := prCache[branch.Name]; exists || prQueried[branch.Name] {
 					continue
 				}
 				// Fetch PR info for this branch (might be merged or non-existent)
@@ -155,7 +245,7 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 				}
 				// Also check parent if not in cache and not base branch
 				if branch.Parent != baseBranch {
-					if _, exists := prCache[branch.Parent]; !exists {
+					if _, exists := prCache[branch.Parent]; !exists && !prQueried[branch.Parent] {
 						if pr, err := githubClient.GetPRForBranch(branch.Parent); err == nil && pr != nil {
 							prCache[branch.Parent] = pr
 						}
@@ -172,70 +262,67 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 	if len(stackBranches) == 0 {
 		// Wait for PR fetch to complete before returning
 		wg.Wait()
-		fmt.Println("No stack branches found.")
-		fmt.Printf("Current branch: %s\n", ui.Branch(currentBranch))
-		fmt.Printf("\nUse '%s' to create a new stack branch.\n", ui.Command("stack new <branch-name>"))
+		if statusJSONOutput {
+			return printJSON(statusJSON{stackJSON: newStackJSON(gitClient, nil, currentBranch, nil, nil, operation), InterruptedSync: interrupted})
+		}
+		fmt.Println(i18n.T("stack.noBranches"))
+		fmt.Println(i18n.T("stack.currentBranch", ui.Branch(currentBranch)))
+		fmt.Printf("\n%s\n", i18n.T("stack.createHint", ui.Command("stack new <branch-name>")))
 		return nil
 	}
 
 	// If tree is nil, current branch is not part of any stack
 	// Check this BEFORE waiting for PR fetch to avoid long delays
 	if tree == nil {
-		baseBranch := stack.GetBaseBranch(gitClient)
-
-		// Don't offer to add the base branch to a stack - it can't have a parent
-		if currentBranch == baseBranch {
-			fmt.Printf("Branch '%s' is the base branch and cannot be part of a stack.\n", ui.Branch(currentBranch))
-			fmt.Printf("\nUse '%s' to create a new stack branch.\n", ui.Command("stack new <branch-name>"))
+		if statusJSONOutput {
+			return printJSON(statusJSON{stackJSON: newStackJSON(gitClient, nil, currentBranch, nil, nil, operation), InterruptedSync: interrupted})
+		}
+		// Don't offer to change stack config in the middle of another operation
+		if operation != nil {
 			return nil
 		}
 
-		fmt.Printf("Current branch '%s' is not part of a stack.\n\n", ui.Branch(currentBranch))
-		fmt.Printf("Add to stack with '%s' as parent? [Y/n] ", ui.Branch(baseBranch))
-
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+		added, err := syncer.OfferToAddToStack(gitClient, stdinReader, currentBranch, stack.GetBaseBranch(gitClient))
+		if err != nil || !added {
+			return err
 		}
+		fmt.Println()
+		// Run status again to show the stack
+		return runStatus(gitClient, githubClient)
+	}
 
-		input = strings.TrimSpace(strings.ToLower(input))
-		if input == "" || input == "y" || input == "yes" {
-			// Set the stackparent config
-			configKey := fmt.Sprintf("branch.%s.stackparent", currentBranch)
-			if err := gitClient.SetConfig(configKey, baseBranch); err != nil {
-				return fmt.Errorf("failed to set stack parent: %w", err)
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	if statusJSONOutput {
+		out := statusJSON{stackJSON: newStackJSON(gitClient, tree, currentBranch, prCache, umbrellas, operation), InterruptedSync: interrupted}
+		if !noPR && operation == nil {
+			result, err := detectSyncIssues(gitClient, filterTreeBranches(stackBranches, allTreeBranches), prCache, umbrellas, func(string) {}, fetchDone)
+			if err != nil {
+				return fmt.Errorf("failed to check for sync issues: %w", err)
+			}
+			out.Issues = result.issues
+			if out.Issues == nil {
+				out.Issues = []syncIssue{}
 			}
-			fmt.Println(ui.Success(fmt.Sprintf("Added '%s' to stack with parent '%s'", ui.Branch(currentBranch), ui.Branch(baseBranch))))
-			fmt.Println()
-			// Run status again to show the stack
-			return runStatus(gitClient, githubClient)
 		}
-		return nil
+		return printJSON(out)
 	}
 
 	// Print the tree
 	fmt.Println()
-	printTree(gitClient, tree, "", true, currentBranch, prCache)
+	printStatusTree(gitClient, tree, currentBranch, prCache, umbrellas)
+	if github.Offline && !noPR {
+		fmt.Printf("\n%s\n", ui.Dim(github.OfflineNote(time.Now())))
+	}
 
-	// Check for sync issues (skip if --no-pr)
-	if !noPR {
-		// Filter stackBranches to only include branches in the current tree
-		branchSet := make(map[string]bool)
-		for _, name := range allTreeBranches {
-			branchSet[name] = true
-		}
-		var treeBranches []stack.StackBranch
-		for _, branch := range stackBranches {
-			if branchSet[branch.Name] {
-				treeBranches = append(treeBranches, branch)
-			}
-		}
+	// Check for sync issues (skip if --no-pr). Branches are in flux while an
+	// operation is in progress, so the result would be misleading.
+	if !noPR && operation == nil {
+		treeBranches := filterTreeBranches(stackBranches, allTreeBranches)
 
 		var syncResult *syncIssuesResult
 		if err := spinner.WrapWithAutoDelayAndProgress("Checking for sync issues...", 300*time.Millisecond, func(progress spinner.ProgressFunc) error {
 			var err error
-			syncResult, err = detectSyncIssues(gitClient, treeBranches, prCache, progress, fetchDone)
+			syncResult, err = detectSyncIssues(gitClient, treeBranches, prCache, umbrellas, progress, fetchDone)
 			return err
 		}); err != nil {
 			// Don't fail on detection errors, just skip the check
@@ -245,6 +332,9 @@ func runStatus(gitClient git.GitClient, githubClient github.GitHubClient) error
 		if syncResult != nil {
 			printSyncIssues(syncResult)
 		}
+
+		// Long-lived stacks rot quietly while the base moves on
+		printStaleRoots(detectStaleRoots(gitClient, treeBranches, stack.GetBaseBranch(gitClient), staleCommits, staleDays, time.Now()), time.Now())
 	}
 
 	return nil
@@ -275,63 +365,47 @@ func getAllBranchNamesFromTree(node *stack.TreeNode) []string {
 	return result
 }
 
-func printTree(gitClient git.GitClient, node *stack.TreeNode, prefix string, isLast bool, currentBranch string, prCache map[string]*github.PRInfo) {
-	if node == nil {
-		return
+// filterTreeBranches returns the stack branches that are in the tree
+func filterTreeBranches(stackBranches []stack.StackBranch, treeNames []string) []stack.StackBranch {
+	branchSet := make(map[string]bool)
+	for _, name := range treeNames {
+		branchSet[name] = true
 	}
-
-	// Flatten the tree into a vertical list
-	printTreeVertical(gitClient, node, currentBranch, prCache, false)
-}
-
-func printTreeVertical(gitClient git.GitClient, node *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, isPipe bool) {
-	if node == nil {
-		return
-	}
-
-	// Determine the current branch marker
-	marker := ""
-	if node.Name == currentBranch {
-		marker = ui.CurrentBranchMarker()
-	}
-
-	// Get PR info from cache
-	prInfo := ""
-	if node.Name != stack.GetBaseBranch(gitClient) {
-		if pr, exists := prCache[node.Name]; exists {
-			prInfo = fmt.Sprintf(" %s", ui.PRInfo(pr.URL, pr.State))
+	var treeBranches []stack.StackBranch
+	for _, branch := range stackBranches {
+		if branchSet[branch.Name] {
+			treeBranches = append(treeBranches, branch)
 		}
 	}
-
-	// Print pipe if needed
-	if isPipe {
-		fmt.Printf("  %s\n", ui.Pipe())
-	}
-
-	// Print current node
-	fmt.Printf(" %s%s%s\n", ui.Branch(node.Name), prInfo, marker)
-
-	// Print children vertically
-	for _, child := range node.Children {
-		printTreeVertical(gitClient, child, currentBranch, prCache, true)
-	}
+	return treeBranches
 }
 
 // syncIssuesResult holds the result of detectSyncIssues
 type syncIssuesResult struct {
-	issues []string
+	issues []syncIssue
+}
+
+// syncIssue is one way a branch is out of sync. Kind is pr-base, behind-parent
+// or needs-push.
+type syncIssue struct {
+	Branch  string `json:"branch"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	// line is the message as printed by 'stack status', with colors
+	line string
 }
 
 // detectSyncIssues checks if any branches are out of sync and returns the issues (doesn't print)
 // If skipFetch is true, assumes git fetch was already called (to avoid redundant network calls)
-func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch, prCache map[string]*github.PRInfo, progress spinner.ProgressFunc, skipFetch bool) (*syncIssuesResult, error) {
-	var issues []string
+func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch, prCache map[string]*github.PRInfo, umbrellas map[string]bool, progress spinner.ProgressFunc, skipFetch bool) (*syncIssuesResult, error) {
+	var issues []syncIssue
 
 	// Fetch once upfront to ensure we have latest remote refs (unless already done)
 	if !skipFetch {
 		progress("Fetching latest changes...")
 		if verbose {
-			fmt.Println("Fetching latest changes from origin...")
+			fmt.Printf("Fetching latest changes from %s...\n", git.Remote)
 		}
 		_ = gitClient.Fetch()
 	}
@@ -340,6 +414,12 @@ func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch
 		fmt.Printf("Checking %d branch(es) for sync issues...\n", len(stackBranches))
 	}
 
+	// PRs above an umbrella branch target the branch below it
+	parents := make(map[string]string)
+	for _, branch := range stackBranches {
+		parents[branch.Name] = branch.Parent
+	}
+
 	// Check each stack branch for sync issues
 	for i, branch := range stackBranches {
 		progress(fmt.Sprintf("Checking branch %d/%d (%s)...", i+1, len(stackBranches), branch.Name))
@@ -356,19 +436,32 @@ func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch
 			continue
 		}
 
+		// Queued PRs are left alone until they merge
+		if syncer.InMergeQueue(prCache[branch.Name]) {
+			if verbose {
+				fmt.Printf("  Skipping (PR is in the merge queue)\n")
+			}
+			continue
+		}
+
 		// Check if PR base matches the configured parent (if PR exists)
 		if pr, exists := prCache[branch.Name]; exists {
 			if verbose {
 				fmt.Printf("  Found PR #%d (base: %s, state: %s)\n", pr.Number, pr.Base, pr.State)
 			}
 
-			if pr.Base != branch.Parent {
+			if prBase := syncer.PRBaseFor(branch.Parent, parents, umbrellas); pr.Base != prBase && !flatPRs {
 				if verbose {
-					fmt.Printf("  ✗ PR base (%s) doesn't match configured parent (%s)\n", pr.Base, branch.Parent)
+					fmt.Printf("  %s PR base (%s) doesn't match configured parent (%s)\n", ui.ErrorIcon(), pr.Base, prBase)
 				}
-				issues = append(issues, fmt.Sprintf("  - Branch '%s' PR base (%s) doesn't match parent (%s)", ui.Branch(branch.Name), ui.Branch(pr.Base), ui.Branch(branch.Parent)))
+				issues = append(issues, syncIssue{
+					Branch:  branch.Name,
+					Kind:    "pr-base",
+					Message: fmt.Sprintf("PR base (%s) doesn't match parent (%s)", pr.Base, prBase),
+					line:    fmt.Sprintf("  - Branch '%s' PR base (%s) doesn't match parent (%s)", ui.Branch(branch.Name), ui.Branch(pr.Base), ui.Branch(prBase)),
+				})
 			} else if verbose {
-				fmt.Printf("  ✓ PR base matches configured parent\n")
+				fmt.Printf("  %s PR base matches configured parent\n", ui.SuccessIcon())
 			}
 		} else if verbose {
 			fmt.Printf("  No PR found for this branch\n")
@@ -381,39 +474,49 @@ func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch
 		behind, err := gitClient.IsCommitsBehind(branch.Name, branch.Parent)
 		if err == nil && behind {
 			if verbose {
-				fmt.Printf("  ✗ Branch is behind %s (needs rebase)\n", branch.Parent)
+				fmt.Printf("  %s Branch is behind %s (needs rebase)\n", ui.ErrorIcon(), branch.Parent)
 			}
-			issues = append(issues, fmt.Sprintf("  - Branch '%s' is behind %s (needs rebase)", ui.Branch(branch.Name), ui.Branch(branch.Parent)))
+			issues = append(issues, syncIssue{
+				Branch:  branch.Name,
+				Kind:    "behind-parent",
+				Message: fmt.Sprintf("behind %s (needs rebase)", branch.Parent),
+				line:    fmt.Sprintf("  - Branch '%s' is behind %s (needs rebase)", ui.Branch(branch.Name), ui.Branch(branch.Parent)),
+			})
 		} else if err == nil && verbose {
-			fmt.Printf("  ✓ Branch is up to date with %s\n", branch.Parent)
+			fmt.Printf("  %s Branch is up to date with %s\n", ui.SuccessIcon(), branch.Parent)
 		} else if err != nil && verbose {
-			fmt.Printf("  ⚠ Could not check if branch is behind: %v\n", err)
+			fmt.Printf("  %s Could not check if branch is behind: %v\n", ui.WarningIcon(), err)
 		}
 
 		// Check if local branch differs from remote (needs push)
 		if gitClient.RemoteBranchExists(branch.Name) {
 			if verbose {
-				fmt.Printf("  Checking if local branch differs from origin/%s...\n", branch.Name)
+				fmt.Printf("  Checking if local branch differs from %s...\n", git.RemoteRef(branch.Name))
 			}
 			localHash, localErr := gitClient.GetCommitHash(branch.Name)
-			remoteHash, remoteErr := gitClient.GetCommitHash("origin/" + branch.Name)
+			remoteHash, remoteErr := gitClient.GetCommitHash(git.RemoteRef(branch.Name))
 			if localErr == nil && remoteErr == nil && localHash != remoteHash {
 				if verbose {
-					fmt.Printf("  ✗ Local branch differs from origin/%s (needs push)\n", branch.Name)
+					fmt.Printf("  %s Local branch differs from %s (needs push)\n", ui.ErrorIcon(), git.RemoteRef(branch.Name))
 				}
-				issues = append(issues, fmt.Sprintf("  - Branch '%s' differs from origin (needs push)", ui.Branch(branch.Name)))
+				issues = append(issues, syncIssue{
+					Branch:  branch.Name,
+					Kind:    "needs-push",
+					Message: fmt.Sprintf("differs from %s (needs push)", git.RemoteOf(branch.Name)),
+					line:    fmt.Sprintf("  - Branch '%s' differs from %s (needs push)", ui.Branch(branch.Name), git.RemoteOf(branch.Name)),
+				})
 			} else if localErr == nil && remoteErr == nil && verbose {
-				fmt.Printf("  ✓ Local branch matches origin/%s\n", branch.Name)
+				fmt.Printf("  %s Local branch matches %s\n", ui.SuccessIcon(), git.RemoteRef(branch.Name))
 			} else if verbose {
 				if localErr != nil {
-					fmt.Printf("  ⚠ Could not get local commit hash: %v\n", localErr)
+					fmt.Printf("  %s Could not get local commit hash: %v\n", ui.WarningIcon(), localErr)
 				}
 				if remoteErr != nil {
-					fmt.Printf("  ⚠ Could not get remote commit hash: %v\n", remoteErr)
+					fmt.Printf("  %s Could not get remote commit hash: %v\n", ui.WarningIcon(), remoteErr)
 				}
 			}
 		} else if verbose {
-			fmt.Printf("  ℹ No remote branch origin/%s found\n", branch.Name)
+			fmt.Printf("  ℹ No remote branch %s found\n", git.RemoteRef(branch.Name))
 		}
 	}
 
@@ -422,18 +525,35 @@ func detectSyncIssues(gitClient git.GitClient, stackBranches []stack.StackBranch
 	}, nil
 }
 
+// printOperationInProgress warns about a rebase/merge/cherry-pick stopped part way,
+// with the commands to finish or abort it
+func printOperationInProgress(gitClient git.GitClient, operation *git.Operation) {
+	if operation.Branch != "" {
+		fmt.Println(ui.Warning(i18n.T("status.inProgress", operation.Kind, ui.Branch(operation.Branch))))
+	} else {
+		fmt.Println(ui.Warning(i18n.T("status.inProgressDetached", operation.Kind)))
+	}
+
+	// A rebase started by stack sync has to be finished through sync so the rest of the stack follows
+	if operation.Kind == "rebase" && gitClient.GetConfig(syncer.ConfigOriginalBranch) != "" {
+		fmt.Println(i18n.T("status.inProgressHint", ui.Command("git rebase --continue && stack sync --resume"), ui.Command("stack sync --abort")))
+	} else {
+		fmt.Println(i18n.T("status.inProgressHint", ui.Command(fmt.Sprintf("git %s --continue", operation.Kind)), ui.Command(fmt.Sprintf("git %s --abort", operation.Kind))))
+	}
+}
+
 // printSyncIssues prints the sync issues result
 func printSyncIssues(result *syncIssuesResult) {
 	if len(result.issues) > 0 {
 		fmt.Println()
-		fmt.Println(ui.Warning("Stack out of sync detected:"))
+		fmt.Println(ui.Warning(i18n.T("status.outOfSync")))
 		for _, issue := range result.issues {
-			fmt.Println(issue)
+			fmt.Println(issue.line)
 		}
 		fmt.Println()
-		fmt.Printf("Run '%s' to rebase branches and update PR bases.\n", ui.Command("stack sync"))
+		fmt.Println(i18n.T("status.runSync", ui.Command("stack sync")))
 	} else {
 		fmt.Println()
-		fmt.Println(ui.Success("Stack is perfectly synced! All branches are up to date."))
+		fmt.Println(ui.Success(i18n.T("status.synced")))
 	}
 }
@@ -3,6 +3,7 @@ package cmd
 import (
 	"testing"
 
+	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
 	"github.com/javoire/stackinator/internal/stack"
 	"github.com/javoire/stackinator/internal/testutil"
@@ -21,6 +22,8 @@ func TestRunStatus(t *testing.T) {
 		{
 			name: "display simple stack",
 			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOperationInProgress").Return(nil)
+				mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
 				// Get current branch
 				mockGit.On("GetCurrentBranch").Return("feature-a", nil)
 				// Get stack branches (called multiple times in BuildStackTreeForBranch)
@@ -38,6 +41,8 @@ func TestRunStatus(t *testing.T) {
 		{
 			name: "no stack branches",
 			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOperationInProgress").Return(nil)
+				mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
 				// Get current branch
 				mockGit.On("GetCurrentBranch").Return("main", nil)
 				// Get stack branches (empty)
@@ -45,6 +50,35 @@ func TestRunStatus(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "rebase in progress shows the branch being rebased",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOperationInProgress").Return(&git.Operation{Kind: "rebase", Branch: "feature-a"})
+				mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+				// HEAD is detached during the rebase
+				mockGit.On("GetCurrentBranch").Return("", nil)
+				stackParents := map[string]string{
+					"feature-a": "main",
+				}
+				mockGit.On("GetAllStackParents").Return(stackParents, nil).Times(3)
+				mockGit.On("GetConfig", "stack.baseBranch").Return("")
+				mockGit.On("GetDefaultBranch").Return("main")
+			},
+			expectError: false,
+		},
+		{
+			name: "interrupted sync is pointed out",
+			setupMocks: func(mockGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient) {
+				mockGit.On("GetOperationInProgress").Return(nil)
+				mockGit.On("GetConfig", "stack.sync.originalBranch").Return("feature-a")
+				mockGit.On("GetConfig", "stack.sync.startedAt").Return("1700000000")
+				mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main"}, nil).Times(3)
+				mockGit.On("GetConfig", "stack.baseBranch").Return("")
+				mockGit.On("GetDefaultBranch").Return("main")
+			},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -53,6 +87,7 @@ func TestRunStatus(t *testing.T) {
 			mockGH := new(testutil.MockGitHubClient)
 
 			tt.setupMocks(mockGit, mockGH)
+			testutil.AllowStackUmbrellas(mockGit)
 
 			// Set noPR to true to skip PR fetching in parallel goroutines
 			noPR = true
@@ -147,7 +182,7 @@ func TestDetectSyncIssues(t *testing.T) {
 			mockGit.On("GetDefaultBranch").Return("main").Maybe()
 
 			nopProgress := func(msg string) {} // No-op progress function
-			result, err := detectSyncIssues(mockGit, tt.stackBranches, tt.prCache, nopProgress, true)
+			result, err := detectSyncIssues(mockGit, tt.stackBranches, tt.prCache, nil, nopProgress, true)
 
 			assert.NoError(t, err)
 			assert.NotNil(t, result)
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+var statusAt string
+
+// statusAtLayouts are the date formats accepted by --at, besides commits
+var statusAtLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+// parseStatusAt reads --at as a date (local time), or as a commit whose committer
+// date is used
+func parseStatusAt(gitClient git.GitClient, value string) (time.Time, error) {
+	for _, layout := range statusAtLayouts {
+		if at, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return at, nil
+		}
+	}
+	at, err := gitClient.GetCommitTime(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("--at %s is neither a date (YYYY-MM-DD [HH:MM]) nor a commit", value)
+	}
+	return at, nil
+}
+
+// runStatusAt prints the stack with every branch at the commit it pointed to at the
+// given time, read from the branch reflogs. Stack parents and PRs have no history,
+// so the current parents are used and PRs aren't shown.
+func runStatusAt(gitClient git.GitClient, value string) error {
+	at, err := parseStatusAt(gitClient, value)
+	if err != nil {
+		return err
+	}
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	tree, err := stack.BuildStackTreeForBranch(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to build stack tree: %w", err)
+	}
+	// Outside a stack, show all of them
+	if tree == nil {
+		if tree, err = stack.BuildStackTree(gitClient); err != nil {
+			return fmt.Errorf("failed to build stack tree: %w", err)
+		}
+	}
+	if tree == nil {
+		fmt.Println("No stack branches found.")
+		return nil
+	}
+
+	fmt.Printf("Stack as of %s %s\n\n", at.Format("2006-01-02 15:04"), ui.Dim("(branch tips from the reflogs, with today's parents)"))
+	printTreeAt(gitClient, tree, "", at, currentBranch, false)
+	return nil
+}
+
+func printTreeAt(gitClient git.GitClient, node *stack.TreeNode, parentTip string, at time.Time, currentBranch string, isPipe bool) {
+	tip, err := gitClient.GetBranchTipAt(node.Name, at)
+	if err != nil {
+		tip = ""
+	}
+
+	state := ""
+	switch {
+	case tip == "":
+		state = " " + ui.Dim("(no reflog entry: not created yet, or expired)")
+	case parentTip != "":
+		if mergeBase, err := gitClient.GetMergeBase(tip, parentTip); err == nil && mergeBase != parentTip {
+			state = fmt.Sprintf(" %s needed a rebase onto its parent", ui.WarningIcon())
+		}
+	}
+	if tip != "" {
+		if current, err := gitClient.GetCommitHash(node.Name); err == nil && current != tip {
+			state += " " + ui.Dim("(moved since)")
+		}
+	}
+
+	marker := ""
+	if node.Name == currentBranch {
+		marker = ui.CurrentBranchMarker()
+	}
+
+	if isPipe {
+		fmt.Printf("  %s\n", ui.Pipe())
+	}
+	fmt.Printf(" %s %s%s%s\n", ui.Branch(node.Name), ui.Dim(ui.ShortSHA(tip)), state, marker)
+
+	for _, child := range node.Children {
+		printTreeAt(gitClient, child, tip, at, currentBranch, true)
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestParseStatusAt(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	commitTime := time.Unix(1700000000, 0)
+	mockGit.On("GetCommitTime", "abc123").Return(commitTime, nil)
+	mockGit.On("GetCommitTime", "yesterday").Return(time.Time{}, errors.New("bad revision"))
+
+	at, err := parseStatusAt(mockGit, "2024-05-06 14:30")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 5, 6, 14, 30, 0, 0, time.Local), at)
+
+	at, err = parseStatusAt(mockGit, "abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, commitTime, at)
+
+	_, err = parseStatusAt(mockGit, "yesterday")
+	assert.Error(t, err)
+}
+
+func TestRunStatusAt(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+	at := time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local)
+	mockGit.On("GetBranchTipAt", "main", at).Return("m1", nil)
+	mockGit.On("GetBranchTipAt", "feature-a", at).Return("a1", nil)
+	mockGit.On("GetBranchTipAt", "feature-b", at).Return("", nil)
+	mockGit.On("GetMergeBase", "a1", "m1").Return("m0", nil)
+	mockGit.On("GetCommitHash", mock.Anything).Return("now", nil)
+
+	err := runStatusAt(mockGit, "2024-05-06")
+
+	assert.NoError(t, err)
+	mockGit.AssertExpectations(t)
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+var (
+	// statusDepth is how many levels below the base status shows, 0 for all
+	statusDepth int
+	// statusExpand shows every branch, without folding
+	statusExpand bool
+	// statusAll shows every stack instead of the current branch's
+	statusAll bool
+)
+
+// statusLine is one line of the status tree: a branch, or a fold standing in for
+// several branches
+type statusLine struct {
+	Branch   string
+	Folded   []string // Branches behind a fold, empty for a branch line
+	Merged   bool     // The fold holds merged branches
+	Children []*statusLine
+}
+
+// treeFolder folds the parts of a status tree that would bury the rest: runs and
+// sub-trees of merged branches, and whatever is deeper than depth
+type treeFolder struct {
+	currentBranch  string
+	baseBranch     string
+	prCache        map[string]*github.PRInfo
+	depth          int
+	collapseMerged bool
+}
+
+// foldStatusTree turns a stack tree into the lines status prints
+func foldStatusTree(tree *stack.TreeNode, currentBranch, baseBranch string, prCache map[string]*github.PRInfo, depth int, expand bool) *statusLine {
+	f := &treeFolder{currentBranch: currentBranch, baseBranch: baseBranch, prCache: prCache}
+	if !expand {
+		f.depth = depth
+		f.collapseMerged = true
+	}
+	return f.fold(tree, 0)
+}
+
+func (f *treeFolder) fold(node *stack.TreeNode, level int) *statusLine {
+	// Past the depth limit, the rest of the sub-tree is one fold
+	if f.depth > 0 && level > f.depth {
+		return &statusLine{Folded: subtreeBranches(node)}
+	}
+
+	// Merged branches gather in one fold, up to the first one that isn't merged
+	if f.collapseMerged {
+		var run []string
+		rest := node
+		for rest != nil && f.merged(rest.Name) {
+			if f.allMerged(rest) {
+				run = append(run, subtreeBranches(rest)...)
+				rest = nil
+				break
+			}
+			if len(rest.Children) != 1 {
+				break
+			}
+			run = append(run, rest.Name)
+			rest = rest.Children[0]
+		}
+		if len(run) >= 2 {
+			line := &statusLine{Folded: run, Merged: true}
+			if rest != nil {
+				line.Children = []*statusLine{f.fold(rest, level+len(run))}
+			}
+			return line
+		}
+	}
+
+	line := &statusLine{Branch: node.Name}
+	for _, child := range node.Children {
+		line.Children = append(line.Children, f.fold(child, level+1))
+	}
+	return line
+}
+
+// merged reports whether a branch's PR is merged. The base and current branches
+// never are, so they stay in view.
+func (f *treeFolder) merged(branch string) bool {
+	if branch == f.currentBranch || branch == f.baseBranch {
+		return false
+	}
+	pr := f.prCache[branch]
+	return pr != nil && pr.State == "MERGED"
+}
+
+// allMerged reports whether every branch in the sub-tree is merged
+func (f *treeFolder) allMerged(node *stack.TreeNode) bool {
+	if !f.merged(node.Name) {
+		return false
+	}
+	for _, child := range node.Children {
+		if !f.allMerged(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// subtreeBranches returns the branches of a sub-tree, top down
+func subtreeBranches(node *stack.TreeNode) []string {
+	names := []string{node.Name}
+	for _, child := range node.Children {
+		names = append(names, subtreeBranches(child)...)
+	}
+	return names
+}
+
+// foldLabel describes what a fold hides
+func foldLabel(line *statusLine, currentBranch string) string {
+	if line.Merged {
+		names := strings.Join(line.Folded, ", ")
+		if len(line.Folded) > 2 {
+			names = line.Folded[0] + " … " + line.Folded[len(line.Folded)-1]
+		}
+		return fmt.Sprintf("… %d merged branches (%s)", len(line.Folded), names)
+	}
+	label := fmt.Sprintf("… %d more branch(es)", len(line.Folded))
+	for _, name := range line.Folded {
+		if name == currentBranch {
+			label += ", including " + name + " *"
+		}
+	}
+	return label
+}
+
+// statusPrinter prints the status tree
+type statusPrinter struct {
+	currentBranch string
+	baseBranch    string
+	prCache       map[string]*github.PRInfo
+	umbrellas     map[string]bool
+	folded        int
+}
+
+// label returns the text of a line
+func (p *statusPrinter) label(line *statusLine) string {
+	if len(line.Folded) > 0 {
+		p.folded += len(line.Folded)
+		return ui.Dim(foldLabel(line, p.currentBranch))
+	}
+	marker := ""
+	if line.Branch == p.currentBranch {
+		marker = ui.CurrentBranchMarker()
+	}
+	prInfo := ""
+	if line.Branch != p.baseBranch {
+		if pr, exists := p.prCache[line.Branch]; exists {
+			prInfo = fmt.Sprintf(" %s%s", ui.PRInfo(pr.URL, pr.State), syncer.MergeQueueLabel(pr))
+		}
+	}
+	return fmt.Sprintf("%s%s%s%s", ui.Branch(line.Branch), syncer.UmbrellaLabel(p.umbrellas, line.Branch), prInfo, marker)
+}
+
+// printVertical prints a stack without branches in it as a vertical list
+func (p *statusPrinter) printVertical(line *statusLine, isPipe bool) {
+	if isPipe {
+		fmt.Printf("  %s\n", ui.Pipe())
+	}
+	fmt.Printf(" %s\n", p.label(line))
+	for _, child := range line.Children {
+		p.printVertical(child, true)
+	}
+}
+
+// printBranched prints a tree with branches in it using tree connectors, so
+// siblings can be told apart from a parent and its child
+func (p *statusPrinter) printBranched(line *statusLine, prefix string) {
+	for i, child := range line.Children {
+		connector, next := "├─ ", "│  "
+		if i == len(line.Children)-1 {
+			connector, next = "└─ ", "   "
+		}
+		fmt.Printf(" %s%s%s\n", prefix, ui.Dim(connector), p.label(child))
+		p.printBranched(child, prefix+ui.Dim(next))
+	}
+}
+
+// branches reports whether any line has several children
+func (l *statusLine) branches() bool {
+	if len(l.Children) > 1 {
+		return true
+	}
+	for _, child := range l.Children {
+		if child.branches() {
+			return true
+		}
+	}
+	return false
+}
+
+// printStatusTree prints a stack tree with its long or merged parts folded, and
+// how to see them
+func printStatusTree(gitClient git.GitClient, tree *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, umbrellas map[string]bool) {
+	if tree == nil {
+		return
+	}
+	p := &statusPrinter{
+		currentBranch: currentBranch,
+		baseBranch:    stack.GetBaseBranch(gitClient),
+		prCache:       prCache,
+		umbrellas:     umbrellas,
+	}
+	lines := foldStatusTree(tree, currentBranch, p.baseBranch, prCache, statusDepth, statusExpand)
+	if lines.branches() {
+		fmt.Printf(" %s\n", p.label(lines))
+		p.printBranched(lines, "")
+	} else {
+		p.printVertical(lines, false)
+	}
+
+	if p.folded > 0 {
+		fmt.Printf("\n%d branch(es) folded, show them with '%s'\n", p.folded, ui.Command("stack status --expand"))
+	}
+}
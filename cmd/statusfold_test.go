@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFoldStatusTree(t *testing.T) {
+	// main
+	// ├── old-a → old-b → feature-a → feature-b
+	// └── spike → spike-tests
+	tree := &stack.TreeNode{Name: "main", Children: []*stack.TreeNode{
+		{Name: "old-a", Children: []*stack.TreeNode{
+			{Name: "old-b", Children: []*stack.TreeNode{
+				{Name: "feature-a", Children: []*stack.TreeNode{
+					{Name: "feature-b"},
+				}},
+			}},
+		}},
+		{Name: "spike", Children: []*stack.TreeNode{
+			{Name: "spike-tests"},
+		}},
+	}}
+	prCache := map[string]*github.PRInfo{
+		"old-a":       {Number: 1, State: "MERGED"},
+		"old-b":       {Number: 2, State: "MERGED"},
+		"feature-a":   {Number: 3, State: "OPEN"},
+		"spike":       {Number: 4, State: "MERGED"},
+		"spike-tests": {Number: 5, State: "MERGED"},
+	}
+
+	t.Run("folds merged runs and sub-trees", func(t *testing.T) {
+		lines := foldStatusTree(tree, "feature-b", "main", prCache, 0, false)
+
+		assert.Equal(t, "main", lines.Branch)
+		assert.Len(t, lines.Children, 2)
+		run := lines.Children[0]
+		assert.Equal(t, []string{"old-a", "old-b"}, run.Folded)
+		assert.True(t, run.Merged)
+		assert.Equal(t, "feature-a", run.Children[0].Branch)
+		assert.Equal(t, "feature-b", run.Children[0].Children[0].Branch)
+		assert.Equal(t, []string{"spike", "spike-tests"}, lines.Children[1].Folded)
+		assert.Empty(t, lines.Children[1].Children)
+	})
+
+	t.Run("keeps the current branch in view", func(t *testing.T) {
+		lines := foldStatusTree(tree, "spike-tests", "main", prCache, 0, false)
+
+		assert.Equal(t, "spike", lines.Children[1].Branch)
+		assert.Equal(t, "spike-tests", lines.Children[1].Children[0].Branch)
+	})
+
+	t.Run("folds what's below the depth", func(t *testing.T) {
+		lines := foldStatusTree(tree, "feature-b", "main", nil, 2, false)
+
+		old := lines.Children[0]
+		assert.Equal(t, "old-a", old.Branch)
+		assert.Equal(t, "old-b", old.Children[0].Branch)
+		folded := old.Children[0].Children[0]
+		assert.Equal(t, []string{"feature-a", "feature-b"}, folded.Folded)
+		assert.Equal(t, "… 2 more branch(es), including feature-b *", foldLabel(folded, "feature-b"))
+	})
+
+	t.Run("expands everything", func(t *testing.T) {
+		lines := foldStatusTree(tree, "feature-b", "main", prCache, 1, true)
+
+		assert.Equal(t, "old-a", lines.Children[0].Branch)
+		assert.Equal(t, "spike-tests", lines.Children[1].Children[0].Branch)
+	})
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/javoire/stackinator/internal/forge"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// submitDraft opens the PRs created by submit as drafts
+	submitDraft bool
+	// submitPRNav keeps a table of the stack in every PR description
+	submitPRNav bool
+)
+
+var submitCmd = &cobra.Command{
+	Use:   "submit",
+	Short: i18n.T("submit.short"),
+	Long:  i18n.T("submit.long"),
+	Example: `  # Open PRs for the whole stack
+  stack submit
+
+  # Open them as drafts
+  stack submit --draft
+
+  # Add a table of the stack to every PR description
+  stack submit --pr-nav
+
+  # Number the PRs in their titles ("[2/5] ...")
+  stack submit --pr-position title
+
+  # Preview what would be pushed and opened
+  stack submit --dry-run`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
+
+		if !cmd.Flags().Changed("pr-nav") {
+			submitPRNav = gitClient.GetConfig(configPRNav) == "true"
+		}
+		if err := readPRPosition(cmd, gitClient); err != nil {
+			exitWithError(err)
+		}
+		if !noSizeGuard {
+			limits, err := readSizeLimits(gitClient)
+			if err != nil {
+				exitWithError(err)
+			}
+			sizeGuardLimits = limits
+		}
+		if err := runSubmit(gitClient, githubClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	submitCmd.Flags().BoolVar(&submitDraft, "draft", false, "Open the PRs as drafts")
+	submitCmd.Flags().BoolVar(&submitPRNav, "pr-nav", false, "Keep a table of the stack, linking each PR, in every PR description")
+	submitCmd.Flags().StringVar(&prPosition, "pr-position", "", "Mark each PR with its place in the stack: label (stack:2/5), title ([2/5] prefix), both or off")
+	submitCmd.Flags().BoolVar(&noSizeGuard, "no-size-guard", false, "Skip the branch size check (stack.sizeGuard)")
+}
+
+func runSubmit(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) < 2 {
+		return &stack.NotInStackError{Branch: currentBranch}
+	}
+	branches := chain[1:]
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	var prs map[string]*github.PRInfo
+	if err := spinner.WrapWithAutoDelay("Loading PRs...", 300*time.Millisecond, func() error {
+		var err error
+		prs, err = syncer.LoadPRs(githubClient, branches, prScope)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to load PRs: %w", err)
+	}
+	remoteBranches := gitClient.GetRemoteBranchesSet()
+
+	// Oversized branches are flagged before their PRs are opened
+	if sizeGuardLimits.Enabled() {
+		layers := make([]stack.StackBranch, 0, len(branches))
+		for _, branch := range branches {
+			layers = append(layers, stack.StackBranch{Name: branch, Parent: parents[branch]})
+		}
+		if err := syncer.GuardStackSize(gitClient, sizeGuardLimits, layers, prs, umbrellas, chain[0], "stack submit --no-size-guard"); err != nil {
+			return err
+		}
+	}
+
+	created, open := 0, 0
+	for i, branch := range branches {
+		fmt.Printf("%s %s\n", ui.Progress(i+1, len(branches)), ui.Branch(branch))
+
+		if umbrellas[branch] {
+			fmt.Printf("  Skipping umbrella branch (never pushed, no PR)\n\n")
+			continue
+		}
+		pr := prs[branch]
+		if pr != nil && pr.State == "MERGED" {
+			fmt.Printf("  %s\n\n", i18n.T("submit.prNotOpen", pr.Number, ui.PRState(pr.State), ui.Command("stack sync")))
+			continue
+		}
+
+		if err := pushForSubmit(gitClient, branch, remoteBranches[branch]); err != nil {
+			return err
+		}
+
+		if pr != nil && pr.State == "OPEN" {
+			fmt.Printf("  %s PR #%d is already open: %s\n\n", ui.SuccessIcon(), pr.Number, pr.URL)
+			open++
+			continue
+		}
+
+		// A closed PR is replaced by a new one
+		base := syncer.PRBaseFor(parents[branch], parents, umbrellas)
+		if flatPRs {
+			base = stack.GetBaseBranch(gitClient)
+		}
+		syncer.CreatePR(gitClient, githubClient, branch, base, submitDraft, prs)
+		if prs[branch] != pr {
+			created++
+		}
+		fmt.Println()
+	}
+
+	if submitPRNav {
+		syncer.UpdateStackNav(gitClient, githubClient, currentBranch, prScope)
+		fmt.Println()
+	}
+	if prPositionMode.Enabled() {
+		syncer.UpdateStackPositions(gitClient, githubClient, currentBranch, prPositionMode, prScope)
+		fmt.Println()
+	}
+
+	switch {
+	case dryRun:
+		fmt.Println("Dry run, nothing was pushed or opened")
+	case created > 0:
+		fmt.Println(ui.Success(fmt.Sprintf("Opened %d PR(s), %d already open", created, open)))
+	default:
+		fmt.Printf("No PRs opened, %d already open\n", open)
+	}
+	return nil
+}
+
+// pushForSubmit publishes branch, or the commits it has that origin doesn't. A
+// branch that diverged from origin is left for 'stack sync' to force-push.
+func pushForSubmit(gitClient git.GitClient, branch string, onOrigin bool) error {
+	if onOrigin {
+		local, err := gitClient.GetCommitHash(branch)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", branch, err)
+		}
+		remote, err := gitClient.GetCommitHash(git.RemoteRef(branch))
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", git.RemoteRef(branch), err)
+		}
+		if local == remote {
+			return nil
+		}
+		if mergeBase, err := gitClient.GetMergeBase(branch, git.RemoteRef(branch)); err != nil || mergeBase != remote {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", ui.WarningIcon(), i18n.T("submit.differs", git.RemoteRef(branch), ui.Command("stack sync")))
+			return nil
+		}
+	}
+
+	if err := spinner.WrapWithSuccessIndented("  ", fmt.Sprintf("Pushing to %s...", git.RemoteOf(branch)), fmt.Sprintf("Pushed to %s", git.RemoteOf(branch)), func() error {
+		return gitClient.Push(branch, false)
+	}); err != nil {
+		return fmt.Errorf("push failed for %s: %w", branch, err)
+	}
+	return nil
+}
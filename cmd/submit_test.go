@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRunSubmit(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}
+
+	t.Run("pushes new branches and opens PRs for branches without one", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b", "feature-c"}).Return(map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "A", "https://github.com/o/r/pull/1"),
+		}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "feature-a": true, "feature-b": true})
+		// feature-a is in sync with origin
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa", nil)
+		// feature-b is ahead of origin
+		mockGit.On("GetCommitHash", "feature-b").Return("bbb2", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("bbb1", nil)
+		mockGit.On("GetMergeBase", "feature-b", "origin/feature-b").Return("bbb1", nil)
+		mockGit.On("Push", "feature-b", false).Return(nil)
+		// feature-c isn't on origin
+		mockGit.On("Push", "feature-c", false).Return(nil)
+		mockGit.On("GetConfig", mock.Anything).Return("")
+		mockGH.On("CreatePR", "feature-b", "feature-a", false, "").Return(testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url2"), nil)
+		mockGH.On("CreatePR", "feature-c", "feature-b", false, "").Return(testutil.NewPRInfo(3, "OPEN", "feature-b", "C", "url3"), nil)
+
+		err := runSubmit(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "Push", "feature-a", mock.Anything)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("opens drafts and leaves diverged branches to sync", func(t *testing.T) {
+		submitDraft = true
+		defer func() { submitDraft = false }()
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRsForBranches", []string{"feature-a"}).Return(map[string]*github.PRInfo{}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"feature-a": true})
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa2", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa1", nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/feature-a").Return("base", nil)
+		mockGit.On("GetConfig", "branch.feature-a.description").Return("")
+		mockGH.On("CreatePR", "feature-a", "main", true, "").Return(testutil.NewPRInfo(4, "OPEN", "main", "A", "url4"), nil)
+
+		err := runSubmit(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "Push", mock.Anything, mock.Anything)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("fails when a push fails", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+		mockGH.On("GetPRsForBranches", []string{"feature-a"}).Return(map[string]*github.PRInfo{}, nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{})
+		mockGit.On("Push", "feature-a", false).Return(errors.New("rejected"))
+
+		err := runSubmit(mockGit, mockGH)
+
+		assert.ErrorContains(t, err, "push failed for feature-a")
+		mockGH.AssertNotCalled(t, "CreatePR", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not in a stack", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCurrentBranch").Return("main", nil)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+
+		err := runSubmit(mockGit, mockGH)
+
+		var notInStack *stack.NotInStackError
+		assert.ErrorAs(t, err, &notInStack)
+	})
+}
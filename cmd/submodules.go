@@ -0,0 +1,11 @@
+package cmd
+
+import (
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+)
+
+// configureSubmodules reads whether checkouts should update submodules
+func configureSubmodules(gitClient git.GitClient) {
+	git.AutoUpdateSubmodules = gitClient.GetConfig(syncer.ConfigSubmodulesUpdate) == "true"
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureSubmodules(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { git.AutoUpdateSubmodules = false }()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetConfig", syncer.ConfigSubmodulesUpdate).Return("true").Once()
+	mockGit.On("GetConfig", syncer.ConfigSubmodulesUpdate).Return("").Once()
+
+	configureSubmodules(mockGit)
+	assert.True(t, git.AutoUpdateSubmodules)
+
+	configureSubmodules(mockGit)
+	assert.False(t, git.AutoUpdateSubmodules)
+	mockGit.AssertExpectations(t)
+}
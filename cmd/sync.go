@@ -1,55 +1,84 @@
 package cmd
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io"
 	"os"
-	"strings"
-	"sync"
+	"time"
 
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
-	"github.com/javoire/stackinator/internal/spinner"
-	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/syncer"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
-// errAlreadyPrinted is a sentinel error indicating the error message was already displayed
-var errAlreadyPrinted = errors.New("")
-
 var (
 	syncForce      bool
 	syncResume     bool
 	syncAbort      bool
 	syncCherryPick bool
+	// syncDetectMergedByPatch enables patch-id based merge detection for branches
+	// whose PR record is missing (e.g. squash-merged from another remote)
+	syncDetectMergedByPatch bool
+	// syncPruneMerged removes merged branches from stack tracking (on by default)
+	syncPruneMerged bool
+	// syncDeleteMerged deletes merged branches (and their worktrees) once their children are restacked
+	syncDeleteMerged bool
+	// syncReviewComment posts a "what changed" comment on reviewed PRs after pushing
+	// ("summary" or "range-diff", empty to disable)
+	syncReviewComment string
+	// syncProtectApprovals refuses pushes that change the content of approved PRs
+	syncProtectApprovals bool
+	// syncAllowReviewInvalidation lets protected pushes through without asking
+	syncAllowReviewInvalidation bool
+	// syncCreatePRs opens a PR against the parent for branches that don't have one
+	syncCreatePRs bool
+	// syncDraftPRs opens those PRs as drafts (on by default)
+	syncDraftPRs bool
+	// syncPRNav keeps a table of the stack in every PR description
+	syncPRNav bool
+	// syncFull walks the whole stack even when nothing changed since the last sync
+	syncFull bool
+	// syncVerify checks the result once the sync is done
+	syncVerify bool
 	// stdinReader allows tests to inject mock input for prompts
 	stdinReader io.Reader = os.Stdin
 )
 
-// Git config keys for sync state persistence
-const (
-	configSyncStashed        = "stack.sync.stashed"
-	configSyncOriginalBranch = "stack.sync.originalBranch"
-)
+// configDetectMergedByPatch enables --detect-merged-by-patch by default for a repo
+const configDetectMergedByPatch = "stack.detectMergedByPatch"
 
-var syncCmd = &cobra.Command{
-	Use:   "sync",
-	Short: "Sync all stack branches with their parents and update PRs",
-	Long: `Perform a full sync of the stack:
-  1. Fetch latest changes from origin
-  2. Rebase each stack branch onto its parent (in bottom-to-top order)
-  3. Force push each branch to origin
-  4. Update PR base branches to match the stack (if PRs exist)
+// configPruneMerged can be set to "false" to keep tracking merged branches by default
+const configPruneMerged = "stack.sync.pruneMerged"
+
+// configDeleteMerged enables --delete-merged by default for a repo
+const configDeleteMerged = "stack.sync.deleteMerged"
+
+// configReviewComment sets the default for --review-comment
+const configReviewComment = "stack.sync.reviewComment"
 
-This ensures your stack is up-to-date and all PRs have the correct base branches.
+// configProtectApprovals enables the approved-PR push guard
+const configProtectApprovals = "stack.sync.protectApprovals"
 
-If a parent PR has been merged, the child branches will be rebased to point to
-the merged parent's parent.
+// configCreatePRs enables --create-prs by default for a repo
+const configCreatePRs = "stack.sync.createPRs"
 
-Uncommitted changes are automatically stashed and reapplied (using --autostash).`,
+// configDraftPRs can be set to "false" to open PRs created by sync as ready for review
+const configDraftPRs = "stack.sync.draftPRs"
+
+// configPRNav keeps a table of the stack in every PR description ("true"), as
+// --pr-nav does for sync and submit
+const configPRNav = "stack.prNav"
+
+var syncCmd = &cobra.Command{
+	Use:               "sync",
+	Short:             i18n.T("sync.short"),
+	ValidArgsFunction: completeSyncRecovery,
+	Long:              i18n.T("sync.long"),
 	Example: `  # Sync all branches and update PRs
   stack sync
 
@@ -68,932 +97,213 @@ Uncommitted changes are automatically stashed and reapplied (using --autostash).
   # Abort an interrupted sync
   stack sync --abort
 
-  # Common workflow after updating main
-  git checkout main && git pull
-  stack sync`,
-	Run: func(cmd *cobra.Command, args []string) {
-		gitClient := git.NewGitClient()
-		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL("origin"))
-		githubClient := github.NewGitHubClient(repo)
-
-		if err := runSync(gitClient, githubClient); err != nil {
-			// Don't print if error was already displayed with detailed message
-			if !errors.Is(err, errAlreadyPrinted) {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			}
-			os.Exit(1)
-		}
-	},
-}
-
-func init() {
-	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "Use --force instead of --force-with-lease for push (bypasses safety checks)")
-	syncCmd.Flags().BoolVarP(&syncResume, "resume", "r", false, "Resume a sync after resolving rebase conflicts")
-	syncCmd.Flags().BoolVarP(&syncAbort, "abort", "a", false, "Abort an interrupted sync and clean up state")
-	syncCmd.Flags().BoolVar(&syncCherryPick, "cherry-pick", false, "Rebuild polluted branches by cherry-picking unique commits (creates backup)")
-}
+  # Treat branches whose changes already landed on the base branch as merged,
+  # even when no merged PR can be found
+  stack sync --detect-merged-by-patch
 
-func runSync(gitClient git.GitClient, githubClient github.GitHubClient) error {
-	// Track state for stash handling
-	var originalBranch string
-	stashed := false
-	rebaseConflict := false
-
-	// Check for existing sync state (from previous interrupted sync)
-	savedStashed := gitClient.GetConfig(configSyncStashed)
-	savedOriginalBranch := gitClient.GetConfig(configSyncOriginalBranch)
-	hasSavedState := savedStashed == "true" || savedOriginalBranch != ""
-
-	if syncAbort {
-		// Check if there's actually anything to abort
-		hasCherryPick := gitClient.IsCherryPickInProgress()
-		hasRebase := gitClient.IsRebaseInProgress()
-
-		if !hasSavedState && !hasCherryPick && !hasRebase {
-			return fmt.Errorf("no interrupted sync to abort\n\nUse 'stack sync' to start a new sync")
-		}
+  # Treat branches whose PR was closed after a fast-forward push to the base
+  # branch as merged
+  stack sync --detect-merged-by-commit
 
-		fmt.Println("Aborting sync and cleaning up...")
-		fmt.Println()
+  # Skip merged branches but keep them in stack tracking
+  stack sync --prune-merged=false
 
-		// Abort cherry-pick if one is in progress
-		if hasCherryPick {
-			if err := gitClient.AbortCherryPick(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to abort cherry-pick: %v\n", err)
-			} else {
-				fmt.Println(ui.Success("Aborted cherry-pick"))
-			}
-		} else if git.Verbose {
-			fmt.Fprintf(os.Stderr, "Note: no cherry-pick in progress\n")
-		}
+  # Also delete merged branches and their worktrees locally
+  stack sync --delete-merged
 
-		// Abort rebase if one is in progress
-		if hasRebase {
-			if err := gitClient.AbortRebase(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to abort rebase: %v\n", err)
-			} else {
-				fmt.Println(ui.Success("Aborted rebase"))
-			}
-		} else if git.Verbose {
-			fmt.Fprintf(os.Stderr, "Note: no rebase in progress\n")
-		}
+  # Tell reviewers whether a force-push changed anything
+  stack sync --review-comment=summary
 
-		// Restore stashed changes if any
-		if savedStashed == "true" {
-			fmt.Println("Restoring stashed changes...")
-			if err := gitClient.StashPop(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
-				fmt.Fprintf(os.Stderr, "Run '%s' manually to restore your changes\n", ui.Command("git stash pop"))
-			} else {
-				fmt.Println(ui.Success("Restored stashed changes"))
-			}
-		}
+  # Publish new branches and open draft PRs for them
+  stack sync --create-prs
 
-		// Return to original branch if we have one saved
-		if savedOriginalBranch != "" {
-			currentBranch, err := gitClient.GetCurrentBranch()
-			if err == nil && currentBranch != savedOriginalBranch {
-				fmt.Printf("Returning to %s...\n", ui.Branch(savedOriginalBranch))
-				if err := gitClient.CheckoutBranch(savedOriginalBranch); err != nil {
-					fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
-				} else {
-					fmt.Println(ui.Success(fmt.Sprintf("Returned to %s", ui.Branch(savedOriginalBranch))))
-				}
-			}
-		}
+  # Only push a branch once the checks of the branch below it pass
+  stack sync --wait-for-ci
 
-		// Clean up sync state
-		_ = gitClient.UnsetConfig(configSyncStashed)
-		_ = gitClient.UnsetConfig(configSyncOriginalBranch)
+  # Request CODEOWNERS reviewers for each layer's own changes
+  stack sync --request-reviewers
 
-		fmt.Println()
-		fmt.Println(ui.Success("Sync aborted and state cleaned up"))
-		return nil
-	}
+  # Keep a table of the stack in every PR description
+  stack sync --pr-nav
 
-	if syncResume {
-		// Resuming after conflict resolution
-		if !hasSavedState {
-			return fmt.Errorf("no interrupted sync to resume\n\nUse 'stack sync' to start a new sync")
-		}
-		stashed = true
-		originalBranch = savedOriginalBranch
-		fmt.Println("Resuming sync...")
-		fmt.Println()
-	} else {
-		// Starting a fresh sync
-		if hasSavedState {
-			fmt.Fprintf(os.Stderr, "Warning: found state from a previous interrupted sync\n")
-			fmt.Fprintf(os.Stderr, "If you resolved rebase conflicts, run 'stack sync --resume'\n")
-			fmt.Fprintf(os.Stderr, "\nStart fresh? [y/N] ")
-
-			reader := bufio.NewReader(stdinReader)
-			input, err := reader.ReadString('\n')
-			if err != nil {
-				return fmt.Errorf("failed to read input: %w", err)
-			}
+  # Have the sync approved before running it
+  stack sync --plan --json > plan.json
+  stack sync --apply plan.json
 
-			input = strings.TrimSpace(strings.ToLower(input))
-			if input != "y" && input != "yes" {
-				fmt.Println("Aborted. Use 'stack sync --resume' or 'stack sync --abort' to handle the interrupted sync.")
-				return nil
-			}
+  # Common workflow after updating main
+  git checkout main && git pull
+  stack sync`,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
 
-			fmt.Println("Cleaning up stale state and starting fresh...")
-			fmt.Println()
-			// Clean up stale state
-			_ = gitClient.UnsetConfig(configSyncStashed)
-			_ = gitClient.UnsetConfig(configSyncOriginalBranch)
+		// Fall back to the per-repo setting when the flag wasn't given explicitly
+		if !cmd.Flags().Changed("detect-merged-by-patch") {
+			syncDetectMergedByPatch = gitClient.GetConfig(configDetectMergedByPatch) == "true"
 		}
-
-		// Get current branch so we can return to it
-		var err error
-		originalBranch, err = gitClient.GetCurrentBranch()
-		if err != nil {
-			return fmt.Errorf("failed to get current branch: %w", err)
+		readDetectMergedByCommit(cmd, gitClient)
+		if !cmd.Flags().Changed("prune-merged") {
+			syncPruneMerged = gitClient.GetConfig(configPruneMerged) != "false"
 		}
-
-		// Save original branch state for potential --abort
-		if err := gitClient.SetConfig(configSyncOriginalBranch, originalBranch); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to save sync state: %v\n", err)
+		if !cmd.Flags().Changed("delete-merged") {
+			syncDeleteMerged = gitClient.GetConfig(configDeleteMerged) == "true"
 		}
-
-		// Check if working tree is clean and stash if needed
-		clean, err := gitClient.IsWorkingTreeClean()
-		if err != nil {
-			return fmt.Errorf("failed to check working tree status: %w", err)
+		if !cmd.Flags().Changed("create-prs") {
+			syncCreatePRs = gitClient.GetConfig(configCreatePRs) == "true"
 		}
-
-		if !clean {
-			fmt.Println("Stashing uncommitted changes...")
-			if err := gitClient.Stash("stack-sync-autostash"); err != nil {
-				return fmt.Errorf("failed to stash changes: %w", err)
-			}
-			stashed = true
-
-			// Mark that we stashed changes
-			if err := gitClient.SetConfig(configSyncStashed, "true"); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to save sync state: %v\n", err)
-			}
-
-			fmt.Println()
+		if !cmd.Flags().Changed("draft") {
+			syncDraftPRs = gitClient.GetConfig(configDraftPRs) != "false"
 		}
-	}
-
-	// Track if we complete successfully
-	success := false
-
-	// Ensure stash is popped on error (if we don't complete successfully)
-	// But NOT if we hit a rebase conflict - user needs to resolve and --resume
-	defer func() {
-		if stashed && !success && !rebaseConflict {
-			fmt.Println("\nRestoring stashed changes...")
-			if err := gitClient.StashPop(); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
-				fmt.Fprintf(os.Stderr, "Run 'git stash pop' manually to restore your changes\n")
-			}
-			// Clean up sync state since we're restoring the stash
-			_ = gitClient.UnsetConfig(configSyncStashed)
-			_ = gitClient.UnsetConfig(configSyncOriginalBranch)
+		if !cmd.Flags().Changed("pr-nav") {
+			syncPRNav = gitClient.GetConfig(configPRNav) == "true"
 		}
-	}()
-
-	// Check if current branch is in a stack BEFORE doing any network operations
-	// This allows us to prompt the user immediately if needed
-	baseBranch := stack.GetBaseBranch(gitClient)
-	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", originalBranch))
-
-	if parent == "" && originalBranch != baseBranch {
-		fmt.Printf("Branch '%s' is not in a stack.\n", ui.Branch(originalBranch))
-		fmt.Printf("Add it with parent '%s'? [Y/n] ", ui.Branch(baseBranch))
-
-		reader := bufio.NewReader(stdinReader)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+		if !cmd.Flags().Changed("request-reviewers") {
+			syncRequestReviewers = gitClient.GetConfig(configRequestReviewers) == "true"
 		}
-
-		input = strings.TrimSpace(strings.ToLower(input))
-		if input != "" && input != "y" && input != "yes" {
-			fmt.Println("Aborted.")
-			return nil
+		if !cmd.Flags().Changed("wait-for-ci") {
+			syncWaitForCI = gitClient.GetConfig(configWaitForCI) == "true"
 		}
-
-		// Set the parent
-		configKey := fmt.Sprintf("branch.%s.stackparent", originalBranch)
-		if err := gitClient.SetConfig(configKey, baseBranch); err != nil {
-			return fmt.Errorf("failed to set parent: %w", err)
+		if !cmd.Flags().Changed("ci-timeout") {
+			if value := gitClient.GetConfig(configCITimeout); value != "" {
+				timeout, err := time.ParseDuration(value)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, i18n.T("error", fmt.Errorf("invalid %s %q: %w", configCITimeout, value, err)))
+					os.Exit(1)
+				}
+				syncCITimeout = timeout
+			}
 		}
-		fmt.Println(ui.Success(fmt.Sprintf("Added '%s' to stack with parent '%s'", ui.Branch(originalBranch), ui.Branch(baseBranch))))
-	}
-
-	// Start parallel fetch operations (git fetch and GitHub PR fetch)
-	// These are the slowest operations and have no dependencies between them
-	var wg sync.WaitGroup
-	var fetchErr error
-	var prCache map[string]*github.PRInfo
-	var prErr error
-
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		fetchErr = gitClient.Fetch()
-	}()
-	go func() {
-		defer wg.Done()
-		prCache, prErr = githubClient.GetAllPRs()
-	}()
-
-	// While network operations run in background, do local work
-	// Get only branches in the current branch's stack
-	chain, err := stack.GetStackChain(gitClient, originalBranch)
-	if err != nil {
-		return fmt.Errorf("failed to get stack chain: %w", err)
-	}
-
-	if len(chain) == 0 {
-		// Wait for parallel operations before returning
-		wg.Wait()
-		fmt.Println("No stack branches found.")
-		return nil
-	}
-
-	// Build set of branches in current stack
-	chainSet := make(map[string]bool)
-	for _, b := range chain {
-		chainSet[b] = true
-	}
-
-	// Get all stack branches and filter to current stack only
-	allStackBranches, err := stack.GetStackBranches(gitClient)
-	if err != nil {
-		return fmt.Errorf("failed to get stack branches: %w", err)
-	}
-
-	var stackBranches []stack.StackBranch
-	for _, b := range allStackBranches {
-		if chainSet[b.Name] {
-			stackBranches = append(stackBranches, b)
+		if !cmd.Flags().Changed("review-comment") {
+			syncReviewComment = gitClient.GetConfig(configReviewComment)
 		}
-	}
-
-	// Detect branches in chain that don't have stackparent configured
-	// and auto-configure them with inferred parents
-	existingBranchNames := make(map[string]bool)
-	for _, b := range stackBranches {
-		existingBranchNames[b.Name] = true
-	}
-
-	// Walk the chain and add missing branches
-	for i, branchName := range chain {
-		if branchName == baseBranch {
-			continue // Skip base branch
+		if syncReviewComment != "" && syncReviewComment != "summary" && syncReviewComment != "range-diff" {
+			fmt.Fprintln(os.Stderr, i18n.T("error", fmt.Errorf("invalid --review-comment %q (use summary or range-diff)", syncReviewComment)))
+			os.Exit(1)
 		}
-		if existingBranchNames[branchName] {
-			continue // Already in stackBranches
+		if err := readPRScope(cmd, gitClient); err != nil {
+			exitWithError(err)
 		}
-
-		// Infer parent from chain (previous branch in the chain)
-		var inferredParent string
-		if i > 0 {
-			inferredParent = chain[i-1]
-		} else {
-			inferredParent = baseBranch
+		if err := readPRPosition(cmd, gitClient); err != nil {
+			exitWithError(err)
 		}
-
-		// Check if branch exists locally before adding
-		if gitClient.BranchExists(branchName) {
-			stackBranches = append(stackBranches, stack.StackBranch{
-				Name:   branchName,
-				Parent: inferredParent,
-			})
-			existingBranchNames[branchName] = true
-
-			// Configure stackparent so future syncs work correctly
-			configKey := fmt.Sprintf("branch.%s.stackparent", branchName)
-			if err := gitClient.SetConfig(configKey, inferredParent); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to set stackparent for %s: %v\n", branchName, err)
-			} else {
-				fmt.Printf("Auto-configured %s with parent %s\n", branchName, inferredParent)
-			}
+		syncProtectApprovals = gitClient.GetConfig(configProtectApprovals) == "true"
+		strategy, err := parsePushStrategy(gitClient.GetConfig(configPushStrategy))
+		if err != nil {
+			exitWithError(err)
 		}
-	}
-
-	// Sort branches in topological order (bottom to top)
-	sorted, err := stack.TopologicalSort(stackBranches)
-	if err != nil {
-		return fmt.Errorf("failed to sort branches: %w", err)
-	}
-
-	// Check if any branches in the current stack are in worktrees
-	worktrees, err := gitClient.GetWorktreeBranches()
-	if err != nil {
-		// Non-fatal, continue without worktree detection
-		worktrees = make(map[string]string)
-	}
-
-	// Get current worktree path to check if we're already in the right place
-	currentWorktreePath, err := gitClient.GetCurrentWorktreePath()
-	if err != nil {
-		// Non-fatal, continue without worktree path detection
-		currentWorktreePath = ""
-	}
-
-	for _, branch := range sorted {
-		if worktreePath, inWorktree := worktrees[branch.Name]; inWorktree {
-			// Only error if we're NOT already in this worktree
-			if currentWorktreePath != worktreePath {
-				return fmt.Errorf(
-					"cannot sync: branch '%s' is checked out in worktree at %s\n\n"+
-						"To sync this stack:\n"+
-						"  1. cd %s\n"+
-						"  2. stack sync\n\n"+
-						"Or remove the worktree: git worktree remove %s",
-					branch.Name,
-					worktreePath,
-					worktreePath,
-					worktreePath,
-				)
-			}
+		syncPushStrategy = strategy
+		if syncForcePatterns, err = parseBranchPatterns(gitClient.GetConfig(configPushForceBranches)); err != nil {
+			exitWithError(err)
 		}
-	}
-
-	// Wait for parallel network operations to complete
-	if err := spinner.WrapWithSuccess("Fetching from origin and loading PRs...", "Fetched from origin and loaded PRs", func() error {
-		wg.Wait()
-		return nil
-	}); err != nil {
-		return err
-	}
-
-	// Check for fetch errors
-	if fetchErr != nil {
-		return fmt.Errorf("failed to fetch: %w", fetchErr)
-	}
-
-	// Handle PR fetch errors gracefully
-	if prErr != nil {
-		prCache = make(map[string]*github.PRInfo)
-	}
-
-	// GetAllPRs only fetches open PRs (to avoid 502 timeouts on large repos).
-	// For branches in our stack that aren't in the cache, check individually
-	// to detect merged PRs that need special handling.
-	for _, branch := range sorted {
-		// Skip if already in cache (has open PR)
-		if _, exists := prCache[branch.Name]; exists {
-			continue
+		if !syncNoLint {
+			syncCommitLint = gitClient.GetConfig(configCommitLint)
 		}
-		// Fetch PR info for this branch (might be merged or non-existent)
-		if pr, err := githubClient.GetPRForBranch(branch.Name); err == nil && pr != nil {
-			prCache[branch.Name] = pr
+		if syncCommitLintWarn, err = parseCommitLintMode(gitClient.GetConfig(configCommitLintMode)); err != nil {
+			exitWithError(err)
 		}
-		// Also check parent if not in cache
-		if _, exists := prCache[branch.Parent]; !exists {
-			if pr, err := githubClient.GetPRForBranch(branch.Parent); err == nil && pr != nil {
-				prCache[branch.Parent] = pr
-			}
+		if syncDCO, err = parseDCOMode(gitClient.GetConfig(configDCO)); err != nil {
+			exitWithError(err)
 		}
-	}
-
-	// Get all remote branches in one call (more efficient than checking each branch individually)
-	remoteBranches := gitClient.GetRemoteBranchesSet()
-
-	fmt.Printf("Processing %d branch(es)...\n\n", len(sorted))
-
-	// Build a set of stack branch names for quick lookup
-	stackBranchSet := make(map[string]bool)
-	for _, sb := range stackBranches {
-		stackBranchSet[sb.Name] = true
-	}
-
-	// Process each branch
-	for i, branch := range sorted {
-		progress := ui.Progress(i+1, len(sorted))
-
-		// Check if this branch has a merged PR - if so, remove from stack tracking
-		if pr, exists := prCache[branch.Name]; exists && pr.State == "MERGED" {
-			fmt.Printf("%s Skipping %s (PR #%d is %s)...\n", progress, ui.Branch(branch.Name), pr.Number, ui.PRState(pr.State))
-			fmt.Printf("  Removing from stack tracking...\n")
-			configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
-			if err := gitClient.UnsetConfig(configKey); err != nil {
-				fmt.Fprintf(os.Stderr, "  Warning: failed to remove stack config: %v\n", err)
-			} else {
-				fmt.Printf("  %s Removed. You can delete this branch with: %s\n", ui.SuccessIcon(), ui.Command(fmt.Sprintf("git branch -d %s", branch.Name)))
+		if !noSizeGuard {
+			if sizeGuardLimits, err = readSizeLimits(gitClient); err != nil {
+				exitWithError(err)
 			}
-			fmt.Println()
-			continue
 		}
-
-		fmt.Printf("%s Processing %s...\n", progress, ui.Branch(branch.Name))
-
-		// Check if parent PR is merged
-		oldParent := "" // Track old parent for --onto rebase
-		parentPR := prCache[branch.Parent]
-		if parentPR != nil && parentPR.State == "MERGED" {
-			fmt.Printf("  Parent PR #%d has been merged\n", parentPR.Number)
-
-			// Save old parent for --onto rebase
-			oldParent = branch.Parent
-
-			// Update parent to grandparent
-			grandparent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", branch.Parent))
-			if grandparent == "" {
-				grandparent = stack.GetBaseBranch(gitClient)
-			}
-
-			fmt.Printf("  %s Updated parent from %s to %s\n", ui.SuccessIcon(), ui.Branch(branch.Parent), ui.Branch(grandparent))
-			configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
-			if err := gitClient.SetConfig(configKey, grandparent); err != nil {
-				fmt.Fprintf(os.Stderr, "  Warning: failed to update parent config: %v\n", err)
-			} else {
-				branch.Parent = grandparent
-			}
+		if syncDeleteMerged && !syncPruneMerged {
+			fmt.Fprintln(os.Stderr, i18n.T("error", errors.New("--delete-merged can't be combined with --prune-merged=false")))
+			os.Exit(1)
 		}
 
-		// Checkout the branch
-		if err := gitClient.CheckoutBranch(branch.Name); err != nil {
-			return fmt.Errorf("failed to checkout %s: %w", branch.Name, err)
+		if syncPlanJSON && !syncPlanOnly {
+			fmt.Fprintln(os.Stderr, i18n.T("error", errors.New("--json only applies to --plan")))
+			os.Exit(1)
 		}
-
-		// Sync with remote branch if it exists (unless --force is set)
-		remoteBranch := "origin/" + branch.Name
-		// Check if we have a local tracking ref for the remote branch
-		hasLocalRef := remoteBranches[branch.Name]
-		// Branch exists on remote if we have local tracking ref OR if there's a PR for it
-		// (PR existence proves branch is on origin, even if local ref is missing)
-		branchExistsOnRemote := hasLocalRef || prCache[branch.Name] != nil
-
-		// If branch is on remote but we don't have the local tracking ref, fetch it
-		if branchExistsOnRemote && !hasLocalRef {
-			if git.Verbose {
-				fmt.Printf("  Fetching remote branch (local tracking ref missing)...\n")
+		// Describe the sync for approval instead of running it
+		if syncPlanOnly {
+			// Keep color codes out of the JSON plan
+			if syncPlanJSON {
+				ui.SetNoColor(true)
 			}
-			if err := gitClient.FetchBranch(branch.Name); err != nil {
-				// If fetch fails, the branch might have been deleted on remote
-				// Fall back to treating it as a new branch
-				if git.Verbose {
-					fmt.Printf("  Could not fetch remote branch, treating as new branch\n")
-				}
-				branchExistsOnRemote = false
+			if err := runSyncPlan(gitClient, githubClient); err != nil {
+				exitWithError(err)
 			}
+			return
 		}
-
-		if branchExistsOnRemote && !syncForce {
-			// Check if local and remote have diverged
-			localHash, err := gitClient.GetCommitHash(branch.Name)
-			if err != nil {
-				return fmt.Errorf("failed to get local commit hash: %w", err)
-			}
-
-			remoteHash, err := gitClient.GetCommitHash(remoteBranch)
-			if err != nil {
-				return fmt.Errorf("failed to get remote commit hash: %w", err)
-			}
-
-			if localHash != remoteHash {
-				// Check merge base to determine relationship
-				mergeBase, err := gitClient.GetMergeBase(branch.Name, remoteBranch)
-				if err != nil {
-					return fmt.Errorf("failed to get merge base: %w", err)
-				}
-
-				if mergeBase == remoteHash {
-					// Local is ahead of remote (we have new commits)
-					if git.Verbose {
-						fmt.Printf("  Local branch is ahead of origin (has new commits)\n")
-					}
-				} else if mergeBase == localHash {
-					// Local is behind remote (safe to fast-forward)
-					fmt.Printf("  Fast-forwarding to origin/%s...\n", branch.Name)
-					if err := gitClient.ResetToRemote(branch.Name); err != nil {
-						return fmt.Errorf("failed to fast-forward: %w", err)
-					}
-				} else {
-					// Branches have diverged - this is normal after rebasing onto an updated parent
-					// --force-with-lease will safely handle this during push
-					if git.Verbose {
-						fmt.Printf("  Local and remote have diverged (normal after rebase)\n")
-					}
-				}
-			} else if git.Verbose {
-				fmt.Printf("  Local branch is up-to-date with origin/%s\n", branch.Name)
+		options := currentSyncOptions()
+		if syncApplyPlan != "" {
+			if syncResume || syncAbort {
+				fmt.Fprintln(os.Stderr, i18n.T("error", errors.New("--apply can't be combined with --resume or --abort")))
+				os.Exit(1)
 			}
-		} else if syncForce && branchExistsOnRemote {
-			if git.Verbose {
-				fmt.Printf("  Skipping divergence check (--force enabled)\n")
+			plan, err := loadSyncPlan(syncApplyPlan)
+			if err == nil {
+				options, err = verifySyncPlan(gitClient, githubClient, plan)
 			}
-		} else {
-			if git.Verbose {
-				fmt.Printf("  Remote branch origin/%s doesn't exist yet (new branch)\n", branch.Name)
+			if err != nil {
+				exitWithError(err)
 			}
 		}
 
-		// Determine rebase target: origin/<parent> for base branches, local for stack branches
-		rebaseTarget := branch.Parent
-		if !stackBranchSet[branch.Parent] {
-			// Parent is not a stack branch, so it's a base branch - use origin/<parent>
-			rebaseTarget = "origin/" + branch.Parent
-
-			// Explicitly fetch the base branch to ensure tracking ref is up to date
-			// This is needed because 'git fetch origin' may not always update tracking refs
-			// reliably (e.g., repos with limited refspecs or certain git configurations)
-			if err := gitClient.FetchBranch(branch.Parent); err != nil {
-				// Non-fatal: continue with potentially stale ref, rebase will still work
-				// but might not include latest changes from the base branch
-				if git.Verbose {
-					fmt.Printf("  Note: could not fetch %s: %v\n", branch.Parent, err)
-				}
+		// Explain what sync does before the first force-push in this repo
+		if !dryRun && !syncResume && !syncAbort {
+			if err := ensureOnboarded(gitClient); err != nil {
+				exitWithError(err)
 			}
 		}
 
-		// Rebase onto parent
-		// If parent was just merged (oldParent set), use --onto to exclude old parent's commits
-		if err := spinner.WrapWithSuccessIndented(
-			"  ",
-			fmt.Sprintf("Rebasing onto %s...", rebaseTarget),
-			fmt.Sprintf("Rebased onto %s", rebaseTarget),
-			func() error {
-				if oldParent != "" {
-					// Parent was merged - use --onto to handle squash merge
-					// This excludes commits from oldParent that are now in rebaseTarget
-					fmt.Printf("  Using --onto to handle squash merge (excluding commits from %s)\n", oldParent)
-					return gitClient.RebaseOnto(rebaseTarget, oldParent, branch.Name)
-				}
-
-				// Get unique commits in this branch by comparing patch content (not just SHAs)
-				// This detects duplicate changes even if commits were rebased with different SHAs
-				uniqueCommits, err := gitClient.GetUniqueCommitsByPatch(rebaseTarget, branch.Name)
-				if err != nil {
-					// If we can't get unique commits, fall back to regular rebase
-					if git.Verbose {
-						fmt.Printf("  Could not get unique commits by patch, using regular rebase: %v\n", err)
-					}
-					return gitClient.Rebase(rebaseTarget)
-				}
-
-				// If no unique commits, branch is up-to-date
-				if len(uniqueCommits) == 0 {
-					if git.Verbose {
-						fmt.Printf("  Branch is up-to-date with %s (no unique patches)\n", rebaseTarget)
-					}
-					return nil
-				}
-
-				if git.Verbose {
-					fmt.Printf("  Found %d unique commit(s) by patch comparison\n", len(uniqueCommits))
-				}
-
-				// Get merge-base to understand the history
-				mergeBase, err := gitClient.GetMergeBase(branch.Name, rebaseTarget)
-				if err != nil {
-					// If we can't find merge-base, fall back to regular rebase
-					if git.Verbose {
-						fmt.Printf("  Could not find merge-base, using regular rebase: %v\n", err)
-					}
-					return gitClient.Rebase(rebaseTarget)
-				}
-
-				rebaseTargetHash, err := gitClient.GetCommitHash(rebaseTarget)
-				if err == nil && mergeBase == rebaseTargetHash {
-					// Parent hasn't changed since we branched, regular rebase is fine
-					return gitClient.Rebase(rebaseTarget)
-				}
-
-				// Count commits from merge-base to current branch (total commits in branch history)
-				allCommits, err := gitClient.GetUniqueCommits(mergeBase, branch.Name)
-				if err == nil && len(allCommits) > len(uniqueCommits)*2 {
-					// Branch has polluted history: many more commits than unique patches
-					// This usually means branch diverged from parent's history (e.g., based on old backup)
-
-					if syncCherryPick {
-						// Automated cherry-pick rebuild with backup
-						tempBranch := branch.Name + "-rebuild"
-
-						// Find available backup branch name
-						backupBranch := branch.Name + "-backup"
-						for i := 2; gitClient.BranchExists(backupBranch); i++ {
-							backupBranch = fmt.Sprintf("%s-backup-%d", branch.Name, i)
-						}
-
-						fmt.Printf("\n")
-						fmt.Printf("⚠ Detected polluted branch history (%d commits, %d unique patches)\n", len(allCommits), len(uniqueCommits))
-						fmt.Printf("  Creating backup: %s\n", backupBranch)
-
-						// Create backup branch from current branch (without checkout)
-						if err := gitClient.CreateBranch(backupBranch, branch.Name); err != nil {
-							return fmt.Errorf("failed to create backup branch: %w", err)
-						}
-
-						fmt.Printf("  Rebuilding with %d unique commit(s)...\n", len(uniqueCommits))
-
-						// Checkout parent branch
-						if err := gitClient.CheckoutBranch(rebaseTarget); err != nil {
-							return fmt.Errorf("failed to checkout parent %s: %w", rebaseTarget, err)
-						}
-
-						// Create temp branch from parent
-						if err := gitClient.CreateBranchAndCheckout(tempBranch, rebaseTarget); err != nil {
-							return fmt.Errorf("failed to create temp branch: %w", err)
-						}
-
-						// Cherry-pick each unique commit
-						for _, commit := range uniqueCommits {
-							if git.Verbose {
-								fmt.Printf("    Cherry-picking %s\n", commit[:8])
-							}
-							if err := gitClient.CherryPick(commit); err != nil {
-								// Cherry-pick conflict - let user resolve
-								rebaseConflict = true
-								fmt.Fprintf(os.Stderr, "\n  Cherry-pick conflict on %s. To continue:\n", commit[:8])
-								fmt.Fprintf(os.Stderr, "    1. Resolve the conflicts\n")
-								fmt.Fprintf(os.Stderr, "    2. Run 'git add <resolved files>'\n")
-								fmt.Fprintf(os.Stderr, "    3. Run 'git cherry-pick --continue'\n")
-								fmt.Fprintf(os.Stderr, "    4. Complete remaining cherry-picks manually\n")
-								fmt.Fprintf(os.Stderr, "    5. Run 'git branch -D %s && git branch -m %s'\n", branch.Name, branch.Name)
-								fmt.Fprintf(os.Stderr, "    6. Run 'stack sync --resume'\n")
-								fmt.Fprintf(os.Stderr, "\n  Backup saved as: %s\n", backupBranch)
-								return fmt.Errorf("cherry-pick conflict: %w", err)
-							}
-						}
-
-						// Delete original branch and rename temp to original
-						if err := gitClient.DeleteBranchForce(branch.Name); err != nil {
-							return fmt.Errorf("failed to delete original branch: %w", err)
-						}
-
-						// We're on tempBranch, rename it to the original branch name
-						if err := gitClient.RenameBranch(tempBranch, branch.Name); err != nil {
-							return fmt.Errorf("failed to rename temp branch: %w", err)
-						}
-
-						// Restore stackparent config (git branch -D deletes the branch's config section)
-						configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
-						if err := gitClient.SetConfig(configKey, branch.Parent); err != nil {
-							return fmt.Errorf("failed to restore stackparent config: %w", err)
-						}
-
-						fmt.Printf("  %s Rebuilt %s (backup saved as %s)\n", ui.SuccessIcon(), ui.Branch(branch.Name), ui.Branch(backupBranch))
-						fmt.Printf("  To delete backup later: %s\n", ui.Command(fmt.Sprintf("git branch -D %s", backupBranch)))
-
-						// Branch is now clean - no need to rebase, just return nil
-						return nil
-					}
-
-					// No --cherry-pick flag: show warning and suggest the flag
-					rebaseConflict = true
-					fmt.Fprintf(os.Stderr, "\n")
-					fmt.Fprintf(os.Stderr, "⚠ Detected polluted branch history:\n")
-					fmt.Fprintf(os.Stderr, "  - %d commits in branch history\n", len(allCommits))
-					fmt.Fprintf(os.Stderr, "  - Only %d unique patch(es)\n", len(uniqueCommits))
-					fmt.Fprintf(os.Stderr, "\n")
-					fmt.Fprintf(os.Stderr, "This usually means your branch diverged from the parent's history.\n")
-					fmt.Fprintf(os.Stderr, "Rebasing may result in many conflicts.\n")
-					fmt.Fprintf(os.Stderr, "\n")
-					fmt.Fprintf(os.Stderr, "Recommended: Run 'stack sync --cherry-pick' to auto-rebuild\n")
-					fmt.Fprintf(os.Stderr, "  (Creates backup branch before rebuilding)\n")
-					fmt.Fprintf(os.Stderr, "\n")
-					fmt.Fprintf(os.Stderr, "Or rebuild manually:\n")
-					fmt.Fprintf(os.Stderr, "  1. git checkout %s\n", branch.Parent)
-					fmt.Fprintf(os.Stderr, "  2. git checkout -b %s-clean\n", branch.Name)
-					for i, commit := range uniqueCommits {
-						if i < 5 { // Show first 5 commits
-							fmt.Fprintf(os.Stderr, "  3. git cherry-pick %s\n", commit[:8])
-						}
-					}
-					if len(uniqueCommits) > 5 {
-						fmt.Fprintf(os.Stderr, "     ... (%d more commits)\n", len(uniqueCommits)-5)
-					}
-					fmt.Fprintf(os.Stderr, "  4. git branch -D %s\n", branch.Name)
-					fmt.Fprintf(os.Stderr, "  5. git branch -m %s\n", branch.Name)
-					fmt.Fprintf(os.Stderr, "  6. git push --force-with-lease\n")
-					fmt.Fprintf(os.Stderr, "\n")
-					return fmt.Errorf("branch history is polluted, manual cleanup recommended")
-				}
-
-				// Use --onto to only replay commits unique to this branch
-				// This prevents conflicts from duplicate commits when parent was rebased
-				if git.Verbose {
-					fmt.Printf("  Using --onto with merge-base %s to handle rebased parent\n", mergeBase[:8])
-				}
-				return gitClient.RebaseOnto(rebaseTarget, mergeBase, branch.Name)
-			},
-		); err != nil {
-			rebaseConflict = true
-			fmt.Fprintf(os.Stderr, "\n  Rebase conflict detected. To continue:\n")
-			fmt.Fprintf(os.Stderr, "    1. Resolve the conflicts\n")
-			fmt.Fprintf(os.Stderr, "    2. Run 'git add <resolved files>'\n")
-			fmt.Fprintf(os.Stderr, "    3. Run 'git rebase --continue'\n")
-			fmt.Fprintf(os.Stderr, "    4. Run 'stack sync --resume'\n")
-			fmt.Fprintf(os.Stderr, "\n  Or to abort the sync:\n")
-			fmt.Fprintf(os.Stderr, "    Run 'stack sync --abort'\n")
-			if stashed {
-				fmt.Fprintf(os.Stderr, "\n  Note: Your uncommitted changes have been stashed and will be restored when you run --resume or --abort\n")
-			}
-			return fmt.Errorf("failed to rebase: %w", errAlreadyPrinted)
-		}
-
-		// Push to origin - only if the branch already exists remotely
-		if branchExistsOnRemote {
-			pushErr := spinner.WrapWithSuccessIndented(
-				"  ",
-				"Pushing to origin...",
-				"Pushed to origin",
-				func() error {
-					if syncForce {
-						// Use regular --force (bypasses --force-with-lease safety checks)
-						if git.Verbose {
-							fmt.Printf("  Using --force (bypassing safety checks)\n")
-						}
-						return gitClient.ForcePush(branch.Name)
-					}
-
-					// Fetch one more time right before push to get the current remote SHA
-					if git.Verbose {
-						fmt.Printf("  Refreshing remote tracking ref before push...\n")
-					}
-					if err := gitClient.FetchBranch(branch.Name); err != nil {
-						// Non-fatal, continue with push using plain --force-with-lease
-						if git.Verbose {
-							fmt.Fprintf(os.Stderr, "  Note: could not refresh tracking ref: %v\n", err)
-						}
-						return gitClient.Push(branch.Name, true)
-					}
-
-					// Get the remote SHA to use with explicit --force-with-lease
-					// This avoids "stale info" errors that can occur with plain --force-with-lease
-					remoteSha, err := gitClient.GetCommitHash("origin/" + branch.Name)
-					if err != nil {
-						// Fall back to plain --force-with-lease
-						if git.Verbose {
-							fmt.Fprintf(os.Stderr, "  Note: could not get remote SHA, using plain force-with-lease: %v\n", err)
-						}
-						return gitClient.Push(branch.Name, true)
-					}
-
-					return gitClient.PushWithExpectedRemote(branch.Name, remoteSha)
-				},
-			)
-
-			if pushErr != nil {
-				if !syncForce {
-					fmt.Fprintf(os.Stderr, "\nPossible cause:\n")
-					fmt.Fprintf(os.Stderr, "  Remote branch was updated after fetch - try running 'stack sync' again\n")
-				}
-				return fmt.Errorf("push failed for %s", branch.Name)
+		// Bring back stack branches deleted locally before the chain is built
+		if !syncResume && !syncAbort {
+			if err := restoreRemoteOnlyBranches(gitClient, githubClient); err != nil {
+				exitWithError(err)
 			}
-		} else {
-			fmt.Printf("  Skipping push (branch not yet on origin)\n")
 		}
 
-		// Check if PR exists and update base if needed
-		pr := prCache[branch.Name]
-		if pr != nil {
-			if pr.Base != branch.Parent {
-				fmt.Printf("  Updating PR #%d base from %s to %s...\n", pr.Number, ui.Branch(pr.Base), ui.Branch(branch.Parent))
-				if err := githubClient.UpdatePRBase(pr.Number, branch.Parent); err != nil {
-					fmt.Fprintf(os.Stderr, "  Warning: failed to update PR base: %v\n", err)
-				} else {
-					fmt.Printf("  %s PR #%d updated\n", ui.SuccessIcon(), pr.Number)
-				}
-			} else {
-				fmt.Printf("  %s PR #%d base is already correct (%s)\n", ui.SuccessIcon(), pr.Number, ui.Branch(pr.Base))
-			}
-		} else {
-			fmt.Printf("  No PR found (create one with '%s')\n", ui.Command("gh pr create"))
-		}
+		configureLFS(gitClient, syncNoLFSSmudge)
 
-		fmt.Println()
-	}
-
-	// Return to original branch
-	fmt.Printf("Returning to %s...\n", ui.Branch(originalBranch))
-	if err := gitClient.CheckoutBranch(originalBranch); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
-	}
-
-	fmt.Println()
-
-	// Display the updated stack status (reuse prCache to avoid redundant API call)
-	if err := displayStatusAfterSync(gitClient, githubClient, prCache); err != nil {
-		// Don't fail if we can't display status, just warn
-		fmt.Fprintf(os.Stderr, "Warning: failed to display stack status: %v\n", err)
-	}
-
-	// Mark as successful so defer doesn't restore stash
-	success = true
-
-	// Restore stashed changes before success message
-	if stashed {
-		fmt.Println()
-		fmt.Println("Restoring stashed changes...")
-		if err := gitClient.StashPop(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
-			fmt.Fprintf(os.Stderr, "Run 'git stash pop' manually to restore your changes\n")
+		if err := syncWith(gitClient, githubClient, options); err != nil {
+			exitWithError(err)
 		}
-	}
-
-	// Clean up sync state (both stash flag and original branch)
-	_ = gitClient.UnsetConfig(configSyncStashed)
-	_ = gitClient.UnsetConfig(configSyncOriginalBranch)
-
-	fmt.Println()
-	fmt.Println(ui.Success("Sync complete!"))
-
-	return nil
-}
-
-// displayStatusAfterSync shows the stack tree after a successful sync
-// It reuses the prCache from earlier to avoid a redundant API call
-func displayStatusAfterSync(gitClient git.GitClient, githubClient github.GitHubClient, prCache map[string]*github.PRInfo) error {
-	currentBranch, err := gitClient.GetCurrentBranch()
-	if err != nil {
-		return fmt.Errorf("failed to get current branch: %w", err)
-	}
-
-	tree, err := stack.BuildStackTreeForBranch(gitClient, currentBranch)
-	if err != nil {
-		return fmt.Errorf("failed to build stack tree: %w", err)
-	}
-
-	// Filter out branches with merged PRs (leaf nodes only)
-	tree = filterMergedBranchesForSync(tree, prCache)
-
-	// Print the tree
-	printTreeForSync(gitClient, tree, currentBranch, prCache)
-
-	return nil
+	},
 }
 
-// filterMergedBranchesForSync removes branches with merged PRs from the tree,
-// but only if they don't have children (to keep the stack structure visible)
-func filterMergedBranchesForSync(node *stack.TreeNode, prCache map[string]*github.PRInfo) *stack.TreeNode {
-	if node == nil {
-		return nil
-	}
-
-	// Filter children recursively first
-	var filteredChildren []*stack.TreeNode
-	for _, child := range node.Children {
-		// Recurse first to process all descendants
-		filtered := filterMergedBranchesForSync(child, prCache)
-
-		// Only filter out merged branches if they have no children
-		if pr, exists := prCache[child.Name]; exists && pr.State == "MERGED" {
-			// If this merged branch still has children after filtering, keep it
-			if filtered != nil && len(filtered.Children) > 0 {
-				filteredChildren = append(filteredChildren, filtered)
-			}
-			// Otherwise skip this merged leaf branch
-		} else {
-			// Not merged, keep it
-			if filtered != nil {
-				filteredChildren = append(filteredChildren, filtered)
-			}
-		}
-	}
-
-	node.Children = filteredChildren
-	return node
+func init() {
+	syncCmd.Flags().BoolVarP(&syncForce, "force", "f", false, "Use --force instead of --force-with-lease for every push (bypasses safety checks)")
+	syncCmd.Flags().BoolVarP(&syncResume, "resume", "r", false, "Resume a sync after resolving rebase conflicts")
+	syncCmd.Flags().BoolVarP(&syncAbort, "abort", "a", false, "Abort an interrupted sync and clean up state")
+	syncCmd.Flags().BoolVar(&syncCherryPick, "cherry-pick", false, "Rebuild polluted branches by cherry-picking unique commits (creates backup)")
+	syncCmd.Flags().BoolVar(&syncDetectMergedByPatch, "detect-merged-by-patch", false, "Treat branches whose patches already landed on the base branch as merged, even without a merged PR")
+	syncCmd.Flags().BoolVar(&detectMergedByCommit, "detect-merged-by-commit", false, "Treat branches with a closed PR as merged when their tip is already on the base branch")
+	syncCmd.Flags().BoolVar(&syncPruneMerged, "prune-merged", true, "Remove branches with merged PRs from stack tracking (use --prune-merged=false to only skip them)")
+	syncCmd.Flags().StringVar(&syncReviewComment, "review-comment", "", "After force-pushing a reviewed PR, comment what changed: summary or range-diff")
+	syncCmd.Flags().BoolVar(&syncAllowReviewInvalidation, "allow-review-invalidation", false, "Push approved PRs even if their content changed (with stack.sync.protectApprovals)")
+	syncCmd.Flags().BoolVar(&syncDeleteMerged, "delete-merged", false, "Delete merged branches and their worktrees locally once their children are restacked")
+	syncCmd.Flags().BoolVar(&syncCreatePRs, "create-prs", false, "Push branches that aren't on origin yet and open a PR against the parent for branches without one")
+	syncCmd.Flags().BoolVar(&syncDraftPRs, "draft", true, "Open PRs created by --create-prs as drafts (use --draft=false for ready for review)")
+	syncCmd.Flags().BoolVar(&syncPRNav, "pr-nav", false, "Keep a table of the stack, linking each PR, in every PR description")
+	syncCmd.Flags().StringVar(&prPosition, "pr-position", "", "Mark each PR with its place in the stack: label (stack:2/5), title ([2/5] prefix), both or off")
+	syncCmd.Flags().BoolVar(&syncRequestReviewers, "request-reviewers", false, "Request reviews from the CODEOWNERS of the files each branch changes relative to its parent")
+	syncCmd.Flags().BoolVar(&syncWaitForCI, "wait-for-ci", false, "Wait for a pushed branch's PR checks to pass before pushing the branches stacked on it")
+	syncCmd.Flags().DurationVar(&syncCITimeout, "ci-timeout", syncer.DefaultCITimeout, "How long --wait-for-ci waits for checks before holding back the rest of the stack")
+	syncCmd.Flags().BoolVar(&syncNoLFSSmudge, "no-lfs-smudge", false, "Leave Git LFS files as pointers while rebasing, and download them once for the branch sync ends on")
+	syncCmd.Flags().BoolVar(&syncNoLint, "no-lint", false, "Skip the commit message linter (stack.commitLint.command) for this sync")
+	syncCmd.Flags().BoolVar(&noSizeGuard, "no-size-guard", false, "Skip the branch size check (stack.sizeGuard) for this sync")
+	syncCmd.Flags().BoolVar(&syncFull, "full", false, "Walk the whole stack even if nothing changed since the last sync")
+	syncCmd.Flags().BoolVar(&syncVerify, "verify", false, "Check the result once the sync is done: PR bases, pushed tips and branches on their parents")
+	syncCmd.Flags().BoolVar(&syncPlanOnly, "plan", false, "Show what sync would do, without changing anything")
+	syncCmd.Flags().BoolVar(&syncPlanJSON, "json", false, "With --plan, print the plan as JSON for --apply")
+	syncCmd.Flags().StringVar(&syncApplyPlan, "apply", "", "Sync exactly as described by a plan from --plan --json, refusing if the repo changed since")
+	syncCmd.Flags().StringVar(&prScope, "pr-scope", syncer.PRScopeStack, "Which PRs to look up: stack (PRs of the stack's branches) or author (only your own PRs)")
 }
 
-// printTreeForSync prints the stack tree after sync
-func printTreeForSync(gitClient git.GitClient, node *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo) {
-	if node == nil {
-		return
-	}
-	printTreeVerticalForSync(gitClient, node, currentBranch, prCache, false)
+// runSync syncs the current stack with the sync flags as they are
+func runSync(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	return syncWith(gitClient, githubClient, currentSyncOptions())
 }
 
-func printTreeVerticalForSync(gitClient git.GitClient, node *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, isPipe bool) {
-	if node == nil {
-		return
-	}
-
-	// Determine the current branch marker
-	marker := ""
-	if node.Name == currentBranch {
-		marker = ui.CurrentBranchMarker()
-	}
-
-	// Get PR info from cache
-	prInfo := ""
-	if node.Name != stack.GetBaseBranch(gitClient) {
-		if pr, exists := prCache[node.Name]; exists {
-			prInfo = fmt.Sprintf(" %s", ui.PRInfo(pr.URL, pr.State))
-		}
-	}
-
-	// Print pipe if needed
-	if isPipe {
-		fmt.Printf("  %s\n", ui.Pipe())
-	}
-
-	// Print current node
-	fmt.Printf(" %s%s%s\n", ui.Branch(node.Name), prInfo, marker)
-
-	// Print children vertically
-	for _, child := range node.Children {
-		printTreeVerticalForSync(gitClient, child, currentBranch, prCache, true)
-	}
+// syncWith syncs the current stack with options, reading answers to prompts from
+// stdinReader
+func syncWith(gitClient git.GitClient, githubClient github.GitHubClient, options syncer.Options) error {
+	engine := syncer.New(gitClient, githubClient, options, nil)
+	engine.Input = stdinReader
+	return engine.Run()
 }
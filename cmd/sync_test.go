@@ -1,15 +1,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/javoire/stackinator/internal/github"
-	"github.com/javoire/stackinator/internal/stack"
 	"github.com/javoire/stackinator/internal/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestRunSyncBasic(t *testing.T) {
@@ -27,6 +28,7 @@ func TestRunSyncBasic(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		// Check working tree
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		// Get base branch
@@ -39,9 +41,17 @@ func TestRunSyncBasic(t *testing.T) {
 			"feature-b": "feature-a",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe() // Called in GetStackChain, TopologicalSort, and displayStatusAfterSync
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 		// Parallel operations
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "feature-b").Return(nil, nil).Maybe()
@@ -87,6 +97,7 @@ func TestRunSyncBasic(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -111,6 +122,7 @@ func TestRunSyncMergedParent(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
 		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -121,6 +133,14 @@ func TestRunSyncMergedParent(t *testing.T) {
 			"feature-b": "feature-a",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe() // Called in GetStackChain, TopologicalSort, and displayStatusAfterSync
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		// Parallel operations
 		mockGit.On("Fetch").Return(nil)
@@ -129,7 +149,7 @@ func TestRunSyncMergedParent(t *testing.T) {
 		prCache := map[string]*github.PRInfo{
 			"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "Feature A", "url"),
 		}
-		mockGH.On("GetAllPRs").Return(prCache, nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(prCache, nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-b").Return(nil, nil).Maybe()
 
@@ -160,6 +180,7 @@ func TestRunSyncMergedParent(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -169,6 +190,189 @@ func TestRunSyncMergedParent(t *testing.T) {
 	})
 }
 
+func TestRunSyncDeleteMerged(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	syncDeleteMerged = true
+	defer func() { syncDeleteMerged = false }()
+
+	t.Run("merged parent is deleted after child is restacked", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		// Setup: Check for existing sync state (none)
+		mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+		// Setup
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		// Save original branch state
+		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+		mockGit.On("IsWorkingTreeClean").Return(true, nil)
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+		mockGit.On("GetDefaultBranch").Return("main").Maybe() // Called many times in tree printing
+
+		stackParents := map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+		}
+		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe() // Called in GetStackChain, TopologicalSort, and displayStatusAfterSync
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
+
+		// Parallel operations
+		mockGit.On("Fetch").Return(nil)
+
+		// Parent PR is merged
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "Feature A", "url"),
+		}
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(prCache, nil)
+		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
+		mockGH.On("GetPRForBranch", "feature-b").Return(nil, nil).Maybe()
+
+		mockGit.On("GetWorktreeBranches").Return(map[string]string{
+			"feature-a": "/Users/test/repo/.worktrees/feature-a",
+		}, nil)
+		mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{
+			"main":      true,
+			"feature-a": true,
+			"feature-b": true,
+		})
+
+		// Process feature-a (merged, skip)
+		mockGit.On("UnsetConfig", "branch.feature-a.stackparent").Return(nil)
+
+		// Process feature-b (parent is merged, update parent to grandparent)
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+		mockGit.On("FetchBranch", "main").Return(nil) // Fetch base branch before rebase
+		mockGit.On("RebaseOnto", "origin/main", "feature-a", "feature-b").Return(nil)
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+
+		// Return to original branch
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		// Delete merged feature-a (remote branch already gone) and its worktree
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("", errors.New("unknown revision"))
+		// Squash-merged into main
+		mockGit.On("GetCommitHash", "origin/main").Return("m2", nil)
+		mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{"a1"}, nil)
+		mockGit.On("IsMergedByPatch", "origin/main", "feature-a").Return(true, nil)
+		mockGit.On("RemoveWorktree", "/Users/test/repo/.worktrees/feature-a").Return(nil)
+		mockGit.On("DeleteBranchForce", "feature-a").Return(nil)
+
+		// Clean up sync state
+		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+}
+
+func TestRunSyncKeepMergedTracking(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	syncPruneMerged = false
+	defer func() { syncPruneMerged = true }()
+
+	t.Run("merged branch keeps stack tracking", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		// Setup: Check for existing sync state (none)
+		mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+		// Setup
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		// Save original branch state
+		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+		mockGit.On("IsWorkingTreeClean").Return(true, nil)
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+		mockGit.On("GetDefaultBranch").Return("main").Maybe() // Called many times in tree printing
+
+		stackParents := map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+		}
+		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe() // Called in GetStackChain, TopologicalSort, and displayStatusAfterSync
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
+
+		// Parallel operations
+		mockGit.On("Fetch").Return(nil)
+
+		// Parent PR is merged
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "Feature A", "url"),
+		}
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(prCache, nil)
+		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
+		mockGH.On("GetPRForBranch", "feature-b").Return(nil, nil).Maybe()
+
+		mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+		mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{
+			"main":      true,
+			"feature-a": true,
+			"feature-b": true,
+		})
+
+		// Process feature-a (merged, skipped but still tracked)
+
+		// Process feature-b (parent is merged, update parent to grandparent)
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+		mockGit.On("FetchBranch", "main").Return(nil) // Fetch base branch before rebase
+		mockGit.On("RebaseOnto", "origin/main", "feature-a", "feature-b").Return(nil)
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+
+		// Return to original branch
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		// Clean up sync state
+		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "UnsetConfig", "branch.feature-a.stackparent")
+	})
+}
+
 func TestRunSyncUpdatePRBase(t *testing.T) {
 	testutil.SetupTest()
 	defer testutil.TeardownTest()
@@ -184,6 +388,7 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
 		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -194,6 +399,14 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 			"feature-b": "feature-a",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe() // Called in GetStackChain, TopologicalSort, and displayStatusAfterSync
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		// Parallel operations
 		mockGit.On("Fetch").Return(nil)
@@ -203,7 +416,7 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 			"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "Feature A", "url"),
 			"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "Feature B", "url"), // Wrong base!
 		}
-		mockGH.On("GetAllPRs").Return(prCache, nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(prCache, nil)
 
 		mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
 		mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
@@ -237,6 +450,7 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 		mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
 
 		// Update PR base
+		mockGH.On("GetPRByNumber", 2).Return(testutil.NewPRInfo(2, "OPEN", "main", "B", "url"), nil)
 		mockGH.On("UpdatePRBase", 2, "feature-a").Return(nil)
 
 		// Return to original branch
@@ -244,6 +458,7 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -251,6 +466,83 @@ func TestRunSyncUpdatePRBase(t *testing.T) {
 		mockGit.AssertExpectations(t)
 		mockGH.AssertExpectations(t)
 	})
+
+	t.Run("PR merged during the sync moves its children", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+		mockGit.On("IsWorkingTreeClean").Return(true, nil)
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+		mockGit.On("GetDefaultBranch").Return("main").Maybe()
+		mockGit.On("GetAllStackParents").Return(map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+		}, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
+		mockGit.On("Fetch").Return(nil)
+
+		// Both PRs were open when the sync looked them up
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "OPEN", "develop", "Feature A", "url"),
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "Feature B", "url"),
+		}, nil)
+		mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+		mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{
+			"main":      true,
+			"feature-a": true,
+			"feature-b": true,
+		})
+
+		// Process feature-a, whose PR merges before its base is updated
+		mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("abc123", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("abc123", nil)
+		mockGit.On("FetchBranch", "main").Return(nil)
+		mockGit.On("GetUniqueCommitsByPatch", "origin/main", "feature-a").Return([]string{"abc123"}, nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("main123", nil)
+		mockGit.On("GetCommitHash", "origin/main").Return("main123", nil)
+		mockGit.On("Rebase", "origin/main").Return(nil)
+		mockGit.On("FetchBranch", "feature-a").Return(nil)
+		mockGit.On("PushWithExpectedRemote", "feature-a", "abc123").Return(nil)
+		mockGH.On("GetPRByNumber", 1).Return(testutil.NewPRInfo(1, "MERGED", "develop", "Feature A", "url"), nil)
+
+		// Process feature-b as the child of a merged parent
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+		mockGit.On("RebaseOnto", "origin/main", "feature-a", "feature-b").Return(nil)
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+		mockGH.On("GetPRByNumber", 2).Return(testutil.NewPRInfo(2, "OPEN", "feature-a", "Feature B", "url"), nil)
+		mockGH.On("UpdatePRBase", 2, "main").Return(nil)
+
+		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+		mockGH.AssertNotCalled(t, "UpdatePRBase", 1, mock.Anything)
+	})
 }
 
 func TestRunSyncStashHandling(t *testing.T) {
@@ -268,6 +560,7 @@ func TestRunSyncStashHandling(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-a").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		// Working tree is dirty
 		mockGit.On("IsWorkingTreeClean").Return(false, nil)
 		// Stash changes
@@ -283,9 +576,17 @@ func TestRunSyncStashHandling(t *testing.T) {
 			"feature-a": "main",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "main").Return(nil, nil).Maybe()
@@ -315,6 +616,7 @@ func TestRunSyncStashHandling(t *testing.T) {
 		mockGit.On("StashPop").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -339,6 +641,7 @@ func TestRunSyncErrorHandling(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-a").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
 		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -348,9 +651,17 @@ func TestRunSyncErrorHandling(t *testing.T) {
 			"feature-a": "main",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "main").Return(nil, nil).Maybe()
@@ -391,6 +702,7 @@ func TestRunSyncErrorHandling(t *testing.T) {
 		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-a").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		// Working tree is dirty - will stash
 		mockGit.On("IsWorkingTreeClean").Return(false, nil)
 		mockGit.On("Stash", "stack-sync-autostash").Return(nil)
@@ -405,9 +717,17 @@ func TestRunSyncErrorHandling(t *testing.T) {
 			"feature-a": "main",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "main").Return(nil, nil).Maybe()
@@ -438,36 +758,6 @@ func TestRunSyncErrorHandling(t *testing.T) {
 	})
 }
 
-func TestFilterMergedBranchesForSync(t *testing.T) {
-	// Test the filterMergedBranchesForSync function
-	// This is a simple unit test for the tree filtering logic
-	prCache := map[string]*github.PRInfo{
-		"merged-leaf":   testutil.NewPRInfo(1, "MERGED", "main", "Merged Leaf", "url"),
-		"merged-parent": testutil.NewPRInfo(2, "MERGED", "main", "Merged Parent", "url"),
-	}
-
-	tree := &stack.TreeNode{
-		Name: "main",
-		Children: []*stack.TreeNode{
-			{
-				Name: "merged-parent",
-				Children: []*stack.TreeNode{
-					{Name: "child-of-merged", Children: nil},
-				},
-			},
-			{Name: "merged-leaf", Children: nil},
-			{Name: "open-branch", Children: nil},
-		},
-	}
-
-	filtered := filterMergedBranchesForSync(tree, prCache)
-
-	// merged-parent should be kept because it has children
-	// merged-leaf should be filtered out
-	// open-branch should be kept
-	assert.Equal(t, 2, len(filtered.Children))
-}
-
 func TestRunSyncNoStackBranches(t *testing.T) {
 	testutil.SetupTest()
 	defer testutil.TeardownTest()
@@ -482,6 +772,7 @@ func TestRunSyncNoStackBranches(t *testing.T) {
 	mockGit.On("GetCurrentBranch").Return("main", nil)
 	// Save original branch state
 	mockGit.On("SetConfig", "stack.sync.originalBranch", "main").Return(nil)
+	mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 	mockGit.On("IsWorkingTreeClean").Return(true, nil)
 	mockGit.On("GetConfig", "branch.main.stackparent").Return("")
 	mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -489,11 +780,19 @@ func TestRunSyncNoStackBranches(t *testing.T) {
 
 	stackParents := map[string]string{}
 	mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+	testutil.AllowPRPins(mockGit)
+	testutil.AllowStackUmbrellas(mockGit)
+	testutil.AllowSyncState(mockGit)
+	testutil.AllowSyncWorktree(mockGit)
+	testutil.AllowSubmodules(mockGit)
+	testutil.AllowUpdateRefs(mockGit)
+	testutil.AllowOpLog(mockGit)
+	testutil.AllowPickedCommits(mockGit)
 
 	// When there are no stack branches, code returns early after parallel ops
 	// These are started but may not complete before early return
 	mockGit.On("Fetch").Return(nil).Maybe()
-	mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil).Maybe()
+	mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil).Maybe()
 
 	// These calls don't happen when there are no stack branches (early return)
 
@@ -544,9 +843,17 @@ func TestRunSyncResume(t *testing.T) {
 			"feature-a": "main",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "main").Return(nil, nil).Maybe()
@@ -579,6 +886,7 @@ func TestRunSyncResume(t *testing.T) {
 		mockGit.On("StashPop").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -601,10 +909,12 @@ func TestRunSyncResume(t *testing.T) {
 		// Clean up orphaned state (user confirmed)
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		mockGit.On("GetCurrentBranch").Return("feature-a", nil)
 		// Save original branch state
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-a").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
 		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -614,9 +924,17 @@ func TestRunSyncResume(t *testing.T) {
 			"feature-a": "main",
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
 		// GetPRForBranch is called for branches not in the cache (to detect merged PRs)
 		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil).Maybe()
 		mockGH.On("GetPRForBranch", "main").Return(nil, nil).Maybe()
@@ -645,6 +963,7 @@ func TestRunSyncResume(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -689,6 +1008,7 @@ func TestRunSyncAutoConfiguresMissingStackparent(t *testing.T) {
 		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
 		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
 		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
 		mockGit.On("IsWorkingTreeClean").Return(true, nil)
 		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
 		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
@@ -699,6 +1019,14 @@ func TestRunSyncAutoConfiguresMissingStackparent(t *testing.T) {
 			"feature-b": "feature-a", // feature-a is missing!
 		}
 		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
 
 		// The fix should auto-configure feature-a with parent=main
 		mockGit.On("BranchExists", "feature-a").Return(true)
@@ -706,10 +1034,9 @@ func TestRunSyncAutoConfiguresMissingStackparent(t *testing.T) {
 
 		// Parallel operations
 		mockGit.On("Fetch").Return(nil)
-		mockGH.On("GetAllPRs").Return(make(map[string]*github.PRInfo), nil)
-		mockGH.On("GetPRForBranch", "feature-a").Return(nil, nil)
-		mockGH.On("GetPRForBranch", "main").Return(nil, nil)
-		mockGH.On("GetPRForBranch", "feature-b").Return(nil, nil)
+		// Tracked branches and their parents are queried in bulk, so neither needs a
+		// per-branch lookup
+		mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(make(map[string]*github.PRInfo), nil)
 
 		// Worktree checks
 		mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
@@ -748,6 +1075,7 @@ func TestRunSyncAutoConfiguresMissingStackparent(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -809,6 +1137,7 @@ func TestRunSyncAbort(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -842,6 +1171,7 @@ func TestRunSyncAbort(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -873,6 +1203,7 @@ func TestRunSyncAbort(t *testing.T) {
 		// Clean up sync state
 		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
 		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
 
 		err := runSync(mockGit, mockGH)
 
@@ -881,3 +1212,81 @@ func TestRunSyncAbort(t *testing.T) {
 		mockGH.AssertExpectations(t)
 	})
 }
+
+func TestRunSyncDetectMergedByPatch(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("reparent child when parent landed without merged PR", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+
+		syncDetectMergedByPatch = true
+		defer func() { syncDetectMergedByPatch = false }()
+
+		mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+		mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+		mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+		mockGit.On("IsWorkingTreeClean").Return(true, nil)
+		mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+		mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+		mockGit.On("GetDefaultBranch").Return("main").Maybe()
+
+		stackParents := map[string]string{
+			"feature-a": "main",
+			"feature-b": "feature-a",
+		}
+		mockGit.On("GetAllStackParents").Return(stackParents, nil).Maybe()
+		testutil.AllowPRPins(mockGit)
+		testutil.AllowStackUmbrellas(mockGit)
+		testutil.AllowSyncState(mockGit)
+		testutil.AllowSyncWorktree(mockGit)
+		testutil.AllowSubmodules(mockGit)
+		testutil.AllowUpdateRefs(mockGit)
+		testutil.AllowOpLog(mockGit)
+		testutil.AllowPickedCommits(mockGit)
+
+		mockGit.On("Fetch").Return(nil)
+		// No PR records at all (e.g. PR was created from another remote)
+		mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
+		mockGH.On("GetPRForBranch", mock.Anything).Return(nil, nil).Maybe()
+
+		mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+		mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+		mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{
+			"main":      true,
+			"feature-a": true,
+			"feature-b": true,
+		})
+
+		// feature-a was squash-merged into main, feature-b was not
+		mockGit.On("IsMergedByPatch", "origin/main", "feature-a").Return(true, nil)
+		mockGit.On("IsMergedByPatch", "origin/main", "feature-b").Return(false, nil)
+
+		// feature-a is removed from tracking
+		mockGit.On("UnsetConfig", "branch.feature-a.stackparent").Return(nil)
+
+		// feature-b is moved onto main, excluding feature-a's commits
+		mockGit.On("GetConfig", "branch.feature-a.stackparent").Return("main")
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+		mockGit.On("FetchBranch", "main").Return(nil)
+		mockGit.On("RebaseOnto", "origin/main", "feature-a", "feature-b").Return(nil)
+		mockGit.On("FetchBranch", "feature-b").Return(nil)
+		mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+
+		mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+		mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "CheckoutBranch", "feature-a")
+	})
+}
@@ -0,0 +1,346 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+var (
+	// syncPlanOnly prints what sync would do, and the state it would do it from,
+	// without changing anything
+	syncPlanOnly bool
+	// syncPlanJSON prints the plan as JSON, to be approved and passed to --apply
+	syncPlanJSON bool
+	// syncApplyPlan is a plan file from --plan --json; sync refuses to run if the
+	// repo no longer matches it
+	syncApplyPlan string
+)
+
+// syncPlanVersion is bumped when the plan format changes incompatibly
+const syncPlanVersion = 1
+
+// syncPlan is a reviewable description of a sync: the state it starts from and what
+// it will do to each branch. Applying it re-checks the state, so it does exactly
+// what was approved or nothing.
+type syncPlan struct {
+	Version   int            `json:"version"`
+	CreatedAt string         `json:"createdAt"`
+	Branch    string         `json:"branch"`
+	Base      string         `json:"base"`
+	BaseSHA   string         `json:"baseSha"`
+	Options   syncer.Options `json:"options"`
+	Steps     []syncPlanStep `json:"steps"`
+}
+
+// syncPlanStep is one branch of the plan, in the order sync processes them
+type syncPlanStep struct {
+	Branch    string `json:"branch"`
+	Parent    string `json:"parent"`
+	Action    string `json:"action"` // rebase, push, none, skip-merged or skip-merge-queue
+	Onto      string `json:"onto,omitempty"`
+	LocalSHA  string `json:"localSha"`
+	RemoteSHA string `json:"remoteSha,omitempty"`
+	OntoSHA   string `json:"ontoSha,omitempty"`
+	PRNumber  int    `json:"prNumber,omitempty"`
+	PRState   string `json:"prState,omitempty"`
+	PRBase    string `json:"prBase,omitempty"`
+}
+
+// currentSyncOptions captures the sync flags, after config defaults were applied
+func currentSyncOptions() syncer.Options {
+	return syncer.Options{
+		Force:                   syncForce,
+		CherryPick:              syncCherryPick,
+		DetectMergedByPatch:     syncDetectMergedByPatch,
+		DetectMergedByCommit:    detectMergedByCommit,
+		PruneMerged:             syncPruneMerged,
+		DeleteMerged:            syncDeleteMerged,
+		CreatePRs:               syncCreatePRs,
+		DraftPRs:                syncDraftPRs,
+		RequestReviewers:        syncRequestReviewers,
+		PRNav:                   syncPRNav,
+		WaitForCI:               syncWaitForCI,
+		CITimeout:               syncCITimeout.String(),
+		ReviewComment:           syncReviewComment,
+		AllowReviewInvalidation: syncAllowReviewInvalidation,
+		Resume:                  syncResume,
+		Abort:                   syncAbort,
+		Full:                    syncFull,
+		Verify:                  syncVerify,
+		ProtectApprovals:        syncProtectApprovals,
+		CommitLint:              syncCommitLint,
+		CommitLintWarn:          syncCommitLintWarn,
+		DCO:                     syncDCO,
+		SizeLimits:              sizeGuardLimits,
+		PRPosition:              prPositionMode,
+		PRScope:                 prScope,
+		FlatPRs:                 flatPRs,
+		PushStrategy:            syncPushStrategy,
+		ForceBranches:           syncForcePatterns,
+	}
+}
+
+// planSyncOptions is the sync flags with the options a plan was made with
+func planSyncOptions(planned syncer.Options) syncer.Options {
+	options := currentSyncOptions()
+	options.Force = planned.Force
+	options.CherryPick = planned.CherryPick
+	options.DetectMergedByPatch = planned.DetectMergedByPatch
+	options.DetectMergedByCommit = planned.DetectMergedByCommit
+	options.PruneMerged = planned.PruneMerged
+	options.DeleteMerged = planned.DeleteMerged
+	options.CreatePRs = planned.CreatePRs
+	options.DraftPRs = planned.DraftPRs
+	options.RequestReviewers = planned.RequestReviewers
+	options.PRNav = planned.PRNav
+	options.WaitForCI = planned.WaitForCI
+	options.CITimeout = planned.CITimeout
+	options.ReviewComment = planned.ReviewComment
+	options.AllowReviewInvalidation = planned.AllowReviewInvalidation
+	// Every step of the plan is applied, even if the last sync left nothing to do
+	options.Full = true
+	return options
+}
+
+// buildSyncPlan fetches and describes what sync would do with options from the
+// current branch, without changing any branch
+func buildSyncPlan(gitClient git.GitClient, githubClient github.GitHubClient, options syncer.Options, now time.Time) (*syncPlan, error) {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current branch: %w", err)
+	}
+	chain, err := stack.GetStackChain(gitClient, currentBranch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack chain: %w", err)
+	}
+	if len(chain) == 0 {
+		return nil, &stack.NotInStackError{Branch: currentBranch}
+	}
+
+	if err := gitClient.Fetch(); err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	// Merged PRs change what sync does, so the plan can't be made without them
+	prCache, _, err := syncer.LoadStackPRs(gitClient, githubClient, options.PRScope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load PRs: %w", err)
+	}
+
+	baseBranch := stack.GetBaseBranch(gitClient)
+	remoteBase := git.RemoteRef(baseBranch)
+	baseSHA, err := gitClient.GetCommitHash(remoteBase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", remoteBase, err)
+	}
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	remoteBranches := gitClient.GetRemoteBranchesSet()
+
+	// Pinned PR numbers are resolved the same way sync does
+	var branches []stack.StackBranch
+	for _, branch := range chain {
+		if branch != baseBranch {
+			branches = append(branches, stack.StackBranch{Name: branch, Parent: parents[branch]})
+		}
+	}
+	prPins, _ := gitClient.GetAllStackPRs()
+	syncer.ResolvePinnedPRs(githubClient, branches, prCache, prPins)
+	landedByCommit := map[string]bool{}
+	if options.DetectMergedByCommit {
+		if landedByCommit, err = syncer.MergedByCommit(gitClient, remoteBase, prCache); err != nil {
+			return nil, fmt.Errorf("failed to check for branches merged by commit: %w", err)
+		}
+	}
+
+	plan := &syncPlan{
+		Version:   syncPlanVersion,
+		CreatedAt: now.UTC().Format(time.RFC3339),
+		Branch:    currentBranch,
+		Base:      baseBranch,
+		BaseSHA:   baseSHA,
+		Options:   options,
+	}
+
+	merged := make(map[string]bool)
+	rebased := make(map[string]bool)
+	for _, sb := range branches {
+		branch := sb.Name
+		step := syncPlanStep{Branch: branch, Parent: sb.Parent}
+		if step.LocalSHA, err = gitClient.GetCommitHash(branch); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", branch, err)
+		}
+		if remoteBranches[branch] {
+			step.RemoteSHA, _ = gitClient.GetCommitHash(git.RemoteRef(branch))
+		}
+		pr := prCache[branch]
+		if pr != nil {
+			step.PRNumber, step.PRState, step.PRBase = pr.Number, pr.State, pr.Base
+		}
+
+		mergedByPatch := false
+		if (pr == nil || pr.State != "MERGED") && options.DetectMergedByPatch {
+			mergedByPatch, _ = gitClient.IsMergedByPatch(remoteBase, branch)
+		}
+
+		switch {
+		case pr != nil && pr.State == "MERGED", mergedByPatch, landedByCommit[branch]:
+			step.Action = "skip-merged"
+			merged[branch] = true
+		case syncer.InMergeQueue(pr):
+			step.Action = "skip-merge-queue"
+		default:
+			// Merged parents are skipped, so the branch moves onto the first one left
+			onto := step.Parent
+			for onto != baseBranch && merged[onto] && parents[onto] != "" {
+				onto = parents[onto]
+			}
+			step.Onto = onto
+			if onto == baseBranch {
+				step.Onto = remoteBase
+			}
+			if step.OntoSHA, err = gitClient.GetCommitHash(step.Onto); err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %w", step.Onto, err)
+			}
+
+			mergeBase, err := gitClient.GetMergeBase(branch, step.Onto)
+			switch {
+			case err != nil || mergeBase != step.OntoSHA || rebased[onto]:
+				step.Action = "rebase"
+				rebased[branch] = true
+			case step.LocalSHA != step.RemoteSHA:
+				step.Action = "push"
+			default:
+				step.Action = "none"
+			}
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+func runSyncPlan(gitClient git.GitClient, githubClient github.GitHubClient) error {
+	plan, err := buildSyncPlan(gitClient, githubClient, currentSyncOptions(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	if syncPlanJSON {
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode plan: %w", err)
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+
+	fmt.Printf("Sync plan for %s (%s at %s):\n\n", ui.Branch(plan.Branch), ui.Branch(git.RemoteRef(plan.Base)), ui.ShortSHA(plan.BaseSHA))
+	for _, step := range plan.Steps {
+		switch step.Action {
+		case "rebase":
+			fmt.Printf("  %s: rebase onto %s and push\n", ui.Branch(step.Branch), ui.Branch(step.Onto))
+		case "push":
+			fmt.Printf("  %s: push\n", ui.Branch(step.Branch))
+		case "none":
+			fmt.Printf("  %s: %s\n", ui.Branch(step.Branch), ui.Dim("up to date"))
+		case "skip-merged":
+			fmt.Printf("  %s: skip (merged)\n", ui.Branch(step.Branch))
+		case "skip-merge-queue":
+			fmt.Printf("  %s: skip (in the merge queue)\n", ui.Branch(step.Branch))
+		}
+	}
+	fmt.Printf("\nSave it for approval with '%s'\n", ui.Command("stack sync --plan --json > plan.json"))
+	return nil
+}
+
+// loadSyncPlan reads a plan written by --plan --json
+func loadSyncPlan(path string) (*syncPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan: %w", err)
+	}
+	var plan syncPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if plan.Version != syncPlanVersion {
+		return nil, fmt.Errorf("plan %s has version %d, this version of stack applies version %d", path, plan.Version, syncPlanVersion)
+	}
+	return &plan, nil
+}
+
+// verifySyncPlan makes sure the repo is still in the state the plan was made from,
+// and returns the options to apply it with
+func verifySyncPlan(gitClient git.GitClient, githubClient github.GitHubClient, plan *syncPlan) (syncer.Options, error) {
+	options := planSyncOptions(plan.Options)
+	if err := options.Validate(); err != nil {
+		return options, err
+	}
+	current, err := buildSyncPlan(gitClient, githubClient, options, time.Now())
+	if err != nil {
+		return options, err
+	}
+
+	drift := syncPlanDrift(plan, current)
+	if len(drift) == 0 {
+		return options, nil
+	}
+	fmt.Fprintf(os.Stderr, "%s The repo changed since the plan was made:\n", ui.ErrorIcon())
+	for _, change := range drift {
+		fmt.Fprintf(os.Stderr, "  - %s\n", change)
+	}
+	fmt.Fprintf(os.Stderr, "\nMake a new plan with '%s'\n", ui.Command("stack sync --plan --json"))
+	return options, ui.Reported(errors.New("the repo changed since the plan was made"))
+}
+
+// syncPlanDrift lists how current differs from the planned state
+func syncPlanDrift(planned, current *syncPlan) []string {
+	var drift []string
+	if planned.Branch != current.Branch {
+		drift = append(drift, fmt.Sprintf("the plan starts from %s, but %s is checked out", planned.Branch, current.Branch))
+		return drift
+	}
+	if planned.Base != current.Base || planned.BaseSHA != current.BaseSHA {
+		drift = append(drift, fmt.Sprintf("%s moved from %s to %s", git.RemoteRef(planned.Base), ui.ShortSHA(planned.BaseSHA), ui.ShortSHA(current.BaseSHA)))
+	}
+
+	steps := make(map[string]syncPlanStep)
+	for _, step := range current.Steps {
+		steps[step.Branch] = step
+	}
+	for _, want := range planned.Steps {
+		got, ok := steps[want.Branch]
+		switch {
+		case !ok:
+			drift = append(drift, fmt.Sprintf("%s is no longer in the stack", want.Branch))
+		case got.Parent != want.Parent:
+			drift = append(drift, fmt.Sprintf("%s has parent %s instead of %s", want.Branch, got.Parent, want.Parent))
+		case got.LocalSHA != want.LocalSHA:
+			drift = append(drift, fmt.Sprintf("%s moved from %s to %s", want.Branch, ui.ShortSHA(want.LocalSHA), ui.ShortSHA(got.LocalSHA)))
+		case got.RemoteSHA != want.RemoteSHA:
+			drift = append(drift, fmt.Sprintf("%s moved from %s to %s", git.RemoteRef(want.Branch), ui.ShortSHA(want.RemoteSHA), ui.ShortSHA(got.RemoteSHA)))
+		case got.PRNumber != want.PRNumber || got.PRState != want.PRState || got.PRBase != want.PRBase:
+			drift = append(drift, fmt.Sprintf("the PR of %s changed", want.Branch))
+		case got.Action != want.Action || got.Onto != want.Onto || got.OntoSHA != want.OntoSHA:
+			drift = append(drift, fmt.Sprintf("%s would now %s instead of %s", want.Branch, got.Action, want.Action))
+		}
+		delete(steps, want.Branch)
+	}
+	for _, step := range current.Steps {
+		if _, added := steps[step.Branch]; added {
+			drift = append(drift, fmt.Sprintf("%s was added to the stack", step.Branch))
+		}
+	}
+	return drift
+}
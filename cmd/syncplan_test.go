@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setupSyncPlanMocks(prs map[string]*github.PRInfo) (*testutil.MockGitClient, *testutil.MockGitHubClient) {
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+
+	mockGit.On("GetCurrentBranch").Return("feature-c", nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}, nil)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+	mockGit.On("Fetch").Return(nil)
+	mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil)
+	mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "feature-a": true, "feature-b": true, "feature-c": true})
+	mockGH.On("GetPRsForBranches", mock.Anything).Return(prs, nil)
+
+	mockGit.On("GetCommitHash", "origin/main").Return("m2", nil)
+	mockGit.On("GetCommitHash", "feature-a").Return("a1", nil)
+	mockGit.On("GetCommitHash", "origin/feature-a").Return("a1", nil)
+	mockGit.On("GetCommitHash", "feature-b").Return("b1", nil)
+	mockGit.On("GetCommitHash", "origin/feature-b").Return("b1", nil)
+	mockGit.On("GetCommitHash", "feature-c").Return("c2", nil)
+	mockGit.On("GetCommitHash", "origin/feature-c").Return("c1", nil)
+	return mockGit, mockGH
+}
+
+func TestBuildSyncPlan(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("describes each branch of the stack", func(t *testing.T) {
+		mockGit, mockGH := setupSyncPlanMocks(map[string]*github.PRInfo{})
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("m1", nil)
+		mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("a1", nil)
+		mockGit.On("GetMergeBase", "feature-c", "feature-b").Return("b1", nil)
+
+		plan, err := buildSyncPlan(mockGit, mockGH, currentSyncOptions(), time.Unix(0, 0))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "m2", plan.BaseSHA)
+		var actions []string
+		for _, step := range plan.Steps {
+			actions = append(actions, step.Branch+":"+step.Action)
+		}
+		// feature-b is already on feature-a, but feature-a gets rebased below it
+		assert.Equal(t, []string{"feature-a:rebase", "feature-b:rebase", "feature-c:rebase"}, actions)
+	})
+
+	t.Run("skips merged branches and moves their children down", func(t *testing.T) {
+		mockGit, mockGH := setupSyncPlanMocks(map[string]*github.PRInfo{
+			"feature-a": {Number: 1, State: "MERGED", Base: "main"},
+			"feature-b": {Number: 2, State: "OPEN", Base: "feature-a"},
+		})
+		mockGit.On("GetMergeBase", "feature-b", "origin/main").Return("m2", nil)
+		mockGit.On("GetMergeBase", "feature-c", "feature-b").Return("b1", nil)
+
+		plan, err := buildSyncPlan(mockGit, mockGH, currentSyncOptions(), time.Unix(0, 0))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "skip-merged", plan.Steps[0].Action)
+		assert.Equal(t, "origin/main", plan.Steps[1].Onto)
+		assert.Equal(t, "none", plan.Steps[1].Action)
+		assert.Equal(t, "push", plan.Steps[2].Action)
+	})
+}
+
+func TestSyncPlanDrift(t *testing.T) {
+	planned := &syncPlan{
+		Branch:  "feature-b",
+		Base:    "main",
+		BaseSHA: "m1",
+		Steps: []syncPlanStep{
+			{Branch: "feature-a", Parent: "main", Action: "rebase", LocalSHA: "a1"},
+			{Branch: "feature-b", Parent: "feature-a", Action: "rebase", LocalSHA: "b1"},
+		},
+	}
+
+	assert.Empty(t, syncPlanDrift(planned, planned))
+
+	current := *planned
+	current.BaseSHA = "m2"
+	current.Steps = []syncPlanStep{
+		{Branch: "feature-a", Parent: "main", Action: "rebase", LocalSHA: "a2"},
+		{Branch: "feature-b", Parent: "feature-a", Action: "rebase", LocalSHA: "b1"},
+		{Branch: "feature-x", Parent: "feature-a", Action: "rebase", LocalSHA: "x1"},
+	}
+	assert.Equal(t, []string{
+		"origin/main moved from m1 to m2",
+		"feature-a moved from a1 to a2",
+		"feature-x was added to the stack",
+	}, syncPlanDrift(planned, &current))
+}
+
+func TestLoadSyncPlan(t *testing.T) {
+	dir := t.TempDir()
+	write := func(plan syncPlan) string {
+		path := filepath.Join(dir, "plan.json")
+		data, _ := json.Marshal(plan)
+		assert.NoError(t, os.WriteFile(path, data, 0o644))
+		return path
+	}
+
+	plan, err := loadSyncPlan(write(syncPlan{Version: syncPlanVersion, Branch: "feature-a"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "feature-a", plan.Branch)
+
+	_, err = loadSyncPlan(write(syncPlan{Version: syncPlanVersion + 1}))
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// setupHiddenWorktreeSync mocks a sync of feature-a <- feature-b from feature-b,
+// up to the rebases, with the hidden worktree created in commonDir
+func setupHiddenWorktreeSync(mockGit, worktreeGit *testutil.MockGitClient, mockGH *testutil.MockGitHubClient, commonDir string) string {
+	path := filepath.Join(commonDir, "stackinator/sync-worktree")
+
+	mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+	mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+	mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+	mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+	mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+	mockGit.On("IsWorkingTreeClean").Return(true, nil)
+	mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+	mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+	mockGit.On("GetDefaultBranch").Return("main").Maybe()
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+	}, nil).Maybe()
+	testutil.AllowPRPins(mockGit)
+	testutil.AllowStackUmbrellas(mockGit)
+	testutil.AllowSyncState(mockGit)
+	testutil.AllowPickedCommits(mockGit)
+	testutil.AllowPickedCommits(worktreeGit)
+	testutil.AllowSubmodules(mockGit)
+	testutil.AllowUpdateRefs(mockGit)
+	mockGit.On("Fetch").Return(nil)
+	mockGH.On("GetPRsForBranches", mock.Anything).Return(make(map[string]*github.PRInfo), nil)
+	mockGH.On("GetPRForBranch", mock.Anything).Return(nil, nil).Maybe()
+
+	// The hidden worktree
+	mockGit.On("GetConfig", "stack.sync.worktree").Return("")
+	mockGit.On("GetCommonDir").Return(commonDir, nil)
+	mockGit.On("GetBranchTips").Return(map[string]string{}, nil).Maybe()
+	mockGit.On("AddWorktreeDetached", path, "HEAD").Return(nil)
+	mockGit.On("InWorktree", path).Return(worktreeGit)
+
+	mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+	mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+	mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{
+		"main":      true,
+		"feature-a": true,
+		"feature-b": true,
+	})
+	mockGit.On("FetchBranch", mock.Anything).Return(nil)
+	mockGit.On("GetCommitHash", "feature-a").Return("abc123", nil)
+	mockGit.On("GetCommitHash", "origin/feature-a").Return("abc123", nil)
+	mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+	mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+	mockGit.On("GetCommitHash", "origin/main").Return("main123", nil)
+	mockGit.On("GetUniqueCommitsByPatch", "origin/main", "feature-a").Return([]string{"abc123"}, nil)
+	mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("main123", nil)
+	mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{"def456"}, nil)
+	mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("abc123", nil)
+	mockGit.On("PushWithExpectedRemote", "feature-a", "abc123").Return(nil)
+	mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+	return path
+}
+
+func TestRunSyncInHiddenWorktree(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("branches other than the current one are rebased in the hidden worktree", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		worktreeGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		path := setupHiddenWorktreeSync(mockGit, worktreeGit, mockGH, t.TempDir())
+
+		worktreeGit.On("CheckoutBranch", "feature-a").Return(nil)
+		worktreeGit.On("Rebase", "origin/main").Return(nil)
+		// feature-b is checked out here, so it's rebased here
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("Rebase", "feature-a").Return(nil)
+		mockGit.On("ForceRemoveWorktree", path).Return(nil).Once()
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "CheckoutBranch", "feature-a")
+		mockGit.AssertExpectations(t)
+		worktreeGit.AssertExpectations(t)
+	})
+
+	t.Run("a conflict is rebased again in the user's worktree", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		worktreeGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		path := setupHiddenWorktreeSync(mockGit, worktreeGit, mockGH, t.TempDir())
+
+		worktreeGit.On("CheckoutBranch", "feature-a").Return(nil)
+		worktreeGit.On("Rebase", "origin/main").Return(&git.ConflictError{Op: "rebase", Err: errors.New("conflict")})
+		worktreeGit.On("AbortRebase").Return(nil)
+		mockGit.On("ForceRemoveWorktree", path).Return(nil).Once()
+		mockGit.On("CheckoutBranch", "feature-a").Return(nil)
+		mockGit.On("Rebase", "origin/main").Return(nil)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("Rebase", "feature-a").Return(nil)
+
+		err := runSync(mockGit, mockGH)
+
+		assert.NoError(t, err)
+		mockGit.AssertExpectations(t)
+		worktreeGit.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// Git config keys for the look of spinners and status glyphs. stack.ui.theme
+// picks a built-in theme (default or minimal), and the others override parts of it.
+const (
+	configUITheme         = "stack.ui.theme"
+	configUISpinnerFrames = "stack.ui.spinnerFrames"
+	configUISuccessGlyph  = "stack.ui.successGlyph"
+	configUIWarningGlyph  = "stack.ui.warningGlyph"
+	configUIErrorGlyph    = "stack.ui.errorGlyph"
+	configUISuccessColor  = "stack.ui.successColor"
+	configUIWarningColor  = "stack.ui.warningColor"
+	configUIErrorColor    = "stack.ui.errorColor"
+)
+
+// configureTheme applies the theme from git config. An invalid setting is reported
+// and the rest of the theme still applies.
+func configureTheme(gitClient git.GitClient) {
+	theme, err := readTheme(gitClient)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, i18n.T("error", err))
+	}
+	ui.SetTheme(theme)
+}
+
+// readTheme builds the theme described by git config, skipping invalid settings
+func readTheme(gitClient git.GitClient) (ui.Theme, error) {
+	var errs []string
+	name := gitClient.GetConfig(configUITheme)
+	if name == "" {
+		name = "default"
+	}
+	theme, err := ui.LookupTheme(name)
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("%s: %v", configUITheme, err))
+		theme, _ = ui.LookupTheme("default")
+	}
+
+	// Frames are separated by spaces, so a frame can be several characters wide
+	if frames := strings.Fields(gitClient.GetConfig(configUISpinnerFrames)); len(frames) > 0 {
+		theme.Frames = frames
+	}
+
+	for _, setting := range []struct {
+		key   string
+		glyph *string
+	}{
+		{configUISuccessGlyph, &theme.SuccessGlyph},
+		{configUIWarningGlyph, &theme.WarningGlyph},
+		{configUIErrorGlyph, &theme.ErrorGlyph},
+	} {
+		if value := gitClient.GetConfig(setting.key); value != "" {
+			*setting.glyph = value
+		}
+	}
+
+	for _, setting := range []struct {
+		key   string
+		color **color.Color
+	}{
+		{configUISuccessColor, &theme.SuccessColor},
+		{configUIWarningColor, &theme.WarningColor},
+		{configUIErrorColor, &theme.ErrorColor},
+	} {
+		value := gitClient.GetConfig(setting.key)
+		if value == "" {
+			continue
+		}
+		parsed, err := ui.ParseColor(value)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", setting.key, err))
+			continue
+		}
+		*setting.color = parsed
+	}
+
+	if len(errs) > 0 {
+		return theme, fmt.Errorf("invalid theme settings: %s", strings.Join(errs, "; "))
+	}
+	return theme, nil
+}
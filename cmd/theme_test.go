@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadTheme(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("defaults when nothing is configured", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", mock.Anything).Return("")
+
+		theme, err := readTheme(mockGit)
+
+		require.NoError(t, err)
+		assert.Equal(t, "✓", theme.SuccessGlyph)
+		assert.Len(t, theme.Frames, 10)
+	})
+
+	t.Run("overrides parts of a built-in theme", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configUITheme).Return("minimal")
+		mockGit.On("GetConfig", configUISpinnerFrames).Return(".  .. ...")
+		mockGit.On("GetConfig", configUIErrorGlyph).Return("FAIL")
+		mockGit.On("GetConfig", configUISuccessColor).Return("bright-cyan")
+		mockGit.On("GetConfig", mock.Anything).Return("")
+
+		theme, err := readTheme(mockGit)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{".", "..", "..."}, theme.Frames)
+		assert.Equal(t, "+", theme.SuccessGlyph)
+		assert.Equal(t, "FAIL", theme.ErrorGlyph)
+		assert.Equal(t, color.New(color.FgHiCyan), theme.SuccessColor)
+	})
+
+	t.Run("reports invalid settings and keeps the rest", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configUITheme).Return("fancy")
+		mockGit.On("GetConfig", configUIWarningColor).Return("orange")
+		mockGit.On("GetConfig", configUIWarningGlyph).Return("!!")
+		mockGit.On("GetConfig", mock.Anything).Return("")
+
+		theme, err := readTheme(mockGit)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `unknown theme "fancy"`)
+		assert.Contains(t, err.Error(), `unknown color "orange"`)
+		assert.Equal(t, "✓", theme.SuccessGlyph)
+		assert.Equal(t, "!!", theme.WarningGlyph)
+	})
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateGolden rewrites the golden files instead of comparing with them:
+//
+//	go test ./cmd -run TestTreeRendering -update
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata/tree")
+
+// treeTopology is a stack layout rendered by the tree golden tests
+type treeTopology struct {
+	name      string
+	parents   map[string]string
+	current   string
+	prs       map[string]*github.PRInfo
+	umbrellas map[string]bool
+}
+
+// openPRs returns an open PR for each branch, numbered in the order given
+func openPRs(branches ...string) map[string]*github.PRInfo {
+	prs := make(map[string]*github.PRInfo)
+	for i, branch := range branches {
+		prs[branch] = testutil.NewPRInfo(i+1, "OPEN", "", branch, "https://github.com/org/repo/pull/"+strconv.Itoa(i+1))
+	}
+	return prs
+}
+
+var treeTopologies = []treeTopology{
+	{
+		name:    "linear",
+		parents: map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"},
+		current: "feature-b",
+		prs:     openPRs("feature-a", "feature-b", "feature-c"),
+	},
+	{
+		// feature-a splits into two sub-stacks, the way a diamond's sides leave its top
+		name: "diamond",
+		parents: map[string]string{
+			"feature-a":   "main",
+			"feature-b":   "feature-a",
+			"feature-c":   "feature-a",
+			"feature-b-2": "feature-b",
+			"feature-c-2": "feature-c",
+		},
+		current: "feature-c-2",
+		prs:     openPRs("feature-a", "feature-b", "feature-c", "feature-b-2", "feature-c-2"),
+	},
+	{
+		name: "multi-root",
+		parents: map[string]string{
+			"auth":          "main",
+			"auth-ui":       "auth",
+			"payments":      "main",
+			"payments-api":  "payments",
+			"payments-docs": "payments-api",
+		},
+		current: "payments-api",
+		prs:     openPRs("auth", "auth-ui", "payments", "payments-api"),
+	},
+	{
+		name:    "merged-middle",
+		parents: map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b", "feature-d": "feature-c"},
+		current: "feature-d",
+		prs: func() map[string]*github.PRInfo {
+			prs := openPRs("feature-a", "feature-b", "feature-c", "feature-d")
+			prs["feature-b"].State = "MERGED"
+			prs["feature-c"].State = "MERGED"
+			return prs
+		}(),
+	},
+	{
+		name:      "umbrella",
+		parents:   map[string]string{"epic": "main", "part-1": "epic", "part-2": "epic"},
+		current:   "epic",
+		prs:       openPRs("part-1", "part-2"),
+		umbrellas: map[string]bool{"epic": true},
+	},
+}
+
+// treeRenderers print a tree the way a command shows it
+var treeRenderers = []struct {
+	name   string
+	render func(gitClient git.GitClient, tree *stack.TreeNode, topology treeTopology)
+}{
+	{"status", func(gitClient git.GitClient, tree *stack.TreeNode, topology treeTopology) {
+		printStatusTree(gitClient, tree, topology.current, topology.prs, topology.umbrellas)
+	}},
+	{"show", func(gitClient git.GitClient, tree *stack.TreeNode, topology treeTopology) {
+		printLocalStackTree(tree, topology.current, topology.umbrellas, false)
+	}},
+	{"sync", func(gitClient git.GitClient, tree *stack.TreeNode, topology treeTopology) {
+		syncer.PrintTree(gitClient, tree, topology.current, topology.prs, topology.umbrellas)
+	}},
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestTreeRendering(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	for _, topology := range treeTopologies {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetAllStackParents").Return(topology.parents, nil)
+		mockGit.On("GetConfig", "stack.baseBranch").Return("")
+		mockGit.On("GetDefaultBranch").Return("main")
+
+		for _, renderer := range treeRenderers {
+			t.Run(renderer.name+"/"+topology.name, func(t *testing.T) {
+				// Built for each renderer, since the sync one filters it in place
+				tree, err := stack.BuildStackTree(mockGit)
+				require.NoError(t, err)
+				got := captureStdout(t, func() { renderer.render(mockGit, tree, topology) })
+
+				path := filepath.Join("testdata", "tree", renderer.name+"-"+topology.name+".golden")
+				if *updateGolden {
+					require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+					require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+					return
+				}
+				want, err := os.ReadFile(path)
+				require.NoError(t, err, "run with -update to create the golden file")
+				assert.Equal(t, string(want), got)
+			})
+		}
+	}
+}
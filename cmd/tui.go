@@ -0,0 +1,352 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/syncer"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: i18n.T("ui.short"),
+	Long:  i18n.T("ui.long"),
+	Example: `  # Browse and sync the stack interactively
+  stack ui`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runUI(gitClient); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runUI(gitClient git.GitClient) error {
+	if !spinner.IsTerminal() {
+		return errors.New(i18n.T("ui.needsTerminal"))
+	}
+	m, err := newUIModel(gitClient)
+	if err != nil {
+		return err
+	}
+	_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+// uiMode is what the arrow keys and Enter act on
+type uiMode int
+
+const (
+	uiBrowse     uiMode = iota // the tree, Enter checks out
+	uiMenu                     // the actions
+	uiPickParent               // the tree, Enter reparents the current branch
+)
+
+// uiActions are the entries of the actions menu
+var uiActions = []string{
+	"Sync the stack",
+	"Reparent the current branch",
+	"Prune merged branches",
+}
+
+// uiRow is a branch of the tree, flattened for display
+type uiRow struct {
+	name  string
+	depth int
+}
+
+// uiActionDoneMsg reports that an action run from the menu has finished
+type uiActionDoneMsg struct {
+	args []string
+	err  error
+}
+
+// uiModel is the state of 'stack ui'
+type uiModel struct {
+	gitClient  git.GitClient
+	rows       []uiRow
+	umbrellas  map[string]bool
+	current    string
+	cursor     int
+	mode       uiMode
+	menuCursor int
+	message    string
+}
+
+func newUIModel(gitClient git.GitClient) (*uiModel, error) {
+	m := &uiModel{gitClient: gitClient}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// reload reads the stacks again and puts the cursor on the current branch
+func (m *uiModel) reload() error {
+	current, err := m.gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	tree, err := stack.BuildStackTree(m.gitClient)
+	if err != nil {
+		return fmt.Errorf("failed to build stack tree: %w", err)
+	}
+	m.umbrellas, _ = m.gitClient.GetStackUmbrellas()
+
+	m.current = current
+	m.rows = nil
+	var walk func(node *stack.TreeNode, depth int)
+	walk = func(node *stack.TreeNode, depth int) {
+		m.rows = append(m.rows, uiRow{name: node.Name, depth: depth})
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	if tree != nil {
+		walk(tree, 0)
+	}
+
+	m.cursor = 0
+	for i, row := range m.rows {
+		if row.name == current {
+			m.cursor = i
+		}
+	}
+	return nil
+}
+
+func (m *uiModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case uiActionDoneMsg:
+		command := "stack " + strings.Join(msg.args, " ")
+		if msg.err != nil {
+			m.message = ui.Error(fmt.Sprintf("'%s' failed: %v", command, msg.err))
+		} else {
+			m.message = ui.Success(fmt.Sprintf("'%s' finished", command))
+		}
+		if err := m.reload(); err != nil {
+			m.message = ui.Error(err.Error())
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		key := msg.String()
+		if key == "ctrl+c" {
+			return m, tea.Quit
+		}
+		switch m.mode {
+		case uiMenu:
+			return m.updateMenu(key)
+		case uiPickParent:
+			return m.updatePickParent(key)
+		default:
+			return m.updateBrowse(key)
+		}
+	}
+	return m, nil
+}
+
+func (m *uiModel) updateBrowse(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "q", "esc":
+		return m, tea.Quit
+	case "up", "k", "down", "j":
+		m.moveCursor(key)
+	case "enter":
+		if len(m.rows) == 0 {
+			return m, nil
+		}
+		branch := m.rows[m.cursor].name
+		if branch == m.current {
+			return m, nil
+		}
+		if err := m.gitClient.CheckoutBranch(branch); err != nil {
+			m.message = ui.Error(fmt.Sprintf("Failed to check out %s: %v", branch, err))
+			return m, nil
+		}
+		m.current = branch
+		m.message = ui.Success(fmt.Sprintf("Checked out %s", ui.Branch(branch)))
+	case "m":
+		m.mode = uiMenu
+		m.menuCursor = 0
+		m.message = ""
+	}
+	return m, nil
+}
+
+func (m *uiModel) updateMenu(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "q", "esc", "m":
+		m.mode = uiBrowse
+	case "up", "k":
+		if m.menuCursor > 0 {
+			m.menuCursor--
+		}
+	case "down", "j":
+		if m.menuCursor < len(uiActions)-1 {
+			m.menuCursor++
+		}
+	case "enter":
+		m.mode = uiBrowse
+		switch m.menuCursor {
+		case 0:
+			return m, m.runAction("sync")
+		case 1:
+			m.mode = uiPickParent
+			m.message = fmt.Sprintf("Pick the new parent of %s", ui.Branch(m.current))
+		case 2:
+			return m, m.runAction("prune")
+		}
+	}
+	return m, nil
+}
+
+func (m *uiModel) updatePickParent(key string) (tea.Model, tea.Cmd) {
+	switch key {
+	case "q", "esc":
+		m.mode = uiBrowse
+		m.message = ""
+	case "up", "k", "down", "j":
+		m.moveCursor(key)
+	case "enter":
+		parent := m.rows[m.cursor].name
+		if parent == m.current {
+			m.message = ui.Warning("A branch can't be its own parent, pick another one")
+			return m, nil
+		}
+		m.mode = uiBrowse
+		return m, m.runAction("reparent", parent)
+	}
+	return m, nil
+}
+
+func (m *uiModel) moveCursor(key string) {
+	switch key {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.rows)-1 {
+			m.cursor++
+		}
+	}
+}
+
+// runAction runs 'stack <args>' in the terminal, leaving the full-screen view
+// until it finishes, with the global flags this run was given
+func (m *uiModel) runAction(args ...string) tea.Cmd {
+	executable, err := os.Executable()
+	if err != nil {
+		m.message = ui.Error(fmt.Sprintf("Failed to find the stack executable: %v", err))
+		return nil
+	}
+	return tea.Exec(&uiExec{cmd: exec.Command(executable, actionArgs(args...)...)}, func(err error) tea.Msg {
+		return uiActionDoneMsg{args: args, err: err}
+	})
+}
+
+// actionArgs is args followed by the global flags this run was given, so an
+// action works on the same repo, in the same modes
+func actionArgs(args ...string) []string {
+	cmdArgs := append([]string{}, args...)
+	if chdir != "" {
+		// The action may not start in the same directory, so pass it as an absolute path
+		dir, err := filepath.Abs(chdir)
+		if err != nil {
+			dir = chdir
+		}
+		cmdArgs = append(cmdArgs, "-C", dir)
+	}
+	flags := []struct {
+		set  bool
+		name string
+	}{
+		{dryRun, "--dry-run"},
+		{verbose, "--verbose"},
+		{readOnly, "--read-only"},
+		{offline, "--offline"},
+		{noColor, "--no-color"},
+		{assumeYes, "--yes"},
+	}
+	for _, flag := range flags {
+		if flag.set {
+			cmdArgs = append(cmdArgs, flag.name)
+		}
+	}
+	return cmdArgs
+}
+
+func (m *uiModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(ui.Dim("stack ui · ↑/↓ move · enter check out · m actions · q quit") + "\n\n")
+
+	// The tree always has the base branch
+	if len(m.rows) <= 1 {
+		sb.WriteString(i18n.T("stack.noBranches") + "\n\n")
+	}
+	for i, row := range m.rows {
+		pointer := "  "
+		if i == m.cursor && m.mode != uiMenu {
+			pointer = "› "
+		}
+		line := pointer + strings.Repeat("  ", row.depth) + ui.Branch(row.name) + syncer.UmbrellaLabel(m.umbrellas, row.name)
+		if row.name == m.current {
+			line += ui.CurrentBranchMarker()
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	if m.mode == uiMenu {
+		sb.WriteString("\n")
+		for i, action := range uiActions {
+			pointer := "  "
+			if i == m.menuCursor {
+				pointer = "› "
+			}
+			sb.WriteString(pointer + action + "\n")
+		}
+		sb.WriteString(ui.Dim("enter run · esc back") + "\n")
+	}
+
+	if m.message != "" {
+		sb.WriteString("\n" + m.message + "\n")
+	}
+	return sb.String()
+}
+
+// uiExec runs an action and waits for Enter afterwards, so its output can be
+// read before the full-screen view comes back
+type uiExec struct {
+	cmd *exec.Cmd
+}
+
+func (e *uiExec) Run() error {
+	err := e.cmd.Run()
+	fmt.Fprintf(e.cmd.Stdout, "\n%s", ui.Dim("Press Enter to return to stack ui"))
+	_, _ = bufio.NewReader(e.cmd.Stdin).ReadString('\n')
+	return err
+}
+
+func (e *uiExec) SetStdin(r io.Reader)  { e.cmd.Stdin = r }
+func (e *uiExec) SetStdout(w io.Writer) { e.cmd.Stdout = w }
+func (e *uiExec) SetStderr(w io.Writer) { e.cmd.Stderr = w }
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUIModel sets up 'stack ui' on main <- feature-a <- feature-b, from feature-a
+func newTestUIModel(t *testing.T, mockGit *testutil.MockGitClient) *uiModel {
+	mockGit.On("GetCurrentBranch").Return("feature-a", nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+	}, nil)
+	mockGit.On("GetConfig", "stack.baseBranch").Return("")
+	mockGit.On("GetDefaultBranch").Return("main")
+	mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+
+	m, err := newUIModel(mockGit)
+	require.NoError(t, err)
+	return m
+}
+
+func pressKey(m *uiModel, key string) tea.Cmd {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		msg = tea.KeyMsg{Type: tea.KeyEsc}
+	case "up":
+		msg = tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		msg = tea.KeyMsg{Type: tea.KeyDown}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	_, cmd := m.Update(msg)
+	return cmd
+}
+
+func TestUIModelNavigation(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	m := newTestUIModel(t, mockGit)
+
+	assert.Equal(t, []uiRow{{"main", 0}, {"feature-a", 1}, {"feature-b", 2}}, m.rows)
+	assert.Equal(t, 1, m.cursor, "the cursor starts on the current branch")
+
+	pressKey(m, "down")
+	pressKey(m, "down")
+	assert.Equal(t, 2, m.cursor, "the cursor stops at the last branch")
+	pressKey(m, "k")
+	pressKey(m, "up")
+	pressKey(m, "up")
+	assert.Equal(t, 0, m.cursor)
+
+	mockGit.On("CheckoutBranch", "main").Return(nil)
+	pressKey(m, "enter")
+	assert.Equal(t, "main", m.current)
+	assert.Contains(t, m.View(), "main *")
+
+	mockGit.On("CheckoutBranch", "feature-b").Return(errors.New("local changes would be overwritten"))
+	pressKey(m, "j")
+	pressKey(m, "j")
+	pressKey(m, "enter")
+	assert.Equal(t, "main", m.current, "a failed checkout leaves the current branch")
+	assert.Contains(t, m.message, "local changes would be overwritten")
+
+	mockGit.AssertExpectations(t)
+}
+
+func TestUIModelMenu(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	m := newTestUIModel(t, mockGit)
+
+	pressKey(m, "m")
+	assert.Equal(t, uiMenu, m.mode)
+	assert.Contains(t, m.View(), "Prune merged branches")
+	pressKey(m, "esc")
+	assert.Equal(t, uiBrowse, m.mode)
+
+	// Reparent asks for the new parent first
+	pressKey(m, "m")
+	pressKey(m, "down")
+	assert.Nil(t, pressKey(m, "enter"))
+	assert.Equal(t, uiPickParent, m.mode)
+
+	assert.Nil(t, pressKey(m, "enter"), "the current branch can't be its own parent")
+	assert.Equal(t, uiPickParent, m.mode)
+
+	pressKey(m, "up")
+	assert.NotNil(t, pressKey(m, "enter"))
+	assert.Equal(t, uiBrowse, m.mode)
+}
+
+func TestUIModelActionDone(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	m := newTestUIModel(t, mockGit)
+	m.cursor = 0
+
+	m.Update(uiActionDoneMsg{args: []string{"sync"}, err: errors.New("exit status 2")})
+
+	assert.Contains(t, m.message, "'stack sync' failed: exit status 2")
+	assert.Equal(t, 1, m.cursor, "the stack is reloaded")
+}
+
+func TestActionArgs(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	defer func() {
+		chdir, dryRun, verbose, readOnly, offline, noColor, assumeYes = "", false, false, false, false, false, false
+	}()
+
+	tests := []struct {
+		name     string
+		set      func()
+		expected []string
+	}{
+		{
+			name:     "no global flags",
+			set:      func() {},
+			expected: []string{"sync"},
+		},
+		{
+			name: "every global flag",
+			set: func() {
+				chdir = "/repos/other"
+				dryRun, verbose, readOnly, offline, noColor, assumeYes = true, true, true, true, true, true
+			},
+			expected: []string{"sync", "-C", "/repos/other", "--dry-run", "--verbose", "--read-only", "--offline", "--no-color", "--yes"},
+		},
+		{
+			name: "-C made absolute",
+			set: func() {
+				chdir = "../other"
+			},
+			expected: []string{"sync", "-C", filepath.Join(filepath.Dir(wd), "other")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chdir, dryRun, verbose, readOnly, offline, noColor, assumeYes = "", false, false, false, false, false, false
+			tt.set()
+
+			assert.Equal(t, tt.expected, actionArgs("sync"))
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunNewUmbrella(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("CheckBranchName", "payments").Return(nil)
+	mockGit.On("BranchExists", "payments").Return(false)
+	mockGit.On("BranchExists", "main").Return(true)
+	mockGit.On("CreateBranchAndCheckout", "payments", "main").Return(nil)
+	mockGit.On("SetConfig", "branch.payments.stackparent", "main").Return(nil)
+	mockGit.On("SetConfig", "branch.payments.stackumbrella", "true").Return(nil)
+
+	dryRun = true
+	newUmbrella = true
+	defer func() {
+		dryRun = false
+		newUmbrella = false
+	}()
+
+	err := runNew(mockGit, "payments", "main")
+
+	assert.NoError(t, err)
+	mockGit.AssertExpectations(t)
+}
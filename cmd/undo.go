@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/oplog"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// undoList lists the recorded operations instead of undoing one
+	undoList bool
+	// undoForce undoes even branches that changed since the operation
+	undoForce bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: i18n.T("undo.short"),
+	Long:  i18n.T("undo.long"),
+	Example: `  # Undo the last sync
+  stack undo
+
+  # See what would be restored
+  stack undo --dry-run
+
+  # List the operations that can be undone, most recent first
+  stack undo --list`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		var err error
+		if undoList {
+			err = runUndoList(gitClient)
+		} else {
+			err = runUndo(gitClient)
+		}
+		if err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	undoCmd.Flags().BoolVar(&undoList, "list", false, "List the operations that can be undone, most recent first")
+	undoCmd.Flags().BoolVar(&undoForce, "force", false, "Undo even branches that changed since the operation, discarding those changes")
+}
+
+func runUndoList(gitClient git.GitClient) error {
+	entries, err := oplog.Read(gitClient)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to undo: no sync, restack or reparent recorded")
+		return nil
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		fmt.Printf("  %s\n", entries[i].Describe())
+	}
+	return nil
+}
+
+func runUndo(gitClient git.GitClient) error {
+	entries, err := oplog.Read(gitClient)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Println("Nothing to undo: no sync, restack or reparent recorded")
+		return nil
+	}
+	if op := gitClient.GetOperationInProgress(); op != nil {
+		return fmt.Errorf("a %s is in progress; finish or abort it first", op.Kind)
+	}
+	entry := entries[len(entries)-1]
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	tips, err := gitClient.GetBranchTips()
+	if err != nil {
+		return fmt.Errorf("failed to get branch tips: %w", err)
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+	worktrees, err := gitClient.GetWorktreeBranches()
+	if err != nil {
+		worktrees = make(map[string]string)
+	}
+	currentWorktreePath, _ := gitClient.GetCurrentWorktreePath()
+
+	// Work out what the operation changed. One that never finished didn't record
+	// what it left, so every change since it started counts.
+	finished := entry.After != nil
+	names := make([]string, 0, len(entry.Parents))
+	for name := range entry.Parents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tipsToRestore, parentsToRestore, changedSince []string
+	for _, name := range names {
+		changed := false
+		if before, recorded := entry.Tips[name]; recorded && tips[name] != before {
+			after := tips[name]
+			if finished {
+				after = entry.After[name]
+			}
+			if after != before {
+				tipsToRestore = append(tipsToRestore, name)
+				changed = tips[name] != after
+			}
+		}
+		if parents[name] != entry.Parents[name] {
+			after := parents[name]
+			if finished {
+				after = entry.AfterParents[name]
+			}
+			if after != entry.Parents[name] {
+				parentsToRestore = append(parentsToRestore, name)
+				changed = changed || parents[name] != after
+			}
+		}
+		if changed {
+			changedSince = append(changedSince, name)
+		}
+	}
+
+	if !undoForce {
+		if !finished && len(tipsToRestore)+len(parentsToRestore) > 0 {
+			return errors.New(i18n.T("undo.unfinished", entry.Operation, ui.Command("stack undo --force")))
+		}
+		if len(changedSince) > 0 {
+			return errors.New(i18n.T("undo.changedSince", strings.Join(changedSince, ", "), entry.Operation, ui.Command("stack undo --force")))
+		}
+	}
+
+	// The current branch is reset, so changes in the working tree would be lost
+	for _, name := range tipsToRestore {
+		if name != currentBranch {
+			continue
+		}
+		clean, err := gitClient.IsWorkingTreeClean()
+		if err != nil {
+			return fmt.Errorf("failed to check working tree status: %w", err)
+		}
+		if !clean {
+			return fmt.Errorf("working tree has uncommitted changes, commit or stash them before undoing")
+		}
+	}
+
+	fmt.Printf("Undoing the %s\n", entry.Describe())
+
+	restored := 0
+	var moved []string
+	for _, name := range tipsToRestore {
+		done, err := restoreTip(gitClient, name, entry.Tips[name], tips[name] != "", currentBranch, worktrees, currentWorktreePath)
+		if err != nil {
+			return err
+		}
+		if done {
+			restored++
+			if _, onOrigin := tips[git.RemoteRef(name)]; onOrigin {
+				moved = append(moved, name)
+			}
+		}
+	}
+	for _, name := range parentsToRestore {
+		parent := entry.Parents[name]
+		key := fmt.Sprintf("branch.%s.stackparent", name)
+		if parent == "" {
+			err = gitClient.UnsetConfig(key)
+		} else {
+			err = gitClient.SetConfig(key, parent)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to restore the parent of %s: %w", name, err)
+		}
+		if parent == "" {
+			fmt.Printf("  %s Removed %s from the stack\n", ui.SuccessIcon(), ui.Branch(name))
+		} else {
+			fmt.Printf("  %s Set the parent of %s back to %s\n", ui.SuccessIcon(), ui.Branch(name), ui.Branch(parent))
+		}
+		restored++
+	}
+
+	if dryRun {
+		return nil
+	}
+	if err := oplog.Write(gitClient, entries[:len(entries)-1]); err != nil {
+		return err
+	}
+	if restored == 0 {
+		fmt.Println("Nothing changed since then, dropped it from the log")
+		return nil
+	}
+	fmt.Println(ui.Success(fmt.Sprintf("Undid the %s", entry.Operation)))
+	if len(moved) > 0 {
+		fmt.Printf("\n%s still has the newer commits of %s; push them again to restore them there,\n", git.RemoteOf(moved[0]), strings.Join(moved, ", "))
+		fmt.Printf("e.g. %s\n", ui.Command("git push --force-with-lease "+git.RemoteOf(moved[0])+" "+moved[0]))
+	}
+	return nil
+}
+
+// restoreTip points branch back at tip, recreating it if it was deleted and
+// resetting it if it's the current branch. A branch checked out in another
+// worktree is left for the user. Reports whether it was restored.
+func restoreTip(gitClient git.GitClient, branch, tip string, exists bool, currentBranch string, worktrees map[string]string, currentWorktreePath string) (bool, error) {
+	switch {
+	case !exists:
+		if err := gitClient.CreateBranch(branch, tip); err != nil {
+			return false, fmt.Errorf("failed to recreate %s: %w", branch, err)
+		}
+		fmt.Printf("  %s Recreated %s at %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.ShortSHA(tip))
+	case branch == currentBranch:
+		if err := gitClient.ResetHard(tip); err != nil {
+			return false, fmt.Errorf("failed to reset %s: %w", branch, err)
+		}
+		fmt.Printf("  %s Reset %s to %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.ShortSHA(tip))
+	default:
+		if path, ok := worktrees[branch]; ok && path != currentWorktreePath {
+			fmt.Printf("  %s Skipped %s, checked out in %s (reset it there to %s)\n", ui.WarningIcon(), ui.Branch(branch), path, ui.ShortSHA(tip))
+			return false, nil
+		}
+		if err := gitClient.ForceBranch(branch, tip); err != nil {
+			return false, fmt.Errorf("failed to move %s: %w", branch, err)
+		}
+		fmt.Printf("  %s Moved %s back to %s\n", ui.SuccessIcon(), ui.Branch(branch), ui.ShortSHA(tip))
+	}
+	return true, nil
+}
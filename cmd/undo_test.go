@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/oplog"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunUndo(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	// The sync rebased feature-c onto feature-a after feature-b merged and was deleted
+	synced := map[string]string{"feature-a": "sha-a", "feature-b": "", "feature-c": "new-c"}
+	syncedParents := map[string]string{"feature-a": "main", "feature-b": "", "feature-c": "feature-a"}
+	// The sync only rebased feature-b
+	rebasedB := map[string]string{"feature-a": "sha-a", "feature-b": "new-b", "feature-c": "sha-c"}
+	unchangedParents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b"}
+
+	tests := []struct {
+		name          string
+		noLog         bool
+		currentBranch string
+		after         map[string]string
+		afterParents  map[string]string
+		force         bool
+		setupMocks    func(*testutil.MockGitClient)
+		expectError   bool
+		errorContains string
+		expectEntries int
+	}{
+		{
+			name:          "restores the tips and parents the last operation changed",
+			currentBranch: "feature-a",
+			after:         synced,
+			afterParents:  syncedParents,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetBranchTips").Return(map[string]string{
+					"feature-a": "sha-a", "feature-c": "new-c", "origin/feature-c": "new-c",
+				}, nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-c": "feature-a"}, nil)
+				mockGit.On("CreateBranch", "feature-b", "sha-b").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+				mockGit.On("ForceBranch", "feature-c", "sha-c").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-b").Return(nil)
+			},
+			expectEntries: 1,
+		},
+		{
+			name:          "resets the current branch",
+			currentBranch: "feature-b",
+			after:         rebasedB,
+			afterParents:  unchangedParents,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetBranchTips").Return(rebasedB, nil)
+				mockGit.On("GetAllStackParents").Return(unchangedParents, nil)
+				mockGit.On("IsWorkingTreeClean").Return(true, nil)
+				mockGit.On("ResetHard", "sha-b").Return(nil)
+			},
+			expectEntries: 1,
+		},
+		{
+			name:          "refuses to reset the current branch over uncommitted changes",
+			currentBranch: "feature-b",
+			after:         rebasedB,
+			afterParents:  unchangedParents,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No ResetHard: it would throw the changes away
+				mockGit.On("GetBranchTips").Return(rebasedB, nil)
+				mockGit.On("GetAllStackParents").Return(unchangedParents, nil)
+				mockGit.On("IsWorkingTreeClean").Return(false, nil)
+			},
+			expectError:   true,
+			errorContains: "uncommitted changes",
+			expectEntries: 2,
+		},
+		{
+			name:          "leaves branches the operation didn't touch alone",
+			currentBranch: "feature-a",
+			after:         rebasedB,
+			afterParents:  unchangedParents,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No ResetHard: feature-a got a new commit after the sync, which didn't touch it
+				mockGit.On("GetBranchTips").Return(map[string]string{"feature-a": "a2", "feature-b": "new-b", "feature-c": "sha-c"}, nil)
+				mockGit.On("GetAllStackParents").Return(unchangedParents, nil)
+				mockGit.On("ForceBranch", "feature-b", "sha-b").Return(nil)
+			},
+			expectEntries: 1,
+		},
+		{
+			name:          "refuses when a branch changed since the operation",
+			currentBranch: "feature-a",
+			after:         synced,
+			afterParents:  syncedParents,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No ForceBranch or CreateBranch: feature-c would lose c2
+				mockGit.On("GetBranchTips").Return(map[string]string{"feature-a": "sha-a", "feature-c": "c2"}, nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-c": "feature-a"}, nil)
+			},
+			expectError:   true,
+			errorContains: "feature-c changed since the sync",
+			expectEntries: 2,
+		},
+		{
+			name:          "undoes a branch changed since with --force",
+			currentBranch: "feature-a",
+			after:         synced,
+			afterParents:  syncedParents,
+			force:         true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetBranchTips").Return(map[string]string{"feature-a": "sha-a", "feature-c": "c2"}, nil)
+				mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-c": "feature-a"}, nil)
+				mockGit.On("CreateBranch", "feature-b", "sha-b").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-b.stackparent", "feature-a").Return(nil)
+				mockGit.On("ForceBranch", "feature-c", "sha-c").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-c.stackparent", "feature-b").Return(nil)
+			},
+			expectEntries: 1,
+		},
+		{
+			name:          "refuses to undo an operation that didn't finish",
+			currentBranch: "feature-a",
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No ForceBranch: without the tips it left, nothing can be checked
+				mockGit.On("GetBranchTips").Return(rebasedB, nil)
+				mockGit.On("GetAllStackParents").Return(unchangedParents, nil)
+			},
+			expectError:   true,
+			errorContains: "didn't finish",
+			expectEntries: 2,
+		},
+		{
+			name:       "nothing recorded",
+			noLog:      true,
+			setupMocks: func(mockGit *testutil.MockGitClient) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			undoForce = tt.force
+			defer func() { undoForce = false }()
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+			if !tt.noLog {
+				assert.NoError(t, oplog.Write(mockGit, []oplog.Entry{
+					{Operation: "restack", Branch: "feature-a", Tips: map[string]string{"feature-a": "old-a"}, Parents: map[string]string{"feature-a": "main"}},
+					{
+						Operation: "sync",
+						Branch:    "feature-b",
+						Tips:      map[string]string{"feature-a": "sha-a", "feature-b": "sha-b", "feature-c": "sha-c"},
+						Parents:   unchangedParents,
+
+						After:        tt.after,
+						AfterParents: tt.afterParents,
+					},
+				}))
+				mockGit.On("GetOperationInProgress").Return(nil)
+				mockGit.On("GetCurrentBranch").Return(tt.currentBranch, nil)
+				mockGit.On("GetWorktreeBranches").Return(map[string]string{tt.currentBranch: "/repo"}, nil)
+				mockGit.On("GetCurrentWorktreePath").Return("/repo", nil)
+			}
+			tt.setupMocks(mockGit)
+
+			err := runUndo(mockGit)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, tt.errorContains)
+			} else {
+				assert.NoError(t, err)
+			}
+			entries, _ := oplog.Read(mockGit)
+			assert.Len(t, entries, tt.expectEntries)
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -1,29 +1,26 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
-	"os"
 
 	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
 )
 
 var upCmd = &cobra.Command{
 	Use:   "up",
-	Short: "Move to the parent branch in the stack",
-	Long: `Checkout the parent branch of the current branch in the stack.
-
-If the current branch has no parent (is at the root of the stack),
-an error message will be displayed.`,
+	Short: i18n.T("up.short"),
+	Long:  i18n.T("up.long"),
 	Example: `  # Move to parent branch
   stack up`,
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
 
 		if err := runUp(gitClient); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -39,7 +36,7 @@ func runUp(gitClient git.GitClient) error {
 	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", currentBranch))
 
 	if parent == "" {
-		return fmt.Errorf("already at stack root (no parent for %s)", currentBranch)
+		return errors.New(i18n.T("up.atRoot", currentBranch))
 	}
 
 	// Checkout the parent branch
@@ -47,6 +44,6 @@ func runUp(gitClient git.GitClient) error {
 		return fmt.Errorf("failed to checkout parent branch %s: %w", parent, err)
 	}
 
-	fmt.Printf("Switched to parent branch: %s\n", ui.Branch(parent))
+	fmt.Println(i18n.T("up.switched", ui.Branch(parent)))
 	return nil
 }
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var upliftCmd = &cobra.Command{
+	Use:   "uplift <new-branch>",
+	Short: i18n.T("uplift.short"),
+	Long:  i18n.T("uplift.long"),
+	Example: `  # Oops, committed on main
+  stack uplift feature-auth
+
+  # Preview what would be moved
+  stack uplift feature-auth --dry-run`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		gitClient := git.NewGitClient()
+
+		if err := runUplift(gitClient, args[0]); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func runUplift(gitClient git.GitClient, branchName string) error {
+	if err := validateBranchName(gitClient, branchName); err != nil {
+		return err
+	}
+	if gitClient.BranchExists(branchName) {
+		return fmt.Errorf("branch %s already exists", branchName)
+	}
+
+	baseBranch := stack.GetBaseBranch(gitClient)
+	remoteBase := git.RemoteRef(baseBranch)
+	if err := gitClient.FetchBranch(baseBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: failed to fetch %s, using the last fetched state: %v\n", ui.Branch(baseBranch), err)
+	}
+
+	commits, err := gitClient.GetUniqueCommits(remoteBase, baseBranch)
+	if err != nil {
+		return fmt.Errorf("failed to compare %s with %s: %w", baseBranch, remoteBase, err)
+	}
+	if len(commits) == 0 {
+		fmt.Printf("%s has no local commits that aren't on %s\n", ui.Branch(baseBranch), ui.Branch(remoteBase))
+		return nil
+	}
+
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	fmt.Printf("Moving %d commit(s) from %s into %s\n", len(commits), ui.Branch(baseBranch), ui.Branch(branchName))
+
+	// Switching to the new branch keeps uncommitted changes, and frees the base
+	// branch so it can be moved without touching the working tree
+	if currentBranch == baseBranch {
+		err = gitClient.CreateBranchAndCheckout(branchName, baseBranch)
+	} else {
+		err = gitClient.CreateBranch(branchName, baseBranch)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+
+	if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", branchName), baseBranch); err != nil {
+		return fmt.Errorf("failed to set parent config: %w", err)
+	}
+
+	if err := gitClient.ForceBranch(baseBranch, remoteBase); err != nil {
+		return fmt.Errorf("created %s, but failed to reset %s to %s (reset it yourself with '%s'): %w",
+			branchName, baseBranch, remoteBase, ui.Command(fmt.Sprintf("git branch --force %s %s", baseBranch, remoteBase)), err)
+	}
+
+	if !dryRun {
+		fmt.Println(ui.Success(fmt.Sprintf("Created branch %s with parent %s, and reset %s to %s", ui.Branch(branchName), ui.Branch(baseBranch), ui.Branch(baseBranch), ui.Branch(remoteBase))))
+		fmt.Printf("Push it and open a PR with '%s'\n", ui.Command("stack sync --create-prs"))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunUplift(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name       string
+		commits    []string
+		setupMocks func(*testutil.MockGitClient)
+	}{
+		{
+			name:    "moves commits off the checked out base branch",
+			commits: []string{"c1", "c2"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCurrentBranch").Return("main", nil)
+				mockGit.On("CreateBranchAndCheckout", "feature-auth", "main").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-auth.stackparent", "main").Return(nil)
+				mockGit.On("ForceBranch", "main", "origin/main").Return(nil)
+			},
+		},
+		{
+			name:    "leaves the current branch checked out",
+			commits: []string{"c1"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetCurrentBranch").Return("other", nil)
+				mockGit.On("CreateBranch", "feature-auth", "main").Return(nil)
+				mockGit.On("SetConfig", "branch.feature-auth.stackparent", "main").Return(nil)
+				mockGit.On("ForceBranch", "main", "origin/main").Return(nil)
+			},
+		},
+		{
+			name:    "does nothing without local commits",
+			commits: []string{},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No CreateBranch or ForceBranch: main has nothing to move
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("CheckBranchName", "feature-auth").Return(nil)
+			mockGit.On("BranchExists", "feature-auth").Return(false)
+			mockGit.On("GetConfig", "stack.baseBranch").Return("")
+			mockGit.On("GetDefaultBranch").Return("main")
+			mockGit.On("FetchBranch", "main").Return(nil)
+			mockGit.On("GetUniqueCommits", "origin/main", "main").Return(tt.commits, nil)
+			tt.setupMocks(mockGit)
+
+			err := runUplift(mockGit, "feature-auth")
+
+			assert.NoError(t, err)
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
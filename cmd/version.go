@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/javoire/stackinator/internal/i18n"
 	"github.com/spf13/cobra"
 )
 
@@ -15,8 +16,8 @@ var (
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "Print version information",
-	Long:  `Print the version, commit hash, and build date of this stack binary.`,
+	Short: i18n.T("version.short"),
+	Long:  i18n.T("version.long"),
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("stack version %s\n", version)
 		fmt.Printf("  commit: %s\n", commit)
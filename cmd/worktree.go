@@ -7,8 +7,12 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/forge"
 	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/script"
 	"github.com/javoire/stackinator/internal/spinner"
 	"github.com/javoire/stackinator/internal/ui"
 	"github.com/spf13/cobra"
@@ -18,13 +22,8 @@ var worktreePrune bool
 
 var worktreeCmd = &cobra.Command{
 	Use:   "worktree <branch-name> [base-branch]",
-	Short: "Create a worktree in .worktrees/ directory",
-	Long: `Create a git worktree in the .worktrees/ directory for the specified branch.
-
-If the branch exists locally or on the remote, it will be used.
-If the branch doesn't exist, a new branch will be created from the current branch
-(or from base-branch if specified) and stack tracking will be set up automatically.
-Use --prune to clean up worktrees for branches with merged PRs.`,
+	Short: i18n.T("worktree.short"),
+	Long:  i18n.T("worktree.long"),
 	Example: `  # Create worktree for new branch (from current branch, with stack tracking)
   stack worktree my-feature
 
@@ -53,8 +52,7 @@ Use --prune to clean up worktrees for branches with merged PRs.`,
 	},
 	Run: func(cmd *cobra.Command, args []string) {
 		gitClient := git.NewGitClient()
-		repo := github.ParseRepoFromURL(gitClient.GetRemoteURL("origin"))
-		githubClient := github.NewGitHubClient(repo)
+		githubClient := forge.NewClient(gitClient.GetRemoteURL(git.Remote))
 
 		var err error
 		if worktreePrune {
@@ -67,8 +65,7 @@ Use --prune to clean up worktrees for branches with merged PRs.`,
 			err = runWorktree(gitClient, githubClient, args[0], baseBranch)
 		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
+			exitWithError(err)
 		}
 	},
 }
@@ -120,7 +117,7 @@ func createNewBranchWorktree(gitClient git.GitClient, branchName, baseBranch, wo
 	// Use origin/baseBranch if it's a remote branch to get fresh copy
 	baseRef := baseBranch
 	if gitClient.RemoteBranchExists(baseBranch) {
-		baseRef = "origin/" + baseBranch
+		baseRef = git.RemoteRef(baseBranch)
 	}
 
 	fmt.Printf("Creating new branch %s from %s\n", ui.Branch(branchName), ui.Branch(baseRef))
@@ -166,7 +163,7 @@ func createWorktreeForExisting(gitClient git.GitClient, branchName, worktreePath
 			return fmt.Errorf("failed to create worktree: %w", err)
 		}
 		if !dryRun {
-			fmt.Println(ui.Success(fmt.Sprintf("Created worktree at %s (tracking origin/%s)", worktreePath, branchName)))
+			fmt.Println(ui.Success(fmt.Sprintf("Created worktree at %s (tracking %s)", worktreePath, git.RemoteRef(branchName))))
 			fmt.Printf("\nTo switch to this worktree, run:\n  %s\n", ui.Command(fmt.Sprintf("cd %s", worktreePath)))
 		}
 		return nil
@@ -241,7 +238,12 @@ func runWorktreePrune(gitClient git.GitClient, githubClient github.GitHubClient)
 	var prCache map[string]*github.PRInfo
 	if err := spinner.WrapWithSuccess("Fetching PRs...", "Fetched PRs", func() error {
 		var prErr error
-		prCache, prErr = githubClient.GetAllPRs()
+		branches := make([]string, 0, len(worktreesToCheck))
+		for _, wt := range worktreesToCheck {
+			branches = append(branches, wt.branch)
+		}
+		// GetAllPRs only lists open PRs, so merged ones have to be looked up by branch
+		prCache, prErr = githubClient.GetPRsForBranches(branches)
 		return prErr
 	}); err != nil {
 		return fmt.Errorf("failed to fetch PRs: %w", err)
@@ -290,7 +292,7 @@ func runWorktreePrune(gitClient git.GitClient, githubClient github.GitHubClient)
 
 	fmt.Println()
 	fmt.Println(ui.Success("Worktree prune complete!"))
-	fmt.Printf("Tip: Run '%s' to also delete the merged branches.\n", ui.Command("stack prune"))
+	fmt.Println(i18n.T("worktree.pruneHint", ui.Command("stack prune")))
 
 	return nil
 }
@@ -319,7 +321,12 @@ func ensureWorktreesIgnored(repoRoot string) error {
 	}
 
 	if dryRun {
-		fmt.Println("  [DRY RUN] Adding .worktrees to .gitignore")
+		dryrun.Record(dryrun.Other)
+		if script.Enabled {
+			script.Line("echo .worktrees >> " + script.Quote(gitignorePath))
+		} else {
+			fmt.Println("  [DRY RUN] Adding .worktrees to .gitignore")
+		}
 		return nil
 	}
 
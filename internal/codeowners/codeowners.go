@@ -0,0 +1,118 @@
+package codeowners
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Locations are where GitHub looks for a CODEOWNERS file, in order of precedence
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is one line of a CODEOWNERS file
+type Rule struct {
+	Pattern string
+	Owners  []string // e.g. "@alice", "@org/team"; empty means the path has no owners
+
+	re *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file. Later rules take precedence, as on GitHub.
+type File struct {
+	Rules []Rule
+}
+
+// Parse parses the content of a CODEOWNERS file. Comments, blank lines and
+// patterns that can't be compiled are skipped.
+func Parse(content string) *File {
+	file := &File{}
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		re, err := regexp.Compile(patternToRegexp(fields[0]))
+		if err != nil {
+			continue
+		}
+		file.Rules = append(file.Rules, Rule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	return file
+}
+
+// Owners returns the owners of path (relative to the repo root), taken from the
+// last rule that matches it
+func (f *File) Owners(path string) []string {
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return f.Rules[i].Owners
+		}
+	}
+	return nil
+}
+
+// OwnersOf returns the owners of any of paths, those owning the most paths first
+func (f *File) OwnersOf(paths []string) []string {
+	counts := make(map[string]int)
+	for _, path := range paths {
+		for _, owner := range f.Owners(path) {
+			counts[owner]++
+		}
+	}
+
+	owners := make([]string, 0, len(counts))
+	for owner := range counts {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if counts[owners[i]] != counts[owners[j]] {
+			return counts[owners[i]] > counts[owners[j]]
+		}
+		return owners[i] < owners[j]
+	})
+	return owners
+}
+
+// patternToRegexp translates a gitignore-style CODEOWNERS pattern. A pattern
+// with a leading or inner slash is anchored at the repo root, otherwise it can
+// match at any depth. A match on a directory also covers everything under it.
+func patternToRegexp(pattern string) string {
+	dirOnly := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	var re strings.Builder
+	if anchored {
+		re.WriteString("^")
+	} else {
+		re.WriteString("^(?:.*/)?")
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "/**") && i+3 == len(pattern):
+			re.WriteString("/.*")
+			i += 2
+		case pattern[i] == '*':
+			re.WriteString("[^/]*")
+		case pattern[i] == '?':
+			re.WriteString("[^/]")
+		default:
+			re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	if dirOnly {
+		re.WriteString("/.*$")
+	} else {
+		re.WriteString("(?:/.*)?$")
+	}
+	return re.String()
+}
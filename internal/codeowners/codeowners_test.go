@@ -0,0 +1,51 @@
+package codeowners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `# Default owners
+*                @org/core
+
+*.md             @docs-team   # docs anywhere
+/cmd/            @alice
+internal/git/    @bob @carol
+**/testdata/**   @qa
+/scripts/build
+`
+
+func TestOwners(t *testing.T) {
+	file := Parse(sample)
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{"main.go", []string{"@org/core"}},
+		{"README.md", []string{"@docs-team"}},
+		{"docs/how-it-works.md", []string{"@docs-team"}},
+		{"cmd/sync.go", []string{"@alice"}},
+		{"cmd/README.md", []string{"@alice"}},
+		{"internal/git/git.go", []string{"@bob", "@carol"}},
+		{"other/internal/git/git.go", []string{"@org/core"}},
+		{"internal/stack/testdata/tree.golden", []string{"@qa"}},
+		{"scripts/build", []string{}},
+		{"scripts/test", []string{"@org/core"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, file.Owners(tt.path))
+		})
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	file := Parse(sample)
+
+	owners := file.OwnersOf([]string{"cmd/sync.go", "cmd/status.go", "internal/git/git.go"})
+
+	assert.Equal(t, []string{"@alice", "@bob", "@carol"}, owners)
+}
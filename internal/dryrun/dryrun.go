@@ -0,0 +1,56 @@
+package dryrun
+
+import "sync"
+
+// Kind is the sort of change a dry run skipped, as counted in its summary
+type Kind int
+
+const (
+	// Push is a branch or ref pushed to the remote
+	Push Kind = iota
+	// Delete is a branch, ref, worktree or file removed, locally or on the remote
+	Delete
+	// Config is a git config value set or unset
+	Config
+	// PREdit is a pull request (or merge request) created, edited or closed
+	PREdit
+	// Other is any other change, e.g. a rebase, a checkout or a file written
+	Other
+)
+
+var (
+	mu     sync.Mutex
+	counts = map[Kind]int{}
+)
+
+// Record counts a change skipped because of the dry run
+func Record(kind Kind) {
+	mu.Lock()
+	defer mu.Unlock()
+	counts[kind]++
+}
+
+// Count returns how many changes of kind were skipped
+func Count(kind Kind) int {
+	mu.Lock()
+	defer mu.Unlock()
+	return counts[kind]
+}
+
+// Total returns how many changes were skipped altogether
+func Total() int {
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
+
+// Reset clears the counts
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	counts = map[Kind]int{}
+}
@@ -0,0 +1,24 @@
+package dryrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	Record(Push)
+	Record(Push)
+	Record(Config)
+
+	assert.Equal(t, 2, Count(Push))
+	assert.Equal(t, 1, Count(Config))
+	assert.Equal(t, 0, Count(Delete))
+	assert.Equal(t, 3, Total())
+
+	Reset()
+	assert.Equal(t, 0, Total())
+}
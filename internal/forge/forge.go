@@ -0,0 +1,46 @@
+package forge
+
+import (
+	"strings"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/gitlab"
+)
+
+// Client is what commands use to look up and update PRs. GitHub PRs and GitLab
+// merge requests both sit behind it.
+type Client = github.GitHubClient
+
+// PRInfo is a pull or merge request
+type PRInfo = github.PRInfo
+
+// The supported forges
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Override picks the forge instead of detecting it from the origin URL
+// (stack.forge), for self-hosted GitLab on a host not named after it
+var Override = ""
+
+// Detect returns the forge hosting remoteURL. Hosts with "gitlab" in their name
+// are GitLab, everything else GitHub.
+func Detect(remoteURL string) string {
+	if Override != "" {
+		return Override
+	}
+	host, _ := gitlab.ParseProjectFromURL(remoteURL)
+	if strings.Contains(strings.ToLower(host), "gitlab") {
+		return GitLab
+	}
+	return GitHub
+}
+
+// NewClient returns the client for the forge hosting remoteURL
+func NewClient(remoteURL string) Client {
+	if Detect(remoteURL) == GitLab {
+		return gitlab.NewGitLabClient(gitlab.ParseProjectFromURL(remoteURL))
+	}
+	return github.NewGitHubClient(github.ParseRepoFromURL(remoteURL))
+}
@@ -0,0 +1,32 @@
+package forge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	defer func() { Override = "" }()
+
+	tests := []struct {
+		name      string
+		remoteURL string
+		override  string
+		expected  string
+	}{
+		{"github.com", "git@github.com:owner/repo.git", "", GitHub},
+		{"GitHub Enterprise", "https://ghe.example.com/owner/repo", "", GitHub},
+		{"gitlab.com over SSH", "git@gitlab.com:group/project.git", "", GitLab},
+		{"self-hosted GitLab", "https://gitlab.example.com/group/sub/project.git", "", GitLab},
+		{"no origin", "", "", GitHub},
+		{"override", "git@git.example.com:group/project.git", GitLab, GitLab},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Override = tt.override
+			assert.Equal(t, tt.expected, Detect(tt.remoteURL))
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Hunk is one hunk of a diff without context lines. For a pure addition
+// OldStart is the line after which the new lines go, with OldLines 0.
+type Hunk struct {
+	Path     string
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	// Lines are the hunk's -, + and "\ No newline at end of file" lines
+	Lines []string
+}
+
+// GetStagedHunks returns the hunks of the staged changes to files that exist in
+// HEAD. New, deleted and binary files, mode changes and paths git has to quote
+// are left out, as their changes can't be applied hunk by hunk.
+func (c *gitClient) GetStagedHunks() ([]Hunk, error) {
+	output, err := c.runCmdRaw("", "", "-c", "core.quotePath=false", "diff", "--cached", "-U0", "--no-color", "--no-ext-diff", "--no-textconv", "--no-renames", "--src-prefix=a/", "--dst-prefix=b/")
+	if err != nil {
+		return nil, err
+	}
+	return parseHunks(output), nil
+}
+
+// parseHunks reads the hunks of a 'git diff -U0' of modified text files
+func parseHunks(output string) []Hunk {
+	var hunks []Hunk
+	var path string
+	skip := true
+	var current *Hunk
+	for _, line := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			path, skip, current = "", false, nil
+		case current == nil && (strings.HasPrefix(line, "new file mode") || strings.HasPrefix(line, "deleted file mode") ||
+			strings.HasPrefix(line, "old mode") || strings.HasPrefix(line, "Binary files") || strings.HasPrefix(line, "GIT binary patch")):
+			skip = true
+		case current == nil && strings.HasPrefix(line, "+++ "):
+			name := strings.TrimSuffix(strings.TrimPrefix(line, "+++ "), "\t")
+			if !strings.HasPrefix(name, "b/") {
+				// /dev/null or a quoted path
+				skip = true
+				continue
+			}
+			path = strings.TrimPrefix(name, "b/")
+		case strings.HasPrefix(line, "@@ "):
+			current = nil
+			if skip || path == "" {
+				continue
+			}
+			hunk, ok := parseHunkHeader(line)
+			if !ok {
+				skip = true
+				continue
+			}
+			hunk.Path = path
+			hunks = append(hunks, hunk)
+			current = &hunks[len(hunks)-1]
+		case current != nil && (strings.HasPrefix(line, "-") || strings.HasPrefix(line, "+") || strings.HasPrefix(line, "\\")):
+			current.Lines = append(current.Lines, line)
+		}
+	}
+	return hunks
+}
+
+// parseHunkHeader reads "@@ -<start>[,<count>] +<start>[,<count>] @@"
+func parseHunkHeader(line string) (Hunk, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[3] != "@@" {
+		return Hunk{}, false
+	}
+	oldStart, oldLines, ok := parseHunkRange(fields[1], "-")
+	if !ok {
+		return Hunk{}, false
+	}
+	newStart, newLines, ok := parseHunkRange(fields[2], "+")
+	if !ok {
+		return Hunk{}, false
+	}
+	return Hunk{OldStart: oldStart, OldLines: oldLines, NewStart: newStart, NewLines: newLines}, true
+}
+
+func parseHunkRange(field, sign string) (start, count int, ok bool) {
+	if !strings.HasPrefix(field, sign) {
+		return 0, 0, false
+	}
+	startText, countText, hasCount := strings.Cut(field[1:], ",")
+	start, err := strconv.Atoi(startText)
+	if err != nil {
+		return 0, 0, false
+	}
+	count = 1
+	if hasCount {
+		if count, err = strconv.Atoi(countText); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, count, true
+}
+
+// BlameLines returns the commit that last changed each of the count lines of
+// path at ref, starting at line start
+func (c *gitClient) BlameLines(ref, path string, start, count int) ([]string, error) {
+	output, err := c.runCmd("blame", "--porcelain", "-L", fmt.Sprintf("%d,+%d", start, count), ref, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlameCommits(output), nil
+}
+
+// parseBlameCommits reads 'git blame --porcelain' output, where each line of the
+// file is introduced by "<commit> <original line> <final line> [<group size>]"
+// and its content follows on a line starting with a tab
+func parseBlameCommits(output string) []string {
+	var commits []string
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !isCommitHash(fields[0]) {
+			continue
+		}
+		commits = append(commits, fields[0])
+	}
+	return commits
+}
+
+// isCommitHash reports whether s is a full SHA-1 or SHA-256 object name
+func isCommitHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// CommitHunks writes a commit on top of parent that applies hunks, whose line
+// numbers are relative to parent, and returns its hash. Like CommitFile only
+// objects are written: the index, the worktree and every ref are left alone.
+func (c *gitClient) CommitHunks(parent string, hunks []Hunk, message string) (string, error) {
+	file, err := os.CreateTemp("", "stackinator-index-")
+	if err != nil {
+		return "", err
+	}
+	index := file.Name()
+	file.Close()
+	// read-tree wants a missing index file rather than an empty one
+	os.Remove(index)
+	defer os.Remove(index)
+
+	if _, err := c.runCmdRaw(index, "", "read-tree", parent); err != nil {
+		return "", err
+	}
+	if _, err := c.runCmdRaw(index, formatPatch(hunks), "apply", "--cached", "--unidiff-zero", "--whitespace=nowarn", "-"); err != nil {
+		return "", err
+	}
+	tree, err := c.runCmdRaw(index, "", "write-tree")
+	if err != nil {
+		return "", err
+	}
+	commit, err := c.runCmdRaw(index, "", "commit-tree", strings.TrimSpace(tree), "-p", parent, "-m", message)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(commit), nil
+}
+
+// formatPatch writes hunks as a patch 'git apply --unidiff-zero' takes. The new
+// line numbers are worked out again from the old ones, so hunks taken from a
+// larger diff can be applied on their own.
+func formatPatch(hunks []Hunk) string {
+	sorted := append([]Hunk{}, hunks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].OldStart < sorted[j].OldStart
+	})
+
+	var patch strings.Builder
+	path, delta := "", 0
+	for i, hunk := range sorted {
+		if i == 0 || hunk.Path != path {
+			path, delta = hunk.Path, 0
+			// git ends names with spaces in them with a tab, so they can be told apart from a timestamp
+			tab := ""
+			if strings.Contains(path, " ") {
+				tab = "\t"
+			}
+			fmt.Fprintf(&patch, "diff --git a/%s b/%s\n--- a/%s%s\n+++ b/%s%s\n", path, path, path, tab, path, tab)
+		}
+		start := hunk.OldStart
+		if hunk.OldLines == 0 {
+			start++
+		}
+		newStart := start + delta
+		if hunk.NewLines == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&patch, "@@ -%d,%d +%d,%d @@\n", hunk.OldStart, hunk.OldLines, newStart, hunk.NewLines)
+		for _, line := range hunk.Lines {
+			patch.WriteString(line + "\n")
+		}
+		delta += hunk.NewLines - hunk.OldLines
+	}
+	return patch.String()
+}
+
+// runCmdRaw is runCmdWithInput without trimming the output, for output where
+// whitespace matters. With index set, the command uses that index file instead
+// of the repository's.
+func (c *gitClient) runCmdRaw(index, input string, args ...string) (string, error) {
+	if Verbose {
+		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	cmd.Env = commandEnv()
+	if index != "" {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, "GIT_INDEX_FILE="+index)
+	}
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), stderr.String())
+	}
+
+	return stdout.String(), nil
+}
@@ -0,0 +1,63 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictError is returned when a rebase, cherry-pick or am stopped on conflicts
+// and is waiting for them to be resolved
+type ConflictError struct {
+	Op  string // "rebase", "cherry-pick" or "am", as in 'git <op> --continue'
+	Err error
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s stopped on conflicts: %v", e.Op, e.Err)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// DivergedError is returned when a push was rejected because origin has commits on
+// the branch that the local branch doesn't (or that weren't fetched yet)
+type DivergedError struct {
+	Branch string
+	Err    error
+}
+
+func (e *DivergedError) Error() string {
+	return fmt.Sprintf("%s has diverged from origin/%s: %v", e.Branch, e.Branch, e.Err)
+}
+
+func (e *DivergedError) Unwrap() error {
+	return e.Err
+}
+
+// conflictError returns a ConflictError when the failed command left an operation
+// waiting for conflicts to be resolved, and err as it is otherwise
+func (c *gitClient) conflictError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if op := c.GetOperationInProgress(); op != nil {
+		return &ConflictError{Op: op.Kind, Err: err}
+	}
+	return err
+}
+
+// pushError returns a DivergedError when a push of branch was rejected because the
+// remote branch moved, and err as it is otherwise
+func pushError(branch string, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	for _, reason := range []string{"non-fast-forward", "stale info", "fetch first"} {
+		if strings.Contains(msg, reason) {
+			return &DivergedError{Branch: branch, Err: err}
+		}
+	}
+	return err
+}
@@ -2,9 +2,18 @@ package git
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/script"
 )
 
 // Verbose controls whether to print executed commands
@@ -13,20 +22,120 @@ var Verbose = false
 // DryRun controls whether to actually execute mutation commands
 var DryRun = false
 
+// WorkDir is the directory git commands run in (empty means the current directory)
+var WorkDir = ""
+
+// Remote is the remote branches are fetched from, pushed to and compared with.
+// It is origin unless stack.remote names another one. PRs are opened against it.
+var Remote = "origin"
+
+// PushRemote is where stack branches are pushed when it isn't Remote: a fork,
+// with PRs opened against Remote (stack.pushRemote). Empty means Remote.
+var PushRemote = ""
+
+// upstreamBranches are the branches that live on Remote while the others are
+// pushed to PushRemote, e.g. the base branch
+var upstreamBranches = map[string]bool{}
+
+// SetUpstreamBranches sets the branches that stay on Remote when PushRemote is set
+func SetUpstreamBranches(branches []string) {
+	upstreamBranches = make(map[string]bool, len(branches))
+	for _, branch := range branches {
+		upstreamBranches[branch] = true
+	}
+}
+
+// RemoteOf returns the remote branch lives on: PushRemote for the stack's own
+// branches when it's set, Remote otherwise
+func RemoteOf(branch string) string {
+	if upstreamBranches[branch] {
+		return Remote
+	}
+	return pushRemote()
+}
+
+// pushRemote returns the remote stack branches and metadata refs are pushed to
+func pushRemote() string {
+	if PushRemote != "" {
+		return PushRemote
+	}
+	return Remote
+}
+
+// TrimRemote returns ref without the remote in front of it, e.g. main for
+// origin/main, or ref itself when it doesn't start with Remote or PushRemote
+func TrimRemote(ref string) string {
+	for _, remote := range remotes() {
+		if branch, ok := strings.CutPrefix(ref, remote+"/"); ok {
+			return branch
+		}
+	}
+	return ref
+}
+
+// remotes returns Remote, and PushRemote when it's another remote
+func remotes() []string {
+	if PushRemote != "" && PushRemote != Remote {
+		return []string{Remote, PushRemote}
+	}
+	return []string{Remote}
+}
+
+// RemoteRef returns the remote-tracking branch of branch on the remote it lives
+// on, e.g. origin/main
+func RemoteRef(branch string) string {
+	return RemoteOf(branch) + "/" + branch
+}
+
+// ErrConfigLocked is returned by config writes when another process (often an
+// IDE's git integration) kept the config file locked for the whole retry period
+var ErrConfigLocked = errors.New("git config is locked by another process")
+
+// configLockRetries are the waits between attempts at a config write that failed
+// because the config file was locked
+var configLockRetries = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	400 * time.Millisecond,
+	800 * time.Millisecond,
+	1600 * time.Millisecond,
+}
+
 // gitClient implements the GitClient interface using exec.Command
-type gitClient struct{}
+type gitClient struct {
+	dir string // Worktree the commands run in, WorkDir when empty
+}
 
 // NewGitClient creates a new GitClient implementation
 func NewGitClient() GitClient {
+	if ReadOnly {
+		return &readOnlyClient{&gitClient{}}
+	}
 	return &gitClient{}
 }
 
+// InWorktree returns a client that runs its commands in the worktree at path
+func (c *gitClient) InWorktree(path string) GitClient {
+	return &gitClient{dir: path}
+}
+
+// workDir is the directory the client's commands run in
+func (c *gitClient) workDir() string {
+	if c.dir != "" {
+		return c.dir
+	}
+	return WorkDir
+}
+
 // runCmd executes a git command and returns stdout
 func (c *gitClient) runCmd(args ...string) (string, error) {
 	if Verbose {
 		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
 	}
 	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	cmd.Env = commandEnv()
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -39,12 +148,84 @@ func (c *gitClient) runCmd(args ...string) (string, error) {
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// runCmdWithInput is runCmd with input written to the command's stdin
+func (c *gitClient) runCmdWithInput(input string, args ...string) (string, error) {
+	if Verbose {
+		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	cmd.Env = commandEnv()
+	cmd.Stdin = strings.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %s", strings.Join(args, " "), stderr.String())
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// printDryRun reports a command skipped because of DryRun, either inline or
+// as a line of the generated script
+func printDryRun(args ...string) {
+	dryrun.Record(dryRunKind(args))
+	if script.Enabled {
+		script.Record("git", args...)
+		return
+	}
+	fmt.Printf("  [DRY RUN] git %s\n", strings.Join(args, " "))
+}
+
+// dryRunKind tells what sort of change a git command skipped by DryRun makes,
+// for the summary printed at the end of the dry run
+func dryRunKind(args []string) dryrun.Kind {
+	// Leading options such as -c key=value or -C path come in pairs
+	for len(args) > 1 && (args[0] == "-c" || args[0] == "-C") {
+		args = args[2:]
+	}
+	if len(args) == 0 {
+		return dryrun.Other
+	}
+	rest := args[1:]
+	switch args[0] {
+	case "push":
+		for _, arg := range rest {
+			if arg == "--delete" || arg == "-d" || strings.HasPrefix(arg, ":") {
+				return dryrun.Delete
+			}
+		}
+		return dryrun.Push
+	case "branch":
+		for _, arg := range rest {
+			if arg == "-d" || arg == "-D" || arg == "--delete" {
+				return dryrun.Delete
+			}
+		}
+	case "worktree":
+		if len(rest) > 0 && (rest[0] == "remove" || rest[0] == "prune") {
+			return dryrun.Delete
+		}
+	case "update-ref":
+		if len(rest) > 0 && rest[0] == "-d" {
+			return dryrun.Delete
+		}
+	case "config":
+		return dryrun.Config
+	}
+	return dryrun.Other
+}
+
 // runCmdMayFail runs a command that might fail (returns empty string on error)
 func (c *gitClient) runCmdMayFail(args ...string) string {
 	if Verbose {
 		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
 	}
 	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	cmd.Env = commandEnv()
 	var stdout bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = nil
@@ -58,6 +239,23 @@ func (c *gitClient) GetRepoRoot() (string, error) {
 	return c.runCmd("rev-parse", "--show-toplevel")
 }
 
+// GetHooksDir returns the absolute path of the directory git runs hooks from,
+// honoring core.hooksPath
+func (c *gitClient) GetHooksDir() (string, error) {
+	return c.runCmd("rev-parse", "--path-format=absolute", "--git-path", "hooks")
+}
+
+// GetCommonDir returns the absolute path of the git directory shared by all
+// worktrees of the repository
+func (c *gitClient) GetCommonDir() (string, error) {
+	return c.runCmd("rev-parse", "--path-format=absolute", "--git-common-dir")
+}
+
+// IsBareRepo returns true if the repository has no working tree
+func (c *gitClient) IsBareRepo() bool {
+	return c.runCmdMayFail("rev-parse", "--is-bare-repository") == "true"
+}
+
 // GetCurrentBranch returns the name of the currently checked out branch
 func (c *gitClient) GetCurrentBranch() (string, error) {
 	return c.runCmd("branch", "--show-current")
@@ -78,66 +276,202 @@ func (c *gitClient) ListBranches() ([]string, error) {
 	return branches, nil
 }
 
-// GetConfig reads a git config value
+// CheckBranchName validates name with git check-ref-format --branch
+func (c *gitClient) CheckBranchName(name string) error {
+	_, err := c.runCmd("check-ref-format", "--branch", name)
+	return err
+}
+
+// GetConfig reads a git config value. stack.* settings git config doesn't have
+// fall back to .stackinator.toml, then to the user's settings file.
 func (c *gitClient) GetConfig(key string) string {
-	return c.runCmdMayFail("config", "--get", key)
+	if value := c.runCmdMayFail("config", "--get", key); value != "" {
+		return value
+	}
+	return c.fileConfig(key)
 }
 
-// GetAllStackParents fetches all stack parent configs in one call (more efficient)
+// GetAllStackParents fetches all stack parent configs in one call (more efficient).
+// Entries left behind by branches that were deleted outside of stack are skipped,
+// so they don't show up as phantom branches; 'stack clean-config' removes them.
 func (c *gitClient) GetAllStackParents() (map[string]string, error) {
-	output, err := c.runCmd("config", "--get-regexp", "^branch\\..*\\.stackparent$")
+	return c.stackParents(true)
+}
+
+// GetOrphanedStackParents returns the stack parents of branches that no longer
+// exist locally, which GetAllStackParents leaves out
+func (c *gitClient) GetOrphanedStackParents() (map[string]string, error) {
+	return c.stackParents(false)
+}
+
+// stackParents reads all branch.<name>.stackparent entries and keeps those whose
+// branch exists locally (or, with present=false, those whose branch doesn't)
+func (c *gitClient) stackParents(present bool) (map[string]string, error) {
+	parents := c.branchConfig("stackparent")
+
+	if len(parents) > 0 {
+		local, err := c.localBranches()
+		if err != nil {
+			if present {
+				return parents, nil
+			}
+			return nil, err
+		}
+		for branch := range parents {
+			if local[branch] != present {
+				delete(parents, branch)
+			}
+		}
+	}
+
+	return parents, nil
+}
+
+// branchConfig reads every branch.<name>.<variable> entry in one call, keyed by
+// branch name. It is empty when none is set.
+func (c *gitClient) branchConfig(variable string) map[string]string {
+	output, err := c.runCmd("config", "-z", "--get-regexp", "^branch\\..*\\."+variable+"$")
 	if err != nil {
-		// No stack parents configured
-		return make(map[string]string), nil
+		// Nothing configured
+		return make(map[string]string)
 	}
+	return parseBranchConfig(output, variable)
+}
 
-	parents := make(map[string]string)
-	for _, line := range strings.Split(output, "\n") {
-		if line == "" {
+// parseBranchConfig parses the output of git config -z --get-regexp for
+// branch.<name>.<variable> keys, where each entry is the key and the value
+// separated by a newline and ended by a NUL. Names and values are kept as they
+// are, whatever characters they hold.
+func parseBranchConfig(output, variable string) map[string]string {
+	values := make(map[string]string)
+	for _, entry := range strings.Split(output, "\x00") {
+		key, value, ok := strings.Cut(entry, "\n")
+		if !ok || value == "" {
 			continue
 		}
-		parts := strings.Fields(line)
-		if len(parts) != 2 {
+		name, ok := strings.CutPrefix(key, "branch.")
+		if !ok {
 			continue
 		}
-		// Extract branch name from "branch.<name>.stackparent"
-		configKey := parts[0]
-		parent := parts[1]
+		if name, ok = strings.CutSuffix(name, "."+variable); ok && name != "" {
+			values[name] = value
+		}
+	}
+	return values
+}
 
-		// Remove "branch." prefix and ".stackparent" suffix
-		if strings.HasPrefix(configKey, "branch.") && strings.HasSuffix(configKey, ".stackparent") {
-			branchName := configKey[7 : len(configKey)-12]
-			parents[branchName] = parent
+// localBranches returns the set of local branch names. Full refnames are used
+// because refname:short is ambiguous when a tag has the same name as a branch.
+func (c *gitClient) localBranches() (map[string]bool, error) {
+	output, err := c.runCmd("for-each-ref", "--format=%(refname)", "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+
+	branches := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if line != "" {
+			branches[strings.TrimPrefix(line, "refs/heads/")] = true
 		}
 	}
+	return branches, nil
+}
 
-	return parents, nil
+// GetStackConfigKeys lists every per-branch stack setting (branch.<name>.stack*),
+// including those of branches that no longer exist
+func (c *gitClient) GetStackConfigKeys() ([]string, error) {
+	output, err := c.runCmd("config", "-z", "--name-only", "--get-regexp", "^branch\\..*\\.stack[a-z]*$")
+	if err != nil {
+		// Nothing configured
+		return []string{}, nil
+	}
+
+	var keys []string
+	for _, key := range strings.Split(output, "\x00") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// GetAllStackPRs fetches all pinned PR numbers (branch.<name>.stackpr) in one call
+func (c *gitClient) GetAllStackPRs() (map[string]int, error) {
+	prs := make(map[string]int)
+	for branch, value := range c.branchConfig("stackpr") {
+		if number, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			prs[branch] = number
+		}
+	}
+	return prs, nil
+}
+
+// GetAllStackPRBases fetches the PR base each branch's PR was last seen with
+// (branch.<name>.stackprbase) in one call
+func (c *gitClient) GetAllStackPRBases() (map[string]string, error) {
+	return c.branchConfig("stackprbase"), nil
+}
+
+// GetStackUmbrellas fetches all umbrella branches (branch.<name>.stackumbrella) in one call
+func (c *gitClient) GetStackUmbrellas() (map[string]bool, error) {
+	umbrellas := make(map[string]bool)
+	for branch, value := range c.branchConfig("stackumbrella") {
+		if value == "true" {
+			umbrellas[branch] = true
+		}
+	}
+	return umbrellas, nil
 }
 
 // SetConfig writes a git config value
 func (c *gitClient) SetConfig(key, value string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git config %s %s\n", key, value)
+		printDryRun("config", key, value)
 		return nil
 	}
-	_, err := c.runCmd("config", key, value)
-	return err
+	return c.runConfigCmd("config", key, value)
 }
 
 // UnsetConfig removes a git config value
 func (c *gitClient) UnsetConfig(key string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git config --unset %s\n", key)
+		printDryRun("config", "--unset", key)
 		return nil
 	}
-	_, err := c.runCmd("config", "--unset", key)
+	return c.runConfigCmd("config", "--unset", key)
+}
+
+// runConfigCmd runs a git config write, retrying with backoff while another process
+// holds the config lock
+func (c *gitClient) runConfigCmd(args ...string) error {
+	_, err := c.runCmd(args...)
+	for _, wait := range configLockRetries {
+		if !isConfigLockError(err) {
+			return err
+		}
+		if Verbose {
+			fmt.Printf("  [git] config is locked, retrying in %s\n", wait)
+		}
+		time.Sleep(wait)
+		_, err = c.runCmd(args...)
+	}
+	if isConfigLockError(err) {
+		lock := c.gitPath("config") + ".lock"
+		return fmt.Errorf("git %s: %w\nClose the tool holding it and try again. If no git process is running, remove the stale lock file: %s",
+			strings.Join(args, " "), ErrConfigLocked, lock)
+	}
 	return err
 }
 
+// isConfigLockError reports whether a git config write failed on the config lock
+func isConfigLockError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "could not lock config file")
+}
+
 // CreateBranch creates a new branch from a ref without checking it out
 func (c *gitClient) CreateBranch(name, from string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git branch %s %s\n", name, from)
+		printDryRun("branch", name, from)
 		return nil
 	}
 	_, err := c.runCmd("branch", name, from)
@@ -147,7 +481,7 @@ func (c *gitClient) CreateBranch(name, from string) error {
 // CreateBranchAndCheckout creates a new branch from the specified base and checks it out
 func (c *gitClient) CreateBranchAndCheckout(name, from string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git checkout -b %s %s\n", name, from)
+		printDryRun("checkout", "-b", name, from)
 		return nil
 	}
 	_, err := c.runCmd("checkout", "-b", name, from)
@@ -157,17 +491,19 @@ func (c *gitClient) CreateBranchAndCheckout(name, from string) error {
 // CheckoutBranch switches to the specified branch
 func (c *gitClient) CheckoutBranch(name string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git checkout %s\n", name)
+		printDryRun("checkout", name)
 		return nil
 	}
-	_, err := c.runCmd("checkout", name)
-	return err
+	if _, err := c.runCmd("checkout", name); err != nil {
+		return err
+	}
+	return c.updateSubmodulesAfterCheckout(name)
 }
 
 // RenameBranch renames a branch (must be on that branch)
 func (c *gitClient) RenameBranch(oldName, newName string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git branch -m %s %s\n", oldName, newName)
+		printDryRun("branch", "-m", oldName, newName)
 		return nil
 	}
 	_, err := c.runCmd("branch", "-m", oldName, newName)
@@ -177,11 +513,22 @@ func (c *gitClient) RenameBranch(oldName, newName string) error {
 // Rebase rebases the current branch onto the specified base
 func (c *gitClient) Rebase(onto string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git rebase --autostash %s\n", onto)
+		printDryRun("rebase", "--autostash", onto)
 		return nil
 	}
 	_, err := c.runCmd("rebase", "--autostash", onto)
-	return err
+	return c.conflictError(err)
+}
+
+// RebaseSignoff rebases the current branch onto upstream, adding a Signed-off-by
+// trailer for the committer to each commit that doesn't end with one already
+func (c *gitClient) RebaseSignoff(upstream string) error {
+	if DryRun {
+		printDryRun("rebase", "--autostash", "--signoff", upstream)
+		return nil
+	}
+	_, err := c.runCmd("rebase", "--autostash", "--signoff", upstream)
+	return c.conflictError(err)
 }
 
 // RebaseOnto rebases the current branch onto newBase, excluding commits up to and including oldBase
@@ -189,107 +536,222 @@ func (c *gitClient) Rebase(onto string) error {
 // Equivalent to: git rebase --onto newBase oldBase currentBranch
 func (c *gitClient) RebaseOnto(newBase, oldBase, currentBranch string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git rebase --autostash --onto %s %s %s\n", newBase, oldBase, currentBranch)
+		printDryRun("rebase", "--autostash", "--onto", newBase, oldBase, currentBranch)
 		return nil
 	}
 	_, err := c.runCmd("rebase", "--autostash", "--onto", newBase, oldBase, currentBranch)
-	return err
+	return c.conflictError(err)
 }
 
-// FetchBranch fetches a specific branch from origin to update tracking info
+// FetchBranch fetches a specific branch from the remote to update tracking info
 func (c *gitClient) FetchBranch(branch string) error {
 	// Use refspec to ensure the tracking ref is created/updated
 	// git fetch origin <branch> alone only updates FETCH_HEAD, not refs/remotes/origin/<branch>
-	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", branch, branch)
+	remote := RemoteOf(branch)
+	refspec := fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", branch, remote, branch)
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git fetch origin %s\n", refspec)
+		printDryRun("fetch", remote, refspec)
 		return nil
 	}
-	_, err := c.runCmd("fetch", "origin", refspec)
-	return err
+	if Offline {
+		return nil
+	}
+	_, err := c.runCmd("fetch", remote, refspec)
+	return fetchError(err)
 }
 
-// Push pushes a branch to origin
+// Push pushes a branch to the remote
 func (c *gitClient) Push(branch string, forceWithLease bool) error {
 	args := []string{"push"}
 	if forceWithLease {
 		args = append(args, "--force-with-lease")
 	}
-	args = append(args, "origin", branch)
+	args = append(args, RemoteOf(branch), branch)
 
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git %s\n", strings.Join(args, " "))
+		printDryRun(args...)
 		return nil
 	}
+	if Offline {
+		return offlineError(args...)
+	}
 
-	_, err := c.runCmd(args...)
-	return err
+	if _, err := c.runCmd(args...); err != nil {
+		return pushError(branch, err)
+	}
+	return c.pushLFS(branch)
 }
 
 // PushWithExpectedRemote pushes a branch using --force-with-lease with an explicit expected SHA.
 // This avoids "stale info" errors that can occur with plain --force-with-lease.
 func (c *gitClient) PushWithExpectedRemote(branch string, expectedRemoteSha string) error {
 	leaseArg := fmt.Sprintf("--force-with-lease=refs/heads/%s:%s", branch, expectedRemoteSha)
-	args := []string{"push", leaseArg, "origin", branch}
+	args := []string{"push", leaseArg, RemoteOf(branch), branch}
 
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git %s\n", strings.Join(args, " "))
+		printDryRun(args...)
 		return nil
 	}
+	if Offline {
+		return offlineError(args...)
+	}
 
-	_, err := c.runCmd(args...)
-	return err
+	if _, err := c.runCmd(args...); err != nil {
+		return pushError(branch, err)
+	}
+	return c.pushLFS(branch)
 }
 
-// ForcePush force pushes a branch to origin (bypasses --force-with-lease safety)
+// ForcePush force pushes a branch to the remote (bypasses --force-with-lease safety)
 func (c *gitClient) ForcePush(branch string) error {
-	args := []string{"push", "--force", "origin", branch}
+	args := []string{"push", "--force", RemoteOf(branch), branch}
 
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git %s\n", strings.Join(args, " "))
+		printDryRun(args...)
 		return nil
 	}
+	if Offline {
+		return offlineError(args...)
+	}
 
-	_, err := c.runCmd(args...)
-	return err
+	if _, err := c.runCmd(args...); err != nil {
+		return err
+	}
+	return c.pushLFS(branch)
 }
 
-// IsWorkingTreeClean returns true if there are no uncommitted changes
+// CleanIgnorePaths are pathspecs whose changes don't make the working tree dirty,
+// e.g. generated files or .env that are always modified (stack.cleanIgnore)
+var CleanIgnorePaths []string
+
+// IsWorkingTreeClean returns true if there are no uncommitted changes outside
+// CleanIgnorePaths. Like git rebase's own check, submodules checked out at another
+// commit don't count, as a rebase leaves them behind until the next
+// 'git submodule update'.
 func (c *gitClient) IsWorkingTreeClean() (bool, error) {
-	output, err := c.runCmd("status", "--porcelain")
+	args := []string{"status", "--porcelain", "--ignore-submodules=all"}
+	if len(CleanIgnorePaths) > 0 {
+		// Paths are relative to the top of the repo, wherever stack runs from
+		args = append(args, "--", ":(top)")
+		for _, path := range CleanIgnorePaths {
+			args = append(args, ":(top,exclude)"+path)
+		}
+	}
+	output, err := c.runCmd(args...)
 	if err != nil {
 		return false, err
 	}
 	return output == "", nil
 }
 
-// Fetch fetches from origin
-func (c *gitClient) Fetch() error {
+// HasStagedChanges returns true if there are changes staged for commit
+func (c *gitClient) HasStagedChanges() (bool, error) {
+	output, err := c.runCmd("diff", "--cached", "--name-only")
+	if err != nil {
+		return false, err
+	}
+	return output != "", nil
+}
+
+// CommitFixup commits the staged changes as a fixup! of commit
+func (c *gitClient) CommitFixup(commit string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git fetch origin\n")
+		printDryRun("commit", "--fixup="+commit)
 		return nil
 	}
-	_, err := c.runCmd("fetch", "origin")
+	_, err := c.runCmd("commit", "--fixup="+commit)
 	return err
 }
 
+// Commit commits the staged changes, or every change to tracked files with all,
+// amending HEAD with amend. Without a message, an amend keeps HEAD's message and
+// a new commit opens the editor. The command is attached to the terminal for the
+// editor and the output of commit hooks.
+func (c *gitClient) Commit(message string, amend, all bool) error {
+	args := commitArgs(message, amend, all)
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	cmd.Env = commandEnv()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+func commitArgs(message string, amend, all bool) []string {
+	args := []string{"commit"}
+	if all {
+		args = append(args, "--all")
+	}
+	if amend {
+		args = append(args, "--amend")
+	}
+	if message != "" {
+		args = append(args, "--message", message)
+	} else if amend {
+		args = append(args, "--no-edit")
+	}
+	return args
+}
+
+// RebaseAutosquash rebases the current branch onto upstream, squashing fixup!
+// commits into their targets without opening an editor. Branches pointing at
+// rebased commits are moved along (--update-refs, git 2.38+).
+func (c *gitClient) RebaseAutosquash(upstream string) error {
+	args := []string{"-c", "sequence.editor=:", "rebase", "--interactive", "--autosquash", "--autostash", "--update-refs", upstream}
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	_, err := c.runCmd(args...)
+	return c.conflictError(err)
+}
+
+// Fetch fetches from the remote, and from the push remote when it's another one
+func (c *gitClient) Fetch() error {
+	args := []string{"fetch", Remote}
+	if remotes := remotes(); len(remotes) > 1 {
+		args = append([]string{"fetch", "--multiple"}, remotes...)
+	}
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	if Offline {
+		return nil
+	}
+	_, err := c.runCmd(args...)
+	return fetchError(err)
+}
+
 // BranchExists checks if a branch exists locally
 func (c *gitClient) BranchExists(name string) bool {
 	output := c.runCmdMayFail("rev-parse", "--verify", "refs/heads/"+name)
 	return output != ""
 }
 
-// RemoteBranchExists checks if a branch exists on origin
+// RemoteBranchExists checks if a branch exists on the remote
 func (c *gitClient) RemoteBranchExists(name string) bool {
-	output := c.runCmdMayFail("rev-parse", "--verify", "refs/remotes/origin/"+name)
+	output := c.runCmdMayFail("rev-parse", "--verify", "refs/remotes/"+RemoteRef(name))
 	return output != ""
 }
 
-// GetRemoteBranchesSet fetches all remote branches from origin in one call
+// GetRemoteBranchesSet fetches all branches on the remote in one call
 // and returns a set (map[string]bool) for efficient lookups.
 // This is more efficient than calling RemoteBranchExists multiple times.
 func (c *gitClient) GetRemoteBranchesSet() map[string]bool {
-	output := c.runCmdMayFail("for-each-ref", "--format=%(refname:short)", "refs/remotes/origin/")
+	args := []string{"for-each-ref", "--format=%(refname:short)"}
+	for _, remote := range remotes() {
+		args = append(args, "refs/remotes/"+remote+"/")
+	}
+	output := c.runCmdMayFail(args...)
 	if output == "" {
 		return make(map[string]bool)
 	}
@@ -300,16 +762,46 @@ func (c *gitClient) GetRemoteBranchesSet() map[string]bool {
 		if line == "" {
 			continue
 		}
-		// Remove "origin/" prefix to get just the branch name
-		if strings.HasPrefix(line, "origin/") {
-			branchName := strings.TrimPrefix(line, "origin/")
-			branches[branchName] = true
+		// Remove the "origin/" prefix to get just the branch name, keeping
+		// branches from the remote they live on
+		for _, remote := range remotes() {
+			if branchName, ok := strings.CutPrefix(line, remote+"/"); ok && RemoteOf(branchName) == remote {
+				branches[branchName] = true
+			}
 		}
 	}
 
 	return branches
 }
 
+// GetBranchTips returns the commit every local branch and every branch on the
+// remote points to, in one call. Local branches are keyed by name, the remote's
+// as RemoteRef(name), e.g. origin/<name>.
+func (c *gitClient) GetBranchTips() (map[string]string, error) {
+	args := []string{"for-each-ref", "--format=%(objectname) %(refname)", "refs/heads/"}
+	for _, remote := range remotes() {
+		args = append(args, "refs/remotes/"+remote+"/")
+	}
+	output, err := c.runCmd(args...)
+	if err != nil {
+		return nil, err
+	}
+
+	tips := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		hash, ref, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		if name, local := strings.CutPrefix(ref, "refs/heads/"); local {
+			tips[name] = hash
+		} else if name, remote := strings.CutPrefix(ref, "refs/remotes/"); remote && !strings.HasSuffix(name, "/HEAD") {
+			tips[name] = hash
+		}
+	}
+	return tips, nil
+}
+
 // IsRebaseInProgress checks if a rebase is currently in progress
 func (c *gitClient) IsRebaseInProgress() bool {
 	// Git creates REBASE_HEAD during rebase (both regular and interactive)
@@ -324,10 +816,66 @@ func (c *gitClient) IsCherryPickInProgress() bool {
 	return err == nil
 }
 
+// Operation is a rebase, merge, cherry-pick or revert stopped part way, e.g. on a conflict
+type Operation struct {
+	Kind   string // "rebase", "am", "merge", "cherry-pick" or "revert"
+	Branch string // Branch the operation is on (the branch being rebased); empty if unknown
+}
+
+// GetOperationInProgress returns the operation in progress in the current worktree,
+// whether or not stack started it, or nil if there is none
+func (c *gitClient) GetOperationInProgress() *Operation {
+	// An interactive or merge-based rebase keeps its state in rebase-merge,
+	// an apply-based one in rebase-apply. Both record the branch being rebased.
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		path := c.gitPath(dir)
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		// git am also uses rebase-apply, and marks it with an "applying" file
+		if _, err := os.Stat(filepath.Join(path, "applying")); err == nil {
+			branch, _ := c.GetCurrentBranch()
+			return &Operation{Kind: "am", Branch: branch}
+		}
+		headName, _ := os.ReadFile(filepath.Join(path, "head-name"))
+		branch := strings.TrimPrefix(strings.TrimSpace(string(headName)), "refs/heads/")
+		if branch == "detached HEAD" {
+			branch = ""
+		}
+		return &Operation{Kind: "rebase", Branch: branch}
+	}
+
+	for _, op := range []struct{ ref, kind string }{
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	} {
+		if _, err := c.runCmd("rev-parse", "--verify", "--quiet", op.ref); err == nil {
+			branch, _ := c.GetCurrentBranch()
+			return &Operation{Kind: op.kind, Branch: branch}
+		}
+	}
+
+	return nil
+}
+
+// gitPath resolves a path inside the git dir of the current worktree
+func (c *gitClient) gitPath(name string) string {
+	path := c.runCmdMayFail("rev-parse", "--git-path", name)
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	// Relative paths are relative to the directory git ran in
+	return filepath.Join(c.workDir(), path)
+}
+
 // AbortRebase aborts an in-progress rebase
 func (c *gitClient) AbortRebase() error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git rebase --abort\n")
+		printDryRun("rebase", "--abort")
 		return nil
 	}
 	_, err := c.runCmd("rebase", "--abort")
@@ -337,7 +885,7 @@ func (c *gitClient) AbortRebase() error {
 // AbortCherryPick aborts an in-progress cherry-pick
 func (c *gitClient) AbortCherryPick() error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git cherry-pick --abort\n")
+		printDryRun("cherry-pick", "--abort")
 		return nil
 	}
 	_, err := c.runCmd("cherry-pick", "--abort")
@@ -346,9 +894,9 @@ func (c *gitClient) AbortCherryPick() error {
 
 // ResetToRemote resets the current branch to match the remote branch exactly
 func (c *gitClient) ResetToRemote(branch string) error {
-	remoteBranch := "origin/" + branch
+	remoteBranch := RemoteRef(branch)
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git reset --hard %s\n", remoteBranch)
+		printDryRun("reset", "--hard", remoteBranch)
 		return nil
 	}
 	_, err := c.runCmd("reset", "--hard", remoteBranch)
@@ -407,30 +955,245 @@ func (c *gitClient) GetUniqueCommitsByPatch(base, branch string) ([]string, erro
 	return uniqueCommits, nil
 }
 
+// GetBranchesMergedInto returns the local branches whose tip is reachable from ref,
+// in a single call
+func (c *gitClient) GetBranchesMergedInto(ref string) (map[string]bool, error) {
+	output, err := c.runCmd("for-each-ref", "--format=%(refname)", "--merged="+ref, "refs/heads/")
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]bool)
+	for _, line := range strings.Split(output, "\n") {
+		if name, ok := strings.CutPrefix(line, "refs/heads/"); ok {
+			merged[name] = true
+		}
+	}
+	return merged, nil
+}
+
+// IsMergedByPatch reports whether every change in branch has already landed in base,
+// comparing patch IDs rather than commit SHAs. This catches rebase merges (each commit
+// has a matching patch in base) and squash merges (the branch's combined diff matches
+// a single commit in base) even when no merged PR record is available.
+// Branches with no commits beyond base are never reported as merged.
+func (c *gitClient) IsMergedByPatch(base, branch string) (bool, error) {
+	commits, err := c.GetUniqueCommits(base, branch)
+	if err != nil {
+		return false, err
+	}
+	if len(commits) == 0 {
+		// Nothing to compare (empty branch or already an ancestor of base)
+		return false, nil
+	}
+
+	// Rebase merge: every commit has an equivalent patch in base
+	unique, err := c.GetUniqueCommitsByPatch(base, branch)
+	if err != nil {
+		return false, err
+	}
+	if len(unique) == 0 {
+		return true, nil
+	}
+
+	// Squash merge: the branch's combined diff matches one commit in base
+	mergeBase, err := c.GetMergeBase(base, branch)
+	if err != nil {
+		return false, err
+	}
+	branchIDs, err := c.patchIDs("diff", mergeBase, branch)
+	if err != nil || len(branchIDs) == 0 {
+		return false, err
+	}
+	baseIDs, err := c.patchIDs("log", "-p", "--no-merges", "--format=commit %H", mergeBase+".."+base)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range baseIDs {
+		if id == branchIDs[0] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// patchIDs runs a git command producing patch output and returns the stable patch IDs
+// computed by git patch-id, one per patch in the output
+func (c *gitClient) patchIDs(args ...string) ([]string, error) {
+	patch, err := c.runCmd(args...)
+	if err != nil {
+		return nil, err
+	}
+	if patch == "" {
+		return []string{}, nil
+	}
+
+	if Verbose {
+		fmt.Printf("  [git] patch-id --stable\n")
+	}
+	cmd := exec.Command("git", "patch-id", "--stable")
+	cmd.Dir = c.workDir()
+	cmd.Stdin = strings.NewReader(patch + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git patch-id failed: %s", stderr.String())
+	}
+
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		// Output format is "<patch-id> <commit-id>"
+		parts := strings.Fields(line)
+		if len(parts) >= 1 {
+			ids = append(ids, parts[0])
+		}
+	}
+	return ids, nil
+}
+
 // CherryPick cherry-picks a commit onto the current branch
 func (c *gitClient) CherryPick(commit string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git cherry-pick %s\n", commit)
+		printDryRun("cherry-pick", commit)
 		return nil
 	}
 	_, err := c.runCmd("cherry-pick", commit)
-	return err
+	return c.conflictError(err)
+}
+
+// CherryPickTracked cherry-picks a commit, recording the source commit in a
+// "(cherry picked from commit <sha>)" trailer (git cherry-pick -x)
+func (c *gitClient) CherryPickTracked(commit string) error {
+	if DryRun {
+		printDryRun("cherry-pick", "-x", commit)
+		return nil
+	}
+	_, err := c.runCmd("cherry-pick", "-x", commit)
+	return c.conflictError(err)
+}
+
+// FormatPatch writes the commits in base..branch as numbered patch files into dir
+// (git format-patch) and returns their paths in order
+func (c *gitClient) FormatPatch(base, branch, dir string) ([]string, error) {
+	args := []string{"format-patch", "--output-directory", dir, base + ".." + branch}
+	if DryRun {
+		printDryRun(args...)
+		return nil, nil
+	}
+	output, err := c.runCmd(args...)
+	if err != nil {
+		return nil, err
+	}
+	if output == "" {
+		return []string{}, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+// ApplyPatches applies patch files to the current branch as commits, falling back
+// to a 3-way merge when they don't apply cleanly (git am --3way)
+func (c *gitClient) ApplyPatches(files []string) error {
+	args := append([]string{"am", "--3way"}, files...)
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	_, err := c.runCmd(args...)
+	return c.conflictError(err)
+}
+
+// PickedCommit is a commit cherry-picked with a source trailer
+type PickedCommit struct {
+	SHA    string
+	Source string
+}
+
+var pickedFromPattern = regexp.MustCompile(`\(cherry picked from commit ([0-9a-f]{7,40})\)`)
+
+// GetPickedCommits returns the commits in base..branch that record the commit they
+// were cherry-picked from, newest first
+func (c *gitClient) GetPickedCommits(base, branch string) ([]PickedCommit, error) {
+	output, err := c.runCmd("log", "--format=%H%n%B%x00", base+".."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parsePickedCommits(output), nil
+}
+
+// parsePickedCommits parses 'git log --format=%H%n%B%x00' output
+func parsePickedCommits(output string) []PickedCommit {
+	var picked []PickedCommit
+	for _, entry := range strings.Split(output, "\x00") {
+		entry = strings.TrimSpace(entry)
+		sha, body, ok := strings.Cut(entry, "\n")
+		if !ok {
+			continue
+		}
+		// A pick of a pick gets another trailer; the first names the original commit
+		match := pickedFromPattern.FindStringSubmatch(body)
+		if match == nil {
+			continue
+		}
+		picked = append(picked, PickedCommit{SHA: sha, Source: match[1]})
+	}
+	return picked
+}
+
+// IsCommitLanded reports whether commit, or a commit with the same patch, is in base
+func (c *gitClient) IsCommitLanded(base, commit string) (bool, error) {
+	mergeBase, err := c.GetMergeBase(base, commit)
+	if err != nil {
+		return false, err
+	}
+	commitHash, err := c.GetCommitHash(commit)
+	if err != nil {
+		return false, err
+	}
+	if mergeBase == commitHash {
+		// Landed as is
+		return true, nil
+	}
+
+	commitIDs, err := c.patchIDs("show", "--format=commit %H", commitHash)
+	if err != nil || len(commitIDs) == 0 {
+		return false, err
+	}
+	baseIDs, err := c.patchIDs("log", "-p", "--no-merges", "--format=commit %H", mergeBase+".."+base)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range baseIDs {
+		if id == commitIDs[0] {
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 // ResetHard resets the current branch to a ref
 func (c *gitClient) ResetHard(ref string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git reset --hard %s\n", ref)
+		printDryRun("reset", "--hard", ref)
 		return nil
 	}
 	_, err := c.runCmd("reset", "--hard", ref)
 	return err
 }
 
+// ForceBranch points branch at ref without checking it out
+func (c *gitClient) ForceBranch(branch, ref string) error {
+	if DryRun {
+		printDryRun("branch", "--force", branch, ref)
+		return nil
+	}
+	_, err := c.runCmd("branch", "--force", branch, ref)
+	return err
+}
+
 // Stash stashes the current changes
 func (c *gitClient) Stash(message string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git stash push -m \"%s\"\n", message)
+		printDryRun("stash", "push", "-m", message)
 		return nil
 	}
 	_, err := c.runCmd("stash", "push", "-m", message)
@@ -440,7 +1203,7 @@ func (c *gitClient) Stash(message string) error {
 // StashPop pops the most recent stash
 func (c *gitClient) StashPop() error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git stash pop\n")
+		printDryRun("stash", "pop")
 		return nil
 	}
 	_, err := c.runCmd("stash", "pop")
@@ -451,7 +1214,7 @@ func (c *gitClient) StashPop() error {
 // by checking the remote HEAD or falling back to common defaults
 func (c *gitClient) GetDefaultBranch() string {
 	// Try to get the remote's default branch
-	output := c.runCmdMayFail("symbolic-ref", "refs/remotes/origin/HEAD")
+	output := c.runCmdMayFail("symbolic-ref", "refs/remotes/"+Remote+"/HEAD")
 	if output != "" {
 		// Output format: refs/remotes/origin/master
 		parts := strings.Split(output, "/")
@@ -473,34 +1236,53 @@ func (c *gitClient) GetDefaultBranch() string {
 
 // GetWorktreeBranches returns a map of branch names to their worktree paths (resolved to canonical paths)
 func (c *gitClient) GetWorktreeBranches() (map[string]string, error) {
-	output := c.runCmdMayFail("worktree", "list", "--porcelain")
-	if output == "" {
-		return make(map[string]string), nil
-	}
-
 	worktrees := make(map[string]string)
-	var currentPath string
-
-	for _, line := range strings.Split(output, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "worktree ") {
-			currentPath = strings.TrimPrefix(line, "worktree ")
-		} else if strings.HasPrefix(line, "branch ") && currentPath != "" {
-			branch := strings.TrimPrefix(line, "branch refs/heads/")
-			// Resolve symlinks to get canonical path for accurate comparison
-			canonicalPath, err := resolveSymlinks(currentPath)
-			if err != nil {
-				// If we can't resolve, use the original path
-				canonicalPath = currentPath
-			}
-			worktrees[branch] = canonicalPath
-			currentPath = "" // Reset for next worktree
+	for _, worktree := range c.worktrees() {
+		if worktree.branch == "" {
+			continue
 		}
+		// Resolve symlinks to get canonical path for accurate comparison
+		canonicalPath, err := resolveSymlinks(worktree.path)
+		if err != nil {
+			// If we can't resolve, use the original path
+			canonicalPath = worktree.path
+		}
+		worktrees[worktree.branch] = canonicalPath
 	}
 
 	return worktrees, nil
 }
 
+// worktreeEntry is a worktree as git worktree list --porcelain describes it
+type worktreeEntry struct {
+	path   string
+	branch string // empty when detached
+}
+
+// worktrees lists the worktrees, NUL-separated when git supports it so that
+// paths holding a newline are read whole
+func (c *gitClient) worktrees() []worktreeEntry {
+	if c.gitAtLeast(worktreeListZVersion) {
+		return parseWorktrees(c.runCmdMayFail("worktree", "list", "--porcelain", "-z"), "\x00")
+	}
+	return parseWorktrees(c.runCmdMayFail("worktree", "list", "--porcelain"), "\n")
+}
+
+// parseWorktrees reads git worktree list --porcelain output, whose attributes
+// ("worktree <path>", "branch <ref>", ...) are ended by sep. Paths are kept as
+// they are, including spaces at either end.
+func parseWorktrees(output, sep string) []worktreeEntry {
+	var worktrees []worktreeEntry
+	for _, attribute := range strings.Split(output, sep) {
+		if path, ok := strings.CutPrefix(attribute, "worktree "); ok {
+			worktrees = append(worktrees, worktreeEntry{path: path})
+		} else if ref, ok := strings.CutPrefix(attribute, "branch "); ok && len(worktrees) > 0 {
+			worktrees[len(worktrees)-1].branch = strings.TrimPrefix(ref, "refs/heads/")
+		}
+	}
+	return worktrees
+}
+
 // GetCurrentWorktreePath returns the absolute path of the current worktree
 func (c *gitClient) GetCurrentWorktreePath() (string, error) {
 	// Use git rev-parse to get the absolute path to the top-level of the current worktree
@@ -542,35 +1324,101 @@ func resolveSymlinks(path string) (string, error) {
 
 // IsCommitsBehind checks if the 'branch' is behind 'base' (i.e., base has commits that branch doesn't)
 func (c *gitClient) IsCommitsBehind(branch, base string) (bool, error) {
+	behind, err := c.CountCommitsBehind(branch, base)
+	return behind > 0, err
+}
+
+// CountCommitsBehind returns how many commits origin/<base> has that branch doesn't
+func (c *gitClient) CountCommitsBehind(branch, base string) (int, error) {
 	// NOTE: Caller should fetch first to ensure latest remote refs
 	// We don't fetch here to avoid multiple fetches in loops
 
 	// Always use origin/ prefix for the base since we're comparing against what's on the remote
 	// (which is what the PR is based on)
-	baseBranch := "origin/" + base
+	baseBranch := RemoteRef(base)
 
 	// Get commit count: ahead...behind
 	// Format: "ahead<tab>behind"
 	output, err := c.runCmd("rev-list", "--left-right", "--count", branch+"..."+baseBranch)
 	if err != nil {
-		return false, err
+		return 0, err
 	}
 
 	parts := strings.Fields(output)
 	if len(parts) != 2 {
-		return false, fmt.Errorf("unexpected output from git rev-list: %s", output)
+		return 0, fmt.Errorf("unexpected output from git rev-list: %s", output)
 	}
 
 	// parts[0] = ahead count, parts[1] = behind count
-	// We only care if behind count > 0
-	return parts[1] != "0", nil
+	return strconv.Atoi(parts[1])
+}
+
+// GetCommitTime returns the committer date of ref. Rebasing rewrites it, so for
+// the first commit of a branch it is when the branch was last rebased.
+func (c *gitClient) GetCommitTime(ref string) (time.Time, error) {
+	output, err := c.runCmd("log", "-1", "--format=%ct", ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := strconv.ParseInt(output, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unexpected output from git log: %s", output)
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// GetCommitMessage returns the full commit message of ref
+func (c *gitClient) GetCommitMessage(ref string) (string, error) {
+	return c.runCmd("log", "-1", "--format=%B", ref)
+}
+
+// GetCommitAuthorEmail returns the author email of ref
+func (c *gitClient) GetCommitAuthorEmail(ref string) (string, error) {
+	return c.runCmd("log", "-1", "--format=%ae", ref)
+}
+
+// GetBranchTipAt returns the commit branch pointed to at the given time, from its
+// reflog. It returns "" when the reflog has no entry that old, e.g. because the
+// branch didn't exist yet or the entries have expired.
+func (c *gitClient) GetBranchTipAt(branch string, at time.Time) (string, error) {
+	output, err := c.runCmd("reflog", "show", "--date=unix", "--format=%H %gd", "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+
+	return parseReflogTip(output, at), nil
+}
+
+// parseReflogTip picks the commit of the latest reflog entry at or before at. Each
+// entry is like "<sha> <branch>@{<unix time>}"; they are newest first, but clock
+// changes can reorder them.
+func parseReflogTip(output string, at time.Time) string {
+	tip, tipTime := "", int64(0)
+	for _, line := range strings.Split(output, "\n") {
+		hash, selector, found := strings.Cut(line, " ")
+		if !found {
+			continue
+		}
+		start := strings.LastIndex(selector, "@{")
+		if start == -1 || !strings.HasSuffix(selector, "}") {
+			continue
+		}
+		seconds, err := strconv.ParseInt(selector[start+2:len(selector)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if seconds <= at.Unix() && (tip == "" || seconds > tipTime) {
+			tip, tipTime = hash, seconds
+		}
+	}
+	return tip
 }
 
 // DeleteBranch deletes a branch safely (equivalent to git branch -d)
 // This will fail if the branch has unmerged commits
 func (c *gitClient) DeleteBranch(name string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git branch -d %s\n", name)
+		printDryRun("branch", "-d", name)
 		return nil
 	}
 	_, err := c.runCmd("branch", "-d", name)
@@ -581,7 +1429,7 @@ func (c *gitClient) DeleteBranch(name string) error {
 // This will delete the branch even if it has unmerged commits
 func (c *gitClient) DeleteBranchForce(name string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git branch -D %s\n", name)
+		printDryRun("branch", "-D", name)
 		return nil
 	}
 	_, err := c.runCmd("branch", "-D", name)
@@ -591,7 +1439,7 @@ func (c *gitClient) DeleteBranchForce(name string) error {
 // AddWorktree creates a worktree at the specified path for an existing local branch
 func (c *gitClient) AddWorktree(path, branch string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git worktree add %s %s\n", path, branch)
+		printDryRun("worktree", "add", path, branch)
 		return nil
 	}
 	_, err := c.runCmd("worktree", "add", path, branch)
@@ -602,7 +1450,7 @@ func (c *gitClient) AddWorktree(path, branch string) error {
 // The new branch is created from the given base branch
 func (c *gitClient) AddWorktreeNewBranch(path, newBranch, baseBranch string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git worktree add -b %s %s %s\n", newBranch, path, baseBranch)
+		printDryRun("worktree", "add", "-b", newBranch, path, baseBranch)
 		return nil
 	}
 	_, err := c.runCmd("worktree", "add", "-b", newBranch, path, baseBranch)
@@ -613,37 +1461,308 @@ func (c *gitClient) AddWorktreeNewBranch(path, newBranch, baseBranch string) err
 // This creates a local branch that tracks the remote branch
 func (c *gitClient) AddWorktreeFromRemote(path, branch string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git worktree add --track -b %s %s origin/%s\n", branch, path, branch)
+		printDryRun("worktree", "add", "--track", "-b", branch, path, RemoteRef(branch))
 		return nil
 	}
-	_, err := c.runCmd("worktree", "add", "--track", "-b", branch, path, "origin/"+branch)
+	_, err := c.runCmd("worktree", "add", "--track", "-b", branch, path, RemoteRef(branch))
 	return err
 }
 
 // RemoveWorktree removes a worktree at the specified path
 func (c *gitClient) RemoveWorktree(path string) error {
 	if DryRun {
-		fmt.Printf("  [DRY RUN] git worktree remove %s\n", path)
+		printDryRun("worktree", "remove", path)
 		return nil
 	}
 	_, err := c.runCmd("worktree", "remove", path)
 	return err
 }
 
-// ListWorktrees returns a list of all worktree paths
-func (c *gitClient) ListWorktrees() ([]string, error) {
-	output := c.runCmdMayFail("worktree", "list", "--porcelain")
+// ForceRemoveWorktree removes the worktree at path, discarding any changes and
+// operation in progress in it
+func (c *gitClient) ForceRemoveWorktree(path string) error {
+	if DryRun {
+		printDryRun("worktree", "remove", "--force", path)
+		return nil
+	}
+	_, err := c.runCmd("worktree", "remove", "--force", path)
+	return err
+}
+
+// PruneWorktrees drops the records of worktrees whose directory was deleted
+func (c *gitClient) PruneWorktrees() error {
+	if DryRun {
+		printDryRun("worktree", "prune")
+		return nil
+	}
+	_, err := c.runCmd("worktree", "prune")
+	return err
+}
+
+// AddWorktreeDetached creates a worktree at path with a detached HEAD at ref
+func (c *gitClient) AddWorktreeDetached(path, ref string) error {
+	if DryRun {
+		printDryRun("worktree", "add", "--detach", path, ref)
+		return nil
+	}
+	_, err := c.runCmd("worktree", "add", "--detach", path, ref)
+	return err
+}
+
+// GetWorktreeHead returns the commit checked out in the worktree at path
+func (c *gitClient) GetWorktreeHead(path string) (string, error) {
+	return c.runCmd("-C", path, "rev-parse", "HEAD")
+}
+
+// CheckoutDetachedInWorktree moves the worktree at path to a detached HEAD at ref
+func (c *gitClient) CheckoutDetachedInWorktree(path, ref string) error {
+	if DryRun {
+		printDryRun("-C", path, "checkout", "--detach", ref)
+		return nil
+	}
+	_, err := c.runCmd("-C", path, "checkout", "--detach", ref)
+	return err
+}
+
+// RangeDiff compares the commits in oldBase..oldHead with those in newBase..newHead
+func (c *gitClient) RangeDiff(oldBase, oldHead, newBase, newHead string) (string, error) {
+	// The <base> <old> <new> form also accepts an empty old range (old == base),
+	// which the two-range form rejects
+	if oldBase == newBase {
+		return c.runCmd("range-diff", "--no-color", oldBase, oldHead, newHead)
+	}
+	return c.runCmd("range-diff", "--no-color", oldBase+".."+oldHead, newBase+".."+newHead)
+}
+
+// RangeDiffSummary groups the commits of a range-diff by how they changed.
+// Each entry is the commit subject.
+type RangeDiffSummary struct {
+	Unchanged []string // same patch on both sides (e.g. only rebased)
+	Changed   []string // matched, but the patch differs
+	Added     []string // only in the new range
+	Dropped   []string // only in the old range
+}
+
+// HasContentChanges reports whether any commit's patch was changed, added or dropped
+func (s RangeDiffSummary) HasContentChanges() bool {
+	return len(s.Changed)+len(s.Added)+len(s.Dropped) > 0
+}
+
+// rangeDiffLine matches a commit pair line, e.g. "1:  abc1234 ! 1:  def5678 Subject"
+var rangeDiffLine = regexp.MustCompile(`^\s*(?:\d+|-+):\s+(?:[0-9a-f]+|-+)\s+([=!<>])\s+(?:\d+|-+):\s+(?:[0-9a-f]+|-+)\s+(.*)$`)
+
+// ParseRangeDiff summarizes git range-diff output. Patch lines shown for
+// changed commits are ignored.
+func ParseRangeDiff(output string) RangeDiffSummary {
+	var summary RangeDiffSummary
+	for _, line := range strings.Split(output, "\n") {
+		m := rangeDiffLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		subject := strings.TrimSpace(m[2])
+		switch m[1] {
+		case "=":
+			summary.Unchanged = append(summary.Unchanged, subject)
+		case "!":
+			summary.Changed = append(summary.Changed, subject)
+		case ">":
+			summary.Added = append(summary.Added, subject)
+		case "<":
+			summary.Dropped = append(summary.Dropped, subject)
+		}
+	}
+	return summary
+}
+
+// GetChangedFiles returns the paths changed in branch since it forked from base
+func (c *gitClient) GetChangedFiles(base, branch string) ([]string, error) {
+	output, err := c.runCmd("diff", "--name-only", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
 	if output == "" {
 		return []string{}, nil
 	}
+	return strings.Split(output, "\n"), nil
+}
 
-	var paths []string
+// GetMergeConflicts simulates merging branch into base, without touching the
+// worktree or any ref, and returns the paths that would conflict (none when it
+// merges cleanly). Needs git 2.38 or later.
+func (c *gitClient) GetMergeConflicts(base, branch string) ([]string, error) {
+	args := []string{"merge-tree", "--write-tree", "--name-only", "--no-messages", base, branch}
+	if Verbose {
+		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Exit status 1 means the merge has conflicts, anything else that it failed
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if err != nil && (!errors.As(err, &exitErr) || exitErr.ExitCode() != 1) {
+		return nil, fmt.Errorf("git %s failed: %s", strings.Join(args, " "), stderr.String())
+	}
+
+	// The first line is the merged tree, followed by the conflicted paths
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	seen := make(map[string]bool)
+	conflicts := []string{}
+	for _, path := range lines[1:] {
+		if path != "" && !seen[path] {
+			seen[path] = true
+			conflicts = append(conflicts, path)
+		}
+	}
+	return conflicts, nil
+}
+
+// FileStat is the number of lines a diff adds and deletes in one file.
+// Binary files count as zero lines.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+}
+
+// GetDiffStat returns the per-file line counts of the changes in branch since it forked from base
+func (c *gitClient) GetDiffStat(base, branch string) ([]FileStat, error) {
+	output, err := c.runCmd("diff", "--numstat", "--no-renames", base+"..."+branch)
+	if err != nil {
+		return nil, err
+	}
+	return parseNumstat(output), nil
+}
+
+// parseNumstat parses git diff --numstat output ("<added>\t<deleted>\t<path>",
+// with "-" counts for binary files)
+func parseNumstat(output string) []FileStat {
+	stats := []FileStat{}
 	for _, line := range strings.Split(output, "\n") {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "worktree ") {
-			path := strings.TrimPrefix(line, "worktree ")
-			paths = append(paths, path)
+		parts := strings.SplitN(line, "\t", 3)
+		if len(parts) != 3 {
+			continue
 		}
+		added, _ := strconv.Atoi(parts[0])
+		deleted, _ := strconv.Atoi(parts[1])
+		stats = append(stats, FileStat{Path: parts[2], Added: added, Deleted: deleted})
+	}
+	return stats
+}
+
+// ShowFile returns the content of path at ref, e.g. a file on origin/main
+func (c *gitClient) ShowFile(ref, path string) (string, error) {
+	return c.runCmd("show", ref+":"+path)
+}
+
+// FetchRef fetches ref (e.g. refs/stack/metadata) from the push remote into the
+// same local ref, replacing it. A ref the remote doesn't have is left alone locally.
+func (c *gitClient) FetchRef(ref string) error {
+	refspec := fmt.Sprintf("+%s:%s", ref, ref)
+	if DryRun {
+		printDryRun("fetch", pushRemote(), refspec)
+		return nil
+	}
+	if Offline {
+		return nil
+	}
+	_, err := c.runCmd("fetch", pushRemote(), refspec)
+	if err != nil && strings.Contains(err.Error(), "couldn't find remote ref") {
+		return nil
+	}
+	return fetchError(err)
+}
+
+// CommitFile writes a commit whose tree holds a single file, on top of parent
+// (a root commit when parent is empty), and returns its hash. Only objects are
+// written: the index, the worktree and every ref are left alone, so it runs in a
+// dry run too, and the ref update that makes the commit reachable is skipped.
+func (c *gitClient) CommitFile(parent, name, content, message string) (string, error) {
+	blob, err := c.runCmdWithInput(content, "hash-object", "-w", "--stdin")
+	if err != nil {
+		return "", err
+	}
+	tree, err := c.runCmdWithInput(fmt.Sprintf("100644 blob %s\t%s\n", blob, name), "mktree")
+	if err != nil {
+		return "", err
+	}
+	args := []string{"commit-tree", tree, "-m", message}
+	if parent != "" {
+		args = append(args, "-p", parent)
+	}
+	return c.runCmd(args...)
+}
+
+// UpdateRef points ref at sha
+func (c *gitClient) UpdateRef(ref, sha string) error {
+	if DryRun {
+		printDryRun("update-ref", ref, sha)
+		return nil
+	}
+	_, err := c.runCmd("update-ref", ref, sha)
+	return err
+}
+
+// PushRef pushes ref to the same ref on the push remote. The push is rejected
+// unless it fast-forwards what the remote has.
+func (c *gitClient) PushRef(ref string) error {
+	args := []string{"push", pushRemote(), fmt.Sprintf("%s:%s", ref, ref)}
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	if Offline {
+		return offlineError(args...)
+	}
+	_, err := c.runCmd(args...)
+	return err
+}
+
+// EditFile opens path in the editor git uses for commit messages (core.editor,
+// GIT_EDITOR, VISUAL or EDITOR) and waits for it to be closed
+func (c *gitClient) EditFile(path string) error {
+	editor, err := c.runCmd("var", "GIT_EDITOR")
+	if err != nil {
+		return err
+	}
+	// The editor setting may include arguments, so let the shell split it like git does
+	cmd := exec.Command("sh", "-c", editor+` "$@"`, editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor '%s' failed: %w", editor, err)
+	}
+	return nil
+}
+
+// EditBranchDescription opens the description of branch (branch.<name>.description)
+// in the editor, like git branch --edit-description
+func (c *gitClient) EditBranchDescription(branch string) error {
+	if DryRun {
+		printDryRun("branch", "--edit-description", branch)
+		return nil
+	}
+	cmd := exec.Command("git", "branch", "--edit-description", branch)
+	cmd.Dir = c.workDir()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git branch --edit-description %s failed: %w", branch, err)
+	}
+	return nil
+}
+
+// ListWorktrees returns a list of all worktree paths
+func (c *gitClient) ListWorktrees() ([]string, error) {
+	paths := []string{}
+	for _, worktree := range c.worktrees() {
+		paths = append(paths, worktree.path)
 	}
 
 	return paths, nil
@@ -1,8 +1,12 @@
 package git
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/javoire/stackinator/internal/dryrun"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,3 +24,341 @@ func TestGitClientInterface(t *testing.T) {
 // For unit tests focused on critical path, we rely on integration tests or testutil mocks
 // The real value is in testing the stack package and command packages with mocked clients
 
+
+func TestParseRangeDiff(t *testing.T) {
+	output := `1:  e07ab30 = 1:  e07ab30 Add config loader
+2:  479d557 ! 2:  067e6c3 Handle missing file
+    @@ Metadata
+     ## Commit message ##
+    -    Handle missing file
+    +    Handle missing file gracefully
+3:  1234abc < -:  ------- Remove debug logging
+-:  ------- > 3:  a8fe79d Add tests`
+
+	summary := ParseRangeDiff(output)
+
+	assert.Equal(t, []string{"Add config loader"}, summary.Unchanged)
+	assert.Equal(t, []string{"Handle missing file"}, summary.Changed)
+	assert.Equal(t, []string{"Remove debug logging"}, summary.Dropped)
+	assert.Equal(t, []string{"Add tests"}, summary.Added)
+	assert.True(t, summary.HasContentChanges())
+
+	rebasedOnly := ParseRangeDiff("1:  e07ab30 = 1:  f00ba12 Add config loader")
+	assert.False(t, rebasedOnly.HasContentChanges())
+}
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tcmd/sync.go\n-\t-\tdocs/logo.png\n0\t5\tpath with spaces/file.go"
+
+	stats := parseNumstat(output)
+
+	assert.Equal(t, []FileStat{
+		{Path: "cmd/sync.go", Added: 10, Deleted: 2},
+		{Path: "docs/logo.png"},
+		{Path: "path with spaces/file.go", Deleted: 5},
+	}, stats)
+}
+
+func TestParsePickedCommits(t *testing.T) {
+	output := "aaa\nFix typo\n\n(cherry picked from commit 1111111111111111111111111111111111111111)\n\x00\n" +
+		"bbb\nRegular commit\n\x00\n" +
+		"ccc\nPicked twice\n\n(cherry picked from commit 2222222)\n(cherry picked from commit 3333333)\n\x00"
+
+	picked := parsePickedCommits(output)
+
+	assert.Equal(t, []PickedCommit{
+		{SHA: "aaa", Source: "1111111111111111111111111111111111111111"},
+		{SHA: "ccc", Source: "2222222"},
+	}, picked)
+}
+
+func TestParseReflogTip(t *testing.T) {
+	output := "ccc feature@{300}\nbbb feature@{200}\naaa feature@{100}\nddd feature@{250}"
+
+	assert.Equal(t, "bbb", parseReflogTip(output, time.Unix(220, 0)))
+	assert.Equal(t, "ddd", parseReflogTip(output, time.Unix(260, 0)))
+	assert.Equal(t, "ccc", parseReflogTip(output, time.Unix(300, 0)))
+	assert.Equal(t, "", parseReflogTip(output, time.Unix(50, 0)))
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	ReadOnly = true
+	defer func() { ReadOnly = false }()
+
+	client := NewGitClient()
+
+	assert.ErrorIs(t, client.SetConfig("branch.a.stackparent", "main"), ErrReadOnly)
+	assert.ErrorIs(t, client.Push("a", true), ErrReadOnly)
+	assert.ErrorIs(t, client.CheckoutBranch("a"), ErrReadOnly)
+	assert.ErrorIs(t, client.DeleteBranchForce("a"), ErrReadOnly)
+}
+
+func TestIsConfigLockError(t *testing.T) {
+	locked := errors.New("git config branch.a.stackparent main failed: error: could not lock config file .git/config: File exists\n")
+
+	assert.True(t, isConfigLockError(locked))
+	assert.False(t, isConfigLockError(errors.New("git config failed: error: key does not contain a section")))
+	assert.False(t, isConfigLockError(nil))
+}
+
+func TestPushError(t *testing.T) {
+	rejected := errors.New("git push origin a failed: ! [rejected] a -> a (fetch first)")
+	var diverged *DivergedError
+
+	assert.ErrorAs(t, pushError("a", rejected), &diverged)
+	assert.Equal(t, "a", diverged.Branch)
+	assert.ErrorAs(t, pushError("a", errors.New("git push origin a failed: ! [rejected] a -> a (stale info)")), &diverged)
+
+	other := errors.New("git push origin a failed: Could not resolve host")
+	assert.Equal(t, other, pushError("a", other))
+	assert.NoError(t, pushError("a", nil))
+}
+
+func TestFetchError(t *testing.T) {
+	defer func() {
+		Offline = false
+		OnUnreachable = nil
+	}()
+
+	other := errors.New("git fetch origin failed: fatal: couldn't find remote ref")
+	assert.Equal(t, other, fetchError(other))
+	assert.False(t, Offline)
+
+	var reason error
+	OnUnreachable = func(err error) { reason = err }
+	unreachable := errors.New("git fetch origin failed: ssh: Could not resolve hostname github.com")
+	assert.NoError(t, fetchError(unreachable))
+	assert.True(t, Offline)
+	assert.Equal(t, unreachable, reason)
+}
+
+func TestParseStaleSubmodules(t *testing.T) {
+	output := " 1111111111111111111111111111111111111111 libs/clean (v1.0)\n" +
+		"+2222222222222222222222222222222222222222 libs/moved (v1.1-2-g2222222)\n" +
+		"-3333333333333333333333333333333333333333 libs/uninitialized\n" +
+		"+4444444444444444444444444444444444444444 libs/moved/nested (heads/main)\n" +
+		"+5555555555555555555555555555555555555555 libs/with space (v2.0)\n" +
+		"+6666666666666666666666666666666666666666 libs/no describe"
+
+	assert.Equal(t, []string{"libs/moved", "libs/moved/nested", "libs/with space", "libs/no describe"}, parseStaleSubmodules(output))
+	assert.Empty(t, parseStaleSubmodules(""))
+}
+
+func TestParseConflictedSubmodules(t *testing.T) {
+	output := "160000 1111111111111111111111111111111111111111 1\tlibs/shared\x00" +
+		"160000 2222222222222222222222222222222222222222 2\tlibs/shared\x00" +
+		"160000 3333333333333333333333333333333333333333 3\tlibs/shared\x00" +
+		"100644 4444444444444444444444444444444444444444 2\tcmd/sync.go\x00" +
+		"100644 5555555555555555555555555555555555555555 3\tcmd/sync.go\x00" +
+		"160000 6666666666666666666666666666666666666666 2\tlibs/new\nline\x00"
+
+	assert.Equal(t, []string{"libs/shared", "libs/new\nline"}, parseConflictedSubmodules(output))
+	assert.Empty(t, parseConflictedSubmodules(""))
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   [2]int
+		ok     bool
+	}{
+		{"git version 2.39.5", [2]int{2, 39}, true},
+		{"git version 2.39.3 (Apple Git-145)\n", [2]int{2, 39}, true},
+		{"git version 2.42.0.windows.2", [2]int{2, 42}, true},
+		{"git version 3.0", [2]int{3, 0}, true},
+		{"git version dev", [2]int{}, false},
+		{"", [2]int{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseVersion(tt.output)
+		assert.Equal(t, tt.ok, ok, tt.output)
+		assert.Equal(t, tt.want, got, tt.output)
+	}
+}
+
+func TestRemoteOfWithPushRemote(t *testing.T) {
+	defer func() {
+		PushRemote = ""
+		SetUpstreamBranches(nil)
+	}()
+
+	assert.Equal(t, "origin/feature-a", RemoteRef("feature-a"))
+
+	PushRemote = "myfork"
+	SetUpstreamBranches([]string{"main"})
+
+	assert.Equal(t, "origin/main", RemoteRef("main"))
+	assert.Equal(t, "myfork/feature-a", RemoteRef("feature-a"))
+	assert.Equal(t, "main", TrimRemote("origin/main"))
+	assert.Equal(t, "feature-a", TrimRemote("myfork/feature-a"))
+	assert.Equal(t, "other/feature-a", TrimRemote("other/feature-a"))
+}
+
+func TestParseBranchConfig(t *testing.T) {
+	output := "branch.feature-a.stackparent\nmain\x00" +
+		"branch.my.feature.stackparent\nfeature-a\x00" +
+		"branch.spaced.stackparent\nmy parent\x00" +
+		"branch.empty.stackparent\n\x00" +
+		// A key without a value, as "[branch \"flag\"] stackparent" writes it
+		"branch.flag.stackparent\x00"
+
+	assert.Equal(t, map[string]string{
+		"feature-a":  "main",
+		"my.feature": "feature-a",
+		"spaced":     "my parent",
+	}, parseBranchConfig(output, "stackparent"))
+	assert.Empty(t, parseBranchConfig(output, "stackpr"))
+	assert.Empty(t, parseBranchConfig("", "stackparent"))
+
+	// A variable that ends like another doesn't match it
+	output = "branch.feature-a.stackpr\n12\x00branch.feature-a.stackprbase\nmain\x00"
+	assert.Equal(t, map[string]string{"feature-a": "12"}, parseBranchConfig(output, "stackpr"))
+	assert.Equal(t, map[string]string{"feature-a": "main"}, parseBranchConfig(output, "stackprbase"))
+}
+
+func TestParseWorktrees(t *testing.T) {
+	output := "worktree /repo\x00HEAD 1111111111111111111111111111111111111111\x00branch refs/heads/main\x00\x00" +
+		"worktree /work/my tree \x00HEAD 2222222222222222222222222222222222222222\x00branch refs/heads/feature-a\x00\x00" +
+		"worktree /work/new\nline\x00HEAD 3333333333333333333333333333333333333333\x00detached\x00\x00"
+
+	assert.Equal(t, []worktreeEntry{
+		{path: "/repo", branch: "main"},
+		{path: "/work/my tree ", branch: "feature-a"},
+		{path: "/work/new\nline"},
+	}, parseWorktrees(output, "\x00"))
+
+	// Without -z, as older git prints it
+	output = "worktree /repo\nHEAD 1111111111111111111111111111111111111111\nbranch refs/heads/main\n\n" +
+		"worktree /work/my tree\nHEAD 2222222222222222222222222222222222222222\nbranch refs/heads/feature-a"
+	assert.Equal(t, []worktreeEntry{
+		{path: "/repo", branch: "main"},
+		{path: "/work/my tree", branch: "feature-a"},
+	}, parseWorktrees(output, "\n"))
+	assert.Empty(t, parseWorktrees("", "\x00"))
+}
+
+func FuzzParseBranchConfig(f *testing.F) {
+	f.Add("feature-a", "main")
+	f.Add("fix/ünïcode", "main")
+	f.Add("a=b", "c = d")
+	f.Add("with space", "tab\there")
+	f.Add("branch.x.stackparent", "multi\nline")
+
+	f.Fuzz(func(t *testing.T, branch, parent string) {
+		// Config keys can't hold a newline or a NUL, nor values a NUL
+		if branch == "" || parent == "" || strings.ContainsAny(branch, "\n\x00") || strings.Contains(parent, "\x00") {
+			t.Skip()
+		}
+		output := "branch.other.stackparent\nmain\x00branch." + branch + ".stackparent\n" + parent + "\x00"
+
+		parents := parseBranchConfig(output, "stackparent")
+
+		assert.Equal(t, parent, parents[branch])
+		if branch != "other" {
+			assert.Equal(t, "main", parents["other"])
+			assert.Len(t, parents, 2)
+		}
+	})
+}
+
+func TestParseHunks(t *testing.T) {
+	output := "diff --git a/app.go b/app.go\n" +
+		"index 1111111..2222222 100644\n" +
+		"--- a/app.go\n" +
+		"+++ b/app.go\n" +
+		"@@ -3 +3 @@ func main() {\n" +
+		"-\told()\n" +
+		"+\tnew()  \n" +
+		"@@ -10,2 +9,0 @@\n" +
+		"--- gone\n" +
+		"-gone too\n" +
+		"@@ -20,0 +19,2 @@\n" +
+		"+++ added\n" +
+		"+added too\n" +
+		"diff --git a/new.go b/new.go\n" +
+		"new file mode 100644\n" +
+		"--- /dev/null\n" +
+		"+++ b/new.go\n" +
+		"@@ -0,0 +1 @@\n" +
+		"+package main\n" +
+		"diff --git a/my file.txt b/my file.txt\n" +
+		"--- a/my file.txt\t\n" +
+		"+++ b/my file.txt\t\n" +
+		"@@ -1 +1 @@\n" +
+		"-a\n" +
+		"\\ No newline at end of file\n" +
+		"+b\n" +
+		"\\ No newline at end of file\n" +
+		"diff --git a/logo.png b/logo.png\n" +
+		"Binary files a/logo.png and b/logo.png differ\n"
+
+	assert.Equal(t, []Hunk{
+		{Path: "app.go", OldStart: 3, OldLines: 1, NewStart: 3, NewLines: 1, Lines: []string{"-\told()", "+\tnew()  "}},
+		{Path: "app.go", OldStart: 10, OldLines: 2, NewStart: 9, NewLines: 0, Lines: []string{"--- gone", "-gone too"}},
+		{Path: "app.go", OldStart: 20, OldLines: 0, NewStart: 19, NewLines: 2, Lines: []string{"+++ added", "+added too"}},
+		{Path: "my file.txt", OldStart: 1, OldLines: 1, NewStart: 1, NewLines: 1, Lines: []string{"-a", "\\ No newline at end of file", "+b", "\\ No newline at end of file"}},
+	}, parseHunks(output))
+	assert.Empty(t, parseHunks(""))
+}
+
+func TestParseBlameCommits(t *testing.T) {
+	a := strings.Repeat("a", 40)
+	b := strings.Repeat("b", 40)
+	output := a + " 3 3 2\n" +
+		"author A\n" +
+		"summary " + b + " 1 1\n" +
+		"filename app.go\n" +
+		"\t" + b + " 1 1\n" +
+		a + " 4 4\n" +
+		"\tsecond\n" +
+		b + " 7 5 1\n" +
+		"previous " + a + " app.go\n" +
+		"filename app.go\n" +
+		"\tthird\n"
+
+	assert.Equal(t, []string{a, a, b}, parseBlameCommits(output))
+	assert.Empty(t, parseBlameCommits(""))
+}
+
+func TestFormatPatch(t *testing.T) {
+	hunks := []Hunk{
+		{Path: "b.go", OldStart: 5, OldLines: 1, NewLines: 1, Lines: []string{"-x", "+y"}},
+		{Path: "a.go", OldStart: 10, OldLines: 1, NewLines: 0, Lines: []string{"-gone"}},
+		{Path: "a.go", OldStart: 2, OldLines: 1, NewLines: 3, Lines: []string{"-one", "+one", "+two", "+three"}},
+		{Path: "my file", OldStart: 4, OldLines: 0, NewLines: 1, Lines: []string{"+new"}},
+	}
+
+	assert.Equal(t, "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n"+
+		"@@ -2,1 +2,3 @@\n-one\n+one\n+two\n+three\n"+
+		"@@ -10,1 +11,0 @@\n-gone\n"+
+		"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n"+
+		"@@ -5,1 +5,1 @@\n-x\n+y\n"+
+		"diff --git a/my file b/my file\n--- a/my file\t\n+++ b/my file\t\n"+
+		"@@ -4,0 +5,1 @@\n+new\n", formatPatch(hunks))
+}
+
+func TestDryRunKind(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected dryrun.Kind
+	}{
+		{[]string{"push", "--force-with-lease", "origin", "feature"}, dryrun.Push},
+		{[]string{"push", "origin", "refs/stack/metadata:refs/stack/metadata"}, dryrun.Push},
+		{[]string{"push", "--delete", "origin", "feature"}, dryrun.Delete},
+		{[]string{"push", "origin", ":feature"}, dryrun.Delete},
+		{[]string{"branch", "-D", "feature"}, dryrun.Delete},
+		{[]string{"branch", "--force", "feature", "abc123"}, dryrun.Other},
+		{[]string{"worktree", "remove", "--force", "/tmp/wt"}, dryrun.Delete},
+		{[]string{"worktree", "add", "/tmp/wt", "feature"}, dryrun.Other},
+		{[]string{"update-ref", "-d", "refs/stack/metadata"}, dryrun.Delete},
+		{[]string{"config", "--unset", "branch.feature.stackparent"}, dryrun.Config},
+		{[]string{"-c", "sequence.editor=:", "rebase", "--interactive", "main"}, dryrun.Other},
+		{[]string{"-C", "/tmp/wt", "checkout", "--detach", "main"}, dryrun.Other},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			assert.Equal(t, tt.expected, dryRunKind(tt.args))
+		})
+	}
+}
@@ -1,12 +1,23 @@
 package git
 
+import "time"
+
 // GitClient defines the interface for all git operations
 type GitClient interface {
 	GetRepoRoot() (string, error)
+	GetHooksDir() (string, error)
+	GetCommonDir() (string, error)
+	IsBareRepo() bool
 	GetCurrentBranch() (string, error)
 	ListBranches() ([]string, error)
+	CheckBranchName(name string) error
 	GetConfig(key string) string
 	GetAllStackParents() (map[string]string, error)
+	GetAllStackPRs() (map[string]int, error)
+	GetAllStackPRBases() (map[string]string, error)
+	GetStackUmbrellas() (map[string]bool, error)
+	GetOrphanedStackParents() (map[string]string, error)
+	GetStackConfigKeys() ([]string, error)
 	SetConfig(key, value string) error
 	UnsetConfig(key string) error
 	CreateBranch(name, from string) error
@@ -15,17 +26,32 @@ type GitClient interface {
 	RenameBranch(oldName, newName string) error
 	Rebase(onto string) error
 	RebaseOnto(newBase, oldBase, currentBranch string) error
+	RebaseSignoff(upstream string) error
+	SupportsUpdateRefs() bool
+	RebaseUpdateRefs(upstream string) error
 	FetchBranch(branch string) error
 	Push(branch string, forceWithLease bool) error
 	PushWithExpectedRemote(branch string, expectedRemoteSha string) error
 	ForcePush(branch string) error
 	IsWorkingTreeClean() (bool, error)
+	UpdateSubmodules() error
+	GetStaleSubmodules() ([]string, error)
+	GetConflictedSubmodules() ([]string, error)
+	UsesLFS() bool
+	LFSInstalled() bool
+	LFSPull() error
+	HasStagedChanges() (bool, error)
+	CommitFixup(commit string) error
+	Commit(message string, amend, all bool) error
+	RebaseAutosquash(upstream string) error
 	Fetch() error
 	BranchExists(name string) bool
 	RemoteBranchExists(name string) bool
 	GetRemoteBranchesSet() map[string]bool
+	GetBranchTips() (map[string]string, error)
 	IsRebaseInProgress() bool
 	IsCherryPickInProgress() bool
+	GetOperationInProgress() *Operation
 	AbortRebase() error
 	AbortCherryPick() error
 	ResetToRemote(branch string) error
@@ -33,20 +59,53 @@ type GitClient interface {
 	GetCommitHash(ref string) (string, error)
 	GetUniqueCommits(base, branch string) ([]string, error)
 	GetUniqueCommitsByPatch(base, branch string) ([]string, error)
+	IsMergedByPatch(base, branch string) (bool, error)
+	GetBranchesMergedInto(ref string) (map[string]bool, error)
 	CherryPick(commit string) error
+	CherryPickTracked(commit string) error
+	FormatPatch(base, branch, dir string) ([]string, error)
+	ApplyPatches(files []string) error
+	GetPickedCommits(base, branch string) ([]PickedCommit, error)
+	IsCommitLanded(base, commit string) (bool, error)
 	ResetHard(ref string) error
+	ForceBranch(branch, ref string) error
 	Stash(message string) error
 	StashPop() error
 	GetDefaultBranch() string
 	GetWorktreeBranches() (map[string]string, error)
 	GetCurrentWorktreePath() (string, error)
 	IsCommitsBehind(branch, base string) (bool, error)
+	CountCommitsBehind(branch, base string) (int, error)
+	GetCommitTime(ref string) (time.Time, error)
+	GetCommitMessage(ref string) (string, error)
+	GetCommitAuthorEmail(ref string) (string, error)
+	GetBranchTipAt(branch string, at time.Time) (string, error)
 	DeleteBranch(name string) error
 	DeleteBranchForce(name string) error
 	AddWorktree(path, branch string) error
 	AddWorktreeNewBranch(path, newBranch, baseBranch string) error
 	AddWorktreeFromRemote(path, branch string) error
 	RemoveWorktree(path string) error
+	ForceRemoveWorktree(path string) error
+	PruneWorktrees() error
+	AddWorktreeDetached(path, ref string) error
+	GetWorktreeHead(path string) (string, error)
+	CheckoutDetachedInWorktree(path, ref string) error
+	RangeDiff(oldBase, oldHead, newBase, newHead string) (string, error)
+	GetChangedFiles(base, branch string) ([]string, error)
+	GetMergeConflicts(base, branch string) ([]string, error)
+	GetDiffStat(base, branch string) ([]FileStat, error)
+	ShowFile(ref, path string) (string, error)
+	FetchRef(ref string) error
+	CommitFile(parent, name, content, message string) (string, error)
+	GetStagedHunks() ([]Hunk, error)
+	BlameLines(ref, path string, start, count int) ([]string, error)
+	CommitHunks(parent string, hunks []Hunk, message string) (string, error)
+	UpdateRef(ref, sha string) error
+	PushRef(ref string) error
+	EditFile(path string) error
+	EditBranchDescription(branch string) error
 	ListWorktrees() ([]string, error)
 	GetRemoteURL(remoteName string) string
+	InWorktree(path string) GitClient
 }
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// LFS makes pushes also upload the branch's Git LFS objects and fail when that
+// fails, instead of relying on the pre-push hook git lfs installs, which may be
+// missing. Set for repos that track files in LFS.
+var LFS = false
+
+// SkipLFSSmudge leaves LFS files as pointers on checkouts and rebases, so
+// checkouts don't download large files that are only passed through on the way
+// to another branch. LFSPull fills them in afterwards.
+var SkipLFSSmudge = false
+
+// commandEnv returns the environment git commands run with, nil for the current one
+func commandEnv() []string {
+	if !SkipLFSSmudge {
+		return nil
+	}
+	return append(os.Environ(), "GIT_LFS_SKIP_SMUDGE=1")
+}
+
+// UsesLFS reports whether any tracked file is stored in Git LFS. It only reads
+// .gitattributes, so it works without git-lfs installed.
+func (c *gitClient) UsesLFS() bool {
+	return c.runCmdMayFail("ls-files", "--", ":(attr:filter=lfs)") != ""
+}
+
+// LFSInstalled reports whether the git lfs command is available
+func (c *gitClient) LFSInstalled() bool {
+	return c.runCmdMayFail("lfs", "version") != ""
+}
+
+// LFSPull downloads the LFS files of the checked-out branch and replaces their
+// pointers with the content, e.g. after checkouts with SkipLFSSmudge
+func (c *gitClient) LFSPull() error {
+	args := []string{"lfs", "pull"}
+	if DryRun {
+		printDryRun(args...)
+		return nil
+	}
+	if Offline {
+		return offlineError(args...)
+	}
+	if Verbose {
+		fmt.Printf("  [git] %s\n", strings.Join(args, " "))
+	}
+	// Unlike the other commands, without GIT_LFS_SKIP_SMUDGE
+	cmd := exec.Command("git", args...)
+	cmd.Dir = c.workDir()
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %s", strings.Join(args, " "), stderr.String())
+	}
+	return nil
+}
+
+// pushLFS uploads the LFS objects of branch after it was pushed, when LFS is set.
+// Objects the server already has are skipped, so this is quick when the pre-push
+// hook uploaded them.
+func (c *gitClient) pushLFS(branch string) error {
+	if !LFS {
+		return nil
+	}
+	if _, err := c.runCmd("lfs", "push", RemoteOf(branch), branch); err != nil {
+		return fmt.Errorf("pushed %s, but uploading its LFS objects failed: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Offline skips fetching and fails pushes, so commands work from the local refs
+// (--offline / STACKINATOR_OFFLINE). It is also set once origin turns out to be
+// unreachable.
+var Offline = false
+
+// OnUnreachable is called when a fetch finds origin unreachable, before the
+// command carries on offline
+var OnUnreachable func(err error)
+
+// ErrOffline is returned by pushes while offline
+var ErrOffline = errors.New("origin is not reachable while offline")
+
+// networkFailures are what git prints when it can't reach the remote
+var networkFailures = []string{
+	"Could not resolve host",
+	"Could not resolve hostname",
+	"Temporary failure in name resolution",
+	"Network is unreachable",
+	"No route to host",
+	"Connection timed out",
+	"Operation timed out",
+	"Connection refused",
+}
+
+// isNetworkError reports whether a git command failed because the remote
+// couldn't be reached
+func isNetworkError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, failure := range networkFailures {
+		if strings.Contains(msg, failure) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchError switches to offline when a fetch failed because origin is
+// unreachable, so the command carries on with the refs it already has
+func fetchError(err error) error {
+	if !isNetworkError(err) {
+		return err
+	}
+	if !Offline {
+		Offline = true
+		if OnUnreachable != nil {
+			OnUnreachable(err)
+		}
+	}
+	return nil
+}
+
+func offlineError(args ...string) error {
+	return fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrOffline)
+}
@@ -0,0 +1,193 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReadOnly makes NewGitClient return a client that refuses every call that would
+// change the repository, its config or origin (STACKINATOR_READONLY / --read-only).
+// Fetching is still allowed, as it only updates remote-tracking refs.
+var ReadOnly = false
+
+// ErrReadOnly is returned by mutating calls in read-only mode
+var ErrReadOnly = errors.New("not allowed in read-only mode")
+
+// readOnlyClient wraps a GitClient, failing every mutating call
+type readOnlyClient struct {
+	GitClient
+}
+
+func readOnlyError(args ...string) error {
+	return fmt.Errorf("git %s: %w", strings.Join(args, " "), ErrReadOnly)
+}
+
+func (c *readOnlyClient) SetConfig(key, value string) error {
+	return readOnlyError("config", key, value)
+}
+
+func (c *readOnlyClient) UnsetConfig(key string) error {
+	return readOnlyError("config", "--unset", key)
+}
+
+func (c *readOnlyClient) CreateBranch(name, from string) error {
+	return readOnlyError("branch", name, from)
+}
+
+func (c *readOnlyClient) CreateBranchAndCheckout(name, from string) error {
+	return readOnlyError("checkout", "-b", name, from)
+}
+
+func (c *readOnlyClient) CheckoutBranch(name string) error {
+	return readOnlyError("checkout", name)
+}
+
+func (c *readOnlyClient) UpdateSubmodules() error {
+	return readOnlyError("submodule", "update", "--init", "--recursive")
+}
+
+func (c *readOnlyClient) LFSPull() error {
+	return readOnlyError("lfs", "pull")
+}
+
+func (c *readOnlyClient) RenameBranch(oldName, newName string) error {
+	return readOnlyError("branch", "-m", oldName, newName)
+}
+
+func (c *readOnlyClient) Rebase(onto string) error {
+	return readOnlyError("rebase", onto)
+}
+
+func (c *readOnlyClient) RebaseSignoff(upstream string) error {
+	return readOnlyError("rebase", "--signoff", upstream)
+}
+
+func (c *readOnlyClient) RebaseUpdateRefs(upstream string) error {
+	return readOnlyError("rebase", "--update-refs", upstream)
+}
+
+func (c *readOnlyClient) RebaseOnto(newBase, oldBase, currentBranch string) error {
+	return readOnlyError("rebase", "--onto", newBase, oldBase, currentBranch)
+}
+
+func (c *readOnlyClient) RebaseAutosquash(upstream string) error {
+	return readOnlyError("rebase", "--autosquash", upstream)
+}
+
+func (c *readOnlyClient) Push(branch string, forceWithLease bool) error {
+	return readOnlyError("push", RemoteOf(branch), branch)
+}
+
+func (c *readOnlyClient) PushWithExpectedRemote(branch string, expectedRemoteSha string) error {
+	return readOnlyError("push", RemoteOf(branch), branch)
+}
+
+func (c *readOnlyClient) ForcePush(branch string) error {
+	return readOnlyError("push", "--force", RemoteOf(branch), branch)
+}
+
+func (c *readOnlyClient) CommitFixup(commit string) error {
+	return readOnlyError("commit", "--fixup="+commit)
+}
+
+func (c *readOnlyClient) Commit(message string, amend, all bool) error {
+	return readOnlyError(commitArgs(message, amend, all)...)
+}
+
+func (c *readOnlyClient) AbortRebase() error {
+	return readOnlyError("rebase", "--abort")
+}
+
+func (c *readOnlyClient) AbortCherryPick() error {
+	return readOnlyError("cherry-pick", "--abort")
+}
+
+func (c *readOnlyClient) ResetToRemote(branch string) error {
+	return readOnlyError("reset", "--hard", RemoteRef(branch))
+}
+
+func (c *readOnlyClient) CherryPick(commit string) error {
+	return readOnlyError("cherry-pick", commit)
+}
+
+func (c *readOnlyClient) CherryPickTracked(commit string) error {
+	return readOnlyError("cherry-pick", "-x", commit)
+}
+
+func (c *readOnlyClient) ApplyPatches(files []string) error {
+	return readOnlyError(append([]string{"am", "--3way"}, files...)...)
+}
+
+func (c *readOnlyClient) ForceBranch(branch, ref string) error {
+	return readOnlyError("branch", "--force", branch, ref)
+}
+
+func (c *readOnlyClient) EditBranchDescription(branch string) error {
+	return readOnlyError("branch", "--edit-description", branch)
+}
+
+func (c *readOnlyClient) ResetHard(ref string) error {
+	return readOnlyError("reset", "--hard", ref)
+}
+
+func (c *readOnlyClient) Stash(message string) error {
+	return readOnlyError("stash", "push", "-m", message)
+}
+
+func (c *readOnlyClient) StashPop() error {
+	return readOnlyError("stash", "pop")
+}
+
+func (c *readOnlyClient) DeleteBranch(name string) error {
+	return readOnlyError("branch", "-d", name)
+}
+
+func (c *readOnlyClient) DeleteBranchForce(name string) error {
+	return readOnlyError("branch", "-D", name)
+}
+
+func (c *readOnlyClient) AddWorktree(path, branch string) error {
+	return readOnlyError("worktree", "add", path, branch)
+}
+
+func (c *readOnlyClient) AddWorktreeNewBranch(path, newBranch, baseBranch string) error {
+	return readOnlyError("worktree", "add", "-b", newBranch, path, baseBranch)
+}
+
+func (c *readOnlyClient) AddWorktreeFromRemote(path, branch string) error {
+	return readOnlyError("worktree", "add", path, branch)
+}
+
+func (c *readOnlyClient) RemoveWorktree(path string) error {
+	return readOnlyError("worktree", "remove", path)
+}
+
+func (c *readOnlyClient) ForceRemoveWorktree(path string) error {
+	return readOnlyError("worktree", "remove", "--force", path)
+}
+
+func (c *readOnlyClient) PruneWorktrees() error {
+	return readOnlyError("worktree", "prune")
+}
+
+// InWorktree keeps the client in the worktree at path read-only
+func (c *readOnlyClient) InWorktree(path string) GitClient {
+	return &readOnlyClient{c.GitClient.InWorktree(path)}
+}
+
+func (c *readOnlyClient) AddWorktreeDetached(path, ref string) error {
+	return readOnlyError("worktree", "add", "--detach", path, ref)
+}
+
+func (c *readOnlyClient) CheckoutDetachedInWorktree(path, ref string) error {
+	return readOnlyError("-C", path, "checkout", "--detach", ref)
+}
+
+func (c *readOnlyClient) UpdateRef(ref, sha string) error {
+	return readOnlyError("update-ref", ref, sha)
+}
+
+func (c *readOnlyClient) PushRef(ref string) error {
+	return readOnlyError("push", pushRemote(), ref)
+}
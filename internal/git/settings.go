@@ -0,0 +1,48 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/javoire/stackinator/internal/config"
+)
+
+var (
+	settingsMu sync.Mutex
+	settings   = make(map[string]*config.Settings) // Settings files by working directory
+)
+
+// fileSettings returns the settings from .stackinator.toml and the user's
+// settings file for the repository the client runs in, reading them once per
+// working directory. A file that can't be parsed is reported and left out.
+func (c *gitClient) fileSettings() *config.Settings {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	dir := c.workDir()
+	if s, ok := settings[dir]; ok {
+		return s
+	}
+	s, err := config.Load(c.runCmdMayFail("rev-parse", "--show-toplevel"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring settings file: %v\n", err)
+	}
+	if ignored := s.Ignored(); len(ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s can't set %s, set them in git config or %s instead\n",
+			config.RepoFile, strings.Join(ignored, ", "), config.UserFile())
+	}
+	settings[dir] = s
+	return s
+}
+
+// fileConfig looks a stack.* key up in the settings files, for when git config
+// doesn't set it
+func (c *gitClient) fileConfig(key string) string {
+	if !strings.HasPrefix(strings.ToLower(key), "stack.") {
+		return ""
+	}
+	value, _ := c.fileSettings().Get(key)
+	return value
+}
@@ -0,0 +1,97 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AutoUpdateSubmodules runs 'git submodule update' after every checkout, so
+// submodules follow the commits recorded by the branch (stack.submodules.update)
+var AutoUpdateSubmodules = false
+
+// submoduleMode is the file mode git records for a submodule (a gitlink)
+const submoduleMode = "160000"
+
+// UpdateSubmodules checks out the commits the superproject records for its
+// submodules, initializing any that are new
+func (c *gitClient) UpdateSubmodules() error {
+	if DryRun {
+		printDryRun("submodule", "update", "--init", "--recursive")
+		return nil
+	}
+	_, err := c.runCmd("submodule", "update", "--init", "--recursive")
+	return err
+}
+
+// GetStaleSubmodules returns the submodules checked out at a different commit
+// than the one the superproject records, e.g. after a rebase moved it
+func (c *gitClient) GetStaleSubmodules() ([]string, error) {
+	output, err := c.runCmd("submodule", "status", "--recursive")
+	if err != nil {
+		return nil, err
+	}
+	return parseStaleSubmodules(output), nil
+}
+
+// GetConflictedSubmodules returns the submodules with a conflict in the index,
+// which have to be resolved by picking a commit rather than editing a file
+func (c *gitClient) GetConflictedSubmodules() ([]string, error) {
+	output, err := c.runCmd("ls-files", "-z", "--unmerged")
+	if err != nil {
+		return nil, err
+	}
+	return parseConflictedSubmodules(output), nil
+}
+
+// parseStaleSubmodules reads 'git submodule status' output, where a leading +
+// marks a submodule whose checkout doesn't match the recorded commit. The path
+// is everything between the commit and the describe output, spaces included.
+func parseStaleSubmodules(output string) []string {
+	stale := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "+") {
+			continue
+		}
+		// +<sha> <path> (<describe>)
+		_, path, ok := strings.Cut(line[1:], " ")
+		if !ok {
+			continue
+		}
+		if i := strings.LastIndex(path, " ("); i > 0 && strings.HasSuffix(path, ")") {
+			path = path[:i]
+		}
+		if path != "" {
+			stale = append(stale, path)
+		}
+	}
+	return stale
+}
+
+// parseConflictedSubmodules reads 'git ls-files -z --unmerged' output, one entry
+// per conflict stage ("<mode> <sha> <stage>\t<path>") ended by a NUL, keeping
+// the gitlinks
+func parseConflictedSubmodules(output string) []string {
+	seen := make(map[string]bool)
+	conflicted := []string{}
+	for _, line := range strings.Split(output, "\x00") {
+		info, path, ok := strings.Cut(line, "\t")
+		if !ok || !strings.HasPrefix(info, submoduleMode+" ") || seen[path] {
+			continue
+		}
+		seen[path] = true
+		conflicted = append(conflicted, path)
+	}
+	return conflicted
+}
+
+// updateSubmodulesAfterCheckout follows a checkout with a submodule update when
+// AutoUpdateSubmodules is set
+func (c *gitClient) updateSubmodulesAfterCheckout(branch string) error {
+	if !AutoUpdateSubmodules {
+		return nil
+	}
+	if err := c.UpdateSubmodules(); err != nil {
+		return fmt.Errorf("checked out %s, but failed to update submodules: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// updateRefsVersion is the first git release with 'git rebase --update-refs'
+var updateRefsVersion = [2]int{2, 38}
+
+// worktreeListZVersion is the first git release with 'git worktree list -z'
+var worktreeListZVersion = [2]int{2, 36}
+
+var (
+	versionOnce sync.Once
+	version     [2]int // Major and minor version of the git on PATH, zero if unknown
+)
+
+// gitVersion returns the major and minor version of git, asking it only once
+func (c *gitClient) gitVersion() [2]int {
+	versionOnce.Do(func() {
+		version, _ = parseVersion(c.runCmdMayFail("version"))
+	})
+	return version
+}
+
+// gitAtLeast reports whether git is at least the given release
+func (c *gitClient) gitAtLeast(release [2]int) bool {
+	v := c.gitVersion()
+	return v[0] > release[0] || (v[0] == release[0] && v[1] >= release[1])
+}
+
+// SupportsUpdateRefs reports whether git can move the branches along the way
+// when rebasing a branch (git rebase --update-refs, git 2.38 and later)
+func (c *gitClient) SupportsUpdateRefs() bool {
+	return c.gitAtLeast(updateRefsVersion)
+}
+
+// RebaseUpdateRefs rebases the current branch onto upstream, moving every local
+// branch pointing at one of the rebased commits along with it. A stack rebased
+// from its top branch is restacked in a single pass.
+func (c *gitClient) RebaseUpdateRefs(upstream string) error {
+	if DryRun {
+		printDryRun("rebase", "--autostash", "--update-refs", upstream)
+		return nil
+	}
+	_, err := c.runCmd("rebase", "--autostash", "--update-refs", upstream)
+	return c.conflictError(err)
+}
+
+// parseVersion reads the major and minor version from 'git version' output, e.g.
+// "git version 2.39.3 (Apple Git-145)" or "git version 2.42.0.windows.2"
+func parseVersion(output string) ([2]int, bool) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "git" || fields[1] != "version" {
+		return [2]int{}, false
+	}
+	parts := strings.Split(fields[2], ".")
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
@@ -0,0 +1,102 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Host is the GitHub host to use instead of the one in the origin URL, from
+// stack.ghHost or GH_HOST. Needed when origin goes through an SSH host alias,
+// e.g. git@github-work:org/repo for a second account.
+var Host = ""
+
+// User is the gh account to use on the host (stack.ghUser), for when gh is logged
+// in to several accounts there. Empty means gh's active account.
+var User = ""
+
+var (
+	tokensMu sync.Mutex
+	tokens   = make(map[string]string)
+)
+
+// defaultHost is the host gh uses for repos given as OWNER/REPO
+const defaultHost = "github.com"
+
+// WithHost returns repo (OWNER/REPO or HOST/OWNER/REPO) moved to Host, if set
+func WithHost(repo string) string {
+	if Host == "" || repo == "" {
+		return repo
+	}
+	parts := strings.Split(repo, "/")
+	if len(parts) == 3 {
+		parts = parts[1:]
+	}
+	if Host == defaultHost {
+		return strings.Join(parts, "/")
+	}
+	return Host + "/" + strings.Join(parts, "/")
+}
+
+// HostOf returns the host of repo (OWNER/REPO or HOST/OWNER/REPO)
+func HostOf(repo string) string {
+	if parts := strings.Split(repo, "/"); len(parts) == 3 {
+		return parts[0]
+	}
+	if Host != "" {
+		return Host
+	}
+	return defaultHost
+}
+
+// ghEnv returns the environment gh runs with for host: the current one, plus the
+// host and the token of the selected account when they were configured
+func ghEnv(host string) ([]string, error) {
+	if Host == "" && User == "" {
+		return nil, nil
+	}
+	env := os.Environ()
+	if Host != "" {
+		env = append(env, "GH_HOST="+Host)
+	}
+	if User != "" {
+		token, err := userToken(host)
+		if err != nil {
+			return nil, err
+		}
+		// gh reads GH_TOKEN for github.com and GH_ENTERPRISE_TOKEN for other hosts
+		name := "GH_ENTERPRISE_TOKEN"
+		if host == defaultHost {
+			name = "GH_TOKEN"
+		}
+		env = append(env, name+"="+token)
+	}
+	return env, nil
+}
+
+// userToken returns the token gh stored for User on host, looked up once per host
+func userToken(host string) (string, error) {
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	if token, ok := tokens[host]; ok {
+		return token, nil
+	}
+
+	if Verbose {
+		fmt.Printf("  [gh] auth token --hostname %s --user %s\n", host, User)
+	}
+	cmd := exec.Command("gh", "auth", "token", "--hostname", host, "--user", User)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", &AuthError{Err: fmt.Errorf("gh has no token for %s on %s (log in with 'gh auth login --hostname %s'): %s",
+			User, host, host, strings.TrimSpace(stderr.String()))}
+	}
+	token := strings.TrimSpace(stdout.String())
+	tokens[host] = token
+	return token, nil
+}
@@ -0,0 +1,41 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthError is returned when gh isn't logged in, or its token was rejected
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return fmt.Sprintf("GitHub authentication failed: %v", e.Err)
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+// authFailures are what gh prints when it has no usable credentials
+var authFailures = []string{
+	"gh auth login",
+	"HTTP 401",
+	"Bad credentials",
+	"authentication required",
+}
+
+// ghError returns an AuthError when gh failed for lack of credentials, a
+// NetworkError when it couldn't reach GitHub, and err as it is otherwise
+func ghError(stderr string, err error) error {
+	if isNetworkFailure(stderr) {
+		return &NetworkError{Err: err}
+	}
+	for _, failure := range authFailures {
+		if strings.Contains(stderr, failure) {
+			return &AuthError{Err: err}
+		}
+	}
+	return err
+}
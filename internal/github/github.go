@@ -3,10 +3,15 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/script"
 )
 
 // Verbose controls whether to print executed commands
@@ -15,6 +20,32 @@ var Verbose = false
 // DryRun controls whether to actually execute mutation commands
 var DryRun = false
 
+// WorkDir is the directory gh commands run in (empty means the current directory).
+// gh uses it to find the repo when no --repo is given.
+var WorkDir = ""
+
+// HeadOwner is the owner of the fork PR branches are pushed to, when PRs are
+// opened from a fork against the repo (stack.pushRemote). Empty when branches
+// are pushed to the repo itself.
+var HeadOwner = ""
+
+// headRef returns how gh names branch as a PR head: OWNER:branch from a fork
+func headRef(branch string) string {
+	if HeadOwner != "" {
+		return HeadOwner + ":" + branch
+	}
+	return branch
+}
+
+// ownHead reports whether a PR's head branch is one of ours: in the repo itself,
+// or in HeadOwner's fork when PRs are opened from one
+func ownHead(isCrossRepository bool, headOwner string) bool {
+	if HeadOwner == "" {
+		return !isCrossRepository
+	}
+	return isCrossRepository && strings.EqualFold(headOwner, HeadOwner)
+}
+
 // PRInfo contains information about a Pull Request
 type PRInfo struct {
 	Number           int
@@ -23,6 +54,26 @@ type PRInfo struct {
 	Title            string
 	URL              string
 	MergeStateStatus string // "BEHIND", "BLOCKED", "CLEAN", "DIRTY", "UNKNOWN", "UNSTABLE"
+	Head             string // Head branch the PR is opened from
+	ReviewDecision   string // "APPROVED", "CHANGES_REQUESTED", "REVIEW_REQUIRED", or empty
+	Reviewed         bool   // At least one review has been submitted
+
+	// Only populated by ListAuthoredPRs
+	IsDraft   bool
+	Checks    string // "SUCCESS", "FAILURE", "PENDING", or empty if there are no checks
+	CreatedAt time.Time
+
+	// Only populated by GetPRChecks (along with Checks)
+	HeadSHA string
+
+	// Only populated by GetPRsForBranches: whether the PR is in the repository's
+	// merge queue, and its position there
+	InMergeQueue       bool
+	MergeQueuePosition int
+
+	// Only populated by GetPRsForBranches, GetPRsByAuthor and the GitLab client;
+	// nil when the labels weren't fetched
+	Labels []string
 }
 
 // githubClient implements the GitHubClient interface using exec.Command
@@ -33,7 +84,17 @@ type githubClient struct {
 // NewGitHubClient creates a new GitHubClient implementation
 // repo should be in OWNER/REPO format (e.g., "javoire/stackinator")
 func NewGitHubClient(repo string) GitHubClient {
-	return &githubClient{repo: repo}
+	return Wrap(&githubClient{repo: WithHost(repo)})
+}
+
+// Wrap adds the PR cache used offline, and read-only mode when it's on, to a
+// client. Backends for other forges go through it too.
+func Wrap(client GitHubClient) GitHubClient {
+	client = &offlineClient{client}
+	if ReadOnly {
+		return &readOnlyClient{client}
+	}
+	return client
 }
 
 // ParseRepoFromURL extracts HOST/OWNER/REPO or OWNER/REPO from a git remote URL
@@ -94,37 +155,77 @@ func (c *githubClient) runGH(args ...string) (string, error) {
 	if c.repo != "" {
 		args = append([]string{"--repo", c.repo}, args...)
 	}
+	return execGH(HostOf(c.repo), args...)
+}
+
+// execGH runs gh with args as given for a repo on host and returns stdout
+func execGH(host string, args ...string) (string, error) {
 	if Verbose {
 		fmt.Printf("  [gh] %s\n", strings.Join(args, " "))
 	}
+	env, err := ghEnv(host)
+	if err != nil {
+		return "", err
+	}
 	cmd := exec.Command("gh", args...)
+	cmd.Dir = WorkDir
+	cmd.Env = env
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
-	if err != nil {
-		return "", fmt.Errorf("gh %s failed: %s", strings.Join(args, " "), stderr.String())
+	if err := cmd.Run(); err != nil {
+		return "", ghError(stderr.String(), fmt.Errorf("gh %s failed: %s", strings.Join(args, " "), stderr.String()))
 	}
 
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// printDryRun reports a gh command skipped because of DryRun. Inline output shows
+// summary; the generated script gets the full command, including --repo.
+func (c *githubClient) printDryRun(summary string, args ...string) {
+	dryrun.Record(dryrun.PREdit)
+	if script.Enabled {
+		if c.repo != "" {
+			args = append([]string{"--repo", c.repo}, args...)
+		}
+		script.Record("gh", args...)
+		return
+	}
+	fmt.Printf("  [DRY RUN] gh %s\n", summary)
+}
+
 // GetPRForBranch returns PR info for the specified branch
 func (c *githubClient) GetPRForBranch(branch string) (*PRInfo, error) {
-	output, err := c.runGH("pr", "view", branch, "--json", "number,state,baseRefName,title,url,mergeStateStatus")
+	return c.viewPR(headRef(branch))
+}
+
+// GetPRByNumber returns PR info for a PR number, whatever its head branch is called now
+func (c *githubClient) GetPRByNumber(number int) (*PRInfo, error) {
+	return c.viewPR(strconv.Itoa(number))
+}
+
+// viewPR returns PR info for a branch name or PR number, or nil if there is no such PR
+func (c *githubClient) viewPR(ref string) (*PRInfo, error) {
+	output, err := c.runGH("pr", "view", ref, "--json", "number,state,headRefName,baseRefName,title,url,mergeStateStatus,reviewDecision,latestReviews")
 	if err != nil {
+		if errors.Is(err, ErrOffline) {
+			return nil, err
+		}
 		// No PR exists for this branch
 		return nil, nil
 	}
 
 	var data struct {
-		Number           int    `json:"number"`
-		State            string `json:"state"`
-		BaseRefName      string `json:"baseRefName"`
-		Title            string `json:"title"`
-		URL              string `json:"url"`
-		MergeStateStatus string `json:"mergeStateStatus"`
+		Number           int        `json:"number"`
+		State            string     `json:"state"`
+		HeadRefName      string     `json:"headRefName"`
+		BaseRefName      string     `json:"baseRefName"`
+		Title            string     `json:"title"`
+		URL              string     `json:"url"`
+		MergeStateStatus string     `json:"mergeStateStatus"`
+		ReviewDecision   string     `json:"reviewDecision"`
+		LatestReviews    []struct{} `json:"latestReviews"`
 	}
 
 	if err := json.Unmarshal([]byte(output), &data); err != nil {
@@ -138,6 +239,9 @@ func (c *githubClient) GetPRForBranch(branch string) (*PRInfo, error) {
 		Title:            data.Title,
 		URL:              data.URL,
 		MergeStateStatus: data.MergeStateStatus,
+		Head:             data.HeadRefName,
+		ReviewDecision:   data.ReviewDecision,
+		Reviewed:         len(data.LatestReviews) > 0,
 	}, nil
 }
 
@@ -145,19 +249,21 @@ func (c *githubClient) GetPRForBranch(branch string) (*PRInfo, error) {
 // Only fetches open PRs to avoid timeouts on repos with many PRs
 func (c *githubClient) GetAllPRs() (map[string]*PRInfo, error) {
 	// Fetch only open PRs - much faster and avoids 502 timeouts on large repos
-	output, err := c.runGH("pr", "list", "--state", "open", "--json", "number,state,headRefName,baseRefName,title,url,mergeStateStatus", "--limit", "500")
+	output, err := c.runGH("pr", "list", "--state", "open", "--json", "number,state,headRefName,baseRefName,title,url,mergeStateStatus,reviewDecision,latestReviews", "--limit", "500")
 	if err != nil {
 		return nil, fmt.Errorf("failed to list PRs: %w", err)
 	}
 
 	var prs []struct {
-		Number           int    `json:"number"`
-		State            string `json:"state"`
-		HeadRefName      string `json:"headRefName"`
-		BaseRefName      string `json:"baseRefName"`
-		Title            string `json:"title"`
-		URL              string `json:"url"`
-		MergeStateStatus string `json:"mergeStateStatus"`
+		Number           int        `json:"number"`
+		State            string     `json:"state"`
+		HeadRefName      string     `json:"headRefName"`
+		BaseRefName      string     `json:"baseRefName"`
+		Title            string     `json:"title"`
+		URL              string     `json:"url"`
+		MergeStateStatus string     `json:"mergeStateStatus"`
+		ReviewDecision   string     `json:"reviewDecision"`
+		LatestReviews    []struct{} `json:"latestReviews"`
 	}
 
 	if err := json.Unmarshal([]byte(output), &prs); err != nil {
@@ -181,20 +287,588 @@ func (c *githubClient) GetAllPRs() (map[string]*PRInfo, error) {
 			Title:            pr.Title,
 			URL:              pr.URL,
 			MergeStateStatus: pr.MergeStateStatus,
+			Head:             pr.HeadRefName,
+			ReviewDecision:   pr.ReviewDecision,
+			Reviewed:         len(pr.LatestReviews) > 0,
+		}
+	}
+
+	return prMap, nil
+}
+
+// prQueryBatchSize is how many head branches are looked up per GraphQL request
+const prQueryBatchSize = 50
+
+// GetPRsForBranches fetches the PRs (in any state) whose head is one of branches,
+// batching branches into GraphQL queries instead of listing every PR in the repo.
+// PRs from forks other than HeadOwner's are ignored. If a branch has several PRs,
+// the open one wins, otherwise the most recently created.
+func (c *githubClient) GetPRsForBranches(branches []string) (map[string]*PRInfo, error) {
+	prMap := make(map[string]*PRInfo)
+
+	for start := 0; start < len(branches); start += prQueryBatchSize {
+		end := start + prQueryBatchSize
+		if end > len(branches) {
+			end = len(branches)
+		}
+		batch := branches[start:end]
+
+		output, err := c.queryPRsForBranches(batch, true)
+		if err != nil && isUnknownFieldError(err) {
+			// GitHub Enterprise Server versions without merge queues
+			output, err = c.queryPRsForBranches(batch, false)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to query PRs: %w", err)
+		}
+
+		found, err := parsePRsForBranches(output, batch)
+		if err != nil {
+			return nil, err
+		}
+		for branch, pr := range found {
+			prMap[branch] = pr
+		}
+	}
+
+	if Verbose {
+		fmt.Printf("  [gh] Found PRs for %d of %d branches\n", len(prMap), len(branches))
+	}
+
+	return prMap, nil
+}
+
+// queryPRsForBranches runs a buildPRsForBranchesQuery query for one batch of branches
+func (c *githubClient) queryPRsForBranches(batch []string, withMergeQueue bool) (string, error) {
+	args := c.graphQLArgs(buildPRsForBranchesQuery(len(batch), withMergeQueue))
+	for i, branch := range batch {
+		args = append(args, "-f", fmt.Sprintf("h%d=%s", i, branch))
+	}
+	return execGH(HostOf(c.repo), args...)
+}
+
+// isUnknownFieldError reports whether a GraphQL query failed because the server
+// doesn't know one of the queried PR fields
+func isUnknownFieldError(err error) bool {
+	return strings.Contains(err.Error(), "doesn't exist on type 'PullRequest'")
+}
+
+// graphQLArgs builds a gh api graphql call for the client's repo. gh api doesn't take
+// --repo, so the owner and name are passed as variables (and the host for GHE).
+// Without a repo, gh fills in {owner}/{repo} from the current directory.
+func (c *githubClient) graphQLArgs(query string) []string {
+	args := []string{"api", "graphql"}
+
+	parts := strings.Split(c.repo, "/")
+	switch len(parts) {
+	case 2:
+		args = append(args, "-f", "owner="+parts[0], "-f", "name="+parts[1])
+	case 3:
+		args = append(args, "--hostname", parts[0], "-f", "owner="+parts[1], "-f", "name="+parts[2])
+	default:
+		// Placeholders are only expanded in -F fields
+		args = append(args, "-F", "owner={owner}", "-F", "name={repo}")
+	}
+
+	return append(args, "-f", "query="+query)
+}
+
+// buildPRsForBranchesQuery builds a query with one aliased pullRequests lookup per
+// head branch, taking the branch names from variables $h0..$h<n-1>. Merge queue
+// fields are only asked for with withMergeQueue.
+func buildPRsForBranchesQuery(n int, withMergeQueue bool) string {
+	var vars, fields strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&vars, ", $h%d: String!", i)
+		fmt.Fprintf(&fields, "    b%d: pullRequests(headRefName: $h%d, first: 10, orderBy: {field: CREATED_AT, direction: DESC}) { ...pr }\n", i, i)
+	}
+
+	mergeQueue := ""
+	if withMergeQueue {
+		mergeQueue = "\n    isInMergeQueue mergeQueueEntry { position }"
+	}
+
+	return fmt.Sprintf(`query($owner: String!, $name: String!%s) {
+  repository(owner: $owner, name: $name) {
+%s  }
+}
+
+fragment pr on PullRequestConnection {
+  nodes {
+    number state headRefName baseRefName title url mergeStateStatus reviewDecision isCrossRepository
+    headRepositoryOwner { login }
+    latestReviews(first: 1) { totalCount }
+    labels(first: 20) { nodes { name } }%s
+  }
+}`, vars.String(), fields.String(), mergeQueue)
+}
+
+// parsePRsForBranches parses the response of a buildPRsForBranchesQuery query,
+// where alias b<i> holds the PRs for branches[i]
+func parsePRsForBranches(output string, branches []string) (map[string]*PRInfo, error) {
+	var response struct {
+		Data struct {
+			Repository map[string]struct {
+				Nodes []struct {
+					Number            int    `json:"number"`
+					State             string `json:"state"`
+					HeadRefName       string `json:"headRefName"`
+					BaseRefName       string `json:"baseRefName"`
+					Title             string `json:"title"`
+					URL               string `json:"url"`
+					MergeStateStatus  string `json:"mergeStateStatus"`
+					ReviewDecision    string `json:"reviewDecision"`
+					IsCrossRepository bool   `json:"isCrossRepository"`
+					HeadOwner         struct {
+						Login string `json:"login"`
+					} `json:"headRepositoryOwner"`
+					LatestReviews struct {
+						TotalCount int `json:"totalCount"`
+					} `json:"latestReviews"`
+					Labels struct {
+						Nodes []struct {
+							Name string `json:"name"`
+						} `json:"nodes"`
+					} `json:"labels"`
+					IsInMergeQueue  bool `json:"isInMergeQueue"`
+					MergeQueueEntry *struct {
+						Position int `json:"position"`
+					} `json:"mergeQueueEntry"`
+				} `json:"nodes"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse PR query: %w", err)
+	}
+
+	prMap := make(map[string]*PRInfo)
+	for i, branch := range branches {
+		connection, ok := response.Data.Repository[fmt.Sprintf("b%d", i)]
+		if !ok {
+			continue
+		}
+
+		var chosen *PRInfo
+		for _, pr := range connection.Nodes {
+			if !ownHead(pr.IsCrossRepository, pr.HeadOwner.Login) {
+				continue
+			}
+			info := &PRInfo{
+				Number:           pr.Number,
+				State:            pr.State,
+				Base:             pr.BaseRefName,
+				Title:            pr.Title,
+				URL:              pr.URL,
+				MergeStateStatus: pr.MergeStateStatus,
+				Head:             pr.HeadRefName,
+				ReviewDecision:   pr.ReviewDecision,
+				Reviewed:         pr.LatestReviews.TotalCount > 0,
+				InMergeQueue:     pr.IsInMergeQueue,
+				Labels:           []string{},
+			}
+			for _, label := range pr.Labels.Nodes {
+				info.Labels = append(info.Labels, label.Name)
+			}
+			if pr.MergeQueueEntry != nil {
+				info.MergeQueuePosition = pr.MergeQueueEntry.Position
+			}
+			// Nodes are newest first
+			if chosen == nil || (info.State == "OPEN" && chosen.State != "OPEN") {
+				chosen = info
+			}
+		}
+		if chosen != nil {
+			prMap[branch] = chosen
 		}
 	}
 
 	return prMap, nil
 }
 
+// GetPRsByAuthor fetches the PRs (in any state) opened by author, keyed by head
+// branch. Use "@me" for the authenticated user. On shared repos this is a much
+// smaller payload than listing every PR. PRs from forks other than HeadOwner's
+// are ignored.
+func (c *githubClient) GetPRsByAuthor(author string) (map[string]*PRInfo, error) {
+	output, err := c.runGH("pr", "list", "--state", "all", "--author", author,
+		"--json", "number,state,headRefName,baseRefName,title,url,mergeStateStatus,reviewDecision,latestReviews,isCrossRepository,headRepositoryOwner,labels",
+		"--limit", "500")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+
+	prMap, err := parsePRsByAuthor(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if Verbose {
+		fmt.Printf("  [gh] Fetched PRs for %d branches by %s\n", len(prMap), author)
+	}
+
+	return prMap, nil
+}
+
+// parsePRsByAuthor parses the JSON output of the pr list call in GetPRsByAuthor.
+// PRs are listed newest first; if a branch has several, the open one wins.
+func parsePRsByAuthor(output string) (map[string]*PRInfo, error) {
+	var prs []struct {
+		Number            int        `json:"number"`
+		State             string     `json:"state"`
+		HeadRefName       string     `json:"headRefName"`
+		BaseRefName       string     `json:"baseRefName"`
+		Title             string     `json:"title"`
+		URL               string     `json:"url"`
+		MergeStateStatus  string     `json:"mergeStateStatus"`
+		ReviewDecision    string     `json:"reviewDecision"`
+		LatestReviews     []struct{} `json:"latestReviews"`
+		IsCrossRepository bool       `json:"isCrossRepository"`
+		HeadOwner         struct {
+			Login string `json:"login"`
+		} `json:"headRepositoryOwner"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+
+	prMap := make(map[string]*PRInfo)
+	for _, pr := range prs {
+		if !ownHead(pr.IsCrossRepository, pr.HeadOwner.Login) {
+			continue
+		}
+		if existing, ok := prMap[pr.HeadRefName]; ok && (existing.State == "OPEN" || pr.State != "OPEN") {
+			continue
+		}
+		info := &PRInfo{
+			Number:           pr.Number,
+			State:            pr.State,
+			Base:             pr.BaseRefName,
+			Title:            pr.Title,
+			URL:              pr.URL,
+			MergeStateStatus: pr.MergeStateStatus,
+			Head:             pr.HeadRefName,
+			ReviewDecision:   pr.ReviewDecision,
+			Reviewed:         len(pr.LatestReviews) > 0,
+			Labels:           []string{},
+		}
+		for _, label := range pr.Labels {
+			info.Labels = append(info.Labels, label.Name)
+		}
+		prMap[pr.HeadRefName] = info
+	}
+	return prMap, nil
+}
+
+// ListAuthoredPRs fetches all open PRs by author in a single call, including draft,
+// review and CI state. Use "@me" for the authenticated user.
+func (c *githubClient) ListAuthoredPRs(author string) ([]*PRInfo, error) {
+	output, err := c.runGH("pr", "list", "--state", "open", "--author", author,
+		"--json", "number,state,headRefName,baseRefName,title,url,isDraft,reviewDecision,statusCheckRollup,createdAt",
+		"--limit", "500")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PRs: %w", err)
+	}
+
+	prs, err := parseAuthoredPRs(output)
+	if err != nil {
+		return nil, err
+	}
+
+	if Verbose {
+		fmt.Printf("  [gh] Fetched %d PRs by %s\n", len(prs), author)
+	}
+
+	return prs, nil
+}
+
+// parseAuthoredPRs parses the JSON output of the pr list call in ListAuthoredPRs
+func parseAuthoredPRs(output string) ([]*PRInfo, error) {
+	var prs []struct {
+		Number            int          `json:"number"`
+		State             string       `json:"state"`
+		HeadRefName       string       `json:"headRefName"`
+		BaseRefName       string       `json:"baseRefName"`
+		Title             string       `json:"title"`
+		URL               string       `json:"url"`
+		IsDraft           bool         `json:"isDraft"`
+		ReviewDecision    string       `json:"reviewDecision"`
+		StatusCheckRollup []checkState `json:"statusCheckRollup"`
+		CreatedAt         time.Time    `json:"createdAt"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse PR list: %w", err)
+	}
+
+	result := make([]*PRInfo, 0, len(prs))
+	for _, pr := range prs {
+		result = append(result, &PRInfo{
+			Number:         pr.Number,
+			State:          pr.State,
+			Base:           pr.BaseRefName,
+			Title:          pr.Title,
+			URL:            pr.URL,
+			Head:           pr.HeadRefName,
+			IsDraft:        pr.IsDraft,
+			ReviewDecision: pr.ReviewDecision,
+			Checks:         summarizeChecks(pr.StatusCheckRollup),
+			CreatedAt:      pr.CreatedAt,
+		})
+	}
+	return result, nil
+}
+
+// checkState is one entry of a PR's statusCheckRollup. Check runs report
+// status/conclusion, while legacy commit statuses only report state.
+type checkState struct {
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+// summarizeChecks reduces a PR's checks to a single state: any failure wins,
+// then anything still running, otherwise success
+func summarizeChecks(checks []checkState) string {
+	if len(checks) == 0 {
+		return ""
+	}
+
+	pending := false
+	for _, check := range checks {
+		switch {
+		case check.State == "FAILURE" || check.State == "ERROR":
+			return "FAILURE"
+		case check.Conclusion == "FAILURE" || check.Conclusion == "TIMED_OUT" ||
+			check.Conclusion == "CANCELLED" || check.Conclusion == "ACTION_REQUIRED" ||
+			check.Conclusion == "STARTUP_FAILURE":
+			return "FAILURE"
+		case check.State == "PENDING" || check.State == "EXPECTED":
+			pending = true
+		case check.State == "" && check.Status != "COMPLETED":
+			pending = true
+		}
+	}
+
+	if pending {
+		return "PENDING"
+	}
+	return "SUCCESS"
+}
+
+// GetPRChecks returns the PR's head commit and the combined state of its checks
+func (c *githubClient) GetPRChecks(prNumber int) (*PRInfo, error) {
+	output, err := c.runGH("pr", "view", strconv.Itoa(prNumber), "--json", "number,headRefOid,statusCheckRollup")
+	if err != nil {
+		return nil, err
+	}
+	return parsePRChecks(output)
+}
+
+// parsePRChecks parses the JSON output of the pr view call in GetPRChecks
+func parsePRChecks(output string) (*PRInfo, error) {
+	var data struct {
+		Number            int          `json:"number"`
+		HeadRefOid        string       `json:"headRefOid"`
+		StatusCheckRollup []checkState `json:"statusCheckRollup"`
+	}
+
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return nil, fmt.Errorf("failed to parse PR checks: %w", err)
+	}
+
+	return &PRInfo{
+		Number:  data.Number,
+		HeadSHA: data.HeadRefOid,
+		Checks:  summarizeChecks(data.StatusCheckRollup),
+	}, nil
+}
+
 // UpdatePRBase updates the base branch of a PR
 func (c *githubClient) UpdatePRBase(prNumber int, newBase string) error {
+	args := []string{"pr", "edit", strconv.Itoa(prNumber), "--base", newBase}
+	if DryRun {
+		c.printDryRun(strings.Join(args, " "), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// CreatePR opens a PR from head into base, with the title and body filled in from
+// the branch's commits. A non-empty body replaces the one from the commits.
+// Returns nil in dry-run mode.
+func (c *githubClient) CreatePR(head, base string, draft bool, body string) (*PRInfo, error) {
+	args := []string{"pr", "create", "--head", headRef(head), "--base", base, "--fill"}
+	if body != "" {
+		args = append(args, "--body", body)
+	}
+	if draft {
+		args = append(args, "--draft")
+	}
+	if DryRun {
+		c.printDryRun(strings.Join(args, " "), args...)
+		return nil, nil
+	}
+
+	output, err := c.runGH(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+
+	// gh prints the URL of the new PR as its last line
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	url := strings.TrimSpace(lines[len(lines)-1])
+	number, err := strconv.Atoi(url[strings.LastIndex(url, "/")+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse PR number from %q", url)
+	}
+
+	return &PRInfo{
+		Number: number,
+		State:  "OPEN",
+		Base:   base,
+		Head:   head,
+		URL:    url,
+	}, nil
+}
+
+// CommentOnPR adds a comment to a PR
+func (c *githubClient) CommentOnPR(prNumber int, body string) error {
+	args := []string{"pr", "comment", strconv.Itoa(prNumber), "--body", body}
+	if DryRun {
+		// The body is usually long, so it's only included in the script
+		c.printDryRun(fmt.Sprintf("pr comment %d", prNumber), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// GetPRBody returns the description of a PR
+func (c *githubClient) GetPRBody(prNumber int) (string, error) {
+	output, err := c.runGH("pr", "view", strconv.Itoa(prNumber), "--json", "body")
+	if err != nil {
+		return "", err
+	}
+
+	var data struct {
+		Body string `json:"body"`
+	}
+	if err := json.Unmarshal([]byte(output), &data); err != nil {
+		return "", fmt.Errorf("failed to parse PR body: %w", err)
+	}
+	return data.Body, nil
+}
+
+// UpdatePRBody replaces the description of a PR
+func (c *githubClient) UpdatePRBody(prNumber int, body string) error {
+	args := []string{"pr", "edit", strconv.Itoa(prNumber), "--body", body}
+	if DryRun {
+		// The body is usually long, so it's only included in the script
+		c.printDryRun(fmt.Sprintf("pr edit %d --body ...", prNumber), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// UpdatePRTitle replaces the title of a PR
+func (c *githubClient) UpdatePRTitle(prNumber int, title string) error {
+	args := []string{"pr", "edit", strconv.Itoa(prNumber), "--title", title}
+	if DryRun {
+		c.printDryRun(fmt.Sprintf("pr edit %d --title %q", prNumber, title), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// EditPRLabels adds and removes labels on a PR. Labels that are already there
+// (or already gone) are left as they are, and added labels that don't exist in
+// the repository yet are created first.
+func (c *githubClient) EditPRLabels(prNumber int, add, remove []string) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	args := []string{"pr", "edit", strconv.Itoa(prNumber)}
+	for _, label := range add {
+		args = append(args, "--add-label", label)
+	}
+	for _, label := range remove {
+		args = append(args, "--remove-label", label)
+	}
+	if DryRun {
+		c.printDryRun(strings.Join(args, " "), args...)
+		return nil
+	}
+
+	for _, label := range add {
+		if _, err := c.runGH("label", "create", label); err != nil && !strings.Contains(err.Error(), "already exists") {
+			return fmt.Errorf("failed to create label %s: %w", label, err)
+		}
+	}
+	_, err := c.runGH(args...)
+	return err
+}
+
+// RequestReviewers requests reviews on a PR from users or teams ("alice", "org/team")
+func (c *githubClient) RequestReviewers(prNumber int, reviewers []string) error {
+	args := []string{"pr", "edit", strconv.Itoa(prNumber), "--add-reviewer", strings.Join(reviewers, ",")}
+	if DryRun {
+		c.printDryRun(strings.Join(args, " "), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// GetCurrentUser returns the login of the user gh is authenticated as
+func (c *githubClient) GetCurrentUser() (string, error) {
+	args := []string{"api", "user", "--jq", ".login"}
+	// gh api doesn't take --repo; for GHE the host decides which account is used
+	if parts := strings.Split(c.repo, "/"); len(parts) == 3 {
+		args = append(args, "--hostname", parts[0])
+	}
+	return execGH(HostOf(c.repo), args...)
+}
+
+// MergePR merges a PR with method ("squash", "merge" or "rebase"). When the base
+// branch requires a merge queue, gh adds the PR to the queue instead, so the PR
+// may still be open when this returns.
+func (c *githubClient) MergePR(prNumber int, method string) error {
+	args := []string{"pr", "merge", strconv.Itoa(prNumber), "--" + method}
+	if DryRun {
+		c.printDryRun(strings.Join(args, " "), args...)
+		return nil
+	}
+
+	_, err := c.runGH(args...)
+	return err
+}
+
+// ClosePR closes a PR without merging it, leaving comment on it first when not empty
+func (c *githubClient) ClosePR(prNumber int, comment string) error {
+	args := []string{"pr", "close", strconv.Itoa(prNumber)}
+	if comment != "" {
+		args = append(args, "--comment", comment)
+	}
 	if DryRun {
-		fmt.Printf("  [DRY RUN] gh pr edit %d --base %s\n", prNumber, newBase)
+		c.printDryRun(fmt.Sprintf("pr close %d", prNumber), args...)
 		return nil
 	}
 
-	_, err := c.runGH("pr", "edit", strconv.Itoa(prNumber), "--base", newBase)
+	_, err := c.runGH(args...)
 	return err
 }
 
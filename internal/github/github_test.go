@@ -1,6 +1,7 @@
 package github
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +80,234 @@ func TestParseRepoFromURL(t *testing.T) {
 // Note: More comprehensive tests would require mocking exec.Command or running actual gh CLI commands
 // For unit tests focused on critical path, we rely on integration tests or testutil mocks
 
+
+func TestParseAuthoredPRs(t *testing.T) {
+	output := `[
+		{
+			"number": 12,
+			"state": "OPEN",
+			"headRefName": "feature-b",
+			"baseRefName": "feature-a",
+			"title": "Feature B",
+			"url": "https://github.com/test/repo/pull/12",
+			"isDraft": true,
+			"reviewDecision": "REVIEW_REQUIRED",
+			"statusCheckRollup": [
+				{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "SUCCESS"},
+				{"__typename": "StatusContext", "state": "PENDING"}
+			],
+			"createdAt": "2024-01-02T10:00:00Z"
+		}
+	]`
+
+	prs, err := parseAuthoredPRs(output)
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 1)
+	assert.Equal(t, 12, prs[0].Number)
+	assert.Equal(t, "feature-b", prs[0].Head)
+	assert.Equal(t, "feature-a", prs[0].Base)
+	assert.True(t, prs[0].IsDraft)
+	assert.Equal(t, "REVIEW_REQUIRED", prs[0].ReviewDecision)
+	assert.Equal(t, "PENDING", prs[0].Checks)
+	assert.Equal(t, 2024, prs[0].CreatedAt.Year())
+}
+
+func TestSummarizeChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []checkState
+		expected string
+	}{
+		{
+			name:     "no checks",
+			checks:   nil,
+			expected: "",
+		},
+		{
+			name: "all passed",
+			checks: []checkState{
+				{Status: "COMPLETED", Conclusion: "SUCCESS"},
+				{State: "SUCCESS"},
+				{Status: "COMPLETED", Conclusion: "SKIPPED"},
+			},
+			expected: "SUCCESS",
+		},
+		{
+			name: "check run still running",
+			checks: []checkState{
+				{Status: "COMPLETED", Conclusion: "SUCCESS"},
+				{Status: "IN_PROGRESS"},
+			},
+			expected: "PENDING",
+		},
+		{
+			name: "failure wins over pending",
+			checks: []checkState{
+				{Status: "IN_PROGRESS"},
+				{Status: "COMPLETED", Conclusion: "FAILURE"},
+			},
+			expected: "FAILURE",
+		},
+		{
+			name: "commit status error",
+			checks: []checkState{
+				{State: "ERROR"},
+			},
+			expected: "FAILURE",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, summarizeChecks(tt.checks))
+		})
+	}
+}
+
+func TestParsePRChecks(t *testing.T) {
+	output := `{
+		"number": 12,
+		"headRefOid": "abc123",
+		"statusCheckRollup": [
+			{"__typename": "CheckRun", "status": "COMPLETED", "conclusion": "SUCCESS"},
+			{"__typename": "CheckRun", "status": "IN_PROGRESS", "conclusion": ""}
+		]
+	}`
+
+	pr, err := parsePRChecks(output)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 12, pr.Number)
+	assert.Equal(t, "abc123", pr.HeadSHA)
+	assert.Equal(t, "PENDING", pr.Checks)
+}
+
+func TestBuildPRsForBranchesQuery(t *testing.T) {
+	query := buildPRsForBranchesQuery(2, true)
+
+	assert.Contains(t, query, "$h0: String!, $h1: String!")
+	assert.Contains(t, query, "b0: pullRequests(headRefName: $h0")
+	assert.Contains(t, query, "b1: pullRequests(headRefName: $h1")
+	assert.NotContains(t, query, "$h2")
+	assert.Contains(t, query, "mergeQueueEntry { position }")
+
+	assert.NotContains(t, buildPRsForBranchesQuery(2, false), "mergeQueueEntry")
+}
+
+func TestParsePRsForBranches(t *testing.T) {
+	output := `{"data": {"repository": {
+		"b0": {"nodes": [
+			{"number": 7, "state": "CLOSED", "headRefName": "feature-a", "baseRefName": "main", "latestReviews": {"totalCount": 0}},
+			{"number": 3, "state": "OPEN", "headRefName": "feature-a", "baseRefName": "main", "latestReviews": {"totalCount": 1},
+			 "isInMergeQueue": true, "mergeQueueEntry": {"position": 2}, "labels": {"nodes": [{"name": "stack:1/2"}]}}
+		]},
+		"b1": {"nodes": [
+			{"number": 9, "state": "MERGED", "headRefName": "feature-b", "baseRefName": "feature-a", "latestReviews": {"totalCount": 0}},
+			{"number": 5, "state": "CLOSED", "headRefName": "feature-b", "baseRefName": "feature-a", "latestReviews": {"totalCount": 0}}
+		]},
+		"b2": {"nodes": [
+			{"number": 11, "state": "OPEN", "headRefName": "feature-c", "baseRefName": "main", "isCrossRepository": true}
+		]},
+		"b3": {"nodes": []}
+	}}}`
+
+	prs, err := parsePRsForBranches(output, []string{"feature-a", "feature-b", "feature-c", "feature-d", "feature-e"})
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 2)
+	// The open PR wins over a newer closed one
+	assert.Equal(t, 3, prs["feature-a"].Number)
+	assert.True(t, prs["feature-a"].Reviewed)
+	assert.True(t, prs["feature-a"].InMergeQueue)
+	assert.Equal(t, 2, prs["feature-a"].MergeQueuePosition)
+	assert.Equal(t, []string{"stack:1/2"}, prs["feature-a"].Labels)
+	// Otherwise the newest PR wins
+	assert.Equal(t, 9, prs["feature-b"].Number)
+	assert.Equal(t, "feature-a", prs["feature-b"].Base)
+	// PRs from forks are ignored
+	assert.NotContains(t, prs, "feature-c")
+}
+
+func TestParsePRsForBranchesFromFork(t *testing.T) {
+	HeadOwner = "me"
+	defer func() { HeadOwner = "" }()
+
+	output := `{"data": {"repository": {
+		"b0": {"nodes": [
+			{"number": 4, "state": "OPEN", "headRefName": "feature-a", "baseRefName": "main", "isCrossRepository": true, "headRepositoryOwner": {"login": "someone"}},
+			{"number": 3, "state": "OPEN", "headRefName": "feature-a", "baseRefName": "main", "isCrossRepository": true, "headRepositoryOwner": {"login": "Me"}}
+		]},
+		"b1": {"nodes": [
+			{"number": 2, "state": "OPEN", "headRefName": "feature-b", "baseRefName": "main"}
+		]}
+	}}}`
+
+	prs, err := parsePRsForBranches(output, []string{"feature-a", "feature-b"})
+
+	assert.NoError(t, err)
+	// Only PRs from our fork count, whoever else has a branch by that name
+	assert.Len(t, prs, 1)
+	assert.Equal(t, 3, prs["feature-a"].Number)
+	assert.Equal(t, "me:feature-a", headRef("feature-a"))
+}
+
+func TestParsePRsByAuthor(t *testing.T) {
+	output := `[
+		{"number": 8, "state": "CLOSED", "headRefName": "feature-a", "baseRefName": "main", "latestReviews": []},
+		{"number": 6, "state": "MERGED", "headRefName": "feature-b", "baseRefName": "feature-a", "latestReviews": [{}]},
+		{"number": 4, "state": "OPEN", "headRefName": "feature-a", "baseRefName": "main", "latestReviews": [], "labels": [{"name": "bug"}]},
+		{"number": 2, "state": "CLOSED", "headRefName": "feature-b", "baseRefName": "main", "latestReviews": []},
+		{"number": 1, "state": "OPEN", "headRefName": "feature-c", "baseRefName": "main", "isCrossRepository": true}
+	]`
+
+	prs, err := parsePRsByAuthor(output)
+
+	assert.NoError(t, err)
+	assert.Len(t, prs, 2)
+	assert.Equal(t, 4, prs["feature-a"].Number)
+	assert.Equal(t, []string{"bug"}, prs["feature-a"].Labels)
+	assert.Equal(t, 6, prs["feature-b"].Number)
+	assert.True(t, prs["feature-b"].Reviewed)
+}
+
+func TestReadOnlyClient(t *testing.T) {
+	ReadOnly = true
+	defer func() { ReadOnly = false }()
+
+	client := NewGitHubClient("owner/repo")
+
+	assert.ErrorIs(t, client.UpdatePRBase(1, "main"), ErrReadOnly)
+	_, err := client.CreatePR("feature-a", "main", false, "")
+	assert.ErrorIs(t, err, ErrReadOnly)
+	assert.ErrorIs(t, client.CommentOnPR(1, "hi"), ErrReadOnly)
+	assert.ErrorIs(t, client.RequestReviewers(1, []string{"alice"}), ErrReadOnly)
+	assert.ErrorIs(t, client.UpdatePRTitle(1, "[1/2] Add auth"), ErrReadOnly)
+	assert.ErrorIs(t, client.EditPRLabels(1, []string{"stack:1/2"}, nil), ErrReadOnly)
+}
+
+func TestGHError(t *testing.T) {
+	err := errors.New("gh pr list failed")
+	var auth *AuthError
+
+	assert.ErrorAs(t, ghError("To get started with GitHub CLI, please run:  gh auth login", err), &auth)
+	assert.ErrorAs(t, ghError("HTTP 401: Bad credentials (https://api.github.com/graphql)", err), &auth)
+	assert.Equal(t, err, ghError("HTTP 404: Not Found", err))
+}
+
+func TestWithHost(t *testing.T) {
+	defer func() { Host = "" }()
+
+	assert.Equal(t, "github-work/acme/widgets", WithHost("github-work/acme/widgets"))
+	assert.Equal(t, "github-work", HostOf("github-work/acme/widgets"))
+	assert.Equal(t, "github.com", HostOf("acme/widgets"))
+
+	Host = "github.com"
+	assert.Equal(t, "acme/widgets", WithHost("github-work/acme/widgets"))
+	assert.Equal(t, "github.com", HostOf("acme/widgets"))
+
+	Host = "ghe.example.com"
+	assert.Equal(t, "ghe.example.com/acme/widgets", WithHost("acme/widgets"))
+	assert.Equal(t, "ghe.example.com", HostOf("acme/widgets"))
+	assert.Equal(t, "", WithHost(""))
+}
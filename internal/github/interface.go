@@ -3,8 +3,23 @@ package github
 // GitHubClient defines the interface for all GitHub operations
 type GitHubClient interface {
 	GetPRForBranch(branch string) (*PRInfo, error)
+	GetPRByNumber(number int) (*PRInfo, error)
 	GetAllPRs() (map[string]*PRInfo, error)
+	GetPRsForBranches(branches []string) (map[string]*PRInfo, error)
+	GetPRsByAuthor(author string) (map[string]*PRInfo, error)
+	ListAuthoredPRs(author string) ([]*PRInfo, error)
+	GetPRChecks(prNumber int) (*PRInfo, error)
 	UpdatePRBase(prNumber int, newBase string) error
+	CreatePR(head, base string, draft bool, body string) (*PRInfo, error)
+	CommentOnPR(prNumber int, body string) error
+	GetPRBody(prNumber int) (string, error)
+	UpdatePRBody(prNumber int, body string) error
+	UpdatePRTitle(prNumber int, title string) error
+	EditPRLabels(prNumber int, add, remove []string) error
+	RequestReviewers(prNumber int, reviewers []string) error
+	MergePR(prNumber int, method string) error
+	ClosePR(prNumber int, comment string) error
+	GetCurrentUser() (string, error)
 	IsPRMerged(prNumber int) (bool, error)
 }
 
@@ -0,0 +1,388 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// Offline answers PR lookups from the PRs cached by earlier runs and fails the
+// calls that need GitHub (--offline / STACKINATOR_OFFLINE). It is also set once
+// GitHub turns out to be unreachable.
+var Offline = false
+
+// OnUnreachable is called when a gh call finds GitHub unreachable, before the
+// command carries on offline
+var OnUnreachable func(err error)
+
+// CacheFile is where the PRs looked up online are kept for offline runs. Empty
+// disables the cache.
+var CacheFile = ""
+
+// ErrOffline is returned by calls that need GitHub while offline
+var ErrOffline = errors.New("GitHub is not reachable while offline")
+
+// NetworkError is returned when gh couldn't reach GitHub
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("GitHub is unreachable: %v", e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes a NetworkError match ErrOffline
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrOffline
+}
+
+// networkFailures are what gh prints when it can't reach the host
+var networkFailures = []string{
+	"error connecting to",
+	"no such host",
+	"i/o timeout",
+	"network is unreachable",
+	"connection refused",
+	"TLS handshake timeout",
+}
+
+// isNetworkFailure reports whether gh's stderr says the host couldn't be reached
+func isNetworkFailure(stderr string) bool {
+	for _, failure := range networkFailures {
+		if strings.Contains(stderr, failure) {
+			return true
+		}
+	}
+	return false
+}
+
+// prCache is the content of CacheFile
+type prCache struct {
+	Updated time.Time
+	PRs     map[string]*PRInfo // By head branch
+}
+
+var cacheMu sync.Mutex
+
+// readCache loads CacheFile, returning an empty cache when there is none
+func readCache() prCache {
+	cache := prCache{PRs: make(map[string]*PRInfo)}
+	if CacheFile == "" {
+		return cache
+	}
+	data, err := os.ReadFile(CacheFile)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil || cache.PRs == nil {
+		return prCache{PRs: make(map[string]*PRInfo)}
+	}
+	return cache
+}
+
+// cachePRs records PRs looked up online, keyed by head branch. The cache is only
+// a fallback, so failing to write it is not an error.
+func cachePRs(prs map[string]*PRInfo) {
+	if CacheFile == "" || len(prs) == 0 {
+		return
+	}
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	cache := readCache()
+	for branch, pr := range prs {
+		if pr != nil {
+			cache.PRs[branch] = pr
+		}
+	}
+	cache.Updated = time.Now()
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(CacheFile), 0o755); err != nil {
+		return
+	}
+	tmp := CacheFile + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, CacheFile); err != nil && Verbose {
+		fmt.Printf("  Note: failed to cache PRs: %v\n", err)
+	}
+}
+
+// CacheUpdated returns when PRs were last cached, zero if never
+func CacheUpdated() time.Time {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	return readCache().Updated
+}
+
+// OfflineNote labels output built without the network as possibly stale
+func OfflineNote(now time.Time) string {
+	updated := CacheUpdated()
+	if updated.IsZero() {
+		return "Offline: using local refs, no PR data cached yet"
+	}
+	return fmt.Sprintf("Offline: using local refs and PR data from %s ago, which may be stale", ui.Age(now.Sub(updated)))
+}
+
+// offlineClient wraps a GitHubClient, caching the PRs it looks up and answering
+// lookups from that cache while offline
+type offlineClient struct {
+	GitHubClient
+}
+
+// goOffline switches to offline after err, if GitHub couldn't be reached
+func goOffline(err error) bool {
+	if !errors.Is(err, ErrOffline) {
+		return false
+	}
+	if !Offline {
+		Offline = true
+		if OnUnreachable != nil {
+			OnUnreachable(err)
+		}
+	}
+	return true
+}
+
+// cached returns the cached PRs, all of them when branches is nil
+func cached(branches []string) map[string]*PRInfo {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+	all := readCache().PRs
+	if branches == nil {
+		return all
+	}
+	prs := make(map[string]*PRInfo)
+	for _, branch := range branches {
+		if pr, ok := all[branch]; ok {
+			prs[branch] = pr
+		}
+	}
+	return prs
+}
+
+func (c *offlineClient) GetPRForBranch(branch string) (*PRInfo, error) {
+	if !Offline {
+		pr, err := c.GitHubClient.GetPRForBranch(branch)
+		if !goOffline(err) {
+			if err == nil && pr != nil {
+				cachePRs(map[string]*PRInfo{branch: pr})
+			}
+			return pr, err
+		}
+	}
+	return cached([]string{branch})[branch], nil
+}
+
+func (c *offlineClient) GetPRByNumber(number int) (*PRInfo, error) {
+	if !Offline {
+		pr, err := c.GitHubClient.GetPRByNumber(number)
+		if !goOffline(err) {
+			if err == nil && pr != nil {
+				cachePRs(map[string]*PRInfo{pr.Head: pr})
+			}
+			return pr, err
+		}
+	}
+	for _, pr := range cached(nil) {
+		if pr.Number == number {
+			return pr, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *offlineClient) GetAllPRs() (map[string]*PRInfo, error) {
+	if !Offline {
+		prs, err := c.GitHubClient.GetAllPRs()
+		if !goOffline(err) {
+			if err == nil {
+				cachePRs(prs)
+			}
+			return prs, err
+		}
+	}
+	open := make(map[string]*PRInfo)
+	for branch, pr := range cached(nil) {
+		if pr.State == "OPEN" {
+			open[branch] = pr
+		}
+	}
+	return open, nil
+}
+
+func (c *offlineClient) GetPRsForBranches(branches []string) (map[string]*PRInfo, error) {
+	if !Offline {
+		prs, err := c.GitHubClient.GetPRsForBranches(branches)
+		if !goOffline(err) {
+			if err == nil {
+				cachePRs(prs)
+			}
+			return prs, err
+		}
+	}
+	return cached(branches), nil
+}
+
+// GetPRsByAuthor answers with every cached PR while offline, as the cache doesn't
+// record authors. Callers narrow the result down to the branches they track.
+func (c *offlineClient) GetPRsByAuthor(author string) (map[string]*PRInfo, error) {
+	if !Offline {
+		prs, err := c.GitHubClient.GetPRsByAuthor(author)
+		if !goOffline(err) {
+			if err == nil {
+				cachePRs(prs)
+			}
+			return prs, err
+		}
+	}
+	return cached(nil), nil
+}
+
+func (c *offlineClient) IsPRMerged(prNumber int) (bool, error) {
+	if !Offline {
+		merged, err := c.GitHubClient.IsPRMerged(prNumber)
+		if !goOffline(err) {
+			return merged, err
+		}
+	}
+	for _, pr := range cached(nil) {
+		if pr.Number == prNumber {
+			return pr.State == "MERGED", nil
+		}
+	}
+	return false, fmt.Errorf("PR #%d: %w", prNumber, ErrOffline)
+}
+
+func (c *offlineClient) ListAuthoredPRs(author string) ([]*PRInfo, error) {
+	if Offline {
+		return nil, ErrOffline
+	}
+	prs, err := c.GitHubClient.ListAuthoredPRs(author)
+	goOffline(err)
+	return prs, err
+}
+
+func (c *offlineClient) GetPRChecks(prNumber int) (*PRInfo, error) {
+	if Offline {
+		return nil, ErrOffline
+	}
+	pr, err := c.GitHubClient.GetPRChecks(prNumber)
+	goOffline(err)
+	return pr, err
+}
+
+func (c *offlineClient) UpdatePRBase(prNumber int, newBase string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.UpdatePRBase(prNumber, newBase)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) CreatePR(head, base string, draft bool, body string) (*PRInfo, error) {
+	if Offline {
+		return nil, ErrOffline
+	}
+	pr, err := c.GitHubClient.CreatePR(head, base, draft, body)
+	goOffline(err)
+	return pr, err
+}
+
+func (c *offlineClient) CommentOnPR(prNumber int, body string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.CommentOnPR(prNumber, body)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) GetPRBody(prNumber int) (string, error) {
+	if Offline {
+		return "", ErrOffline
+	}
+	body, err := c.GitHubClient.GetPRBody(prNumber)
+	goOffline(err)
+	return body, err
+}
+
+func (c *offlineClient) UpdatePRBody(prNumber int, body string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.UpdatePRBody(prNumber, body)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) UpdatePRTitle(prNumber int, title string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.UpdatePRTitle(prNumber, title)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) EditPRLabels(prNumber int, add, remove []string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.EditPRLabels(prNumber, add, remove)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) RequestReviewers(prNumber int, reviewers []string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.RequestReviewers(prNumber, reviewers)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) MergePR(prNumber int, method string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.MergePR(prNumber, method)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) ClosePR(prNumber int, comment string) error {
+	if Offline {
+		return ErrOffline
+	}
+	err := c.GitHubClient.ClosePR(prNumber, comment)
+	goOffline(err)
+	return err
+}
+
+func (c *offlineClient) GetCurrentUser() (string, error) {
+	if Offline {
+		return "", ErrOffline
+	}
+	login, err := c.GitHubClient.GetCurrentUser()
+	goOffline(err)
+	return login, err
+}
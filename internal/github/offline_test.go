@@ -0,0 +1,90 @@
+package github
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// unreachableClient fails every lookup like gh does without a network
+type unreachableClient struct {
+	GitHubClient
+	calls int
+}
+
+func (c *unreachableClient) GetPRsForBranches(branches []string) (map[string]*PRInfo, error) {
+	c.calls++
+	return nil, &NetworkError{Err: errors.New("error connecting to api.github.com")}
+}
+
+// reachableClient answers lookups with fixed PRs
+type reachableClient struct {
+	GitHubClient
+	prs map[string]*PRInfo
+}
+
+func (c *reachableClient) GetPRsForBranches(branches []string) (map[string]*PRInfo, error) {
+	return c.prs, nil
+}
+
+func TestOfflineClient(t *testing.T) {
+	CacheFile = filepath.Join(t.TempDir(), "prs.json")
+	defer func() {
+		CacheFile = ""
+		Offline = false
+		OnUnreachable = nil
+	}()
+
+	online := &offlineClient{&reachableClient{prs: map[string]*PRInfo{
+		"feature-a": {Number: 1, State: "OPEN", Base: "main", Head: "feature-a"},
+		"feature-b": {Number: 2, State: "MERGED", Base: "feature-a", Head: "feature-b"},
+	}}}
+	_, err := online.GetPRsForBranches([]string{"feature-a", "feature-b"})
+	assert.NoError(t, err)
+	assert.False(t, CacheUpdated().IsZero())
+
+	t.Run("answers from the cache once GitHub is unreachable", func(t *testing.T) {
+		var reason error
+		OnUnreachable = func(err error) { reason = err }
+		inner := &unreachableClient{}
+		client := &offlineClient{inner}
+
+		prs, err := client.GetPRsForBranches([]string{"feature-a", "feature-c"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, prs["feature-a"].Number)
+		assert.NotContains(t, prs, "feature-c")
+		assert.True(t, Offline)
+		assert.ErrorIs(t, reason, ErrOffline)
+
+		// Later lookups don't try GitHub again
+		_, _ = client.GetPRsForBranches([]string{"feature-a"})
+		assert.Equal(t, 1, inner.calls)
+
+		merged, err := client.IsPRMerged(2)
+		assert.NoError(t, err)
+		assert.True(t, merged)
+
+		pr, err := client.GetPRByNumber(1)
+		assert.NoError(t, err)
+		assert.Equal(t, "feature-a", pr.Head)
+	})
+
+	t.Run("fails calls that need GitHub", func(t *testing.T) {
+		Offline = true
+		client := &offlineClient{&unreachableClient{}}
+
+		assert.ErrorIs(t, client.UpdatePRBase(1, "main"), ErrOffline)
+		_, err := client.GetCurrentUser()
+		assert.ErrorIs(t, err, ErrOffline)
+	})
+}
+
+func TestGHErrorNetwork(t *testing.T) {
+	err := ghError("error connecting to api.github.com\ncheck your internet connection", errors.New("gh pr list failed"))
+	assert.ErrorIs(t, err, ErrOffline)
+
+	var auth *AuthError
+	assert.False(t, errors.As(err, &auth))
+}
@@ -0,0 +1,59 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ReadOnly makes NewGitHubClient return a client that refuses every call that
+// would change a PR (STACKINATOR_READONLY / --read-only)
+var ReadOnly = false
+
+// ErrReadOnly is returned by mutating calls in read-only mode
+var ErrReadOnly = errors.New("not allowed in read-only mode")
+
+// readOnlyClient wraps a GitHubClient, failing every mutating call
+type readOnlyClient struct {
+	GitHubClient
+}
+
+func readOnlyError(args ...string) error {
+	return fmt.Errorf("gh %s: %w", strings.Join(args, " "), ErrReadOnly)
+}
+
+func (c *readOnlyClient) UpdatePRBase(prNumber int, newBase string) error {
+	return readOnlyError("pr", "edit", fmt.Sprint(prNumber), "--base", newBase)
+}
+
+func (c *readOnlyClient) CreatePR(head, base string, draft bool, body string) (*PRInfo, error) {
+	return nil, readOnlyError("pr", "create", "--head", head, "--base", base)
+}
+
+func (c *readOnlyClient) CommentOnPR(prNumber int, body string) error {
+	return readOnlyError("pr", "comment", fmt.Sprint(prNumber))
+}
+
+func (c *readOnlyClient) UpdatePRBody(prNumber int, body string) error {
+	return readOnlyError("pr", "edit", fmt.Sprint(prNumber), "--body")
+}
+
+func (c *readOnlyClient) UpdatePRTitle(prNumber int, title string) error {
+	return readOnlyError("pr", "edit", fmt.Sprint(prNumber), "--title", title)
+}
+
+func (c *readOnlyClient) EditPRLabels(prNumber int, add, remove []string) error {
+	return readOnlyError("pr", "edit", fmt.Sprint(prNumber), "--add-label", strings.Join(add, ","), "--remove-label", strings.Join(remove, ","))
+}
+
+func (c *readOnlyClient) RequestReviewers(prNumber int, reviewers []string) error {
+	return readOnlyError("pr", "edit", fmt.Sprint(prNumber), "--add-reviewer", strings.Join(reviewers, ","))
+}
+
+func (c *readOnlyClient) MergePR(prNumber int, method string) error {
+	return readOnlyError("pr", "merge", fmt.Sprint(prNumber), "--"+method)
+}
+
+func (c *readOnlyClient) ClosePR(prNumber int, comment string) error {
+	return readOnlyError("pr", "close", fmt.Sprint(prNumber))
+}
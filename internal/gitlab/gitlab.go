@@ -0,0 +1,521 @@
+package gitlab
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/dryrun"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/script"
+)
+
+// Verbose controls whether to print executed commands
+var Verbose = false
+
+// DryRun controls whether to actually execute mutation commands
+var DryRun = false
+
+// WorkDir is the directory glab commands run in (empty means the current directory)
+var WorkDir = ""
+
+// gitlabClient implements the forge client for GitLab merge requests using the
+// glab CLI. Merge requests are reported as PRs: their IID is the PR number, the
+// source branch the head and the target branch the base.
+type gitlabClient struct {
+	host    string // e.g. gitlab.com
+	project string // Full path, e.g. group/subgroup/project
+}
+
+// NewGitLabClient creates a client for the project at host, with its full path
+// (e.g. "group/subgroup/project")
+func NewGitLabClient(host, project string) github.GitHubClient {
+	return github.Wrap(&gitlabClient{host: host, project: project})
+}
+
+// ParseProjectFromURL extracts the host and the full project path from a git
+// remote URL. Supports formats:
+//   - git@gitlab.com:group/project.git -> gitlab.com, group/project
+//   - ssh://git@gitlab.example.com:2222/group/sub/project.git -> gitlab.example.com, group/sub/project
+//   - https://gitlab.com/group/project -> gitlab.com, group/project
+func ParseProjectFromURL(remoteURL string) (host, project string) {
+	remoteURL = strings.TrimSuffix(strings.TrimSpace(remoteURL), ".git")
+
+	switch {
+	case strings.HasPrefix(remoteURL, "git@"):
+		parts := strings.SplitN(strings.TrimPrefix(remoteURL, "git@"), ":", 2)
+		if len(parts) == 2 {
+			host, project = parts[0], parts[1]
+		}
+	case strings.Contains(remoteURL, "://"):
+		u, err := url.Parse(remoteURL)
+		if err == nil {
+			host, project = u.Hostname(), strings.TrimPrefix(u.Path, "/")
+		}
+	}
+
+	if host == "" || project == "" {
+		return "", ""
+	}
+	return host, strings.Trim(project, "/")
+}
+
+// repoURL is the project's web URL, which glab's --repo accepts for any host
+func (c *gitlabClient) repoURL() string {
+	return "https://" + c.host + "/" + c.project
+}
+
+// endpoint is the REST path of the project, followed by path
+func (c *gitlabClient) endpoint(path string) string {
+	return "projects/" + url.PathEscape(c.project) + path
+}
+
+// api runs a GET request on the REST API and returns the response body
+func (c *gitlabClient) api(path string, paginate bool) (string, error) {
+	args := []string{"api", "--hostname", c.host}
+	if paginate {
+		args = append(args, "--paginate")
+	}
+	return execGlab(append(args, c.endpoint(path))...)
+}
+
+// runMR runs a glab mr subcommand against the project, or prints it in dry-run mode
+func (c *gitlabClient) runMR(summary string, args ...string) (string, error) {
+	args = append([]string{"mr"}, args...)
+	args = append(args, "--repo", c.repoURL())
+	if DryRun {
+		dryrun.Record(dryrun.PREdit)
+		if script.Enabled {
+			script.Record("glab", args...)
+		} else {
+			fmt.Printf("  [DRY RUN] glab mr %s\n", summary)
+		}
+		return "", nil
+	}
+	return execGlab(args...)
+}
+
+// networkFailures are what glab prints when it can't reach the host
+var networkFailures = []string{
+	"no such host",
+	"i/o timeout",
+	"network is unreachable",
+	"connection refused",
+	"TLS handshake timeout",
+}
+
+// execGlab runs glab with args and returns stdout
+func execGlab(args ...string) (string, error) {
+	if Verbose {
+		fmt.Printf("  [glab] %s\n", strings.Join(args, " "))
+	}
+	cmd := exec.Command("glab", args...)
+	cmd.Dir = WorkDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// glab missing from PATH leaves stderr empty
+		if stderr.Len() > 0 {
+			err = errors.New(strings.TrimSpace(stderr.String()))
+		}
+		err = fmt.Errorf("glab %s failed: %w", strings.Join(args, " "), err)
+		for _, failure := range networkFailures {
+			if strings.Contains(stderr.String(), failure) {
+				return "", &github.NetworkError{Err: err}
+			}
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// mergeRequest is a merge request as returned by the REST API
+type mergeRequest struct {
+	IID                 int       `json:"iid"`
+	State               string    `json:"state"`
+	SourceBranch        string    `json:"source_branch"`
+	TargetBranch        string    `json:"target_branch"`
+	Title               string    `json:"title"`
+	Description         string    `json:"description"`
+	WebURL              string    `json:"web_url"`
+	Draft               bool      `json:"draft"`
+	CreatedAt           time.Time `json:"created_at"`
+	SHA                 string    `json:"sha"`
+	DetailedMergeStatus string    `json:"detailed_merge_status"`
+	Labels              []string  `json:"labels"`
+	SourceProjectID     int       `json:"source_project_id"`
+	TargetProjectID     int       `json:"target_project_id"`
+	HeadPipeline        *struct {
+		Status string `json:"status"`
+	} `json:"head_pipeline"`
+}
+
+// states maps merge request states to PR states
+var states = map[string]string{
+	"opened": "OPEN",
+	"merged": "MERGED",
+	"closed": "CLOSED",
+	"locked": "CLOSED",
+}
+
+// mergeStatuses maps detailed merge statuses to GitHub's mergeStateStatus;
+// anything else keeps the MR from merging for now
+var mergeStatuses = map[string]string{
+	"mergeable":        "CLEAN",
+	"need_rebase":      "BEHIND",
+	"conflict":         "DIRTY",
+	"broken_status":    "DIRTY",
+	"checking":         "UNKNOWN",
+	"unchecked":        "UNKNOWN",
+	"ci_still_running": "UNSTABLE",
+	"ci_must_pass":     "UNSTABLE",
+}
+
+// pipelineChecks maps pipeline statuses to the combined state of a PR's checks
+var pipelineChecks = map[string]string{
+	"success":              "SUCCESS",
+	"failed":               "FAILURE",
+	"canceled":             "FAILURE",
+	"created":              "PENDING",
+	"waiting_for_resource": "PENDING",
+	"preparing":            "PENDING",
+	"pending":              "PENDING",
+	"running":              "PENDING",
+	"scheduled":            "PENDING",
+	"manual":               "PENDING",
+}
+
+func (mr *mergeRequest) prInfo() *github.PRInfo {
+	pr := &github.PRInfo{
+		Number:           mr.IID,
+		State:            states[mr.State],
+		Base:             mr.TargetBranch,
+		Title:            mr.Title,
+		URL:              mr.WebURL,
+		MergeStateStatus: "BLOCKED",
+		Head:             mr.SourceBranch,
+		IsDraft:          mr.Draft,
+		CreatedAt:        mr.CreatedAt,
+		HeadSHA:          mr.SHA,
+		Labels:           append([]string{}, mr.Labels...),
+	}
+	if status, ok := mergeStatuses[mr.DetailedMergeStatus]; ok {
+		pr.MergeStateStatus = status
+	}
+	if mr.HeadPipeline != nil {
+		pr.Checks = pipelineChecks[mr.HeadPipeline.Status]
+	}
+	return pr
+}
+
+// parseMergeRequests parses one or more JSON arrays of merge requests, as printed
+// by glab api --paginate (one array per page)
+func parseMergeRequests(output string) ([]mergeRequest, error) {
+	var mrs []mergeRequest
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for {
+		var page []mergeRequest
+		err := decoder.Decode(&page)
+		if errors.Is(err, io.EOF) {
+			return mrs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse merge requests: %w", err)
+		}
+		mrs = append(mrs, page...)
+	}
+}
+
+// byBranch keys merge requests by source branch. MRs from forks are ignored.
+// MRs are listed newest first; if a branch has several, the open one wins.
+func byBranch(mrs []mergeRequest) map[string]*github.PRInfo {
+	prMap := make(map[string]*github.PRInfo)
+	for i := range mrs {
+		mr := &mrs[i]
+		if mr.SourceProjectID != mr.TargetProjectID {
+			continue
+		}
+		if existing, ok := prMap[mr.SourceBranch]; ok && (existing.State == "OPEN" || mr.State != "opened") {
+			continue
+		}
+		prMap[mr.SourceBranch] = mr.prInfo()
+	}
+	return prMap
+}
+
+// listMRs lists the project's merge requests matching query
+func (c *gitlabClient) listMRs(query url.Values) ([]mergeRequest, error) {
+	query.Set("per_page", "100")
+	output, err := c.api("/merge_requests?"+query.Encode(), true)
+	if err != nil {
+		return nil, err
+	}
+	return parseMergeRequests(output)
+}
+
+// getMR returns a merge request by IID
+func (c *gitlabClient) getMR(iid int) (*mergeRequest, error) {
+	output, err := c.api("/merge_requests/"+strconv.Itoa(iid), false)
+	if err != nil {
+		return nil, err
+	}
+	var mr mergeRequest
+	if err := json.Unmarshal([]byte(output), &mr); err != nil {
+		return nil, fmt.Errorf("failed to parse merge request: %w", err)
+	}
+	return &mr, nil
+}
+
+// authorQuery filters by author; "@me" is the authenticated user, as with gh
+func authorQuery(author string) url.Values {
+	if author == "@me" {
+		return url.Values{"scope": {"created_by_me"}}
+	}
+	return url.Values{"author_username": {author}}
+}
+
+// GetPRForBranch returns the merge request from branch, or nil if there is none
+func (c *gitlabClient) GetPRForBranch(branch string) (*github.PRInfo, error) {
+	mrs, err := c.listMRs(url.Values{"source_branch": {branch}, "state": {"all"}})
+	if err != nil {
+		if errors.Is(err, github.ErrOffline) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return byBranch(mrs)[branch], nil
+}
+
+// GetPRByNumber returns the merge request with IID number, or nil if there is none
+func (c *gitlabClient) GetPRByNumber(number int) (*github.PRInfo, error) {
+	mr, err := c.getMR(number)
+	if err != nil {
+		if errors.Is(err, github.ErrOffline) {
+			return nil, err
+		}
+		return nil, nil
+	}
+	return mr.prInfo(), nil
+}
+
+// GetAllPRs fetches all open merge requests of the project
+func (c *gitlabClient) GetAllPRs() (map[string]*github.PRInfo, error) {
+	mrs, err := c.listMRs(url.Values{"state": {"opened"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if Verbose {
+		fmt.Printf("  [glab] Fetched %d merge requests\n", len(mrs))
+	}
+	return byBranch(mrs), nil
+}
+
+// GetPRsForBranches fetches the merge requests (in any state) from branches. The
+// REST API filters on one source branch at a time, so this is a call per branch.
+func (c *gitlabClient) GetPRsForBranches(branches []string) (map[string]*github.PRInfo, error) {
+	prMap := make(map[string]*github.PRInfo)
+	for _, branch := range branches {
+		mrs, err := c.listMRs(url.Values{"source_branch": {branch}, "state": {"all"}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list merge requests: %w", err)
+		}
+		if pr := byBranch(mrs)[branch]; pr != nil {
+			prMap[branch] = pr
+		}
+	}
+	return prMap, nil
+}
+
+// GetPRsByAuthor fetches the merge requests (in any state) opened by author,
+// keyed by source branch
+func (c *gitlabClient) GetPRsByAuthor(author string) (map[string]*github.PRInfo, error) {
+	query := authorQuery(author)
+	query.Set("state", "all")
+	mrs, err := c.listMRs(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	return byBranch(mrs), nil
+}
+
+// ListAuthoredPRs fetches the open merge requests by author. Lists don't include
+// pipelines, so Checks is left empty.
+func (c *gitlabClient) ListAuthoredPRs(author string) ([]*github.PRInfo, error) {
+	query := authorQuery(author)
+	query.Set("state", "opened")
+	mrs, err := c.listMRs(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	prs := make([]*github.PRInfo, 0, len(mrs))
+	for i := range mrs {
+		prs = append(prs, mrs[i].prInfo())
+	}
+	return prs, nil
+}
+
+// GetPRChecks returns the merge request's head commit and the state of its pipeline
+func (c *gitlabClient) GetPRChecks(prNumber int) (*github.PRInfo, error) {
+	mr, err := c.getMR(prNumber)
+	if err != nil {
+		return nil, err
+	}
+	return mr.prInfo(), nil
+}
+
+// UpdatePRBase changes the target branch of a merge request
+func (c *gitlabClient) UpdatePRBase(prNumber int, newBase string) error {
+	args := []string{"update", strconv.Itoa(prNumber), "--target-branch", newBase}
+	_, err := c.runMR(strings.Join(args, " "), args...)
+	return err
+}
+
+// CreatePR opens a merge request from head into base, with the title and
+// description filled in from the branch's commits. A non-empty body replaces the
+// description. Returns nil in dry-run mode.
+func (c *gitlabClient) CreatePR(head, base string, draft bool, body string) (*github.PRInfo, error) {
+	args := []string{"create", "--source-branch", head, "--target-branch", base, "--fill", "--yes"}
+	if body != "" {
+		args = append(args, "--description", body)
+	}
+	if draft {
+		args = append(args, "--draft")
+	}
+	output, err := c.runMR(fmt.Sprintf("create --source-branch %s --target-branch %s", head, base), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+	if DryRun {
+		return nil, nil
+	}
+
+	// glab prints the URL of the new merge request as its last line
+	lines := strings.Split(output, "\n")
+	mrURL := strings.TrimSpace(lines[len(lines)-1])
+	number, err := strconv.Atoi(mrURL[strings.LastIndex(mrURL, "/")+1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse merge request number from %q", mrURL)
+	}
+
+	return &github.PRInfo{
+		Number: number,
+		State:  "OPEN",
+		Base:   base,
+		Head:   head,
+		URL:    mrURL,
+	}, nil
+}
+
+// CommentOnPR adds a comment to a merge request
+func (c *gitlabClient) CommentOnPR(prNumber int, body string) error {
+	_, err := c.runMR(fmt.Sprintf("note %d", prNumber), "note", strconv.Itoa(prNumber), "--message", body)
+	return err
+}
+
+// GetPRBody returns the description of a merge request
+func (c *gitlabClient) GetPRBody(prNumber int) (string, error) {
+	mr, err := c.getMR(prNumber)
+	if err != nil {
+		return "", err
+	}
+	return mr.Description, nil
+}
+
+// UpdatePRBody replaces the description of a merge request
+func (c *gitlabClient) UpdatePRBody(prNumber int, body string) error {
+	_, err := c.runMR(fmt.Sprintf("update %d --description ...", prNumber), "update", strconv.Itoa(prNumber), "--description", body)
+	return err
+}
+
+// UpdatePRTitle replaces the title of a merge request
+func (c *gitlabClient) UpdatePRTitle(prNumber int, title string) error {
+	_, err := c.runMR(fmt.Sprintf("update %d --title %q", prNumber, title), "update", strconv.Itoa(prNumber), "--title", title)
+	return err
+}
+
+// EditPRLabels adds and removes labels on a merge request. GitLab creates labels
+// that don't exist yet, and ignores ones that are already there (or gone).
+func (c *gitlabClient) EditPRLabels(prNumber int, add, remove []string) error {
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	args := []string{"update", strconv.Itoa(prNumber)}
+	if len(add) > 0 {
+		args = append(args, "--label", strings.Join(add, ","))
+	}
+	if len(remove) > 0 {
+		args = append(args, "--unlabel", strings.Join(remove, ","))
+	}
+	_, err := c.runMR(strings.Join(args, " "), args...)
+	return err
+}
+
+// RequestReviewers adds reviewers to a merge request, keeping the existing ones
+func (c *gitlabClient) RequestReviewers(prNumber int, reviewers []string) error {
+	added := make([]string, len(reviewers))
+	for i, reviewer := range reviewers {
+		added[i] = "+" + reviewer
+	}
+	args := []string{"update", strconv.Itoa(prNumber), "--reviewer", strings.Join(added, ",")}
+	_, err := c.runMR(strings.Join(args, " "), args...)
+	return err
+}
+
+// GetCurrentUser returns the username glab is authenticated as
+func (c *gitlabClient) GetCurrentUser() (string, error) {
+	output, err := execGlab("api", "--hostname", c.host, "user")
+	if err != nil {
+		return "", err
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal([]byte(output), &user); err != nil {
+		return "", fmt.Errorf("failed to parse user: %w", err)
+	}
+	return user.Username, nil
+}
+
+// MergePR merges a merge request with method ("squash", "merge" or "rebase")
+func (c *gitlabClient) MergePR(prNumber int, method string) error {
+	args := []string{"merge", strconv.Itoa(prNumber), "--yes"}
+	switch method {
+	case "squash":
+		args = append(args, "--squash")
+	case "rebase":
+		args = append(args, "--rebase")
+	}
+	_, err := c.runMR(strings.Join(args, " "), args...)
+	return err
+}
+
+// ClosePR closes a merge request without merging it. glab can't comment while
+// closing, so the comment is left as a note first.
+func (c *gitlabClient) ClosePR(prNumber int, comment string) error {
+	if comment != "" {
+		if err := c.CommentOnPR(prNumber, comment); err != nil {
+			return err
+		}
+	}
+	_, err := c.runMR(fmt.Sprintf("close %d", prNumber), "close", strconv.Itoa(prNumber))
+	return err
+}
+
+// IsPRMerged checks if a merge request has been merged
+func (c *gitlabClient) IsPRMerged(prNumber int) (bool, error) {
+	mr, err := c.getMR(prNumber)
+	if err != nil {
+		return false, err
+	}
+	return mr.State == "merged", nil
+}
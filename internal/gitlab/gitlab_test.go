@@ -0,0 +1,79 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProjectFromURL(t *testing.T) {
+	tests := []struct {
+		remoteURL string
+		host      string
+		project   string
+	}{
+		{"git@gitlab.com:group/project.git", "gitlab.com", "group/project"},
+		{"git@gitlab.com:group/sub/project", "gitlab.com", "group/sub/project"},
+		{"https://gitlab.com/group/project.git", "gitlab.com", "group/project"},
+		{"ssh://git@gitlab.example.com:2222/group/sub/project.git", "gitlab.example.com", "group/sub/project"},
+		{"https://gitlab.example.com/group/project/", "gitlab.example.com", "group/project"},
+		{"", "", ""},
+		{"not a url", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.remoteURL, func(t *testing.T) {
+			host, project := ParseProjectFromURL(tt.remoteURL)
+			assert.Equal(t, tt.host, host)
+			assert.Equal(t, tt.project, project)
+		})
+	}
+}
+
+func TestParseMergeRequests(t *testing.T) {
+	// glab api --paginate prints one array per page
+	output := `[{"iid": 3, "state": "opened", "source_branch": "feature-a", "target_branch": "main", "title": "Add A",
+  "web_url": "https://gitlab.com/group/project/-/merge_requests/3", "detailed_merge_status": "need_rebase",
+  "source_project_id": 1, "target_project_id": 1}]
+[{"iid": 2, "state": "merged", "source_branch": "feature-b", "target_branch": "feature-a", "title": "Add B",
+  "detailed_merge_status": "not_open", "source_project_id": 1, "target_project_id": 1,
+  "head_pipeline": {"status": "success"}}]`
+
+	mrs, err := parseMergeRequests(output)
+	require.NoError(t, err)
+	require.Len(t, mrs, 2)
+
+	a := mrs[0].prInfo()
+	assert.Equal(t, 3, a.Number)
+	assert.Equal(t, "OPEN", a.State)
+	assert.Equal(t, "main", a.Base)
+	assert.Equal(t, "feature-a", a.Head)
+	assert.Equal(t, "BEHIND", a.MergeStateStatus)
+	assert.Equal(t, "", a.Checks)
+
+	b := mrs[1].prInfo()
+	assert.Equal(t, "MERGED", b.State)
+	assert.Equal(t, "BLOCKED", b.MergeStateStatus)
+	assert.Equal(t, "SUCCESS", b.Checks)
+
+	_, err = parseMergeRequests("not json")
+	assert.Error(t, err)
+}
+
+func TestByBranch(t *testing.T) {
+	mrs := []mergeRequest{
+		{IID: 5, State: "closed", SourceBranch: "feature", SourceProjectID: 1, TargetProjectID: 1},
+		{IID: 4, State: "opened", SourceBranch: "feature", SourceProjectID: 1, TargetProjectID: 1},
+		{IID: 3, State: "merged", SourceBranch: "feature", SourceProjectID: 1, TargetProjectID: 1},
+		{IID: 2, State: "opened", SourceBranch: "fork", SourceProjectID: 9, TargetProjectID: 1},
+		{IID: 1, State: "merged", SourceBranch: "old", SourceProjectID: 1, TargetProjectID: 1},
+	}
+
+	prs := byBranch(mrs)
+
+	assert.Len(t, prs, 2)
+	assert.Equal(t, 4, prs["feature"].Number, "the open MR wins over newer closed ones")
+	assert.Equal(t, 1, prs["old"].Number)
+	assert.NotContains(t, prs, "fork")
+}
@@ -0,0 +1,1155 @@
+package i18n
+
+// The long help of each command is kept apart from the short messages, and
+// merged into their catalogs when the package loads
+func init() {
+	for key, msg := range helpEN {
+		messagesEN[key] = msg
+	}
+	for key, msg := range helpES {
+		messagesES[key] = msg
+	}
+}
+
+// helpEN holds the long help text of each command, merged into messagesEN
+var helpEN = map[string]string{
+	"root.long": `A CLI tool for managing stacks of branches and syncing them to GitHub Pull Requests.
+
+Stack branches are tracked using git config, where each branch stores its parent.
+The tool helps you create, navigate, and sync stacked branches with minimal overhead.`,
+
+	"new.long": `Create a new branch in the stack, optionally specifying a parent branch.
+
+The new branch will be created from the specified parent (or current branch if not specified),
+and the parent relationship will be stored in git config (branch.<name>.stackparent).
+
+If no parent is specified and you're not on a stack branch, the base branch (default: main)
+will be used as the parent.
+
+With --umbrella, the branch is created as an umbrella: a branch without commits
+of its own that only groups sub-stacks. Sync keeps it on top of its parent but
+never pushes it or opens a PR for it, and the PRs of its children target the
+branch below it.`,
+
+	"status.long": `Display the stack structure as a tree, showing:
+  - Branch hierarchy (parent → child relationships)
+  - Current branch (highlighted with *)
+  - PR status for each branch (if available)
+
+This helps you visualize your stack and see which branches have PRs.
+
+To keep large stacks scannable, runs and sub-trees of merged branches fold into
+a single line, as does everything below --depth. --expand shows it all.
+
+--json prints the whole tree instead, unfolded: every branch with its parent,
+PR, and commits ahead of and behind its parent, along with the sync issues.`,
+
+	"show.long": `Display the local stack structure as a tree without fetching remote PR info.
+
+This is a fast version of 'stack status' that only reads local git config.
+Use 'stack status' to see PR information and sync issues.
+
+--json prints the tree as JSON instead: every branch with its parent, and
+commits ahead of and behind its parent.`,
+
+	"sync.long": `Perform a full sync of the stack:
+  1. Fetch latest changes from origin
+  2. Rebase each stack branch onto its parent (in bottom-to-top order)
+  3. Force push each branch to origin
+  4. Update PR base branches to match the stack (if PRs exist)
+
+This ensures your stack is up-to-date and all PRs have the correct base branches.
+
+If a parent PR has been merged, the child branches will be rebased to point to
+the merged parent's parent.
+
+Branches other than the current one are rebased in a hidden worktree inside the
+git dir, so this worktree isn't switched from branch to branch (set
+stack.sync.worktree to false to rebase them here).
+
+Uncommitted changes are automatically stashed and reapplied (using --autostash).`,
+
+	"prune.long": `Remove branches with merged PRs from stack tracking and delete them locally.
+
+By default, this command only checks branches in the stack (those created with 'stack new').
+Use --all to check all local branches.
+
+This command will:
+  1. Find all branches with merged PRs
+  2. Remove them from stack tracking (if applicable)
+  3. Delete the local branches with 'git branch -d'
+
+If a branch has unmerged commits locally, use --force to delete it anyway.
+
+With --detect-merged-by-commit, a branch whose PR was closed also counts as
+merged when its tip is already on origin/<base>, as after a fast-forward push.`,
+
+	"parent.long": `Display the parent branch of the current branch in the stack.
+
+If the current branch has no parent set, it will show that the branch
+is not part of a stack.`,
+
+	"rename.long": `Rename the current branch to a new name while preserving all stack relationships.
+
+This command will:
+  - Rename the git branch
+  - Update the branch's parent reference in git config
+  - Update all child branches to point to the new name
+
+The command must be run while on the branch you want to rename.`,
+
+	"reparent.long": `Change or set the parent branch of the current branch.
+
+This command updates the stack parent relationship in git config and, if a PR
+exists for the current branch, automatically updates the PR base to match the
+new parent.
+
+This is useful for:
+- Adding an existing branch to a stack (when no parent is currently set)
+- Reorganizing your stack when you want to change which branch a feature is based on`,
+
+	"worktree.long": `Create a git worktree in the .worktrees/ directory for the specified branch.
+
+If the branch exists locally or on the remote, it will be used.
+If the branch doesn't exist, a new branch will be created from the current branch
+(or from base-branch if specified) and stack tracking will be set up automatically.
+Use --prune to clean up worktrees for branches with merged PRs.`,
+
+	"up.long": `Checkout the parent branch of the current branch in the stack.
+
+If the current branch has no parent (is at the root of the stack),
+an error message will be displayed.`,
+
+	"down.long": `Checkout a child branch of the current branch in the stack.
+
+If the current branch has no children (is at the tip of the stack),
+an error message will be displayed.
+
+If there are multiple children, you will be prompted to select one.`,
+
+	"go.long": `Jump to the n-th branch of the current stack, counting from 1 at the bottom.
+
+Without n, the branches from the bottom of the stack through the current branch
+and up to its tip are listed with their numbers, and you will be prompted to
+select one. Where the stack branches out above the current branch, the list
+stops at the fork; use 'stack down' to pick a side.
+
+A faster alternative to repeated 'stack up' and 'stack down' in a deep stack,
+and short enough to bind to an alias.`,
+
+	"version.long": `Print the version, commit hash, and build date of this stack binary.`,
+
+	"prs.long": `List all of your open PRs grouped by stack.
+
+PRs are grouped by following each PR's base branch to the PR it is stacked on,
+so this works across machines and without local stack tracking. Each PR shows:
+  - State (open or draft)
+  - CI result (passing, failing, pending)
+  - Review decision
+  - Age
+
+All PRs are fetched in a single call.`,
+
+	"import.long": `Import stacks from open PRs into local stack tracking.
+
+Stacks are discovered by following each PR's base branch to the PR it is
+stacked on. For every PR, the head branch is fetched from origin, a local
+branch is created if needed, and its stack parent is set to the PR's base.
+
+This is useful to pick up your own stacks on another machine, or to review a
+teammate's stack as a whole with --author. Use --worktrees to check each branch
+out into its own worktree under .worktrees/.`,
+
+	"adopt.long": `Start tracking stacks that were built without stackinator.
+
+With --from-prs, the parent of each branch is inferred from your open PRs: a PR
+whose base is the head of another PR is stacked on it, and a PR whose base has
+no PR of its own starts a stack on that branch. The resulting stacks are
+printed, marking the branches whose parent would change, and nothing is
+written until you confirm (or pass --yes).
+
+Once confirmed, every branch is tracked as with 'stack import': it is fetched
+from origin, created locally if needed, and its stack parent is set to its PR's
+base. Branches that are already tracked with the same parent are left alone.`,
+
+	"review.long": `Check out a PR's head in a review worktree under .worktrees/review/.
+
+The worktree has a detached HEAD at the PR's head commit, so reviewing never
+touches your own branches. The PR's commits are printed as a range-diff
+against its base.
+
+Running review again for the same PR moves the worktree to the new head and
+prints a range-diff between the previously reviewed commits and the new ones,
+which shows exactly what changed since your last review, even after a rebase.`,
+
+	"rangediff.long": `Compare a branch with origin/<branch> using git range-diff, and summarize which
+commits changed, were added, or were dropped since the PR was last pushed.
+
+Commits are compared by patch, so a branch that was only rebased onto a new
+parent shows no content changes. Defaults to the current branch.
+
+Use --comment to post the summary and range-diff on the branch's PR, so
+reviewers can see what changed since they last looked.`,
+
+	"base.long": `Show the base branch that stacks are built on, and where it comes from:
+either stack.baseBranch in git config, or detected from origin/HEAD.
+
+Use 'stack base set <branch>' to change it, or --detect to go back to the
+detected branch. When the base changes, stacks rooted on the old base are
+moved onto the new one (run 'stack sync' afterwards to rebase them).`,
+
+	"cleanConfig.long": `Remove stack settings (branch.<name>.stackparent, branch.<name>.stackpr, ...)
+left behind by branches that no longer exist locally, for example after
+deleting a branch with 'git branch -D'.
+
+Such entries are already ignored when building stacks; this removes them from
+git config for good.`,
+
+	"reviewers.long": `Suggest reviewers for each branch in the current stack from CODEOWNERS.
+
+Each branch is matched against CODEOWNERS using only the files it changes
+relative to its parent, so every layer gets the owners of its own changes
+instead of the whole stack pinging the same people. CODEOWNERS is read from
+the base branch on origin, as GitHub does.
+
+Use --request to request the suggested reviewers on each branch's open PR.`,
+
+	"blame.long": `Show the stack as a tree with, for each branch, the directories it touches and
+how many lines it changes relative to its parent.
+
+This gives reviewers quick context on each layer, and helps decide where a
+large branch could be split.`,
+
+	"rebase.long": `Edit the order of the whole stack in your editor, like the todo list of
+'git rebase -i' but with a line per branch instead of per commit.
+
+Each line is an action and a branch, bottom of the stack first:
+
+  pick <branch>   keep the branch (lines can be moved to reorder branches)
+  fold <branch>   fold the branch's commits into the branch on the line above
+  drop <branch>   take the branch and its commits out of the stack
+
+Removing a line drops the branch. Dropped and folded branches are kept locally,
+but are no longer tracked as part of the stack.
+
+Once the editor is closed, each branch is rebased with --onto so it only takes
+its own commits along, the stack parents are updated and open PRs are retargeted.
+If a rebase stops on a conflict, resolve it, run 'git rebase --continue' and
+then 'stack rebase --continue'. 'stack rebase --abort' puts every branch back
+where it was.
+
+Only linear stacks are supported. Push the result with 'stack sync'.`,
+
+	"fixup.long": `Commit the staged changes as a 'fixup!' of the tip of a branch lower in the
+current stack (or of --commit), without checking that branch out.
+
+With --restack, the fixup is squashed into its target right away with an
+autosquash rebase of the current branch. Branches between the target and the
+current branch are moved along (git rebase --update-refs, git 2.38 or newer).
+Run 'stack sync' afterwards to restack the branches above and push.`,
+
+	"absorb.long": `Commit each staged hunk as a 'fixup!' of the stack commit that last changed
+its lines, like git absorb, then squash the fixups in and restack the branches
+above the current one.
+
+A hunk is absorbed when every line it changes or removes was last changed by
+the same commit of the current branch or a branch below it. Hunks that only add
+lines, or change lines of several commits or of commits outside the stack, are
+left uncommitted.
+
+The fixups are squashed in with an autosquash rebase of the current branch,
+which moves the branches below it along (git rebase --update-refs, git 2.38 or
+newer). The branches stacked on the current one are then rebased onto it like
+'stack rebase --interactive-plan' does, so conflicts are handled with 'stack
+rebase --continue' or 'stack rebase --abort'. With --no-squash only the fixup
+commits are made.
+
+Push the branches with 'stack sync'.`,
+
+	"amend.long": `Amend the last commit of the current branch with the staged changes, then
+rebase every branch stacked above it onto the new commit, so the stack stays
+consistent without a full 'stack sync'.
+
+When the branch has no commits of its own yet, a new commit is made instead of
+amending its parent's. -m replaces the commit message (an amend keeps it
+otherwise) and --all stages every change to tracked files first, like 'git
+commit --all'.
+
+The branches above are rebased locally like 'stack rebase --interactive-plan'
+does, so conflicts are handled with 'stack rebase --continue' or 'stack rebase
+--abort'. Nothing is fetched or pushed unless --push is given, which pushes the
+branch and the branches above it that are on origin, using the same strategy as
+'stack sync' (stack.pushStrategy).`,
+
+	"pick.long": `Cherry-pick a commit, or every commit of a branch, onto the current branch.
+
+For a branch, its own commits are picked: those since its stack parent, or
+since it forked from the base branch. Each picked commit records the commit it
+came from in a "(cherry picked from commit <sha>)" trailer.
+
+Once the original commit lands on the base branch, 'stack sync' notices that
+the pick is redundant (by comparing patches) and offers to drop it.`,
+
+	"check.long": `Check that the stack is consistent, using only local data so it runs in well
+under a second:
+  - stack parents form a tree (no cycles) and every parent branch exists
+  - no sync, 'stack rebase -i' or git operation was left half-way
+  - each PR targets the branch's parent, as last seen by 'stack sync'
+
+Problems that break sync are errors and make the command exit non-zero; the
+rest are warnings. --json prints the findings for CI and other tools.
+
+With --pre-push, check the refs git is about to push instead, as read from
+stdin in the format of the pre-push hook (see 'stack hook install'). The push
+is refused when it would:
+  - push a stack branch onto the base branch
+  - overwrite a remote branch that has newer commits than the local one, or
+    commits that haven't been fetched yet
+
+A PR whose base doesn't match the branch's parent in the stack is reported
+as a warning, as 'stack sync' retargets it.
+
+With --pre-commit, warn when committing directly on the base branch while
+stacks exist (see 'stack hook install --pre-commit').`,
+
+	"hook.long": `Manage the git hooks that check the stack. The pre-push hook runs
+'stack check --pre-push', so a plain 'git push' can't silently break the
+stack: the push is refused when it would push a stack branch onto the base
+branch, or overwrite a newer remote branch.
+
+With --pre-commit, a pre-commit hook is installed as well. It warns when you
+commit directly on the base branch while stacks exist, as those commits
+belong in a stack branch.`,
+
+	"uplift.long": `Move commits made by mistake on the base branch into a new stack branch.
+
+The local commits on the base branch that aren't on origin are kept on a new
+branch (with the base branch as its stack parent), and the base branch is
+reset to origin. When the base branch is checked out, the new branch is
+checked out instead, keeping any uncommitted changes.`,
+
+	"name.long": `Show or set the name of the current stack, so it can be found with 'stack list'
+and checked out with 'stack switch <name>'.
+
+The name is stored on the stack's root branch (branch.<root>.stackname), the
+branch directly on top of the base branch.`,
+
+	"list.long": `List all stacks with their name, root branch and number of branches. The
+current stack is marked.`,
+
+	"switch.long": `Check out the tip of a stack, given its name or its root branch.
+
+If the stack branches out into several tips, you will be prompted to select one.`,
+
+	"checkout.long": `Check out a branch of the current stack without typing its full name.
+
+The name is matched loosely, ignoring case: an exact name wins, then names
+starting with it, then names containing it, then names containing its letters
+in order (e.g. "pay-api" for payments-api). If several branches match equally
+well, you will be prompted to select one.
+
+Only the branches of the current stack are considered, or of every stack with
+--all. Outside a stack, every stack's branches are.`,
+
+	"describe.long": `Edit the description of a branch (the current branch by default) in your editor.
+
+This is the same description as 'git branch --edit-description', kept in git
+with the branch. When 'stack sync --create-prs' opens a PR for the branch, the
+description is used as the PR body instead of the commit messages.`,
+
+	"reorder.long": `Swap a branch (the current branch by default) with its parent, so it can land
+first when review priorities change.
+
+The branch is rebased onto its grandparent with only its own commits, the parent
+is rebased onto it, and the branches stacked on the branch are moved onto the
+parent. Stack parents are updated and open PRs are retargeted.
+
+This is 'stack rebase --interactive-plan' with two lines swapped, so conflicts
+are handled the same way: resolve them, run 'git rebase --continue' and then
+'stack rebase --continue', or put every branch back with 'stack rebase --abort'.
+
+Only linear stacks are supported. Push the result with 'stack sync'.`,
+
+	"split.long": `Break the current branch into stacked branches, one per group of commits.
+
+The branch's own commits are listed oldest first, and you pick the commits
+after which to split and a name for each new branch. Each new branch ends at
+its last commit and is stacked on the one before it; the current branch keeps
+the commits after the last split and is stacked on the last new branch.
+
+No commit is rewritten: the new branches point at commits the branch already
+has, so only branches and stack parents change. Branches stacked on the current
+branch stay on it. Push the new branches and open their PRs with 'stack sync'
+or 'stack submit'.
+
+With --at, the split is given on the command line instead of interactively.
+Since branch names can't contain ':', "<commit>:<branch>" is unambiguous.`,
+
+	"fold.long": `Collapse the current branch into its parent, e.g. when two layers of the
+stack turn out to be one change.
+
+The branch is rebased onto its parent if needed and the parent is moved to its
+tip, so the parent gets the branch's commits. The branches stacked on the
+branch are moved onto the parent, the branch is deleted and its open PR is
+closed with a comment pointing at the parent. You end up on the parent.
+
+This is 'stack rebase --interactive-plan' with the branch's line turned into a
+fold, so conflicts are handled the same way: resolve them, run 'git rebase
+--continue' and then 'stack rebase --continue', or put every branch back with
+'stack rebase --abort'.
+
+Only linear stacks are supported. Push the parent with 'stack sync'.`,
+
+	"formatPatch.long": `Export the stack up to the current branch as patch series, one directory per
+branch holding the commits the branch adds on top of its parent (git format-patch).
+
+A stack-series file next to the directories records the branches and their
+parents, so 'stack am' can rebuild the stack from it. The patches can be sent to
+a mailing list as they are, or copied to another machine.`,
+
+	"am.long": `Rebuild a stack from a series written by 'stack format-patch'.
+
+Each branch in the series is created on top of its parent and its patches are
+applied with 'git am --3way'. The bottom branch starts from the parent it had
+when it was exported if that branch exists here, otherwise from the base branch
+(or --onto). Stack parents are set as the branches are created.
+
+If a patch doesn't apply, resolve it and run 'git am --continue' (or
+'git am --abort'), then run 'stack am' again: branches that already exist are
+skipped, so it picks up where it stopped.`,
+
+	"env.long": `Show the repository, GitHub host and account stack uses here.
+
+With several GitHub accounts on one machine (e.g. work and personal), pick the
+one for a repo with stack.ghUser, and the host with stack.ghHost when origin
+goes through an SSH host alias:
+
+  git config stack.ghHost github.com
+  git config stack.ghUser my-work-login
+
+The account must be logged in to gh ('gh auth login'); stack uses its token
+without switching gh's active account. GH_HOST is honored when stack.ghHost
+isn't set.`,
+
+	"submit.long": `Open a PR for every branch of the stack that doesn't have one.
+
+The stack is walked bottom to top, from the base branch up to the current
+branch. Each branch that isn't on origin yet is pushed, and a branch that is
+ahead of origin is pushed too. Branches with an open PR are left alone; the
+others get a PR against their parent (or the branch below an umbrella parent),
+with the title and body filled in from their commits. The branch description
+(see 'stack describe') becomes the body when it is set.
+
+Branches that differ from origin in other ways, e.g. after a rebase, are not
+force-pushed; run 'stack sync' for that.`,
+
+	"land.long": `Merge the PR of the bottom branch of the stack, then restack the rest.
+
+The bottom branch is the one right above the base branch in the stack of the
+current branch. Its PR has to be open and target the base branch, and the
+branch has to match origin so that what is merged is what you have locally.
+
+The PR is merged with 'gh pr merge', squashing by default. When the base branch
+uses a merge queue, the PR is added to the queue instead. Either way, land
+waits until GitHub reports the PR as merged, then runs 'stack sync': the
+branches stacked on the landed one are moved onto the base branch, rebased and
+force-pushed, and their PRs retargeted.
+
+When the landed branch is the one checked out and a single branch is stacked
+on it, that branch is checked out so the sync covers it.`,
+
+	"share.long": `Publish the stack parents of your branches on origin, so teammates who check
+them out can use stack commands on them.
+
+Stack parents only live in your local git config. Share writes those of every
+stack branch that is on origin to the %s ref and pushes it.
+The ref is shared by everyone working on the repository: branches shared by
+others are kept, yours replace what was shared for them before, and branches
+that are no longer on origin are dropped.
+
+On another clone, 'stack fetch-metadata' reads it back.`,
+
+	"fetchMetadata.long": `Track branches with the stack parents shared on origin by 'stack share'.
+
+Every shared branch that exists locally and has no stack parent yet gets the
+shared one. Branches that already have a parent keep it, and are listed when it
+differs from the shared one; use 'stack reparent' to change them. Shared
+branches that aren't checked out here are skipped.`,
+
+	"conflicts.long": `Find the branches of the stack that will conflict with the base branch when
+they are merged, and the layer each conflict starts at.
+
+GitHub only tells whether a PR conflicts with its own base, which for most of
+a stack is the parent branch. This command merges each branch, from the base
+branch up to the current one, into origin's base branch in memory (nothing is
+checked out or written) and lists the conflicting files. A file that conflicts
+for a branch but not for its parent starts conflicting at that branch, so that
+is where to resolve it; branches above only inherit it.
+
+PRs that GitHub reports as conflicting with their base are flagged too.
+Exits with status 1 when any branch conflicts. Needs git 2.38 or later.`,
+
+	"ui.long": `Browse every stack in a full-screen view and act on it without typing commands.
+
+Move with the arrow keys (or j/k) and press Enter to check out the selected
+branch. m opens the actions: sync the stack, reparent the current branch onto
+a branch picked from the tree, or prune merged branches. Actions run as the
+usual 'stack sync', 'stack reparent' and 'stack prune', with their output and
+prompts, and the view returns once they finish. q quits.`,
+
+	"recover.long": `Finish or roll back a rename or reparent that stopped part way.
+
+'stack rename' and 'stack reparent' take several steps (renaming the branch,
+moving its parent, pointing its children at the new name, retargeting its PR).
+Each records what it's about to do before starting, and clears the record once
+done. If one fails in between, the stack is left half changed; 'stack recover'
+reads the record, redoes the steps that haven't happened yet, and clears it.
+Steps that already happened are left alone, so it's safe to run more than once.
+
+With --rollback, the steps that happened are undone instead, putting the stack
+back as it was before the operation.`,
+
+	"restack.long": `Rebase a single branch (the current branch by default) onto its parent and
+force-push it, without syncing the rest of the stack.
+
+Use it after changing a branch in the middle of the stack when only the branch
+above needs to follow, instead of running 'stack sync' over the whole chain.
+With --descendants the branch's children are restacked onto it too; branches
+further up are left alone.
+
+Only the branch's own commits are replayed onto the local parent; nothing is
+fetched and PRs aren't touched. Branches without a remote branch aren't pushed.
+Pushes use the same strategy as 'stack sync' (stack.pushStrategy).
+
+If a rebase stops on a conflict, resolve it, run 'git rebase --continue' and run
+'stack restack' again to push.`,
+
+	"sizeGuard.long": `Check the size of each branch in the current stack against the review limits.
+
+Each branch is measured by what it changes on top of its parent: the files it
+touches and the lines it adds and deletes. Branches over the limits set with
+stack.sizeGuard.maxFiles and stack.sizeGuard.maxLines (or --max-files and
+--max-lines) are flagged, and the command exits with an error, so it can run in
+CI. 'stack sync' and 'stack submit' run the same check before pushing; with
+stack.sizeGuard.mode=block they stop on it.`,
+
+	"undo.long": `Put the stack back as it was before the last sync, restack or reparent.
+
+Before each of those, stack records the tip and parent of every branch in your
+stacks, and once it finishes, what it left them at. 'stack undo' moves the
+branches the operation changed back to their recorded tips and restores their
+parents, then drops the operation from the log, so running it again undoes the
+one before. Branches the operation didn't touch are left alone.
+
+A branch that changed again since the operation, e.g. with a new commit, would
+lose that change, so undo refuses unless --force is given. The same goes for an
+operation that never finished, such as a sync stopped on conflicts and aborted,
+as its changes can't be told apart from later ones.
+
+Only local branches are restored. Branches that were force-pushed keep their
+new commits on origin until pushed again, and PR bases aren't changed back.
+Branches checked out in another worktree are skipped.`,
+
+	"changelog.long": `Print a markdown changelog of the stack, from its base branch up to the
+current branch (or the given one).
+
+Each branch gets a section headed by its PR title and link, followed by the
+subjects of its own commits, oldest first. 'fixup!' and 'squash!' commits are
+left out. Branches without a PR are headed by their name.
+
+The changelog is printed on its own, so it can be piped into the description of
+an umbrella PR or a deployment ticket once the stack lands. Run it from the top
+branch to cover the whole stack.`,
+
+	"doctor.long": `Check the stack metadata for problems, and repair them with --fix:
+  - parents that no longer exist: the branch is moved onto the closest
+    ancestor that still does (or the base branch)
+  - stack config left behind by deleted branches: it's removed
+  - PRs that don't target the branch's parent: they're retargeted
+  - worktrees whose directory was deleted: git forgets them
+
+Some problems need a decision and are only reported:
+  - parents that form a cycle (fix with 'stack reparent')
+  - branches that were pushed before but are gone from origin
+  - a sync, rebase plan or git operation left half-way
+
+Moving a branch onto another parent only changes its config; 'stack sync'
+rebases it. The command exits non-zero while errors remain.`,
+}
+
+// helpES is the Spanish long help text of each command, merged into messagesES
+var helpES = map[string]string{
+	"root.long": `Una herramienta de línea de comandos para gestionar pilas de ramas y sincronizarlas con Pull Requests de GitHub.
+
+Las ramas de la pila se siguen en la configuración de git, donde cada rama guarda su padre.
+La herramienta te ayuda a crear, recorrer y sincronizar ramas apiladas con el mínimo esfuerzo.`,
+
+	"new.long": `Crea una nueva rama en la pila, indicando opcionalmente una rama padre.
+
+La nueva rama se crea a partir del padre indicado (o de la rama actual si no se indica),
+y la relación con el padre se guarda en la configuración de git (branch.<nombre>.stackparent).
+
+Si no se indica un padre y no estás en una rama de pila, se usa la rama base
+(por defecto: main) como padre.
+
+Con --umbrella, la rama se crea como paraguas: una rama sin commits propios que
+solo agrupa subpilas. Sync la mantiene encima de su padre, pero nunca la sube ni
+abre un PR para ella, y los PRs de sus hijas apuntan a la rama que tiene debajo.`,
+
+	"status.long": `Muestra la estructura de la pila como un árbol, con:
+  - La jerarquía de ramas (relaciones padre → hija)
+  - La rama actual (marcada con *)
+  - El estado del PR de cada rama (si existe)
+
+Esto te ayuda a visualizar tu pila y ver qué ramas tienen PRs.
+
+Para que las pilas grandes se lean de un vistazo, las series y subárboles de
+ramas fusionadas se pliegan en una sola línea, igual que todo lo que queda por
+debajo de --depth. --expand lo muestra todo.
+
+--json imprime en su lugar el árbol completo, sin plegar: cada rama con su padre,
+su PR y los commits por delante y por detrás de su padre, junto con los
+problemas de sincronización.`,
+
+	"show.long": `Muestra la estructura local de la pila como un árbol sin consultar los PRs remotos.
+
+Es una versión rápida de 'stack status' que solo lee la configuración local de git.
+Usa 'stack status' para ver la información de los PRs y los problemas de sincronización.
+
+--json imprime en su lugar el árbol como JSON: cada rama con su padre y los
+commits por delante y por detrás de su padre.`,
+
+	"sync.long": `Sincroniza por completo la pila:
+  1. Obtiene los últimos cambios de origin
+  2. Hace rebase de cada rama de la pila sobre su padre (de abajo arriba)
+  3. Hace force push de cada rama a origin
+  4. Actualiza las ramas base de los PRs para que coincidan con la pila (si hay PRs)
+
+Así tu pila queda al día y todos los PRs tienen la rama base correcta.
+
+Si el PR de un padre se fusionó, las ramas hijas se rebasan sobre el padre del
+padre fusionado.
+
+Las ramas distintas de la actual se rebasan en un worktree oculto dentro del
+directorio de git, para que este worktree no vaya cambiando de rama en rama
+(pon stack.sync.worktree a false para rebasarlas aquí).
+
+Los cambios sin confirmar se guardan y se vuelven a aplicar automáticamente (con --autostash).`,
+
+	"prune.long": `Quita de la pila las ramas con PRs fusionados y las borra localmente.
+
+Por defecto, este comando solo revisa las ramas de la pila (las creadas con 'stack new').
+Usa --all para revisar todas las ramas locales.
+
+Este comando:
+  1. Busca todas las ramas con PRs fusionados
+  2. Las quita del seguimiento de la pila (si procede)
+  3. Borra las ramas locales con 'git branch -d'
+
+Si una rama tiene commits locales sin fusionar, usa --force para borrarla igualmente.
+
+Con --detect-merged-by-commit, una rama cuyo PR se cerró también cuenta como
+fusionada cuando su punta ya está en origin/<base>, como tras un push con
+fast-forward.`,
+
+	"parent.long": `Muestra la rama padre de la rama actual en la pila.
+
+Si la rama actual no tiene padre, indica que la rama no forma parte de una pila.`,
+
+	"rename.long": `Renombra la rama actual conservando todas las relaciones de la pila.
+
+Este comando:
+  - Renombra la rama de git
+  - Actualiza la referencia al padre de la rama en la configuración de git
+  - Hace que todas las ramas hijas apunten al nuevo nombre
+
+El comando debe ejecutarse desde la rama que quieres renombrar.`,
+
+	"reparent.long": `Cambia o asigna la rama padre de la rama actual.
+
+Este comando actualiza la relación con el padre en la configuración de git y, si
+existe un PR para la rama actual, cambia automáticamente la base del PR para
+que coincida con el nuevo padre.
+
+Sirve para:
+- Añadir una rama existente a una pila (cuando aún no tiene padre)
+- Reorganizar la pila cuando quieres cambiar sobre qué rama se basa una funcionalidad`,
+
+	"worktree.long": `Crea un worktree de git en el directorio .worktrees/ para la rama indicada.
+
+Si la rama existe localmente o en el remoto, se usa esa.
+Si la rama no existe, se crea una nueva a partir de la rama actual (o de
+rama-base si se indica) y se configura automáticamente su seguimiento en la pila.
+Usa --prune para limpiar los worktrees de ramas con PRs fusionados.`,
+
+	"up.long": `Cambia a la rama padre de la rama actual en la pila.
+
+Si la rama actual no tiene padre (está en la raíz de la pila), se muestra un
+mensaje de error.`,
+
+	"down.long": `Cambia a una rama hija de la rama actual en la pila.
+
+Si la rama actual no tiene hijas (está en el extremo de la pila), se muestra un
+mensaje de error.
+
+Si hay varias hijas, se te pedirá que elijas una.`,
+
+	"go.long": `Salta a la n-ésima rama de la pila actual, contando desde 1 en la base.
+
+Sin n, se listan con su número las ramas desde la base de la pila, pasando por
+la rama actual, hasta su extremo, y se te pedirá que elijas una. Donde la pila
+se bifurca por encima de la rama actual, la lista se detiene en la bifurcación;
+usa 'stack down' para elegir un lado.
+
+Una alternativa más rápida a repetir 'stack up' y 'stack down' en una pila
+profunda, y lo bastante corta para asignarla a un alias.`,
+
+	"version.long": `Muestra la versión, el hash del commit y la fecha de compilación de este binario de stack.`,
+
+	"prs.long": `Lista todos tus PRs abiertos agrupados por pila.
+
+Los PRs se agrupan siguiendo la rama base de cada PR hasta el PR sobre el que
+está apilado, así que funciona en cualquier máquina y sin seguimiento local de
+la pila. Cada PR muestra:
+  - Estado (abierto o borrador)
+  - Resultado de CI (correcto, fallido, pendiente)
+  - Decisión de revisión
+  - Antigüedad
+
+Todos los PRs se obtienen en una sola llamada.`,
+
+	"import.long": `Importa pilas desde PRs abiertos al seguimiento local de la pila.
+
+Las pilas se descubren siguiendo la rama base de cada PR hasta el PR sobre el
+que está apilado. Para cada PR, se obtiene la rama head de origin, se crea una
+rama local si hace falta y se asigna como padre de la pila la base del PR.
+
+Sirve para retomar tus propias pilas en otra máquina, o para revisar entera la
+pila de un compañero con --author. Usa --worktrees para abrir cada rama en su
+propio worktree bajo .worktrees/.`,
+
+	"adopt.long": `Empieza a seguir pilas creadas sin stackinator.
+
+Con --from-prs, el padre de cada rama se deduce de tus PRs abiertos: un PR cuya
+base es la rama head de otro PR está apilado sobre él, y un PR cuya base no
+tiene PR propio empieza una pila sobre esa rama. Se imprimen las pilas
+resultantes, marcando las ramas cuyo padre cambiaría, y no se escribe nada
+hasta que confirmes (o pases --yes).
+
+Una vez confirmado, cada rama se sigue como con 'stack import': se obtiene de
+origin, se crea localmente si hace falta y se asigna como padre de la pila la
+base de su PR. Las ramas que ya se siguen con el mismo padre no se tocan.`,
+
+	"review.long": `Abre la rama head de un PR en un worktree de revisión bajo .worktrees/review/.
+
+El worktree tiene un HEAD separado en el commit head del PR, así que revisar
+nunca toca tus propias ramas. Los commits del PR se imprimen como un range-diff
+contra su base.
+
+Ejecutar review de nuevo para el mismo PR mueve el worktree al nuevo head e
+imprime un range-diff entre los commits revisados antes y los nuevos, que
+muestra exactamente qué cambió desde tu última revisión, incluso tras un rebase.`,
+
+	"rangediff.long": `Compara una rama con origin/<rama> usando git range-diff, y resume qué commits
+cambiaron, se añadieron o se descartaron desde el último push del PR.
+
+Los commits se comparan por parche, así que una rama que solo se rebasó sobre un
+nuevo padre no muestra cambios de contenido. Por defecto usa la rama actual.
+
+Usa --comment para publicar el resumen y el range-diff en el PR de la rama, para
+que los revisores vean qué cambió desde la última vez que lo miraron.`,
+
+	"base.long": `Muestra la rama base sobre la que se construyen las pilas, y de dónde sale:
+de stack.baseBranch en la configuración de git, o detectada a partir de origin/HEAD.
+
+Usa 'stack base set <rama>' para cambiarla, o --detect para volver a la rama
+detectada. Cuando cambia la base, las pilas que partían de la base anterior se
+mueven a la nueva (ejecuta después 'stack sync' para rebasarlas).`,
+
+	"cleanConfig.long": `Elimina la configuración de pila (branch.<nombre>.stackparent, branch.<nombre>.stackpr, ...)
+que dejaron ramas que ya no existen localmente, por ejemplo tras borrar una rama
+con 'git branch -D'.
+
+Esas entradas ya se ignoran al construir las pilas; esto las quita de la
+configuración de git definitivamente.`,
+
+	"reviewers.long": `Sugiere revisores para cada rama de la pila actual a partir de CODEOWNERS.
+
+Cada rama se compara con CODEOWNERS usando solo los archivos que cambia respecto
+a su padre, así cada capa recibe los responsables de sus propios cambios en vez
+de que toda la pila avise a las mismas personas. CODEOWNERS se lee de la rama
+base en origin, como hace GitHub.
+
+Usa --request para pedir la revisión de los revisores sugeridos en el PR abierto
+de cada rama.`,
+
+	"blame.long": `Muestra la pila como un árbol con, para cada rama, los directorios que toca y
+cuántas líneas cambia respecto a su padre.
+
+Da a los revisores un contexto rápido de cada capa, y ayuda a decidir por dónde
+se podría dividir una rama grande.`,
+
+	"rebase.long": `Edita el orden de toda la pila en tu editor, como la lista de tareas de
+'git rebase -i' pero con una línea por rama en vez de por commit.
+
+Cada línea es una acción y una rama, empezando por la base de la pila:
+
+  pick <rama>   conserva la rama (las líneas se pueden mover para reordenar ramas)
+  fold <rama>   integra los commits de la rama en la rama de la línea anterior
+  drop <rama>   saca la rama y sus commits de la pila
+
+Borrar una línea descarta la rama. Las ramas descartadas e integradas se
+conservan localmente, pero dejan de seguirse como parte de la pila.
+
+Al cerrar el editor, cada rama se rebasa con --onto para que solo se lleve sus
+propios commits, se actualizan los padres de la pila y se cambia la base de los
+PRs abiertos. Si un rebase se detiene por un conflicto, resuélvelo, ejecuta
+'git rebase --continue' y luego 'stack rebase --continue'. 'stack rebase --abort'
+devuelve cada rama a donde estaba.
+
+Solo se admiten pilas lineales. Sube el resultado con 'stack sync'.`,
+
+	"fixup.long": `Confirma los cambios preparados como 'fixup!' de la punta de una rama inferior de
+la pila actual (o de --commit), sin cambiar a esa rama.
+
+Con --restack, el fixup se integra en su destino de inmediato con un rebase
+autosquash de la rama actual. Las ramas entre el destino y la rama actual se
+mueven con él (git rebase --update-refs, git 2.38 o posterior). Ejecuta después
+'stack sync' para rebasar las ramas de encima y subirlas.`,
+
+	"absorb.long": `Confirma cada bloque preparado como 'fixup!' del commit de la pila que cambió
+sus líneas por última vez, como git absorb, y luego integra los fixups y rebasa
+las ramas que hay encima de la actual.
+
+Un bloque se absorbe cuando todas las líneas que cambia o elimina las cambió por
+última vez el mismo commit de la rama actual o de una rama inferior. Los bloques
+que solo añaden líneas, o que cambian líneas de varios commits o de commits de
+fuera de la pila, se quedan sin confirmar.
+
+Los fixups se integran con un rebase autosquash de la rama actual, que mueve
+con él las ramas inferiores (git rebase --update-refs, git 2.38 o posterior).
+Después, las ramas apiladas sobre la actual se rebasan sobre ella como hace
+'stack rebase --interactive-plan', así que los conflictos se resuelven con
+'stack rebase --continue' o 'stack rebase --abort'. Con --no-squash solo se
+crean los commits de fixup.
+
+Sube las ramas con 'stack sync'.`,
+
+	"amend.long": `Corrige el último commit de la rama actual con los cambios preparados, y luego
+rebasa cada rama apilada encima sobre el nuevo commit, para que la pila siga
+siendo coherente sin un 'stack sync' completo.
+
+Cuando la rama aún no tiene commits propios, se crea un commit nuevo en lugar
+de corregir el de su padre. -m sustituye el mensaje del commit (si no, la
+corrección lo conserva) y --all prepara primero todos los cambios de los
+archivos seguidos, como 'git commit --all'.
+
+Las ramas de encima se rebasan localmente como hace 'stack rebase
+--interactive-plan', así que los conflictos se resuelven con 'stack rebase
+--continue' o 'stack rebase --abort'. No se obtiene ni se sube nada salvo con
+--push, que sube la rama y las ramas de encima que están en origin, con la
+misma estrategia que 'stack sync' (stack.pushStrategy).`,
+
+	"pick.long": `Aplica un commit, o todos los commits de una rama, sobre la rama actual.
+
+De una rama se aplican sus propios commits: los posteriores a su padre de pila,
+o a su bifurcación de la rama base. Cada commit aplicado registra el commit del
+que procede en una línea "(cherry picked from commit <sha>)".
+
+Cuando el commit original llega a la rama base, 'stack sync' detecta que la
+copia sobra (comparando parches) y ofrece descartarla.`,
+
+	"check.long": `Comprueba que la pila es coherente, usando solo datos locales para que tarde
+bastante menos de un segundo:
+  - los padres de la pila forman un árbol (sin ciclos) y cada rama padre existe
+  - no quedó a medias ningún sync, 'stack rebase -i' u operación de git
+  - cada PR apunta al padre de su rama, según lo vio por última vez 'stack sync'
+
+Los problemas que rompen sync son errores y hacen que el comando termine con un
+código distinto de cero; el resto son avisos. --json imprime los resultados para
+CI y otras herramientas.
+
+Con --pre-push, comprueba en su lugar las refs que git va a subir, leídas de la
+entrada estándar en el formato del hook pre-push (ver 'stack hook install'). El
+push se rechaza cuando:
+  - subiría una rama de pila sobre la rama base
+  - sobrescribiría una rama remota que tiene commits más nuevos que la local, o
+    commits que aún no se han obtenido
+
+Un PR cuya base no coincide con el padre de la rama en la pila se indica como
+aviso, ya que 'stack sync' le cambia la base.
+
+Con --pre-commit, avisa al confirmar directamente en la rama base mientras
+existan pilas (ver 'stack hook install --pre-commit').`,
+
+	"hook.long": `Gestiona los hooks de git que comprueban la pila. El hook pre-push ejecuta
+'stack check --pre-push', para que un simple 'git push' no pueda romper la pila
+sin avisar: el push se rechaza cuando subiría una rama de pila sobre la rama
+base, o sobrescribiría una rama remota más nueva.
+
+Con --pre-commit, se instala también un hook pre-commit. Avisa cuando confirmas
+directamente en la rama base mientras existen pilas, ya que esos commits
+pertenecen a una rama de pila.`,
+
+	"uplift.long": `Mueve a una nueva rama de pila los commits hechos por error en la rama base.
+
+Los commits locales de la rama base que no están en origin se quedan en una
+nueva rama (con la rama base como padre de pila), y la rama base se restablece
+a origin. Si estabas en la rama base, pasas a la nueva rama, conservando los
+cambios sin confirmar.`,
+
+	"name.long": `Muestra o asigna el nombre de la pila actual, para encontrarla con 'stack list'
+y cambiar a ella con 'stack switch <nombre>'.
+
+El nombre se guarda en la rama raíz de la pila (branch.<raíz>.stackname), la
+rama que está directamente sobre la rama base.`,
+
+	"list.long": `Lista todas las pilas con su nombre, su rama raíz y su número de ramas. La pila
+actual aparece marcada.`,
+
+	"switch.long": `Cambia a la punta de una pila, dado su nombre o su rama raíz.
+
+Si la pila se divide en varias puntas, se te pedirá que elijas una.`,
+
+	"checkout.long": `Cambia a una rama de la pila actual sin escribir su nombre completo.
+
+El nombre se compara de forma flexible, sin distinguir mayúsculas: gana un
+nombre exacto, luego los nombres que empiezan por él, luego los que lo
+contienen, y luego los que contienen sus letras en orden (p. ej. "pay-api" para
+payments-api). Si varias ramas coinciden por igual, se te pedirá que elijas una.
+
+Solo se tienen en cuenta las ramas de la pila actual, o las de todas las pilas
+con --all. Fuera de una pila, se usan las de todas.`,
+
+	"describe.long": `Edita en tu editor la descripción de una rama (por defecto, la actual).
+
+Es la misma descripción que 'git branch --edit-description', guardada en git
+junto a la rama. Cuando 'stack sync --create-prs' abre un PR para la rama, se
+usa la descripción como cuerpo del PR en lugar de los mensajes de los commits.`,
+
+	"reorder.long": `Intercambia una rama (por defecto, la actual) con su padre, para que pueda
+fusionarse antes cuando cambian las prioridades de revisión.
+
+La rama se rebasa sobre su abuela solo con sus propios commits, el padre se
+rebasa sobre ella, y las ramas apiladas sobre la rama pasan al padre. Se
+actualizan los padres de la pila y se cambia la base de los PRs abiertos.
+
+Es 'stack rebase --interactive-plan' con dos líneas intercambiadas, así que los
+conflictos se resuelven igual: resuélvelos, ejecuta 'git rebase --continue' y
+luego 'stack rebase --continue', o devuelve cada rama a su sitio con
+'stack rebase --abort'.
+
+Solo se admiten pilas lineales. Sube el resultado con 'stack sync'.`,
+
+	"split.long": `Divide la rama actual en ramas apiladas, una por grupo de commits.
+
+Los commits propios de la rama se listan del más antiguo al más nuevo, y eliges
+los commits tras los que dividir y un nombre para cada nueva rama. Cada nueva
+rama termina en su último commit y se apila sobre la anterior; la rama actual
+conserva los commits posteriores a la última división y se apila sobre la
+última rama nueva.
+
+No se reescribe ningún commit: las nuevas ramas apuntan a commits que la rama
+ya tiene, así que solo cambian las ramas y los padres de la pila. Las ramas
+apiladas sobre la rama actual siguen sobre ella. Sube las nuevas ramas y abre
+sus PRs con 'stack sync' o 'stack submit'.
+
+Con --at, la división se indica en la línea de comandos en lugar de forma
+interactiva. Como los nombres de rama no pueden contener ':',
+"<commit>:<rama>" no es ambiguo.`,
+
+	"fold.long": `Integra la rama actual en su padre, p. ej. cuando dos capas de la pila
+resultan ser un solo cambio.
+
+La rama se rebasa sobre su padre si hace falta y el padre se mueve a su punta,
+así el padre recibe los commits de la rama. Las ramas apiladas sobre la rama
+pasan al padre, la rama se borra y su PR abierto se cierra con un comentario
+que apunta al padre. Terminas en el padre.
+
+Es 'stack rebase --interactive-plan' con la línea de la rama convertida en un
+fold, así que los conflictos se resuelven igual: resuélvelos, ejecuta
+'git rebase --continue' y luego 'stack rebase --continue', o devuelve cada rama
+a su sitio con 'stack rebase --abort'.
+
+Solo se admiten pilas lineales. Sube el padre con 'stack sync'.`,
+
+	"formatPatch.long": `Exporta la pila hasta la rama actual como series de parches, un directorio por
+rama con los commits que la rama añade sobre su padre (git format-patch).
+
+Un archivo stack-series junto a los directorios registra las ramas y sus
+padres, para que 'stack am' pueda reconstruir la pila a partir de él. Los
+parches se pueden enviar tal cual a una lista de correo, o copiar a otra máquina.`,
+
+	"am.long": `Reconstruye una pila a partir de una serie escrita por 'stack format-patch'.
+
+Cada rama de la serie se crea sobre su padre y sus parches se aplican con
+'git am --3way'. La rama inferior parte del padre que tenía al exportarse si esa
+rama existe aquí, y si no de la rama base (o de --onto). Los padres de la pila
+se asignan a medida que se crean las ramas.
+
+Si un parche no se aplica, resuélvelo y ejecuta 'git am --continue' (o
+'git am --abort'), y luego vuelve a ejecutar 'stack am': las ramas que ya
+existen se omiten, así que continúa donde se detuvo.`,
+
+	"env.long": `Muestra el repositorio, el host de GitHub y la cuenta que usa stack aquí.
+
+Con varias cuentas de GitHub en una misma máquina (p. ej. trabajo y personal),
+elige la de un repositorio con stack.ghUser, y el host con stack.ghHost cuando
+origin pasa por un alias de host SSH:
+
+  git config stack.ghHost github.com
+  git config stack.ghUser mi-usuario-del-trabajo
+
+La cuenta debe haber iniciado sesión en gh ('gh auth login'); stack usa su token
+sin cambiar la cuenta activa de gh. Se respeta GH_HOST cuando stack.ghHost no
+está definido.`,
+
+	"submit.long": `Abre un PR para cada rama de la pila que no lo tenga.
+
+La pila se recorre de abajo arriba, desde la rama base hasta la rama actual.
+Cada rama que aún no está en origin se sube, y también una rama que va por
+delante de origin. Las ramas con un PR abierto no se tocan; las demás reciben un
+PR contra su padre (o contra la rama que hay bajo un padre paraguas), con el
+título y el cuerpo tomados de sus commits. La descripción de la rama (ver
+'stack describe') pasa a ser el cuerpo cuando está definida.
+
+Las ramas que difieren de origin de otra forma, p. ej. tras un rebase, no se
+suben con force push; ejecuta 'stack sync' para eso.`,
+
+	"land.long": `Fusiona el PR de la rama inferior de la pila y luego reapila el resto.
+
+La rama inferior es la que está justo encima de la rama base en la pila de la
+rama actual. Su PR debe estar abierto y apuntar a la rama base, y la rama debe
+coincidir con origin para que lo que se fusiona sea lo que tienes en local.
+
+El PR se fusiona con 'gh pr merge', con squash por defecto. Cuando la rama base
+usa una cola de fusión, el PR se añade a la cola. En ambos casos, land espera a
+que GitHub indique que el PR está fusionado y luego ejecuta 'stack sync': las
+ramas apiladas sobre la fusionada pasan a la rama base, se rebasan y se suben
+con force push, y se cambia la base de sus PRs.
+
+Cuando la rama fusionada es la que tienes activa y hay una sola rama apilada
+sobre ella, se cambia a esa rama para que el sync la incluya.`,
+
+	"share.long": `Publica en origin los padres de pila de tus ramas, para que los compañeros que
+las abran puedan usar los comandos de stack con ellas.
+
+Los padres de pila solo existen en tu configuración local de git. Share escribe
+los de cada rama de pila que está en origin en la ref %s y la sube.
+La ref la comparten todos los que trabajan en el repositorio: las ramas
+compartidas por otros se conservan, las tuyas sustituyen lo que se compartió
+antes para ellas, y las ramas que ya no están en origin se descartan.
+
+En otro clon, 'stack fetch-metadata' la vuelve a leer.`,
+
+	"fetchMetadata.long": `Sigue las ramas con los padres de pila compartidos en origin por 'stack share'.
+
+Cada rama compartida que existe localmente y aún no tiene padre de pila recibe
+el compartido. Las ramas que ya tienen padre lo conservan, y se listan cuando
+difiere del compartido; usa 'stack reparent' para cambiarlas. Las ramas
+compartidas que no están aquí se omiten.`,
+
+	"conflicts.long": `Encuentra las ramas de la pila que entrarán en conflicto con la rama base al
+fusionarse, y la capa en la que empieza cada conflicto.
+
+GitHub solo indica si un PR entra en conflicto con su propia base, que para la
+mayor parte de la pila es la rama padre. Este comando fusiona en memoria cada
+rama, desde la rama base hasta la actual, con la rama base de origin (no se
+cambia de rama ni se escribe nada) y lista los archivos en conflicto. Un archivo
+que entra en conflicto en una rama pero no en su padre empieza a hacerlo en esa
+rama, así que es ahí donde hay que resolverlo; las ramas de encima solo lo
+heredan.
+
+También se marcan los PRs que GitHub indica en conflicto con su base. Termina
+con código 1 cuando alguna rama tiene conflictos. Requiere git 2.38 o posterior.`,
+
+	"ui.long": `Recorre todas las pilas en una vista a pantalla completa y actúa sobre ellas sin
+escribir comandos.
+
+Muévete con las flechas (o j/k) y pulsa Intro para cambiar a la rama
+seleccionada. m abre las acciones: sincronizar la pila, cambiar el padre de la
+rama actual a una rama elegida en el árbol, o limpiar las ramas fusionadas. Las
+acciones se ejecutan como los habituales 'stack sync', 'stack reparent' y
+'stack prune', con su salida y sus preguntas, y la vista vuelve cuando
+terminan. q sale.`,
+
+	"recover.long": `Termina o deshace un renombrado o cambio de padre que se detuvo a medias.
+
+'stack rename' y 'stack reparent' constan de varios pasos (renombrar la rama,
+mover su padre, apuntar sus hijas al nuevo nombre, cambiar la base de su PR).
+Cada uno registra lo que va a hacer antes de empezar, y borra el registro al
+terminar. Si uno falla entre medias, la pila queda cambiada a medias; 'stack
+recover' lee el registro, rehace los pasos que aún no se hicieron y lo borra.
+Los pasos ya hechos no se tocan, así que se puede ejecutar más de una vez.
+
+Con --rollback, en su lugar se deshacen los pasos que se hicieron, devolviendo
+la pila a como estaba antes de la operación.`,
+
+	"restack.long": `Rebasa una sola rama (por defecto, la actual) sobre su padre y la sube con
+force push, sin sincronizar el resto de la pila.
+
+Úsalo tras cambiar una rama en mitad de la pila cuando solo la rama de encima
+tiene que seguirla, en lugar de ejecutar 'stack sync' sobre toda la cadena.
+Con --descendants también se reapilan sobre ella las hijas de la rama; las
+ramas de más arriba no se tocan.
+
+Solo se reaplican los commits propios de la rama sobre el padre local; no se
+obtiene nada y no se tocan los PRs. Las ramas sin rama remota no se suben.
+Los push usan la misma estrategia que 'stack sync' (stack.pushStrategy).
+
+Si un rebase se detiene por un conflicto, resuélvelo, ejecuta
+'git rebase --continue' y vuelve a ejecutar 'stack restack' para subirla.`,
+
+	"sizeGuard.long": `Comprueba el tamaño de cada rama de la pila actual contra los límites de revisión.
+
+Cada rama se mide por lo que cambia sobre su padre: los archivos que toca y las
+líneas que añade y borra. Las ramas que superan los límites fijados con
+stack.sizeGuard.maxFiles y stack.sizeGuard.maxLines (o --max-files y
+--max-lines) se marcan, y el comando termina con error, para poder ejecutarlo en
+CI. 'stack sync' y 'stack submit' hacen la misma comprobación antes de subir;
+con stack.sizeGuard.mode=block se detienen en ella.`,
+
+	"undo.long": `Devuelve la pila al estado anterior al último sync, restack o reparent.
+
+Antes de cada uno de ellos, stack registra la punta y el padre de cada rama de
+tus pilas y, al terminar, dónde las dejó. 'stack undo' devuelve las ramas que
+cambió la operación a sus puntas registradas y restaura sus padres, y luego
+quita la operación del registro, así que al ejecutarlo otra vez deshace la
+anterior. Las ramas que la operación no tocó no se tocan.
+
+Una rama que cambió de nuevo desde la operación, p. ej. con un commit nuevo,
+perdería ese cambio, así que undo se niega salvo con --force. Lo mismo ocurre
+con una operación que nunca terminó, como un sync detenido por conflictos y
+abortado, ya que sus cambios no se pueden distinguir de los posteriores.
+
+Solo se restauran las ramas locales. Las ramas subidas con force push conservan
+sus commits nuevos en origin hasta que se vuelvan a subir, y no se restauran las
+bases de los PRs. Las ramas abiertas en otro worktree se omiten.`,
+
+	"changelog.long": `Imprime un registro de cambios en markdown de la pila, desde su rama base hasta
+la rama actual (o la indicada).
+
+Cada rama tiene una sección encabezada por el título y el enlace de su PR,
+seguida de los asuntos de sus propios commits, del más antiguo al más nuevo. Se
+omiten los commits 'fixup!' y 'squash!'. Las ramas sin PR se encabezan con su
+nombre.
+
+El registro se imprime solo, para poder pasarlo a la descripción de un PR
+paraguas o de un ticket de despliegue cuando la pila se fusione. Ejecútalo desde
+la rama superior para cubrir toda la pila.`,
+
+	"doctor.long": `Comprueba los metadatos de la pila en busca de problemas, y los repara con --fix:
+  - padres que ya no existen: la rama se mueve al ancestro más cercano que
+    sigue existiendo (o a la rama base)
+  - configuración de pila que dejaron ramas borradas: se elimina
+  - PRs que no apuntan al padre de su rama: se les cambia la base
+  - worktrees cuyo directorio se borró: git los olvida
+
+Algunos problemas requieren una decisión y solo se indican:
+  - padres que forman un ciclo (corrígelo con 'stack reparent')
+  - ramas que se subieron antes pero ya no están en origin
+  - un sync, plan de rebase u operación de git que quedó a medias
+
+Mover una rama a otro padre solo cambia su configuración; 'stack sync' la
+rebasa. El comando termina con un código distinto de cero mientras queden errores.`,
+}
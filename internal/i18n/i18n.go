@@ -0,0 +1,87 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DefaultLocale is used when no supported locale is configured
+const DefaultLocale = "en"
+
+// catalogs maps a locale to its message catalog
+var catalogs = map[string]map[string]string{
+	"en": messagesEN,
+	"es": messagesES,
+}
+
+// locale is the active locale, detected from the environment at startup
+// and optionally overridden from git config (stack.locale) via SetLocale
+var locale = detectLocale()
+
+// T returns the message for key in the active locale, formatted with args.
+// Falls back to English when the key is missing from the active catalog,
+// and to the key itself when it is missing everywhere.
+func T(key string, args ...interface{}) string {
+	msg, ok := catalogs[locale][key]
+	if !ok {
+		msg, ok = catalogs[DefaultLocale][key]
+		if !ok {
+			msg = key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// SetLocale sets the active locale. Accepts values like "es", "es_ES" or
+// "es_ES.UTF-8". Unsupported locales are ignored and false is returned.
+func SetLocale(value string) bool {
+	l := normalize(value)
+	if _, ok := catalogs[l]; !ok {
+		return false
+	}
+	locale = l
+	return true
+}
+
+// Locale returns the active locale
+func Locale() string {
+	return locale
+}
+
+// Supported returns the list of supported locales
+func Supported() []string {
+	return []string{"en", "es"}
+}
+
+// detectLocale picks the locale from the standard environment variables,
+// in the same order of precedence as gettext
+func detectLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		if l := normalize(value); catalogs[l] != nil {
+			return l
+		}
+		// The first non-empty variable wins, even if unsupported
+		return DefaultLocale
+	}
+	return DefaultLocale
+}
+
+// normalize reduces a POSIX locale string ("es_ES.UTF-8@euro") to its language code ("es")
+func normalize(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexAny(value, "_-.@"); i != -1 {
+		value = value[:i]
+	}
+	if value == "c" || value == "posix" || value == "" {
+		return DefaultLocale
+	}
+	return value
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"es", "es"},
+		{"es_ES", "es"},
+		{"es_ES.UTF-8", "es"},
+		{"en-US", "en"},
+		{"de_DE@euro", "de"},
+		{"C", "en"},
+		{"POSIX", "en"},
+		{"", "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, normalize(tt.input))
+		})
+	}
+}
+
+func TestDetectLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{"LANG only", map[string]string{"LANG": "es_ES.UTF-8"}, "es"},
+		{"LC_ALL wins over LANG", map[string]string{"LC_ALL": "en_US.UTF-8", "LANG": "es_ES.UTF-8"}, "en"},
+		{"LC_MESSAGES wins over LANG", map[string]string{"LC_MESSAGES": "es", "LANG": "en_US"}, "es"},
+		{"unsupported falls back", map[string]string{"LANG": "fr_FR.UTF-8"}, "en"},
+		{"nothing set", map[string]string{}, "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+				t.Setenv(env, tt.env[env])
+			}
+			assert.Equal(t, tt.expected, detectLocale())
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLocale(Locale())
+
+	assert.True(t, SetLocale("en"))
+	assert.Equal(t, "Error: boom", T("error", "boom"))
+	assert.Equal(t, "unknown.key", T("unknown.key"))
+
+	assert.True(t, SetLocale("es_ES.UTF-8"))
+	assert.Equal(t, "es", Locale())
+	assert.Equal(t, "Rama actual: main", T("stack.currentBranch", "main"))
+
+	// Unsupported locales leave the active locale unchanged
+	assert.False(t, SetLocale("fr"))
+	assert.Equal(t, "es", Locale())
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	for _, l := range Supported() {
+		catalog, ok := catalogs[l]
+		assert.True(t, ok, "missing catalog for %s", l)
+
+		for key, msg := range messagesEN {
+			translated, ok := catalog[key]
+			if assert.True(t, ok, "%s: missing key %s", l, key) {
+				// Translations must keep the same format verbs as the English source
+				assert.Equal(t, strings.Count(msg, "%"), strings.Count(translated, "%"), "%s: format verbs differ for %s", l, key)
+			}
+		}
+		for key := range catalog {
+			_, ok := messagesEN[key]
+			assert.True(t, ok, "%s: key %s not in English catalog", l, key)
+		}
+	}
+}
@@ -0,0 +1,419 @@
+package i18n
+
+// messagesEN is the English catalog and the source of truth for message keys
+var messagesEN = map[string]string{
+	// Command help
+	"root.short":          "Manage stacked branches and sync them to GitHub PRs",
+	"new.short":           "Create a new branch in the stack",
+	"status.short":        "Show the current stack structure",
+	"show.short":          "Show the local stack structure (fast)",
+	"sync.short":          "Sync all stack branches with their parents and update PRs",
+	"prune.short":         "Clean up branches with merged PRs",
+	"parent.short":        "Show the parent of the current branch",
+	"rename.short":        "Rename the current branch while preserving stack relationships",
+	"reparent.short":      "Change the parent of the current branch",
+	"worktree.short":      "Create a worktree in .worktrees/ directory",
+	"up.short":            "Move to the parent branch in the stack",
+	"down.short":          "Move to a child branch in the stack",
+	"go.short":            "Jump to a branch of the stack by its number",
+	"version.short":       "Print version information",
+	"prs.short":           "List your open PRs grouped by stack",
+	"import.short":        "Import stacks from open PRs into local stack tracking",
+	"adopt.short":         "Track stacks built without stackinator, inferred from your open PRs",
+	"review.short":        "Check out a PR in a read-only review worktree",
+	"rangediff.short":     "Show what changed in a branch since it was last pushed",
+	"base.short":          "Show or change the base branch stacks are built on",
+	"baseSet.short":       "Change the base branch and move existing stacks onto it",
+	"cleanConfig.short":   "Remove stack config left behind by deleted branches",
+	"reviewers.short":     "Suggest reviewers for each branch in the stack from CODEOWNERS",
+	"blame.short":         "Summarize which directories each branch in the stack touches",
+	"rebase.short":        "Reorder, fold or drop branches of the stack in your editor",
+	"fixup.short":         "Commit staged changes as a fixup of a lower branch in the stack",
+	"absorb.short":        "Fold staged hunks into the stack commits they change",
+	"amend.short":         "Amend the current branch and restack the branches above it",
+	"pick.short":          "Cherry-pick a commit or branch onto the current branch, tracking its source",
+	"check.short":         "Check that the stack is consistent (fast, offline)",
+	"hook.short":          "Manage the git hooks that check the stack",
+	"hookInstall.short":   "Install a pre-push hook that runs 'stack check'",
+	"hookUninstall.short": "Remove the git hooks installed by stack",
+	"uplift.short":        "Move local commits on the base branch into a new stack branch",
+	"name.short":          "Show or set the name of the current stack",
+	"list.short":          "List all stacks and their names",
+	"switch.short":        "Check out the tip of a stack by name",
+	"checkout.short":      "Check out a branch of the stack by part of its name",
+	"describe.short":      "Edit the description of a branch, used as its PR body",
+	"reorder.short":       "Swap a branch with its parent in the stack",
+	"split.short":         "Break the current branch into stacked branches by commit",
+	"fold.short":          "Collapse the current branch into its parent",
+	"formatPatch.short":   "Export the stack as patch series, one directory per branch",
+	"am.short":            "Rebuild a stack from a series written by format-patch",
+	"env.short":           "Show the repository, GitHub host and account in use",
+	"submit.short":        "Push the stack and open a PR for every branch without one",
+	"land.short":          "Merge the bottom PR of the stack and restack the rest",
+	"share.short":         "Publish your stack parents on origin for teammates",
+	"fetchMetadata.short": "Track branches with the stack parents shared on origin",
+	"conflicts.short":     "Find where the stack will conflict with the base branch",
+	"ui.short":            "Browse the stack and run actions interactively",
+	"recover.short":       "Finish or roll back an interrupted rename or reparent",
+	"restack.short":       "Rebase a single branch onto its parent and push it",
+	"sizeGuard.short":     "Check each branch of the stack against the review size limits",
+	"undo.short":          "Put the stack back as it was before the last sync, restack or reparent",
+	"changelog.short":     "Print a markdown changelog of the stack's PRs and commits",
+	"doctor.short":        "Check the stack metadata for problems and repair them",
+
+	// Global flags
+	"flag.dryRun":   "Show what would happen without executing",
+	"flag.verbose":  "Show detailed output",
+	"flag.noColor":  "Disable colored output",
+	"flag.yes":      "Acknowledge first-run confirmation prompts without asking",
+	"flag.chdir":    "Run as if stack was started in <path> instead of the current directory",
+	"flag.script":   "With --dry-run, print the git/gh commands as a shell script instead of running them",
+	"flag.readOnly": "Fail on any change to the repository, its config or PRs (also STACKINATOR_READONLY=1)",
+	"flag.offline":  "Skip fetching, pushing and GitHub, using local refs and cached PR data (also STACKINATOR_OFFLINE=1)",
+
+	// Errors
+	"error":                   "Error: %v",
+	"error.notInRepo":         "Error: not in a git repository",
+	"error.chdir":             "Error: cannot change to %s: %v",
+	"error.scriptNeedsDryRun": "Error: --script can only be used with --dry-run",
+	"error.bareRepo":          "Error: this is a bare repository; stack needs a working tree (set GIT_WORK_TREE or run from a worktree)",
+
+	// Dry-run summary
+	"dryRun.summary":   "Dry run: skipped %s",
+	"dryRun.nothing":   "Dry run: nothing would change",
+	"dryRun.pushes":    "%d push(es)",
+	"dryRun.deletions": "%d deletion(s)",
+	"dryRun.config":    "%d config write(s)",
+	"dryRun.prEdits":   "%d PR edit(s)",
+	"dryRun.other":     "%d other change(s)",
+
+	// Shared stack messages
+	"stack.noBranches":    "No stack branches found.",
+	"stack.currentBranch": "Current branch: %s",
+	"stack.createHint":    "Use '%s' to create a new stack branch.",
+
+	// Navigation
+	"parent.notInStack": "(not in a stack)",
+	"up.atRoot":         "already at stack root (no parent for %s)",
+	"up.switched":       "Switched to parent branch: %s",
+	"down.noChildren":   "no children (tip of stack)",
+	"down.multiple":     "Multiple children found for %s:",
+	"down.select":       "Select branch (1-%d): ",
+	"down.switched":     "Switched to child branch: %s",
+	"go.notInStack":     "%s is not in a stack",
+	"go.outOfRange":     "invalid branch number %q (1-%d)",
+	"go.switched":       "Switched to %s (%d/%d)",
+
+	// Sync
+	"sync.conflict.detected":    "Rebase conflict detected. To continue:",
+	"conflict.resolve":          "1. Resolve the conflicts",
+	"conflict.add":              "2. Run 'git add <resolved files>'",
+	"conflict.continue":         "3. Run 'git rebase --continue'",
+	"sync.conflict.resume":      "4. Run 'stack sync --resume'",
+	"sync.conflict.abortHeader": "Or to abort the sync:",
+	"sync.conflict.abort":       "Run 'stack sync --abort'",
+	"sync.conflict.stashNote":   "Note: Your uncommitted changes have been stashed and will be restored when you run --resume or --abort",
+	"sync.possibleCause":        "Possible cause:",
+	"sync.pushHint":             "Remote branch was updated after fetch - try running 'stack sync' again",
+	"sync.complete":             "Sync complete!",
+
+	// Status
+	"status.outOfSync": "Stack out of sync detected:",
+	"status.runSync":   "Run '%s' to rebase branches and update PR bases.",
+	"status.synced":    "Stack is perfectly synced! All branches are up to date.",
+
+	"status.inProgress":         "%s in progress on %s: resolve or abort before syncing",
+	"status.inProgressDetached": "%s in progress: resolve or abort before syncing",
+	"status.inProgressHint":     "  Continue with '%s' or abort with '%s'",
+
+	"status.interruptedSync":       "An interrupted sync exists (started %s ago on %s): resume or abort it",
+	"status.interruptedSyncNoTime": "An interrupted sync exists (started on %s): resume or abort it",
+
+	// Prune
+	"prune.complete": "Prune complete!",
+
+	// Error hints
+	"hint.conflict":                 "Resolve the conflicts and run '%s', or run '%s'",
+	"hint.auth":                     "Sign in with '%s', or check the account in use with '%s'",
+	"hint.offline":                  "This needs the network; run it again once online",
+	"hint.notInStack":               "Add it to a stack with '%s'.",
+	"rebase.planInProgress":         "a rebase plan is in progress\n\nUse '%s' or '%s'",
+	"rebase.planAlreadyInProgress":  "a rebase plan is already in progress\n\nUse '%s' or '%s'",
+	"rebase.nothingToDo":            "nothing to do\n\nUse '%s' to edit the stack",
+	"rebase.stillInProgress":        "a rebase is still in progress\n\nResolve the conflicts and run '%s' first",
+	"sync.noneToAbort":              "no interrupted sync to abort\n\nUse 'stack sync' to start a new sync",
+	"sync.noneToResume":             "no interrupted sync to resume\n\nUse 'stack sync' to start a new sync",
+	"sync.resumeHint":               "If you resolved rebase conflicts, run 'stack sync --resume'",
+	"sync.startFreshAborted":        "Aborted. Use 'stack sync --resume' or 'stack sync --abort' to handle the interrupted sync.",
+	"recover.pending":               "a %s didn't finish; run '%s' to finish it, or '%s' to undo it",
+	"recover.stopped":               "The %s stopped part way. Run '%s' to finish it, or '%s' to undo it",
+	"recover.bothExist":             "both %s and %s exist; delete the one that shouldn't and run '%s' again",
+	"undo.unfinished":               "the %s didn't finish, so its changes can't be told apart from later ones\n\nUse '%s' to put back every branch that changed since it started",
+	"undo.changedSince":             "%s changed since the %s, undoing it would discard that\n\nUse '%s' to undo it anyway",
+	"ui.needsTerminal":              "stack ui needs a terminal; use 'stack status' in scripts",
+	"conflict.rebaseStopped":        "Rebase stopped. To continue:",
+	"conflict.rebaseOn":             "Rebase conflict on %s. To continue:",
+	"conflict.cherryPickOn":         "Cherry-pick conflict on %s. To continue:",
+	"conflict.cherryPickContinue":   "3. Run 'git cherry-pick --continue'",
+	"absorb.conflict.abort":         "Or run 'git rebase --abort' to keep the fixup commits as they are",
+	"absorb.conflict.restack":       "Then restack the branches above with 'stack sync'",
+	"fixup.conflict.abort":          "Or run 'git rebase --abort' to keep the fixup commit as is",
+	"pick.conflict.rest":            "4. Run 'git cherry-pick -x %s'",
+	"pick.conflict.abort":           "Or run 'git cherry-pick --abort' to stop picking",
+	"rebase.conflict.continue":      "4. Run 'stack rebase --continue'",
+	"rebase.conflict.abort":         "To restore every branch instead:",
+	"restack.conflict.stopped":      "Rebase of %s stopped on conflicts. To continue:",
+	"restack.conflict.resolve":      "1. Resolve the conflicts and run 'git add <resolved files>'",
+	"restack.conflict.continue":     "2. Run 'git rebase --continue'",
+	"restack.conflict.push":         "3. Run 'stack restack %s' to push it",
+	"restack.conflict.abort":        "Or run 'git rebase --abort' to leave %s as it was.",
+	"am.conflict.stopped":           "A patch did not apply. To continue:",
+	"am.conflict.resolve":           "1. Resolve the conflicts and 'git add' the files",
+	"am.conflict.continue":          "2. Run '%s'",
+	"am.conflict.rerun":             "3. Run '%s' to create the remaining branches",
+	"am.conflict.abort":             "Or start the branch over with '%s' and '%s'",
+	"sync.cherryPick.rest":          "4. Complete remaining cherry-picks manually",
+	"sync.cherryPick.rename":        "5. Run 'git branch -D %s && git branch -m %s'",
+	"sync.cherryPick.resume":        "6. Run 'stack sync --resume'",
+	"sync.cherryPick.recommended":   "Recommended: Run 'stack sync --cherry-pick' to auto-rebuild",
+	"sync.conflict.lfs":             "LFS files were left as pointers (--no-lfs-smudge); run 'git lfs pull' once done.",
+	"adopt.syncHint":                "Run '%s' to restack them on their parents.",
+	"base.syncHint":                 "Run '%s' to rebase the moved stacks onto %s.",
+	"check.notFetched":              "%s has commits that haven't been fetched; run '%s' first",
+	"check.remoteNewer":             "%s has newer commits than the local branch; run '%s' first",
+	"import.statusHint":             "Run '%s' on any imported branch to see its stack.",
+	"land.wrongBase":                "PR #%d targets %s instead of %s, run '%s' first",
+	"land.severalChildren":          "%d branches are stacked on %s; run '%s' from each of them to restack it",
+	"land.differs":                  "%s differs from %s, run '%s' before landing it",
+	"land.notMerged":                "PR #%d isn't merged after %s, run '%s' once it is",
+	"mergeCheck.parentMerged":       "Parent %s of %s was merged: run '%s' to move it onto %s",
+	"prune.forceHint":               "Use '%s' to force delete, or manually delete with: %s",
+	"rebase.loadPRsFailed":          "Warning: failed to load PRs, run 'stack sync' to retarget them: %v",
+	"rebase.prNotOpen":              "PR #%d is now %s, run 'stack sync' to move the branches above it",
+	"share.pushFailed":              "failed to push %s (if someone shared at the same time, run '%s' again)",
+	"share.fetchHint":               "Teammates can run '%s' to track the branches they check out.",
+	"fetchMetadata.missing":         "%d shared branch(es) aren't checked out here; check them out and run '%s' again to track them.",
+	"staleness.syncHint":            "Run '%s' to restack before it drifts further.",
+	"submit.prNotOpen":              "PR #%d is %s, run '%s' to restack on top of it",
+	"submit.differs":                "Differs from %s, not pushing (run '%s' to force-push it)",
+	"worktree.pruneHint":            "Tip: Run '%s' to also delete the merged branches.",
+	"sync.keepBaseHint":             "To keep %s instead, run '%s' on %s afterwards",
+	"sync.ciHeldHint":               "Fix the failing branch and run '%s' again to push them.",
+	"sync.lfsPullHint":              "Run '%s' to replace the pointer files with their content",
+	"sync.submodulesHint":           "Run '%s' to check out the recorded commits,",
+	"sync.submodulesConfigHint":     "or set '%s' to do it after every checkout.",
+	"sync.submoduleConflictHint":    "For each, check out the commit to keep inside the submodule",
+	"sync.submoduleConflictExample": "(e.g. 'git -C <path> checkout <commit>'), then run 'git add <path>'.",
+	"sync.stashPopHint":             "Run '%s' manually to restore your changes",
+	"sync.nothingChanged":           "Nothing changed since the last sync (use '%s' to walk the stack anyway)",
+	"sync.offlineHint":              "Run '%s' again once online to push and update PRs",
+}
+
+// messagesES is the Spanish catalog
+var messagesES = map[string]string{
+	// Command help
+	"root.short":          "Gestiona ramas apiladas y sincronízalas con PRs de GitHub",
+	"new.short":           "Crea una nueva rama en la pila",
+	"status.short":        "Muestra la estructura actual de la pila",
+	"show.short":          "Muestra la estructura local de la pila (rápido)",
+	"sync.short":          "Sincroniza las ramas de la pila con sus padres y actualiza los PRs",
+	"prune.short":         "Limpia las ramas con PRs fusionados",
+	"prs.short":           "Lista tus PRs abiertos agrupados por pila",
+	"import.short":        "Importa pilas desde PRs abiertos al seguimiento local",
+	"adopt.short":         "Sigue pilas creadas sin stackinator, deducidas de tus PRs abiertos",
+	"review.short":        "Abre un PR en un worktree de revisión de solo lectura",
+	"rangediff.short":     "Muestra qué cambió en una rama desde el último push",
+	"base.short":          "Muestra o cambia la rama base sobre la que se construyen las pilas",
+	"baseSet.short":       "Cambia la rama base y mueve las pilas existentes sobre ella",
+	"cleanConfig.short":   "Elimina la configuración de pila que dejaron ramas borradas",
+	"reviewers.short":     "Sugiere revisores para cada rama de la pila a partir de CODEOWNERS",
+	"blame.short":         "Resume qué directorios toca cada rama de la pila",
+	"rebase.short":        "Reordena, combina o descarta ramas de la pila en tu editor",
+	"fixup.short":         "Confirma los cambios preparados como fixup de una rama inferior de la pila",
+	"absorb.short":        "Incorpora los cambios preparados a los commits de la pila que modifican",
+	"amend.short":         "Corrige la rama actual y rebasa las ramas que tiene encima",
+	"pick.short":          "Aplica un commit o rama sobre la rama actual, registrando su origen",
+	"check.short":         "Comprueba que la pila es coherente (rápido, sin red)",
+	"hook.short":          "Gestiona los hooks de git que comprueban la pila",
+	"hookInstall.short":   "Instala un hook pre-push que ejecuta 'stack check'",
+	"hookUninstall.short": "Elimina los hooks de git instalados por stack",
+	"uplift.short":        "Mueve los commits locales de la rama base a una nueva rama de pila",
+	"name.short":          "Muestra o asigna el nombre de la pila actual",
+	"list.short":          "Lista todas las pilas y sus nombres",
+	"switch.short":        "Cambia a la punta de una pila por su nombre",
+	"checkout.short":      "Cambia a una rama de la pila por parte de su nombre",
+	"describe.short":      "Edita la descripción de una rama, usada como cuerpo de su PR",
+	"reorder.short":       "Intercambia una rama con su padre en la pila",
+	"split.short":         "Divide la rama actual en ramas apiladas por commit",
+	"fold.short":          "Integra la rama actual en su rama padre",
+	"formatPatch.short":   "Exporta la pila como series de parches, un directorio por rama",
+	"am.short":            "Reconstruye una pila a partir de una serie escrita por format-patch",
+	"env.short":           "Muestra el repositorio, el host de GitHub y la cuenta en uso",
+	"submit.short":        "Sube la pila y abre un PR para cada rama que no lo tenga",
+	"land.short":          "Fusiona el PR inferior de la pila y reapila el resto",
+	"share.short":         "Publica los padres de tus pilas en origin para tu equipo",
+	"fetchMetadata.short": "Sigue ramas con los padres de pila compartidos en origin",
+	"conflicts.short":     "Encuentra dónde la pila entrará en conflicto con la rama base",
+	"ui.short":            "Recorre la pila y ejecuta acciones de forma interactiva",
+	"recover.short":       "Termina o deshace un renombrado o cambio de padre interrumpido",
+	"restack.short":       "Rebasa una sola rama sobre su padre y la sube",
+	"sizeGuard.short":     "Comprueba cada rama de la pila contra los límites de tamaño de revisión",
+	"undo.short":          "Devuelve la pila al estado anterior al último sync, restack o reparent",
+	"changelog.short":     "Imprime un registro de cambios en markdown de los PRs y commits de la pila",
+	"doctor.short":        "Comprueba los metadatos de la pila y repara sus problemas",
+	"parent.short":        "Muestra el padre de la rama actual",
+	"rename.short":        "Renombra la rama actual conservando las relaciones de la pila",
+	"reparent.short":      "Cambia el padre de la rama actual",
+	"worktree.short":      "Crea un worktree en el directorio .worktrees/",
+	"up.short":            "Cambia a la rama padre en la pila",
+	"down.short":          "Cambia a una rama hija en la pila",
+	"go.short":            "Salta a una rama de la pila por su número",
+	"version.short":       "Muestra información de la versión",
+
+	// Global flags
+	"flag.dryRun":   "Muestra lo que ocurriría sin ejecutar nada",
+	"flag.verbose":  "Muestra salida detallada",
+	"flag.noColor":  "Desactiva la salida en color",
+	"flag.yes":      "Confirma los avisos de primer uso sin preguntar",
+	"flag.chdir":    "Ejecuta como si stack se hubiera iniciado en <ruta> en lugar del directorio actual",
+	"flag.script":   "Con --dry-run, imprime los comandos git/gh como un script de shell en lugar de ejecutarlos",
+	"flag.readOnly": "Falla ante cualquier cambio en el repositorio, su configuración o los PRs (también STACKINATOR_READONLY=1)",
+	"flag.offline":  "Omite fetch, push y GitHub, usando las refs locales y los PRs en caché (también STACKINATOR_OFFLINE=1)",
+
+	// Errors
+	"error":                   "Error: %v",
+	"error.notInRepo":         "Error: no estás en un repositorio git",
+	"error.chdir":             "Error: no se puede cambiar a %s: %v",
+	"error.scriptNeedsDryRun": "Error: --script solo se puede usar con --dry-run",
+	"error.bareRepo":          "Error: este es un repositorio bare; stack necesita un árbol de trabajo (define GIT_WORK_TREE o ejecútalo desde un worktree)",
+
+	// Dry-run summary
+	"dryRun.summary":   "Simulación: se omitieron %s",
+	"dryRun.nothing":   "Simulación: no cambiaría nada",
+	"dryRun.pushes":    "%d push(es)",
+	"dryRun.deletions": "%d borrado(s)",
+	"dryRun.config":    "%d escritura(s) de config",
+	"dryRun.prEdits":   "%d cambio(s) de PR",
+	"dryRun.other":     "%d cambio(s) más",
+
+	// Shared stack messages
+	"stack.noBranches":    "No se encontraron ramas de pila.",
+	"stack.currentBranch": "Rama actual: %s",
+	"stack.createHint":    "Usa '%s' para crear una nueva rama de pila.",
+
+	// Navigation
+	"parent.notInStack": "(no está en una pila)",
+	"up.atRoot":         "ya estás en la raíz de la pila (%s no tiene padre)",
+	"up.switched":       "Cambiado a la rama padre: %s",
+	"down.noChildren":   "no hay ramas hijas (extremo de la pila)",
+	"down.multiple":     "Se encontraron varias ramas hijas de %s:",
+	"down.select":       "Selecciona una rama (1-%d): ",
+	"down.switched":     "Cambiado a la rama hija: %s",
+	"go.notInStack":     "%s no está en una pila",
+	"go.outOfRange":     "número de rama no válido %q (1-%d)",
+	"go.switched":       "Cambiado a %s (%d/%d)",
+
+	// Sync
+	"sync.conflict.detected":    "Conflicto de rebase detectado. Para continuar:",
+	"conflict.resolve":          "1. Resuelve los conflictos",
+	"conflict.add":              "2. Ejecuta 'git add <archivos resueltos>'",
+	"conflict.continue":         "3. Ejecuta 'git rebase --continue'",
+	"sync.conflict.resume":      "4. Ejecuta 'stack sync --resume'",
+	"sync.conflict.abortHeader": "O para abortar la sincronización:",
+	"sync.conflict.abort":       "Ejecuta 'stack sync --abort'",
+	"sync.conflict.stashNote":   "Nota: tus cambios sin confirmar se guardaron en el stash y se restaurarán al ejecutar --resume o --abort",
+	"sync.possibleCause":        "Posible causa:",
+	"sync.pushHint":             "La rama remota se actualizó después del fetch; vuelve a ejecutar 'stack sync'",
+	"sync.complete":             "¡Sincronización completa!",
+
+	// Status
+	"status.outOfSync": "Se detectó que la pila no está sincronizada:",
+	"status.runSync":   "Ejecuta '%s' para hacer rebase de las ramas y actualizar las bases de los PRs.",
+	"status.synced":    "¡La pila está perfectamente sincronizada! Todas las ramas están al día.",
+
+	"status.inProgress":         "%s en curso en %s: resuélvelo o abórtalo antes de sincronizar",
+	"status.inProgressDetached": "%s en curso: resuélvelo o abórtalo antes de sincronizar",
+	"status.inProgressHint":     "  Continúa con '%s' o aborta con '%s'",
+
+	"status.interruptedSync":       "Hay una sincronización interrumpida (iniciada hace %s en %s): reanúdala o abórtala",
+	"status.interruptedSyncNoTime": "Hay una sincronización interrumpida (iniciada en %s): reanúdala o abórtala",
+
+	// Prune
+	"prune.complete": "¡Limpieza completa!",
+
+	// Error hints
+	"hint.conflict":                 "Resuelve los conflictos y ejecuta '%s', o ejecuta '%s'",
+	"hint.auth":                     "Inicia sesión con '%s', o comprueba la cuenta en uso con '%s'",
+	"hint.offline":                  "Esto necesita conexión; vuelve a ejecutarlo cuando estés en línea",
+	"hint.notInStack":               "Añádela a una pila con '%s'.",
+	"rebase.planInProgress":         "hay un plan de rebase en curso\n\nUsa '%s' o '%s'",
+	"rebase.planAlreadyInProgress":  "ya hay un plan de rebase en curso\n\nUsa '%s' o '%s'",
+	"rebase.nothingToDo":            "no hay nada que hacer\n\nUsa '%s' para editar la pila",
+	"rebase.stillInProgress":        "todavía hay un rebase en curso\n\nResuelve los conflictos y ejecuta '%s' primero",
+	"sync.noneToAbort":              "no hay ninguna sincronización interrumpida que abortar\n\nUsa 'stack sync' para empezar una nueva",
+	"sync.noneToResume":             "no hay ninguna sincronización interrumpida que reanudar\n\nUsa 'stack sync' para empezar una nueva",
+	"sync.resumeHint":               "Si resolviste conflictos de rebase, ejecuta 'stack sync --resume'",
+	"sync.startFreshAborted":        "Cancelado. Usa 'stack sync --resume' o 'stack sync --abort' para gestionar la sincronización interrumpida.",
+	"recover.pending":               "un %s no terminó; ejecuta '%s' para terminarlo, o '%s' para deshacerlo",
+	"recover.stopped":               "El %s se detuvo a medias. Ejecuta '%s' para terminarlo, o '%s' para deshacerlo",
+	"recover.bothExist":             "existen %s y %s; borra la que sobra y vuelve a ejecutar '%s'",
+	"undo.unfinished":               "el %s no terminó, así que sus cambios no se pueden distinguir de los posteriores\n\nUsa '%s' para devolver cada rama que cambió desde que empezó",
+	"undo.changedSince":             "%s cambió desde el %s, deshacerlo descartaría ese cambio\n\nUsa '%s' para deshacerlo igualmente",
+	"ui.needsTerminal":              "stack ui necesita una terminal; usa 'stack status' en scripts",
+	"conflict.rebaseStopped":        "El rebase se detuvo. Para continuar:",
+	"conflict.rebaseOn":             "Conflicto de rebase en %s. Para continuar:",
+	"conflict.cherryPickOn":         "Conflicto de cherry-pick en %s. Para continuar:",
+	"conflict.cherryPickContinue":   "3. Ejecuta 'git cherry-pick --continue'",
+	"absorb.conflict.abort":         "O ejecuta 'git rebase --abort' para dejar los commits de fixup como están",
+	"absorb.conflict.restack":       "Después reapila las ramas de encima con 'stack sync'",
+	"fixup.conflict.abort":          "O ejecuta 'git rebase --abort' para dejar el commit de fixup como está",
+	"pick.conflict.rest":            "4. Ejecuta 'git cherry-pick -x %s'",
+	"pick.conflict.abort":           "O ejecuta 'git cherry-pick --abort' para dejar de aplicar commits",
+	"rebase.conflict.continue":      "4. Ejecuta 'stack rebase --continue'",
+	"rebase.conflict.abort":         "Para restaurar en su lugar todas las ramas:",
+	"restack.conflict.stopped":      "El rebase de %s se detuvo por conflictos. Para continuar:",
+	"restack.conflict.resolve":      "1. Resuelve los conflictos y ejecuta 'git add <archivos resueltos>'",
+	"restack.conflict.continue":     "2. Ejecuta 'git rebase --continue'",
+	"restack.conflict.push":         "3. Ejecuta 'stack restack %s' para subirla",
+	"restack.conflict.abort":        "O ejecuta 'git rebase --abort' para dejar %s como estaba.",
+	"am.conflict.stopped":           "Un parche no se pudo aplicar. Para continuar:",
+	"am.conflict.resolve":           "1. Resuelve los conflictos y haz 'git add' de los archivos",
+	"am.conflict.continue":          "2. Ejecuta '%s'",
+	"am.conflict.rerun":             "3. Ejecuta '%s' para crear las ramas restantes",
+	"am.conflict.abort":             "O empieza la rama de nuevo con '%s' y '%s'",
+	"sync.cherryPick.rest":          "4. Completa a mano los cherry-picks restantes",
+	"sync.cherryPick.rename":        "5. Ejecuta 'git branch -D %s && git branch -m %s'",
+	"sync.cherryPick.resume":        "6. Ejecuta 'stack sync --resume'",
+	"sync.cherryPick.recommended":   "Recomendado: ejecuta 'stack sync --cherry-pick' para reconstruirla automáticamente",
+	"sync.conflict.lfs":             "Los archivos LFS se dejaron como punteros (--no-lfs-smudge); ejecuta 'git lfs pull' al terminar.",
+	"adopt.syncHint":                "Ejecuta '%s' para reapilarlas sobre sus padres.",
+	"base.syncHint":                 "Ejecuta '%s' para rebasar las pilas movidas sobre %s.",
+	"check.notFetched":              "%s tiene commits que aún no se han obtenido; ejecuta '%s' primero",
+	"check.remoteNewer":             "%s tiene commits más nuevos que la rama local; ejecuta '%s' primero",
+	"import.statusHint":             "Ejecuta '%s' en cualquier rama importada para ver su pila.",
+	"land.wrongBase":                "el PR #%d apunta a %s en lugar de %s, ejecuta '%s' primero",
+	"land.severalChildren":          "Hay %d ramas apiladas sobre %s; ejecuta '%s' desde cada una para reapilarla",
+	"land.differs":                  "%s difiere de %s, ejecuta '%s' antes de fusionarla",
+	"land.notMerged":                "el PR #%d no se ha fusionado tras %s, ejecuta '%s' cuando lo esté",
+	"mergeCheck.parentMerged":       "El padre %s de %s se fusionó: ejecuta '%s' para moverla sobre %s",
+	"prune.forceHint":               "Usa '%s' para forzar el borrado, o bórrala a mano con: %s",
+	"rebase.loadPRsFailed":          "Aviso: no se pudieron cargar los PRs, ejecuta 'stack sync' para cambiar su base: %v",
+	"rebase.prNotOpen":              "El PR #%d ahora está %s, ejecuta 'stack sync' para mover las ramas de encima",
+	"share.pushFailed":              "no se pudo subir %s (si alguien compartió a la vez, vuelve a ejecutar '%s')",
+	"share.fetchHint":               "Tus compañeros pueden ejecutar '%s' para seguir las ramas que abran.",
+	"fetchMetadata.missing":         "%d rama(s) compartida(s) no están aquí; ábrelas y vuelve a ejecutar '%s' para seguirlas.",
+	"staleness.syncHint":            "Ejecuta '%s' para reapilar antes de que se aleje más.",
+	"submit.prNotOpen":              "El PR #%d está %s, ejecuta '%s' para reapilar sobre él",
+	"submit.differs":                "Difiere de %s, no se sube (ejecuta '%s' para subirla con force push)",
+	"worktree.pruneHint":            "Consejo: ejecuta '%s' para borrar también las ramas fusionadas.",
+	"sync.keepBaseHint":             "Para conservar %s en su lugar, ejecuta después '%s' en %s",
+	"sync.ciHeldHint":               "Corrige la rama que falla y vuelve a ejecutar '%s' para subirlas.",
+	"sync.lfsPullHint":              "Ejecuta '%s' para sustituir los archivos puntero por su contenido",
+	"sync.submodulesHint":           "Ejecuta '%s' para cambiar a los commits registrados,",
+	"sync.submodulesConfigHint":     "o define '%s' para hacerlo tras cada cambio de rama.",
+	"sync.submoduleConflictHint":    "En cada uno, cambia dentro del submódulo al commit que quieras conservar",
+	"sync.submoduleConflictExample": "(p. ej. 'git -C <ruta> checkout <commit>'), y luego ejecuta 'git add <ruta>'.",
+	"sync.stashPopHint":             "Ejecuta '%s' a mano para restaurar tus cambios",
+	"sync.nothingChanged":           "Nada cambió desde la última sincronización (usa '%s' para recorrer la pila igualmente)",
+	"sync.offlineHint":              "Vuelve a ejecutar '%s' cuando tengas conexión para subir y actualizar los PRs",
+}
@@ -0,0 +1,195 @@
+// Package oplog records the stack as it was before each sync, restack and
+// reparent, and as the operation left it, for 'stack undo' to put it back.
+package oplog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// File is where the log is kept, one JSON object per line, relative to the git
+// directory shared by all worktrees
+const File = "stackinator/oplog"
+
+// Size is how many operations the log keeps
+const Size = 50
+
+// Entry is the stack as it was before an operation, and as the operation left it
+type Entry struct {
+	Operation string            `json:"operation"` // "sync", "restack" or "reparent"
+	StartedAt time.Time         `json:"startedAt"`
+	Branch    string            `json:"branch"`  // Branch the operation ran on
+	Tips      map[string]string `json:"tips"`    // Commit of each branch
+	Parents   map[string]string `json:"parents"` // Parent of each branch, empty when untracked
+
+	// Set once the operation finished, for the same branches: the commit of each
+	// (empty when deleted) and its parent. Nil while it hasn't.
+	After        map[string]string `json:"after,omitempty"`
+	AfterParents map[string]string `json:"afterParents,omitempty"`
+}
+
+// Describe says what the operation was and when
+func (e *Entry) Describe() string {
+	return fmt.Sprintf("%s on %s (%s ago)", e.Operation, ui.Branch(e.Branch), ui.Age(time.Since(e.StartedAt)))
+}
+
+func logPath(gitClient git.GitClient) (string, error) {
+	dir, err := gitClient.GetCommonDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the git directory: %w", err)
+	}
+	return filepath.Join(dir, File), nil
+}
+
+// Read returns the recorded operations, oldest first
+func Read(gitClient git.GitClient) ([]Entry, error) {
+	path, err := logPath(gitClient)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var entries []Entry
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Write replaces the log with entries, keeping the last Size
+func Write(gitClient git.GitClient, entries []Entry) error {
+	path, err := logPath(gitClient)
+	if err != nil {
+		return err
+	}
+	if len(entries) > Size {
+		entries = entries[len(entries)-Size:]
+	}
+
+	var lines []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode the operation log: %w", err)
+		}
+		lines = append(append(lines, line...), '\n')
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, lines, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Record snapshots every stack branch before operation changes them. Branches in
+// also are recorded even if they aren't in a stack yet. A failure only warns, as
+// the operation itself can still go ahead. Nothing is recorded in a dry run, as
+// nothing changes.
+func Record(gitClient git.GitClient, operation, branch string, also ...string) {
+	if git.DryRun {
+		return
+	}
+	if err := appendEntry(gitClient, operation, branch, also); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the %s for 'stack undo': %v\n", operation, err)
+	}
+}
+
+func appendEntry(gitClient git.GitClient, operation, branch string, also []string) error {
+	entries, err := Read(gitClient)
+	if err != nil {
+		return err
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return err
+	}
+	tips, err := gitClient.GetBranchTips()
+	if err != nil {
+		return err
+	}
+
+	entry := Entry{
+		Operation: operation,
+		StartedAt: time.Now(),
+		Branch:    branch,
+		Tips:      make(map[string]string),
+		Parents:   make(map[string]string),
+	}
+	for name, parent := range parents {
+		entry.Parents[name] = parent
+	}
+	for _, name := range also {
+		entry.Parents[name] = parents[name]
+	}
+	for name := range entry.Parents {
+		if tip, ok := tips[name]; ok {
+			entry.Tips[name] = tip
+		}
+	}
+	return Write(gitClient, append(entries, entry))
+}
+
+// Finish records what the last recorded operation left the branches at, so
+// 'stack undo' only puts back what it changed. A failure only warns, as undo
+// then asks for --force instead.
+func Finish(gitClient git.GitClient, operation string) {
+	if git.DryRun {
+		return
+	}
+	if err := complete(gitClient, operation); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the %s for 'stack undo': %v\n", operation, err)
+	}
+}
+
+func complete(gitClient git.GitClient, operation string) error {
+	entries, err := Read(gitClient)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	entry := &entries[len(entries)-1]
+	if entry.Operation != operation || entry.After != nil {
+		return nil
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return err
+	}
+	tips, err := gitClient.GetBranchTips()
+	if err != nil {
+		return err
+	}
+
+	entry.After = make(map[string]string)
+	entry.AfterParents = make(map[string]string)
+	for name := range entry.Parents {
+		entry.After[name] = tips[name]
+		entry.AfterParents[name] = parents[name]
+	}
+	return Write(gitClient, entries)
+}
@@ -0,0 +1,79 @@
+package oplog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecord(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	commonDir := t.TempDir()
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCommonDir").Return(commonDir, nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+	mockGit.On("GetBranchTips").Return(map[string]string{
+		"main": "sha-main", "feature-a": "sha-a", "feature-b": "sha-b", "scratch": "sha-s", "origin/feature-a": "sha-a",
+	}, nil)
+
+	Record(mockGit, "sync", "feature-b")
+	Record(mockGit, "reparent", "scratch", "scratch")
+
+	entries, err := Read(mockGit)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "sync", entries[0].Operation)
+	assert.Equal(t, map[string]string{"feature-a": "sha-a", "feature-b": "sha-b"}, entries[0].Tips)
+	assert.Equal(t, map[string]string{"feature-a": "main", "feature-b": "feature-a", "scratch": ""}, entries[1].Parents)
+	assert.Equal(t, "sha-s", entries[1].Tips["scratch"])
+	assert.Nil(t, entries[1].After)
+}
+
+func TestFinish(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil).Once()
+	mockGit.On("GetBranchTips").Return(map[string]string{"feature-a": "sha-a", "feature-b": "sha-b"}, nil).Once()
+	Record(mockGit, "sync", "feature-b")
+
+	// The sync rebased feature-b and deleted feature-a after it merged
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-b": "main"}, nil)
+	mockGit.On("GetBranchTips").Return(map[string]string{"feature-b": "new-b"}, nil)
+	Finish(mockGit, "restack")
+	entries, _ := Read(mockGit)
+	assert.Nil(t, entries[0].After, "only the operation that recorded itself")
+
+	Finish(mockGit, "sync")
+
+	entries, err := Read(mockGit)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"feature-a": "", "feature-b": "new-b"}, entries[0].After)
+	assert.Equal(t, map[string]string{"feature-a": "", "feature-b": "main"}, entries[0].AfterParents)
+}
+
+func TestWriteKeepsTheLatest(t *testing.T) {
+	commonDir := t.TempDir()
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetCommonDir").Return(commonDir, nil)
+
+	entries := make([]Entry, Size+5)
+	for i := range entries {
+		entries[i] = Entry{Operation: "sync", Branch: string(rune('a' + i%26))}
+	}
+	assert.NoError(t, Write(mockGit, entries))
+
+	read, err := Read(mockGit)
+	assert.NoError(t, err)
+	assert.Len(t, read, Size)
+	assert.Equal(t, entries[5].Branch, read[0].Branch)
+	_, err = os.Stat(filepath.Join(commonDir, File))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,85 @@
+package script
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Enabled records dry-run mutations as shell commands instead of printing them inline
+var Enabled = false
+
+// WorkDir is emitted as a leading cd so the script runs in the same repository
+var WorkDir = ""
+
+var (
+	mu       sync.Mutex
+	commands []string
+)
+
+// safeArg matches arguments that don't need quoting in a POSIX shell
+var safeArg = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// Record adds a command to the script, quoting each argument for the shell
+func Record(name string, args ...string) {
+	quoted := make([]string, 0, len(args)+1)
+	quoted = append(quoted, Quote(name))
+	for _, arg := range args {
+		quoted = append(quoted, Quote(arg))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	commands = append(commands, strings.Join(quoted, " "))
+}
+
+// Line adds a raw shell line, for steps that aren't a single command (e.g. a
+// redirection). Arguments must already be quoted with Quote.
+func Line(line string) {
+	mu.Lock()
+	defer mu.Unlock()
+	commands = append(commands, line)
+}
+
+// Reset clears all recorded lines
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	commands = nil
+}
+
+// Write writes the recorded commands as a shell script that stops at the first failure
+func Write(w io.Writer, title string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var b strings.Builder
+	b.WriteString("#!/bin/sh\n")
+	if title != "" {
+		fmt.Fprintf(&b, "# %s\n", title)
+	}
+	b.WriteString("set -e\n\n")
+	if WorkDir != "" {
+		fmt.Fprintf(&b, "cd %s\n", Quote(WorkDir))
+	}
+	if len(commands) == 0 {
+		b.WriteString("# Nothing to do\n")
+	}
+	for _, c := range commands {
+		b.WriteString(c)
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Quote returns arg quoted for a POSIX shell, leaving simple words unquoted
+func Quote(arg string) string {
+	if safeArg.MatchString(arg) {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
@@ -0,0 +1,64 @@
+package script
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected string
+	}{
+		{"feature/login", "feature/login"},
+		{"branch.feature-a.stackparent", "branch.feature-a.stackparent"},
+		{"--force-with-lease=feature:abc123", "--force-with-lease=feature:abc123"},
+		{"stack sync", "'stack sync'"},
+		{"it's", `'it'\''s'`},
+		{"", "''"},
+		{"$HOME", "'$HOME'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Quote(tt.arg))
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	Reset()
+	defer Reset()
+	defer func() { WorkDir = "" }()
+
+	WorkDir = "/tmp/my repo"
+	Record("git", "checkout", "feature-a")
+	Line("echo .worktrees >> " + Quote("/tmp/my repo/.gitignore"))
+	Record("gh", "pr", "comment", "12", "--body", "Rebased onto `main`")
+
+	var out bytes.Buffer
+	err := Write(&out, "stack sync")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `#!/bin/sh
+# stack sync
+set -e
+
+cd '/tmp/my repo'
+git checkout feature-a
+echo .worktrees >> '/tmp/my repo/.gitignore'
+gh pr comment 12 --body 'Rebased onto `+"`main`"+`'
+`, out.String())
+}
+
+func TestWriteEmpty(t *testing.T) {
+	Reset()
+
+	var out bytes.Buffer
+	err := Write(&out, "")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\nset -e\n\n# Nothing to do\n", out.String())
+}
@@ -0,0 +1,72 @@
+package spinner
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// progressBarWidth is the number of cells in the overall progress bar
+const progressBarWidth = 10
+
+// Progress tracks the overall progress of a multi-item operation (e.g. syncing
+// each branch of a stack). Steps run through Progress show a persistent header
+// line with the overall progress above the spinner for the current sub-step.
+type Progress struct {
+	mu      sync.Mutex
+	title   string
+	total   int
+	current int
+	label   string
+}
+
+// NewProgress creates a progress tracker for total items
+func NewProgress(title string, total int) *Progress {
+	return &Progress{title: title, total: total}
+}
+
+// Next advances to the next item, labelled with label (e.g. the branch name)
+func (p *Progress) Next(label string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current < p.total {
+		p.current++
+	}
+	p.label = label
+}
+
+// Header returns the overall progress line, e.g. "Syncing [███░░░░░░░] 2/5 feature-b"
+func (p *Progress) Header() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	filled := 0
+	if p.total > 0 {
+		// Count the current item as in progress, not done
+		filled = (p.current - 1) * progressBarWidth / p.total
+		if filled < 0 {
+			filled = 0
+		}
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return dim.Sprintf("%s %s %d/%d %s", p.title, bar, p.current, p.total, p.label)
+}
+
+// Step runs fn with an indented spinner for the current sub-step, drawn below the
+// overall progress header. Both lines are cleared when the step finishes and
+// replaced by the success or error message.
+func (p *Progress) Step(indent, message, successMessage string, fn func() error) error {
+	if !Enabled {
+		return WrapWithSuccessIndented(indent, message, successMessage, fn)
+	}
+	sp := New(indent + message).WithHeader(p.Header).Start()
+	err := fn()
+	if err != nil {
+		sp.Stop(fmt.Sprintf("%s%s %s: %v", indent, ui.ErrorIcon(), message, err))
+		return err
+	}
+	sp.Stop(fmt.Sprintf("%s%s %s", indent, ui.SuccessIcon(), successMessage))
+	return nil
+}
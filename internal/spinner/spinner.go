@@ -8,35 +8,111 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/mattn/go-isatty"
 )
 
 // Enabled controls whether spinners are displayed (disabled in verbose mode)
 var Enabled = true
 
-// Spinner represents a loading spinner
+// Accessible replaces animated frames with timestamped plain-text progress lines,
+// for screen readers and dumb terminals. Message updates are printed as new lines
+// instead of overwriting the current line.
+var Accessible = false
+
+// AccessibleInterval is how often a still-running operation is re-announced in accessible mode
+var AccessibleInterval = 5 * time.Second
+
+// Output streams, overridable in tests
+var (
+	stderr io.Writer = os.Stderr
+	stdout io.Writer = os.Stdout
+)
+
+// SetStdout redirects final result messages, e.g. to keep stdout free for
+// machine-readable output
+func SetStdout(w io.Writer) {
+	stdout = w
+}
+
+// IsTerminal reports whether both stdout and stderr are attached to a terminal.
+// Spinners are drawn on stderr, but animating while stdout is piped (e.g. into
+// less) would still interleave with the real output, so both must be terminals.
+func IsTerminal() bool {
+	return isTerminal(os.Stdout) && isTerminal(os.Stderr)
+}
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// Terminal writes from all spinners are serialized, and only the most recently
+// shown spinner draws frames. This keeps nested spinners from fighting over the
+// same line: the outer one pauses until the inner one stops.
+var (
+	outputMu sync.Mutex
+	visible  []*Spinner
+)
+
+// commandKind identifies a request sent to the spinner goroutine
+type commandKind int
+
+const (
+	cmdUpdate commandKind = iota
+	cmdStop
+)
+
+// command is a request to the spinner goroutine
+type command struct {
+	kind    commandKind
+	message string
+	done    chan struct{} // closed once a stop has been fully processed
+}
+
+// spinnerState tracks the lifecycle of a Spinner
+type spinnerState int
+
+const (
+	stateNew spinnerState = iota
+	stateRunning
+	stateStopped
+)
+
+// Spinner represents a loading spinner.
+//
+// All drawing happens on a single goroutine that owns the message and frame state;
+// Start, UpdateMessage and Stop communicate with it over a channel, so they are safe
+// to call from any goroutine.
 type Spinner struct {
 	message      string
 	frames       []string
 	interval     time.Duration
-	writer       io.Writer
-	stopChan     chan struct{}
-	stopped      bool
-	mu           sync.Mutex
+	delay        time.Duration
+	writer       io.Writer // transient spinner frames (stderr)
+	out          io.Writer // final result messages (stdout)
 	hideWhenDone bool
-	wg           sync.WaitGroup
+	accessible   bool
+	header       func() string // optional persistent line drawn above the spinner
+	lines        int           // lines currently drawn (owned by the spinner goroutine)
+
+	mu    sync.Mutex // guards state, and message before the goroutine starts
+	state spinnerState
+	cmds  chan command
+	done  chan struct{} // closed when the goroutine exits
 }
 
-var defaultFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-
 // New creates a new spinner with the given message
 func New(message string) *Spinner {
 	return &Spinner{
 		message:      message,
-		frames:       defaultFrames,
+		frames:       ui.SpinnerFrames(),
 		interval:     80 * time.Millisecond,
-		writer:       os.Stdout,
-		stopChan:     make(chan struct{}),
+		writer:       stderr,
+		out:          stdout,
 		hideWhenDone: false,
+		accessible:   Accessible,
+		cmds:         make(chan command),
+		done:         make(chan struct{}),
 	}
 }
 
@@ -46,75 +122,218 @@ func (s *Spinner) HideWhenDone() *Spinner {
 	return s
 }
 
+// WithHeader draws an extra line above the spinner with overall progress.
+// The header is re-read on every frame, so it can change while the spinner runs.
+func (s *Spinner) WithHeader(header func() string) *Spinner {
+	s.header = header
+	return s
+}
+
 // Start starts the spinner
 func (s *Spinner) Start() *Spinner {
-	if Enabled {
-		s.wg.Add(1)
-		go s.run()
+	return s.StartAfter(0)
+}
+
+// StartAfter starts the spinner but only shows it once delay has passed.
+// If Stop is called before then, nothing is drawn.
+func (s *Spinner) StartAfter(delay time.Duration) *Spinner {
+	if !Enabled {
+		return s
 	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != stateNew {
+		return s
+	}
+	s.state = stateRunning
+	s.delay = delay
+	go s.loop(s.message)
 	return s
 }
 
-// Stop stops the spinner and optionally shows a final message
+// Stop stops the spinner and optionally shows a final message.
+// It returns once the spinner line has been cleared and the message printed.
 func (s *Spinner) Stop(finalMessage string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	prev := s.state
+	s.state = stateStopped
+	s.mu.Unlock()
 
-	if s.stopped {
+	switch prev {
+	case stateStopped:
+		return
+	case stateNew:
+		// Never started (e.g. spinners disabled) - just print the final message
+		s.printFinal(finalMessage)
 		return
 	}
 
-	s.stopped = true
+	done := make(chan struct{})
+	s.cmds <- command{kind: cmdStop, message: finalMessage, done: done}
+	<-done
+}
 
-	if Enabled {
-		close(s.stopChan)
-		s.mu.Unlock() // Unlock so the goroutine can finish
+// UpdateMessage updates the spinner message while it's running
+func (s *Spinner) UpdateMessage(message string) {
+	s.mu.Lock()
+	if s.state != stateRunning {
+		if s.state == stateNew {
+			s.message = message
+		}
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Unlock()
 
-		// Wait for the goroutine to fully finish
-		s.wg.Wait()
+	// The goroutine may exit concurrently if Stop races with this update
+	select {
+	case s.cmds <- command{kind: cmdUpdate, message: message}:
+	case <-s.done:
+	}
+}
 
-		s.mu.Lock() // Re-lock for the rest of the function
+// loop is the spinner goroutine. It owns message, frame and visibility state.
+func (s *Spinner) loop(message string) {
+	defer close(s.done)
+
+	var (
+		shown    bool
+		frameIdx int
+		started  = time.Now()
+		ticker   *time.Ticker
+		tick     <-chan time.Time
+		delayed  <-chan time.Time
+	)
+
+	show := func() {
+		shown = true
+		outputMu.Lock()
+		visible = append(visible, s)
+		outputMu.Unlock()
+
+		interval := s.interval
+		if s.accessible {
+			interval = AccessibleInterval
+			s.announce(message, "")
+		}
+		ticker = time.NewTicker(interval)
+		tick = ticker.C
+	}
 
-		// Clear the line and move cursor to beginning
-		fmt.Fprint(s.writer, "\r\033[K")
+	if s.delay > 0 {
+		timer := time.NewTimer(s.delay)
+		defer timer.Stop()
+		delayed = timer.C
+	} else {
+		show()
+	}
 
-		// Flush to ensure clearing is processed immediately
-		if f, ok := s.writer.(interface{ Sync() error }); ok {
-			_ = f.Sync()
+	for {
+		select {
+		case <-delayed:
+			delayed = nil
+			show()
+
+		case <-tick:
+			if s.accessible {
+				s.announce(message, fmt.Sprintf(" (still working, %ds)", int(time.Since(started).Seconds())))
+			} else {
+				s.draw(s.frames[frameIdx%len(s.frames)], message)
+				frameIdx++
+			}
+
+		case cmd := <-s.cmds:
+			switch cmd.kind {
+			case cmdUpdate:
+				changed := cmd.message != message
+				message = cmd.message
+				// In accessible mode every change is announced on its own line
+				if shown && s.accessible && changed {
+					s.announce(message, "")
+				}
+
+			case cmdStop:
+				if shown {
+					ticker.Stop()
+					s.hide()
+				}
+				s.printFinal(cmd.message)
+				close(cmd.done)
+				return
+			}
 		}
 	}
+}
 
-	// Print final message if not hiding
-	if !s.hideWhenDone && finalMessage != "" {
-		fmt.Fprintln(s.writer, finalMessage)
+// draw renders one frame, but only if this is the innermost visible spinner
+func (s *Spinner) draw(frame, message string) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if len(visible) == 0 || visible[len(visible)-1] != s {
+		return
 	}
-}
 
-// UpdateMessage updates the spinner message while it's running
-func (s *Spinner) UpdateMessage(message string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.message = message
+	// Move back to the first line we drew before redrawing
+	if s.lines > 1 {
+		fmt.Fprintf(s.writer, "\r\033[%dA", s.lines-1)
+	}
+	if s.header != nil {
+		fmt.Fprintf(s.writer, "\r\033[K%s\n", s.header())
+		s.lines = 2
+	} else {
+		s.lines = 1
+	}
+	fmt.Fprintf(s.writer, "\r\033[K%s %s", frame, dim.Sprint(message))
 }
 
-func (s *Spinner) run() {
-	defer s.wg.Done()
-	frameIdx := 0
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+// announce prints the message as a timestamped line (accessible mode)
+func (s *Spinner) announce(message, suffix string) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	if s.header != nil {
+		message = s.header() + ": " + message
+	}
+	fmt.Fprintf(s.writer, "[%s] %s%s\n", time.Now().Format("15:04:05"), message, suffix)
+}
 
-	for {
-		select {
-		case <-s.stopChan:
-			return
-		case <-ticker.C:
-			s.mu.Lock()
-			frame := s.frames[frameIdx%len(s.frames)]
-			fmt.Fprintf(s.writer, "\r%s %s", frame, dim.Sprint(s.message))
-			s.mu.Unlock()
-			frameIdx++
+// hide removes the spinner from the visible stack and clears its line
+func (s *Spinner) hide() {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	for i, v := range visible {
+		if v == s {
+			visible = append(visible[:i], visible[i+1:]...)
+			break
 		}
 	}
+
+	// Accessible output is line-based, so there is nothing to clear
+	if s.accessible {
+		return
+	}
+
+	// Clear the line and move cursor to beginning, including the header line
+	fmt.Fprint(s.writer, "\r\033[K")
+	for ; s.lines > 1; s.lines-- {
+		fmt.Fprint(s.writer, "\033[1A\033[K")
+	}
+	s.lines = 0
+
+	// Flush to ensure clearing is processed immediately
+	if f, ok := s.writer.(interface{ Sync() error }); ok {
+		_ = f.Sync()
+	}
+}
+
+// printFinal prints the final message if not hiding (this is real output, so it goes to stdout)
+func (s *Spinner) printFinal(finalMessage string) {
+	if s.hideWhenDone || finalMessage == "" {
+		return
+	}
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	fmt.Fprintln(s.out, finalMessage)
 }
 
 // Wrap runs a function with a spinner
@@ -129,52 +348,48 @@ func Wrap(message string, fn func() error) error {
 	return err
 }
 
-// Color helpers for spinner output
-var (
-	green = color.New(color.FgGreen)
-	red   = color.New(color.FgRed)
-	dim   = color.New(color.Faint)
-)
+// dim colors spinner messages; the result glyphs come from the ui theme
+var dim = color.New(color.Faint)
 
 // WrapWithSuccess runs a function with a spinner and shows success/error message
 func WrapWithSuccess(message, successMessage string, fn func() error) error {
 	if !Enabled {
-		// When disabled (verbose mode), print message and run
-		fmt.Println(dim.Sprint(message))
+		// When disabled (verbose mode or not a terminal), print progress to stderr and run
+		fmt.Fprintln(stderr, dim.Sprint(message))
 		err := fn()
 		if err != nil {
-			fmt.Printf("%s Error: %v\n", red.Sprint("✗"), err)
+			fmt.Fprintf(stdout, "%s Error: %v\n", ui.ErrorIcon(), err)
 		}
 		return err
 	}
 	sp := New(message).Start()
 	err := fn()
 	if err != nil {
-		sp.Stop(fmt.Sprintf("%s %s: %v", red.Sprint("✗"), message, err))
+		sp.Stop(fmt.Sprintf("%s %s: %v", ui.ErrorIcon(), message, err))
 		return err
 	}
-	sp.Stop(fmt.Sprintf("%s %s", green.Sprint("✓"), successMessage))
+	sp.Stop(fmt.Sprintf("%s %s", ui.SuccessIcon(), successMessage))
 	return nil
 }
 
 // WrapWithSuccessIndented runs a function with a spinner and shows indented success/error message
 func WrapWithSuccessIndented(indent, message, successMessage string, fn func() error) error {
 	if !Enabled {
-		// When disabled (verbose mode), print message and run
-		fmt.Println(indent + dim.Sprint(message))
+		// When disabled (verbose mode or not a terminal), print progress to stderr and run
+		fmt.Fprintln(stderr, indent+dim.Sprint(message))
 		err := fn()
 		if err != nil {
-			fmt.Printf("%s%s Error: %v\n", indent, red.Sprint("✗"), err)
+			fmt.Fprintf(stdout, "%s%s Error: %v\n", indent, ui.ErrorIcon(), err)
 		}
 		return err
 	}
 	sp := New(indent + message).Start()
 	err := fn()
 	if err != nil {
-		sp.Stop(fmt.Sprintf("%s%s %s: %v", indent, red.Sprint("✗"), message, err))
+		sp.Stop(fmt.Sprintf("%s%s %s: %v", indent, ui.ErrorIcon(), message, err))
 		return err
 	}
-	sp.Stop(fmt.Sprintf("%s%s %s", indent, green.Sprint("✓"), successMessage))
+	sp.Stop(fmt.Sprintf("%s%s %s", indent, ui.SuccessIcon(), successMessage))
 	return nil
 }
 
@@ -188,83 +403,22 @@ func WrapWithAutoDelay(message string, delay time.Duration, fn func() error) err
 		return fn()
 	}
 
-	// Create a timer and a flag to track if we should show the spinner
-	var sp *Spinner
-	var mu sync.Mutex
-	spinnerStarted := false
-
-	// Start a goroutine that will show the spinner after the delay
-	timer := time.AfterFunc(delay, func() {
-		mu.Lock()
-		defer mu.Unlock()
-		sp = New(message).HideWhenDone().Start()
-		spinnerStarted = true
-	})
-
-	// Run the function (synchronously)
+	sp := New(message).HideWhenDone().StartAfter(delay)
 	err := fn()
-
-	// Stop the timer to prevent spinner from starting if function completed quickly
-	timer.Stop()
-
-	// Small delay to ensure timer goroutine has finished
-	time.Sleep(15 * time.Millisecond)
-
-	// Now safely stop the spinner if it was started
-	mu.Lock()
-	if spinnerStarted && sp != nil {
-		sp.Stop("")
-	}
-	mu.Unlock()
-
+	sp.Stop("")
 	return err
 }
 
-// WrapWithAutoDelayAndProgress runs a function with auto-delay spinner that supports progress updates
+// WrapWithAutoDelayAndProgress runs a function with auto-delay spinner that supports progress updates.
+// Updates made before the spinner appears are kept, so it always shows the latest message.
 func WrapWithAutoDelayAndProgress(message string, delay time.Duration, fn func(progress ProgressFunc) error) error {
 	if !Enabled {
 		// When disabled (verbose mode), just run the function with a no-op progress callback
 		return fn(func(msg string) {})
 	}
 
-	// Create a timer and a flag to track if we should show the spinner
-	var sp *Spinner
-	var mu sync.Mutex
-	spinnerStarted := false
-
-	// Start a goroutine that will show the spinner after the delay
-	timer := time.AfterFunc(delay, func() {
-		mu.Lock()
-		defer mu.Unlock()
-		sp = New(message).HideWhenDone().Start()
-		spinnerStarted = true
-	})
-
-	// Create progress callback that updates spinner if it's running
-	progress := func(msg string) {
-		mu.Lock()
-		defer mu.Unlock()
-		if spinnerStarted && sp != nil {
-			sp.UpdateMessage(msg)
-		}
-		// If spinner hasn't started yet, progress updates are no-ops
-	}
-
-	// Run the function (synchronously) with progress callback
-	err := fn(progress)
-
-	// Stop the timer to prevent spinner from starting if function completed quickly
-	timer.Stop()
-
-	// Small delay to ensure timer goroutine has finished
-	time.Sleep(15 * time.Millisecond)
-
-	// Now safely stop the spinner if it was started
-	mu.Lock()
-	if spinnerStarted && sp != nil {
-		sp.Stop("")
-	}
-	mu.Unlock()
-
+	sp := New(message).HideWhenDone().StartAfter(delay)
+	err := fn(sp.UpdateMessage)
+	sp.Stop("")
 	return err
 }
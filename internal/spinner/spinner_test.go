@@ -0,0 +1,214 @@
+package spinner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// syncBuffer is a goroutine-safe bytes.Buffer
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// captureOutput enables spinners and redirects their output for the duration of a test
+func captureOutput(t *testing.T) (errOut, out *syncBuffer) {
+	t.Helper()
+	errOut, out = &syncBuffer{}, &syncBuffer{}
+
+	prevEnabled, prevAccessible := Enabled, Accessible
+	prevStderr, prevStdout := stderr, stdout
+	Enabled, Accessible = true, false
+	stderr, stdout = errOut, out
+
+	t.Cleanup(func() {
+		Enabled, Accessible = prevEnabled, prevAccessible
+		stderr, stdout = prevStderr, prevStdout
+	})
+	return errOut, out
+}
+
+func TestStopPrintsFinalMessageToStdout(t *testing.T) {
+	errOut, out := captureOutput(t)
+
+	sp := New("Working...").Start()
+	time.Sleep(100 * time.Millisecond)
+	sp.Stop("done")
+
+	assert.Equal(t, "done\n", out.String())
+	assert.Contains(t, errOut.String(), "Working...")
+	// Line is cleared on stop
+	assert.True(t, strings.HasSuffix(errOut.String(), "\r\033[K"))
+}
+
+func TestStopIsIdempotent(t *testing.T) {
+	_, out := captureOutput(t)
+
+	sp := New("Working...").Start()
+	sp.Stop("done")
+	sp.Stop("again")
+
+	assert.Equal(t, "done\n", out.String())
+}
+
+func TestStopWithoutStart(t *testing.T) {
+	errOut, out := captureOutput(t)
+
+	sp := New("Working...")
+	sp.UpdateMessage("ignored")
+	sp.Stop("done")
+
+	assert.Equal(t, "done\n", out.String())
+	assert.Empty(t, errOut.String())
+}
+
+func TestStartAfterDoesNotDrawWhenStoppedEarly(t *testing.T) {
+	errOut, _ := captureOutput(t)
+
+	sp := New("Slow...").HideWhenDone().StartAfter(time.Hour)
+	sp.Stop("")
+
+	assert.Empty(t, errOut.String())
+}
+
+func TestWrapWithAutoDelayFastFunction(t *testing.T) {
+	errOut, out := captureOutput(t)
+
+	err := WrapWithAutoDelay("Loading...", 200*time.Millisecond, func() error {
+		return errors.New("boom")
+	})
+
+	assert.EqualError(t, err, "boom")
+	assert.Empty(t, errOut.String())
+	assert.Empty(t, out.String())
+}
+
+func TestWrapWithAutoDelayAndProgressShowsLatestMessage(t *testing.T) {
+	errOut, _ := captureOutput(t)
+
+	err := WrapWithAutoDelayAndProgress("Checking...", 20*time.Millisecond, func(progress ProgressFunc) error {
+		// Updated before the spinner is visible
+		progress("step 1")
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, errOut.String(), "step 1")
+	assert.NotContains(t, errOut.String(), "Checking...")
+}
+
+func TestConcurrentUpdatesAndStop(t *testing.T) {
+	captureOutput(t)
+
+	for i := 0; i < 20; i++ {
+		sp := New("Working...").Start()
+
+		var wg sync.WaitGroup
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func(j int) {
+				defer wg.Done()
+				for k := 0; k < 20; k++ {
+					sp.UpdateMessage(fmt.Sprintf("worker %d step %d", j, k))
+				}
+			}(j)
+		}
+
+		// Stop while updates may still be in flight; must not deadlock or race
+		sp.Stop("")
+		wg.Wait()
+	}
+}
+
+func TestNestedSpinners(t *testing.T) {
+	errOut, out := captureOutput(t)
+
+	err := WrapWithSuccess("Outer...", "Outer done", func() error {
+		time.Sleep(100 * time.Millisecond)
+		return WrapWithSuccessIndented("  ", "Inner...", "Inner done", func() error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "  ✓ Inner done\n✓ Outer done\n", out.String())
+	assert.Contains(t, errOut.String(), "Inner...")
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	assert.Empty(t, visible)
+}
+
+func TestAccessibleAnnouncesUpdatesAsLines(t *testing.T) {
+	errOut, _ := captureOutput(t)
+	Accessible = true
+
+	sp := New("Fetching...").HideWhenDone().Start()
+	sp.UpdateMessage("Rebasing...")
+	sp.UpdateMessage("Rebasing...") // unchanged, not announced again
+	sp.Stop("")
+
+	lines := strings.Split(strings.TrimSpace(errOut.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "Fetching...")
+		assert.Contains(t, lines[1], "Rebasing...")
+	}
+	assert.NotContains(t, errOut.String(), "\r")
+}
+
+func TestProgressHeader(t *testing.T) {
+	p := NewProgress("Syncing", 4)
+	p.Next("feature-a")
+	assert.Contains(t, p.Header(), "1/4 feature-a")
+	assert.Contains(t, p.Header(), strings.Repeat("░", progressBarWidth))
+
+	p.Next("feature-b")
+	p.Next("feature-c")
+	assert.Contains(t, p.Header(), "3/4 feature-c")
+	assert.Contains(t, p.Header(), strings.Repeat("█", 5))
+
+	// Advancing past the end doesn't overflow the bar
+	p.Next("feature-d")
+	p.Next("extra")
+	assert.Contains(t, p.Header(), "4/4 extra")
+}
+
+func TestProgressStepDrawsAndClearsHeader(t *testing.T) {
+	errOut, out := captureOutput(t)
+
+	p := NewProgress("Syncing", 2)
+	p.Next("feature-a")
+	err := p.Step("  ", "Rebasing onto main...", "Rebased onto main", func() error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.Contains(t, errOut.String(), "1/2 feature-a")
+	assert.Contains(t, errOut.String(), "Rebasing onto main...")
+	// Header line is cleared when the step finishes
+	assert.Contains(t, errOut.String(), "\033[1A\033[K")
+	assert.Contains(t, out.String(), "Rebased onto main")
+	assert.NotContains(t, out.String(), "feature-a")
+}
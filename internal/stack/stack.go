@@ -14,6 +14,15 @@ type StackBranch struct {
 	Exists bool
 }
 
+// NotInStackError is returned for a branch that isn't part of any stack
+type NotInStackError struct {
+	Branch string
+}
+
+func (e *NotInStackError) Error() string {
+	return fmt.Sprintf("%s is not in a stack", e.Branch)
+}
+
 // GetStackBranches returns all branches that are part of a stack
 func GetStackBranches(gitClient git.GitClient) ([]StackBranch, error) {
 	// Fetch all stack parents in one efficient call
@@ -90,6 +99,33 @@ func GetStackChain(gitClient git.GitClient, branch string) ([]string, error) {
 	return chain, nil
 }
 
+// RootOf walks up from branch to the root of its stack, given the parent of each
+// stack branch
+func RootOf(parents map[string]string, branch string) string {
+	seen := make(map[string]bool)
+	for !seen[branch] {
+		seen[branch] = true
+		parent := parents[branch]
+		if _, stacked := parents[parent]; !stacked {
+			return branch
+		}
+		branch = parent
+	}
+	return branch
+}
+
+// Members returns root and all the branches stacked on it, sorted
+func Members(parents map[string]string, root string) []string {
+	var members []string
+	for branch := range parents {
+		if RootOf(parents, branch) == root {
+			members = append(members, branch)
+		}
+	}
+	sort.Strings(members)
+	return members
+}
+
 // TopologicalSort returns branches in bottom-to-top order (base to tips)
 func TopologicalSort(branches []StackBranch) ([]StackBranch, error) {
 	// Build adjacency map
@@ -1,6 +1,9 @@
 package stack
 
 import (
+	"fmt"
+	"math/rand"
+	"sort"
 	"testing"
 
 	"github.com/javoire/stackinator/internal/testutil"
@@ -331,3 +334,134 @@ func TestGetChildrenOf(t *testing.T) {
 	mockGit.AssertExpectations(t)
 }
 
+
+// randomStacks returns the parents of n stack branches with shuffled names,
+// each on main or on a branch made before it
+func randomStacks(r *rand.Rand, n int) map[string]string {
+	names := make([]string, n)
+	for i, j := range r.Perm(n) {
+		names[i] = fmt.Sprintf("b%d", j)
+	}
+	parents := make(map[string]string, n)
+	for i, name := range names {
+		parents[name] = "main"
+		if i > 0 && r.Intn(4) != 0 {
+			parents[name] = names[r.Intn(i)]
+		}
+	}
+	return parents
+}
+
+// addCycle points some of the branches at each other in a loop, and returns them
+func addCycle(r *rand.Rand, parents map[string]string) []string {
+	var names []string
+	for name := range parents {
+		names = append(names, name)
+	}
+	// Map order isn't stable, so pick from sorted names to keep seeds reproducible
+	sort.Strings(names)
+	r.Shuffle(len(names), func(i, j int) { names[i], names[j] = names[j], names[i] })
+	cycle := names[:1+r.Intn(len(names))]
+	for i, name := range cycle {
+		parents[name] = cycle[(i+1)%len(cycle)]
+	}
+	return cycle
+}
+
+func toStackBranches(parents map[string]string) []StackBranch {
+	var branches []StackBranch
+	for name, parent := range parents {
+		branches = append(branches, StackBranch{Name: name, Parent: parent})
+	}
+	return branches
+}
+
+func TestTopologicalSortProperties(t *testing.T) {
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		parents := randomStacks(r, 1+r.Intn(30))
+
+		sorted, err := TopologicalSort(toStackBranches(parents))
+		if !assert.NoError(t, err, "seed %d", seed) {
+			continue
+		}
+
+		// Every branch exactly once, after its parent
+		assert.Len(t, sorted, len(parents), "seed %d", seed)
+		position := make(map[string]int)
+		for i, branch := range sorted {
+			_, seen := position[branch.Name]
+			assert.False(t, seen, "seed %d: %s sorted twice", seed, branch.Name)
+			position[branch.Name] = i
+		}
+		for name, parent := range parents {
+			if parentPos, stacked := position[parent]; stacked {
+				assert.Less(t, parentPos, position[name], "seed %d: %s sorted before its parent %s", seed, name, parent)
+			}
+		}
+
+		// The same branches in another order sort the same way
+		shuffled := toStackBranches(parents)
+		r.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		again, err := TopologicalSort(shuffled)
+		assert.NoError(t, err, "seed %d", seed)
+		assert.Equal(t, sorted, again, "seed %d", seed)
+	}
+}
+
+func TestTopologicalSortDetectsCycles(t *testing.T) {
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		parents := randomStacks(r, 1+r.Intn(30))
+		cycle := addCycle(r, parents)
+
+		_, err := TopologicalSort(toStackBranches(parents))
+
+		assert.Error(t, err, "seed %d: cycle %v", seed, cycle)
+	}
+}
+
+func TestGetStackChainProperties(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		parents := randomStacks(r, 1+r.Intn(30))
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+
+		for name := range parents {
+			chain, err := GetStackChain(mockGit, name)
+			if !assert.NoError(t, err, "seed %d", seed) {
+				continue
+			}
+
+			// From the base, each branch on the one before it, up to the branch
+			assert.Equal(t, "main", chain[0], "seed %d", seed)
+			assert.Equal(t, name, chain[len(chain)-1], "seed %d", seed)
+			for i := 1; i < len(chain); i++ {
+				assert.Equal(t, chain[i-1], parents[chain[i]], "seed %d: chain %v", seed, chain)
+			}
+		}
+	}
+}
+
+func TestGetStackChainDetectsCycles(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	for seed := int64(0); seed < 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		parents := randomStacks(r, 1+r.Intn(30))
+		cycle := addCycle(r, parents)
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+
+		// Every branch of the loop reaches it again
+		for _, name := range cycle {
+			_, err := GetStackChain(mockGit, name)
+			assert.Error(t, err, "seed %d: cycle %v from %s", seed, cycle, name)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// reconcileBaseChanges looks for open PRs whose base was changed on GitHub since
+// the last sync, comparing it with the base recorded then (branch.<name>.stackprbase).
+//
+// When a PR's base branch is deleted, e.g. after it was merged from the web UI,
+// GitHub retargets the PR to the base of the deleted branch's own PR. For a PR
+// moved from the branch's parent to the parent's own base like that, the stack
+// parent is updated to match, unless the parent was merged: the sync moves the
+// branches of merged parents itself. Any other base change is pointed out, as
+// the sync sets the base back to match the stack.
+//
+// It returns the branches whose parent was changed, with their new parent.
+func (e *Engine) reconcileBaseChanges(gitClient git.GitClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, prBases map[string]string, umbrellas map[string]bool, merged map[string]bool) map[string]string {
+	moved := make(map[string]string)
+	if e.options.FlatPRs {
+		return moved
+	}
+
+	parents := make(map[string]string)
+	for _, branch := range branches {
+		parents[branch.Name] = branch.Parent
+	}
+
+	for _, branch := range branches {
+		pr := prCache[branch.Name]
+		recorded := prBases[branch.Name]
+		if pr == nil || pr.State != "OPEN" || recorded == "" || recorded == pr.Base {
+			continue
+		}
+		expected := PRBaseFor(branch.Parent, parents, umbrellas)
+		if pr.Base == expected {
+			continue
+		}
+
+		if grandparent, ok := parents[branch.Parent]; ok && recorded == expected && pr.Base == PRBaseFor(grandparent, parents, umbrellas) {
+			if merged[branch.Parent] {
+				fmt.Printf("PR #%d was retargeted to %s on GitHub after %s was merged\n", pr.Number, ui.Branch(pr.Base), ui.Branch(branch.Parent))
+				continue
+			}
+			if err := gitClient.SetConfig(fmt.Sprintf("branch.%s.stackparent", branch.Name), grandparent); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to set parent of %s: %v\n", branch.Name, err)
+				continue
+			}
+			moved[branch.Name] = grandparent
+			fmt.Printf("%s PR #%d was retargeted to %s on GitHub after %s was deleted, moved %s onto %s to match\n",
+				ui.SuccessIcon(), pr.Number, ui.Branch(pr.Base), ui.Branch(branch.Parent), ui.Branch(branch.Name), ui.Branch(grandparent))
+			continue
+		}
+
+		fmt.Fprintf(os.Stderr, "%s PR #%d was retargeted to %s on GitHub, but the parent of %s is %s: its base will be set back to %s\n",
+			ui.WarningIcon(), pr.Number, ui.Branch(pr.Base), ui.Branch(branch.Name), ui.Branch(branch.Parent), ui.Branch(expected))
+		fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("sync.keepBaseHint", ui.Branch(pr.Base), ui.Command("stack reparent "+pr.Base), ui.Branch(branch.Name)))
+	}
+	return moved
+}
@@ -0,0 +1,98 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestReconcileBaseChanges(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+		{Name: "feature-c", Parent: "feature-b"},
+	}
+
+	t.Run("follows GitHub's retarget after the parent was deleted", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "main").Return(nil)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "CLOSED", "main", "A", ""),
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "B", ""),
+		}
+		prBases := map[string]string{"feature-b": "feature-a"}
+
+		moved := New(mockGit, nil, Options{}, nil).reconcileBaseChanges(mockGit, branches, prCache, prBases, nil, map[string]bool{})
+
+		assert.Equal(t, map[string]string{"feature-b": "main"}, moved)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("leaves branches of merged parents to the sync", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "A", ""),
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "B", ""),
+		}
+		prBases := map[string]string{"feature-b": "feature-a"}
+
+		moved := New(mockGit, nil, Options{}, nil).reconcileBaseChanges(mockGit, branches, prCache, prBases, nil, map[string]bool{"feature-a": true})
+
+		assert.Empty(t, moved)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+
+	t.Run("only warns about other base changes", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		prCache := map[string]*github.PRInfo{
+			"feature-c": testutil.NewPRInfo(3, "OPEN", "release", "C", ""),
+		}
+		prBases := map[string]string{"feature-c": "feature-b"}
+
+		moved := New(mockGit, nil, Options{}, nil).reconcileBaseChanges(mockGit, branches, prCache, prBases, nil, map[string]bool{})
+
+		assert.Empty(t, moved)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+
+	t.Run("ignores bases that already match the stack", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		prCache := map[string]*github.PRInfo{
+			// Retargeted by 'stack reparent', which doesn't record the base
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "B", ""),
+			// Never recorded
+			"feature-c": testutil.NewPRInfo(3, "OPEN", "main", "C", ""),
+		}
+		prBases := map[string]string{"feature-b": "main"}
+
+		moved := New(mockGit, nil, Options{}, nil).reconcileBaseChanges(mockGit, branches, prCache, prBases, nil, map[string]bool{})
+
+		assert.Empty(t, moved)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+
+	t.Run("follows the retarget past an umbrella branch", func(t *testing.T) {
+		umbrellaBranches := []stack.StackBranch{
+			{Name: "umbrella", Parent: "main"},
+			{Name: "feature-a", Parent: "umbrella"},
+			{Name: "feature-b", Parent: "feature-a"},
+		}
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("SetConfig", "branch.feature-b.stackparent", "umbrella").Return(nil)
+		prCache := map[string]*github.PRInfo{
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "B", ""),
+		}
+		prBases := map[string]string{"feature-b": "feature-a"}
+
+		moved := New(mockGit, nil, Options{}, nil).reconcileBaseChanges(mockGit, umbrellaBranches, prCache, prBases, map[string]bool{"umbrella": true}, map[string]bool{})
+
+		assert.Equal(t, map[string]string{"feature-b": "umbrella"}, moved)
+	})
+}
@@ -0,0 +1,152 @@
+package syncer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// DefaultCITimeout is how long sync waits for a branch's checks before holding back the rest
+const DefaultCITimeout = 30 * time.Minute
+
+// ciNoChecksGrace is how long a pushed head may go without any checks before the
+// repo is assumed to have no CI for it
+const ciNoChecksGrace = time.Minute
+
+// ciPollInterval is how often checks are polled, and ciSleep how we wait in
+// between; both are overridden in tests
+var (
+	ciPollInterval = 15 * time.Second
+	ciSleep        = time.Sleep
+)
+
+// ciGate holds back pushes of branches stacked on a branch that was pushed during
+// this sync until that branch's checks pass. If they fail or time out, every
+// branch above it is still rebased locally but not pushed, so CI isn't spent on
+// layers that will be rebased again once the lower one is fixed.
+type ciGate struct {
+	gitClient    git.GitClient
+	githubClient github.GitHubClient
+	timeout      time.Duration
+	progress     *spinner.Progress
+
+	pushed map[string]bool   // branches pushed in this sync
+	passed map[string]bool   // pushed branches whose checks passed
+	held   map[string]string // branches not pushed, with the reason
+}
+
+func newCIGate(gitClient git.GitClient, githubClient github.GitHubClient, timeout time.Duration, progress *spinner.Progress) *ciGate {
+	return &ciGate{
+		gitClient:    gitClient,
+		githubClient: githubClient,
+		timeout:      timeout,
+		progress:     progress,
+		pushed:       make(map[string]bool),
+		passed:       make(map[string]bool),
+		held:         make(map[string]string),
+	}
+}
+
+// markPushed records that branch was pushed during this sync
+func (g *ciGate) markPushed(branch string) {
+	g.pushed[branch] = true
+}
+
+// holdReason returns why branch must not be pushed yet, or "" if it can be.
+// If parent was pushed in this sync, this waits for the checks on parent's PR.
+func (g *ciGate) holdReason(branch, parent string, prCache map[string]*github.PRInfo) string {
+	if reason, ok := g.held[parent]; ok {
+		g.held[branch] = reason
+		return reason
+	}
+	if !g.pushed[parent] || g.passed[parent] {
+		return ""
+	}
+
+	// Without a PR there are no checks to wait for
+	pr := prCache[parent]
+	if pr == nil {
+		return ""
+	}
+
+	if git.DryRun {
+		fmt.Printf("  [DRY RUN] wait for checks on PR #%d (%s) before pushing\n", pr.Number, parent)
+		g.passed[parent] = true
+		return ""
+	}
+
+	headSHA, err := g.gitClient.GetCommitHash(parent)
+	if err != nil {
+		return g.hold(branch, fmt.Sprintf("could not resolve %s: %v", parent, err))
+	}
+
+	var state string
+	_ = g.progress.Step("  ", fmt.Sprintf("Waiting for checks on PR #%d (%s)...", pr.Number, parent), fmt.Sprintf("Checks finished on PR #%d", pr.Number), func() error {
+		state, err = waitForChecks(g.githubClient, pr.Number, headSHA, g.timeout)
+		return err
+	})
+	if err != nil {
+		return g.hold(branch, fmt.Sprintf("could not read checks on PR #%d: %v", pr.Number, err))
+	}
+
+	switch state {
+	case "FAILURE":
+		return g.hold(branch, fmt.Sprintf("checks failed on PR #%d (%s)", pr.Number, parent))
+	case "PENDING":
+		return g.hold(branch, fmt.Sprintf("checks on PR #%d (%s) still running after %s", pr.Number, parent, g.timeout))
+	}
+
+	g.passed[parent] = true
+	return ""
+}
+
+// hold records reason for branch (and so for its descendants) and returns it
+func (g *ciGate) hold(branch, reason string) string {
+	g.held[branch] = reason
+	return reason
+}
+
+// printSummary lists the branches whose push was held back
+func (g *ciGate) printSummary() {
+	if len(g.held) == 0 {
+		return
+	}
+	fmt.Println(ui.Warning(fmt.Sprintf("%d branch(es) were rebased locally but not pushed while waiting for CI.", len(g.held))))
+	fmt.Println(i18n.T("sync.ciHeldHint", ui.Command("stack sync")))
+	fmt.Println()
+}
+
+// waitForChecks polls the checks of a PR until they finish for headSHA or timeout
+// passes. Returns "SUCCESS", "FAILURE", "PENDING" on timeout, or "" when no checks
+// showed up for the head at all.
+func waitForChecks(githubClient github.GitHubClient, prNumber int, headSHA string, timeout time.Duration) (string, error) {
+	start := time.Now()
+	for {
+		pr, err := githubClient.GetPRChecks(prNumber)
+		if err != nil {
+			return "", err
+		}
+
+		// Until GitHub has seen the push, the checks are those of the previous head
+		if pr.HeadSHA == headSHA {
+			switch pr.Checks {
+			case "SUCCESS", "FAILURE":
+				return pr.Checks, nil
+			case "":
+				if time.Since(start) >= ciNoChecksGrace {
+					return "", nil
+				}
+			}
+		}
+
+		if time.Since(start) >= timeout {
+			return "PENDING", nil
+		}
+		ciSleep(ciPollInterval)
+	}
+}
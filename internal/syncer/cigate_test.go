@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForChecks(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	defer func() { ciSleep = time.Sleep }()
+	ciSleep = func(time.Duration) {}
+
+	t.Run("waits until GitHub sees the new head", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRChecks", 5).Return(&github.PRInfo{Number: 5, HeadSHA: "old", Checks: "SUCCESS"}, nil).Once()
+		mockGH.On("GetPRChecks", 5).Return(&github.PRInfo{Number: 5, HeadSHA: "new", Checks: "PENDING"}, nil).Once()
+		mockGH.On("GetPRChecks", 5).Return(&github.PRInfo{Number: 5, HeadSHA: "new", Checks: "FAILURE"}, nil).Once()
+
+		state, err := waitForChecks(mockGH, 5, "new", time.Hour)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "FAILURE", state)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("times out while pending", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRChecks", 5).Return(&github.PRInfo{Number: 5, HeadSHA: "new", Checks: "PENDING"}, nil)
+
+		state, err := waitForChecks(mockGH, 5, "new", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "PENDING", state)
+	})
+}
+
+func TestCIGateHoldReason(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	defer func() { ciSleep = time.Sleep }()
+	ciSleep = func(time.Duration) {}
+
+	prCache := map[string]*github.PRInfo{
+		"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "A", "https://github.com/o/r/pull/1"),
+	}
+
+	t.Run("parent not pushed in this sync", func(t *testing.T) {
+		gate := newCIGate(new(testutil.MockGitClient), new(testutil.MockGitHubClient), time.Hour, spinner.NewProgress("Syncing", 2))
+
+		assert.Empty(t, gate.holdReason("feature-b", "feature-a", prCache))
+	})
+
+	t.Run("failed checks hold back descendants", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("abc", nil)
+		mockGH.On("GetPRChecks", 1).Return(&github.PRInfo{Number: 1, HeadSHA: "abc", Checks: "FAILURE"}, nil)
+		gate := newCIGate(mockGit, mockGH, time.Hour, spinner.NewProgress("Syncing", 3))
+		gate.markPushed("feature-a")
+
+		reason := gate.holdReason("feature-b", "feature-a", prCache)
+
+		assert.Contains(t, reason, "checks failed on PR #1")
+		assert.Equal(t, reason, gate.holdReason("feature-c", "feature-b", prCache))
+		mockGH.AssertNumberOfCalls(t, "GetPRChecks", 1)
+	})
+
+	t.Run("passed checks are only waited for once", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("abc", nil)
+		mockGH.On("GetPRChecks", 1).Return(&github.PRInfo{Number: 1, HeadSHA: "abc", Checks: "SUCCESS"}, nil)
+		gate := newCIGate(mockGit, mockGH, time.Hour, spinner.NewProgress("Syncing", 3))
+		gate.markPushed("feature-a")
+
+		assert.Empty(t, gate.holdReason("feature-b", "feature-a", prCache))
+		assert.Empty(t, gate.holdReason("feature-b2", "feature-a", prCache))
+		mockGH.AssertNumberOfCalls(t, "GetPRChecks", 1)
+	})
+}
@@ -0,0 +1,102 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// runLinter runs the linter command on a commit message; overridden in tests
+var runLinter = func(command, message string) (string, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Dir = git.WorkDir
+	cmd.Stdin = strings.NewReader(message)
+	output, err := cmd.CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+// lintFailure is a commit whose message the linter rejected
+type lintFailure struct {
+	Commit  string
+	Subject string
+	Output  string
+}
+
+// lintStackCommits runs the linter over the commits each branch adds on top of its
+// parent, before anything is rebased or pushed. Merged branches aren't checked.
+// Failures are reported per branch; unless stack.commitLint.mode is "warn" they
+// stop the sync.
+func (e *Engine) lintStackCommits(gitClient git.GitClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, baseBranch string) error {
+	failed := 0
+	for _, branch := range branches {
+		if pr, ok := prCache[branch.Name]; ok && pr.State == "MERGED" {
+			continue
+		}
+		parent := branch.Parent
+		if parent == baseBranch {
+			parent = git.RemoteRef(baseBranch)
+		}
+
+		failures, err := e.lintBranchCommits(gitClient, parent, branch.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: failed to lint the commits of %s: %v\n", branch.Name, err)
+			continue
+		}
+		if len(failures) == 0 {
+			continue
+		}
+
+		failed++
+		icon := ui.ErrorIcon()
+		if e.options.CommitLintWarn {
+			icon = ui.WarningIcon()
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: %d commit message(s) rejected by '%s'\n", icon, ui.Branch(branch.Name), len(failures), e.options.CommitLint)
+		for _, failure := range failures {
+			fmt.Fprintf(os.Stderr, "  %s %s\n", ui.Dim(ui.ShortSHA(failure.Commit)), failure.Subject)
+			for _, line := range strings.Split(failure.Output, "\n") {
+				if line != "" {
+					fmt.Fprintf(os.Stderr, "    %s\n", line)
+				}
+			}
+		}
+	}
+
+	if failed == 0 || e.options.CommitLintWarn {
+		if failed > 0 {
+			fmt.Fprintln(os.Stderr)
+		}
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "\nNothing was pushed. Reword the commits (e.g. with '%s'), or skip the check once with '%s'\n",
+		ui.Command("git rebase -i"), ui.Command("stack sync --no-lint"))
+	return ui.Reported(errors.New("commit messages rejected by the linter"))
+}
+
+// lintBranchCommits runs the linter on each commit in branch that isn't in parent
+func (e *Engine) lintBranchCommits(gitClient git.GitClient, parent, branch string) ([]lintFailure, error) {
+	commits, err := gitClient.GetUniqueCommits(parent, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []lintFailure
+	for _, commit := range commits {
+		message, err := gitClient.GetCommitMessage(commit)
+		if err != nil {
+			return nil, err
+		}
+		if output, err := runLinter(e.options.CommitLint, message+"\n"); err != nil {
+			subject, _, _ := strings.Cut(message, "\n")
+			failures = append(failures, lintFailure{Commit: commit, Subject: subject, Output: output})
+		}
+	}
+	return failures, nil
+}
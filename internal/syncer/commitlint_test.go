@@ -0,0 +1,98 @@
+package syncer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintStackCommits(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	originalLinter := runLinter
+	defer func() { runLinter = originalLinter }()
+	// Accept conventional commit subjects only
+	runLinter = func(command, message string) (string, error) {
+		if strings.HasPrefix(message, "feat: ") || strings.HasPrefix(message, "fix: ") {
+			return "", nil
+		}
+		return "subject must start with a type", errors.New("exit status 1")
+	}
+
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+		{Name: "feature-c", Parent: "feature-b"},
+	}
+	prCache := map[string]*github.PRInfo{"feature-c": {Number: 3, State: "MERGED"}}
+
+	tests := []struct {
+		name        string
+		options     Options
+		expectError bool
+	}{
+		{
+			name:        "stops the sync when a commit fails",
+			options:     Options{CommitLint: "commitlint"},
+			expectError: true,
+		},
+		{
+			name:    "only warns in warn mode",
+			options: Options{CommitLint: "commitlint", CommitLintWarn: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{"a1"}, nil)
+			mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+			mockGit.On("GetCommitMessage", "a1").Return("feat: add login", nil)
+			mockGit.On("GetCommitMessage", "b1").Return("fix: typo", nil)
+			mockGit.On("GetCommitMessage", "b2").Return("WIP", nil)
+
+			engine := New(nil, nil, tt.options, nil)
+			err := engine.lintStackCommits(mockGit, branches, prCache, "main")
+
+			if tt.expectError {
+				var reported *ui.ReportedError
+				assert.ErrorAs(t, err, &reported)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLintBranchCommits(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	originalLinter := runLinter
+	defer func() { runLinter = originalLinter }()
+	runLinter = func(command, message string) (string, error) {
+		if strings.HasPrefix(message, "fix: ") {
+			return "", nil
+		}
+		return "subject must start with a type", errors.New("exit status 1")
+	}
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+	mockGit.On("GetCommitMessage", "b1").Return("fix: typo", nil)
+	mockGit.On("GetCommitMessage", "b2").Return("WIP", nil)
+
+	engine := New(nil, nil, Options{CommitLint: "commitlint"}, nil)
+	failures, err := engine.lintBranchCommits(mockGit, "feature-a", "feature-b")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []lintFailure{{Commit: "b2", Subject: "WIP", Output: "subject must start with a type"}}, failures)
+}
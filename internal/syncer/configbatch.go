@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+// configBatch queues config writes that don't have to land right away, like PR pins
+// and recorded PR bases, so a sync writes them in one burst at the end instead of
+// taking the config lock over and over between network calls
+type configBatch struct {
+	keys   []string
+	values map[string]string
+}
+
+func newConfigBatch() *configBatch {
+	return &configBatch{values: make(map[string]string)}
+}
+
+// Set queues a write; a later Set of the same key replaces the value
+func (b *configBatch) Set(key, value string) {
+	if _, queued := b.values[key]; !queued {
+		b.keys = append(b.keys, key)
+	}
+	b.values[key] = value
+}
+
+// Flush writes the queued values in the order they were first set. A failed write
+// doesn't stop the others, unless the config stayed locked: then the rest would
+// only wait for the lock again.
+func (b *configBatch) Flush(gitClient git.GitClient) error {
+	var errs []error
+	for _, key := range b.keys {
+		if err := gitClient.SetConfig(key, b.values[key]); err != nil {
+			errs = append(errs, err)
+			if errors.Is(err, git.ErrConfigLocked) {
+				break
+			}
+		}
+	}
+	b.keys = nil
+	b.values = make(map[string]string)
+	return errors.Join(errs...)
+}
+
+// flushStackRecords writes the queued PR pins and bases, which are only hints for
+// later commands, so failing to write them is a warning
+func flushStackRecords(gitClient git.GitClient, batch *configBatch) {
+	err := batch.Flush(gitClient)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, git.ErrConfigLocked) {
+		fmt.Fprintf(os.Stderr, "Warning: could not record PR numbers and bases: %v\n", err)
+	} else if git.Verbose {
+		fmt.Printf("  Note: could not record PR numbers and bases: %v\n", err)
+	}
+}
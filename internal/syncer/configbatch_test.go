@@ -0,0 +1,43 @@
+package syncer
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigBatch(t *testing.T) {
+	t.Run("writes each key once, in order", func(t *testing.T) {
+		batch := newConfigBatch()
+		batch.Set("branch.feature-a.stackpr", "12")
+		batch.Set("branch.feature-b.stackprbase", "main")
+		batch.Set("branch.feature-a.stackpr", "14")
+
+		mockGit := new(testutil.MockGitClient)
+		first := mockGit.On("SetConfig", "branch.feature-a.stackpr", "14").Return(nil)
+		mockGit.On("SetConfig", "branch.feature-b.stackprbase", "main").Return(nil).NotBefore(first)
+
+		assert.NoError(t, batch.Flush(mockGit))
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNumberOfCalls(t, "SetConfig", 2)
+
+		// Flushing empties the batch
+		assert.NoError(t, batch.Flush(mockGit))
+		mockGit.AssertNumberOfCalls(t, "SetConfig", 2)
+	})
+
+	t.Run("stops once the config stays locked", func(t *testing.T) {
+		batch := newConfigBatch()
+		batch.Set("branch.feature-a.stackpr", "12")
+		batch.Set("branch.feature-b.stackpr", "13")
+
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("SetConfig", "branch.feature-a.stackpr", "12").Return(fmt.Errorf("git config: %w", git.ErrConfigLocked))
+
+		assert.ErrorIs(t, batch.Flush(mockGit), git.ErrConfigLocked)
+		mockGit.AssertNotCalled(t, "SetConfig", "branch.feature-b.stackpr", "13")
+	})
+}
@@ -0,0 +1,47 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// CreatePR opens a PR for branch against base, as a draft if draft is set, and
+// adds it to prCache, so the status shown afterwards includes it. The branch description
+// (see 'stack describe') becomes the PR body. Failures are only warned about: the
+// branch itself was synced fine.
+func CreatePR(gitClient git.GitClient, githubClient github.GitHubClient, branch, base string, draft bool, prCache map[string]*github.PRInfo) {
+	kind := "PR"
+	if draft {
+		kind = "draft PR"
+	}
+	fmt.Printf("  Creating %s against %s...\n", kind, ui.Branch(base))
+
+	pr, err := githubClient.CreatePR(branch, base, draft, BranchDescription(gitClient, branch))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: failed to create PR: %v\n", err)
+		return
+	}
+	if pr == nil {
+		// Dry run
+		return
+	}
+
+	prCache[branch] = pr
+	fmt.Printf("  %s Created PR #%d: %s\n", ui.SuccessIcon(), pr.Number, pr.URL)
+}
+
+// BranchDescription returns the description of branch set with 'stack describe' or
+// 'git branch --edit-description'
+func BranchDescription(gitClient git.GitClient, branch string) string {
+	return strings.TrimSpace(gitClient.GetConfig(BranchDescriptionKey(branch)))
+}
+
+// BranchDescriptionKey is where git keeps the description of branch
+func BranchDescriptionKey(branch string) string {
+	return fmt.Sprintf("branch.%s.description", branch)
+}
@@ -0,0 +1,56 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreatePR(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("adds created PR to cache", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "branch.feature-b.description").Return("")
+		mockGH := new(testutil.MockGitHubClient)
+		created := testutil.NewPRInfo(7, "OPEN", "feature-a", "", "https://github.com/o/r/pull/7")
+		mockGH.On("CreatePR", "feature-b", "feature-a", true, "").Return(created, nil)
+		prCache := map[string]*github.PRInfo{}
+
+		CreatePR(mockGit, mockGH, "feature-b", "feature-a", true, prCache)
+
+		assert.Equal(t, created, prCache["feature-b"])
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("ready for review with --draft=false", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "branch.feature-b.description").Return("")
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("CreatePR", "feature-b", "feature-a", false, "").Return(nil, errors.New("boom"))
+		prCache := map[string]*github.PRInfo{}
+
+		CreatePR(mockGit, mockGH, "feature-b", "feature-a", false, prCache)
+
+		assert.Empty(t, prCache)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("uses the branch description as PR body", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "branch.feature-b.description").Return("Adds the auth flow.\n\nPart 2 of 3.\n")
+		mockGH := new(testutil.MockGitHubClient)
+		created := testutil.NewPRInfo(8, "OPEN", "feature-a", "", "https://github.com/o/r/pull/8")
+		mockGH.On("CreatePR", "feature-b", "feature-a", true, "Adds the auth flow.\n\nPart 2 of 3.").Return(created, nil)
+		prCache := map[string]*github.PRInfo{}
+
+		CreatePR(mockGit, mockGH, "feature-b", "feature-a", true, prCache)
+
+		assert.Equal(t, created, prCache["feature-b"])
+		mockGH.AssertExpectations(t)
+	})
+}
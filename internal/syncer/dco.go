@@ -0,0 +1,132 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+const (
+	DCOCheck = "check"
+	DCOFix   = "fix"
+)
+
+// unsignedCommit is a commit without a sign-off from its author
+type unsignedCommit struct {
+	Commit  string
+	Subject string
+	Author  string
+}
+
+// hasSignOff reports whether message has a Signed-off-by trailer for email
+func hasSignOff(message, email string) bool {
+	want := "<" + strings.ToLower(email) + ">"
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if strings.HasPrefix(line, "signed-off-by:") && strings.Contains(line, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSignOffs looks for commits without a sign-off from their author in what each
+// branch adds on top of its parent, before anything is rebased or pushed. Merged
+// branches aren't checked. In check mode missing sign-offs stop the sync. In fix
+// mode the branches to sign off are returned, as long as you authored the commits:
+// a sign-off certifies the author's own work, so it can't be added for someone else.
+func (e *Engine) checkSignOffs(gitClient git.GitClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, baseBranch string) (map[string][]unsignedCommit, error) {
+	self := strings.ToLower(gitClient.GetConfig("user.email"))
+	toFix := make(map[string][]unsignedCommit)
+	failed := 0
+	for _, branch := range branches {
+		if pr, ok := prCache[branch.Name]; ok && pr.State == "MERGED" {
+			continue
+		}
+		parent := branch.Parent
+		if parent == baseBranch {
+			parent = git.RemoteRef(baseBranch)
+		}
+
+		unsigned, err := unsignedCommits(gitClient, parent, branch.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: failed to check the sign-offs of %s: %v\n", branch.Name, err)
+			continue
+		}
+		if len(unsigned) == 0 {
+			continue
+		}
+
+		// Only your own commits can be fixed
+		var foreign []unsignedCommit
+		if e.options.DCO == DCOFix {
+			for _, c := range unsigned {
+				if strings.ToLower(c.Author) != self {
+					foreign = append(foreign, c)
+				}
+			}
+			if len(foreign) == 0 {
+				toFix[branch.Name] = unsigned
+				continue
+			}
+			unsigned = foreign
+		}
+
+		failed++
+		fmt.Fprintf(os.Stderr, "%s %s: %d commit(s) without a Signed-off-by from their author\n", ui.ErrorIcon(), ui.Branch(branch.Name), len(unsigned))
+		for _, c := range unsigned {
+			fmt.Fprintf(os.Stderr, "  %s %s %s\n", ui.Dim(ui.ShortSHA(c.Commit)), c.Subject, ui.Dim("("+c.Author+")"))
+		}
+	}
+
+	if failed == 0 {
+		return toFix, nil
+	}
+	if e.options.DCO == DCOFix {
+		fmt.Fprintln(os.Stderr, "\nNothing was pushed. These commits were authored by someone else, ask them to sign them off")
+	} else {
+		fmt.Fprintf(os.Stderr, "\nNothing was pushed. Sign the commits off with '%s', or let sync do it with '%s'\n",
+			ui.Command("git rebase --signoff <parent>"), ui.Command("git config stack.dco fix"))
+	}
+	return nil, ui.Reported(errors.New("commits without a sign-off"))
+}
+
+// unsignedCommits returns the commits in branch that aren't in parent and lack a
+// sign-off from their author
+func unsignedCommits(gitClient git.GitClient, parent, branch string) ([]unsignedCommit, error) {
+	commits, err := gitClient.GetUniqueCommits(parent, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	var unsigned []unsignedCommit
+	for _, commit := range commits {
+		message, err := gitClient.GetCommitMessage(commit)
+		if err != nil {
+			return nil, err
+		}
+		author, err := gitClient.GetCommitAuthorEmail(commit)
+		if err != nil {
+			return nil, err
+		}
+		if !hasSignOff(message, author) {
+			subject, _, _ := strings.Cut(message, "\n")
+			unsigned = append(unsigned, unsignedCommit{Commit: commit, Subject: subject, Author: author})
+		}
+	}
+	return unsigned, nil
+}
+
+// reportSignOffs lists the commits of a branch that sync signed off
+func reportSignOffs(commits []unsignedCommit) {
+	fmt.Printf("  %s Signed off %d commit(s):\n", ui.SuccessIcon(), len(commits))
+	for _, c := range commits {
+		fmt.Printf("    %s %s\n", ui.Dim(ui.ShortSHA(c.Commit)), c.Subject)
+	}
+}
@@ -0,0 +1,90 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasSignOff(t *testing.T) {
+	message := "Add login\n\nSigned-off-by: Jane Doe <Jane@example.com>"
+
+	assert.True(t, hasSignOff(message, "jane@example.com"))
+	assert.False(t, hasSignOff(message, "john@example.com"))
+	assert.False(t, hasSignOff("Add login\n\nSigned-off-by: jane@example.com", "jane@example.com"))
+}
+
+func TestCheckSignOffs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+		{Name: "feature-c", Parent: "feature-b"},
+	}
+	prCache := map[string]*github.PRInfo{"feature-c": {Number: 3, State: "MERGED"}}
+
+	tests := []struct {
+		name           string
+		dco            string
+		bAuthor        string
+		expectReported bool
+		expectToFix    map[string][]unsignedCommit
+	}{
+		{
+			name:           "stops the sync in check mode",
+			dco:            DCOCheck,
+			bAuthor:        "me@example.com",
+			expectReported: true,
+		},
+		{
+			name:    "returns the branches to sign off in fix mode",
+			dco:     DCOFix,
+			bAuthor: "me@example.com",
+			expectToFix: map[string][]unsignedCommit{
+				"feature-b": {
+					{Commit: "b1", Subject: "Fix typo", Author: "me@example.com"},
+					{Commit: "b2", Subject: "Add logout", Author: "me@example.com"},
+				},
+			},
+		},
+		{
+			name:           "doesn't sign off someone else's commits",
+			dco:            DCOFix,
+			bAuthor:        "other@example.com",
+			expectReported: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "user.email").Return("me@example.com")
+			mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{"a1"}, nil)
+			mockGit.On("GetUniqueCommits", "feature-a", "feature-b").Return([]string{"b1", "b2"}, nil)
+			mockGit.On("GetCommitMessage", "a1").Return("Add login\n\nSigned-off-by: Me <me@example.com>", nil)
+			mockGit.On("GetCommitAuthorEmail", "a1").Return("me@example.com", nil)
+			mockGit.On("GetCommitMessage", "b1").Return("Fix typo", nil)
+			mockGit.On("GetCommitAuthorEmail", "b1").Return("me@example.com", nil)
+			mockGit.On("GetCommitMessage", "b2").Return("Add logout", nil)
+			mockGit.On("GetCommitAuthorEmail", "b2").Return(tt.bAuthor, nil)
+			engine := New(nil, nil, Options{DCO: tt.dco}, nil)
+
+			toFix, err := engine.checkSignOffs(mockGit, branches, prCache, "main")
+
+			if tt.expectReported {
+				var reported *ui.ReportedError
+				assert.ErrorAs(t, err, &reported)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectToFix, toFix)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
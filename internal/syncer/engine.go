@@ -0,0 +1,95 @@
+package syncer
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/spinner"
+)
+
+// Engine syncs the current stack. Whatever drives it, the sync command or a
+// long-running mode, sets its options and follows its progress through an
+// observer rather than reading the output.
+type Engine struct {
+	gitClient    git.GitClient
+	githubClient github.GitHubClient
+	options      Options
+	observer     Observer
+
+	// Input is where prompts read their answers from
+	Input io.Reader
+
+	// ciTimeout is Options.CITimeout, parsed by Run
+	ciTimeout time.Duration
+
+	// The branch being synced, for the events of its steps
+	branch string
+	index  int
+	total  int
+}
+
+// New returns an engine syncing with options; a nil observer ignores the events.
+// Prompts read from os.Stdin.
+func New(gitClient git.GitClient, githubClient github.GitHubClient, options Options, observer Observer) *Engine {
+	if observer == nil {
+		observer = Discard
+	}
+	return &Engine{
+		gitClient:    gitClient,
+		githubClient: githubClient,
+		options:      options,
+		observer:     observer,
+		Input:        os.Stdin,
+	}
+}
+
+// Run syncs the stack of the current branch, or resumes or aborts an interrupted
+// sync. Invalid options fail it before anything changes.
+func (e *Engine) Run() error {
+	timeout, err := e.options.ciTimeout()
+	if err == nil {
+		e.ciTimeout = timeout
+		err = e.run()
+	}
+	e.observer.OnEvent(Event{Kind: Finished, Total: e.total, Err: err})
+	return err
+}
+
+// emit reports an event about the branch being synced
+func (e *Engine) emit(kind EventKind, message string, err error) {
+	e.observer.OnEvent(Event{
+		Kind:    kind,
+		Branch:  e.branch,
+		Index:   e.index,
+		Total:   e.total,
+		Message: message,
+		Err:     err,
+	})
+}
+
+// startBranch moves the progress to the index-th of total branches
+func (e *Engine) startBranch(progress *spinner.Progress, branch string, index, total int) {
+	e.branch, e.index, e.total = branch, index, total
+	progress.Next(branch)
+	e.emit(BranchStarted, "", nil)
+}
+
+// skipBranch reports that the branch being synced is left alone, and why
+func (e *Engine) skipBranch(reason string) {
+	e.emit(BranchSkipped, reason, nil)
+}
+
+// step runs fn under the progress spinner, reporting when it starts and ends
+func (e *Engine) step(progress *spinner.Progress, indent, message, successMessage string, fn func() error) error {
+	e.emit(StepStarted, message, nil)
+	err := progress.Step(indent, message, successMessage, fn)
+	if err != nil {
+		e.emit(StepFailed, message, err)
+	} else {
+		e.emit(StepDone, successMessage, nil)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEngineReportsEvents(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+
+	queued := testutil.NewPRInfo(12, "OPEN", "main", "A", "url")
+	queued.InMergeQueue = true
+
+	mockGit.On("GetConfig", "stack.sync.stashed").Return("")
+	mockGit.On("GetConfig", "stack.sync.originalBranch").Return("")
+	mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+	mockGit.On("SetConfig", "stack.sync.originalBranch", "feature-b").Return(nil)
+	mockGit.On("SetConfig", "stack.sync.startedAt", mock.Anything).Return(nil)
+	mockGit.On("IsWorkingTreeClean").Return(true, nil)
+	mockGit.On("GetConfig", "branch.feature-b.stackparent").Return("feature-a")
+	mockGit.On("GetConfig", "stack.baseBranch").Return("").Maybe()
+	mockGit.On("GetDefaultBranch").Return("main").Maybe()
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+	}, nil).Maybe()
+	testutil.AllowPRPins(mockGit)
+	testutil.AllowStackUmbrellas(mockGit)
+	testutil.AllowSyncState(mockGit)
+	testutil.AllowSyncWorktree(mockGit)
+	testutil.AllowSubmodules(mockGit)
+	testutil.AllowUpdateRefs(mockGit)
+	testutil.AllowOpLog(mockGit)
+	testutil.AllowPickedCommits(mockGit)
+	mockGit.On("Fetch").Return(nil)
+	mockGH.On("GetPRsForBranches", mock.Anything).Return(map[string]*github.PRInfo{"feature-a": queued}, nil)
+	mockGH.On("GetPRForBranch", mock.Anything).Return(nil, nil).Maybe()
+	mockGit.On("GetWorktreeBranches").Return(make(map[string]string), nil)
+	mockGit.On("GetCurrentWorktreePath").Return("/Users/test/repo", nil)
+	mockGit.On("GetRemoteBranchesSet").Return(map[string]bool{"main": true, "feature-a": true, "feature-b": true})
+	mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+	mockGit.On("GetCommitHash", "feature-b").Return("def456", nil)
+	mockGit.On("GetCommitHash", "origin/feature-b").Return("def456", nil)
+	mockGit.On("GetUniqueCommitsByPatch", "feature-a", "feature-b").Return([]string{}, nil)
+	mockGit.On("FetchBranch", "feature-b").Return(nil)
+	mockGit.On("PushWithExpectedRemote", "feature-b", "def456").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.stashed").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.originalBranch").Return(nil)
+	mockGit.On("UnsetConfig", "stack.sync.startedAt").Return(nil)
+
+	var events []Event
+	err := New(mockGit, mockGH, Options{}, ObserverFunc(func(event Event) {
+		events = append(events, event)
+	})).Run()
+
+	assert.NoError(t, err)
+	type step struct {
+		kind    EventKind
+		branch  string
+		message string
+	}
+	var steps []step
+	for _, event := range events {
+		steps = append(steps, step{event.Kind, event.Branch, event.Message})
+	}
+	assert.Equal(t, []step{
+		{BranchStarted, "feature-a", ""},
+		{BranchSkipped, "feature-a", "PR #12 is in the merge queue"},
+		{BranchStarted, "feature-b", ""},
+		{StepStarted, "feature-b", "Rebasing onto feature-a..."},
+		{StepDone, "feature-b", "Rebased onto feature-a"},
+		{StepStarted, "feature-b", "Pushing to origin..."},
+		{StepDone, "feature-b", "Pushed to origin"},
+		{Finished, "", ""},
+	}, steps)
+	assert.Equal(t, 2, events[2].Index)
+	assert.Equal(t, 2, events[2].Total)
+}
+
+func TestEngineRejectsInvalidOptions(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+	var finished []Event
+	err := New(mockGit, mockGH, Options{CITimeout: "soon"}, ObserverFunc(func(event Event) {
+		finished = append(finished, event)
+	})).Run()
+
+	assert.Error(t, err)
+	if assert.Len(t, finished, 1) {
+		assert.Equal(t, Finished, finished[0].Kind)
+		assert.Equal(t, err, finished[0].Err)
+	}
+	mockGit.AssertNotCalled(t, "Fetch")
+}
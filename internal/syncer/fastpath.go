@@ -0,0 +1,118 @@
+package syncer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// stackSyncedKey is the git config key recording the state a sync of the branch's
+// stack last left things in
+func stackSyncedKey(branch string) string {
+	return fmt.Sprintf("branch.%s.stacksynced", branch)
+}
+
+// fastPathAllowed reports whether the sync may stop early when nothing changed.
+// Options that do more than restack and push always need the full walk.
+func (e *Engine) fastPathAllowed() bool {
+	return !e.options.Full && !e.options.Force && !e.options.CherryPick && !e.options.CreatePRs && !e.options.Verify &&
+		!e.options.DeleteMerged && !e.options.RequestReviewers && e.options.ReviewComment == "" &&
+		!e.options.DetectMergedByPatch && !e.options.DetectMergedByCommit && !e.options.PRNav && !e.options.PRPosition.Enabled() &&
+		!git.Offline
+}
+
+// syncFingerprint sums up what a sync of branch's stack depends on: origin's base
+// tip, and each branch's parent, tip and tip on origin. Empty when branch isn't in
+// a stack.
+func syncFingerprint(gitClient git.GitClient, branch, baseBranch string) (string, error) {
+	chain, err := stack.GetStackChain(gitClient, branch)
+	if err != nil || len(chain) == 0 {
+		return "", err
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return "", err
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	tips, err := gitClient.GetBranchTips()
+	if err != nil {
+		return "", err
+	}
+
+	baseTip, ok := tips[git.RemoteRef(baseBranch)]
+	if !ok {
+		return "", fmt.Errorf("%s not found", git.RemoteRef(baseBranch))
+	}
+	lines := []string{"base " + baseBranch + " " + baseTip}
+	for _, name := range chain {
+		if name == baseBranch {
+			continue
+		}
+		remoteTip, pushed := tips[git.RemoteRef(name)]
+		if !pushed {
+			remoteTip = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s %s %s %t", name, parents[name], tips[name], remoteTip, umbrellas[name]))
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(lines, "\n")))), nil
+}
+
+// syncUpToDate reports whether branch's stack is where the last sync left it: same
+// fingerprint, every pushed branch matching origin, and every PR last seen
+// targeting the branch it should. Recorded PR bases stand in for GitHub, which
+// keeps the check local and quick.
+func (e *Engine) syncUpToDate(gitClient git.GitClient, branch, baseBranch string) bool {
+	recorded := gitClient.GetConfig(stackSyncedKey(branch))
+	if recorded == "" {
+		return false
+	}
+	fingerprint, err := syncFingerprint(gitClient, branch, baseBranch)
+	if err != nil || fingerprint != recorded {
+		return false
+	}
+
+	chain, err := stack.GetStackChain(gitClient, branch)
+	if err != nil {
+		return false
+	}
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return false
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	bases, _ := gitClient.GetAllStackPRBases()
+	tips, err := gitClient.GetBranchTips()
+	if err != nil {
+		return false
+	}
+	for _, name := range chain {
+		if name == baseBranch {
+			continue
+		}
+		// A push held back or rejected last time still has to happen
+		if remoteTip, pushed := tips[git.RemoteRef(name)]; pushed && remoteTip != tips[name] {
+			return false
+		}
+		// So does a PR base update that failed
+		if base, ok := bases[name]; ok && !e.options.FlatPRs && base != PRBaseFor(parents[name], parents, umbrellas) {
+			return false
+		}
+	}
+	return true
+}
+
+// recordSyncState remembers the state the sync left branch's stack in, for the next
+// sync to compare against
+func recordSyncState(gitClient git.GitClient, batch *configBatch, branch, baseBranch string) {
+	if git.DryRun || git.Offline {
+		return
+	}
+	fingerprint, err := syncFingerprint(gitClient, branch, baseBranch)
+	if err != nil || fingerprint == "" {
+		return
+	}
+	batch.Set(stackSyncedKey(branch), fingerprint)
+}
@@ -0,0 +1,103 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncUpToDate(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+	tips := func(originB string) map[string]string {
+		return map[string]string{
+			"main":             "m1",
+			"origin/main":      "m1",
+			"feature-a":        "a1",
+			"origin/feature-a": "a1",
+			"feature-b":        "b1",
+			"origin/feature-b": originB,
+		}
+	}
+
+	// The state a previous sync recorded
+	previous := new(testutil.MockGitClient)
+	previous.On("GetAllStackParents").Return(parents, nil)
+	previous.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+	previous.On("GetAllStackPRBases").Return(parents, nil)
+	previous.On("GetBranchTips").Return(tips("b1"), nil)
+	fingerprint, err := syncFingerprint(previous, "feature-b", "main")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, fingerprint)
+
+	tests := []struct {
+		name     string
+		recorded string
+		originB  string
+		prBases  map[string]string
+		expected bool
+	}{
+		{
+			name:     "nothing changed",
+			recorded: fingerprint,
+			originB:  "b1",
+			prBases:  parents,
+			expected: true,
+		},
+		{
+			name:    "never synced",
+			originB: "b1",
+			prBases: parents,
+		},
+		{
+			name:     "origin moved",
+			recorded: fingerprint,
+			originB:  "b2",
+			prBases:  parents,
+		},
+		{
+			name:     "a PR base update is still pending",
+			recorded: fingerprint,
+			originB:  "b1",
+			prBases:  map[string]string{"feature-a": "main", "feature-b": "main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "branch.feature-b.stacksynced").Return(tt.recorded)
+			// How much of the stack is read depends on how early the check bails out
+			mockGit.On("GetAllStackParents").Return(parents, nil).Maybe()
+			mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil).Maybe()
+			mockGit.On("GetAllStackPRBases").Return(tt.prBases, nil).Maybe()
+			mockGit.On("GetBranchTips").Return(tips(tt.originB), nil).Maybe()
+			engine := New(nil, nil, Options{}, nil)
+
+			assert.Equal(t, tt.expected, engine.syncUpToDate(mockGit, "feature-b", "main"))
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFastPathAllowed(t *testing.T) {
+	assert.True(t, New(nil, nil, Options{}, nil).fastPathAllowed())
+
+	tests := map[string]Options{
+		"--delete-merged":           {DeleteMerged: true},
+		"--request-reviewers":       {RequestReviewers: true},
+		"--detect-merged-by-patch":  {DetectMergedByPatch: true},
+		"--detect-merged-by-commit": {DetectMergedByCommit: true},
+		"--pr-nav":                  {PRNav: true},
+		"--pr-position":             {PRPosition: PositionMode{Labels: true}},
+		"--review-comment":          {ReviewComment: "summary"},
+	}
+	for name, options := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.False(t, New(nil, nil, options, nil).fastPathAllowed())
+		})
+	}
+}
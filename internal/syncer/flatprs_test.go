@@ -0,0 +1,44 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFlatPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	engine := New(nil, nil, Options{FlatPRs: true}, nil)
+
+	t.Run("doesn't check PR bases", func(t *testing.T) {
+		parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCommitHash", "feature-b").Return("bbb", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("bbb", nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("aaa", nil)
+		prs := map[string]*github.PRInfo{"feature-b": testutil.NewPRInfo(13, "OPEN", "main", "B", "url")}
+
+		result := engine.verifyBranch(mockGit, "feature-b", parents, nil, prs, nil, map[string]bool{"feature-b": true}, "main")
+
+		assert.True(t, result.ok())
+		assert.Equal(t, []string{"PR #13 base not checked with stack.flatPRs"}, result.Skipped)
+	})
+
+	t.Run("leaves PR bases to GitHub", func(t *testing.T) {
+		branches := []stack.StackBranch{{Name: "feature-a", Parent: "main"}, {Name: "feature-b", Parent: "feature-a"}}
+		prCache := map[string]*github.PRInfo{"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "B", "")}
+		mockGit := new(testutil.MockGitClient)
+
+		moved := engine.reconcileBaseChanges(mockGit, branches, prCache, map[string]string{"feature-b": "feature-a"}, nil, map[string]bool{})
+
+		assert.Empty(t, moved)
+		mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+	})
+}
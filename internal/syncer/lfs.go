@@ -0,0 +1,24 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// restoreLFSFiles downloads the LFS files of the current branch after checkouts
+// that left them as pointers
+func restoreLFSFiles(gitClient git.GitClient) {
+	if !git.SkipLFSSmudge {
+		return
+	}
+	git.SkipLFSSmudge = false
+	fmt.Println("Downloading LFS files...")
+	if err := gitClient.LFSPull(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to download LFS files: %v\n", err)
+		fmt.Fprintln(os.Stderr, i18n.T("sync.lfsPullHint", ui.Command("git lfs pull")))
+	}
+}
@@ -0,0 +1,36 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreLFSFiles(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { git.SkipLFSSmudge = false }()
+
+	t.Run("pulls LFS files skipped during the sync", func(t *testing.T) {
+		git.SkipLFSSmudge = true
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("LFSPull").Return(errors.New("offline"))
+
+		restoreLFSFiles(mockGit)
+
+		assert.False(t, git.SkipLFSSmudge)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("does nothing when smudge wasn't skipped", func(t *testing.T) {
+		git.SkipLFSSmudge = false
+		mockGit := new(testutil.MockGitClient)
+
+		restoreLFSFiles(mockGit)
+
+		mockGit.AssertNotCalled(t, "LFSPull")
+	})
+}
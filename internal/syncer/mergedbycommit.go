@@ -0,0 +1,35 @@
+package syncer
+
+import (
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+)
+
+// MergedByCommit returns the branches whose PR is closed while their tip is
+// already reachable from base: merged by pushing the commits themselves, e.g.
+// a fast-forward push, which GitHub can show as closed rather than merged. All
+// branches are checked with a single git call. Only closed PRs count, since a
+// branch without commits of its own is reachable from base too.
+func MergedByCommit(gitClient git.GitClient, base string, prCache map[string]*github.PRInfo) (map[string]bool, error) {
+	closed := make(map[string]bool)
+	for branch, pr := range prCache {
+		if pr != nil && pr.State == "CLOSED" {
+			closed[branch] = true
+		}
+	}
+	if len(closed) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	reachable, err := gitClient.GetBranchesMergedInto(base)
+	if err != nil {
+		return nil, err
+	}
+	merged := make(map[string]bool)
+	for branch := range closed {
+		if reachable[branch] {
+			merged[branch] = true
+		}
+	}
+	return merged, nil
+}
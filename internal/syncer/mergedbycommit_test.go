@@ -0,0 +1,59 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergedByCommit(t *testing.T) {
+	t.Run("closed PRs whose tip is on the base", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetBranchesMergedInto", "origin/main").Return(map[string]bool{
+			"feature-a": true,
+			"feature-b": true,
+			"feature-d": true,
+		}, nil).Once()
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "CLOSED", "main", "A", "url"),
+			// Open, with nothing of its own yet
+			"feature-b": testutil.NewPRInfo(2, "OPEN", "main", "B", "url"),
+			// Closed without landing
+			"feature-c": testutil.NewPRInfo(3, "CLOSED", "main", "C", "url"),
+		}
+
+		merged, err := MergedByCommit(mockGit, "origin/main", prCache)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]bool{"feature-a": true}, merged)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("no closed PRs", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "A", "url"),
+		}
+
+		merged, err := MergedByCommit(mockGit, "origin/main", prCache)
+
+		assert.NoError(t, err)
+		assert.Empty(t, merged)
+		mockGit.AssertNotCalled(t, "GetBranchesMergedInto", "origin/main")
+	})
+
+	t.Run("git fails", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetBranchesMergedInto", "origin/main").Return(nil, errors.New("bad ref"))
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(1, "CLOSED", "main", "A", "url"),
+		}
+
+		_, err := MergedByCommit(mockGit, "origin/main", prCache)
+
+		assert.Error(t, err)
+	})
+}
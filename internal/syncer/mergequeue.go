@@ -0,0 +1,25 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// InMergeQueue reports whether pr is waiting in the merge queue. Pushing to a
+// queued PR's branch, or changing its base, drops it from the queue.
+func InMergeQueue(pr *github.PRInfo) bool {
+	return pr != nil && pr.State == "OPEN" && pr.InMergeQueue
+}
+
+// MergeQueueLabel marks a PR waiting in the merge queue in stack trees
+func MergeQueueLabel(pr *github.PRInfo) string {
+	if !InMergeQueue(pr) {
+		return ""
+	}
+	if pr.MergeQueuePosition > 0 {
+		return " " + ui.Dim(fmt.Sprintf("(merge queue #%d)", pr.MergeQueuePosition))
+	}
+	return " " + ui.Dim("(in merge queue)")
+}
@@ -0,0 +1,18 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueueLabel(t *testing.T) {
+	queued := testutil.NewPRInfo(12, "OPEN", "main", "A", "url")
+	queued.InMergeQueue = true
+	queued.MergeQueuePosition = 3
+
+	assert.Contains(t, MergeQueueLabel(queued), "merge queue #3")
+	assert.Empty(t, MergeQueueLabel(testutil.NewPRInfo(13, "OPEN", "main", "B", "url")))
+	assert.Empty(t, MergeQueueLabel(nil))
+}
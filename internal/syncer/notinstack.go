@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configNotInStack is what sync, status and show do when the current branch isn't
+// in a stack: prompt (the default) asks whether to add it with the base branch as
+// parent, add adds it without asking, and error fails with exit code 5
+const configNotInStack = "stack.notInStack"
+
+const (
+	notInStackPrompt = "prompt"
+	notInStackAdd    = "add"
+	notInStackError  = "error"
+)
+
+// notInStackMode reads stack.notInStack
+func notInStackMode(gitClient git.GitClient) (string, error) {
+	switch mode := gitClient.GetConfig(configNotInStack); mode {
+	case "":
+		return notInStackPrompt, nil
+	case notInStackPrompt, notInStackAdd, notInStackError:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s %q (expected prompt, add or error)", configNotInStack, mode)
+	}
+}
+
+// OfferToAddToStack deals with a current branch that isn't in a stack the same
+// way for every command, as stack.notInStack says: it adds the branch with the base
+// branch as parent, asking first by default, or fails with a NotInStackError. It
+// reports whether the branch was added. The base branch can't have a parent, so
+// it's never added. The prompt reads its answer from stdin.
+func OfferToAddToStack(gitClient git.GitClient, stdin io.Reader, branch, baseBranch string) (bool, error) {
+	if branch == baseBranch {
+		fmt.Printf("Branch '%s' is the base branch and cannot be part of a stack.\n", ui.Branch(branch))
+		fmt.Printf("\n%s\n", i18n.T("stack.createHint", ui.Command("stack new <branch-name>")))
+		return false, nil
+	}
+
+	mode, err := notInStackMode(gitClient)
+	if err != nil {
+		return false, err
+	}
+	if mode == notInStackError {
+		return false, &stack.NotInStackError{Branch: branch}
+	}
+
+	fmt.Printf("Branch '%s' is not in a stack.\n", ui.Branch(branch))
+	if mode == notInStackPrompt {
+		fmt.Printf("Add it with parent '%s'? [Y/n] ", ui.Branch(baseBranch))
+		input, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil {
+			return false, fmt.Errorf("failed to read input: %w", err)
+		}
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "" && input != "y" && input != "yes" {
+			fmt.Printf("\n%s\n", NotInStackHint())
+			return false, nil
+		}
+	}
+
+	configKey := fmt.Sprintf("branch.%s.stackparent", branch)
+	if err := gitClient.SetConfig(configKey, baseBranch); err != nil {
+		return false, fmt.Errorf("failed to set parent: %w", err)
+	}
+	fmt.Println(ui.Success(fmt.Sprintf("Added '%s' to stack with parent '%s'", ui.Branch(branch), ui.Branch(baseBranch))))
+	return true, nil
+}
+
+// NotInStackHint is how to get a branch into a stack, or start a new one
+func NotInStackHint() string {
+	return i18n.T("hint.notInStack", ui.Command("stack reparent <parent>")) + "\n" +
+		i18n.T("stack.createHint", ui.Command("stack new <branch-name>"))
+}
@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOfferToAddToStack(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name      string
+		mode      string
+		input     string
+		wantAdded bool
+		wantErr   bool
+	}{
+		{name: "prompt, accepted by default", mode: "", input: "\n", wantAdded: true},
+		{name: "prompt, accepted", mode: "prompt", input: "y\n", wantAdded: true},
+		{name: "prompt, declined", mode: "prompt", input: "n\n"},
+		{name: "added without asking", mode: "add", wantAdded: true},
+		{name: "invalid mode", mode: "ask", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetConfig", "stack.notInStack").Return(tt.mode)
+			if tt.wantAdded {
+				mockGit.On("SetConfig", "branch.feature-a.stackparent", "main").Return(nil)
+			}
+
+			added, err := OfferToAddToStack(mockGit, strings.NewReader(tt.input), "feature-a", "main")
+
+			assert.Equal(t, tt.wantAdded, added)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantAdded {
+				mockGit.AssertNotCalled(t, "SetConfig", mock.Anything, mock.Anything)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+
+	t.Run("error mode fails with not in stack", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", "stack.notInStack").Return("error")
+
+		added, err := OfferToAddToStack(mockGit, strings.NewReader(""), "feature-a", "main")
+
+		assert.False(t, added)
+		var notInStack *stack.NotInStackError
+		assert.ErrorAs(t, err, &notInStack)
+	})
+
+	t.Run("the base branch is never added", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+
+		added, err := OfferToAddToStack(mockGit, strings.NewReader(""), "main", "main")
+
+		assert.False(t, added)
+		assert.NoError(t, err)
+		mockGit.AssertNotCalled(t, "GetConfig", mock.Anything)
+	})
+}
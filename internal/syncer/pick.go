@@ -0,0 +1,46 @@
+package syncer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// dropLandedPicks offers to drop commits of branch (since its parent) that were
+// picked from a commit that has since landed on base, as the pick is redundant
+func (e *Engine) dropLandedPicks(gitClient git.GitClient, branch, parent, base string) {
+	picked, err := gitClient.GetPickedCommits(parent, branch)
+	if err != nil || len(picked) == 0 {
+		return
+	}
+
+	// Picked is newest first, so dropping in that order keeps the older SHAs valid
+	for _, pick := range picked {
+		landed, err := gitClient.IsCommitLanded(base, pick.Source)
+		if err != nil || !landed {
+			continue
+		}
+
+		fmt.Printf("  Commit %s was picked from %s, which has landed on %s\n", ui.ShortSHA(pick.SHA), ui.ShortSHA(pick.Source), ui.Branch(base))
+		if git.DryRun {
+			continue
+		}
+		fmt.Printf("  Drop it from %s? [y/N] ", ui.Branch(branch))
+		input, _ := bufio.NewReader(e.Input).ReadString('\n')
+		input = strings.TrimSpace(strings.ToLower(input))
+		if input != "y" && input != "yes" {
+			continue
+		}
+
+		if err := gitClient.RebaseOnto(pick.SHA+"^", pick.SHA, branch); err != nil {
+			_ = gitClient.AbortRebase()
+			fmt.Fprintf(os.Stderr, "  Warning: failed to drop %s, keeping it: %v\n", ui.ShortSHA(pick.SHA), err)
+			continue
+		}
+		fmt.Printf("  %s Dropped %s\n", ui.SuccessIcon(), ui.ShortSHA(pick.SHA))
+	}
+}
@@ -0,0 +1,29 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+)
+
+func TestDropLandedPicks(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetPickedCommits", "feature-a", "feature-b").Return([]git.PickedCommit{
+		{SHA: "pick2", Source: "src2"},
+		{SHA: "pick1", Source: "src1"},
+	}, nil)
+	mockGit.On("IsCommitLanded", "origin/main", "src2").Return(false, nil)
+	mockGit.On("IsCommitLanded", "origin/main", "src1").Return(true, nil)
+	mockGit.On("RebaseOnto", "pick1^", "pick1", "feature-b").Return(nil)
+	engine := New(mockGit, nil, Options{}, nil)
+	engine.Input = strings.NewReader("y\n")
+
+	engine.dropLandedPicks(mockGit, "feature-b", "feature-a", "origin/main")
+
+	mockGit.AssertExpectations(t)
+}
@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"sort"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+)
+
+// Scopes of the PRs looked up
+const (
+	// PRScopeStack looks up the PRs of the branches involved, whoever opened them
+	PRScopeStack = "stack"
+	// PRScopeAuthor only considers PRs opened by the current user
+	PRScopeAuthor = "author"
+)
+
+// LoadStackPRs fetches the PRs (in any state) of every tracked stack branch and
+// their parents. Querying by head branch keeps this fast on repos with many PRs,
+// where listing all open PRs is the slowest step of status and sync.
+// Also returns the branches that were queried: those missing from the result have no PR.
+func LoadStackPRs(gitClient git.GitClient, githubClient github.GitHubClient, scope string) (map[string]*github.PRInfo, map[string]bool, error) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	heads := make(map[string]bool)
+	for branch, parent := range parents {
+		heads[branch] = true
+		heads[parent] = true
+	}
+
+	branches := make([]string, 0, len(heads))
+	for branch := range heads {
+		branches = append(branches, branch)
+	}
+	sort.Strings(branches)
+
+	prs, err := LoadPRs(githubClient, branches, scope)
+	if err != nil {
+		return nil, nil, err
+	}
+	return prs, heads, nil
+}
+
+// LoadPRs fetches the PRs whose head is one of branches, within scope
+func LoadPRs(githubClient github.GitHubClient, branches []string, scope string) (map[string]*github.PRInfo, error) {
+	if len(branches) == 0 {
+		return make(map[string]*github.PRInfo), nil
+	}
+
+	if scope != PRScopeAuthor {
+		return githubClient.GetPRsForBranches(branches)
+	}
+
+	// One call for the user's own PRs, narrowed down to the branches asked for
+	authored, err := githubClient.GetPRsByAuthor("@me")
+	if err != nil {
+		return nil, err
+	}
+	prs := make(map[string]*github.PRInfo)
+	for _, branch := range branches {
+		if pr, ok := authored[branch]; ok {
+			prs[branch] = pr
+		}
+	}
+	return prs, nil
+}
@@ -0,0 +1,49 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadStackPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+	prA := testutil.NewPRInfo(1, "OPEN", "main", "Feature A", "url")
+
+	t.Run("queries stack branches and their parents", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b", "main"}).
+			Return(map[string]*github.PRInfo{"feature-a": prA}, nil)
+
+		prs, queried, err := LoadStackPRs(mockGit, mockGH, PRScopeStack)
+
+		assert.NoError(t, err)
+		assert.Equal(t, prA, prs["feature-a"])
+		assert.True(t, queried["feature-b"])
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("author scope only keeps own PRs of stack branches", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetAllStackParents").Return(parents, nil)
+		other := testutil.NewPRInfo(2, "OPEN", "main", "Unrelated", "url")
+		mockGH.On("GetPRsByAuthor", "@me").
+			Return(map[string]*github.PRInfo{"feature-a": prA, "unrelated": other}, nil)
+
+		prs, queried, err := LoadStackPRs(mockGit, mockGH, PRScopeAuthor)
+
+		assert.NoError(t, err)
+		assert.Len(t, prs, 1)
+		assert.Equal(t, prA, prs["feature-a"])
+		assert.True(t, queried["feature-b"])
+		mockGH.AssertNotCalled(t, "GetPRsForBranches", []string{"feature-a", "feature-b", "main"})
+	})
+}
@@ -0,0 +1,124 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// The stack table sits between these markers in PR descriptions, so it can be
+// replaced without touching the rest of the description
+const (
+	prNavStart = "<!-- stackinator:stack-nav -->"
+	prNavEnd   = "<!-- /stackinator:stack-nav -->"
+)
+
+// renderStackNav renders the stack of root as a nested Markdown list between the
+// markers, with PRs as #numbers (GitHub links them) and the PR of current pointed out
+func renderStackNav(parents map[string]string, root string, prs map[string]*github.PRInfo, current string) string {
+	children := make(map[string][]string)
+	for child, parent := range parents {
+		children[parent] = append(children[parent], child)
+	}
+	for _, list := range children {
+		sort.Strings(list)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prNavStart + "\n")
+	sb.WriteString("**Stack**\n\n")
+	sb.WriteString(fmt.Sprintf("- `%s`\n", parents[root]))
+
+	visited := make(map[string]bool)
+	var walk func(name string, depth int)
+	walk = func(name string, depth int) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		item := fmt.Sprintf("`%s`", name)
+		if pr := prs[name]; pr != nil && pr.State != "CLOSED" {
+			item = fmt.Sprintf("#%d", pr.Number)
+		}
+		if name == current {
+			item += " ← this PR"
+		}
+		sb.WriteString(fmt.Sprintf("%s- %s\n", strings.Repeat("  ", depth), item))
+		for _, child := range children[name] {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 1)
+
+	sb.WriteString(prNavEnd)
+	return sb.String()
+}
+
+// upsertStackNav replaces the stack table in body, or appends it when there is none
+func upsertStackNav(body, nav string) string {
+	start := strings.Index(body, prNavStart)
+	end := strings.Index(body, prNavEnd)
+	if start >= 0 && end > start {
+		return body[:start] + nav + body[end+len(prNavEnd):]
+	}
+	if strings.TrimSpace(body) == "" {
+		return nav
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + nav
+}
+
+// UpdateStackNav brings the stack table up to date in the description of every
+// open PR in the stack of branch, looking the PRs up within scope. Failures are
+// warnings, the table is a courtesy.
+func UpdateStackNav(gitClient git.GitClient, githubClient github.GitHubClient, branch, scope string) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: not updating stack tables: %v\n", err)
+		return
+	}
+	if parents[branch] == "" {
+		return
+	}
+	root := stack.RootOf(parents, branch)
+	members := stack.Members(parents, root)
+
+	updated := 0
+	err = spinner.WrapWithSuccess("Updating the stack table in PR descriptions...", "Stack tables are up to date", func() error {
+		prs, err := LoadPRs(githubClient, members, scope)
+		if err != nil {
+			return err
+		}
+		for _, member := range members {
+			pr := prs[member]
+			if pr == nil || pr.State != "OPEN" {
+				continue
+			}
+			body, err := githubClient.GetPRBody(pr.Number)
+			if err != nil {
+				return fmt.Errorf("failed to read PR #%d: %w", pr.Number, err)
+			}
+			newBody := upsertStackNav(body, renderStackNav(parents, root, prs, member))
+			if newBody == body {
+				continue
+			}
+			if err := githubClient.UpdatePRBody(pr.Number, newBody); err != nil {
+				return fmt.Errorf("failed to update PR #%d: %w", pr.Number, err)
+			}
+			updated++
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update stack tables: %v\n", err)
+		return
+	}
+	if updated > 0 {
+		fmt.Printf("  Updated %d PR description(s)\n", updated)
+	}
+}
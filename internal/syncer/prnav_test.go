@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRenderStackNav(t *testing.T) {
+	parents := map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+		"feature-c": "feature-a",
+		"other":     "main",
+	}
+	prs := map[string]*github.PRInfo{
+		"feature-a": testutil.NewPRInfo(1, "MERGED", "main", "A", "url1"),
+		"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url2"),
+		"feature-c": testutil.NewPRInfo(3, "CLOSED", "feature-a", "C", "url3"),
+	}
+
+	nav := renderStackNav(parents, "feature-a", prs, "feature-b")
+
+	assert.Equal(t, "<!-- stackinator:stack-nav -->\n**Stack**\n\n"+
+		"- `main`\n"+
+		"  - #1\n"+
+		"    - #2 ← this PR\n"+
+		"    - `feature-c`\n"+
+		"<!-- /stackinator:stack-nav -->", nav)
+}
+
+func TestUpsertStackNav(t *testing.T) {
+	nav := prNavStart + "\nnew\n" + prNavEnd
+
+	assert.Equal(t, nav, upsertStackNav("", nav))
+	assert.Equal(t, "Fixes a bug\n\n"+nav, upsertStackNav("Fixes a bug\n", nav))
+	assert.Equal(t, "Fixes a bug\n\n"+nav+"\n\nMore",
+		upsertStackNav("Fixes a bug\n\n"+prNavStart+"\nold\n"+prNavEnd+"\n\nMore", nav))
+}
+
+func TestUpdateStackNav(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGH := new(testutil.MockGitHubClient)
+	mockGit.On("GetAllStackParents").Return(map[string]string{
+		"feature-a": "main",
+		"feature-b": "feature-a",
+		"other":     "main",
+	}, nil)
+	mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{
+		"feature-a": testutil.NewPRInfo(1, "OPEN", "main", "A", "url1"),
+		"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url2"),
+	}, nil)
+	navA := "<!-- stackinator:stack-nav -->\n**Stack**\n\n- `main`\n  - #1 ← this PR\n    - #2\n<!-- /stackinator:stack-nav -->"
+	// feature-a's table is up to date, feature-b has none yet
+	mockGH.On("GetPRBody", 1).Return("A\n\n"+navA, nil)
+	mockGH.On("GetPRBody", 2).Return("B", nil)
+	mockGH.On("UpdatePRBody", 2, mock.MatchedBy(func(body string) bool {
+		return body == "B\n\n<!-- stackinator:stack-nav -->\n**Stack**\n\n- `main`\n  - #1\n    - #2 ← this PR\n<!-- /stackinator:stack-nav -->"
+	})).Return(nil)
+
+	UpdateStackNav(mockGit, mockGH, "feature-b", PRScopeStack)
+
+	mockGH.AssertNotCalled(t, "UpdatePRBody", 1, mock.Anything)
+	mockGH.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// StackPRKey is the git config key pinning a branch to its PR number
+func StackPRKey(branch string) string {
+	return fmt.Sprintf("branch.%s.stackpr", branch)
+}
+
+// StackPRBaseKey is the git config key recording the base a branch's PR was last
+// seen with, so checks can compare it against the stack offline
+func StackPRBaseKey(branch string) string {
+	return fmt.Sprintf("branch.%s.stackprbase", branch)
+}
+
+// ResolvePinnedPRs looks up pinned PRs by number, so a branch keeps its PR after the
+// head branch is renamed on GitHub, and a PR from another fork with the same head
+// name doesn't replace it. An open PR found by branch name still wins over a pinned
+// PR that has since been closed, e.g. when a branch is reused for a new PR.
+func ResolvePinnedPRs(githubClient github.GitHubClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, pins map[string]int) {
+	for _, branch := range branches {
+		number, pinned := pins[branch.Name]
+		if !pinned {
+			continue
+		}
+
+		cached := prCache[branch.Name]
+		if cached != nil && cached.Number == number {
+			continue
+		}
+
+		pr, err := githubClient.GetPRByNumber(number)
+		if err != nil || pr == nil {
+			if git.Verbose {
+				fmt.Printf("  Pinned PR #%d for %s not found\n", number, branch.Name)
+			}
+			continue
+		}
+		if cached != nil && cached.State == "OPEN" && pr.State != "OPEN" {
+			continue
+		}
+
+		if git.Verbose && cached != nil {
+			fmt.Printf("  Using pinned PR #%d for %s instead of #%d\n", number, branch.Name, cached.Number)
+		}
+		prCache[branch.Name] = pr
+	}
+}
+
+// pinPRs queues the PR number of each branch whose PR was found, or whose PR changed,
+// along with the base of open PRs
+func pinPRs(batch *configBatch, branches []stack.StackBranch, prCache map[string]*github.PRInfo, pins map[string]int, bases map[string]string) {
+	if git.DryRun {
+		return
+	}
+
+	for _, branch := range branches {
+		pr := prCache[branch.Name]
+		if pr == nil {
+			continue
+		}
+		if pins[branch.Name] != pr.Number {
+			batch.Set(StackPRKey(branch.Name), strconv.Itoa(pr.Number))
+		}
+		if pr.State == "OPEN" && bases[branch.Name] != pr.Base {
+			recordPRBase(batch, branch.Name, pr.Base)
+		}
+	}
+}
+
+// recordPRBase remembers the base a branch's PR now targets
+func recordPRBase(batch *configBatch, branch, base string) {
+	if git.DryRun {
+		return
+	}
+	batch.Set(StackPRBaseKey(branch), base)
+}
@@ -0,0 +1,91 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestResolvePinnedPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	branches := []stack.StackBranch{{Name: "feature-a", Parent: "main"}}
+
+	t.Run("finds PR after head branch was renamed", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		renamed := testutil.NewPRInfo(12, "OPEN", "main", "Feature A", "url")
+		mockGH.On("GetPRByNumber", 12).Return(renamed, nil)
+		prCache := map[string]*github.PRInfo{}
+
+		ResolvePinnedPRs(mockGH, branches, prCache, map[string]int{"feature-a": 12})
+
+		assert.Equal(t, renamed, prCache["feature-a"])
+	})
+
+	t.Run("pinned PR wins over fork PR with same head name", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		ours := testutil.NewPRInfo(12, "OPEN", "main", "Feature A", "url")
+		mockGH.On("GetPRByNumber", 12).Return(ours, nil)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(99, "OPEN", "main", "Someone else's feature-a", "url"),
+		}
+
+		ResolvePinnedPRs(mockGH, branches, prCache, map[string]int{"feature-a": 12})
+
+		assert.Equal(t, 12, prCache["feature-a"].Number)
+	})
+
+	t.Run("open PR wins over closed pinned PR", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRByNumber", 12).Return(testutil.NewPRInfo(12, "CLOSED", "main", "Old attempt", "url"), nil)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(20, "OPEN", "main", "Feature A", "url"),
+		}
+
+		ResolvePinnedPRs(mockGH, branches, prCache, map[string]int{"feature-a": 12})
+
+		assert.Equal(t, 20, prCache["feature-a"].Number)
+	})
+
+	t.Run("cache already matches pin", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		prCache := map[string]*github.PRInfo{
+			"feature-a": testutil.NewPRInfo(12, "OPEN", "main", "Feature A", "url"),
+		}
+
+		ResolvePinnedPRs(mockGH, branches, prCache, map[string]int{"feature-a": 12})
+
+		mockGH.AssertNotCalled(t, "GetPRByNumber", mock.Anything)
+	})
+}
+
+func TestPinPRs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+		{Name: "feature-c", Parent: "feature-b"},
+	}
+	prCache := map[string]*github.PRInfo{
+		"feature-a": testutil.NewPRInfo(12, "OPEN", "main", "A", "url"),
+		"feature-b": testutil.NewPRInfo(13, "OPEN", "feature-a", "B", "url"),
+	}
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("SetConfig", "branch.feature-b.stackpr", "13").Return(nil)
+	mockGit.On("SetConfig", "branch.feature-b.stackprbase", "feature-a").Return(nil)
+
+	batch := newConfigBatch()
+	pinPRs(batch, branches, prCache, map[string]int{"feature-a": 12}, map[string]string{"feature-a": "main"})
+	assert.NoError(t, batch.Flush(mockGit))
+
+	mockGit.AssertExpectations(t)
+	mockGit.AssertNumberOfCalls(t, "SetConfig", 2)
+}
@@ -0,0 +1,181 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+)
+
+// positionLabelPattern and positionTitlePattern match the marks UpdateStackPositions
+// adds, so they can be replaced when the stack changes
+var (
+	positionLabelPattern = regexp.MustCompile(`^stack:\d+/\d+$`)
+	positionTitlePattern = regexp.MustCompile(`^\[\d+/\d+\] `)
+)
+
+// PositionMode is how PRs are marked with their place in the stack
+type PositionMode struct {
+	Labels bool
+	Titles bool
+}
+
+func (m PositionMode) Enabled() bool {
+	return m.Labels || m.Titles
+}
+
+// stackPosition is a PR's place in its stack, e.g. 2 of 5
+type stackPosition struct {
+	Index int
+	Total int
+}
+
+func (p stackPosition) label() string {
+	return fmt.Sprintf("stack:%d/%d", p.Index, p.Total)
+}
+
+// withPosition replaces the position prefix of title, adding one if there's none
+func (p stackPosition) withPosition(title string) string {
+	return fmt.Sprintf("[%d/%d] %s", p.Index, p.Total, positionTitlePattern.ReplaceAllString(title, ""))
+}
+
+// stackPositions numbers the layers of a stack: its members with an open PR,
+// leaving out umbrellas. A layer's index counts itself and the layers below it;
+// the total adds the longest run of layers above it, so a linear stack reads
+// 1/N to N/N and merging or adding a layer renumbers the rest.
+func stackPositions(parents map[string]string, members []string, prs map[string]*github.PRInfo, umbrellas map[string]bool) map[string]stackPosition {
+	inStack := make(map[string]bool, len(members))
+	children := make(map[string][]string)
+	for _, member := range members {
+		inStack[member] = true
+		children[parents[member]] = append(children[parents[member]], member)
+	}
+	isLayer := func(branch string) bool {
+		pr := prs[branch]
+		return pr != nil && pr.State == "OPEN" && !umbrellas[branch]
+	}
+
+	// Layers above each branch, following its longest run of descendants
+	above := make(map[string]int)
+	visiting := make(map[string]bool)
+	var countAbove func(branch string) int
+	countAbove = func(branch string) int {
+		if n, ok := above[branch]; ok {
+			return n
+		}
+		if visiting[branch] {
+			return 0
+		}
+		visiting[branch] = true
+		most := 0
+		for _, child := range children[branch] {
+			n := countAbove(child)
+			if isLayer(child) {
+				n++
+			}
+			most = max(most, n)
+		}
+		above[branch] = most
+		return most
+	}
+
+	positions := make(map[string]stackPosition)
+	for _, member := range members {
+		if !isLayer(member) {
+			continue
+		}
+		index := 0
+		seen := make(map[string]bool)
+		for branch := member; inStack[branch] && !seen[branch]; branch = parents[branch] {
+			seen[branch] = true
+			if isLayer(branch) {
+				index++
+			}
+		}
+		positions[member] = stackPosition{Index: index, Total: index + countAbove(member)}
+	}
+	return positions
+}
+
+// positionLabelChanges returns the labels to add and remove so a PR's position
+// label matches position. Labels that weren't fetched are taken as none.
+func positionLabelChanges(labels []string, position stackPosition) (add, remove []string) {
+	want := position.label()
+	found := false
+	for _, label := range labels {
+		switch {
+		case label == want:
+			found = true
+		case positionLabelPattern.MatchString(label):
+			remove = append(remove, label)
+		}
+	}
+	if !found {
+		add = []string{want}
+	}
+	return add, remove
+}
+
+// UpdateStackPositions marks every open PR in the stack of branch with its place
+// in it, as mode says, looking the PRs up within scope. PRs already marked right
+// are left alone. Failures are warnings, the marks are a courtesy.
+func UpdateStackPositions(gitClient git.GitClient, githubClient github.GitHubClient, branch string, mode PositionMode, scope string) {
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: not updating PR positions: %v\n", err)
+		return
+	}
+	if parents[branch] == "" {
+		return
+	}
+	members := stack.Members(parents, stack.RootOf(parents, branch))
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	updated := 0
+	err = spinner.WrapWithSuccess("Updating the position of each PR in the stack...", "PR positions are up to date", func() error {
+		prs, err := LoadPRs(githubClient, members, scope)
+		if err != nil {
+			return err
+		}
+		positions := stackPositions(parents, members, prs, umbrellas)
+		for _, member := range members {
+			position, ok := positions[member]
+			if !ok {
+				continue
+			}
+			pr := prs[member]
+			changed := false
+			if mode.Titles {
+				if title := position.withPosition(pr.Title); title != pr.Title {
+					if err := githubClient.UpdatePRTitle(pr.Number, title); err != nil {
+						return fmt.Errorf("failed to update the title of PR #%d: %w", pr.Number, err)
+					}
+					changed = true
+				}
+			}
+			if mode.Labels {
+				if add, remove := positionLabelChanges(pr.Labels, position); len(add) > 0 || len(remove) > 0 {
+					if err := githubClient.EditPRLabels(pr.Number, add, remove); err != nil {
+						return fmt.Errorf("failed to update the labels of PR #%d: %w", pr.Number, err)
+					}
+					changed = true
+				}
+			}
+			if changed {
+				updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update PR positions: %v\n", err)
+		return
+	}
+	if updated > 0 {
+		fmt.Printf("  Updated the position of %d PR(s)\n", updated)
+	}
+}
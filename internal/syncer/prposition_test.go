@@ -0,0 +1,87 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStackPositions(t *testing.T) {
+	open := func(number int) *github.PRInfo { return &github.PRInfo{Number: number, State: "OPEN"} }
+
+	t.Run("linear stack skips merged layers", func(t *testing.T) {
+		parents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-b", "feature-d": "feature-c"}
+		prs := map[string]*github.PRInfo{
+			"feature-a": {Number: 1, State: "MERGED"},
+			"feature-b": open(2),
+			"feature-c": open(3),
+			"feature-d": open(4),
+		}
+
+		positions := stackPositions(parents, stack.Members(parents, "feature-a"), prs, nil)
+
+		assert.Equal(t, map[string]stackPosition{
+			"feature-b": {Index: 1, Total: 3},
+			"feature-c": {Index: 2, Total: 3},
+			"feature-d": {Index: 3, Total: 3},
+		}, positions)
+	})
+
+	t.Run("branching stack counts the longest run above", func(t *testing.T) {
+		parents := map[string]string{"feature-a": "main", "feature-b": "feature-a", "feature-c": "feature-a", "feature-d": "feature-c", "feature-e": "feature-d"}
+		prs := map[string]*github.PRInfo{
+			"feature-a": open(1),
+			"feature-b": open(2),
+			"feature-c": open(3),
+			"feature-d": open(4),
+		}
+
+		positions := stackPositions(parents, stack.Members(parents, "feature-a"), prs, nil)
+
+		assert.Equal(t, stackPosition{Index: 1, Total: 3}, positions["feature-a"])
+		assert.Equal(t, stackPosition{Index: 2, Total: 2}, positions["feature-b"])
+		assert.Equal(t, stackPosition{Index: 3, Total: 3}, positions["feature-d"])
+		// No PR, no position
+		assert.NotContains(t, positions, "feature-e")
+	})
+}
+
+func TestPositionMarks(t *testing.T) {
+	position := stackPosition{Index: 2, Total: 3}
+
+	assert.Equal(t, "[2/3] Add logout", position.withPosition("Add logout"))
+	assert.Equal(t, "[2/3] Add logout", position.withPosition("[2/4] Add logout"))
+	assert.Equal(t, "[2/3] [WIP] Add logout", position.withPosition("[WIP] Add logout"))
+
+	add, remove := positionLabelChanges([]string{"bug", "stack:2/4"}, position)
+	assert.Equal(t, []string{"stack:2/3"}, add)
+	assert.Equal(t, []string{"stack:2/4"}, remove)
+
+	add, remove = positionLabelChanges([]string{"stack:2/3"}, position)
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+}
+
+func TestUpdateStackPositions(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("GetAllStackParents").Return(map[string]string{"feature-a": "main", "feature-b": "feature-a"}, nil)
+	mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil)
+	mockGH := new(testutil.MockGitHubClient)
+	mockGH.On("GetPRsForBranches", []string{"feature-a", "feature-b"}).Return(map[string]*github.PRInfo{
+		"feature-a": {Number: 1, State: "OPEN", Title: "[1/2] Add login", Labels: []string{"stack:1/2"}},
+		"feature-b": {Number: 2, State: "OPEN", Title: "[2/3] Add logout", Labels: []string{"stack:2/3"}},
+	}, nil)
+	mockGH.On("UpdatePRTitle", 2, "[2/2] Add logout").Return(nil)
+	mockGH.On("EditPRLabels", 2, []string{"stack:2/2"}, []string{"stack:2/3"}).Return(nil)
+
+	UpdateStackPositions(mockGit, mockGH, "feature-b", PositionMode{Labels: true, Titles: true}, PRScopeStack)
+
+	mockGH.AssertExpectations(t)
+	mockGH.AssertNotCalled(t, "UpdatePRTitle", 1, "[1/2] Add login")
+}
@@ -0,0 +1,23 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+// DeleteBranch deletes a branch using 'git branch -d' (safe delete)
+func DeleteBranch(gitClient git.GitClient, name string) error {
+	if git.Verbose {
+		fmt.Printf("  [git] branch -d %s\n", name)
+	}
+	return gitClient.DeleteBranch(name)
+}
+
+// DeleteBranchForce deletes a branch using 'git branch -D' (force delete)
+func DeleteBranchForce(gitClient git.GitClient, name string) error {
+	if git.Verbose {
+		fmt.Printf("  [git] branch -D %s\n", name)
+	}
+	return gitClient.DeleteBranchForce(name)
+}
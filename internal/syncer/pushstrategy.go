@@ -0,0 +1,78 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+// Push strategies, from safest to least safe
+const (
+	// PushLeaseSHA refreshes origin/<branch> and pushes with --force-with-lease=<branch>:<sha>
+	PushLeaseSHA = "lease-sha"
+	// PushLease pushes with plain --force-with-lease against the current tracking ref
+	PushLease = "lease"
+	// PushForce pushes with --force, overwriting whatever is on the remote
+	PushForce = "force"
+)
+
+// PushStrategyFor returns the push strategy for a branch: force for branches
+// matching one of forceBranches, strategy for the rest
+func PushStrategyFor(branch, strategy string, forceBranches []string) string {
+	for _, pattern := range forceBranches {
+		if matched, _ := path.Match(pattern, branch); matched {
+			return PushForce
+		}
+	}
+	return strategy
+}
+
+// pushStrategyFor returns the push strategy for a branch; Force applies to every
+// branch
+func (e *Engine) pushStrategyFor(branch string) string {
+	if e.options.Force {
+		return PushForce
+	}
+	return PushStrategyFor(branch, e.options.PushStrategy, e.options.ForceBranches)
+}
+
+// PushBranch force-pushes a rebased branch with the given strategy
+func PushBranch(gitClient git.GitClient, branch, strategy string) error {
+	switch strategy {
+	case PushForce:
+		// Use regular --force (bypasses --force-with-lease safety checks)
+		if git.Verbose {
+			fmt.Printf("  Using --force (bypassing safety checks)\n")
+		}
+		return gitClient.ForcePush(branch)
+	case PushLease:
+		return gitClient.Push(branch, true)
+	}
+
+	// Fetch one more time right before push to get the current remote SHA
+	if git.Verbose {
+		fmt.Printf("  Refreshing remote tracking ref before push...\n")
+	}
+	if err := gitClient.FetchBranch(branch); err != nil {
+		// Non-fatal, continue with push using plain --force-with-lease
+		if git.Verbose {
+			fmt.Fprintf(os.Stderr, "  Note: could not refresh tracking ref: %v\n", err)
+		}
+		return gitClient.Push(branch, true)
+	}
+
+	// Get the remote SHA to use with explicit --force-with-lease
+	// This avoids "stale info" errors that can occur with plain --force-with-lease
+	remoteSha, err := gitClient.GetCommitHash(git.RemoteRef(branch))
+	if err != nil {
+		// Fall back to plain --force-with-lease
+		if git.Verbose {
+			fmt.Fprintf(os.Stderr, "  Note: could not get remote SHA, using plain force-with-lease: %v\n", err)
+		}
+		return gitClient.Push(branch, true)
+	}
+
+	return gitClient.PushWithExpectedRemote(branch, remoteSha)
+}
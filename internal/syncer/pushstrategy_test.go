@@ -0,0 +1,77 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushStrategyFor(t *testing.T) {
+	patterns := []string{"sandbox/*", "wip-*"}
+
+	tests := []struct {
+		name     string
+		branch   string
+		force    bool
+		strategy string
+		expected string
+	}{
+		{"default", "feature-a", false, PushLeaseSHA, PushLeaseSHA},
+		{"configured default", "feature-a", false, PushLease, PushLease},
+		{"matches pattern", "sandbox/try-this", false, PushLeaseSHA, PushForce},
+		{"matches second pattern", "wip-auth", false, PushLease, PushForce},
+		{"pattern does not cross slashes", "sandbox/a/b", false, PushLeaseSHA, PushLeaseSHA},
+		{"--force wins", "feature-a", true, PushLeaseSHA, PushForce},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New(nil, nil, Options{Force: tt.force, PushStrategy: tt.strategy, ForceBranches: patterns}, nil)
+
+			assert.Equal(t, tt.expected, engine.pushStrategyFor(tt.branch))
+		})
+	}
+}
+
+func TestPushBranch(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("lease-sha pushes against refreshed remote SHA", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchBranch", "feature-a").Return(nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("abc123", nil)
+		mockGit.On("PushWithExpectedRemote", "feature-a", "abc123").Return(nil)
+
+		assert.NoError(t, PushBranch(mockGit, "feature-a", PushLeaseSHA))
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("lease-sha falls back to plain lease", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("FetchBranch", "feature-a").Return(errors.New("network"))
+		mockGit.On("Push", "feature-a", true).Return(nil)
+
+		assert.NoError(t, PushBranch(mockGit, "feature-a", PushLeaseSHA))
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("lease skips the refresh", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("Push", "feature-a", true).Return(nil)
+
+		assert.NoError(t, PushBranch(mockGit, "feature-a", PushLease))
+		mockGit.AssertExpectations(t)
+		mockGit.AssertNotCalled(t, "FetchBranch", "feature-a")
+	})
+
+	t.Run("force", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("ForcePush", "sandbox/x").Return(nil)
+
+		assert.NoError(t, PushBranch(mockGit, "sandbox/x", PushForce))
+		mockGit.AssertExpectations(t)
+	})
+}
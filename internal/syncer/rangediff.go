@@ -0,0 +1,39 @@
+package syncer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+)
+
+// FormatRangeDiffComment formats a range-diff as a markdown PR comment
+func FormatRangeDiffComment(summary git.RangeDiffSummary, output string) string {
+	var b strings.Builder
+	b.WriteString("**Changes since last push**\n\n")
+
+	if !summary.HasContentChanges() {
+		b.WriteString("Rebased only, no content changes.\n")
+	}
+	writeList := func(label string, subjects []string) {
+		if len(subjects) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "%s:\n", label)
+		for _, subject := range subjects {
+			fmt.Fprintf(&b, "- %s\n", subject)
+		}
+		b.WriteString("\n")
+	}
+	writeList("Changed", summary.Changed)
+	writeList("Added", summary.Added)
+	writeList("Dropped", summary.Dropped)
+	if len(summary.Unchanged) > 0 {
+		fmt.Fprintf(&b, "%d commit(s) unchanged.\n", len(summary.Unchanged))
+	}
+
+	b.WriteString("\n<details><summary>git range-diff</summary>\n\n```diff\n")
+	b.WriteString(output)
+	b.WriteString("\n```\n\n</details>\n")
+	return b.String()
+}
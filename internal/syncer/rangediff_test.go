@@ -0,0 +1,23 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatRangeDiffComment(t *testing.T) {
+	rebased := FormatRangeDiffComment(git.RangeDiffSummary{Unchanged: []string{"Add feature"}}, "1:  abc = 1:  def Add feature")
+	assert.Contains(t, rebased, "Rebased only, no content changes.")
+	assert.Contains(t, rebased, "1 commit(s) unchanged.")
+
+	changed := FormatRangeDiffComment(git.RangeDiffSummary{
+		Changed: []string{"Add feature"},
+		Dropped: []string{"Debug logging"},
+	}, "output")
+	assert.NotContains(t, changed, "Rebased only")
+	assert.Contains(t, changed, "Changed:\n- Add feature\n")
+	assert.Contains(t, changed, "Dropped:\n- Debug logging\n")
+	assert.Contains(t, changed, "```diff\noutput\n```")
+}
@@ -0,0 +1,27 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/github"
+)
+
+// PRNotOpenError is returned by RetargetPR when the PR merged or was closed
+// after it was looked up, so there was no base left to change
+type PRNotOpenError struct {
+	PR *github.PRInfo // The PR as it is now
+}
+
+func (e *PRNotOpenError) Error() string {
+	return fmt.Sprintf("PR #%d is %s", e.PR.Number, e.PR.State)
+}
+
+// RetargetPR changes the base of PR number to base, looking the PR up again
+// first: a sync takes a while, and a PR that merged in the meantime would make
+// gh fail. If the lookup itself fails, the update is tried anyway.
+func RetargetPR(githubClient github.GitHubClient, number int, base string) error {
+	if current, err := githubClient.GetPRByNumber(number); err == nil && current != nil && current.State != "OPEN" {
+		return &PRNotOpenError{PR: current}
+	}
+	return githubClient.UpdatePRBase(number, base)
+}
@@ -0,0 +1,42 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRetargetPR(t *testing.T) {
+	t.Run("open PR", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRByNumber", 2).Return(testutil.NewPRInfo(2, "OPEN", "main", "B", "url"), nil)
+		mockGH.On("UpdatePRBase", 2, "feature-a").Return(nil)
+
+		assert.NoError(t, RetargetPR(mockGH, 2, "feature-a"))
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("PR merged in the meantime", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRByNumber", 2).Return(testutil.NewPRInfo(2, "MERGED", "main", "B", "url"), nil)
+
+		err := RetargetPR(mockGH, 2, "feature-a")
+
+		var notOpen *PRNotOpenError
+		assert.ErrorAs(t, err, &notOpen)
+		assert.Equal(t, "MERGED", notOpen.PR.State)
+		mockGH.AssertNotCalled(t, "UpdatePRBase", mock.Anything, mock.Anything)
+	})
+
+	t.Run("lookup fails", func(t *testing.T) {
+		mockGH := new(testutil.MockGitHubClient)
+		mockGH.On("GetPRByNumber", 2).Return(nil, errors.New("gh failed"))
+		mockGH.On("UpdatePRBase", 2, "feature-a").Return(nil)
+
+		assert.NoError(t, RetargetPR(mockGH, 2, "feature-a"))
+		mockGH.AssertExpectations(t)
+	})
+}
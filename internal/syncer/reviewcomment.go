@@ -0,0 +1,156 @@
+package syncer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// pushedState is what a branch looked like on origin before sync pushed it
+type pushedState struct {
+	head string // origin/<branch> commit
+	base string // merge base with the parent as it was on origin
+}
+
+// capturePushedStates records the pushed head and base of every branch whose PR has
+// reviews. This must happen before the loop pushes anything: once a parent is pushed,
+// origin/<parent> no longer shows what the child's reviewers saw.
+func capturePushedStates(gitClient git.GitClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, remoteBranches map[string]bool) map[string]pushedState {
+	states := make(map[string]pushedState)
+	for _, branch := range branches {
+		pr, hasPR := prCache[branch.Name]
+		if !hasPR || pr.State != "OPEN" || !pr.Reviewed || !remoteBranches[branch.Name] {
+			continue
+		}
+
+		head, err := gitClient.GetCommitHash(git.RemoteRef(branch.Name))
+		if err != nil {
+			continue
+		}
+		parentRef := branch.Parent
+		if remoteBranches[branch.Parent] {
+			parentRef = git.RemoteRef(branch.Parent)
+		}
+		base, err := gitClient.GetMergeBase(parentRef, head)
+		if err != nil {
+			continue
+		}
+		states[branch.Name] = pushedState{head: head, base: base}
+	}
+	return states
+}
+
+// postReviewComment comments on a PR what changed between the previously pushed
+// commits and the ones just pushed. Failures are only warnings: the push succeeded.
+func (e *Engine) postReviewComment(gitClient git.GitClient, githubClient github.GitHubClient, prNumber int, branch, parent string, before pushedState) {
+	output, summary, changed, err := diffSincePush(gitClient, branch, parent, before)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: could not compare with previous push: %v\n", err)
+		return
+	}
+	if !changed {
+		return
+	}
+
+	body := formatReviewSummary(summary, parent)
+	if e.options.ReviewComment == "range-diff" {
+		body = FormatRangeDiffComment(summary, output)
+	}
+	if err := githubClient.CommentOnPR(prNumber, body); err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: failed to comment on PR #%d: %v\n", prNumber, err)
+		return
+	}
+	fmt.Printf("  %s Commented on PR #%d what changed since the last push\n", ui.SuccessIcon(), prNumber)
+}
+
+// diffSincePush range-diffs the previously pushed commits against the local branch.
+// changed is false when the branch still points at the pushed commit.
+func diffSincePush(gitClient git.GitClient, branch, parent string, before pushedState) (output string, summary git.RangeDiffSummary, changed bool, err error) {
+	newHead, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		return "", summary, false, err
+	}
+	if newHead == before.head {
+		return "", summary, false, nil
+	}
+	newBase, err := gitClient.GetMergeBase(parent, newHead)
+	if err != nil {
+		return "", summary, false, err
+	}
+	output, err = gitClient.RangeDiff(before.base, before.head, newBase, newHead)
+	if err != nil {
+		return "", summary, false, err
+	}
+	return output, git.ParseRangeDiff(output), true, nil
+}
+
+// confirmApprovalInvalidation stops a push that would change the content of an approved
+// PR, since many repos dismiss approvals on push. Pure rebases pass. Otherwise the user
+// is asked to confirm, unless --allow-review-invalidation was given.
+func (e *Engine) confirmApprovalInvalidation(gitClient git.GitClient, prNumber int, branch, parent string, before pushedState) error {
+	_, summary, changed, err := diffSincePush(gitClient, branch, parent, before)
+	if err != nil {
+		return fmt.Errorf("could not check whether approved PR #%d changes: %w", prNumber, err)
+	}
+	if !changed || !summary.HasContentChanges() {
+		return nil
+	}
+
+	fmt.Printf("  %s PR #%d is approved, and this push changes its content: %s\n",
+		ui.WarningIcon(), prNumber, describeContentChanges(summary))
+	if e.options.AllowReviewInvalidation {
+		fmt.Println("  Pushing anyway (--allow-review-invalidation)")
+		return nil
+	}
+
+	fmt.Print("  Push anyway? This may dismiss the approval. [y/N] ")
+	input, err := bufio.NewReader(e.Input).ReadString('\n')
+	if err != nil && input == "" {
+		fmt.Println()
+		return fmt.Errorf("refusing to push approved PR #%d with changed content (use --allow-review-invalidation)", prNumber)
+	}
+	answer := strings.ToLower(strings.TrimSpace(input))
+	if answer != "y" && answer != "yes" {
+		return fmt.Errorf("refusing to push approved PR #%d with changed content (use --allow-review-invalidation)", prNumber)
+	}
+	return nil
+}
+
+// formatReviewSummary is a short comment telling reviewers whether re-review is needed
+func formatReviewSummary(summary git.RangeDiffSummary, parent string) string {
+	parent = git.TrimRemote(parent)
+	if !summary.HasContentChanges() {
+		return fmt.Sprintf("Rebased onto `%s`, no content changes.", parent)
+	}
+	return fmt.Sprintf("Rebased onto `%s`: %s.", parent, describeContentChanges(summary))
+}
+
+// describeContentChanges lists changed, added and dropped commits in one phrase
+func describeContentChanges(summary git.RangeDiffSummary) string {
+	var parts []string
+	if len(summary.Changed) > 0 {
+		parts = append(parts, "content changed in "+quoteSubjects(summary.Changed))
+	}
+	if len(summary.Added) > 0 {
+		parts = append(parts, "added "+quoteSubjects(summary.Added))
+	}
+	if len(summary.Dropped) > 0 {
+		parts = append(parts, "dropped "+quoteSubjects(summary.Dropped))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// quoteSubjects joins commit subjects as quoted, comma-separated text
+func quoteSubjects(subjects []string) string {
+	quoted := make([]string, len(subjects))
+	for i, subject := range subjects {
+		quoted[i] = fmt.Sprintf("%q", subject)
+	}
+	return strings.Join(quoted, ", ")
+}
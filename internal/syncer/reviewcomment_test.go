@@ -0,0 +1,161 @@
+package syncer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCapturePushedStates(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+
+	reviewed := testutil.NewPRInfo(1, "OPEN", "main", "A", "url")
+	reviewed.Reviewed = true
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+	}
+	prCache := map[string]*github.PRInfo{
+		"feature-a": reviewed,
+		"feature-b": testutil.NewPRInfo(2, "OPEN", "feature-a", "B", "url"), // no reviews yet
+	}
+	remoteBranches := map[string]bool{"main": true, "feature-a": true, "feature-b": true}
+
+	mockGit.On("GetCommitHash", "origin/feature-a").Return("old123", nil)
+	mockGit.On("GetMergeBase", "origin/main", "old123").Return("base0", nil)
+
+	states := capturePushedStates(mockGit, branches, prCache, remoteBranches)
+
+	assert.Equal(t, map[string]pushedState{"feature-a": {head: "old123", base: "base0"}}, states)
+	mockGit.AssertExpectations(t)
+}
+
+func TestPostReviewComment(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	before := pushedState{head: "old123", base: "base0"}
+
+	t.Run("summary for rebase without content changes", func(t *testing.T) {
+		engine := New(nil, nil, Options{ReviewComment: "summary"}, nil)
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("new456", nil)
+		mockGit.On("GetMergeBase", "origin/main", "new456").Return("base1", nil)
+		mockGit.On("RangeDiff", "base0", "old123", "base1", "new456").Return("1:  abc1234 = 1:  def5678 Add feature", nil)
+		mockGH.On("CommentOnPR", 1, "Rebased onto `main`, no content changes.").Return(nil)
+
+		engine.postReviewComment(mockGit, mockGH, 1, "feature-a", "origin/main", before)
+
+		mockGit.AssertExpectations(t)
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("range-diff comment", func(t *testing.T) {
+		engine := New(nil, nil, Options{ReviewComment: "range-diff"}, nil)
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("new456", nil)
+		mockGit.On("GetMergeBase", "origin/main", "new456").Return("base1", nil)
+		mockGit.On("RangeDiff", "base0", "old123", "base1", "new456").Return("1:  abc1234 ! 1:  def5678 Add feature", nil)
+		mockGH.On("CommentOnPR", 1, mock.MatchedBy(func(body string) bool {
+			return assert.Contains(t, body, "Changed:\n- Add feature")
+		})).Return(nil)
+
+		engine.postReviewComment(mockGit, mockGH, 1, "feature-a", "origin/main", before)
+
+		mockGH.AssertExpectations(t)
+	})
+
+	t.Run("nothing pushed", func(t *testing.T) {
+		engine := New(nil, nil, Options{}, nil)
+		mockGit := new(testutil.MockGitClient)
+		mockGH := new(testutil.MockGitHubClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("old123", nil)
+
+		engine.postReviewComment(mockGit, mockGH, 1, "feature-a", "origin/main", before)
+
+		mockGH.AssertNotCalled(t, "CommentOnPR", mock.Anything, mock.Anything)
+	})
+}
+
+func TestFormatReviewSummary(t *testing.T) {
+	summary := git.RangeDiffSummary{
+		Changed:   []string{"Add feature"},
+		Added:     []string{"Add tests"},
+		Unchanged: []string{"Refactor"},
+	}
+
+	assert.Equal(t,
+		"Rebased onto `feature-a`: content changed in \"Add feature\"; added \"Add tests\".",
+		formatReviewSummary(summary, "feature-a"))
+}
+
+func TestConfirmApprovalInvalidation(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	before := pushedState{head: "aaa1111", base: "base0"}
+
+	tests := []struct {
+		name        string
+		rangeDiff   string
+		input       string
+		allow       bool
+		expectError bool
+	}{
+		{
+			name:      "rebase only passes",
+			rangeDiff: "1:  aaa1111 = 1:  bbb2222 Add feature",
+		},
+		{
+			name:        "content change refused",
+			rangeDiff:   "1:  aaa1111 ! 1:  bbb2222 Add feature",
+			input:       "n\n",
+			expectError: true,
+		},
+		{
+			name:        "content change without input refused",
+			rangeDiff:   "1:  aaa1111 ! 1:  bbb2222 Add feature",
+			input:       "",
+			expectError: true,
+		},
+		{
+			name:      "content change confirmed",
+			rangeDiff: "1:  aaa1111 ! 1:  bbb2222 Add feature",
+			input:     "y\n",
+		},
+		{
+			name:      "content change allowed by flag",
+			rangeDiff: "-:  ------- > 2:  ccc3333 Add tests",
+			allow:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New(nil, nil, Options{AllowReviewInvalidation: tt.allow}, nil)
+			engine.Input = strings.NewReader(tt.input)
+
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCommitHash", "feature-a").Return("bbb2222", nil)
+			mockGit.On("GetMergeBase", "origin/main", "bbb2222").Return("base1", nil)
+			mockGit.On("RangeDiff", "base0", "aaa1111", "base1", "bbb2222").Return(tt.rangeDiff, nil)
+
+			err := engine.confirmApprovalInvalidation(mockGit, 1, "feature-a", "origin/main", before)
+
+			if tt.expectError {
+				assert.ErrorContains(t, err, "--allow-review-invalidation")
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
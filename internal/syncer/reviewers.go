@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/codeowners"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// LoadCodeOwners reads the CODEOWNERS file at ref from the first location GitHub checks
+func LoadCodeOwners(gitClient git.GitClient, ref string) (*codeowners.File, error) {
+	for _, path := range codeowners.Locations {
+		if content, err := gitClient.ShowFile(ref, path); err == nil {
+			return codeowners.Parse(content), nil
+		}
+	}
+	return nil, fmt.Errorf("no CODEOWNERS file found on %s", ref)
+}
+
+// CurrentUser returns the authenticated user's login, or "" if it can't be determined
+func CurrentUser(githubClient github.GitHubClient) string {
+	login, err := githubClient.GetCurrentUser()
+	if err != nil {
+		return ""
+	}
+	return login
+}
+
+// SuggestReviewers returns the owners of the files branch changes relative to its
+// parent, leaving out self (PR authors can't review their own PRs) and email
+// owners, which can't be requested. Also returns the number of changed files.
+func SuggestReviewers(gitClient git.GitClient, owners *codeowners.File, branch stack.StackBranch, baseBranch, self string) ([]string, int, error) {
+	parent := branch.Parent
+	if parent == baseBranch {
+		parent = git.RemoteRef(baseBranch)
+	}
+	files, err := gitClient.GetChangedFiles(parent, branch.Name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var suggested []string
+	for _, owner := range owners.OwnersOf(files) {
+		if !strings.HasPrefix(owner, "@") || strings.EqualFold(owner, "@"+self) {
+			continue
+		}
+		suggested = append(suggested, owner)
+	}
+	return suggested, len(files), nil
+}
+
+// RequestReviewers requests owners ("@alice", "@org/team") on a PR. Failures are
+// only warned about, e.g. when a team isn't visible to the user.
+func RequestReviewers(githubClient github.GitHubClient, prNumber int, owners []string) {
+	reviewers := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		reviewers = append(reviewers, strings.TrimPrefix(owner, "@"))
+	}
+	if err := githubClient.RequestReviewers(prNumber, reviewers); err != nil {
+		fmt.Fprintf(os.Stderr, "  Warning: failed to request reviewers on PR #%d: %v\n", prNumber, err)
+		return
+	}
+	if !git.DryRun {
+		fmt.Printf("  %s Requested reviews on PR #%d from %s\n", ui.SuccessIcon(), prNumber, strings.Join(owners, ", "))
+	}
+}
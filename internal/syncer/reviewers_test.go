@@ -0,0 +1,50 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/codeowners"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestReviewers(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	owners := codeowners.Parse("* @org/core\n/cmd/ @alice\n/docs/ docs@example.com\n/internal/git/ @me-user @bob\n")
+
+	t.Run("bottom branch is diffed against origin base", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetChangedFiles", "origin/main", "feature-a").Return([]string{"cmd/sync.go", "cmd/status.go", "go.mod"}, nil)
+
+		suggested, files, err := SuggestReviewers(mockGit, owners, stack.StackBranch{Name: "feature-a", Parent: "main"}, "main", "me-user")
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, files)
+		assert.Equal(t, []string{"@alice", "@org/core"}, suggested)
+	})
+
+	t.Run("leaves out self and email owners", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetChangedFiles", "feature-a", "feature-b").Return([]string{"internal/git/git.go", "docs/commands.md"}, nil)
+
+		suggested, _, err := SuggestReviewers(mockGit, owners, stack.StackBranch{Name: "feature-b", Parent: "feature-a"}, "main", "ME-USER")
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"@bob"}, suggested)
+	})
+}
+
+func TestLoadCodeOwners(t *testing.T) {
+	mockGit := new(testutil.MockGitClient)
+	mockGit.On("ShowFile", "origin/main", ".github/CODEOWNERS").Return("", errors.New("missing"))
+	mockGit.On("ShowFile", "origin/main", "CODEOWNERS").Return("* @alice\n", nil)
+
+	owners, err := LoadCodeOwners(mockGit, "origin/main")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"@alice"}, owners.Owners("main.go"))
+}
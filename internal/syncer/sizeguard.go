@@ -0,0 +1,117 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// SizeLimits is how big a branch can be before it's too much to review at once
+type SizeLimits struct {
+	MaxFiles int
+	MaxLines int  // Added plus deleted lines
+	Block    bool // Stop instead of warning
+}
+
+func (l SizeLimits) Enabled() bool {
+	return l.MaxFiles > 0 || l.MaxLines > 0
+}
+
+// String describes the limits, e.g. "400 lines, 20 files"
+func (l SizeLimits) String() string {
+	var parts []string
+	if l.MaxLines > 0 {
+		parts = append(parts, fmt.Sprintf("%d lines", l.MaxLines))
+	}
+	if l.MaxFiles > 0 {
+		parts = append(parts, fmt.Sprintf("%d files", l.MaxFiles))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LayerSize is the size of what a branch changes on top of its parent
+type LayerSize struct {
+	Branch  string
+	Files   int
+	Added   int
+	Deleted int
+}
+
+func (s LayerSize) lines() int {
+	return s.Added + s.Deleted
+}
+
+// Exceeds reports whether the layer is over any of the limits
+func (s LayerSize) Exceeds(limits SizeLimits) bool {
+	return (limits.MaxFiles > 0 && s.Files > limits.MaxFiles) || (limits.MaxLines > 0 && s.lines() > limits.MaxLines)
+}
+
+// MeasureLayers sizes what each branch changes on top of its parent, comparing
+// the base branch as it is on origin like sync rebases onto it. Merged and
+// umbrella branches aren't reviewed, so they're left out.
+func MeasureLayers(gitClient git.GitClient, branches []stack.StackBranch, prCache map[string]*github.PRInfo, umbrellas map[string]bool, baseBranch string) []LayerSize {
+	var sizes []LayerSize
+	for _, branch := range branches {
+		if pr, ok := prCache[branch.Name]; (ok && pr.State == "MERGED") || umbrellas[branch.Name] {
+			continue
+		}
+		parent := branch.Parent
+		if parent == baseBranch {
+			parent = git.RemoteRef(baseBranch)
+		}
+		stats, err := gitClient.GetDiffStat(parent, branch.Name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: failed to measure the changes of %s: %v\n", branch.Name, err)
+			continue
+		}
+		size := LayerSize{Branch: branch.Name, Files: len(stats)}
+		for _, stat := range stats {
+			size.Added += stat.Added
+			size.Deleted += stat.Deleted
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes
+}
+
+// GuardStackSize reports the branches over limits before anything is rebased or
+// pushed, suggesting to split them. With stack.sizeGuard.mode=block they stop
+// the command; skip is the command that gets past the check once.
+func GuardStackSize(gitClient git.GitClient, limits SizeLimits, branches []stack.StackBranch, prCache map[string]*github.PRInfo, umbrellas map[string]bool, baseBranch, skip string) error {
+	var oversized []LayerSize
+	for _, size := range MeasureLayers(gitClient, branches, prCache, umbrellas, baseBranch) {
+		if size.Exceeds(limits) {
+			oversized = append(oversized, size)
+		}
+	}
+	if len(oversized) == 0 {
+		return nil
+	}
+
+	icon := ui.WarningIcon()
+	if limits.Block {
+		icon = ui.ErrorIcon()
+	}
+	for _, size := range oversized {
+		fmt.Fprintf(os.Stderr, "%s %s is over the review size limit (%s): %s\n", icon, ui.Branch(size.Branch), limits, DescribeLayerSize(size))
+	}
+	fmt.Fprintf(os.Stderr, "Smaller branches are quicker to review; consider splitting with '%s'.\n", ui.Command("stack split"))
+
+	if !limits.Block {
+		fmt.Fprintln(os.Stderr)
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "\nNothing was pushed. Split the branches, or skip the check once with '%s'\n", ui.Command(skip))
+	return ui.Reported(errors.New("branches over the review size limit"))
+}
+
+// DescribeLayerSize reads like "+350 -80 in 12 file(s)"
+func DescribeLayerSize(size LayerSize) string {
+	return fmt.Sprintf("+%d -%d in %d file(s)", size.Added, size.Deleted, size.Files)
+}
@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/javoire/stackinator/internal/ui"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuardStackSize(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	branches := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+		{Name: "feature-c", Parent: "feature-b"},
+	}
+	prCache := map[string]*github.PRInfo{"feature-c": {Number: 3, State: "MERGED"}}
+
+	tests := []struct {
+		name           string
+		limits         SizeLimits
+		expectReported bool
+	}{
+		{
+			// No GetDiffStat for feature-c: its PR is merged
+			name:   "warns about oversized branches",
+			limits: SizeLimits{MaxLines: 400},
+		},
+		{
+			name:           "blocks in block mode",
+			limits:         SizeLimits{MaxLines: 400, Block: true},
+			expectReported: true,
+		},
+		{
+			name:   "passes branches within the limits",
+			limits: SizeLimits{MaxLines: 1000, MaxFiles: 2, Block: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetDiffStat", "origin/main", "feature-a").Return([]git.FileStat{
+				{Path: "auth/login.go", Added: 120, Deleted: 10},
+			}, nil)
+			mockGit.On("GetDiffStat", "feature-a", "feature-b").Return([]git.FileStat{
+				{Path: "auth/logout.go", Added: 300, Deleted: 20},
+				{Path: "auth/logout_test.go", Added: 200},
+			}, nil)
+
+			err := GuardStackSize(mockGit, tt.limits, branches, prCache, nil, "main", "stack sync --no-size-guard")
+
+			if tt.expectReported {
+				var reported *ui.ReportedError
+				assert.ErrorAs(t, err, &reported)
+			} else {
+				assert.NoError(t, err)
+			}
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// ConfigSubmodulesUpdate runs 'git submodule update' after every checkout stack
+// makes, so submodules follow the branch being worked on
+const ConfigSubmodulesUpdate = "stack.submodules.update"
+
+// warnStaleSubmodules points out submodules left at another commit than the one
+// the current branch records, which otherwise show up as unexplained changes in
+// 'git status'. With stack.submodules.update they are updated instead.
+func warnStaleSubmodules(gitClient git.GitClient) {
+	if git.DryRun {
+		return
+	}
+	stale, err := gitClient.GetStaleSubmodules()
+	if err != nil || len(stale) == 0 {
+		return
+	}
+
+	if git.AutoUpdateSubmodules {
+		err := gitClient.UpdateSubmodules()
+		if err == nil {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Warning: failed to update submodules: %v\n", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "\n%s Submodules not at the commits the current branch records:\n", ui.WarningIcon())
+	for _, path := range stale {
+		fmt.Fprintf(os.Stderr, "  - %s\n", path)
+	}
+	fmt.Fprintln(os.Stderr, i18n.T("sync.submodulesHint", ui.Command("git submodule update --init --recursive")))
+	fmt.Fprintln(os.Stderr, i18n.T("sync.submodulesConfigHint", ui.Command("git config "+ConfigSubmodulesUpdate+" true")))
+}
+
+// printSubmoduleConflicts explains how to resolve conflicts in submodules, which
+// have no conflict markers to edit: the fix is to pick the commit to keep
+func printSubmoduleConflicts(gitClient git.GitClient) {
+	conflicted, err := gitClient.GetConflictedSubmodules()
+	if err != nil || len(conflicted) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\n  Conflicting submodule commits:\n")
+	for _, path := range conflicted {
+		fmt.Fprintf(os.Stderr, "    - %s\n", path)
+	}
+	fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("sync.submoduleConflictHint"))
+	fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("sync.submoduleConflictExample"))
+}
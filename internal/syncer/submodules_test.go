@@ -0,0 +1,59 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/testutil"
+)
+
+func TestWarnStaleSubmodules(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+	defer func() { git.AutoUpdateSubmodules = false }()
+
+	t.Run("warns without updating by default", func(t *testing.T) {
+		git.AutoUpdateSubmodules = false
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStaleSubmodules").Return([]string{"libs/shared"}, nil)
+
+		warnStaleSubmodules(mockGit)
+
+		mockGit.AssertNotCalled(t, "UpdateSubmodules")
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("updates when configured", func(t *testing.T) {
+		git.AutoUpdateSubmodules = true
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStaleSubmodules").Return([]string{"libs/shared"}, nil)
+		mockGit.On("UpdateSubmodules").Return(nil)
+
+		warnStaleSubmodules(mockGit)
+
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("still warns when the update fails", func(t *testing.T) {
+		git.AutoUpdateSubmodules = true
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStaleSubmodules").Return([]string{"libs/shared"}, nil)
+		mockGit.On("UpdateSubmodules").Return(errors.New("fetch failed"))
+
+		warnStaleSubmodules(mockGit)
+
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("does nothing when submodules match", func(t *testing.T) {
+		git.AutoUpdateSubmodules = true
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetStaleSubmodules").Return([]string{}, nil)
+
+		warnStaleSubmodules(mockGit)
+
+		mockGit.AssertNotCalled(t, "UpdateSubmodules")
+		mockGit.AssertExpectations(t)
+	})
+}
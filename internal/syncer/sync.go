@@ -0,0 +1,1375 @@
+package syncer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/javoire/stackinator/internal/codeowners"
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/i18n"
+	"github.com/javoire/stackinator/internal/oplog"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// Git config keys for sync state persistence
+const (
+	ConfigStashed        = "stack.sync.stashed"
+	ConfigOriginalBranch = "stack.sync.originalBranch"
+	// ConfigStartedAt is when the interrupted sync started, in Unix seconds
+	ConfigStartedAt = "stack.sync.startedAt"
+)
+
+// run is the sync itself, once Run has checked the options
+func (e *Engine) run() error {
+	gitClient, githubClient := e.gitClient, e.githubClient
+
+	// Track state for stash handling
+	var originalBranch string
+	stashed := false
+	fetched := false
+	rebaseConflict := false
+
+	// Check for existing sync state (from previous interrupted sync)
+	savedStashed := gitClient.GetConfig(ConfigStashed)
+	savedOriginalBranch := gitClient.GetConfig(ConfigOriginalBranch)
+	hasSavedState := savedStashed == "true" || savedOriginalBranch != ""
+
+	if e.options.Abort {
+		// Check if there's actually anything to abort
+		hasCherryPick := gitClient.IsCherryPickInProgress()
+		hasRebase := gitClient.IsRebaseInProgress()
+
+		if !hasSavedState && !hasCherryPick && !hasRebase {
+			return errors.New(i18n.T("sync.noneToAbort"))
+		}
+
+		fmt.Println("Aborting sync and cleaning up...")
+		fmt.Println()
+
+		// Abort cherry-pick if one is in progress
+		if hasCherryPick {
+			if err := gitClient.AbortCherryPick(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to abort cherry-pick: %v\n", err)
+			} else {
+				fmt.Println(ui.Success("Aborted cherry-pick"))
+			}
+		} else if git.Verbose {
+			fmt.Fprintf(os.Stderr, "Note: no cherry-pick in progress\n")
+		}
+
+		// Abort rebase if one is in progress
+		if hasRebase {
+			if err := gitClient.AbortRebase(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to abort rebase: %v\n", err)
+			} else {
+				fmt.Println(ui.Success("Aborted rebase"))
+			}
+		} else if git.Verbose {
+			fmt.Fprintf(os.Stderr, "Note: no rebase in progress\n")
+		}
+
+		// Restore stashed changes if any
+		if savedStashed == "true" {
+			fmt.Println("Restoring stashed changes...")
+			if err := gitClient.StashPop(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
+				fmt.Fprintln(os.Stderr, i18n.T("sync.stashPopHint", ui.Command("git stash pop")))
+			} else {
+				fmt.Println(ui.Success("Restored stashed changes"))
+			}
+		}
+
+		// Return to original branch if we have one saved
+		if savedOriginalBranch != "" {
+			currentBranch, err := gitClient.GetCurrentBranch()
+			if err == nil && currentBranch != savedOriginalBranch {
+				fmt.Printf("Returning to %s...\n", ui.Branch(savedOriginalBranch))
+				if err := gitClient.CheckoutBranch(savedOriginalBranch); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
+				} else {
+					fmt.Println(ui.Success(fmt.Sprintf("Returned to %s", ui.Branch(savedOriginalBranch))))
+				}
+			}
+		}
+
+		// Clean up sync state
+		_ = gitClient.UnsetConfig(ConfigStashed)
+		_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+		_ = gitClient.UnsetConfig(ConfigStartedAt)
+
+		fmt.Println()
+		fmt.Println(ui.Success("Sync aborted and state cleaned up"))
+		return nil
+	}
+
+	if e.options.Resume {
+		// Resuming after conflict resolution
+		if !hasSavedState {
+			return errors.New(i18n.T("sync.noneToResume"))
+		}
+		stashed = true
+		originalBranch = savedOriginalBranch
+		fmt.Println("Resuming sync...")
+		fmt.Println()
+	} else {
+		// Starting a fresh sync
+		if hasSavedState {
+			fmt.Fprintf(os.Stderr, "Warning: found state from a previous interrupted sync\n")
+			fmt.Fprintln(os.Stderr, i18n.T("sync.resumeHint"))
+			fmt.Fprintf(os.Stderr, "\nStart fresh? [y/N] ")
+
+			reader := bufio.NewReader(e.Input)
+			input, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+
+			input = strings.TrimSpace(strings.ToLower(input))
+			if input != "y" && input != "yes" {
+				fmt.Println(i18n.T("sync.startFreshAborted"))
+				return nil
+			}
+
+			fmt.Println("Cleaning up stale state and starting fresh...")
+			fmt.Println()
+			// Clean up stale state
+			_ = gitClient.UnsetConfig(ConfigStashed)
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+		}
+
+		// Get current branch so we can return to it
+		var err error
+		originalBranch, err = gitClient.GetCurrentBranch()
+		if err != nil {
+			return fmt.Errorf("failed to get current branch: %w", err)
+		}
+
+		// Nothing to do if the stack and origin are where the last sync left them
+		if e.fastPathAllowed() {
+			if err := gitClient.Fetch(); err != nil {
+				return fmt.Errorf("failed to fetch: %w", err)
+			}
+			fetched = true
+			if !git.Offline && e.syncUpToDate(gitClient, originalBranch, stack.GetBaseBranch(gitClient)) {
+				fmt.Println(ui.Success("Already up to date"))
+				fmt.Println(i18n.T("sync.nothingChanged", ui.Command("stack sync --full")))
+				return nil
+			}
+		}
+
+		// Save original branch state for potential --abort
+		if err := gitClient.SetConfig(ConfigOriginalBranch, originalBranch); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to save sync state: %v\n", err)
+		}
+		_ = gitClient.SetConfig(ConfigStartedAt, strconv.FormatInt(time.Now().Unix(), 10))
+
+		// Check if working tree is clean and stash if needed
+		clean, err := gitClient.IsWorkingTreeClean()
+		if err != nil {
+			return fmt.Errorf("failed to check working tree status: %w", err)
+		}
+
+		if !clean {
+			fmt.Println("Stashing uncommitted changes...")
+			if err := gitClient.Stash("stack-sync-autostash"); err != nil {
+				return fmt.Errorf("failed to stash changes: %w", err)
+			}
+			stashed = true
+
+			// Mark that we stashed changes
+			if err := gitClient.SetConfig(ConfigStashed, "true"); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save sync state: %v\n", err)
+			}
+
+			fmt.Println()
+		}
+
+		// A resumed sync belongs to the one that started it, so only fresh ones are recorded
+		oplog.Record(gitClient, "sync", originalBranch)
+	}
+
+	// Track if we complete successfully
+	success := false
+
+	// Ensure stash is popped on error (if we don't complete successfully)
+	// But NOT if we hit a rebase conflict - user needs to resolve and --resume
+	defer func() {
+		if stashed && !success && !rebaseConflict {
+			fmt.Println("\nRestoring stashed changes...")
+			if err := gitClient.StashPop(); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
+				fmt.Fprintln(os.Stderr, i18n.T("sync.stashPopHint", ui.Command("git stash pop")))
+			}
+			// Clean up sync state since we're restoring the stash
+			_ = gitClient.UnsetConfig(ConfigStashed)
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+		}
+	}()
+
+	// Check if current branch is in a stack BEFORE doing any network operations
+	// This allows us to prompt the user immediately if needed
+	baseBranch := stack.GetBaseBranch(gitClient)
+	parent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", originalBranch))
+
+	if parent == "" && originalBranch != baseBranch {
+		added, err := OfferToAddToStack(gitClient, e.Input, originalBranch, baseBranch)
+		if err != nil || !added {
+			// Nothing was changed, so there is no sync to resume
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+			return err
+		}
+	}
+
+	// Start parallel fetch operations (git fetch and GitHub PR fetch)
+	// These are the slowest operations and have no dependencies between them
+	var wg sync.WaitGroup
+	var fetchErr error
+	var prCache map[string]*github.PRInfo
+	var prQueried map[string]bool
+	var prErr error
+
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if !fetched {
+			fetchErr = gitClient.Fetch()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		prCache, prQueried, prErr = LoadStackPRs(gitClient, githubClient, e.options.PRScope)
+	}()
+
+	// While network operations run in background, do local work
+	// Get only branches in the current branch's stack
+	chain, err := stack.GetStackChain(gitClient, originalBranch)
+	if err != nil {
+		return fmt.Errorf("failed to get stack chain: %w", err)
+	}
+
+	if len(chain) == 0 {
+		// Wait for parallel operations before returning
+		wg.Wait()
+		fmt.Println("No stack branches found.")
+		return nil
+	}
+
+	// Build set of branches in current stack
+	chainSet := make(map[string]bool)
+	for _, b := range chain {
+		chainSet[b] = true
+	}
+
+	// Get all stack branches and filter to current stack only
+	allStackBranches, err := stack.GetStackBranches(gitClient)
+	if err != nil {
+		return fmt.Errorf("failed to get stack branches: %w", err)
+	}
+
+	var stackBranches []stack.StackBranch
+	for _, b := range allStackBranches {
+		if chainSet[b.Name] {
+			stackBranches = append(stackBranches, b)
+		}
+	}
+
+	// Detect branches in chain that don't have stackparent configured
+	// and auto-configure them with inferred parents
+	existingBranchNames := make(map[string]bool)
+	for _, b := range stackBranches {
+		existingBranchNames[b.Name] = true
+	}
+
+	// Walk the chain and add missing branches
+	for i, branchName := range chain {
+		if branchName == baseBranch {
+			continue // Skip base branch
+		}
+		if existingBranchNames[branchName] {
+			continue // Already in stackBranches
+		}
+
+		// Infer parent from chain (previous branch in the chain)
+		var inferredParent string
+		if i > 0 {
+			inferredParent = chain[i-1]
+		} else {
+			inferredParent = baseBranch
+		}
+
+		// Check if branch exists locally before adding
+		if gitClient.BranchExists(branchName) {
+			stackBranches = append(stackBranches, stack.StackBranch{
+				Name:   branchName,
+				Parent: inferredParent,
+			})
+			existingBranchNames[branchName] = true
+
+			// Configure stackparent so future syncs work correctly
+			configKey := fmt.Sprintf("branch.%s.stackparent", branchName)
+			if err := gitClient.SetConfig(configKey, inferredParent); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to set stackparent for %s: %v\n", branchName, err)
+			} else {
+				fmt.Printf("Auto-configured %s with parent %s\n", branchName, inferredParent)
+			}
+		}
+	}
+
+	// Sort branches in topological order (bottom to top)
+	sorted, err := stack.TopologicalSort(stackBranches)
+	if err != nil {
+		return fmt.Errorf("failed to sort branches: %w", err)
+	}
+
+	// Branches are rebased in a hidden worktree, leaving this one on its branch
+	hidden := e.openSyncWorktree(gitClient)
+	defer hidden.close(gitClient)
+
+	// Check if any branches in the current stack are in worktrees
+	worktrees, err := gitClient.GetWorktreeBranches()
+	if err != nil {
+		// Non-fatal, continue without worktree detection
+		worktrees = make(map[string]string)
+	}
+
+	// Get current worktree path to check if we're already in the right place
+	currentWorktreePath, err := gitClient.GetCurrentWorktreePath()
+	if err != nil {
+		// Non-fatal, continue without worktree path detection
+		currentWorktreePath = ""
+	}
+
+	// Wait for parallel network operations to complete
+	if err := spinner.WrapWithSuccess(fmt.Sprintf("Fetching from %s and loading PRs...", git.Remote), fmt.Sprintf("Fetched from %s and loaded PRs", git.Remote), func() error {
+		wg.Wait()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	// Check for fetch errors
+	if fetchErr != nil {
+		return fmt.Errorf("failed to fetch: %w", fetchErr)
+	}
+
+	// Handle PR fetch errors gracefully
+	if prErr != nil {
+		prCache = make(map[string]*github.PRInfo)
+		prQueried = nil
+	}
+
+	// Pinned PR numbers survive head branch renames and head-name clashes across forks
+	prPins, _ := gitClient.GetAllStackPRs()
+	prBases, _ := gitClient.GetAllStackPRBases()
+	ResolvePinnedPRs(githubClient, sorted, prCache, prPins)
+
+	// Branches that weren't part of the bulk query (e.g. auto-configured above), or
+	// all of them if it failed, are checked individually to detect merged PRs.
+	for _, branch := range sorted {
+		// Skip if already in cache, or known to have no PR
+		if _, exists := prCache[branch.Name]; exists || prQueried[branch.Name] {
+			continue
+		}
+		// Fetch PR info for this branch (might be merged or non-existent)
+		if pr, err := githubClient.GetPRForBranch(branch.Name); err == nil && pr != nil {
+			prCache[branch.Name] = pr
+		}
+		// Also check parent if not in cache
+		if _, exists := prCache[branch.Parent]; !exists && !prQueried[branch.Parent] {
+			if pr, err := githubClient.GetPRForBranch(branch.Parent); err == nil && pr != nil {
+				prCache[branch.Parent] = pr
+			}
+		}
+	}
+	// PR pins and bases are written together once the sync is done
+	stackRecords := newConfigBatch()
+	defer flushStackRecords(gitClient, stackRecords)
+	pinPRs(stackRecords, sorted, prCache, prPins, prBases)
+
+	// Closed PRs whose commits were pushed to the base branch directly
+	landedByCommit := map[string]bool{}
+	if e.options.DetectMergedByCommit {
+		if landedByCommit, err = MergedByCommit(gitClient, git.RemoteRef(baseBranch), prCache); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not check for branches merged by commit: %v\n", err)
+		}
+	}
+
+	// Umbrella branches are never pushed, so PRs above them target the branch below
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	// PRs GitHub retargeted since the last sync, e.g. after their base branch was
+	// deleted, take their branch along
+	merged := make(map[string]bool)
+	for branch := range landedByCommit {
+		merged[branch] = true
+	}
+	for branch, pr := range prCache {
+		if pr.State == "MERGED" {
+			merged[branch] = true
+		}
+	}
+	if moved := e.reconcileBaseChanges(gitClient, sorted, prCache, prBases, umbrellas, merged); len(moved) > 0 {
+		for i := range sorted {
+			if parent, ok := moved[sorted[i].Name]; ok {
+				sorted[i].Parent = parent
+			}
+		}
+		for i := range stackBranches {
+			if parent, ok := moved[stackBranches[i].Name]; ok {
+				stackBranches[i].Parent = parent
+			}
+		}
+		fmt.Println()
+	}
+
+	parents := make(map[string]string)
+	for _, sb := range stackBranches {
+		parents[sb.Name] = sb.Parent
+	}
+
+	// Branches checked out in another worktree can't be rebased from here. Merged
+	// branches are skipped (or deleted along with their worktree), so they don't block.
+	for _, branch := range sorted {
+		if pr, exists := prCache[branch.Name]; (exists && pr.State == "MERGED") || landedByCommit[branch.Name] {
+			continue
+		}
+		if worktreePath, inWorktree := worktrees[branch.Name]; inWorktree {
+			// Only error if we're NOT already in this worktree
+			if currentWorktreePath != worktreePath {
+				return fmt.Errorf(
+					"cannot sync: branch '%s' is checked out in worktree at %s\n\n"+
+						"To sync this stack:\n"+
+						"  1. cd %s\n"+
+						"  2. stack sync\n\n"+
+						"Or remove the worktree: git worktree remove %s",
+					branch.Name,
+					worktreePath,
+					worktreePath,
+					worktreePath,
+				)
+			}
+		}
+	}
+
+	// Commit policies are checked before any branch is rewritten or pushed
+	if e.options.CommitLint != "" {
+		if err := e.lintStackCommits(gitClient, sorted, prCache, baseBranch); err != nil {
+			// Nothing was changed, so there is no sync to resume
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+			return err
+		}
+	}
+	var signOffs map[string][]unsignedCommit
+	if e.options.DCO != "" {
+		if signOffs, err = e.checkSignOffs(gitClient, sorted, prCache, baseBranch); err != nil {
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+			return err
+		}
+	}
+
+	if e.options.SizeLimits.Enabled() {
+		if err := GuardStackSize(gitClient, e.options.SizeLimits, sorted, prCache, umbrellas, baseBranch, "stack sync --no-size-guard"); err != nil {
+			_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+			_ = gitClient.UnsetConfig(ConfigStartedAt)
+			return err
+		}
+	}
+
+	// Get all remote branches in one call (more efficient than checking each branch individually)
+	remoteBranches := gitClient.GetRemoteBranchesSet()
+
+	fmt.Printf("Processing %d branch(es)...\n\n", len(sorted))
+
+	// Build a set of stack branch names for quick lookup
+	stackBranchSet := make(map[string]bool)
+	for _, sb := range stackBranches {
+		stackBranchSet[sb.Name] = true
+	}
+
+	// Branches detected as merged by patch comparison (no merged PR record)
+	mergedByPatch := make(map[string]bool)
+
+	// Merged branches removed from tracking, deleted at the end with --delete-merged,
+	// with the branch their changes landed on
+	var untrackedMerged []string
+	mergedInto := make(map[string]string)
+
+	// Remember what reviewers last saw, before any branch in the stack is pushed
+	var pushedStates map[string]pushedState
+	if e.options.ReviewComment != "" || e.options.ProtectApprovals {
+		pushedStates = capturePushedStates(gitClient, sorted, prCache, remoteBranches)
+	}
+
+	// Overall progress shown above the spinner for each branch's rebase/push steps
+	syncProgress := spinner.NewProgress("Syncing", len(sorted))
+
+	// With --request-reviewers, each PR gets the owners of its own layer's changes
+	var owners *codeowners.File
+	var self string
+	if e.options.RequestReviewers {
+		if owners, err = LoadCodeOwners(gitClient, git.RemoteRef(baseBranch)); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: not requesting reviewers: %v\n", err)
+		} else {
+			self = CurrentUser(githubClient)
+		}
+	}
+
+	// With --wait-for-ci, branches are only pushed once the branch below them passes CI
+	var gate *ciGate
+	if e.options.WaitForCI {
+		gate = newCIGate(gitClient, githubClient, e.ciTimeout, syncProgress)
+	}
+
+	// On git 2.38 and later, a linear stack is restacked in one rebase of its top branch
+	var restacked map[string]string
+	if len(landedByCommit) == 0 && e.updateRefsEligible(gitClient, hidden, sorted, prCache, umbrellas, remoteBranches, signOffs) {
+		target := git.RemoteRef(sorted[0].Parent)
+		if git.Offline && !gitClient.RemoteBranchExists(sorted[0].Parent) {
+			target = sorted[0].Parent
+		}
+		restacked = restackWithUpdateRefs(gitClient, hidden, sorted, target)
+	}
+
+	// Process each branch
+	for i, branch := range sorted {
+		progress := ui.Progress(i+1, len(sorted))
+		e.startBranch(syncProgress, branch.Name, i+1, len(sorted))
+
+		pr, hasPR := prCache[branch.Name]
+		prMerged := hasPR && pr.State == "MERGED"
+
+		// Without a merged PR, optionally check whether the branch's changes already
+		// landed on the base branch (e.g. squash-merged through a different remote)
+		if !prMerged && e.options.DetectMergedByPatch {
+			merged, err := gitClient.IsMergedByPatch(git.RemoteRef(baseBranch), branch.Name)
+			if err != nil && git.Verbose {
+				fmt.Printf("  Note: could not compare patches for %s: %v\n", branch.Name, err)
+			}
+			mergedByPatch[branch.Name] = merged
+		}
+
+		// Check if this branch has been merged - if so, remove from stack tracking
+		if prMerged || mergedByPatch[branch.Name] || landedByCommit[branch.Name] {
+			if prMerged {
+				fmt.Printf("%s Skipping %s (PR #%d is %s)...\n", progress, ui.Branch(branch.Name), pr.Number, ui.PRState(pr.State))
+				e.skipBranch(fmt.Sprintf("PR #%d is %s", pr.Number, pr.State))
+			} else if landedByCommit[branch.Name] {
+				fmt.Printf("%s Skipping %s (PR #%d is %s, but its commits are in %s)...\n", progress, ui.Branch(branch.Name), pr.Number, ui.PRState(pr.State), ui.Branch(baseBranch))
+				e.skipBranch(fmt.Sprintf("PR #%d is %s, but its commits are in %s", pr.Number, pr.State, baseBranch))
+			} else {
+				fmt.Printf("%s Skipping %s (changes already in %s)...\n", progress, ui.Branch(branch.Name), ui.Branch(baseBranch))
+				e.skipBranch(fmt.Sprintf("changes already in %s", baseBranch))
+			}
+			if !e.options.PruneMerged {
+				fmt.Printf("  Keeping stack tracking (--prune-merged=false)\n\n")
+				continue
+			}
+			fmt.Printf("  Removing from stack tracking...\n")
+			configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
+			if err := gitClient.UnsetConfig(configKey); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: failed to remove stack config: %v\n", err)
+			} else {
+				untrackedMerged = append(untrackedMerged, branch.Name)
+				mergedInto[branch.Name] = baseBranch
+				if prMerged {
+					mergedInto[branch.Name] = pr.Base
+				}
+				if !e.options.DeleteMerged {
+					fmt.Printf("  %s Removed. You can delete this branch with: %s\n", ui.SuccessIcon(), ui.Command(fmt.Sprintf("git branch -d %s", branch.Name)))
+				} else {
+					fmt.Printf("  %s Removed. It will be deleted once the stack is synced\n", ui.SuccessIcon())
+				}
+			}
+			fmt.Println()
+			continue
+		}
+
+		// Rebasing, pushing or retargeting a queued PR would drop it from the merge
+		// queue, so it's left as is until it merges
+		if InMergeQueue(pr) {
+			fmt.Printf("%s Skipping %s (PR #%d is in the merge queue)\n\n", progress, ui.Branch(branch.Name), pr.Number)
+			e.skipBranch(fmt.Sprintf("PR #%d is in the merge queue", pr.Number))
+			continue
+		}
+
+		fmt.Printf("%s Processing %s...\n", progress, ui.Branch(branch.Name))
+
+		// Check if parent PR is merged
+		oldParent := "" // Track old parent for --onto rebase
+		parentPR := prCache[branch.Parent]
+		parentPRMerged := parentPR != nil && parentPR.State == "MERGED"
+		if parentPRMerged || mergedByPatch[branch.Parent] || landedByCommit[branch.Parent] {
+			if parentPRMerged {
+				fmt.Printf("  Parent PR #%d has been merged\n", parentPR.Number)
+			} else if landedByCommit[branch.Parent] {
+				fmt.Printf("  Parent %s has already landed in %s (PR #%d was closed with its commits on the base branch)\n", ui.Branch(branch.Parent), ui.Branch(baseBranch), parentPR.Number)
+			} else {
+				fmt.Printf("  Parent %s has already landed in %s (detected by patch)\n", ui.Branch(branch.Parent), ui.Branch(baseBranch))
+			}
+
+			// Save old parent for --onto rebase
+			oldParent = branch.Parent
+
+			// Update parent to grandparent
+			grandparent := gitClient.GetConfig(fmt.Sprintf("branch.%s.stackparent", branch.Parent))
+			if grandparent == "" {
+				grandparent = stack.GetBaseBranch(gitClient)
+			}
+
+			fmt.Printf("  %s Updated parent from %s to %s\n", ui.SuccessIcon(), ui.Branch(branch.Parent), ui.Branch(grandparent))
+			configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
+			if err := gitClient.SetConfig(configKey, grandparent); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: failed to update parent config: %v\n", err)
+			} else {
+				branch.Parent = grandparent
+				parents[branch.Name] = grandparent
+			}
+		}
+
+		// Checkout the branch, unless it was restacked in one pass and only needs pushing
+		branchGit := hidden.clientFor(gitClient, branch.Name)
+		restackedOnto, inPass := restacked[branch.Name]
+		if !inPass {
+			if err := branchGit.CheckoutBranch(branch.Name); err != nil {
+				return fmt.Errorf("failed to checkout %s: %w", branch.Name, err)
+			}
+		}
+
+		// Sync with remote branch if it exists (unless --force is set)
+		remoteBranch := git.RemoteRef(branch.Name)
+		// Check if we have a local tracking ref for the remote branch
+		hasLocalRef := remoteBranches[branch.Name]
+		// Branch exists on remote if we have local tracking ref OR if there's a PR for it
+		// (PR existence proves branch is on origin, even if local ref is missing)
+		branchExistsOnRemote := hasLocalRef || prCache[branch.Name] != nil
+
+		// If branch is on remote but we don't have the local tracking ref, fetch it
+		if branchExistsOnRemote && !hasLocalRef {
+			if git.Verbose {
+				fmt.Printf("  Fetching remote branch (local tracking ref missing)...\n")
+			}
+			if err := gitClient.FetchBranch(branch.Name); err != nil {
+				// If fetch fails, the branch might have been deleted on remote
+				// Fall back to treating it as a new branch
+				if git.Verbose {
+					fmt.Printf("  Could not fetch remote branch, treating as new branch\n")
+				}
+				branchExistsOnRemote = false
+			}
+		}
+
+		pushStrategy := e.pushStrategyFor(branch.Name)
+
+		if branchExistsOnRemote && pushStrategy != PushForce {
+			// Check if local and remote have diverged
+			localHash, err := gitClient.GetCommitHash(branch.Name)
+			if err != nil {
+				return fmt.Errorf("failed to get local commit hash: %w", err)
+			}
+
+			remoteHash, err := gitClient.GetCommitHash(remoteBranch)
+			if err != nil {
+				return fmt.Errorf("failed to get remote commit hash: %w", err)
+			}
+
+			if localHash != remoteHash {
+				// Check merge base to determine relationship
+				mergeBase, err := gitClient.GetMergeBase(branch.Name, remoteBranch)
+				if err != nil {
+					return fmt.Errorf("failed to get merge base: %w", err)
+				}
+
+				if mergeBase == remoteHash {
+					// Local is ahead of remote (we have new commits)
+					if git.Verbose {
+						fmt.Printf("  Local branch is ahead of %s (has new commits)\n", git.RemoteOf(branch.Name))
+					}
+				} else if mergeBase == localHash {
+					// Local is behind remote (safe to fast-forward)
+					fmt.Printf("  Fast-forwarding to %s...\n", remoteBranch)
+					if err := branchGit.ResetToRemote(branch.Name); err != nil {
+						return fmt.Errorf("failed to fast-forward: %w", err)
+					}
+				} else {
+					// Branches have diverged - this is normal after rebasing onto an updated parent
+					// --force-with-lease will safely handle this during push
+					if git.Verbose {
+						fmt.Printf("  Local and remote have diverged (normal after rebase)\n")
+					}
+				}
+			} else if git.Verbose {
+				fmt.Printf("  Local branch is up-to-date with %s\n", remoteBranch)
+			}
+		} else if pushStrategy == PushForce && branchExistsOnRemote {
+			if git.Verbose {
+				fmt.Printf("  Skipping divergence check (--force enabled)\n")
+			}
+		} else {
+			if git.Verbose {
+				fmt.Printf("  Remote branch %s doesn't exist yet (new branch)\n", remoteBranch)
+			}
+		}
+
+		// Determine rebase target: origin/<parent> for base branches, local for stack branches
+		rebaseTarget := branch.Parent
+		if !stackBranchSet[branch.Parent] {
+			// Parent is not a stack branch, so it's a base branch - use origin/<parent>
+			rebaseTarget = git.RemoteRef(branch.Parent)
+
+			// Explicitly fetch the base branch to ensure tracking ref is up to date
+			// This is needed because 'git fetch origin' may not always update tracking refs
+			// reliably (e.g., repos with limited refspecs or certain git configurations)
+			if err := gitClient.FetchBranch(branch.Parent); err != nil {
+				// Non-fatal: continue with potentially stale ref, rebase will still work
+				// but might not include latest changes from the base branch
+				if git.Verbose {
+					fmt.Printf("  Note: could not fetch %s: %v\n", branch.Parent, err)
+				}
+			}
+
+			// Offline, a base that was never fetched can only come from the local branch
+			if git.Offline && !gitClient.RemoteBranchExists(branch.Parent) {
+				rebaseTarget = branch.Parent
+			}
+		}
+
+		// Umbrella branches just follow their parent, with nothing to push or open a PR for
+		if umbrellas[branch.Name] {
+			handled, err := syncUmbrella(branchGit, syncProgress, branch.Name, rebaseTarget)
+			if err != nil {
+				return err
+			}
+			if handled {
+				fmt.Println()
+				continue
+			}
+		}
+
+		// The base may have moved since the stack was restacked, e.g. when fetched above
+		if inPass {
+			if hash, err := gitClient.GetCommitHash(rebaseTarget); err != nil || hash != restackedOnto {
+				inPass = false
+				if err := branchGit.CheckoutBranch(branch.Name); err != nil {
+					return fmt.Errorf("failed to checkout %s: %w", branch.Name, err)
+				}
+			}
+		}
+
+		// Commits picked with 'stack pick' are redundant once their original lands
+		pickRange := rebaseTarget
+		if oldParent != "" {
+			pickRange = oldParent
+		}
+		e.dropLandedPicks(branchGit, branch.Name, pickRange, git.RemoteRef(baseBranch))
+
+		// Rebase onto parent
+		// If parent was just merged (oldParent set), use --onto to exclude old parent's commits
+		rebaseOntoParent := func() error {
+			if oldParent != "" {
+				// Parent was merged - use --onto to handle squash merge
+				// This excludes commits from oldParent that are now in rebaseTarget
+				fmt.Printf("  Using --onto to handle squash merge (excluding commits from %s)\n", oldParent)
+				return branchGit.RebaseOnto(rebaseTarget, oldParent, branch.Name)
+			}
+
+			// Get unique commits in this branch by comparing patch content (not just SHAs)
+			// This detects duplicate changes even if commits were rebased with different SHAs
+			uniqueCommits, err := gitClient.GetUniqueCommitsByPatch(rebaseTarget, branch.Name)
+			if err != nil {
+				// If we can't get unique commits, fall back to regular rebase
+				if git.Verbose {
+					fmt.Printf("  Could not get unique commits by patch, using regular rebase: %v\n", err)
+				}
+				return branchGit.Rebase(rebaseTarget)
+			}
+
+			// If no unique commits, branch is up-to-date
+			if len(uniqueCommits) == 0 {
+				if git.Verbose {
+					fmt.Printf("  Branch is up-to-date with %s (no unique patches)\n", rebaseTarget)
+				}
+				return nil
+			}
+
+			if git.Verbose {
+				fmt.Printf("  Found %d unique commit(s) by patch comparison\n", len(uniqueCommits))
+			}
+
+			// Get merge-base to understand the history
+			mergeBase, err := gitClient.GetMergeBase(branch.Name, rebaseTarget)
+			if err != nil {
+				// If we can't find merge-base, fall back to regular rebase
+				if git.Verbose {
+					fmt.Printf("  Could not find merge-base, using regular rebase: %v\n", err)
+				}
+				return branchGit.Rebase(rebaseTarget)
+			}
+
+			rebaseTargetHash, err := gitClient.GetCommitHash(rebaseTarget)
+			if err == nil && mergeBase == rebaseTargetHash {
+				// Parent hasn't changed since we branched, regular rebase is fine
+				return branchGit.Rebase(rebaseTarget)
+			}
+
+			// Count commits from merge-base to current branch (total commits in branch history)
+			allCommits, err := gitClient.GetUniqueCommits(mergeBase, branch.Name)
+			if err == nil && len(allCommits) > len(uniqueCommits)*2 {
+				// Branch has polluted history: many more commits than unique patches
+				// This usually means branch diverged from parent's history (e.g., based on old backup)
+
+				if e.options.CherryPick {
+					// Automated cherry-pick rebuild with backup
+					tempBranch := branch.Name + "-rebuild"
+
+					// Find available backup branch name
+					backupBranch := branch.Name + "-backup"
+					for i := 2; gitClient.BranchExists(backupBranch); i++ {
+						backupBranch = fmt.Sprintf("%s-backup-%d", branch.Name, i)
+					}
+
+					fmt.Printf("\n")
+					fmt.Printf("%s Detected polluted branch history (%d commits, %d unique patches)\n", ui.WarningIcon(), len(allCommits), len(uniqueCommits))
+					fmt.Printf("  Creating backup: %s\n", backupBranch)
+
+					// Create backup branch from current branch (without checkout)
+					if err := gitClient.CreateBranch(backupBranch, branch.Name); err != nil {
+						return fmt.Errorf("failed to create backup branch: %w", err)
+					}
+
+					fmt.Printf("  Rebuilding with %d unique commit(s)...\n", len(uniqueCommits))
+
+					// Checkout parent branch
+					if err := gitClient.CheckoutBranch(rebaseTarget); err != nil {
+						return fmt.Errorf("failed to checkout parent %s: %w", rebaseTarget, err)
+					}
+
+					// Create temp branch from parent
+					if err := gitClient.CreateBranchAndCheckout(tempBranch, rebaseTarget); err != nil {
+						return fmt.Errorf("failed to create temp branch: %w", err)
+					}
+
+					// Cherry-pick each unique commit
+					for _, commit := range uniqueCommits {
+						if git.Verbose {
+							fmt.Printf("    Cherry-picking %s\n", commit[:8])
+						}
+						if err := gitClient.CherryPick(commit); err != nil {
+							// Cherry-pick conflict - let user resolve
+							rebaseConflict = true
+							fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("conflict.cherryPickOn", commit[:8]))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.cherryPickContinue"))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("sync.cherryPick.rest"))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("sync.cherryPick.rename", branch.Name, branch.Name))
+							fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("sync.cherryPick.resume"))
+							fmt.Fprintf(os.Stderr, "\n  Backup saved as: %s\n", backupBranch)
+							return fmt.Errorf("cherry-pick conflict: %w", err)
+						}
+					}
+
+					// Delete original branch and rename temp to original
+					if err := gitClient.DeleteBranchForce(branch.Name); err != nil {
+						return fmt.Errorf("failed to delete original branch: %w", err)
+					}
+
+					// We're on tempBranch, rename it to the original branch name
+					if err := gitClient.RenameBranch(tempBranch, branch.Name); err != nil {
+						return fmt.Errorf("failed to rename temp branch: %w", err)
+					}
+
+					// Restore stackparent config (git branch -D deletes the branch's config section)
+					configKey := fmt.Sprintf("branch.%s.stackparent", branch.Name)
+					if err := gitClient.SetConfig(configKey, branch.Parent); err != nil {
+						return fmt.Errorf("failed to restore stackparent config: %w", err)
+					}
+
+					fmt.Printf("  %s Rebuilt %s (backup saved as %s)\n", ui.SuccessIcon(), ui.Branch(branch.Name), ui.Branch(backupBranch))
+					fmt.Printf("  To delete backup later: %s\n", ui.Command(fmt.Sprintf("git branch -D %s", backupBranch)))
+
+					// Branch is now clean - no need to rebase, just return nil
+					return nil
+				}
+
+				// No --cherry-pick flag: show warning and suggest the flag
+				rebaseConflict = true
+				fmt.Fprintf(os.Stderr, "\n")
+				fmt.Fprintf(os.Stderr, "%s Detected polluted branch history:\n", ui.WarningIcon())
+				fmt.Fprintf(os.Stderr, "  - %d commits in branch history\n", len(allCommits))
+				fmt.Fprintf(os.Stderr, "  - Only %d unique patch(es)\n", len(uniqueCommits))
+				fmt.Fprintf(os.Stderr, "\n")
+				fmt.Fprintf(os.Stderr, "This usually means your branch diverged from the parent's history.\n")
+				fmt.Fprintf(os.Stderr, "Rebasing may result in many conflicts.\n")
+				fmt.Fprintf(os.Stderr, "\n")
+				fmt.Fprintln(os.Stderr, i18n.T("sync.cherryPick.recommended"))
+				fmt.Fprintf(os.Stderr, "  (Creates backup branch before rebuilding)\n")
+				fmt.Fprintf(os.Stderr, "\n")
+				fmt.Fprintf(os.Stderr, "Or rebuild manually:\n")
+				fmt.Fprintf(os.Stderr, "  1. git checkout %s\n", branch.Parent)
+				fmt.Fprintf(os.Stderr, "  2. git checkout -b %s-clean\n", branch.Name)
+				for i, commit := range uniqueCommits {
+					if i < 5 { // Show first 5 commits
+						fmt.Fprintf(os.Stderr, "  3. git cherry-pick %s\n", commit[:8])
+					}
+				}
+				if len(uniqueCommits) > 5 {
+					fmt.Fprintf(os.Stderr, "     ... (%d more commits)\n", len(uniqueCommits)-5)
+				}
+				fmt.Fprintf(os.Stderr, "  4. git branch -D %s\n", branch.Name)
+				fmt.Fprintf(os.Stderr, "  5. git branch -m %s\n", branch.Name)
+				fmt.Fprintf(os.Stderr, "  6. git push --force-with-lease\n")
+				fmt.Fprintf(os.Stderr, "\n")
+				return fmt.Errorf("branch history is polluted, manual cleanup recommended")
+			}
+
+			// Use --onto to only replay commits unique to this branch
+			// This prevents conflicts from duplicate commits when parent was rebased
+			if git.Verbose {
+				fmt.Printf("  Using --onto with merge-base %s to handle rebased parent\n", mergeBase[:8])
+			}
+			return branchGit.RebaseOnto(rebaseTarget, mergeBase, branch.Name)
+		}
+		rebaseStarted := fmt.Sprintf("Rebasing onto %s...", rebaseTarget)
+		rebaseDone := fmt.Sprintf("Rebased onto %s", rebaseTarget)
+		var rebaseErr error
+		if inPass {
+			fmt.Printf("  %s %s\n", ui.SuccessIcon(), rebaseDone)
+			e.emit(StepDone, rebaseDone, nil)
+		} else {
+			rebaseErr = e.step(syncProgress, "  ", rebaseStarted, rebaseDone, func() error {
+				err := rebaseOntoParent()
+				var conflict *git.ConflictError
+				if branchGit != gitClient && errors.As(err, &conflict) {
+					_ = branchGit.AbortRebase()
+					return errConflictInSyncWorktree
+				}
+				return err
+			})
+		}
+
+		// Conflicts are resolved in this worktree, so the rebase starts over here
+		if errors.Is(rebaseErr, errConflictInSyncWorktree) {
+			hidden.close(gitClient)
+			if rebaseErr = gitClient.CheckoutBranch(branch.Name); rebaseErr == nil {
+				branchGit = gitClient
+				rebaseErr = e.step(syncProgress, "  ", rebaseStarted, rebaseDone, rebaseOntoParent)
+			}
+		}
+		if rebaseErr != nil {
+			rebaseConflict = true
+			e.emit(Conflict, rebaseStarted, rebaseErr)
+			fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("sync.conflict.detected"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.resolve"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.add"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("conflict.continue"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("sync.conflict.resume"))
+			fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("sync.conflict.abortHeader"))
+			fmt.Fprintf(os.Stderr, "    %s\n", i18n.T("sync.conflict.abort"))
+			printSubmoduleConflicts(gitClient)
+			if git.SkipLFSSmudge {
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("sync.conflict.lfs"))
+			}
+			if stashed {
+				fmt.Fprintf(os.Stderr, "\n  %s\n", i18n.T("sync.conflict.stashNote"))
+			}
+			return ui.Reported(fmt.Errorf("failed to rebase %s: %w", branch.Name, rebaseErr))
+		}
+
+		// With stack.dco=fix, missing sign-offs are added once the branch is on its parent
+		if unsigned := signOffs[branch.Name]; len(unsigned) > 0 {
+			if err := e.step(syncProgress, "  ", "Adding Signed-off-by trailers...", "Added Signed-off-by trailers", func() error {
+				return branchGit.RebaseSignoff(rebaseTarget)
+			}); err != nil {
+				return fmt.Errorf("failed to sign off the commits of %s: %w", branch.Name, err)
+			}
+			reportSignOffs(unsigned)
+		}
+
+		// The PR targets the parent, or the branch below it if the parent is an umbrella
+		prBase := PRBaseFor(branch.Parent, parents, umbrellas)
+
+		// Hold back the push while the branch below hasn't passed CI
+		holdReason := ""
+		if gate != nil {
+			holdReason = gate.holdReason(branch.Name, prBase, prCache)
+		}
+
+		// Push to origin - only if the branch already exists remotely
+		if holdReason != "" {
+			fmt.Printf("  %s Not pushing: %s\n", ui.WarningIcon(), holdReason)
+		} else if git.Offline && (branchExistsOnRemote || e.options.CreatePRs) {
+			fmt.Printf("  %s Not pushing while offline\n", ui.WarningIcon())
+		} else if branchExistsOnRemote {
+			// Don't silently invalidate approvals with content changes
+			if state, ok := pushedStates[branch.Name]; ok && e.options.ProtectApprovals && pr != nil && pr.ReviewDecision == "APPROVED" {
+				if err := e.confirmApprovalInvalidation(gitClient, pr.Number, branch.Name, rebaseTarget, state); err != nil {
+					return err
+				}
+			}
+
+			pushErr := e.step(
+				syncProgress,
+				"  ",
+				fmt.Sprintf("Pushing to %s...", git.RemoteOf(branch.Name)),
+				fmt.Sprintf("Pushed to %s", git.RemoteOf(branch.Name)),
+				func() error {
+					return PushBranch(gitClient, branch.Name, pushStrategy)
+				},
+			)
+
+			if pushErr != nil {
+				// A rejected push gets its hint from the error itself
+				var diverged *git.DivergedError
+				if pushStrategy != PushForce && !errors.As(pushErr, &diverged) {
+					fmt.Fprintf(os.Stderr, "\n%s\n", i18n.T("sync.possibleCause"))
+					fmt.Fprintf(os.Stderr, "  %s\n", i18n.T("sync.pushHint"))
+				}
+				return fmt.Errorf("push failed for %s: %w", branch.Name, pushErr)
+			}
+
+			if gate != nil {
+				gate.markPushed(branch.Name)
+			}
+
+			if state, ok := pushedStates[branch.Name]; ok && e.options.ReviewComment != "" && pr != nil {
+				e.postReviewComment(gitClient, githubClient, pr.Number, branch.Name, rebaseTarget, state)
+			}
+		} else if e.options.CreatePRs {
+			// A PR needs its head on origin, so publish the branch first
+			if err := e.step(syncProgress, "  ", fmt.Sprintf("Pushing to %s...", git.RemoteOf(branch.Name)), fmt.Sprintf("Pushed to %s", git.RemoteOf(branch.Name)), func() error {
+				return gitClient.Push(branch.Name, false)
+			}); err != nil {
+				return fmt.Errorf("push failed for %s: %w", branch.Name, err)
+			}
+			if gate != nil {
+				gate.markPushed(branch.Name)
+			}
+		} else {
+			fmt.Printf("  Skipping push (branch not yet on %s)\n", git.RemoteOf(branch.Name))
+		}
+
+		// Check if PR exists and update base if needed
+		if pr != nil {
+			if e.options.FlatPRs {
+				fmt.Printf("  %s PR #%d targets %s\n", ui.SuccessIcon(), pr.Number, ui.Branch(pr.Base))
+			} else if pr.Base != prBase && github.Offline {
+				fmt.Printf("  %s PR #%d should target %s, not updated while offline\n", ui.WarningIcon(), pr.Number, ui.Branch(prBase))
+			} else if pr.Base != prBase {
+				fmt.Printf("  Updating PR #%d base from %s to %s...\n", pr.Number, ui.Branch(pr.Base), ui.Branch(prBase))
+				var notOpen *PRNotOpenError
+				if err := RetargetPR(githubClient, pr.Number, prBase); errors.As(err, &notOpen) {
+					// Merged while syncing: the branches above it are moved off it like
+					// those of any merged parent when their turn comes
+					fmt.Printf("  %s PR #%d is now %s, not updating its base\n", ui.WarningIcon(), pr.Number, ui.PRState(notOpen.PR.State))
+					prCache[branch.Name] = notOpen.PR
+				} else if err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: failed to update PR base: %v\n", err)
+				} else {
+					fmt.Printf("  %s PR #%d updated\n", ui.SuccessIcon(), pr.Number)
+					recordPRBase(stackRecords, branch.Name, prBase)
+				}
+			} else {
+				fmt.Printf("  %s PR #%d base is already correct (%s)\n", ui.SuccessIcon(), pr.Number, ui.Branch(pr.Base))
+			}
+		} else if e.options.CreatePRs && holdReason == "" && !github.Offline {
+			base := prBase
+			if e.options.FlatPRs {
+				base = baseBranch
+			}
+			CreatePR(gitClient, githubClient, branch.Name, base, e.options.DraftPRs, prCache)
+		} else {
+			fmt.Printf("  No PR found (create one with '%s' or '%s')\n", ui.Command("gh pr create"), ui.Command("stack sync --create-prs"))
+		}
+
+		// Ask the owners of this layer's changes for review
+		if owners != nil && holdReason == "" && !github.Offline {
+			if pr := prCache[branch.Name]; pr != nil && pr.State == "OPEN" {
+				suggested, _, err := SuggestReviewers(gitClient, owners, branch, baseBranch, self)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "  Warning: failed to find reviewers: %v\n", err)
+				} else if len(suggested) > 0 {
+					RequestReviewers(githubClient, pr.Number, suggested)
+				}
+			}
+		}
+
+		fmt.Println()
+	}
+
+	// The branch last rebased in the hidden worktree is checked out there until it's gone
+	hidden.close(gitClient)
+
+	// Return to original branch
+	fmt.Printf("Returning to %s...\n", ui.Branch(originalBranch))
+	if err := gitClient.CheckoutBranch(originalBranch); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to return to original branch: %v\n", err)
+	}
+
+	// Rebases move the commits submodules point at without checking them out
+	warnStaleSubmodules(gitClient)
+
+	// With --no-lfs-smudge, only the branch sync ends on gets its LFS files
+	restoreLFSFiles(gitClient)
+
+	fmt.Println()
+
+	if gate != nil {
+		gate.printSummary()
+	}
+
+	// All descendants are restacked, so merged branches are no longer needed locally
+	if e.options.DeleteMerged && len(untrackedMerged) > 0 {
+		deleteMergedBranches(gitClient, untrackedMerged, mergedInto, baseBranch, originalBranch, worktrees, currentWorktreePath)
+		fmt.Println()
+	}
+
+	if e.options.PRNav && !github.Offline {
+		UpdateStackNav(gitClient, githubClient, originalBranch, e.options.PRScope)
+		fmt.Println()
+	}
+
+	if e.options.PRPosition.Enabled() && !github.Offline {
+		UpdateStackPositions(gitClient, githubClient, originalBranch, e.options.PRPosition, e.options.PRScope)
+		fmt.Println()
+	}
+
+	// Display the updated stack status (reuse prCache to avoid redundant API call)
+	if err := displayStatusAfterSync(gitClient, githubClient, prCache); err != nil {
+		// Don't fail if we can't display status, just warn
+		fmt.Fprintf(os.Stderr, "Warning: failed to display stack status: %v\n", err)
+	}
+
+	// Record what the sync left, so 'stack undo' only puts back what it changed
+	oplog.Finish(gitClient, "sync")
+
+	// Mark as successful so defer doesn't restore stash
+	success = true
+
+	// Restore stashed changes before success message
+	if stashed {
+		fmt.Println()
+		fmt.Println("Restoring stashed changes...")
+		if err := gitClient.StashPop(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to restore stashed changes: %v\n", err)
+			fmt.Fprintln(os.Stderr, i18n.T("sync.stashPopHint", ui.Command("git stash pop")))
+		}
+	}
+
+	// Clean up sync state (both stash flag and original branch)
+	_ = gitClient.UnsetConfig(ConfigStashed)
+	_ = gitClient.UnsetConfig(ConfigOriginalBranch)
+	_ = gitClient.UnsetConfig(ConfigStartedAt)
+
+	if e.options.Verify {
+		if git.DryRun {
+			fmt.Println("\nSkipping verification in dry-run mode")
+		} else if git.Offline || github.Offline {
+			fmt.Println("\nSkipping verification while offline")
+		} else if err := e.verifySyncResult(gitClient, githubClient, sorted, baseBranch); err != nil {
+			return err
+		}
+	}
+
+	// The next sync can stop early if nothing changes in the meantime
+	recordSyncState(gitClient, stackRecords, originalBranch, baseBranch)
+
+	if git.Offline || github.Offline {
+		fmt.Printf("\n%s\n", ui.Dim(github.OfflineNote(time.Now())))
+		fmt.Println(i18n.T("sync.offlineHint", ui.Command("stack sync")))
+	}
+
+	fmt.Println()
+	fmt.Println(ui.Success(i18n.T("sync.complete")))
+
+	return nil
+}
+
+// deleteMergedBranches deletes merged branches and any worktrees they are checked out in.
+// Branches are force-deleted since squash merges leave them unmerged by ancestry, but
+// only once every commit is known to be on origin: the branch matches its remote
+// branch, or, when that's gone, its changes landed on the branch it was merged into
+// (mergedInto) or on the base branch. Otherwise it is only deleted when 'git branch -d'
+// agrees, and kept with a note when it doesn't.
+func deleteMergedBranches(gitClient git.GitClient, branches []string, mergedInto map[string]string, baseBranch, originalBranch string, worktrees map[string]string, currentWorktreePath string) {
+	fmt.Println("Deleting merged branches...")
+	for _, branch := range branches {
+		if branch == originalBranch {
+			fmt.Printf("  %s Skipping %s (currently checked out)\n", ui.WarningIcon(), ui.Branch(branch))
+			continue
+		}
+
+		// Remote branches are often deleted on merge; only compare when it still exists
+		landed := false
+		if remoteHash, err := gitClient.GetCommitHash(git.RemoteRef(branch)); err == nil {
+			if localHash, err := gitClient.GetCommitHash(branch); err == nil && localHash != remoteHash {
+				fmt.Printf("  %s Skipping %s (has local changes not on %s)\n", ui.WarningIcon(), ui.Branch(branch), git.RemoteOf(branch))
+				continue
+			}
+			landed = true
+		} else {
+			landed = landedOnOrigin(gitClient, branch, mergedInto[branch], baseBranch)
+		}
+
+		if worktreePath, inWorktree := worktrees[branch]; inWorktree {
+			if worktreePath == currentWorktreePath {
+				fmt.Printf("  %s Skipping %s (checked out in this worktree)\n", ui.WarningIcon(), ui.Branch(branch))
+				continue
+			}
+			if !landed {
+				fmt.Printf("  %s Keeping %s (checked out at %s, with commits not found on %s)\n", ui.WarningIcon(), ui.Branch(branch), worktreePath, git.RemoteOf(branch))
+				continue
+			}
+			if err := gitClient.RemoveWorktree(worktreePath); err != nil {
+				fmt.Fprintf(os.Stderr, "  Warning: failed to remove worktree %s: %v\n", worktreePath, err)
+				continue
+			}
+		}
+
+		if !landed {
+			if err := DeleteBranch(gitClient, branch); err != nil {
+				fmt.Printf("  %s Keeping %s (has commits not found on %s; delete it with '%s' if they aren't needed)\n",
+					ui.WarningIcon(), ui.Branch(branch), git.RemoteOf(branch), ui.Command("git branch -D "+branch))
+				continue
+			}
+		} else if err := DeleteBranchForce(gitClient, branch); err != nil {
+			fmt.Fprintf(os.Stderr, "  Warning: failed to delete %s: %v\n", branch, err)
+			continue
+		}
+		fmt.Printf("  %s Deleted %s\n", ui.SuccessIcon(), ui.Branch(branch))
+	}
+}
+
+// landedOnOrigin reports whether every change of branch is on origin's copy of
+// target or of baseBranch: the branch is an ancestor of it, or its commits match
+// by patch, as after a rebase or squash merge
+func landedOnOrigin(gitClient git.GitClient, branch, target, baseBranch string) bool {
+	refs := []string{git.RemoteRef(baseBranch)}
+	if target != "" && target != baseBranch {
+		refs = append([]string{git.RemoteRef(target)}, refs...)
+	}
+	for _, ref := range refs {
+		if _, err := gitClient.GetCommitHash(ref); err != nil {
+			continue
+		}
+		if commits, err := gitClient.GetUniqueCommits(ref, branch); err == nil && len(commits) == 0 {
+			return true
+		}
+		if merged, err := gitClient.IsMergedByPatch(ref, branch); err == nil && merged {
+			return true
+		}
+	}
+	return false
+}
+
+// displayStatusAfterSync shows the stack tree after a successful sync
+// It reuses the prCache from earlier to avoid a redundant API call
+func displayStatusAfterSync(gitClient git.GitClient, githubClient github.GitHubClient, prCache map[string]*github.PRInfo) error {
+	currentBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	tree, err := stack.BuildStackTreeForBranch(gitClient, currentBranch)
+	if err != nil {
+		return fmt.Errorf("failed to build stack tree: %w", err)
+	}
+
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+	PrintTree(gitClient, tree, currentBranch, prCache, umbrellas)
+
+	return nil
+}
+
+// PrintTree prints tree the way sync shows the stack once it's done, leaving out
+// merged branches without children
+func PrintTree(gitClient git.GitClient, tree *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, umbrellas map[string]bool) {
+	tree = filterMergedBranchesForSync(tree, prCache)
+	printTreeForSync(gitClient, tree, currentBranch, prCache, umbrellas)
+}
+
+// filterMergedBranchesForSync removes branches with merged PRs from the tree,
+// but only if they don't have children (to keep the stack structure visible)
+func filterMergedBranchesForSync(node *stack.TreeNode, prCache map[string]*github.PRInfo) *stack.TreeNode {
+	if node == nil {
+		return nil
+	}
+
+	// Filter children recursively first
+	var filteredChildren []*stack.TreeNode
+	for _, child := range node.Children {
+		// Recurse first to process all descendants
+		filtered := filterMergedBranchesForSync(child, prCache)
+
+		// Only filter out merged branches if they have no children
+		if pr, exists := prCache[child.Name]; exists && pr.State == "MERGED" {
+			// If this merged branch still has children after filtering, keep it
+			if filtered != nil && len(filtered.Children) > 0 {
+				filteredChildren = append(filteredChildren, filtered)
+			}
+			// Otherwise skip this merged leaf branch
+		} else {
+			// Not merged, keep it
+			if filtered != nil {
+				filteredChildren = append(filteredChildren, filtered)
+			}
+		}
+	}
+
+	node.Children = filteredChildren
+	return node
+}
+
+// printTreeForSync prints the stack tree after sync
+func printTreeForSync(gitClient git.GitClient, node *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, umbrellas map[string]bool) {
+	if node == nil {
+		return
+	}
+	printTreeVerticalForSync(gitClient, node, currentBranch, prCache, umbrellas, false)
+}
+
+func printTreeVerticalForSync(gitClient git.GitClient, node *stack.TreeNode, currentBranch string, prCache map[string]*github.PRInfo, umbrellas map[string]bool, isPipe bool) {
+	if node == nil {
+		return
+	}
+
+	// Determine the current branch marker
+	marker := ""
+	if node.Name == currentBranch {
+		marker = ui.CurrentBranchMarker()
+	}
+
+	// Get PR info from cache
+	prInfo := ""
+	if node.Name != stack.GetBaseBranch(gitClient) {
+		if pr, exists := prCache[node.Name]; exists {
+			prInfo = fmt.Sprintf(" %s%s", ui.PRInfo(pr.URL, pr.State), MergeQueueLabel(pr))
+		}
+	}
+
+	// Print pipe if needed
+	if isPipe {
+		fmt.Printf("  %s\n", ui.Pipe())
+	}
+
+	// Print current node
+	fmt.Printf(" %s%s%s%s\n", ui.Branch(node.Name), UmbrellaLabel(umbrellas, node.Name), prInfo, marker)
+
+	// Print children vertically
+	for _, child := range node.Children {
+		printTreeVerticalForSync(gitClient, child, currentBranch, prCache, umbrellas, true)
+	}
+}
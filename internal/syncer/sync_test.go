@@ -0,0 +1,106 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteMergedBranches(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	tests := []struct {
+		name       string
+		parents    map[string]string
+		worktrees  map[string]string
+		setupMocks func(*testutil.MockGitClient)
+	}{
+		{
+			name:    "force-deletes a branch whose commits landed",
+			parents: map[string]string{"feature-a": "main"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{}, nil)
+				mockGit.On("DeleteBranchForce", "feature-a").Return(nil)
+			},
+		},
+		{
+			name:    "checks the branch the PR was merged into",
+			parents: map[string]string{"feature-a": "feature-x"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No GetUniqueCommits against origin/main: the PR wasn't merged into it
+				mockGit.On("GetCommitHash", "origin/feature-x").Return("x1", nil)
+				mockGit.On("GetUniqueCommits", "origin/feature-x", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("IsMergedByPatch", "origin/feature-x", "feature-a").Return(true, nil)
+				mockGit.On("DeleteBranchForce", "feature-a").Return(nil)
+			},
+		},
+		{
+			name:    "keeps a branch with commits that never reached origin",
+			parents: map[string]string{"feature-a": "main"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No DeleteBranchForce: git refuses the safe delete, and that stands
+				mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{"a1", "a2"}, nil)
+				mockGit.On("IsMergedByPatch", "origin/main", "feature-a").Return(false, nil)
+				mockGit.On("DeleteBranch", "feature-a").Return(errors.New("not fully merged"))
+			},
+		},
+		{
+			name:      "keeps the worktree of a branch with unpushed commits",
+			parents:   map[string]string{"feature-a": "main"},
+			worktrees: map[string]string{"feature-a": "/repo/.worktrees/feature-a"},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No RemoveWorktree or DeleteBranch: the commits would be lost
+				mockGit.On("GetUniqueCommits", "origin/main", "feature-a").Return([]string{"a1"}, nil)
+				mockGit.On("IsMergedByPatch", "origin/main", "feature-a").Return(false, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			mockGit.On("GetCommitHash", "origin/feature-a").Return("", errors.New("unknown revision"))
+			mockGit.On("GetCommitHash", "origin/main").Return("m2", nil).Maybe()
+			tt.setupMocks(mockGit)
+
+			deleteMergedBranches(mockGit, []string{"feature-a"}, tt.parents, "main", "feature-b", tt.worktrees, "/repo")
+
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFilterMergedBranchesForSync(t *testing.T) {
+	// Test the filterMergedBranchesForSync function
+	// This is a simple unit test for the tree filtering logic
+	prCache := map[string]*github.PRInfo{
+		"merged-leaf":   testutil.NewPRInfo(1, "MERGED", "main", "Merged Leaf", "url"),
+		"merged-parent": testutil.NewPRInfo(2, "MERGED", "main", "Merged Parent", "url"),
+	}
+
+	tree := &stack.TreeNode{
+		Name: "main",
+		Children: []*stack.TreeNode{
+			{
+				Name: "merged-parent",
+				Children: []*stack.TreeNode{
+					{Name: "child-of-merged", Children: nil},
+				},
+			},
+			{Name: "merged-leaf", Children: nil},
+			{Name: "open-branch", Children: nil},
+		},
+	}
+
+	filtered := filterMergedBranchesForSync(tree, prCache)
+
+	// merged-parent should be kept because it has children
+	// merged-leaf should be filtered out
+	// open-branch should be kept
+	assert.Equal(t, 2, len(filtered.Children))
+}
@@ -0,0 +1,128 @@
+// Package syncer syncs stacks: its Engine restacks each branch onto its parent,
+// pushes it and keeps its PR up to date, reporting its progress as Events. The
+// PR and push helpers it uses are shared with the other commands.
+package syncer
+
+import (
+	"fmt"
+	"time"
+)
+
+// Options are the sync flags, after config defaults were applied. A plan from
+// --plan --json records the ones with a JSON name so --apply syncs the same way;
+// the others are settings of the run applying it.
+type Options struct {
+	Force                   bool   `json:"force"`
+	CherryPick              bool   `json:"cherryPick"`
+	DetectMergedByPatch     bool   `json:"detectMergedByPatch"`
+	DetectMergedByCommit    bool   `json:"detectMergedByCommit"`
+	PruneMerged             bool   `json:"pruneMerged"`
+	DeleteMerged            bool   `json:"deleteMerged"`
+	CreatePRs               bool   `json:"createPRs"`
+	DraftPRs                bool   `json:"draftPRs"`
+	RequestReviewers        bool   `json:"requestReviewers"`
+	PRNav                   bool   `json:"prNav"`
+	WaitForCI               bool   `json:"waitForCI"`
+	CITimeout               string `json:"ciTimeout"` // Go duration, DefaultCITimeout when empty
+	ReviewComment           string `json:"reviewComment,omitempty"`
+	AllowReviewInvalidation bool   `json:"allowReviewInvalidation"`
+
+	Resume           bool         `json:"-"` // Carry on after conflicts were resolved
+	Abort            bool         `json:"-"` // Abort an interrupted sync instead
+	Full             bool         `json:"-"` // Walk the whole stack even if nothing changed since the last sync
+	Verify           bool         `json:"-"` // Check the result once the sync is done
+	ProtectApprovals bool         `json:"-"` // Ask before pushing content changes to approved PRs
+	CommitLint       string       `json:"-"` // Commit message linter command, empty when off
+	CommitLintWarn   bool         `json:"-"` // Report lint failures without stopping
+	DCO              string       `json:"-"` // DCOCheck, DCOFix, or empty when off
+	SizeLimits       SizeLimits   `json:"-"` // Review size limits, zero when off
+	PRPosition       PositionMode `json:"-"` // How PRs are marked with their place in the stack
+	PRScope          string       `json:"-"` // PRScopeStack (when empty) or PRScopeAuthor
+	FlatPRs          bool         `json:"-"` // PRs target the base branch instead of parents
+	PushStrategy     string       `json:"-"` // PushLeaseSHA (when empty), PushLease or PushForce
+	ForceBranches    []string     `json:"-"` // Patterns of branches always pushed with PushForce
+}
+
+// Validate reports options sync can't run with
+func (o Options) Validate() error {
+	_, err := o.ciTimeout()
+	return err
+}
+
+// ciTimeout is CITimeout as a duration
+func (o Options) ciTimeout() (time.Duration, error) {
+	if o.CITimeout == "" {
+		return DefaultCITimeout, nil
+	}
+	timeout, err := time.ParseDuration(o.CITimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ciTimeout %q: %w", o.CITimeout, err)
+	}
+	return timeout, nil
+}
+
+// EventKind is what happened in an Event
+type EventKind int
+
+const (
+	// BranchStarted is reported before anything is done to a branch
+	BranchStarted EventKind = iota
+	// BranchSkipped is reported for a branch left alone, e.g. merged or queued
+	BranchSkipped
+	// StepStarted is reported when a rebase or push of the branch starts
+	StepStarted
+	// StepDone is reported when that step succeeded
+	StepDone
+	// StepFailed is reported when that step failed, with its error
+	StepFailed
+	// Conflict is reported when a rebase stopped on conflicts to resolve
+	Conflict
+	// Finished is reported once, when the sync returns, with its error if any
+	Finished
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case BranchStarted:
+		return "branch-started"
+	case BranchSkipped:
+		return "branch-skipped"
+	case StepStarted:
+		return "step-started"
+	case StepDone:
+		return "step-done"
+	case StepFailed:
+		return "step-failed"
+	case Conflict:
+		return "conflict"
+	case Finished:
+		return "finished"
+	}
+	return "unknown"
+}
+
+// Event is one step of a sync's progress
+type Event struct {
+	Kind    EventKind
+	Branch  string // empty for Finished
+	Index   int    // 1-based position of Branch in the sync
+	Total   int    // number of branches synced
+	Message string // what the step does, or why the branch was skipped
+	Err     error  // for StepFailed, Conflict and Finished
+}
+
+// Observer gets the events of a sync as they happen, on the syncing goroutine
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc lets a function be used as an Observer
+type ObserverFunc func(Event)
+
+// OnEvent calls f(event)
+func (f ObserverFunc) OnEvent(event Event) {
+	f(event)
+}
+
+// Discard is an Observer that ignores every event
+var Discard Observer = ObserverFunc(func(Event) {})
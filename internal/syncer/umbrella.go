@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// Umbrella branches (branch.<name>.stackumbrella) have no commits of their own and
+// only group sub-stacks. They are kept on top of their parent but never pushed,
+// so the PRs of their children target the nearest non-umbrella ancestor.
+
+// UmbrellaLabel marks an umbrella branch in the stack tree
+func UmbrellaLabel(umbrellas map[string]bool, branch string) string {
+	if !umbrellas[branch] {
+		return ""
+	}
+	return " " + ui.Dim("(umbrella)")
+}
+
+// PRBaseFor returns the branch a PR should target for a branch with the given
+// parent, skipping over umbrella branches since they aren't on origin
+func PRBaseFor(parent string, parents map[string]string, umbrellas map[string]bool) string {
+	seen := make(map[string]bool)
+	for umbrellas[parent] && !seen[parent] {
+		seen[parent] = true
+		next, ok := parents[parent]
+		if !ok {
+			break
+		}
+		parent = next
+	}
+	return parent
+}
+
+// syncUmbrella moves the checked out umbrella branch to rebaseTarget. It returns
+// false if the branch has commits after all, in which case it's synced like any
+// other branch.
+func syncUmbrella(gitClient git.GitClient, progress *spinner.Progress, branch, rebaseTarget string) (bool, error) {
+	commits, err := gitClient.GetUniqueCommits(rebaseTarget, branch)
+	if err != nil {
+		return false, fmt.Errorf("failed to check commits on umbrella branch %s: %w", branch, err)
+	}
+	if len(commits) > 0 {
+		fmt.Printf("  %s Umbrella branch has %d commit(s), syncing it like a regular branch\n", ui.WarningIcon(), len(commits))
+		return false, nil
+	}
+
+	if err := progress.Step(
+		"  ",
+		fmt.Sprintf("Moving umbrella to %s...", rebaseTarget),
+		fmt.Sprintf("Moved umbrella to %s (not pushed, no PR)", rebaseTarget),
+		func() error {
+			return gitClient.ResetHard(rebaseTarget)
+		},
+	); err != nil {
+		return false, fmt.Errorf("failed to move umbrella branch %s: %w", branch, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPRBaseFor(t *testing.T) {
+	parents := map[string]string{
+		"payments":     "main",
+		"payments-api": "payments",
+		"api-v2":       "api",
+		"api":          "payments-api",
+	}
+
+	tests := []struct {
+		name      string
+		parent    string
+		umbrellas map[string]bool
+		expected  string
+	}{
+		{"regular parent", "payments-api", map[string]bool{"payments": true}, "payments-api"},
+		{"umbrella parent", "payments", map[string]bool{"payments": true}, "main"},
+		{"nested umbrellas", "api", map[string]bool{"api": true, "payments-api": true, "payments": true}, "main"},
+		{"no umbrellas", "payments", nil, "payments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PRBaseFor(tt.parent, parents, tt.umbrellas))
+		})
+	}
+}
+
+func TestSyncUmbrella(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	t.Run("moves an empty umbrella to its parent", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetUniqueCommits", "origin/main", "payments").Return([]string{}, nil)
+		mockGit.On("ResetHard", "origin/main").Return(nil)
+
+		handled, err := syncUmbrella(mockGit, spinner.NewProgress("Syncing", 1), "payments", "origin/main")
+
+		assert.NoError(t, err)
+		assert.True(t, handled)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("umbrella with commits is synced like a regular branch", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetUniqueCommits", "origin/main", "payments").Return([]string{"abc123"}, nil)
+
+		handled, err := syncUmbrella(mockGit, spinner.NewProgress("Syncing", 1), "payments", "origin/main")
+
+		assert.NoError(t, err)
+		assert.False(t, handled)
+		mockGit.AssertNotCalled(t, "ResetHard", "origin/main")
+	})
+}
@@ -0,0 +1,121 @@
+package syncer
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/spinner"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configSyncUpdateRefs set to "false" makes sync always rebase a stack branch by
+// branch, even where git could restack it in one pass
+const configSyncUpdateRefs = "stack.sync.updateRefs"
+
+// updateRefsEligible reports whether sorted (bottom to top) can be restacked by
+// rebasing its top branch with 'git rebase --update-refs': each branch sits
+// directly on the one below and contains its tip, none has merged or is queued,
+// and nothing sync does to a branch before its rebase applies. Anything else is
+// left to the branch by branch rebase.
+func (e *Engine) updateRefsEligible(gitClient git.GitClient, hidden *syncWorktree, sorted []stack.StackBranch, prCache map[string]*github.PRInfo, umbrellas map[string]bool, remoteBranches map[string]bool, signOffs map[string][]unsignedCommit) bool {
+	if len(sorted) < 2 || git.DryRun || e.options.CherryPick || e.options.DetectMergedByPatch || len(signOffs) > 0 {
+		return false
+	}
+	if gitClient.GetConfig(configSyncUpdateRefs) == "false" || !gitClient.SupportsUpdateRefs() {
+		return false
+	}
+
+	top := sorted[len(sorted)-1].Name
+	for i, branch := range sorted {
+		if i > 0 && branch.Parent != sorted[i-1].Name {
+			return false
+		}
+		if pr := prCache[branch.Name]; (pr != nil && pr.State == "MERGED") || InMergeQueue(pr) || umbrellas[branch.Name] {
+			return false
+		}
+		// git leaves branches checked out in another worktree where they are
+		if hidden != nil && hidden.path != "" && branch.Name == hidden.mainBranch && branch.Name != top {
+			return false
+		}
+		// A branch still to be fetched or fast-forwarded to origin is reset first
+		if prCache[branch.Name] != nil && !remoteBranches[branch.Name] {
+			return false
+		}
+		if remoteBranches[branch.Name] && isBehindRemote(gitClient, branch.Name) {
+			return false
+		}
+		if picked, err := gitClient.GetPickedCommits(branch.Parent, branch.Name); err != nil || len(picked) > 0 {
+			return false
+		}
+		if i > 0 {
+			parentHash, err := gitClient.GetCommitHash(branch.Parent)
+			if err != nil {
+				return false
+			}
+			if mergeBase, err := gitClient.GetMergeBase(branch.Name, branch.Parent); err != nil || mergeBase != parentHash {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isBehindRemote reports whether origin has commits on branch that the local
+// branch hasn't, with none of its own on top
+func isBehindRemote(gitClient git.GitClient, branch string) bool {
+	localHash, err := gitClient.GetCommitHash(branch)
+	if err != nil {
+		return true
+	}
+	remoteHash, err := gitClient.GetCommitHash(git.RemoteRef(branch))
+	if err != nil || remoteHash == localHash {
+		return err != nil
+	}
+	mergeBase, err := gitClient.GetMergeBase(branch, git.RemoteRef(branch))
+	return err != nil || mergeBase == localHash
+}
+
+// restackWithUpdateRefs rebases a linear stack in a single pass, rebasing its top
+// branch onto target with --update-refs so every branch below follows. It returns
+// the commit each restacked branch now sits on, for the branch by branch loop to
+// skip their rebases; nil when the stack doesn't qualify or the rebase stopped,
+// which leaves all of it to the loop.
+func restackWithUpdateRefs(gitClient git.GitClient, hidden *syncWorktree, sorted []stack.StackBranch, target string) map[string]string {
+	top := sorted[len(sorted)-1].Name
+	branchGit := hidden.clientFor(gitClient, top)
+
+	message := fmt.Sprintf("Restacking %d branches in one rebase of %s...", len(sorted), top)
+	done := fmt.Sprintf("Restacked %d branches onto %s", len(sorted), target)
+	err := spinner.WrapWithSuccess(message, done, func() error {
+		if err := branchGit.CheckoutBranch(top); err != nil {
+			return err
+		}
+		return branchGit.RebaseUpdateRefs(target)
+	})
+	if err != nil {
+		// A rebase that stopped part way, on a conflict or otherwise, is undone
+		_ = branchGit.AbortRebase()
+		fmt.Fprintf(os.Stderr, "%s Could not restack in one pass, rebasing branch by branch: %v\n\n", ui.WarningIcon(), err)
+		return nil
+	}
+
+	// Only branches git actually moved onto the one below count as restacked
+	restacked := make(map[string]string)
+	onto := target
+	for _, branch := range sorted {
+		ontoHash, err := gitClient.GetCommitHash(onto)
+		if err != nil {
+			break
+		}
+		if mergeBase, err := gitClient.GetMergeBase(branch.Name, onto); err != nil || mergeBase != ontoHash {
+			break
+		}
+		restacked[branch.Name] = ontoHash
+		onto = branch.Name
+	}
+	fmt.Println()
+	return restacked
+}
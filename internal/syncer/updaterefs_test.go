@@ -0,0 +1,178 @@
+package syncer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdateRefsEligible(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	sorted := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+	}
+	remote := map[string]bool{"feature-a": true}
+	engine := New(nil, nil, Options{}, nil)
+
+	tests := []struct {
+		name       string
+		branches   []stack.StackBranch
+		prs        map[string]*github.PRInfo
+		setupMocks func(*testutil.MockGitClient)
+		expected   bool
+	}{
+		{
+			name:     "linear stack on top of its parents",
+			branches: sorted,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(true)
+				mockGit.On("GetPickedCommits", mock.Anything, mock.Anything).Return([]git.PickedCommit{}, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "origin/feature-a").Return("sha-a", nil)
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("sha-a", nil)
+			},
+			expected: true,
+		},
+		{
+			name:     "branch no longer on its parent's tip",
+			branches: sorted,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(true)
+				mockGit.On("GetPickedCommits", mock.Anything, mock.Anything).Return([]git.PickedCommit{}, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "origin/feature-a").Return("sha-a", nil)
+				mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("old-a", nil)
+			},
+		},
+		{
+			name:     "branch behind origin",
+			branches: sorted,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(true)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "origin/feature-a").Return("sha-remote", nil)
+				mockGit.On("GetMergeBase", "feature-a", "origin/feature-a").Return("sha-a", nil)
+			},
+		},
+		{
+			name:     "merged branch",
+			branches: sorted,
+			prs:      map[string]*github.PRInfo{"feature-a": {Number: 1, State: "MERGED"}},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No branch lookups: feature-a is ruled out before its commits are looked at
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(true)
+			},
+		},
+		{
+			name: "branching stack",
+			branches: []stack.StackBranch{
+				{Name: "feature-a", Parent: "main"},
+				{Name: "feature-b", Parent: "main"},
+			},
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(true)
+				mockGit.On("GetPickedCommits", mock.Anything, mock.Anything).Return([]git.PickedCommit{}, nil)
+				mockGit.On("GetCommitHash", "feature-a").Return("sha-a", nil)
+				mockGit.On("GetCommitHash", "origin/feature-a").Return("sha-a", nil)
+			},
+		},
+		{
+			name:     "git too old",
+			branches: sorted,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("")
+				mockGit.On("SupportsUpdateRefs").Return(false)
+			},
+		},
+		{
+			name:     "turned off",
+			branches: sorted,
+			setupMocks: func(mockGit *testutil.MockGitClient) {
+				// No SupportsUpdateRefs: git isn't asked when the config turns it off
+				mockGit.On("GetConfig", configSyncUpdateRefs).Return("false")
+			},
+		},
+		{
+			name:       "single branch",
+			branches:   sorted[:1],
+			setupMocks: func(mockGit *testutil.MockGitClient) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockGit := new(testutil.MockGitClient)
+			tt.setupMocks(mockGit)
+			prs := tt.prs
+			if prs == nil {
+				prs = map[string]*github.PRInfo{}
+			}
+
+			assert.Equal(t, tt.expected, engine.updateRefsEligible(mockGit, nil, tt.branches, prs, nil, remote, nil))
+			mockGit.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRestackWithUpdateRefs(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	sorted := []stack.StackBranch{
+		{Name: "feature-a", Parent: "main"},
+		{Name: "feature-b", Parent: "feature-a"},
+	}
+
+	t.Run("rebases the top branch and reports where each branch landed", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("RebaseUpdateRefs", "origin/main").Return(nil)
+		mockGit.On("GetCommitHash", "origin/main").Return("sha-main", nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("sha-main", nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("new-a", nil)
+		mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("new-a", nil)
+
+		restacked := restackWithUpdateRefs(mockGit, nil, sorted, "origin/main")
+
+		assert.Equal(t, map[string]string{"feature-a": "sha-main", "feature-b": "new-a"}, restacked)
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("leaves the branches git didn't move to the branch by branch rebase", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("RebaseUpdateRefs", "origin/main").Return(nil)
+		mockGit.On("GetCommitHash", "origin/main").Return("sha-main", nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("old-main", nil)
+
+		restacked := restackWithUpdateRefs(mockGit, nil, sorted, "origin/main")
+
+		assert.Empty(t, restacked)
+	})
+
+	t.Run("aborts on conflicts and falls back", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("CheckoutBranch", "feature-b").Return(nil)
+		mockGit.On("RebaseUpdateRefs", "origin/main").Return(&git.ConflictError{Err: errors.New("conflict")})
+		mockGit.On("AbortRebase").Return(nil)
+
+		restacked := restackWithUpdateRefs(mockGit, nil, sorted, "origin/main")
+
+		assert.Nil(t, restacked)
+		mockGit.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,138 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/stack"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// verifyResult is the outcome of the checks on one branch after a sync
+type verifyResult struct {
+	Branch  string
+	Passed  []string
+	Failed  []string // Failed checks, and checks that couldn't be run
+	Skipped []string
+}
+
+// ok reports whether no check failed for the branch
+func (r verifyResult) ok() bool {
+	return len(r.Failed) == 0
+}
+
+// verifySyncResult re-reads the stack after a sync and checks that each branch that
+// is still tracked ended up where sync meant to put it: its open PR targets its
+// parent, origin has the local tip, and it contains its parent. It prints a
+// checklist and fails when any check does, so a warning earlier in the output
+// can't hide a partial sync.
+func (e *Engine) verifySyncResult(gitClient git.GitClient, githubClient github.GitHubClient, branches []stack.StackBranch, baseBranch string) error {
+	fmt.Println()
+	fmt.Println("Verifying the sync...")
+
+	parents, err := gitClient.GetAllStackParents()
+	if err != nil {
+		return fmt.Errorf("failed to read the stack: %w", err)
+	}
+	umbrellas, _ := gitClient.GetStackUmbrellas()
+
+	// Merged branches are no longer tracked once sync is done with them
+	var names []string
+	for _, b := range branches {
+		if _, tracked := parents[b.Name]; tracked && !umbrellas[b.Name] {
+			names = append(names, b.Name)
+		}
+	}
+
+	// PRs are looked up again, since sync only updated them on GitHub
+	prs, prErr := LoadPRs(githubClient, names, e.options.PRScope)
+	remoteBranches := gitClient.GetRemoteBranchesSet()
+
+	failed := 0
+	for _, name := range names {
+		result := e.verifyBranch(gitClient, name, parents, umbrellas, prs, prErr, remoteBranches, baseBranch)
+		printVerifyResult(result)
+		if !result.ok() {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Printf("\n%s %d of %d branch(es) failed verification\n", ui.ErrorIcon(), failed, len(names))
+		return ui.Reported(errors.New("sync verification failed"))
+	}
+	fmt.Printf("\n%s All %d branch(es) verified\n", ui.SuccessIcon(), len(names))
+	return nil
+}
+
+// verifyBranch runs the post-sync checks on one branch
+func (e *Engine) verifyBranch(gitClient git.GitClient, branch string, parents map[string]string, umbrellas map[string]bool, prs map[string]*github.PRInfo, prErr error, remoteBranches map[string]bool, baseBranch string) verifyResult {
+	result := verifyResult{Branch: branch}
+	parent := parents[branch]
+
+	// The open PR targets the parent (or the branch below an umbrella parent)
+	switch pr := prs[branch]; {
+	case prErr != nil:
+		result.Failed = append(result.Failed, fmt.Sprintf("could not load PRs: %v", prErr))
+	case pr == nil || pr.State != "OPEN":
+		result.Skipped = append(result.Skipped, "no open PR")
+	case e.options.FlatPRs:
+		result.Skipped = append(result.Skipped, fmt.Sprintf("PR #%d base not checked with stack.flatPRs", pr.Number))
+	default:
+		want := PRBaseFor(parent, parents, umbrellas)
+		if pr.Base == want {
+			result.Passed = append(result.Passed, fmt.Sprintf("PR #%d targets %s", pr.Number, want))
+		} else {
+			result.Failed = append(result.Failed, fmt.Sprintf("PR #%d targets %s, expected %s", pr.Number, pr.Base, want))
+		}
+	}
+
+	// origin has what's local
+	if !remoteBranches[branch] {
+		result.Skipped = append(result.Skipped, "not on "+git.RemoteOf(branch))
+	} else {
+		local, localErr := gitClient.GetCommitHash(branch)
+		remote, remoteErr := gitClient.GetCommitHash(git.RemoteRef(branch))
+		switch {
+		case localErr != nil || remoteErr != nil:
+			result.Failed = append(result.Failed, fmt.Sprintf("could not compare with %s", git.RemoteRef(branch)))
+		case local == remote:
+			result.Passed = append(result.Passed, "pushed")
+		default:
+			result.Failed = append(result.Failed, fmt.Sprintf("%s is at %s, local is at %s", git.RemoteRef(branch), ui.ShortSHA(remote), ui.ShortSHA(local)))
+		}
+	}
+
+	// The branch contains its parent
+	parentRef := parent
+	if parent == baseBranch {
+		parentRef = git.RemoteRef(baseBranch)
+	}
+	parentTip, tipErr := gitClient.GetCommitHash(parentRef)
+	mergeBase, baseErr := gitClient.GetMergeBase(branch, parentRef)
+	switch {
+	case tipErr != nil || baseErr != nil:
+		result.Failed = append(result.Failed, fmt.Sprintf("could not compare with %s", parentRef))
+	case mergeBase == parentTip:
+		result.Passed = append(result.Passed, "up to date with "+parent)
+	default:
+		result.Failed = append(result.Failed, "behind "+parentRef)
+	}
+
+	return result
+}
+
+// printVerifyResult prints one line of the checklist, with the failures under it
+func printVerifyResult(r verifyResult) {
+	if r.ok() {
+		fmt.Printf("  %s %s %s\n", ui.SuccessIcon(), ui.Branch(r.Branch), ui.Dim(strings.Join(append(r.Passed, r.Skipped...), ", ")))
+		return
+	}
+	fmt.Printf("  %s %s\n", ui.ErrorIcon(), ui.Branch(r.Branch))
+	for _, failure := range r.Failed {
+		fmt.Printf("      %s\n", failure)
+	}
+}
@@ -0,0 +1,61 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/javoire/stackinator/internal/github"
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyBranch(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	parents := map[string]string{"feature-a": "main", "feature-b": "feature-a"}
+	remote := map[string]bool{"feature-a": true, "feature-b": true}
+	engine := New(nil, nil, Options{}, nil)
+
+	t.Run("passes when everything is in place", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCommitHash", "feature-b").Return("bbb", nil)
+		mockGit.On("GetCommitHash", "origin/feature-b").Return("bbb", nil)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("aaa", nil)
+		prs := map[string]*github.PRInfo{"feature-b": testutil.NewPRInfo(13, "OPEN", "feature-a", "B", "url")}
+
+		result := engine.verifyBranch(mockGit, "feature-b", parents, nil, prs, nil, remote, "main")
+
+		assert.True(t, result.ok())
+		assert.Equal(t, []string{"PR #13 targets feature-a", "pushed", "up to date with feature-a"}, result.Passed)
+	})
+
+	t.Run("reports each failed check", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa2", nil)
+		mockGit.On("GetCommitHash", "origin/feature-a").Return("aaa1", nil)
+		mockGit.On("GetCommitHash", "origin/main").Return("mmm2", nil)
+		mockGit.On("GetMergeBase", "feature-a", "origin/main").Return("mmm1", nil)
+		prs := map[string]*github.PRInfo{"feature-a": testutil.NewPRInfo(12, "OPEN", "develop", "A", "url")}
+
+		result := engine.verifyBranch(mockGit, "feature-a", parents, nil, prs, nil, remote, "main")
+
+		assert.False(t, result.ok())
+		assert.Equal(t, []string{
+			"PR #12 targets develop, expected main",
+			"origin/feature-a is at aaa1, local is at aaa2",
+			"behind origin/main",
+		}, result.Failed)
+	})
+
+	t.Run("skips checks that don't apply", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetCommitHash", "feature-a").Return("aaa", nil)
+		mockGit.On("GetMergeBase", "feature-b", "feature-a").Return("aaa", nil)
+
+		result := engine.verifyBranch(mockGit, "feature-b", parents, nil, nil, nil, map[string]bool{}, "main")
+
+		assert.True(t, result.ok())
+		assert.Equal(t, []string{"no open PR", "not on origin"}, result.Skipped)
+	})
+}
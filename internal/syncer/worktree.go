@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/javoire/stackinator/internal/ui"
+)
+
+// configSyncWorktree set to "false" makes sync rebase every branch in the current
+// worktree, checking each one out in turn
+const configSyncWorktree = "stack.sync.worktree"
+
+// syncWorktreeDir is where the hidden worktree lives, relative to the git common
+// dir. Being inside .git keeps it out of sight of editors and file watchers.
+const syncWorktreeDir = "stackinator/sync-worktree"
+
+// errConflictInSyncWorktree is returned for a rebase that stopped on conflicts in
+// the hidden worktree. Conflicts are resolved in the user's worktree, so sync
+// aborts it there and starts the branch over in the user's worktree.
+var errConflictInSyncWorktree = errors.New("stopped on conflicts, starting over in this worktree to resolve them")
+
+// syncWorktree is a hidden worktree sync rebases branches in, so the user's
+// worktree stays on its branch instead of checking out every branch of the stack
+type syncWorktree struct {
+	path       string
+	git        git.GitClient // Runs commands in the hidden worktree
+	mainBranch string        // Branch checked out in the user's worktree
+}
+
+// openSyncWorktree creates the hidden worktree, removing one left behind by an
+// interrupted sync first. It returns nil when branches are to be rebased in the
+// user's worktree: in dry-run, with --cherry-pick (which rebuilds branches from
+// their parent's checkout), when turned off, or when the worktree can't be made.
+func (e *Engine) openSyncWorktree(gitClient git.GitClient) *syncWorktree {
+	if git.DryRun || e.options.CherryPick || gitClient.GetConfig(configSyncWorktree) == "false" {
+		return nil
+	}
+	commonDir, err := gitClient.GetCommonDir()
+	if err != nil {
+		return nil
+	}
+	path := filepath.Join(commonDir, syncWorktreeDir)
+
+	if _, err := os.Stat(path); err == nil {
+		if err := gitClient.ForceRemoveWorktree(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to remove the sync worktree left at %s, rebasing here: %v\n", path, err)
+			return nil
+		}
+	}
+
+	mainBranch, err := gitClient.GetCurrentBranch()
+	if err != nil {
+		return nil
+	}
+	if err := gitClient.AddWorktreeDetached(path, "HEAD"); err != nil {
+		if git.Verbose {
+			fmt.Printf("  Note: could not create the sync worktree, rebasing here: %v\n", err)
+		}
+		return nil
+	}
+	return &syncWorktree{path: path, git: gitClient.InWorktree(path), mainBranch: mainBranch}
+}
+
+// clientFor returns the client to rebase branch with: the hidden worktree's,
+// unless branch is checked out in the user's worktree, where it has to be rebased,
+// or the hidden worktree was closed
+func (w *syncWorktree) clientFor(gitClient git.GitClient, branch string) git.GitClient {
+	if w == nil || w.path == "" || branch == w.mainBranch {
+		return gitClient
+	}
+	return w.git
+}
+
+// close removes the hidden worktree, releasing the branch checked out in it
+func (w *syncWorktree) close(gitClient git.GitClient) {
+	if w == nil || w.path == "" {
+		return
+	}
+	if err := gitClient.ForceRemoveWorktree(w.path); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove the sync worktree: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Remove it with: %s\n", ui.Command("git worktree remove --force "+w.path))
+	}
+	w.path = ""
+}
@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/javoire/stackinator/internal/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestOpenSyncWorktree(t *testing.T) {
+	testutil.SetupTest()
+	defer testutil.TeardownTest()
+
+	engine := New(nil, nil, Options{}, nil)
+
+	t.Run("turned off", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configSyncWorktree).Return("false")
+
+		assert.Nil(t, engine.openSyncWorktree(mockGit))
+		mockGit.AssertNotCalled(t, "AddWorktreeDetached", mock.Anything, mock.Anything)
+	})
+
+	t.Run("removes a worktree left by an interrupted sync", func(t *testing.T) {
+		commonDir := t.TempDir()
+		path := filepath.Join(commonDir, syncWorktreeDir)
+		assert.NoError(t, os.MkdirAll(path, 0o755))
+
+		mockGit := new(testutil.MockGitClient)
+		worktreeGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configSyncWorktree).Return("")
+		mockGit.On("GetCommonDir").Return(commonDir, nil)
+		mockGit.On("ForceRemoveWorktree", path).Return(nil)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("AddWorktreeDetached", path, "HEAD").Return(nil)
+		mockGit.On("InWorktree", path).Return(worktreeGit)
+
+		w := engine.openSyncWorktree(mockGit)
+
+		assert.NotNil(t, w)
+		assert.Equal(t, worktreeGit, w.clientFor(mockGit, "feature-a"))
+		assert.Equal(t, mockGit, w.clientFor(mockGit, "feature-b"))
+		mockGit.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the current worktree when it can't be created", func(t *testing.T) {
+		mockGit := new(testutil.MockGitClient)
+		mockGit.On("GetConfig", configSyncWorktree).Return("")
+		mockGit.On("GetCommonDir").Return(t.TempDir(), nil)
+		mockGit.On("GetCurrentBranch").Return("feature-b", nil)
+		mockGit.On("AddWorktreeDetached", mock.Anything, "HEAD").Return(errors.New("boom"))
+
+		w := engine.openSyncWorktree(mockGit)
+
+		assert.Nil(t, w)
+		assert.Equal(t, mockGit, w.clientFor(mockGit, "feature-a"))
+	})
+}
@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/javoire/stackinator/internal/git"
+	"github.com/stretchr/testify/mock"
+)
+
+// The Allow helpers let a test run code that reads or records stack state on the
+// side, without each test spelling those calls out. The mocks may go uncalled.
+
+// AllowOpLog lets a test run an operation that records itself for 'stack undo',
+// without a git directory to record it in
+func AllowOpLog(mockGit *MockGitClient) {
+	mockGit.On("GetCommonDir").Return("", errors.New("not a git repository")).Maybe()
+}
+
+// AllowSubmodules lets a sync check for stale submodules, finding none
+func AllowSubmodules(mockGit *MockGitClient) {
+	mockGit.On("GetStaleSubmodules").Return([]string{}, nil).Maybe()
+	mockGit.On("GetConflictedSubmodules").Return([]string{}, nil).Maybe()
+}
+
+// AllowUpdateRefs lets a sync run on a git without --update-refs, so stacks are
+// rebased branch by branch
+func AllowUpdateRefs(mockGit *MockGitClient) {
+	mockGit.On("GetConfig", "stack.sync.updateRefs").Return("").Maybe()
+	mockGit.On("SupportsUpdateRefs").Return(false).Maybe()
+}
+
+// AllowPickedCommits lets a sync look for picked commits, finding none
+func AllowPickedCommits(mockGit *MockGitClient) {
+	mockGit.On("GetPickedCommits", mock.Anything, mock.Anything).Return([]git.PickedCommit{}, nil).Maybe()
+}
+
+// AllowPRPins lets a sync read and write PR pins
+func AllowPRPins(mockGit *MockGitClient) {
+	mockGit.On("GetAllStackPRs").Return(map[string]int{}, nil).Maybe()
+	mockGit.On("GetAllStackPRBases").Return(map[string]string{}, nil).Maybe()
+	mockGit.On("SetConfig", mock.MatchedBy(func(key string) bool {
+		return strings.HasSuffix(key, ".stackpr") || strings.HasSuffix(key, ".stackprbase")
+	}), mock.Anything).Return(nil).Maybe()
+}
+
+// AllowStackUmbrellas lets a command read umbrella branches, finding none
+func AllowStackUmbrellas(mockGit *MockGitClient) {
+	mockGit.On("GetStackUmbrellas").Return(map[string]bool{}, nil).Maybe()
+}
+
+// AllowSyncState lets a sync look up and record the state it left the stack in
+func AllowSyncState(mockGit *MockGitClient) {
+	isSyncStateKey := mock.MatchedBy(func(key string) bool {
+		return strings.HasSuffix(key, ".stacksynced")
+	})
+	mockGit.On("GetConfig", isSyncStateKey).Return("").Maybe()
+	mockGit.On("SetConfig", isSyncStateKey, mock.Anything).Return(nil).Maybe()
+	mockGit.On("GetBranchTips").Return(map[string]string{}, nil).Maybe()
+}
+
+// AllowSyncWorktree lets a sync rebase in the mocked worktree, without the
+// hidden one
+func AllowSyncWorktree(mockGit *MockGitClient) {
+	mockGit.On("GetConfig", "stack.sync.worktree").Return("false").Maybe()
+}
@@ -1,6 +1,9 @@
 package testutil
 
 import (
+	"time"
+
+	"github.com/javoire/stackinator/internal/git"
 	"github.com/javoire/stackinator/internal/github"
 	"github.com/stretchr/testify/mock"
 )
@@ -10,11 +13,31 @@ type MockGitClient struct {
 	mock.Mock
 }
 
+func (m *MockGitClient) GetHooksDir() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetCommonDir() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockGitClient) GetRepoRoot() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockGitClient) IsBareRepo() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockGitClient) CheckBranchName(name string) error {
+	args := m.Called(name)
+	return args.Error(0)
+}
+
 func (m *MockGitClient) GetCurrentBranch() (string, error) {
 	args := m.Called()
 	return args.String(0), args.Error(1)
@@ -35,6 +58,31 @@ func (m *MockGitClient) GetAllStackParents() (map[string]string, error) {
 	return args.Get(0).(map[string]string), args.Error(1)
 }
 
+func (m *MockGitClient) GetAllStackPRs() (map[string]int, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+func (m *MockGitClient) GetAllStackPRBases() (map[string]string, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetStackUmbrellas() (map[string]bool, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
+func (m *MockGitClient) GetOrphanedStackParents() (map[string]string, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetStackConfigKeys() ([]string, error) {
+	args := m.Called()
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockGitClient) SetConfig(key, value string) error {
 	args := m.Called(key, value)
 	return args.Error(0)
@@ -70,6 +118,16 @@ func (m *MockGitClient) Rebase(onto string) error {
 	return args.Error(0)
 }
 
+func (m *MockGitClient) SupportsUpdateRefs() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockGitClient) RebaseUpdateRefs(upstream string) error {
+	args := m.Called(upstream)
+	return args.Error(0)
+}
+
 func (m *MockGitClient) RebaseOnto(newBase, oldBase, currentBranch string) error {
 	args := m.Called(newBase, oldBase, currentBranch)
 	return args.Error(0)
@@ -100,6 +158,26 @@ func (m *MockGitClient) IsWorkingTreeClean() (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockGitClient) HasStagedChanges() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGitClient) CommitFixup(commit string) error {
+	args := m.Called(commit)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) Commit(message string, amend, all bool) error {
+	args := m.Called(message, amend, all)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) RebaseAutosquash(upstream string) error {
+	args := m.Called(upstream)
+	return args.Error(0)
+}
+
 func (m *MockGitClient) Fetch() error {
 	args := m.Called()
 	return args.Error(0)
@@ -120,6 +198,14 @@ func (m *MockGitClient) GetRemoteBranchesSet() map[string]bool {
 	return args.Get(0).(map[string]bool)
 }
 
+func (m *MockGitClient) GetBranchTips() (map[string]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func (m *MockGitClient) IsRebaseInProgress() bool {
 	args := m.Called()
 	return args.Bool(0)
@@ -130,6 +216,14 @@ func (m *MockGitClient) IsCherryPickInProgress() bool {
 	return args.Bool(0)
 }
 
+func (m *MockGitClient) GetOperationInProgress() *git.Operation {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil
+	}
+	return args.Get(0).(*git.Operation)
+}
+
 func (m *MockGitClient) AbortRebase() error {
 	args := m.Called()
 	return args.Error(0)
@@ -171,11 +265,60 @@ func (m *MockGitClient) GetUniqueCommitsByPatch(base, branch string) ([]string,
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockGitClient) IsMergedByPatch(base, branch string) (bool, error) {
+	args := m.Called(base, branch)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetBranchesMergedInto(ref string) (map[string]bool, error) {
+	args := m.Called(ref)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]bool), args.Error(1)
+}
+
 func (m *MockGitClient) CherryPick(commit string) error {
 	args := m.Called(commit)
 	return args.Error(0)
 }
 
+func (m *MockGitClient) CherryPickTracked(commit string) error {
+	args := m.Called(commit)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) GetPickedCommits(base, branch string) ([]git.PickedCommit, error) {
+	args := m.Called(base, branch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]git.PickedCommit), args.Error(1)
+}
+
+func (m *MockGitClient) IsCommitLanded(base, commit string) (bool, error) {
+	args := m.Called(base, commit)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockGitClient) FormatPatch(base, branch, dir string) ([]string, error) {
+	args := m.Called(base, branch, dir)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) ApplyPatches(files []string) error {
+	args := m.Called(files)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) ForceBranch(branch, ref string) error {
+	args := m.Called(branch, ref)
+	return args.Error(0)
+}
+
 func (m *MockGitClient) ResetHard(ref string) error {
 	args := m.Called(ref)
 	return args.Error(0)
@@ -211,6 +354,36 @@ func (m *MockGitClient) IsCommitsBehind(branch, base string) (bool, error) {
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockGitClient) CountCommitsBehind(branch, base string) (int, error) {
+	args := m.Called(branch, base)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetCommitTime(ref string) (time.Time, error) {
+	args := m.Called(ref)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockGitClient) GetCommitMessage(ref string) (string, error) {
+	args := m.Called(ref)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetCommitAuthorEmail(ref string) (string, error) {
+	args := m.Called(ref)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) RebaseSignoff(upstream string) error {
+	args := m.Called(upstream)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) GetBranchTipAt(branch string, at time.Time) (string, error) {
+	args := m.Called(branch, at)
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockGitClient) DeleteBranch(name string) error {
 	args := m.Called(name)
 	return args.Error(0)
@@ -241,11 +414,162 @@ func (m *MockGitClient) RemoveWorktree(path string) error {
 	return args.Error(0)
 }
 
+func (m *MockGitClient) ForceRemoveWorktree(path string) error {
+	args := m.Called(path)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) PruneWorktrees() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockGitClient) InWorktree(path string) git.GitClient {
+	args := m.Called(path)
+	return args.Get(0).(git.GitClient)
+}
+
+func (m *MockGitClient) AddWorktreeDetached(path, ref string) error {
+	args := m.Called(path, ref)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) GetWorktreeHead(path string) (string, error) {
+	args := m.Called(path)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) CheckoutDetachedInWorktree(path, ref string) error {
+	args := m.Called(path, ref)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) RangeDiff(oldBase, oldHead, newBase, newHead string) (string, error) {
+	args := m.Called(oldBase, oldHead, newBase, newHead)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) UpdateSubmodules() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockGitClient) GetStaleSubmodules() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetConflictedSubmodules() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) UsesLFS() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockGitClient) LFSInstalled() bool {
+	args := m.Called()
+	return args.Bool(0)
+}
+
+func (m *MockGitClient) LFSPull() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func (m *MockGitClient) ListWorktrees() ([]string, error) {
 	args := m.Called()
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (m *MockGitClient) GetChangedFiles(base, branch string) ([]string, error) {
+	args := m.Called(base, branch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetMergeConflicts(base, branch string) ([]string, error) {
+	args := m.Called(base, branch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) GetDiffStat(base, branch string) ([]git.FileStat, error) {
+	args := m.Called(base, branch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]git.FileStat), args.Error(1)
+}
+
+func (m *MockGitClient) ShowFile(ref, path string) (string, error) {
+	args := m.Called(ref, path)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) FetchRef(ref string) error {
+	args := m.Called(ref)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) CommitFile(parent, name, content, message string) (string, error) {
+	args := m.Called(parent, name, content, message)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) GetStagedHunks() ([]git.Hunk, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]git.Hunk), args.Error(1)
+}
+
+func (m *MockGitClient) BlameLines(ref, path string, start, count int) ([]string, error) {
+	args := m.Called(ref, path, start, count)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockGitClient) CommitHunks(parent string, hunks []git.Hunk, message string) (string, error) {
+	args := m.Called(parent, hunks, message)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitClient) UpdateRef(ref, sha string) error {
+	args := m.Called(ref, sha)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) PushRef(ref string) error {
+	args := m.Called(ref)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) EditBranchDescription(branch string) error {
+	args := m.Called(branch)
+	return args.Error(0)
+}
+
+func (m *MockGitClient) EditFile(path string) error {
+	args := m.Called(path)
+	return args.Error(0)
+}
+
 func (m *MockGitClient) GetRemoteURL(remoteName string) string {
 	args := m.Called(remoteName)
 	return args.String(0)
@@ -264,16 +588,103 @@ func (m *MockGitHubClient) GetPRForBranch(branch string) (*github.PRInfo, error)
 	return args.Get(0).(*github.PRInfo), args.Error(1)
 }
 
+func (m *MockGitHubClient) GetPRByNumber(number int) (*github.PRInfo, error) {
+	args := m.Called(number)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*github.PRInfo), args.Error(1)
+}
+
 func (m *MockGitHubClient) GetAllPRs() (map[string]*github.PRInfo, error) {
 	args := m.Called()
 	return args.Get(0).(map[string]*github.PRInfo), args.Error(1)
 }
 
+func (m *MockGitHubClient) GetPRsForBranches(branches []string) (map[string]*github.PRInfo, error) {
+	args := m.Called(branches)
+	return args.Get(0).(map[string]*github.PRInfo), args.Error(1)
+}
+
+func (m *MockGitHubClient) GetPRsByAuthor(author string) (map[string]*github.PRInfo, error) {
+	args := m.Called(author)
+	return args.Get(0).(map[string]*github.PRInfo), args.Error(1)
+}
+
+func (m *MockGitHubClient) ListAuthoredPRs(author string) ([]*github.PRInfo, error) {
+	args := m.Called(author)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*github.PRInfo), args.Error(1)
+}
+
+func (m *MockGitHubClient) GetPRChecks(prNumber int) (*github.PRInfo, error) {
+	args := m.Called(prNumber)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*github.PRInfo), args.Error(1)
+}
+
 func (m *MockGitHubClient) UpdatePRBase(prNumber int, newBase string) error {
 	args := m.Called(prNumber, newBase)
 	return args.Error(0)
 }
 
+func (m *MockGitHubClient) CreatePR(head, base string, draft bool, body string) (*github.PRInfo, error) {
+	args := m.Called(head, base, draft, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*github.PRInfo), args.Error(1)
+}
+
+func (m *MockGitHubClient) CommentOnPR(prNumber int, body string) error {
+	args := m.Called(prNumber, body)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) GetPRBody(prNumber int) (string, error) {
+	args := m.Called(prNumber)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockGitHubClient) UpdatePRBody(prNumber int, body string) error {
+	args := m.Called(prNumber, body)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) UpdatePRTitle(prNumber int, title string) error {
+	args := m.Called(prNumber, title)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) EditPRLabels(prNumber int, add, remove []string) error {
+	args := m.Called(prNumber, add, remove)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) RequestReviewers(prNumber int, reviewers []string) error {
+	args := m.Called(prNumber, reviewers)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) MergePR(prNumber int, method string) error {
+	args := m.Called(prNumber, method)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) ClosePR(prNumber int, comment string) error {
+	args := m.Called(prNumber, comment)
+	return args.Error(0)
+}
+
+func (m *MockGitHubClient) GetCurrentUser() (string, error) {
+	args := m.Called()
+	return args.String(0), args.Error(1)
+}
+
 func (m *MockGitHubClient) IsPRMerged(prNumber int) (bool, error) {
 	args := m.Called(prNumber)
 	return args.Bool(0), args.Error(1)
@@ -42,19 +42,19 @@ func PRState(state string) string {
 	}
 }
 
-// Success returns a green success message with checkmark
+// Success returns a success message with the theme's glyph (a green checkmark by default)
 func Success(msg string) string {
-	return green.Sprintf("✓ %s", msg)
+	return theme.SuccessColor.Sprintf("%s %s", theme.SuccessGlyph, msg)
 }
 
-// Warning returns a yellow warning message with warning sign
+// Warning returns a warning message with the theme's glyph (a yellow warning sign by default)
 func Warning(msg string) string {
-	return yellow.Sprintf("⚠ %s", msg)
+	return theme.WarningColor.Sprintf("%s %s", theme.WarningGlyph, msg)
 }
 
-// Error returns a red error message with X
+// Error returns an error message with the theme's glyph (a red X by default)
 func Error(msg string) string {
-	return red.Sprintf("✗ %s", msg)
+	return theme.ErrorColor.Sprintf("%s %s", theme.ErrorGlyph, msg)
 }
 
 // ErrorText returns red text without the X prefix
@@ -82,19 +82,19 @@ func Pipe() string {
 	return dim.Sprint("|")
 }
 
-// SuccessIcon returns just the green checkmark
+// SuccessIcon returns just the success glyph (the green checkmark by default)
 func SuccessIcon() string {
-	return green.Sprint("✓")
+	return theme.SuccessColor.Sprint(theme.SuccessGlyph)
 }
 
-// WarningIcon returns just the yellow warning sign
+// WarningIcon returns just the warning glyph (the yellow warning sign by default)
 func WarningIcon() string {
-	return yellow.Sprint("⚠")
+	return theme.WarningColor.Sprint(theme.WarningGlyph)
 }
 
-// ErrorIcon returns just the red X
+// ErrorIcon returns just the error glyph (the red X by default)
 func ErrorIcon() string {
-	return red.Sprint("✗")
+	return theme.ErrorColor.Sprint(theme.ErrorGlyph)
 }
 
 // PRInfo formats PR information with URL and colored state
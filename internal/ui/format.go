@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+)
+
+// ShortSHA abbreviates a commit hash for display
+func ShortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
+
+// Age formats a duration in its largest whole unit, e.g. "3d", "5h", "12m"
+func Age(age time.Duration) string {
+	switch {
+	case age >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(age.Hours()/24))
+	case age >= time.Hour:
+		return fmt.Sprintf("%dh", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(age.Minutes()))
+	}
+}
+
+// ReportedError wraps an error whose details were already shown to the user, so
+// it only sets the exit code instead of being printed again
+type ReportedError struct {
+	Err error
+}
+
+func (e *ReportedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ReportedError) Unwrap() error {
+	return e.Err
+}
+
+// Reported marks err as already explained to the user
+func Reported(err error) error {
+	return &ReportedError{Err: err}
+}
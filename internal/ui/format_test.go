@@ -0,0 +1,31 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortSHA(t *testing.T) {
+	assert.Equal(t, "0123abcd", ShortSHA("0123abcdef456789"))
+	assert.Equal(t, "abc", ShortSHA("abc"))
+}
+
+func TestAge(t *testing.T) {
+	assert.Equal(t, "5m", Age(5*time.Minute))
+	assert.Equal(t, "2h", Age(150*time.Minute))
+	assert.Equal(t, "3d", Age(80*time.Hour))
+}
+
+func TestReported(t *testing.T) {
+	cause := errors.New("stack check failed")
+	err := fmt.Errorf("sync: %w", Reported(cause))
+
+	var reported *ReportedError
+	assert.True(t, errors.As(err, &reported))
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "stack check failed", reported.Error())
+}
@@ -0,0 +1,95 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Theme is the set of glyphs and colors used for status messages and spinners
+type Theme struct {
+	Frames       []string // spinner animation, one entry per frame
+	SuccessGlyph string
+	WarningGlyph string
+	ErrorGlyph   string
+	SuccessColor *color.Color
+	WarningColor *color.Color
+	ErrorColor   *color.Color
+}
+
+// themes are the built-in themes. minimal sticks to ASCII, for terminals and
+// fonts that render the default glyphs poorly.
+var themes = map[string]Theme{
+	"default": {
+		Frames:       []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		SuccessGlyph: "✓",
+		WarningGlyph: "⚠",
+		ErrorGlyph:   "✗",
+		SuccessColor: green,
+		WarningColor: yellow,
+		ErrorColor:   red,
+	},
+	"minimal": {
+		Frames:       []string{"-", "\\", "|", "/"},
+		SuccessGlyph: "+",
+		WarningGlyph: "!",
+		ErrorGlyph:   "x",
+		SuccessColor: green,
+		WarningColor: yellow,
+		ErrorColor:   red,
+	},
+}
+
+// theme is the theme in effect
+var theme = themes["default"]
+
+// colors are the color names accepted by ParseColor
+var colors = map[string]color.Attribute{
+	"black":   color.FgBlack,
+	"red":     color.FgRed,
+	"green":   color.FgGreen,
+	"yellow":  color.FgYellow,
+	"blue":    color.FgBlue,
+	"magenta": color.FgMagenta,
+	"cyan":    color.FgCyan,
+	"white":   color.FgWhite,
+}
+
+// LookupTheme returns the built-in theme called name
+func LookupTheme(name string) (Theme, error) {
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (expected default or minimal)", name)
+	}
+	return t, nil
+}
+
+// ParseColor reads a color name such as "green", optionally prefixed with
+// "bright-", or "none" to leave the text uncolored
+func ParseColor(name string) (*color.Color, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "none" {
+		return color.New(color.Reset), nil
+	}
+	bright := strings.HasPrefix(name, "bright-")
+	attr, ok := colors[strings.TrimPrefix(name, "bright-")]
+	if !ok {
+		return nil, fmt.Errorf("unknown color %q", name)
+	}
+	if bright {
+		// The bright variants are 60 above the normal ones
+		attr += color.FgHiBlack - color.FgBlack
+	}
+	return color.New(attr), nil
+}
+
+// SetTheme switches the glyphs and colors used from now on
+func SetTheme(t Theme) {
+	theme = t
+}
+
+// SpinnerFrames returns the spinner animation of the current theme
+func SpinnerFrames() []string {
+	return theme.Frames
+}